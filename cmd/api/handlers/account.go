@@ -2,9 +2,14 @@ package handlers
 
 import (
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/baking-bad/bcdhub/internal/bcd"
 	"github.com/baking-bad/bcdhub/internal/config"
+	"github.com/baking-bad/bcdhub/internal/models/operation"
+	"github.com/baking-bad/bcdhub/internal/models/types"
 	"github.com/gin-gonic/gin"
 	"github.com/rs/zerolog/log"
 )
@@ -62,3 +67,107 @@ func GetInfo() gin.HandlerFunc {
 	}
 
 }
+
+// GetAccountOperations godoc
+// @Summary Get account operation history
+// @Description Get operations where account is source, initiator, destination or delegate. Operations are sorted from newest to oldest.
+// @Tags account
+// @ID get-account-operations
+// @Param network path string true "Network"
+// @Param address path string true "Address" minlength(36) maxlength(36)
+// @Param last_id query integer false "Last operation ID"
+// @Param size query integer false "Requested count" mininum(1) maximum(10)
+// @Param kind query string false "Comma-separated operation kinds: transaction, origination, delegation, etc."
+// @Param status query string false "Comma-separated operation statuses: applied, failed, backtracked, skipped"
+// @Param entrypoint query string false "Comma-separated entrypoints"
+// @Param from query integer false "Timestamp (unix seconds) of the beginning of time range, inclusive"
+// @Param to query integer false "Timestamp (unix seconds) of the end of time range, exclusive"
+// @Param counterparty query string false "Address of another participant of operation" minlength(36) maxlength(36)
+// @Accept  json
+// @Produce  json
+// @Success 200 {object} OperationResponse
+// @Failure 400 {object} Error
+// @Failure 404 {object} Error
+// @Failure 500 {object} Error
+// @Router /v1/account/{network}/{address}/operations [get]
+func GetAccountOperations() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.MustGet("context").(*config.Context)
+
+		var req getAccountRequest
+		if err := c.ShouldBindUri(&req); handleError(c, ctx.Storage, err, http.StatusNotFound) {
+			return
+		}
+
+		var args accountOperationsRequest
+		if err := c.ShouldBindQuery(&args); handleError(c, ctx.Storage, err, http.StatusBadRequest) {
+			return
+		}
+
+		acc, err := ctx.Accounts.Get(c.Request.Context(), req.Address)
+		if handleError(c, ctx.Storage, err, 0) {
+			return
+		}
+
+		historyReq := newHistoryRequest(args)
+		if args.Counterparty != "" {
+			counterparty, err := ctx.Accounts.Get(c.Request.Context(), args.Counterparty)
+			if err != nil {
+				if ctx.Storage.IsRecordNotFound(err) {
+					c.SecureJSON(http.StatusOK, OperationResponse{Operations: []Operation{}})
+					return
+				}
+				handleError(c, ctx.Storage, err, 0)
+				return
+			}
+			historyReq.CounterpartyID = counterparty.ID
+		}
+
+		operations, err := ctx.Operations.History(c.Request.Context(), acc.ID, historyReq)
+		if handleError(c, ctx.Storage, err, 0) {
+			return
+		}
+
+		response := OperationResponse{
+			Operations: make([]Operation, len(operations)),
+		}
+		for i := range operations {
+			op, err := prepareOperation(c.Request.Context(), ctx, operations[i], false)
+			if handleError(c, ctx.Storage, err, 0) {
+				return
+			}
+			response.Operations[i] = op
+		}
+		if len(operations) > 0 {
+			response.LastID = strconv.FormatInt(operations[len(operations)-1].ID, 10)
+		}
+		c.SecureJSON(http.StatusOK, response)
+	}
+}
+
+func newHistoryRequest(args accountOperationsRequest) operation.HistoryRequest {
+	req := operation.HistoryRequest{
+		LastID: args.LastID,
+		Limit:  args.Size,
+	}
+	if args.Kind != "" {
+		for _, kind := range strings.Split(args.Kind, ",") {
+			req.Kinds = append(req.Kinds, types.NewOperationKind(kind))
+		}
+	}
+	if args.Status != "" {
+		for _, status := range strings.Split(args.Status, ",") {
+			req.Statuses = append(req.Statuses, types.NewOperationStatus(status))
+		}
+	}
+	if args.Entrypoint != "" {
+		req.Entrypoints = strings.Split(args.Entrypoint, ",")
+	}
+	if args.From > 0 {
+		req.From = time.Unix(args.From, 0).UTC()
+	}
+	if args.To > 0 {
+		req.To = time.Unix(args.To, 0).UTC()
+	}
+	return req
+}
@@ -0,0 +1,48 @@
+package handlers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/baking-bad/bcdhub/internal/models/operation"
+	modelTypes "github.com/baking-bad/bcdhub/internal/models/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewHistoryRequest(t *testing.T) {
+	tests := []struct {
+		name string
+		args accountOperationsRequest
+		want operation.HistoryRequest
+	}{
+		{
+			name: "empty",
+			want: operation.HistoryRequest{},
+		}, {
+			name: "all filters",
+			args: accountOperationsRequest{
+				LastID:     100,
+				Size:       5,
+				Kind:       "transaction,delegation",
+				Status:     "applied,failed",
+				Entrypoint: "transfer,mint",
+				From:       1700000000,
+				To:         1700003600,
+			},
+			want: operation.HistoryRequest{
+				Kinds:       []modelTypes.OperationKind{modelTypes.OperationKindTransaction, modelTypes.OperationKindDelegation},
+				Statuses:    []modelTypes.OperationStatus{modelTypes.OperationStatusApplied, modelTypes.OperationStatusFailed},
+				Entrypoints: []string{"transfer", "mint"},
+				From:        time.Date(2023, 11, 14, 22, 13, 20, 0, time.UTC),
+				To:          time.Date(2023, 11, 14, 23, 13, 20, 0, time.UTC),
+				LastID:      100,
+				Limit:       5,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, newHistoryRequest(tt.args))
+		})
+	}
+}
@@ -137,7 +137,7 @@ func GetBigMapHistory() gin.HandlerFunc {
 
 // GetBigMapKeys godoc
 // @Summary Get big map keys by pointer
-// @Description Get big map keys by pointer
+// @Description Get big map keys by pointer. If `level` is set, keys are returned with their values as they were at the level: keys added later are excluded and keys removed later are returned as active.
 // @Tags bigmap
 // @ID get-bigmap-keys
 // @Param network path string true "Network"
@@ -146,6 +146,7 @@ func GetBigMapHistory() gin.HandlerFunc {
 // @Param size query integer false "Requested count" mininum(1) maximum(10)
 // @Param max_level query integer false "Max level filter" minimum(0)
 // @Param min_level query integer false "Min level filter" minimum(0)
+// @Param level query integer false "Level of big map snapshot" minimum(1)
 // @Accept json
 // @Produce json
 // @Success 200 {array} BigMapResponseItem
@@ -160,19 +161,28 @@ func GetBigMapKeys() gin.HandlerFunc {
 		if err := c.ShouldBindUri(&req); handleError(c, ctx.Storage, err, http.StatusBadRequest) {
 			return
 		}
-		var pageReq bigMapSearchRequest
+		var pageReq bigMapKeysRequest
 		if err := c.ShouldBindQuery(&pageReq); handleError(c, ctx.Storage, err, http.StatusBadRequest) {
 			return
 		}
 
-		keys, err := ctx.BigMapDiffs.Keys(c.Request.Context(), bigmapdiff.GetContext{
+		reqCtx := bigmapdiff.GetContext{
 			Ptr:      &req.Ptr,
 			Size:     pageReq.Size,
 			Offset:   pageReq.Offset,
 			MaxLevel: pageReq.MaxLevel,
 			MinLevel: pageReq.MinLevel,
-		})
+		}
 
+		var (
+			keys []bigmapdiff.BigMapState
+			err  error
+		)
+		if pageReq.Level != nil {
+			keys, err = ctx.BigMapDiffs.KeysAtLevel(c.Request.Context(), reqCtx, *pageReq.Level)
+		} else {
+			keys, err = ctx.BigMapDiffs.Keys(c.Request.Context(), reqCtx)
+		}
 		if handleError(c, ctx.Storage, err, 0) {
 			return
 		}
@@ -182,7 +192,7 @@ func GetBigMapKeys() gin.HandlerFunc {
 			return
 		}
 
-		response, err := prepareBigMapKeys(c.Request.Context(), ctx, keys, symLink)
+		response, err := prepareBigMapKeys(c.Request.Context(), ctx, keys, symLink, pageReq.Level != nil)
 		if handleError(c, ctx.Storage, err, 0) {
 			return
 		}
@@ -337,7 +347,7 @@ func GetBigMapDiffCount() gin.HandlerFunc {
 	}
 }
 
-func prepareBigMapKeys(c context.Context, ctx *config.Context, data []bigmapdiff.BigMapState, symLink string) ([]BigMapResponseItem, error) {
+func prepareBigMapKeys(c context.Context, ctx *config.Context, data []bigmapdiff.BigMapState, symLink string, withValues bool) ([]BigMapResponseItem, error) {
 	if len(data) == 0 {
 		return []BigMapResponseItem{}, nil
 	}
@@ -365,6 +375,14 @@ func prepareBigMapKeys(c context.Context, ctx *config.Context, data []bigmapdiff
 			},
 			Count: data[i].Count,
 		}
+
+		if withValues && !data[i].Removed && len(data[i].Value) > 0 {
+			value, err := createMiguelForType(ast.Copy(bigMapType.ValueType), data[i].Value)
+			if err != nil {
+				return nil, err
+			}
+			res[i].Item.Value = value
+		}
 	}
 	return res, nil
 }
@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"context"
+	stdJSON "encoding/json"
 	"net/http"
 
 	"github.com/baking-bad/bcdhub/internal/bcd"
@@ -21,9 +22,13 @@ import (
 // @Param address path string true "KT address" minlength(36) maxlength(36)
 // @Param protocol query string false "Protocol"
 // @Param level query integer false "Level"
+// @Param macros query bool false "Fold expansions of macros back into macros"
+// @Param expand_constants query bool false "Substitute global constants recursively. Each expansion is preceded by comment with constant hash in Michelson and is listed with its path in Micheline."
+// @Param format query string false "Format of code" Enums(michelson, micheline)
 // @Accept  json
 // @Produce  json
 // @Success 200 {string} string
+// @Success 200 {object} ContractCode
 // @Failure 400 {object} Error
 // @Failure 500 {object} Error
 // @Router /v1/contract/{network}/{address}/code [get]
@@ -57,7 +62,33 @@ func GetContractCode() gin.HandlerFunc {
 			return
 		}
 
-		resp, err := formatter.MichelineToMichelson(code, false, formatter.DefLineSize)
+		if req.Format == "micheline" {
+			response := ContractCode{
+				Code: stdJSON.RawMessage(code.Raw),
+			}
+			if req.ExpandConstants {
+				response.Code, response.Constants, err = bcd.ExpandConstants(response.Code, constantResolver(c.Request.Context(), ctx))
+				if handleError(c, ctx.Storage, err, 0) {
+					return
+				}
+			}
+			c.SecureJSON(http.StatusOK, response)
+			return
+		}
+
+		if req.ExpandConstants {
+			expanded, err := bcd.ExpandConstantsMarked([]byte(code.Raw), constantResolver(c.Request.Context(), ctx))
+			if handleError(c, ctx.Storage, err, 0) {
+				return
+			}
+			code = gjson.ParseBytes(expanded)
+		}
+
+		var opts []formatter.Option
+		if req.Macros {
+			opts = append(opts, formatter.WithMacros())
+		}
+		resp, err := formatter.MichelineToMichelson(code, false, formatter.DefLineSize, opts...)
 		if handleError(c, ctx.Storage, err, 0) {
 			return
 		}
@@ -88,3 +119,17 @@ func getContractCodeJSON(c context.Context, ctx *config.Context, address string,
 
 	return contract, nil
 }
+
+func constantResolver(c context.Context, ctx *config.Context) bcd.ConstantResolver {
+	return func(hashes ...string) (map[string][]byte, error) {
+		constants, err := ctx.GlobalConstants.All(c, hashes...)
+		if err != nil {
+			return nil, err
+		}
+		values := make(map[string][]byte, len(constants))
+		for i := range constants {
+			values[constants[i].Address] = constants[i].Value
+		}
+		return values, nil
+	}
+}
@@ -0,0 +1,176 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/baking-bad/bcdhub/internal/bcd"
+	"github.com/baking-bad/bcdhub/internal/bcd/consts"
+	"github.com/baking-bad/bcdhub/internal/bcd/formatter"
+	"github.com/baking-bad/bcdhub/internal/config"
+	"github.com/baking-bad/bcdhub/internal/models/contract"
+	"github.com/baking-bad/bcdhub/internal/models/types"
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+	"github.com/tidwall/gjson"
+)
+
+// GetCodeDiff godoc
+// @Summary Get diff between two contract scripts
+// @Description Compares parameter, storage, code and views of two contracts separately. Contracts can be from different networks. Protocol is optional: it can be protocol hash or symbolic link of script version (alpha, babylon, jakarta). The latest script version of contract is used if it's empty.
+// @Tags contract
+// @ID get-code-diff
+// @Param left query string true "Left contract in format `network:address[@protocol]`"
+// @Param right query string true "Right contract in format `network:address[@protocol]`"
+// @Accept json
+// @Produce json
+// @Success 200 {object} CodeDiffResponse
+// @Failure 400 {object} Error
+// @Failure 404 {object} Error
+// @Failure 500 {object} Error
+// @Router /v1/diff [get]
+func GetCodeDiff() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctxs := c.MustGet("contexts").(config.Contexts)
+		any := ctxs.Any()
+
+		var req codeDiffRequest
+		if err := c.ShouldBindQuery(&req); handleError(c, any.Storage, err, http.StatusBadRequest) {
+			return
+		}
+
+		left, err := parseCodeDiffLeg(req.Left)
+		if handleError(c, any.Storage, err, http.StatusBadRequest) {
+			return
+		}
+		right, err := parseCodeDiffLeg(req.Right)
+		if handleError(c, any.Storage, err, http.StatusBadRequest) {
+			return
+		}
+
+		leftScript, err := getCodeDiffScript(c.Request.Context(), ctxs, &left)
+		if err != nil {
+			handleCodeDiffError(c, ctxs, left, err)
+			return
+		}
+		rightScript, err := getCodeDiffScript(c.Request.Context(), ctxs, &right)
+		if err != nil {
+			handleCodeDiffError(c, ctxs, right, err)
+			return
+		}
+
+		response, err := diffScripts(leftScript, rightScript)
+		if handleError(c, any.Storage, err, 0) {
+			return
+		}
+		response.Left = left
+		response.Right = right
+
+		c.SecureJSON(http.StatusOK, response)
+	}
+}
+
+// parseCodeDiffLeg - parses string in format `network:address[@protocol]`
+func parseCodeDiffLeg(value string) (CodeDiffLeg, error) {
+	var leg CodeDiffLeg
+
+	network, address, ok := strings.Cut(value, ":")
+	if !ok {
+		return leg, errors.Errorf("invalid contract format: %s. Expected `network:address[@protocol]`", value)
+	}
+	if types.NewNetwork(network) == types.Empty {
+		return leg, errors.Errorf("unknown network: %s", network)
+	}
+	leg.Network = network
+
+	address, protocol, _ := strings.Cut(address, "@")
+	if !bcd.IsContract(address) {
+		return leg, errors.Errorf("invalid contract address: %s", address)
+	}
+	leg.Address = address
+
+	if protocol != "" {
+		switch protocol {
+		case bcd.SymLinkAlpha, bcd.SymLinkBabylon, bcd.SymLinkJakarta:
+			leg.Protocol = protocol
+		default:
+			symLink, err := bcd.GetProtoSymLink(protocol)
+			if err != nil {
+				return leg, err
+			}
+			leg.Protocol = symLink
+		}
+	}
+	return leg, nil
+}
+
+// getCodeDiffScript - returns script of leg. If leg's protocol is empty it's set to the latest script version of contract.
+func getCodeDiffScript(c context.Context, ctxs config.Contexts, leg *CodeDiffLeg) (contract.Script, error) {
+	ctx, err := ctxs.Get(types.NewNetwork(leg.Network))
+	if err != nil {
+		return contract.Script{}, err
+	}
+
+	if leg.Protocol == "" {
+		item, err := ctx.Contracts.Get(c, leg.Address)
+		if err != nil {
+			return contract.Script{}, err
+		}
+		switch {
+		case item.JakartaID > 0:
+			leg.Protocol = bcd.SymLinkJakarta
+		case item.BabylonID > 0:
+			leg.Protocol = bcd.SymLinkBabylon
+		default:
+			leg.Protocol = bcd.SymLinkAlpha
+		}
+	}
+
+	return ctx.Cache.Script(c, leg.Address, leg.Protocol)
+}
+
+func handleCodeDiffError(c *gin.Context, ctxs config.Contexts, leg CodeDiffLeg, err error) {
+	ctx, ctxErr := ctxs.Get(types.NewNetwork(leg.Network))
+	if ctxErr != nil {
+		handleError(c, ctxs.Any().Storage, ctxErr, http.StatusBadRequest)
+		return
+	}
+	handleError(c, ctx.Storage, err, 0)
+}
+
+func diffScripts(left, right contract.Script) (response CodeDiffResponse, err error) {
+	response.Parameter, err = diffScriptSection(consts.PARAMETER, left.Parameter, right.Parameter)
+	if err != nil {
+		return
+	}
+	response.Storage, err = diffScriptSection(consts.STORAGE, left.Storage, right.Storage)
+	if err != nil {
+		return
+	}
+	response.Code, err = diffScriptSection(consts.CODE, left.Code, right.Code)
+	if err != nil {
+		return
+	}
+	response.Views, err = formatter.Diff(scriptViews(left.Views), scriptViews(right.Views))
+	return
+}
+
+// diffScriptSection - wraps section arguments with section primitive, so it's formatted as in full script
+func diffScriptSection(prim string, left, right []byte) (formatter.DiffResult, error) {
+	return formatter.Diff(scriptSection(prim, left), scriptSection(prim, right))
+}
+
+func scriptSection(prim string, args []byte) gjson.Result {
+	if len(args) == 0 {
+		args = []byte("[]")
+	}
+	return gjson.Parse(`{"prim":"` + prim + `","args":` + string(args) + `}`)
+}
+
+func scriptViews(views []byte) gjson.Result {
+	if len(views) == 0 {
+		return gjson.Parse("[]")
+	}
+	return gjson.ParseBytes(views)
+}
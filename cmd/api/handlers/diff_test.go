@@ -0,0 +1,93 @@
+package handlers
+
+import (
+	"testing"
+
+	"github.com/baking-bad/bcdhub/internal/models/contract"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCodeDiffLeg(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    CodeDiffLeg
+		wantErr bool
+	}{
+		{
+			name:  "without protocol",
+			value: "mainnet:KT1Hkg5qeNhfwpKW4fXvq7HGZB9z2EnmCCA9",
+			want: CodeDiffLeg{
+				Network: "mainnet",
+				Address: "KT1Hkg5qeNhfwpKW4fXvq7HGZB9z2EnmCCA9",
+			},
+		}, {
+			name:  "with symbolic link",
+			value: "ghostnet:KT1Hkg5qeNhfwpKW4fXvq7HGZB9z2EnmCCA9@babylon",
+			want: CodeDiffLeg{
+				Network:  "ghostnet",
+				Address:  "KT1Hkg5qeNhfwpKW4fXvq7HGZB9z2EnmCCA9",
+				Protocol: "babylon",
+			},
+		}, {
+			name:  "with protocol hash",
+			value: "mainnet:KT1Hkg5qeNhfwpKW4fXvq7HGZB9z2EnmCCA9@PtYuensgYBb3G3x1hLLbCmcav8ue8Kyd2khADcL5LsT5R1hcXex",
+			want: CodeDiffLeg{
+				Network:  "mainnet",
+				Address:  "KT1Hkg5qeNhfwpKW4fXvq7HGZB9z2EnmCCA9",
+				Protocol: "alpha",
+			},
+		}, {
+			name:    "without network",
+			value:   "KT1Hkg5qeNhfwpKW4fXvq7HGZB9z2EnmCCA9",
+			wantErr: true,
+		}, {
+			name:    "unknown network",
+			value:   "unknown:KT1Hkg5qeNhfwpKW4fXvq7HGZB9z2EnmCCA9",
+			wantErr: true,
+		}, {
+			name:    "implicit account",
+			value:   "mainnet:tz1aCzsYRUgDZBV7zb7Si6q2AobrocFW5qwb",
+			wantErr: true,
+		}, {
+			name:    "unknown protocol",
+			value:   "mainnet:KT1Hkg5qeNhfwpKW4fXvq7HGZB9z2EnmCCA9@unknown",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseCodeDiffLeg(tt.value)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestDiffScripts(t *testing.T) {
+	left := contract.Script{
+		Parameter: []byte(`[{"prim":"unit"}]`),
+		Storage:   []byte(`[{"prim":"nat"}]`),
+		Code:      []byte(`[[{"prim":"CDR"},{"prim":"NIL","args":[{"prim":"operation"}]},{"prim":"PAIR"}]]`),
+	}
+	right := contract.Script{
+		Parameter: []byte(`[{"prim":"nat"}]`),
+		Storage:   []byte(`[{"prim":"nat"}]`),
+		Code:      []byte(`[[{"prim":"CDR"},{"prim":"NIL","args":[{"prim":"operation"}]},{"prim":"PAIR"}]]`),
+		Views:     []byte(`[{"prim":"view","args":[{"string":"get"},{"prim":"unit"},{"prim":"nat"},[{"prim":"CDR"}]]}]`),
+	}
+
+	diff, err := diffScripts(left, right)
+	require.NoError(t, err)
+
+	require.EqualValues(t, 1, diff.Parameter.Removed)
+	require.EqualValues(t, 1, diff.Parameter.Added)
+	require.Zero(t, diff.Storage.Added+diff.Storage.Removed+diff.Storage.Changed)
+	require.Zero(t, diff.Code.Added+diff.Code.Removed+diff.Code.Changed)
+	require.EqualValues(t, 1, diff.Views.Added)
+	require.Zero(t, diff.Views.Removed)
+}
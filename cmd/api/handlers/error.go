@@ -64,7 +64,7 @@ func isTimeoutError(err error) bool {
 }
 
 func getErrorCode(err error, repo models.GeneralRepository) int {
-	if repo.IsRecordNotFound(err) {
+	if repo.IsRecordNotFound(err) || errors.Is(err, errStorageNotIndexed) {
 		return http.StatusNotFound
 	}
 	if errors.Is(err, consts.ErrValidation) ||
@@ -0,0 +1,295 @@
+package handlers
+
+import (
+	"context"
+	stdJSON "encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/baking-bad/bcdhub/internal/bcd"
+	"github.com/baking-bad/bcdhub/internal/bcd/ast"
+	"github.com/baking-bad/bcdhub/internal/bcd/consts"
+	forgeOperations "github.com/baking-bad/bcdhub/internal/bcd/forge/operations"
+	"github.com/baking-bad/bcdhub/internal/config"
+	"github.com/baking-bad/bcdhub/internal/models/block"
+	"github.com/baking-bad/bcdhub/internal/noderpc"
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+)
+
+// default mempool filter of octez node: fee >= minimal_fees + minimal_nanotez_per_byte * size + minimal_nanotez_per_gas_unit * gas.
+// They are settings of the node, not protocol constants, so they can't be received from `constants` RPC.
+const (
+	minimalFees              int64 = 100
+	minimalNanotezPerByte    int64 = 1000
+	minimalNanotezPerGasUnit int64 = 100
+)
+
+// safety margins which are added to simulated values
+const (
+	gasLimitMargin     int64 = 100
+	storageLimitMargin int64 = 20
+)
+
+// size of signature which is appended to forged operation
+const signatureSize = 64
+
+// Estimate godoc
+// @Summary Estimate operation
+// @Description Simulate operation and return recommended gas limit, storage limit, fee and burn with safety margins. `kind` may be `transaction` (default) which calls entrypoint `name` with `data`, `origination` which originates script of the contract with storage built from `data` (current storage is used if `data` is empty) or `transfer_ticket` which sends ticket to entrypoint `name` of the contract. Minimal fee is computed from forged operation size and gas limit with default mempool filter of octez node: 100 mutez + 1000 nanotez per byte + 100 nanotez per gas unit. Nodes with other filter settings may require higher fee. Gas and storage limits are capped by hard limits from protocol constants. Source must be revealed: reveal isn't simulated, so 400 is returned for unrevealed implicit account.
+// @Tags contract
+// @ID estimate
+// @Param network path string true "Network"
+// @Param address path string true "KT address" minlength(36) maxlength(36)
+// @Param body body estimateRequest true "Request body"
+// @Accept json
+// @Produce json
+// @Success 200 {object} EstimationResponse
+// @Failure 400 {object} Error
+// @Failure 404 {object} Error
+// @Failure 500 {object} Error
+// @Router /v1/contract/{network}/{address}/entrypoints/estimate [post]
+func Estimate() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.MustGet("context").(*config.Context)
+
+		var req getContractRequest
+		if err := c.ShouldBindUri(&req); handleError(c, ctx.Storage, err, http.StatusNotFound) {
+			return
+		}
+		var reqEstimate estimateRequest
+		if err := c.ShouldBindJSON(&reqEstimate); handleError(c, ctx.Storage, err, http.StatusBadRequest) {
+			return
+		}
+		if ctx.RPC == nil {
+			handleError(c, ctx.Storage, errors.Errorf("operation can't be estimated: RPC is not set for %s", ctx.Network), http.StatusBadRequest)
+			return
+		}
+
+		state, err := ctx.Blocks.Last(c.Request.Context())
+		if handleError(c, ctx.Storage, err, 0) {
+			return
+		}
+		constants, err := ctx.RPC.GetNetworkConstants(c, state.Level)
+		if handleError(c, ctx.Storage, err, 0) {
+			return
+		}
+		if !bcd.IsContract(reqEstimate.Source) {
+			key, err := ctx.RPC.GetManagerKey(c, reqEstimate.Source)
+			if handleError(c, ctx.Storage, err, 0) {
+				return
+			}
+			if key == "" {
+				handleError(c, ctx.Storage, errors.Errorf("source %s isn't revealed: reveal its public key before estimation", reqEstimate.Source), http.StatusBadRequest)
+				return
+			}
+		}
+
+		contentReq, err := buildEstimatedContent(c.Request.Context(), ctx, state, req.Address, reqEstimate)
+		if handleError(c, ctx.Storage, err, http.StatusBadRequest) {
+			return
+		}
+		content, err := buildRunOperationContent(c.Request.Context(), ctx, state, reqEstimate.Source, contentReq)
+		if handleError(c, ctx.Storage, err, http.StatusBadRequest) {
+			return
+		}
+		counter, err := ctx.RPC.GetCounter(c, reqEstimate.Source)
+		if handleError(c, ctx.Storage, err, 0) {
+			return
+		}
+		content.Counter = counter + 1
+
+		response, err := ctx.RPC.RunOperations(c, state.Protocol.ChainID, state.Hash, []noderpc.RunOperationContent{content})
+		if err != nil {
+			var e noderpc.InvalidNodeResponse
+			if errors.As(err, &e) {
+				handleError(c, ctx.Storage, e, http.StatusBadRequest)
+				return
+			}
+			handleError(c, ctx.Storage, err, 0)
+			return
+		}
+		if len(response.Contents) != 1 {
+			handleError(c, ctx.Storage, errors.Errorf("invalid contents count in simulation: %d", len(response.Contents)), 0)
+			return
+		}
+
+		var data noderpc.Operation
+		if err := json.Unmarshal(response.Contents[0].Raw, &data); handleError(c, ctx.Storage, err, 0) {
+			return
+		}
+
+		estimation, err := estimateContent(state.Hash, content, data, constants)
+		if handleError(c, ctx.Storage, err, 0) {
+			return
+		}
+		c.SecureJSON(http.StatusOK, estimation)
+	}
+}
+
+func buildEstimatedContent(c context.Context, ctx *config.Context, state block.Block, address string, req estimateRequest) (runOperationsContent, error) {
+	content := runOperationsContent{
+		Kind:        req.Kind,
+		Destination: address,
+		Amount:      req.Amount,
+		Name:        req.Name,
+		Data:        req.Data,
+	}
+	switch req.Kind {
+	case "", consts.Transaction:
+		content.Kind = consts.Transaction
+		if req.Name == "" {
+			return content, errors.New("entrypoint name is required for transaction")
+		}
+	case consts.TransferTicket:
+		content.TicketContents = req.TicketContents
+		content.TicketType = req.TicketType
+		content.TicketTicketer = req.TicketTicketer
+		content.TicketAmount = req.TicketAmount
+	case consts.Origination:
+		content.Destination = ""
+		content.Name = ""
+		content.Amount = 0
+		content.Balance = req.Balance
+
+		script, err := buildOriginationScript(c, ctx, state.Protocol.SymLink, address, req.Data)
+		if err != nil {
+			return content, err
+		}
+		content.Script = script
+	}
+	return content, nil
+}
+
+func buildOriginationScript(c context.Context, ctx *config.Context, symLink, address string, data map[string]interface{}) (stdJSON.RawMessage, error) {
+	code, err := getScriptBytes(c, ctx.Cache, address, symLink)
+	if err != nil {
+		return nil, err
+	}
+
+	var storage []byte
+	if len(data) == 0 {
+		storage, err = ctx.RPC.GetScriptStorageRaw(c, address, 0)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		script, err := ast.NewScript(code)
+		if err != nil {
+			return nil, err
+		}
+		storageType, err := script.StorageType()
+		if err != nil {
+			return nil, err
+		}
+		if storageType.Nodes[0].IsPrim(consts.PAIR) {
+			data = map[string]interface{}{
+				storageType.Nodes[0].GetName(): data,
+			}
+		}
+		if err := storageType.FromJSONSchema(data); err != nil {
+			return nil, err
+		}
+		storage, err = storageType.ToParameters("")
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return json.Marshal(map[string]stdJSON.RawMessage{
+		"code":    code,
+		"storage": storage,
+	})
+}
+
+func estimateContent(branch string, content noderpc.RunOperationContent, data noderpc.Operation, constants noderpc.Constants) (EstimationResponse, error) {
+	simulated, err := newSimulatedContent(data)
+	if err != nil {
+		return EstimationResponse{}, err
+	}
+	estimation := EstimationResponse{
+		Status:              simulated.Status,
+		ConsumedGas:         simulated.ConsumedGas,
+		PaidStorageSizeDiff: simulated.PaidStorageSizeDiff,
+		Errors:              simulated.Errors,
+	}
+	if simulated.Status != consts.Applied {
+		return estimation, nil
+	}
+
+	estimation.GasLimit = (simulated.ConsumedGas+999)/1000 + gasLimitMargin
+	if estimation.GasLimit > constants.HardGasLimitPerOperation {
+		estimation.GasLimit = constants.HardGasLimitPerOperation
+	}
+
+	storageUsed := simulated.PaidStorageSizeDiff + allocationsCount(data)*constants.OriginationSize
+	estimation.Burn = storageUsed * constants.CostPerByte
+	if storageUsed > 0 {
+		estimation.StorageLimit = storageUsed + storageLimitMargin
+		if estimation.StorageLimit > constants.HardStorageLimitPerOperation {
+			estimation.StorageLimit = constants.HardStorageLimitPerOperation
+		}
+	}
+
+	content.GasLimit = estimation.GasLimit
+	content.StorageLimit = estimation.StorageLimit
+	estimation.Fee, estimation.Size, err = minimalFee(branch, content)
+	return estimation, err
+}
+
+// allocationsCount - count of originated contracts and allocated implicit accounts by operation and its internal operations. Each of them is paid as `origination_size` bytes.
+func allocationsCount(data noderpc.Operation) int64 {
+	operations := []noderpc.Operation{data}
+	if data.Metadata != nil {
+		operations = append(operations, data.Metadata.Internal...)
+	}
+
+	var count int64
+	for i := range operations {
+		result := operations[i].GetResult()
+		if result == nil {
+			continue
+		}
+		count += int64(len(result.Originated))
+		if result.AllocatedDestinationContract != nil && *result.AllocatedDestinationContract {
+			count++
+		}
+	}
+	return count
+}
+
+// minimalFee - returns minimal fee which is accepted by mempool and size of signed operation. Fee is a part of forged operation, so it's increased until size of operation with it is covered.
+func minimalFee(branch string, content noderpc.RunOperationContent) (int64, int64, error) {
+	raw, err := json.Marshal(content)
+	if err != nil {
+		return 0, 0, err
+	}
+	var forgeContent forgeOperations.Content
+	if err := json.Unmarshal(raw, &forgeContent); err != nil {
+		return 0, 0, err
+	}
+	operation := forgeOperations.Operation{
+		Branch:   branch,
+		Contents: []forgeOperations.Content{forgeContent},
+	}
+
+	var fee int64
+	for {
+		operation.Contents[0].Fee = strconv.FormatInt(fee, 10)
+		forged, err := forgeOperations.Forge(operation)
+		if err != nil {
+			return 0, 0, err
+		}
+		size := int64(len(forged) + signatureSize)
+
+		required := minimalFees + ceilDiv(minimalNanotezPerByte*size, 1000) + ceilDiv(minimalNanotezPerGasUnit*content.GasLimit, 1000)
+		if required <= fee {
+			return fee, size, nil
+		}
+		fee = required
+	}
+}
+
+func ceilDiv(a, b int64) int64 {
+	return (a + b - 1) / b
+}
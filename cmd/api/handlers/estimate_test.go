@@ -0,0 +1,154 @@
+package handlers
+
+import (
+	stdJSON "encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/baking-bad/bcdhub/cmd/api/validations"
+	"github.com/baking-bad/bcdhub/internal/bcd/consts"
+	"github.com/baking-bad/bcdhub/internal/config"
+	"github.com/baking-bad/bcdhub/internal/models/block"
+	"github.com/baking-bad/bcdhub/internal/models/mock"
+	mock_block "github.com/baking-bad/bcdhub/internal/models/mock/block"
+	"github.com/baking-bad/bcdhub/internal/models/types"
+	"github.com/baking-bad/bcdhub/internal/noderpc"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestEstimateContent(t *testing.T) {
+	const branch = "BKqoHEY3C15u8zdGwi9Hhj3ArCz2Q8sRQuHVtcWZqUPopsfNZfh"
+
+	constants := noderpc.Constants{
+		CostPerByte:                  250,
+		HardGasLimitPerOperation:     1040000,
+		HardStorageLimitPerOperation: 60000,
+		OriginationSize:              257,
+	}
+	amount := int64(0)
+	content := noderpc.RunOperationContent{
+		Kind:         consts.Transaction,
+		Source:       "tz1burnburnburnburnburnburnburjAYjjX",
+		Counter:      11,
+		GasLimit:     constants.HardGasLimitPerOperation,
+		StorageLimit: constants.HardStorageLimitPerOperation,
+		Destination:  "KT1FSSJtvHsRL3orgtj8mBi4EoaKJyStG2FF",
+		Amount:       &amount,
+		Parameters:   stdJSON.RawMessage(`{"entrypoint":"mint","value":{"int":"100"}}`),
+	}
+
+	tests := []struct {
+		name             string
+		data             string
+		wantStatus       string
+		wantGasLimit     int64
+		wantStorageLimit int64
+		wantBurn         int64
+	}{
+		{
+			name:             "applied with allocation",
+			data:             `{"kind":"transaction","metadata":{"operation_result":{"status":"applied","consumed_milligas":"2500100","paid_storage_size_diff":"67"},"internal_operation_results":[{"kind":"transaction","source":"KT1FSSJtvHsRL3orgtj8mBi4EoaKJyStG2FF","nonce":0,"amount":"10","destination":"tz1burnburnburnburnburnburnburjAYjjX","result":{"status":"applied","consumed_milligas":"100000","allocated_destination_contract":true}}]}}`,
+			wantStatus:       consts.Applied,
+			wantGasLimit:     2601 + gasLimitMargin,
+			wantStorageLimit: 67 + 257 + storageLimitMargin,
+			wantBurn:         (67 + 257) * 250,
+		}, {
+			name:         "applied without storage",
+			data:         `{"kind":"transaction","metadata":{"operation_result":{"status":"applied","consumed_milligas":"1000"}}}`,
+			wantStatus:   consts.Applied,
+			wantGasLimit: 1 + gasLimitMargin,
+		}, {
+			name:       "failed",
+			data:       `{"kind":"transaction","metadata":{"operation_result":{"status":"failed","errors":[{"kind":"temporary","id":"proto.020-PsParisC.michelson_v1.script_rejected","location":42,"with":{"string":"NOT_ALLOWED"}}]}}}`,
+			wantStatus: consts.Failed,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var data noderpc.Operation
+			require.NoError(t, json.Unmarshal([]byte(tt.data), &data))
+
+			estimation, err := estimateContent(branch, content, data, constants)
+			require.NoError(t, err)
+			require.Equal(t, tt.wantStatus, estimation.Status)
+			require.Equal(t, tt.wantGasLimit, estimation.GasLimit)
+			require.Equal(t, tt.wantStorageLimit, estimation.StorageLimit)
+			require.Equal(t, tt.wantBurn, estimation.Burn)
+
+			if tt.wantStatus != consts.Applied {
+				require.Len(t, estimation.Errors, 1)
+				require.Zero(t, estimation.Fee)
+				return
+			}
+			require.Positive(t, estimation.Size)
+			require.Equal(t, minimalFees+ceilDiv(minimalNanotezPerByte*estimation.Size, 1000)+ceilDiv(minimalNanotezPerGasUnit*estimation.GasLimit, 1000), estimation.Fee)
+		})
+	}
+}
+
+func registerTestValidations(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	v, ok := binding.Validator.Engine().(*validator.Validate)
+	require.True(t, ok)
+	require.NoError(t, validations.Register(v, config.APIConfig{Networks: []string{types.Mainnet.String()}}))
+}
+
+func serveEstimate(ctx *config.Context, body string) *httptest.ResponseRecorder {
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set("context", ctx)
+	})
+	router.POST("/v1/contract/:network/:address/entrypoints/estimate", Estimate())
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/contract/mainnet/KT1FSSJtvHsRL3orgtj8mBi4EoaKJyStG2FF/entrypoints/estimate", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestEstimate_errors(t *testing.T) {
+	registerTestValidations(t)
+
+	const (
+		source = "tz1burnburnburnburnburnburnburjAYjjX"
+		body   = `{"source":"` + source + `","name":"mint","data":{"mint":"100"}}`
+	)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	storage := mock.NewMockGeneralRepository(ctrl)
+	storage.EXPECT().IsRecordNotFound(gomock.Any()).Return(false).AnyTimes()
+
+	t.Run("without RPC", func(t *testing.T) {
+		w := serveEstimate(&config.Context{
+			Network: types.Mainnet,
+			Storage: storage,
+		}, body)
+		require.Equal(t, http.StatusBadRequest, w.Code)
+		require.Contains(t, w.Body.String(), "RPC is not set for mainnet")
+	})
+
+	t.Run("unrevealed source", func(t *testing.T) {
+		blocks := mock_block.NewMockRepository(ctrl)
+		blocks.EXPECT().Last(gomock.Any()).Return(block.Block{Level: 100}, nil)
+		rpc := noderpc.NewMockINode(ctrl)
+		rpc.EXPECT().GetNetworkConstants(gomock.Any(), int64(100)).Return(noderpc.Constants{}, nil)
+		rpc.EXPECT().GetManagerKey(gomock.Any(), source).Return("", nil)
+
+		w := serveEstimate(&config.Context{
+			Network: types.Mainnet,
+			Storage: storage,
+			Blocks:  blocks,
+			RPC:     rpc,
+		}, body)
+		require.Equal(t, http.StatusBadRequest, w.Code)
+		require.Contains(t, w.Body.String(), "isn't revealed")
+	})
+}
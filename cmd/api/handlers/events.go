@@ -4,6 +4,7 @@ import (
 	"net/http"
 
 	"github.com/baking-bad/bcdhub/internal/config"
+	"github.com/baking-bad/bcdhub/internal/models/operation"
 	"github.com/gin-gonic/gin"
 )
 
@@ -61,3 +62,119 @@ func ListEvents() gin.HandlerFunc {
 		c.SecureJSON(http.StatusOK, events)
 	}
 }
+
+// SearchEvents godoc
+// @Summary Search events
+// @Description Search events of all contracts in network. Payload is decoded to JSON with its type. Events are sorted by id in descending order, use `last_id` to get the next page.
+// @Tags operations
+// @ID search-events
+// @Param network path string true "Network"
+// @Param tag query string false "Event tag"
+// @Param emitter query string false "KT address of emitter" minlength(36) maxlength(36)
+// @Param type_hash query string false "Hash of payload type" minlength(64) maxlength(64)
+// @Param from_level query integer false "Lower bound of level range" mininum(0)
+// @Param to_level query integer false "Upper bound of level range" mininum(0)
+// @Param last_id query integer false "Last event id" mininum(0)
+// @Param size query integer false "Expected events count" mininum(1) maximum(10)
+// @Accept  json
+// @Produce  json
+// @Success 200 {array} NetworkEvent
+// @Failure 400 {object} Error
+// @Failure 404 {object} Error
+// @Failure 500 {object} Error
+// @Router /v1/events/{network} [get]
+func SearchEvents() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.MustGet("context").(*config.Context)
+
+		var args eventsRequest
+		if err := c.ShouldBindQuery(&args); handleError(c, ctx.Storage, err, http.StatusBadRequest) {
+			return
+		}
+
+		req := operation.EventsRequest{
+			Tag:       args.Tag,
+			TypeHash:  args.TypeHash,
+			FromLevel: args.FromLevel,
+			ToLevel:   args.ToLevel,
+			LastID:    args.LastID,
+			Limit:     args.Size,
+		}
+		if args.Emitter != "" {
+			emitter, err := ctx.Accounts.Get(c.Request.Context(), args.Emitter)
+			if handleError(c, ctx.Storage, err, 0) {
+				return
+			}
+			req.EmitterID = emitter.ID
+		}
+
+		operations, err := ctx.Operations.Events(c.Request.Context(), req)
+		if handleError(c, ctx.Storage, err, 0) {
+			return
+		}
+
+		events := make([]NetworkEvent, len(operations))
+		for i := range operations {
+			events[i], err = NewNetworkEvent(operations[i])
+			if handleError(c, ctx.Storage, err, 0) {
+				return
+			}
+		}
+		c.SecureJSON(http.StatusOK, events)
+	}
+}
+
+// ListEventTypes godoc
+// @Summary List event types
+// @Description List distinct emitter, tag and payload type of events with JSON schema of payload and count of events. Types are sorted by count of events in descending order.
+// @Tags operations
+// @ID list-event-types
+// @Param network path string true "Network"
+// @Param tag query string false "Event tag"
+// @Param emitter query string false "KT address of emitter" minlength(36) maxlength(36)
+// @Param offset query integer false "Offset" mininum(0)
+// @Param size query integer false "Expected types count" mininum(1) maximum(10)
+// @Accept  json
+// @Produce  json
+// @Success 200 {array} EventType
+// @Failure 400 {object} Error
+// @Failure 404 {object} Error
+// @Failure 500 {object} Error
+// @Router /v1/events/{network}/types [get]
+func ListEventTypes() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.MustGet("context").(*config.Context)
+
+		var args eventTypesRequest
+		if err := c.ShouldBindQuery(&args); handleError(c, ctx.Storage, err, http.StatusBadRequest) {
+			return
+		}
+
+		req := operation.EventTypesRequest{
+			Tag:    args.Tag,
+			Limit:  args.Size,
+			Offset: args.Offset,
+		}
+		if args.Emitter != "" {
+			emitter, err := ctx.Accounts.Get(c.Request.Context(), args.Emitter)
+			if handleError(c, ctx.Storage, err, 0) {
+				return
+			}
+			req.EmitterID = emitter.ID
+		}
+
+		eventTypes, err := ctx.Operations.EventTypes(c.Request.Context(), req)
+		if handleError(c, ctx.Storage, err, 0) {
+			return
+		}
+
+		response := make([]EventType, len(eventTypes))
+		for i := range eventTypes {
+			response[i], err = NewEventType(eventTypes[i])
+			if handleError(c, ctx.Storage, err, 0) {
+				return
+			}
+		}
+		c.SecureJSON(http.StatusOK, response)
+	}
+}
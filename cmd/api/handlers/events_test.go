@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"testing"
+
+	"github.com/baking-bad/bcdhub/internal/models/account"
+	"github.com/baking-bad/bcdhub/internal/models/operation"
+	"github.com/baking-bad/bcdhub/internal/models/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewNetworkEvent(t *testing.T) {
+	payloadType := []byte(`{"prim":"pair","args":[{"prim":"address","annots":["%owner"]},{"prim":"nat","annots":["%amount"]}]}`)
+	payload := []byte(`{"prim":"Pair","args":[{"string":"tz1gjaF81ZRRvdzjobyfVNsAeSC6PScjfQwN"},{"int":"42"}]}`)
+
+	event, err := NewNetworkEvent(operation.Operation{
+		ID:          10,
+		Level:       100,
+		Status:      types.OperationStatusApplied,
+		Source:      account.Account{Address: "KT1ThEdxfUcWUwqsdergy3QnbCWGHSUHeHJq"},
+		Tag:         newNullString("transfer"),
+		PayloadType: payloadType,
+		Payload:     payload,
+	})
+	require.NoError(t, err)
+	require.Equal(t, "KT1ThEdxfUcWUwqsdergy3QnbCWGHSUHeHJq", event.Emitter)
+	require.Equal(t, "transfer", event.Tag)
+	require.Equal(t, "applied", event.Status)
+	require.Equal(t, operation.PayloadTypeHash(payloadType), event.TypeHash)
+	require.Len(t, event.TypeHash, 64)
+
+	decoded, err := json.Marshal(event.Payload)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"owner":"tz1gjaF81ZRRvdzjobyfVNsAeSC6PScjfQwN","amount":"42"}`, string(decoded))
+}
+
+func TestNewNetworkEventWithoutPayload(t *testing.T) {
+	event, err := NewNetworkEvent(operation.Operation{
+		Source: account.Account{Address: "KT1ThEdxfUcWUwqsdergy3QnbCWGHSUHeHJq"},
+	})
+	require.NoError(t, err)
+	require.Empty(t, event.TypeHash)
+	require.Nil(t, event.Payload)
+}
+
+func TestNewEventType(t *testing.T) {
+	payloadType := []byte(`{"prim":"nat"}`)
+
+	eventType, err := NewEventType(operation.EventType{
+		Emitter:     "KT1ThEdxfUcWUwqsdergy3QnbCWGHSUHeHJq",
+		Tag:         newNullString("mint"),
+		PayloadType: payloadType,
+		Count:       3,
+		FirstLevel:  10,
+		LastLevel:   20,
+	})
+	require.NoError(t, err)
+	require.Equal(t, "mint", eventType.Tag)
+	require.Equal(t, int64(3), eventType.Count)
+	require.Equal(t, operation.PayloadTypeHash(payloadType), eventType.TypeHash)
+	require.NotNil(t, eventType.Schema)
+}
+
+func newNullString(value string) types.NullString {
+	return types.NewNullString(&value)
+}
@@ -0,0 +1,184 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/baking-bad/bcdhub/internal/config"
+	"github.com/baking-bad/bcdhub/internal/export"
+	"github.com/baking-bad/bcdhub/internal/models/bigmapdiff"
+	"github.com/baking-bad/bcdhub/internal/models/ticket"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+// ExportOperations godoc
+// @Summary Export account operations
+// @Description Streams all operations where account is source, initiator, destination or delegate with decoded parameters. Operations are sorted from oldest to newest.
+// @Tags export
+// @ID export-operations
+// @Param network path string true "Network"
+// @Param address path string true "Address" minlength(36) maxlength(36)
+// @Param format query string false "Output format: csv (default) or ndjson"
+// @Param kind query string false "Comma-separated operation kinds: transaction, origination, delegation, etc."
+// @Param status query string false "Comma-separated operation statuses: applied, failed, backtracked, skipped"
+// @Param entrypoint query string false "Comma-separated entrypoints"
+// @Param from query integer false "Timestamp (unix seconds) of the beginning of time range, inclusive"
+// @Param to query integer false "Timestamp (unix seconds) of the end of time range, exclusive"
+// @Param counterparty query string false "Address of another participant of operation" minlength(36) maxlength(36)
+// @Produce text/csv
+// @Produce application/x-ndjson
+// @Success 200 {object} export.OperationRow
+// @Failure 400 {object} Error
+// @Failure 404 {object} Error
+// @Failure 500 {object} Error
+// @Router /v1/export/{network}/operations/{address} [get]
+func ExportOperations() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.MustGet("context").(*config.Context)
+
+		var req getAccountRequest
+		if err := c.ShouldBindUri(&req); handleError(c, ctx.Storage, err, http.StatusNotFound) {
+			return
+		}
+
+		var args exportOperationsRequest
+		if err := c.ShouldBindQuery(&args); handleError(c, ctx.Storage, err, http.StatusBadRequest) {
+			return
+		}
+
+		acc, err := ctx.Accounts.Get(c.Request.Context(), req.Address)
+		if handleError(c, ctx.Storage, err, 0) {
+			return
+		}
+
+		historyReq := newHistoryRequest(args.accountOperationsRequest)
+		historyReq.LastID = 0
+		if args.Counterparty != "" {
+			counterparty, err := ctx.Accounts.Get(c.Request.Context(), args.Counterparty)
+			if handleError(c, ctx.Storage, err, 0) {
+				return
+			}
+			historyReq.CounterpartyID = counterparty.ID
+		}
+
+		writer, ok := startExport(c, ctx, args.exportRequest, fmt.Sprintf("operations_%s", req.Address))
+		if !ok {
+			return
+		}
+		if err := export.Operations(c.Request.Context(), ctx, acc.ID, historyReq, writer); err != nil {
+			log.Err(err).Str("network", ctx.Network.String()).Str("address", req.Address).Msg("export operations")
+		}
+	}
+}
+
+// ExportBigMapKeys godoc
+// @Summary Export big map keys
+// @Description Streams current state of all big map keys with decoded keys and values
+// @Tags export
+// @ID export-bigmap-keys
+// @Param network path string true "Network"
+// @Param ptr path integer true "Big map pointer"
+// @Param format query string false "Output format: csv (default) or ndjson"
+// @Param max_level query integer false "Max level filter" minimum(0)
+// @Param min_level query integer false "Min level filter" minimum(0)
+// @Produce text/csv
+// @Produce application/x-ndjson
+// @Success 200 {object} export.BigMapKeyRow
+// @Failure 400 {object} Error
+// @Failure 500 {object} Error
+// @Router /v1/export/{network}/bigmap/{ptr}/keys [get]
+func ExportBigMapKeys() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.MustGet("context").(*config.Context)
+
+		var req getBigMapRequest
+		if err := c.ShouldBindUri(&req); handleError(c, ctx.Storage, err, http.StatusBadRequest) {
+			return
+		}
+
+		var args exportBigMapKeysRequest
+		if err := c.ShouldBindQuery(&args); handleError(c, ctx.Storage, err, http.StatusBadRequest) {
+			return
+		}
+
+		writer, ok := startExport(c, ctx, args.exportRequest, fmt.Sprintf("bigmap_%d_keys", req.Ptr))
+		if !ok {
+			return
+		}
+		if err := export.BigMapKeys(c.Request.Context(), ctx, bigmapdiff.GetContext{
+			Ptr:      &req.Ptr,
+			MaxLevel: args.MaxLevel,
+			MinLevel: args.MinLevel,
+		}, writer); err != nil {
+			log.Err(err).Str("network", ctx.Network.String()).Int64("ptr", req.Ptr).Msg("export big map keys")
+		}
+	}
+}
+
+// ExportTicketUpdates godoc
+// @Summary Export ticket updates
+// @Description Streams all ticket updates of ticketer with decoded ticket contents
+// @Tags export
+// @ID export-ticket-updates
+// @Param network path string true "Network"
+// @Param address path string true "KT address of ticketer" minlength(36) maxlength(36)
+// @Param format query string false "Output format: csv (default) or ndjson"
+// @Param account query string false "Address of ticket holder" minlength(36) maxlength(36)
+// @Param ticket_id query integer false "Ticket ID"
+// @Produce text/csv
+// @Produce application/x-ndjson
+// @Success 200 {object} export.TicketUpdateRow
+// @Failure 400 {object} Error
+// @Failure 404 {object} Error
+// @Failure 500 {object} Error
+// @Router /v1/export/{network}/ticket_updates/{address} [get]
+func ExportTicketUpdates() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.MustGet("context").(*config.Context)
+
+		var req getContractRequest
+		if err := c.ShouldBindUri(&req); handleError(c, ctx.Storage, err, http.StatusNotFound) {
+			return
+		}
+
+		var args exportTicketUpdatesRequest
+		if err := c.ShouldBindQuery(&args); handleError(c, ctx.Storage, err, http.StatusBadRequest) {
+			return
+		}
+
+		if _, err := ctx.Accounts.Get(c.Request.Context(), req.Address); handleError(c, ctx.Storage, err, 0) {
+			return
+		}
+
+		writer, ok := startExport(c, ctx, args.exportRequest, fmt.Sprintf("ticket_updates_%s", req.Address))
+		if !ok {
+			return
+		}
+		if err := export.TicketUpdates(c.Request.Context(), ctx, ticket.UpdatesRequest{
+			Ticketer: req.Address,
+			Account:  args.Account,
+			TicketId: args.TicketId,
+		}, writer); err != nil {
+			log.Err(err).Str("network", ctx.Network.String()).Str("address", req.Address).Msg("export ticket updates")
+		}
+	}
+}
+
+// startExport - writes response headers and returns writer of requested format. Errors which occur after this point can't be returned to client, so they are logged only.
+func startExport(c *gin.Context, ctx *config.Context, req exportRequest, name string) (export.Writer, bool) {
+	format := export.Format(req.Format)
+	if format == "" {
+		format = export.FormatCSV
+	}
+
+	writer, err := export.NewWriter(format, c.Writer)
+	if handleError(c, ctx.Storage, err, http.StatusBadRequest) {
+		return nil, false
+	}
+
+	c.Header("Content-Type", format.ContentType())
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s_%s.%s"`, ctx.Network.String(), name, format))
+	c.Status(http.StatusOK)
+	return writer, true
+}
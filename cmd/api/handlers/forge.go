@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"encoding/hex"
+	"net/http"
+
+	"github.com/baking-bad/bcdhub/internal/bcd/forge/operations"
+	"github.com/baking-bad/bcdhub/internal/config"
+	"github.com/gin-gonic/gin"
+)
+
+// ForgeOperation godoc
+// @Summary Forge operation group
+// @Description Forge operation group to bytes as `forge/operations` RPC does. If signature is passed it's appended to bytes and hash of operation group is returned. `smart_rollup_refute` isn't supported: request with it is answered with 400.
+// @Tags operations
+// @ID forge-operation
+// @Param body body operations.Operation true "Operation group"
+// @Accept  json
+// @Produce  json
+// @Success 200 {object} ForgedOperation
+// @Failure 400 {object} Error
+// @Failure 500 {object} Error
+// @Router /v1/forge [post]
+func ForgeOperation(ctxs config.Contexts) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req operations.Operation
+		if err := c.ShouldBindJSON(&req); handleError(c, ctxs.Any().Storage, err, http.StatusBadRequest) {
+			return
+		}
+
+		data, err := operations.Forge(req)
+		if handleError(c, ctxs.Any().Storage, err, http.StatusBadRequest) {
+			return
+		}
+
+		response := ForgedOperation{
+			Bytes: hex.EncodeToString(data),
+		}
+		if req.Signature != "" {
+			response.Hash, err = operations.Hash(data)
+			if handleError(c, ctxs.Any().Storage, err, 0) {
+				return
+			}
+		}
+		c.SecureJSON(http.StatusOK, response)
+	}
+}
+
+// UnforgeOperation godoc
+// @Summary Unforge operation group
+// @Description Decode forged operation group. Bytes may be signed: in that case signature and hash of operation group are returned. `smart_rollup_refute` isn't supported: bytes containing it are answered with 400.
+// @Tags operations
+// @ID unforge-operation
+// @Param body body unforgeRequest true "Forged operation group"
+// @Accept  json
+// @Produce  json
+// @Success 200 {object} UnforgedOperation
+// @Failure 400 {object} Error
+// @Failure 500 {object} Error
+// @Router /v1/unforge [post]
+func UnforgeOperation(ctxs config.Contexts) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req unforgeRequest
+		if err := c.ShouldBindJSON(&req); handleError(c, ctxs.Any().Storage, err, http.StatusBadRequest) {
+			return
+		}
+
+		data, err := hex.DecodeString(req.Bytes)
+		if handleError(c, ctxs.Any().Storage, err, http.StatusBadRequest) {
+			return
+		}
+		operation, err := operations.Unforge(data)
+		if handleError(c, ctxs.Any().Storage, err, http.StatusBadRequest) {
+			return
+		}
+
+		response := UnforgedOperation{
+			Operation: operation,
+		}
+		if operation.Signature != "" {
+			response.Hash, err = operations.Hash(data)
+			if handleError(c, ctxs.Any().Storage, err, 0) {
+				return
+			}
+		}
+		c.SecureJSON(http.StatusOK, response)
+	}
+}
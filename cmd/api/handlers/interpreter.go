@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"context"
+
+	"github.com/baking-bad/bcdhub/internal/bcd/ast"
+	"github.com/baking-bad/bcdhub/internal/bcd/base"
+	"github.com/baking-bad/bcdhub/internal/bcd/interpreter"
+	"github.com/baking-bad/bcdhub/internal/config"
+	"github.com/baking-bad/bcdhub/internal/models/block"
+	"github.com/baking-bad/bcdhub/internal/models/types"
+	"github.com/pkg/errors"
+)
+
+// chainState - indexed state of network which is used by interpreter. Balances of contracts aren't indexed, so they are zero.
+type chainState struct {
+	ctx     *config.Context
+	symLink string
+}
+
+// BigMapValue -
+func (cs chainState) BigMapValue(c context.Context, ptr int64, keyHash string) (*base.Node, error) {
+	state, err := cs.ctx.BigMapDiffs.Current(c, keyHash, ptr)
+	if err != nil {
+		if cs.ctx.Storage.IsRecordNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if state.Removed || len(state.Value) == 0 {
+		return nil, nil
+	}
+	var value base.Node
+	if err := json.Unmarshal(state.Value, &value); err != nil {
+		return nil, err
+	}
+	return &value, nil
+}
+
+// Contract -
+func (cs chainState) Contract(c context.Context, address string) (interpreter.ContractState, error) {
+	var state interpreter.ContractState
+
+	data, err := getScriptBytes(c, cs.ctx.Cache, address, cs.symLink)
+	if err != nil {
+		if cs.ctx.Storage.IsRecordNotFound(err) {
+			return state, errors.Wrap(interpreter.ErrUnknownContract, address)
+		}
+		return state, err
+	}
+	if state.Script, err = ast.NewScript(data); err != nil {
+		return state, err
+	}
+
+	storage, err := cs.storage(c, address)
+	if err != nil {
+		return state, err
+	}
+	state.Storage = new(base.Node)
+	if err := json.Unmarshal(storage, state.Storage); err != nil {
+		return state, err
+	}
+	return state, nil
+}
+
+// storage - returns current storage of contract. It's taken from the last applied operation, node is requested only if there isn't such operation.
+func (cs chainState) storage(c context.Context, address string) ([]byte, error) {
+	account, err := cs.ctx.Accounts.Get(c, address)
+	if err != nil {
+		if cs.ctx.Storage.IsRecordNotFound(err) {
+			return nil, errors.Wrap(interpreter.ErrUnknownContract, address)
+		}
+		return nil, err
+	}
+	operation, err := cs.ctx.Operations.Last(c, map[string]interface{}{
+		"destination_id": account.ID,
+		"status":         types.OperationStatusApplied,
+	}, 0)
+	if err != nil && !cs.ctx.Storage.IsRecordNotFound(err) {
+		return nil, err
+	}
+	if len(operation.DeffatedStorage) > 0 {
+		return operation.DeffatedStorage, nil
+	}
+	if cs.ctx.RPC == nil {
+		return nil, errors.Errorf("storage of %s isn't indexed", address)
+	}
+	return cs.ctx.RPC.GetScriptStorageRaw(c, address, 0)
+}
+
+// isOffline - code is executed by interpreter if it's requested or node isn't configured
+func isOffline(ctx *config.Context, offline bool) bool {
+	return offline || ctx.RPC == nil
+}
+
+// newInterpreterNode - creates node which executes code of `address` by interpreter in context of the last indexed block
+func newInterpreterNode(ctx *config.Context, state block.Block, address string, opts ...interpreter.Option) *interpreter.Node {
+	env := interpreter.Environment{
+		Self:      address,
+		ChainID:   state.Protocol.ChainID,
+		Level:     state.Level,
+		Timestamp: state.Timestamp,
+	}
+	if state.Protocol.Constants != nil {
+		env.MinBlockTime = state.Protocol.TimeBetweenBlocks
+	}
+	return interpreter.NewNode(chainState{
+		ctx:     ctx,
+		symLink: state.Protocol.SymLink,
+	}, ctx.RPC, env, opts...)
+}
@@ -2,8 +2,11 @@ package handlers
 
 import (
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/baking-bad/bcdhub/internal/config"
+	"github.com/baking-bad/bcdhub/internal/metrics"
 	"github.com/baking-bad/bcdhub/internal/models/types"
 	"github.com/gin-gonic/gin"
 )
@@ -52,3 +55,21 @@ func ContextsMiddleware(ctxs config.Contexts) gin.HandlerFunc {
 		c.Next()
 	}
 }
+
+// MetricsMiddleware - measures latency of requests per route
+func MetricsMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unknown"
+		}
+		metrics.ObserveSince(
+			metrics.APIRequestDuration.WithLabelValues(c.Request.Method, route, strconv.Itoa(c.Writer.Status())),
+			start,
+		)
+	}
+}
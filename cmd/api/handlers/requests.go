@@ -1,10 +1,13 @@
 package handlers
 
 import (
+	stdJSON "encoding/json"
 	"strings"
 
 	"github.com/baking-bad/bcdhub/internal/models/contract"
+	smartrollup "github.com/baking-bad/bcdhub/internal/models/smart_rollup"
 	"github.com/baking-bad/bcdhub/internal/models/types"
+	"github.com/shopspring/decimal"
 )
 
 type getAccountRequest struct {
@@ -29,8 +32,11 @@ func (req getContractRequest) NetworkID() types.Network {
 
 type getContractCodeRequest struct {
 	getContractRequest
-	Protocol string `form:"protocol,omitempty"`
-	Level    int64  `form:"level,omitempty"`
+	Protocol        string `form:"protocol,omitempty"`
+	Level           int64  `form:"level,omitempty"`
+	Macros          bool   `form:"macros,omitempty"`
+	ExpandConstants bool   `form:"expand_constants,omitempty"`
+	Format          string `binding:"omitempty,oneof=michelson micheline" form:"format,omitempty"`
 }
 
 type withStatsRequest struct {
@@ -42,18 +48,31 @@ func (req withStatsRequest) HasStats() bool {
 	return req.Stats == nil || *req.Stats
 }
 
-// CodeDiffLeg -
+// CodeDiffLeg - contract script which is compared. Protocol is a symbolic link of script version: alpha, babylon or jakarta.
 type CodeDiffLeg struct {
-	Address  string        `binding:"required,address" json:"address"`
-	Network  types.Network `binding:"required,network" json:"network"`
-	Protocol string        `json:"protocol,omitempty"`
-	Level    int64         `json:"level,omitempty"`
+	Address  string `json:"address"`
+	Network  string `json:"network"`
+	Protocol string `json:"protocol"`
 }
 
-// CodeDiffRequest -
-type CodeDiffRequest struct {
-	Left  CodeDiffLeg `binding:"required" json:"left"`
-	Right CodeDiffLeg `binding:"required" json:"right"`
+type codeDiffRequest struct {
+	Left  string `binding:"required" form:"left"`
+	Right string `binding:"required" form:"right"`
+}
+
+type searchRequest struct {
+	pageableRequest
+
+	Text     string   `binding:"required,search"            form:"q"`
+	Networks []string `binding:"omitempty,dive,network"     form:"network"`
+	Types    []string `binding:"omitempty,dive,search_type" form:"type"`
+}
+
+type streamRequest struct {
+	Destination string `binding:"omitempty,address"    form:"destination"`
+	Entrypoint  string `binding:"omitempty,entrypoint" form:"entrypoint"`
+	Tag         string `binding:"omitempty,tag"        form:"tag"`
+	Status      string `binding:"omitempty,status"     form:"status"`
 }
 
 type getBigMapRequest struct {
@@ -88,6 +107,17 @@ type opgForAddressRequest struct {
 	Size   int64 `binding:"min=0,bcd_max_size=10" form:"size"`
 }
 
+type accountOperationsRequest struct {
+	LastID       int64  `binding:"omitempty,min=0"           form:"last_id"`
+	Size         int64  `binding:"min=0,bcd_max_size=10"     form:"size"`
+	Kind         string `binding:"omitempty,operation_kind"  form:"kind"`
+	Status       string `binding:"omitempty,status"          form:"status"`
+	Entrypoint   string `binding:"omitempty"                 form:"entrypoint"`
+	From         int64  `binding:"omitempty,min=0"           form:"from"`
+	To           int64  `binding:"omitempty,min=0"           form:"to"`
+	Counterparty string `binding:"omitempty,address"         form:"counterparty"`
+}
+
 type pageableRequest struct {
 	Offset int64 `binding:"min=0"                 form:"offset"`
 	Size   int64 `binding:"min=0,bcd_max_size=10" form:"size"`
@@ -135,6 +165,32 @@ type bigMapSearchRequest struct {
 	MinLevel *int64 `binding:"omitempty"                       form:"min_level,omitempty"`
 }
 
+type bigMapKeysRequest struct {
+	bigMapSearchRequest
+	Level *int64 `binding:"omitempty,min=1" form:"level,omitempty"`
+}
+
+type exportRequest struct {
+	Format string `binding:"omitempty,oneof=csv ndjson" form:"format"`
+}
+
+type exportOperationsRequest struct {
+	exportRequest
+	accountOperationsRequest
+}
+
+type exportBigMapKeysRequest struct {
+	exportRequest
+	MaxLevel *int64 `binding:"omitempty,gt_int64_ptr=MinLevel" form:"max_level,omitempty"`
+	MinLevel *int64 `binding:"omitempty"                       form:"min_level,omitempty"`
+}
+
+type exportTicketUpdatesRequest struct {
+	exportRequest
+	Account  string  `binding:"omitempty,address" form:"account"`
+	TicketId *uint64 `binding:"omitempty"         form:"ticket_id"`
+}
+
 type opgRequest struct {
 	WithStorageDiff bool `binding:"omitempty" form:"with_storage_diff"`
 }
@@ -156,6 +212,46 @@ type runOperationRequest struct {
 	Source string                 `binding:"omitempty,address" json:"source,omitempty"`
 }
 
+type runOperationsRequest struct {
+	Source   string                 `binding:"required,address"    json:"source"`
+	Contents []runOperationsContent `binding:"required,min=1,dive" json:"contents"`
+}
+
+type runOperationsContent struct {
+	Kind         string                 `binding:"required,oneof=transaction origination delegation transfer_ticket" json:"kind"`
+	Fee          int64                  `binding:"omitempty,min=0"                                   json:"fee,omitempty"`
+	GasLimit     int64                  `binding:"omitempty,min=0"                                   json:"gas_limit,omitempty"`
+	StorageLimit int64                  `binding:"omitempty,min=0"                                   json:"storage_limit,omitempty"`
+	Destination  string                 `binding:"omitempty,address"                                 json:"destination,omitempty"`
+	Amount       int64                  `binding:"omitempty,min=0"                                   json:"amount,omitempty"`
+	Name         string                 `json:"name,omitempty"`
+	Data         map[string]interface{} `json:"data,omitempty"`
+	Parameters   stdJSON.RawMessage     `json:"parameters,omitempty"`
+	Balance      int64                  `binding:"omitempty,min=0"                                   json:"balance,omitempty"`
+	Script       stdJSON.RawMessage     `json:"script,omitempty"`
+	Delegate     string                 `binding:"omitempty,address"                                 json:"delegate,omitempty"`
+
+	TicketContents stdJSON.RawMessage `json:"ticket_contents,omitempty"`
+	TicketType     stdJSON.RawMessage `json:"ticket_ty,omitempty"`
+	TicketTicketer string             `binding:"omitempty,address" json:"ticket_ticketer,omitempty"`
+	TicketAmount   int64              `binding:"omitempty,min=0"   json:"ticket_amount,omitempty"`
+}
+
+type estimateRequest struct {
+	Kind   string                 `binding:"omitempty,oneof=transaction origination transfer_ticket" json:"kind,omitempty"`
+	Source string                 `binding:"required,address"                                        json:"source"`
+	Name   string                 `json:"name,omitempty"`
+	Data   map[string]interface{} `json:"data,omitempty"`
+	Amount int64                  `binding:"omitempty,min=0"                                         json:"amount,omitempty"`
+
+	Balance int64 `binding:"omitempty,min=0" json:"balance,omitempty"`
+
+	TicketContents stdJSON.RawMessage `json:"ticket_contents,omitempty"`
+	TicketType     stdJSON.RawMessage `json:"ticket_ty,omitempty"`
+	TicketTicketer string             `binding:"omitempty,address" json:"ticket_ticketer,omitempty"`
+	TicketAmount   int64              `binding:"omitempty,min=0"   json:"ticket_amount,omitempty"`
+}
+
 type runCodeRequest struct {
 	Data     map[string]interface{} `binding:"required"          json:"data"`
 	Name     string                 `binding:"required"          json:"name"`
@@ -163,6 +259,7 @@ type runCodeRequest struct {
 	GasLimit int64                  `json:"gas_limit,omitempty"`
 	Source   string                 `binding:"omitempty,address" json:"source,omitempty"`
 	Sender   string                 `binding:"omitempty,address" json:"sender,omitempty"`
+	Offline  bool                   `json:"offline,omitempty"`
 }
 
 type storageSchemaRequest struct {
@@ -189,6 +286,10 @@ func (req forkRequest) NetworkID() types.Network {
 	return types.NewNetwork(req.Network)
 }
 
+type unforgeRequest struct {
+	Bytes string `binding:"required" json:"bytes"`
+}
+
 type storageRequest struct {
 	Level int `binding:"omitempty,gte=1" form:"level"`
 }
@@ -217,6 +318,7 @@ type executeViewRequest struct {
 	Source         string                       `binding:"omitempty,address"          json:"source,omitempty"`
 	Sender         string                       `binding:"omitempty,address"          json:"sender,omitempty"`
 	View           *contract.ViewImplementation `binding:"required_if=Kind off-chain" json:"view,omitempty"`
+	Offline        bool                         `json:"offline,omitempty"`
 }
 
 type getGlobalConstantRequest struct {
@@ -253,6 +355,14 @@ type getSmartRollupRequest struct {
 	Address string `binding:"required,smart_rollup" uri:"address"`
 }
 
+func (req smartRollupListRequest) toListRequest() smartrollup.ListRequest {
+	return smartrollup.ListRequest{
+		Limit:  req.Size,
+		Offset: req.Offset,
+		Sort:   req.Sort,
+	}
+}
+
 type ticketBalancesRequest struct {
 	pageableRequest
 	WithoutZeroBalances bool `binding:"omitempty" form:"skip_empty"`
@@ -264,3 +374,73 @@ type ticketUpdatesRequest struct {
 	Account  string  `binding:"omitempty,address" form:"account"`
 	TicketId *uint64 `binding:"omitempty"         form:"ticket_id"`
 }
+
+type statsSeriesRequest struct {
+	Metric   string `binding:"required,oneof=operations callers gas burned originations events" form:"metric"`
+	Bucket   string `binding:"omitempty,oneof=hour day"                                          form:"bucket"`
+	Contract string `binding:"omitempty,contract"                                                form:"contract"`
+	Kind     string `binding:"omitempty,operation_kind"                                          form:"kind"`
+	Status   string `binding:"omitempty,oneof=applied backtracked failed skipped"                form:"status"`
+	From     int64  `binding:"omitempty,min=0"                                                   form:"from"`
+	To       int64  `binding:"omitempty,min=0"                                                   form:"to"`
+}
+
+type eventsRequest struct {
+	Tag       string `binding:"omitempty"                    form:"tag"`
+	Emitter   string `binding:"omitempty,contract"           form:"emitter"`
+	TypeHash  string `binding:"omitempty,len=64,hexadecimal" form:"type_hash"`
+	FromLevel int64  `binding:"omitempty,min=0"              form:"from_level"`
+	ToLevel   int64  `binding:"omitempty,min=0"              form:"to_level"`
+	LastID    int64  `binding:"omitempty,min=0"              form:"last_id"`
+	Size      int64  `binding:"min=0,bcd_max_size=10"        form:"size"`
+}
+
+type eventTypesRequest struct {
+	pageableRequest
+
+	Tag     string `binding:"omitempty"          form:"tag"`
+	Emitter string `binding:"omitempty,contract" form:"emitter"`
+}
+
+type getTicketRequest struct {
+	Hash string `binding:"required,len=64,hexadecimal" uri:"hash"`
+}
+
+type ticketSupplyRequest struct {
+	pageableRequest
+
+	FromLevel int64 `binding:"omitempty,min=0" form:"from_level"`
+	ToLevel   int64 `binding:"omitempty,min=0" form:"to_level"`
+}
+
+type ticketFlowRequest struct {
+	FromLevel int64 `binding:"required,min=1"                    form:"from_level"`
+	ToLevel   int64 `binding:"required,min=1,gtefield=FromLevel" form:"to_level"`
+}
+
+type tokenPageableRequest struct {
+	pageableRequest
+
+	TokenId string `binding:"omitempty,number" form:"token_id"`
+}
+
+// tokenID - returns nil if token id isn't set. Token id is validated by binding, so it's always decimal.
+func (req tokenPageableRequest) tokenID() *decimal.Decimal {
+	if req.TokenId == "" {
+		return nil
+	}
+	id := decimal.RequireFromString(req.TokenId)
+	return &id
+}
+
+type tokenTransfersRequest struct {
+	tokenPageableRequest
+
+	Account string `binding:"omitempty,address" form:"account"`
+}
+
+type tokenBalancesRequest struct {
+	pageableRequest
+
+	WithoutZeroBalances bool `binding:"omitempty" form:"skip_empty"`
+}
@@ -5,19 +5,25 @@ import (
 	stdJSON "encoding/json"
 	"time"
 
+	"github.com/baking-bad/bcdhub/internal/bcd"
 	"github.com/baking-bad/bcdhub/internal/bcd/ast"
 	"github.com/baking-bad/bcdhub/internal/bcd/encoding"
+	"github.com/baking-bad/bcdhub/internal/bcd/forge/operations"
 	"github.com/baking-bad/bcdhub/internal/bcd/formatter"
 	"github.com/baking-bad/bcdhub/internal/bcd/tezerrors"
 	"github.com/baking-bad/bcdhub/internal/models/account"
 	"github.com/baking-bad/bcdhub/internal/models/block"
 	"github.com/baking-bad/bcdhub/internal/models/contract"
+	contractmetadata "github.com/baking-bad/bcdhub/internal/models/contract_metadata"
 	"github.com/baking-bad/bcdhub/internal/models/operation"
 	"github.com/baking-bad/bcdhub/internal/models/protocol"
+	"github.com/baking-bad/bcdhub/internal/models/search"
 	smartrollup "github.com/baking-bad/bcdhub/internal/models/smart_rollup"
 	"github.com/baking-bad/bcdhub/internal/models/stats"
 	"github.com/baking-bad/bcdhub/internal/models/ticket"
+	"github.com/baking-bad/bcdhub/internal/models/token"
 	"github.com/baking-bad/bcdhub/internal/models/types"
+	"github.com/baking-bad/bcdhub/internal/noderpc"
 )
 
 // Error -
@@ -27,43 +33,44 @@ type Error struct {
 
 // Operation -
 type Operation struct {
-	ID                                 int64              `extensions:"x-nullable"     json:"id,omitempty"`
-	Level                              int64              `extensions:"x-nullable"     json:"level,omitempty"`
-	Fee                                int64              `extensions:"x-nullable"     json:"fee,omitempty"`
-	Counter                            int64              `extensions:"x-nullable"     json:"counter,omitempty"`
-	GasLimit                           int64              `extensions:"x-nullable"     json:"gas_limit,omitempty"`
-	StorageLimit                       int64              `extensions:"x-nullable"     json:"storage_limit,omitempty"`
-	Amount                             int64              `extensions:"x-nullable"     json:"amount,omitempty"`
-	Balance                            int64              `extensions:"x-nullable"     json:"balance,omitempty"`
-	Burned                             int64              `extensions:"x-nullable"     json:"burned,omitempty"`
-	AllocatedDestinationContractBurned int64              `extensions:"x-nullable"     json:"allocated_destination_contract_burned,omitempty"`
-	IndexedTime                        int64              `json:"-"`
-	ContentIndex                       int64              `json:"content_index"`
-	ConsumedGas                        int64              `example:"100"               extensions:"x-nullable"                                json:"consumed_gas,omitempty"`
-	StorageSize                        int64              `example:"200"               extensions:"x-nullable"                                json:"storage_size,omitempty"`
-	PaidStorageSizeDiff                int64              `example:"300"               extensions:"x-nullable"                                json:"paid_storage_size_diff,omitempty"`
-	TicketUpdatesCount                 int                `json:"ticket_updates_count"`
-	BigMapDiffsCount                   int                `json:"big_map_diffs_count"`
-	Errors                             []*tezerrors.Error `extensions:"x-nullable"     json:"errors,omitempty"`
-	Parameters                         interface{}        `extensions:"x-nullable"     json:"parameters,omitempty"`
-	StorageDiff                        *ast.MiguelNode    `extensions:"x-nullable"     json:"storage_diff,omitempty"`
-	Payload                            []*ast.MiguelNode  `extensions:"x-nullable"     json:"payload,omitempty"`
-	Timestamp                          time.Time          `json:"timestamp"`
-	Protocol                           string             `json:"protocol"`
-	Hash                               string             `extensions:"x-nullable"     json:"hash,omitempty"`
-	Network                            string             `json:"network"`
-	Kind                               string             `json:"kind"`
-	Source                             string             `extensions:"x-nullable"     json:"source,omitempty"`
-	Destination                        string             `extensions:"x-nullable"     json:"destination,omitempty"`
-	PublicKey                          string             `extensions:"x-nullable"     json:"public_key,omitempty"`
-	ManagerPubKey                      string             `extensions:"x-nullable"     json:"manager_pubkey,omitempty"`
-	Delegate                           string             `extensions:"x-nullable"     json:"delegate,omitempty"`
-	Status                             string             `json:"status"`
-	Entrypoint                         string             `extensions:"x-nullable"     json:"entrypoint,omitempty"`
-	Tag                                string             `extensions:"x-nullable"     json:"tag,omitempty"`
-	AllocatedDestinationContract       bool               `example:"true"              extensions:"x-nullable"                                json:"allocated_destination_contract,omitempty"`
-	Internal                           bool               `json:"internal"`
-	Storage                            stdJSON.RawMessage `json:"-"`
+	ID                                 int64               `extensions:"x-nullable"     json:"id,omitempty"`
+	Level                              int64               `extensions:"x-nullable"     json:"level,omitempty"`
+	Fee                                int64               `extensions:"x-nullable"     json:"fee,omitempty"`
+	Counter                            int64               `extensions:"x-nullable"     json:"counter,omitempty"`
+	GasLimit                           int64               `extensions:"x-nullable"     json:"gas_limit,omitempty"`
+	StorageLimit                       int64               `extensions:"x-nullable"     json:"storage_limit,omitempty"`
+	Amount                             int64               `extensions:"x-nullable"     json:"amount,omitempty"`
+	Balance                            int64               `extensions:"x-nullable"     json:"balance,omitempty"`
+	Burned                             int64               `extensions:"x-nullable"     json:"burned,omitempty"`
+	AllocatedDestinationContractBurned int64               `extensions:"x-nullable"     json:"allocated_destination_contract_burned,omitempty"`
+	IndexedTime                        int64               `json:"-"`
+	ContentIndex                       int64               `json:"content_index"`
+	ConsumedGas                        int64               `example:"100"               extensions:"x-nullable"                                json:"consumed_gas,omitempty"`
+	StorageSize                        int64               `example:"200"               extensions:"x-nullable"                                json:"storage_size,omitempty"`
+	PaidStorageSizeDiff                int64               `example:"300"               extensions:"x-nullable"                                json:"paid_storage_size_diff,omitempty"`
+	TicketUpdatesCount                 int                 `json:"ticket_updates_count"`
+	BigMapDiffsCount                   int                 `json:"big_map_diffs_count"`
+	Errors                             []*tezerrors.Error  `extensions:"x-nullable"     json:"errors,omitempty"`
+	Parameters                         interface{}         `extensions:"x-nullable"     json:"parameters,omitempty"`
+	StorageDiff                        *ast.MiguelNode     `extensions:"x-nullable"     json:"storage_diff,omitempty"`
+	Payload                            []*ast.MiguelNode   `extensions:"x-nullable"     json:"payload,omitempty"`
+	Trace                              []noderpc.TraceStep `extensions:"x-nullable"     json:"trace,omitempty"`
+	Timestamp                          time.Time           `json:"timestamp"`
+	Protocol                           string              `json:"protocol"`
+	Hash                               string              `extensions:"x-nullable"     json:"hash,omitempty"`
+	Network                            string              `json:"network"`
+	Kind                               string              `json:"kind"`
+	Source                             string              `extensions:"x-nullable"     json:"source,omitempty"`
+	Destination                        string              `extensions:"x-nullable"     json:"destination,omitempty"`
+	PublicKey                          string              `extensions:"x-nullable"     json:"public_key,omitempty"`
+	ManagerPubKey                      string              `extensions:"x-nullable"     json:"manager_pubkey,omitempty"`
+	Delegate                           string              `extensions:"x-nullable"     json:"delegate,omitempty"`
+	Status                             string              `json:"status"`
+	Entrypoint                         string              `extensions:"x-nullable"     json:"entrypoint,omitempty"`
+	Tag                                string              `extensions:"x-nullable"     json:"tag,omitempty"`
+	AllocatedDestinationContract       bool                `example:"true"              extensions:"x-nullable"                                json:"allocated_destination_contract,omitempty"`
+	Internal                           bool                `json:"internal"`
+	Storage                            stdJSON.RawMessage  `json:"-"`
 }
 
 // FromModel -
@@ -167,6 +174,8 @@ type Contract struct {
 	TxCount         int64     `extensions:"x-nullable" json:"tx_count,omitempty"`
 	MigrationsCount int64     `extensions:"x-nullable" json:"migrations_count,omitempty"`
 	Slug            string    `extensions:"x-nullable" json:"slug,omitempty"`
+
+	Metadata *ContractMetadata `extensions:"x-nullable" json:"metadata,omitempty"`
 }
 
 // FromModel -
@@ -194,6 +203,29 @@ func (c *Contract) FromModel(contract contract.Contract) {
 	c.LastAction = contract.Account.LastAction
 }
 
+// ContractMetadata - TZIP-16 metadata of contract
+type ContractMetadata struct {
+	URI         string   `json:"uri"`
+	Name        string   `extensions:"x-nullable" json:"name,omitempty"`
+	Description string   `extensions:"x-nullable" json:"description,omitempty"`
+	Version     string   `extensions:"x-nullable" json:"version,omitempty"`
+	Interfaces  []string `extensions:"x-nullable" json:"interfaces,omitempty"`
+	Views       []string `extensions:"x-nullable" json:"views,omitempty"`
+	Level       int64    `json:"level"`
+}
+
+// NewContractMetadata -
+func NewContractMetadata(metadata contractmetadata.ContractMetadata) ContractMetadata {
+	return ContractMetadata{
+		URI:         metadata.URI,
+		Name:        metadata.Name,
+		Description: metadata.Description,
+		Version:     metadata.Version,
+		Interfaces:  metadata.Interfaces,
+		Level:       metadata.Level,
+	}
+}
+
 // ContractWithStats -
 type ContractWithStats struct {
 	Contract
@@ -228,6 +260,7 @@ type BigMapItem struct {
 	Key       interface{} `json:"key"`
 	KeyHash   string      `json:"key_hash"`
 	KeyString string      `json:"key_string"`
+	Value     interface{} `json:"value,omitempty"`
 	Level     int64       `json:"level"`
 	Timestamp time.Time   `json:"timestamp"`
 	IsActive  bool        `json:"is_active"`
@@ -288,9 +321,12 @@ type BigMapKeyStateResponse struct {
 
 // CodeDiffResponse -
 type CodeDiffResponse struct {
-	Left  CodeDiffLeg          `json:"left"`
-	Right CodeDiffLeg          `json:"right"`
-	Diff  formatter.DiffResult `json:"diff"`
+	Left      CodeDiffLeg          `json:"left"`
+	Right     CodeDiffLeg          `json:"right"`
+	Parameter formatter.DiffResult `json:"parameter"`
+	Storage   formatter.DiffResult `json:"storage"`
+	Code      formatter.DiffResult `json:"code"`
+	Views     formatter.DiffResult `json:"views"`
 }
 
 // NetworkStats -
@@ -332,6 +368,47 @@ type GetErrorLocationResponse struct {
 	EndColumn   int    `json:"end_col"`
 }
 
+// SimulationResponse -
+type SimulationResponse struct {
+	Contents      []SimulatedContent        `json:"contents"`
+	FailedContent *int64                    `extensions:"x-nullable" json:"failed_content,omitempty"`
+	ErrorLocation *GetErrorLocationResponse `extensions:"x-nullable" json:"error_location,omitempty"`
+}
+
+// SimulatedContent - result of content of simulated operation group. Consumed gas is in milligas and includes internal operations.
+type SimulatedContent struct {
+	Kind                string                `json:"kind"`
+	Status              string                `json:"status"`
+	Counter             int64                 `json:"counter"`
+	ConsumedGas         int64                 `json:"consumed_gas"`
+	PaidStorageSizeDiff int64                 `json:"paid_storage_size_diff"`
+	Errors              []*tezerrors.Error    `extensions:"x-nullable" json:"errors,omitempty"`
+	BigMapDiffs         []SimulatedBigMapDiff `extensions:"x-nullable" json:"big_map_diffs,omitempty"`
+	Operations          []Operation           `extensions:"x-nullable" json:"operations,omitempty"`
+}
+
+// EstimationResponse - recommended limits and fee of operation. Limits and fee are returned only if simulation is applied. Consumed gas is in milligas, size is in bytes of signed operation.
+type EstimationResponse struct {
+	Status              string             `json:"status"`
+	GasLimit            int64              `extensions:"x-nullable" json:"gas_limit,omitempty"`
+	StorageLimit        int64              `extensions:"x-nullable" json:"storage_limit,omitempty"`
+	Fee                 int64              `extensions:"x-nullable" json:"fee,omitempty"`
+	Burn                int64              `extensions:"x-nullable" json:"burn,omitempty"`
+	Size                int64              `extensions:"x-nullable" json:"size,omitempty"`
+	ConsumedGas         int64              `json:"consumed_gas"`
+	PaidStorageSizeDiff int64              `json:"paid_storage_size_diff"`
+	Errors              []*tezerrors.Error `extensions:"x-nullable" json:"errors,omitempty"`
+}
+
+// SimulatedBigMapDiff -
+type SimulatedBigMapDiff struct {
+	Ptr      int64              `json:"ptr"`
+	Contract string             `json:"contract"`
+	KeyHash  string             `json:"key_hash"`
+	Key      stdJSON.RawMessage `json:"key"`
+	Value    stdJSON.RawMessage `extensions:"x-nullable" json:"value,omitempty"`
+}
+
 // Protocol -
 type Protocol struct {
 	Hash       string `example:"PsCARTHAGazKbHtnKfLzQg3kms52kSRpgnDY982a9oYsSXRLQEb" json:"hash"`
@@ -380,6 +457,38 @@ func (b *Block) FromModel(block block.Block) {
 	}
 }
 
+// SeriesPoint - value of metric in time bucket which starts at timestamp
+type SeriesPoint struct {
+	Timestamp time.Time `json:"timestamp"`
+	Value     int64     `json:"value"`
+}
+
+// RPCNodeStatus - health of RPC node
+type RPCNodeStatus struct {
+	URL       string    `json:"url"`
+	Level     int64     `json:"level"`
+	Lag       int64     `json:"lag"`
+	LatencyMs int64     `json:"latency_ms"`
+	Available bool      `json:"available"`
+	Failures  int64     `json:"failures,omitempty"`
+	LastError string    `json:"last_error,omitempty"`
+	CheckedAt time.Time `json:"checked_at"`
+}
+
+// NewRPCNodeStatus -
+func NewRPCNodeStatus(status noderpc.NodeStatus) RPCNodeStatus {
+	return RPCNodeStatus{
+		URL:       status.URL,
+		Level:     status.Level,
+		Lag:       status.Lag,
+		LatencyMs: status.Latency.Milliseconds(),
+		Available: status.Available,
+		Failures:  status.Failures,
+		LastError: status.LastError,
+		CheckedAt: status.CheckedAt.UTC(),
+	}
+}
+
 type Stats struct {
 	ContractsCount              int `json:"contracts_count"`
 	SmartRollupsCount           int `json:"smart_rollups_count"`
@@ -504,6 +613,19 @@ type ForkResponse struct {
 	Storage stdJSON.RawMessage `json:"storage"`
 }
 
+// ForgedOperation -
+type ForgedOperation struct {
+	Bytes string `json:"bytes"`
+	Hash  string `extensions:"x-nullable" json:"hash,omitempty"`
+}
+
+// UnforgedOperation -
+type UnforgedOperation struct {
+	operations.Operation
+
+	Hash string `extensions:"x-nullable" json:"hash,omitempty"`
+}
+
 // Head -
 type Head struct {
 	Network   string    `json:"network"`
@@ -641,6 +763,88 @@ func NewEvent(o operation.Operation) (*Event, error) {
 	return e, nil
 }
 
+// ContractCode - Micheline code of contract with list of expanded global constants
+type ContractCode struct {
+	Code      stdJSON.RawMessage      `json:"code"`
+	Constants []bcd.ConstantExpansion `json:"constants,omitempty"`
+}
+
+// NetworkEvent - event with payload decoded to JSON
+type NetworkEvent struct {
+	ID        int64       `json:"id"`
+	Hash      string      `json:"hash"`
+	Status    string      `json:"status"`
+	Timestamp time.Time   `json:"timestamp"`
+	Level     int64       `json:"level"`
+	Emitter   string      `json:"emitter"`
+	Tag       string      `json:"tag"`
+	TypeHash  string      `json:"type_hash"`
+	Payload   interface{} `extensions:"x-nullable" json:"payload,omitempty"`
+}
+
+// NewNetworkEvent -
+func NewNetworkEvent(o operation.Operation) (NetworkEvent, error) {
+	e := NetworkEvent{
+		ID:        o.ID,
+		Status:    o.Status.String(),
+		Timestamp: o.Timestamp.UTC(),
+		Level:     o.Level,
+		Emitter:   o.Source.Address,
+		Tag:       o.Tag.String(),
+		TypeHash:  operation.PayloadTypeHash(o.PayloadType),
+	}
+	if len(o.Hash) > 0 {
+		e.Hash = encoding.MustEncodeOperationHash(o.Hash)
+	}
+	if len(o.PayloadType) == 0 || len(o.Payload) == 0 {
+		return e, nil
+	}
+
+	payloadType, err := ast.NewTypedAstFromBytes(o.PayloadType)
+	if err != nil {
+		return e, err
+	}
+	if err := payloadType.SettleFromBytes(o.Payload); err != nil {
+		return e, err
+	}
+	payload := make(ast.JSONModel)
+	payloadType.GetJSONModel(payload)
+	e.Payload = payload
+	return e, nil
+}
+
+// EventType - distinct signature of contract events
+type EventType struct {
+	Emitter    string          `json:"emitter"`
+	Tag        string          `json:"tag"`
+	TypeHash   string          `json:"type_hash"`
+	Schema     *ast.JSONSchema `extensions:"x-nullable" json:"schema,omitempty"`
+	Count      int64           `json:"count"`
+	FirstLevel int64           `json:"first_level"`
+	LastLevel  int64           `json:"last_level"`
+}
+
+// NewEventType -
+func NewEventType(typ operation.EventType) (EventType, error) {
+	e := EventType{
+		Emitter:    typ.Emitter,
+		Tag:        typ.Tag.String(),
+		TypeHash:   operation.PayloadTypeHash(typ.PayloadType),
+		Count:      typ.Count,
+		FirstLevel: typ.FirstLevel,
+		LastLevel:  typ.LastLevel,
+	}
+	if len(typ.PayloadType) == 0 {
+		return e, nil
+	}
+	payloadType, err := ast.NewTypedAstFromBytes(typ.PayloadType)
+	if err != nil {
+		return e, err
+	}
+	e.Schema, err = payloadType.ToJSONSchema()
+	return e, err
+}
+
 // TicketUpdate -
 type TicketUpdate struct {
 	ID            int64           `json:"id"`
@@ -696,6 +900,149 @@ func NewSmartRollup(rollup smartrollup.SmartRollup) SmartRollup {
 	}
 }
 
+// SmartRollupCommitment -
+type SmartRollupCommitment struct {
+	ID              int64     `json:"id"`
+	Level           int64     `json:"level"`
+	Timestamp       time.Time `json:"timestamp"`
+	Hash            string    `json:"hash"`
+	Publisher       string    `json:"publisher"`
+	Predecessor     string    `json:"predecessor"`
+	CompressedState string    `json:"compressed_state"`
+	InboxLevel      int64     `json:"inbox_level"`
+	NumberOfTicks   string    `json:"number_of_ticks"`
+	CementedLevel   int64     `extensions:"x-nullable" json:"cemented_level,omitempty"`
+}
+
+// NewSmartRollupCommitment -
+func NewSmartRollupCommitment(commitment smartrollup.Commitment) SmartRollupCommitment {
+	return SmartRollupCommitment{
+		ID:              commitment.ID,
+		Level:           commitment.Level,
+		Timestamp:       commitment.Timestamp,
+		Hash:            commitment.Hash,
+		Publisher:       commitment.Publisher.Address,
+		Predecessor:     commitment.Predecessor,
+		CompressedState: commitment.CompressedState,
+		InboxLevel:      commitment.InboxLevel,
+		NumberOfTicks:   commitment.NumberOfTicks.String(),
+	}
+}
+
+// SmartRollupStaker -
+type SmartRollupStaker struct {
+	Address          string `json:"address"`
+	Active           bool   `json:"active"`
+	FirstLevel       int64  `json:"first_level"`
+	LastLevel        int64  `json:"last_level"`
+	CommitmentsCount int64  `json:"commitments_count"`
+	LastCommitment   string `json:"last_commitment"`
+	RecoveredLevel   int64  `extensions:"x-nullable" json:"recovered_level,omitempty"`
+	LostLevel        int64  `extensions:"x-nullable" json:"lost_level,omitempty"`
+}
+
+// NewSmartRollupStaker -
+func NewSmartRollupStaker(staker smartrollup.Staker) SmartRollupStaker {
+	return SmartRollupStaker{
+		Address:          staker.Address,
+		Active:           staker.IsActive(),
+		FirstLevel:       staker.FirstLevel,
+		LastLevel:        staker.LastLevel,
+		CommitmentsCount: staker.CommitmentsCount,
+		LastCommitment:   staker.LastCommitment,
+		RecoveredLevel:   staker.RecoveredLevel,
+		LostLevel:        staker.LostLevel,
+	}
+}
+
+// SmartRollupGame -
+type SmartRollupGame struct {
+	ID                  int64                 `json:"id"`
+	Level               int64                 `json:"level"`
+	Timestamp           time.Time             `json:"timestamp"`
+	Initiator           string                `json:"initiator"`
+	Opponent            string                `json:"opponent"`
+	InitiatorCommitment string                `json:"initiator_commitment"`
+	OpponentCommitment  string                `json:"opponent_commitment"`
+	Status              string                `json:"status"`
+	Result              string                `extensions:"x-nullable" json:"result,omitempty"`
+	Reason              string                `extensions:"x-nullable" json:"reason,omitempty"`
+	Loser               string                `extensions:"x-nullable" json:"loser,omitempty"`
+	EndLevel            int64                 `extensions:"x-nullable" json:"end_level,omitempty"`
+	Moves               []SmartRollupGameMove `json:"moves"`
+}
+
+// SmartRollupGameMove -
+type SmartRollupGameMove struct {
+	Level     int64     `json:"level"`
+	Timestamp time.Time `json:"timestamp"`
+	Kind      string    `json:"kind"`
+	Player    string    `json:"player"`
+}
+
+// NewSmartRollupGame - builds game from its start and following moves. Status of the game is status of its last move.
+func NewSmartRollupGame(start smartrollup.Refutation, moves []smartrollup.Refutation) SmartRollupGame {
+	game := SmartRollupGame{
+		ID:                  start.ID,
+		Level:               start.Level,
+		Timestamp:           start.Timestamp,
+		Initiator:           start.Player.Address,
+		Opponent:            start.Opponent.Address,
+		InitiatorCommitment: start.PlayerCommitment,
+		OpponentCommitment:  start.OpponentCommitment,
+		Status:              start.Status,
+		Moves:               make([]SmartRollupGameMove, 0, len(moves)),
+	}
+
+	last := start
+	for i := range moves {
+		game.Moves = append(game.Moves, SmartRollupGameMove{
+			Level:     moves[i].Level,
+			Timestamp: moves[i].Timestamp,
+			Kind:      moves[i].Kind,
+			Player:    moves[i].Player.Address,
+		})
+		last = moves[i]
+	}
+
+	game.Status = last.Status
+	if last.IsEnded() {
+		game.Result = last.Result
+		game.Reason = last.Reason
+		game.Loser = last.Loser.Address
+		game.EndLevel = last.Level
+	}
+	return game
+}
+
+// SmartRollupOutboxMessage -
+type SmartRollupOutboxMessage struct {
+	ID                 int64       `json:"id"`
+	Level              int64       `json:"level"`
+	Timestamp          time.Time   `json:"timestamp"`
+	Hash               string      `json:"hash"`
+	Counter            int64       `json:"counter"`
+	Executor           string      `json:"executor"`
+	CementedCommitment string      `json:"cemented_commitment"`
+	TransactionsCount  int         `json:"transactions_count"`
+	Transactions       []Operation `json:"transactions"`
+}
+
+// NewSmartRollupOutboxMessage -
+func NewSmartRollupOutboxMessage(message smartrollup.OutboxMessage) SmartRollupOutboxMessage {
+	return SmartRollupOutboxMessage{
+		ID:                 message.ID,
+		Level:              message.Level,
+		Timestamp:          message.Timestamp,
+		Hash:               encoding.MustEncodeOperationHash(message.Hash),
+		Counter:            message.Counter,
+		Executor:           message.Executor.Address,
+		CementedCommitment: message.CementedCommitment,
+		TransactionsCount:  message.TransactionsCount,
+		Transactions:       make([]Operation, 0),
+	}
+}
+
 type TicketBalance struct {
 	Ticketer    string          `json:"ticketer"`
 	Amount      string          `json:"amount"`
@@ -712,6 +1059,51 @@ func NewTicketBalance(balance ticket.Balance) TicketBalance {
 	}
 }
 
+// TokenBalance -
+type TokenBalance struct {
+	Contract string `json:"contract"`
+	Address  string `json:"address"`
+	TokenId  string `json:"token_id"`
+	Amount   string `json:"amount"`
+}
+
+// NewTokenBalance -
+func NewTokenBalance(balance token.Balance) TokenBalance {
+	return TokenBalance{
+		Contract: balance.Contract.Address,
+		Address:  balance.Account.Address,
+		TokenId:  balance.TokenId.String(),
+		Amount:   balance.Amount.String(),
+	}
+}
+
+// TokenTransfer -
+type TokenTransfer struct {
+	ID            int64     `json:"id"`
+	Level         int64     `json:"level"`
+	Timestamp     time.Time `json:"timestamp"`
+	Contract      string    `json:"contract"`
+	From          string    `json:"from,omitempty"`
+	To            string    `json:"to,omitempty"`
+	TokenId       string    `json:"token_id"`
+	Amount        string    `json:"amount"`
+	OperationHash string    `json:"operation_hash"`
+}
+
+// NewTokenTransfer -
+func NewTokenTransfer(transfer token.Transfer) TokenTransfer {
+	return TokenTransfer{
+		ID:        transfer.ID,
+		Level:     transfer.Level,
+		Timestamp: transfer.Timestamp.UTC(),
+		Contract:  transfer.Contract.Address,
+		From:      transfer.From.Address,
+		To:        transfer.To.Address,
+		TokenId:   transfer.TokenId.String(),
+		Amount:    transfer.Amount.String(),
+	}
+}
+
 type Ticket struct {
 	Ticketer     string          `json:"ticketer"`
 	ContentType  []ast.Typedef   `json:"content_type"`
@@ -751,6 +1143,56 @@ func NewTicket(t ticket.Ticket) (Ticket, error) {
 	return result, nil
 }
 
+// TicketInfo -
+type TicketInfo struct {
+	Ticket
+
+	TicketId     int64  `json:"ticket_id"`
+	Hash         string `json:"hash"`
+	Supply       string `json:"supply"`
+	HoldersCount int64  `json:"holders_count"`
+}
+
+// TicketHolder -
+type TicketHolder struct {
+	Address string `json:"address"`
+	Amount  string `json:"amount"`
+}
+
+// TicketSupplyPoint -
+type TicketSupplyPoint struct {
+	Level     int64     `json:"level"`
+	Timestamp time.Time `json:"timestamp"`
+	Delta     string    `json:"delta"`
+	Supply    string    `json:"supply"`
+}
+
+// TicketFlow - movements of ticket between accounts over level range
+type TicketFlow struct {
+	Ticket    Ticket           `json:"ticket"`
+	FromLevel int64            `json:"from_level"`
+	ToLevel   int64            `json:"to_level"`
+	Nodes     []TicketFlowNode `json:"nodes"`
+	Edges     []TicketFlowEdge `json:"edges"`
+}
+
+// TicketFlowNode -
+type TicketFlowNode struct {
+	Address  string `json:"address"`
+	Type     string `json:"type"`
+	Sent     string `json:"sent"`
+	Received string `json:"received"`
+}
+
+// TicketFlowEdge - aggregated movement of ticket. Source of `mint` and destination of `burn` are empty.
+type TicketFlowEdge struct {
+	From            string `json:"from,omitempty"`
+	To              string `json:"to,omitempty"`
+	Kind            string `json:"kind"`
+	Amount          string `json:"amount"`
+	OperationsCount int64  `json:"operations_count"`
+}
+
 type GlobalConstantItem struct {
 	Timestamp  time.Time `json:"timestamp"`
 	Level      int64     `json:"level"`
@@ -766,3 +1208,38 @@ func NewGlobalConstantItem(item contract.ListGlobalConstantItem) GlobalConstantI
 		LinksCount: item.LinksCount,
 	}
 }
+
+// SearchResponse -
+type SearchResponse struct {
+	Items []SearchItem `json:"items"`
+	Total int64        `json:"total"`
+}
+
+// SearchItem -
+type SearchItem struct {
+	Type      string `json:"type"`
+	Network   string `json:"network"`
+	Address   string `json:"address"`
+	Value     string `json:"value"`
+	Highlight string `json:"highlight"`
+	Level     int64  `json:"level"`
+	Ptr       *int64 `extensions:"x-nullable" json:"ptr,omitempty"`
+	KeyHash   string `json:"key_hash,omitempty"`
+
+	rank int
+}
+
+// NewSearchItem -
+func NewSearchItem(hit search.Hit, network types.Network, text string) SearchItem {
+	return SearchItem{
+		Type:      hit.Type,
+		Network:   network.String(),
+		Address:   hit.Address,
+		Value:     hit.Value,
+		Highlight: highlight(hit.Value, text),
+		Level:     hit.Level,
+		Ptr:       hit.Ptr,
+		KeyHash:   hit.KeyHash,
+		rank:      hit.Rank,
+	}
+}
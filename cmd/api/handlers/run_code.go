@@ -8,6 +8,7 @@ import (
 	"github.com/baking-bad/bcdhub/internal/bcd"
 	"github.com/baking-bad/bcdhub/internal/bcd/ast"
 	"github.com/baking-bad/bcdhub/internal/bcd/consts"
+	"github.com/baking-bad/bcdhub/internal/bcd/interpreter"
 	"github.com/baking-bad/bcdhub/internal/bcd/tezerrors"
 	"github.com/baking-bad/bcdhub/internal/config"
 	"github.com/baking-bad/bcdhub/internal/models/bigmapdiff"
@@ -21,7 +22,7 @@ import (
 	"github.com/pkg/errors"
 )
 
-// RunOperation -
+// RunOperation - simulates operation by node. Interpreter can't apply operations, so 400 is returned if node isn't configured.
 func RunOperation() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		ctx := c.MustGet("context").(*config.Context)
@@ -34,6 +35,10 @@ func RunOperation() gin.HandlerFunc {
 		if err := c.ShouldBindJSON(&reqRunOp); handleError(c, ctx.Storage, err, http.StatusBadRequest) {
 			return
 		}
+		if ctx.RPC == nil {
+			handleError(c, ctx.Storage, errors.Errorf("operation can't be run: RPC is not set for %s", ctx.Network), http.StatusBadRequest)
+			return
+		}
 
 		state, err := ctx.Blocks.Last(c.Request.Context())
 		if handleError(c, ctx.Storage, err, 0) {
@@ -117,7 +122,7 @@ func RunOperation() gin.HandlerFunc {
 
 // RunCode godoc
 // @Summary Execute entrypoint with passed arguments
-// @Description Execute entrypoint with passed arguments
+// @Description Execute entrypoint with passed arguments. If `offline` is set or node isn't configured, code is executed by built-in interpreter against indexed storage and big maps, and stack trace of each instruction is returned in the first operation.
 // @Tags contract
 // @ID run-code
 // @Param network path string true "Network"
@@ -158,7 +163,14 @@ func RunCode() gin.HandlerFunc {
 			return
 		}
 
-		storage, err := ctx.RPC.GetScriptStorageRaw(c, req.Address, 0)
+		rpc := ctx.RPC
+		var storage []byte
+		if isOffline(ctx, reqRunCode.Offline) {
+			rpc = newInterpreterNode(ctx, state, req.Address, interpreter.WithTrace())
+			storage, err = chainState{ctx: ctx, symLink: state.Protocol.SymLink}.storage(c, req.Address)
+		} else {
+			storage, err = ctx.RPC.GetScriptStorageRaw(c, req.Address, 0)
+		}
 		if handleError(c, ctx.Storage, err, 0) {
 			return
 		}
@@ -178,7 +190,8 @@ func RunCode() gin.HandlerFunc {
 			Entrypoint:  input.Entrypoint,
 		}
 
-		response, err := ctx.RPC.RunCode(c, scriptBytes, storage, input.Value, state.Protocol.ChainID, reqRunCode.Source, reqRunCode.Sender, input.Entrypoint, state.Protocol.Hash, reqRunCode.Amount, reqRunCode.GasLimit)
+		response, err := rpc.RunCode(c, scriptBytes, storage, input.Value, state.Protocol.ChainID, reqRunCode.Source, reqRunCode.Sender, input.Entrypoint, state.Protocol.Hash, reqRunCode.Amount, reqRunCode.GasLimit)
+		main.Trace = response.Trace
 		if err != nil {
 			var e noderpc.InvalidNodeResponse
 			if errors.As(err, &e) {
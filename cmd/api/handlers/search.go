@@ -0,0 +1,123 @@
+package handlers
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/baking-bad/bcdhub/internal/config"
+	"github.com/baking-bad/bcdhub/internal/models/search"
+	"github.com/baking-bad/bcdhub/internal/models/types"
+	"github.com/baking-bad/bcdhub/internal/postgres/consts"
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+)
+
+// Search godoc
+// @Summary Search in indexed data
+// @Description Search by address prefix, entrypoint, annotation, tag name, global constant address and big map key string.
+// @Description Entrypoints, annotations, tag names and big map keys are matched by substring. Only the first 1000 hits can be paged through.
+// @Tags search
+// @ID search
+// @Param q query string true "Search text" minlength(3) maxlength(255)
+// @Param network query []string false "Networks filter" collectionFormat(multi)
+// @Param type query []string false "Hit types filter: contract, account, smart_rollup, entrypoint, annotation, tag, global_constant, big_map_key" collectionFormat(multi)
+// @Param offset query integer false "Offset"
+// @Param size query integer false "Requested count" mininum(1) maximum(10)
+// @Accept  json
+// @Produce  json
+// @Success 200 {object} SearchResponse
+// @Failure 400 {object} Error
+// @Failure 500 {object} Error
+// @Router /v1/search [get]
+func Search() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctxs := c.MustGet("contexts").(config.Contexts)
+		any := ctxs.Any()
+
+		var req searchRequest
+		if err := c.ShouldBindQuery(&req); handleError(c, any.Storage, err, http.StatusBadRequest) {
+			return
+		}
+		req.Text = strings.TrimSpace(req.Text)
+
+		size := req.Size
+		if size == 0 {
+			size = consts.DefaultSize
+		}
+		if req.Offset+size > search.MaxDepth {
+			handleError(c, any.Storage, errors.Errorf("offset and size are too big: only first %d hits can be requested", search.MaxDepth), http.StatusBadRequest)
+			return
+		}
+
+		networks := make(types.Networks, 0, len(ctxs))
+		if len(req.Networks) > 0 {
+			for i := range req.Networks {
+				networks = append(networks, types.NewNetwork(req.Networks[i]))
+			}
+		} else {
+			for network := range ctxs {
+				networks = append(networks, network)
+			}
+		}
+		sort.Sort(networks)
+
+		var total int64
+		items := make([]SearchItem, 0)
+		for _, network := range networks {
+			ctx, ok := ctxs[network]
+			if !ok {
+				continue
+			}
+			hits, err := ctx.Search.Search(c.Request.Context(), req.Text, req.Offset+size, req.Types...)
+			if handleError(c, ctx.Storage, err, 0) {
+				return
+			}
+			for i := range hits {
+				items = append(items, NewSearchItem(hits[i], network, req.Text))
+			}
+
+			count, err := ctx.Search.Count(c.Request.Context(), req.Text, req.Types...)
+			if handleError(c, ctx.Storage, err, 0) {
+				return
+			}
+			total += count
+		}
+
+		c.SecureJSON(http.StatusOK, SearchResponse{
+			Items: paginateSearchItems(items, req.Offset, size),
+			Total: total,
+		})
+	}
+}
+
+func paginateSearchItems(items []SearchItem, offset, size int64) []SearchItem {
+	sort.SliceStable(items, func(i, j int) bool {
+		if items[i].rank != items[j].rank {
+			return items[i].rank < items[j].rank
+		}
+		return items[i].Level > items[j].Level
+	})
+
+	if offset >= int64(len(items)) {
+		return []SearchItem{}
+	}
+	end := offset + size
+	if end > int64(len(items)) {
+		end = int64(len(items))
+	}
+	return items[offset:end]
+}
+
+func highlight(value, text string) string {
+	lowerValue := strings.ToLower(value)
+	if len(lowerValue) != len(value) {
+		return value
+	}
+	idx := strings.Index(lowerValue, strings.ToLower(text))
+	if idx < 0 {
+		return value
+	}
+	end := idx + len(text)
+	return value[:idx] + "<mark>" + value[idx:end] + "</mark>" + value[end:]
+}
@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHighlight(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		text  string
+		want  string
+	}{
+		{
+			name:  "prefix",
+			value: "KT1Hkg5qeNhfwpKW4fXvq7HGZB9z2EnmCCA9",
+			text:  "kt1hkg",
+			want:  "<mark>KT1Hkg</mark>5qeNhfwpKW4fXvq7HGZB9z2EnmCCA9",
+		}, {
+			name:  "middle",
+			value: "%transfer",
+			text:  "trans",
+			want:  "%<mark>trans</mark>fer",
+		}, {
+			name:  "not found",
+			value: "fa2",
+			text:  "ledger",
+			want:  "fa2",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, highlight(tt.value, tt.text))
+		})
+	}
+}
+
+func TestPaginateSearchItems(t *testing.T) {
+	items := []SearchItem{
+		{Value: "contains", Level: 10, rank: 2},
+		{Value: "prefix old", Level: 5, rank: 1},
+		{Value: "exact", Level: 1, rank: 0},
+		{Value: "prefix new", Level: 7, rank: 1},
+	}
+
+	got := paginateSearchItems(items, 1, 2)
+	require.Len(t, got, 2)
+	require.Equal(t, "prefix new", got[0].Value)
+	require.Equal(t, "prefix old", got[1].Value)
+
+	require.Empty(t, paginateSearchItems(items, 10, 2))
+	require.Len(t, paginateSearchItems(items, 3, 10), 1)
+}
@@ -0,0 +1,341 @@
+package handlers
+
+import (
+	"context"
+	stdJSON "encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/baking-bad/bcdhub/internal/bcd"
+	"github.com/baking-bad/bcdhub/internal/bcd/consts"
+	"github.com/baking-bad/bcdhub/internal/bcd/tezerrors"
+	"github.com/baking-bad/bcdhub/internal/config"
+	"github.com/baking-bad/bcdhub/internal/models/block"
+	"github.com/baking-bad/bcdhub/internal/models/operation"
+	modelTypes "github.com/baking-bad/bcdhub/internal/models/types"
+	"github.com/baking-bad/bcdhub/internal/noderpc"
+	"github.com/baking-bad/bcdhub/internal/parsers"
+	"github.com/baking-bad/bcdhub/internal/parsers/operations"
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+)
+
+// RunOperations godoc
+// @Summary Simulate operation group
+// @Description Simulate operation group with several contents from one source. Contents are applied in passed order with sequential counters. Transaction parameters may be passed as entrypoint `name` with `data` or as raw Micheline `parameters`. Entrypoint of ticket transfer is passed as `name`. Default storage limit is hard limit per operation. Contents without gas limit share the rest of hard gas limit per block, each share is capped by hard gas limit per operation.
+// @Tags operations
+// @ID run-operations
+// @Param network path string true "Network"
+// @Param body body runOperationsRequest true "Request body"
+// @Accept json
+// @Produce json
+// @Success 200 {object} SimulationResponse
+// @Failure 400 {object} Error
+// @Failure 404 {object} Error
+// @Failure 500 {object} Error
+// @Router /v1/run_operations/{network} [post]
+func RunOperations() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.MustGet("context").(*config.Context)
+
+		var req runOperationsRequest
+		if err := c.ShouldBindJSON(&req); handleError(c, ctx.Storage, err, http.StatusBadRequest) {
+			return
+		}
+		if ctx.RPC == nil {
+			handleError(c, ctx.Storage, errors.Errorf("operations can't be run: RPC is not set for %s", ctx.Network), http.StatusBadRequest)
+			return
+		}
+
+		state, err := ctx.Blocks.Last(c.Request.Context())
+		if handleError(c, ctx.Storage, err, 0) {
+			return
+		}
+		constants, err := ctx.RPC.GetNetworkConstants(c, state.Level)
+		if handleError(c, ctx.Storage, err, 0) {
+			return
+		}
+		predecessor, err := ctx.Blocks.Get(c.Request.Context(), state.Level-1)
+		if handleError(c, ctx.Storage, err, 0) {
+			return
+		}
+
+		counter, err := ctx.RPC.GetCounter(c, req.Source)
+		if handleError(c, ctx.Storage, err, 0) {
+			return
+		}
+
+		contents := make([]noderpc.RunOperationContent, len(req.Contents))
+		for i := range req.Contents {
+			content, err := buildRunOperationContent(c.Request.Context(), ctx, state, req.Source, req.Contents[i])
+			if handleError(c, ctx.Storage, err, http.StatusBadRequest) {
+				return
+			}
+			content.Counter = counter + int64(i) + 1
+			contents[i] = content
+		}
+		if err := splitBlockGasLimit(contents, req.Contents, constants); handleError(c, ctx.Storage, err, http.StatusBadRequest) {
+			return
+		}
+
+		response, err := ctx.RPC.RunOperations(c, state.Protocol.ChainID, state.Hash, contents)
+		if err != nil {
+			var e noderpc.InvalidNodeResponse
+			if errors.As(err, &e) {
+				// the node rejects the whole group on precheck: wrong counter, balance too low etc.
+				handleError(c, ctx.Storage, e, http.StatusBadRequest)
+				return
+			}
+			handleError(c, ctx.Storage, err, 0)
+			return
+		}
+
+		header := noderpc.Header{
+			Level:       state.Level,
+			Protocol:    state.Protocol.Hash,
+			Timestamp:   state.Timestamp,
+			ChainID:     state.Protocol.ChainID,
+			Hash:        state.Hash,
+			Predecessor: predecessor.Hash,
+		}
+
+		parserParams, err := operations.NewParseParams(
+			c.Request.Context(),
+			ctx,
+			operations.WithProtocol(&state.Protocol),
+			operations.WithHead(header),
+		)
+		if handleError(c, ctx.Storage, err, 0) {
+			return
+		}
+
+		store := parsers.NewTestStore()
+		if err := operations.NewGroup(parserParams).Parse(c.Request.Context(), response, store); handleError(c, ctx.Storage, err, 0) {
+			return
+		}
+
+		result, err := prepareSimulation(c.Request.Context(), ctx, response, store.ListOperations())
+		if handleError(c, ctx.Storage, err, 0) {
+			return
+		}
+		c.SecureJSON(http.StatusOK, result)
+	}
+}
+
+func buildRunOperationContent(c context.Context, ctx *config.Context, state block.Block, source string, req runOperationsContent) (noderpc.RunOperationContent, error) {
+	content := noderpc.RunOperationContent{
+		Kind:         req.Kind,
+		Source:       source,
+		Fee:          req.Fee,
+		GasLimit:     req.GasLimit,
+		StorageLimit: req.StorageLimit,
+	}
+	if content.GasLimit == 0 {
+		content.GasLimit = state.Protocol.HardGasLimitPerOperation
+	}
+	if content.StorageLimit == 0 {
+		content.StorageLimit = state.Protocol.HardStorageLimitPerOperation
+	}
+
+	switch req.Kind {
+	case consts.Transaction:
+		if req.Destination == "" {
+			return content, errors.New("destination is required for transaction")
+		}
+		content.Destination = req.Destination
+		content.Amount = &req.Amount
+
+		switch {
+		case req.Name != "":
+			if !bcd.IsContract(req.Destination) {
+				return content, errors.Errorf("entrypoint can be called only on contract: %s", req.Destination)
+			}
+			parameters, err := buildParametersForExecution(c, ctx, req.Destination, state.Protocol.SymLink, req.Name, req.Data)
+			if err != nil {
+				return content, err
+			}
+			content.Parameters, err = json.Marshal(parameters)
+			if err != nil {
+				return content, err
+			}
+		case len(req.Parameters) > 0:
+			content.Parameters = req.Parameters
+		}
+	case consts.Origination:
+		if len(req.Script) == 0 {
+			return content, errors.New("script is required for origination")
+		}
+		content.Balance = &req.Balance
+		content.Script = req.Script
+		content.Delegate = req.Delegate
+	case consts.Delegation:
+		content.Delegate = req.Delegate
+	case consts.TransferTicket:
+		if req.Destination == "" || req.TicketTicketer == "" || len(req.TicketContents) == 0 || len(req.TicketType) == 0 {
+			return content, errors.New("destination, ticket_ticketer, ticket_contents and ticket_ty are required for ticket transfer")
+		}
+		content.Destination = req.Destination
+		content.TicketContents = req.TicketContents
+		content.TicketType = req.TicketType
+		content.TicketTicketer = req.TicketTicketer
+		content.TicketAmount = strconv.FormatInt(req.TicketAmount, 10)
+		content.Entrypoint = req.Name
+		if content.Entrypoint == "" {
+			content.Entrypoint = consts.DefaultEntrypoint
+		}
+	default:
+		return content, errors.Errorf("unsupported operation kind: %s", req.Kind)
+	}
+	return content, nil
+}
+
+// splitBlockGasLimit - contents without explicit gas limit share gas which is left in block after explicit limits, so the group fits into hard gas limit per block
+func splitBlockGasLimit(contents []noderpc.RunOperationContent, req []runOperationsContent, constants noderpc.Constants) error {
+	if constants.HardGasLimitPerBlock == 0 {
+		return nil
+	}
+
+	left := constants.HardGasLimitPerBlock
+	var withoutLimit int64
+	for i := range req {
+		if req[i].GasLimit > 0 {
+			left -= req[i].GasLimit
+		} else {
+			withoutLimit++
+		}
+	}
+	if left < withoutLimit {
+		return errors.Errorf("gas limits of contents exceed hard gas limit per block: %d", constants.HardGasLimitPerBlock)
+	}
+	if withoutLimit == 0 {
+		return nil
+	}
+
+	share := left / withoutLimit
+	for i := range req {
+		if req[i].GasLimit == 0 {
+			contents[i].GasLimit = min(contents[i].GasLimit, share)
+		}
+	}
+	return nil
+}
+
+func prepareSimulation(c context.Context, ctx *config.Context, response noderpc.LightOperationGroup, parsed []*operation.Operation) (SimulationResponse, error) {
+	result := SimulationResponse{
+		Contents: make([]SimulatedContent, len(response.Contents)),
+	}
+
+	originated := make(map[string]struct{})
+	for i := range parsed {
+		if parsed[i].IsOrigination() && parsed[i].Status == modelTypes.OperationStatusApplied {
+			originated[parsed[i].Destination.Address] = struct{}{}
+		}
+	}
+
+	for i := range response.Contents {
+		var data noderpc.Operation
+		if err := json.Unmarshal(response.Contents[i].Raw, &data); err != nil {
+			return result, err
+		}
+
+		content, err := newSimulatedContent(data)
+		if err != nil {
+			return result, err
+		}
+
+		for j := range parsed {
+			if parsed[j].ContentIndex != int64(i) {
+				continue
+			}
+			op, err := prepareSimulatedOperation(c, ctx, *parsed[j], originated)
+			if err != nil {
+				return result, err
+			}
+			content.Operations = append(content.Operations, op)
+
+			for _, diff := range parsed[j].BigMapDiffs {
+				content.BigMapDiffs = append(content.BigMapDiffs, SimulatedBigMapDiff{
+					Ptr:      diff.Ptr,
+					Contract: diff.Contract,
+					KeyHash:  diff.KeyHash,
+					Key:      stdJSON.RawMessage(diff.Key),
+					Value:    stdJSON.RawMessage(diff.Value),
+				})
+			}
+
+			if result.ErrorLocation != nil || !tezerrors.HasScriptRejectedError(parsed[j].Errors) {
+				continue
+			}
+			if _, ok := originated[parsed[j].Destination.Address]; ok {
+				continue
+			}
+			location, err := getErrorLocation(c, ctx, *parsed[j], 2)
+			if err != nil {
+				return result, err
+			}
+			result.ErrorLocation = &location
+		}
+
+		if content.Status == consts.Failed && result.FailedContent == nil {
+			index := int64(i)
+			result.FailedContent = &index
+		}
+		result.Contents[i] = content
+	}
+
+	return result, nil
+}
+
+func newSimulatedContent(data noderpc.Operation) (SimulatedContent, error) {
+	content := SimulatedContent{
+		Kind:    data.Kind,
+		Counter: data.Counter,
+	}
+
+	results := make([]*noderpc.OperationResult, 0)
+	if result := data.GetResult(); result != nil {
+		content.Status = result.Status
+		results = append(results, result)
+	}
+	if data.Metadata != nil {
+		for i := range data.Metadata.Internal {
+			if result := data.Metadata.Internal[i].GetResult(); result != nil {
+				results = append(results, result)
+			}
+		}
+	}
+
+	for _, result := range results {
+		if result.ConsumedMilligas != nil {
+			content.ConsumedGas += *result.ConsumedMilligas
+		} else {
+			content.ConsumedGas += result.ConsumedGas * 1000
+		}
+		if result.PaidStorageSizeDiff != nil {
+			content.PaidStorageSizeDiff += *result.PaidStorageSizeDiff
+		}
+		if len(content.Errors) > 0 || len(result.Errors) == 0 {
+			continue
+		}
+		errs, err := tezerrors.ParseArray(result.Errors)
+		if err != nil {
+			return content, err
+		}
+		for i := range errs {
+			if err := errs[i].Format(); err != nil {
+				return content, err
+			}
+		}
+		content.Errors = errs
+	}
+	return content, nil
+}
+
+// prepareSimulatedOperation - contracts originated by simulated group aren't indexed, so their parameters and storage can't be typed
+func prepareSimulatedOperation(c context.Context, ctx *config.Context, op operation.Operation, originated map[string]struct{}) (Operation, error) {
+	if _, ok := originated[op.Destination.Address]; ok {
+		response, _, err := newOperationResponse(c, ctx, op)
+		return response, err
+	}
+	return prepareOperation(c, ctx, op, true)
+}
@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"testing"
+
+	"github.com/baking-bad/bcdhub/internal/bcd/consts"
+	"github.com/baking-bad/bcdhub/internal/noderpc"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewSimulatedContent(t *testing.T) {
+	tests := []struct {
+		name        string
+		data        string
+		wantStatus  string
+		wantGas     int64
+		wantStorage int64
+		wantErrors  int
+	}{
+		{
+			name:        "applied with internal operations",
+			data:        `{"kind":"transaction","source":"tz1burnburnburnburnburnburnburjAYjjX","fee":"0","counter":"11","gas_limit":"1040000","storage_limit":"60000","amount":"0","destination":"KT1FSSJtvHsRL3orgtj8mBi4EoaKJyStG2FF","metadata":{"operation_result":{"status":"applied","consumed_milligas":"2500","paid_storage_size_diff":"67"},"internal_operation_results":[{"kind":"transaction","source":"KT1FSSJtvHsRL3orgtj8mBi4EoaKJyStG2FF","nonce":0,"amount":"0","destination":"KT1Lw8hCoaBrHeTeMXbqHPG4sS4K1xn7yKcD","result":{"status":"applied","consumed_milligas":"1500","paid_storage_size_diff":"10"}}]}}`,
+			wantStatus:  consts.Applied,
+			wantGas:     4000,
+			wantStorage: 77,
+		}, {
+			name:       "failed",
+			data:       `{"kind":"transaction","source":"tz1burnburnburnburnburnburnburjAYjjX","fee":"0","counter":"12","gas_limit":"1040000","storage_limit":"60000","amount":"0","destination":"KT1FSSJtvHsRL3orgtj8mBi4EoaKJyStG2FF","metadata":{"operation_result":{"status":"failed","errors":[{"kind":"temporary","id":"proto.020-PsParisC.michelson_v1.script_rejected","location":42,"with":{"string":"FA2_INSUFFICIENT_BALANCE"}}]}}}`,
+			wantStatus: consts.Failed,
+			wantErrors: 1,
+		}, {
+			name:       "backtracked",
+			data:       `{"kind":"transaction","source":"tz1burnburnburnburnburnburnburjAYjjX","fee":"0","counter":"10","gas_limit":"1040000","storage_limit":"60000","amount":"100","destination":"tz1burnburnburnburnburnburnburjAYjjX","metadata":{"operation_result":{"status":"backtracked","consumed_milligas":"100000"}}}`,
+			wantStatus: consts.Backtracked,
+			wantGas:    100000,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var data noderpc.Operation
+			require.NoError(t, json.Unmarshal([]byte(tt.data), &data))
+
+			content, err := newSimulatedContent(data)
+			require.NoError(t, err)
+			require.Equal(t, consts.Transaction, content.Kind)
+			require.Equal(t, tt.wantStatus, content.Status)
+			require.Equal(t, tt.wantGas, content.ConsumedGas)
+			require.Equal(t, tt.wantStorage, content.PaidStorageSizeDiff)
+			require.Len(t, content.Errors, tt.wantErrors)
+		})
+	}
+}
+
+func TestSplitBlockGasLimit(t *testing.T) {
+	constants := noderpc.Constants{
+		HardGasLimitPerOperation: 1040000,
+		HardGasLimitPerBlock:     2600000,
+	}
+
+	tests := []struct {
+		name    string
+		limits  []int64
+		want    []int64
+		wantErr bool
+	}{
+		{
+			name:   "single content",
+			limits: []int64{0},
+			want:   []int64{1040000},
+		}, {
+			name:   "several contents",
+			limits: []int64{0, 0, 0},
+			want:   []int64{866666, 866666, 866666},
+		}, {
+			name:   "explicit limits",
+			limits: []int64{2000000, 0, 0},
+			want:   []int64{2000000, 300000, 300000},
+		}, {
+			name:   "only explicit limits",
+			limits: []int64{1000000, 1000000},
+			want:   []int64{1000000, 1000000},
+		}, {
+			name:    "explicit limits exceed block",
+			limits:  []int64{1040000, 1040000, 1040000},
+			wantErr: true,
+		}, {
+			name:    "nothing is left",
+			limits:  []int64{1300000, 1300000, 0},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := make([]runOperationsContent, len(tt.limits))
+			contents := make([]noderpc.RunOperationContent, len(tt.limits))
+			for i, limit := range tt.limits {
+				req[i].GasLimit = limit
+				contents[i].GasLimit = limit
+				if limit == 0 {
+					contents[i].GasLimit = constants.HardGasLimitPerOperation
+				}
+			}
+
+			err := splitBlockGasLimit(contents, req, constants)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			for i := range contents {
+				require.Equal(t, tt.want[i], contents[i].GasLimit)
+			}
+		})
+	}
+}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/baking-bad/bcdhub/internal/bcd/ast"
 	"github.com/baking-bad/bcdhub/internal/config"
+	"github.com/baking-bad/bcdhub/internal/models/operation"
 	"github.com/gin-gonic/gin"
 )
 
@@ -96,3 +97,221 @@ func ListSmartRollups() gin.HandlerFunc {
 		c.SecureJSON(http.StatusOK, response)
 	}
 }
+
+// GetSmartRollupCommitments godoc
+// @Summary List smart rollup commitments
+// @Description List commitments published to smart rollup. Cemented commitments contain level of cementation.
+// @Tags smart-rollups
+// @ID list-smart-rollup-commitments
+// @Param network path string true "network"
+// @Param address path string true "expr address of smart rollup" minlength(36) maxlength(36)
+// @Param size query integer false "Commitments count" mininum(1) maximum(10)
+// @Param offset query integer false "Offset" mininum(1)
+// @Param sort query string false "Sort order" Enums(asc, desc)
+// @Accept json
+// @Produce json
+// @Success 200 {array} SmartRollupCommitment
+// @Failure 400 {object} Error
+// @Failure 404 {object} Error
+// @Failure 500 {object} Error
+// @Router /v1/smart_rollups/{network}/{address}/commitments [get]
+func GetSmartRollupCommitments() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.MustGet("context").(*config.Context)
+
+		var req getSmartRollupRequest
+		if err := c.ShouldBindUri(&req); handleError(c, ctx.Storage, err, http.StatusBadRequest) {
+			return
+		}
+		var args smartRollupListRequest
+		if err := c.ShouldBindQuery(&args); handleError(c, ctx.Storage, err, http.StatusBadRequest) {
+			return
+		}
+
+		rollup, err := ctx.Accounts.Get(c.Request.Context(), req.Address)
+		if handleError(c, ctx.Storage, err, 0) {
+			return
+		}
+
+		commitments, err := ctx.SmartRollups.Commitments(c.Request.Context(), rollup.ID, args.toListRequest())
+		if handleError(c, ctx.Storage, err, 0) {
+			return
+		}
+
+		hashes := make([]string, len(commitments))
+		for i := range commitments {
+			hashes[i] = commitments[i].Hash
+		}
+		cementations, err := ctx.SmartRollups.Cementations(c.Request.Context(), rollup.ID, hashes...)
+		if handleError(c, ctx.Storage, err, 0) {
+			return
+		}
+		cemented := make(map[string]int64, len(cementations))
+		for i := range cementations {
+			cemented[cementations[i].CommitmentHash] = cementations[i].Level
+		}
+
+		response := make([]SmartRollupCommitment, len(commitments))
+		for i := range commitments {
+			response[i] = NewSmartRollupCommitment(commitments[i])
+			response[i].CementedLevel = cemented[commitments[i].Hash]
+		}
+		c.SecureJSON(http.StatusOK, response)
+	}
+}
+
+// GetSmartRollupStakers godoc
+// @Summary List smart rollup stakers
+// @Description List accounts which have published commitments to smart rollup. Staker is inactive if it recovered its bond or lost refutation game after its last commitment.
+// @Tags smart-rollups
+// @ID list-smart-rollup-stakers
+// @Param network path string true "network"
+// @Param address path string true "expr address of smart rollup" minlength(36) maxlength(36)
+// @Accept json
+// @Produce json
+// @Success 200 {array} SmartRollupStaker
+// @Failure 400 {object} Error
+// @Failure 404 {object} Error
+// @Failure 500 {object} Error
+// @Router /v1/smart_rollups/{network}/{address}/stakers [get]
+func GetSmartRollupStakers() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.MustGet("context").(*config.Context)
+
+		var req getSmartRollupRequest
+		if err := c.ShouldBindUri(&req); handleError(c, ctx.Storage, err, http.StatusBadRequest) {
+			return
+		}
+
+		rollup, err := ctx.Accounts.Get(c.Request.Context(), req.Address)
+		if handleError(c, ctx.Storage, err, 0) {
+			return
+		}
+
+		stakers, err := ctx.SmartRollups.Stakers(c.Request.Context(), rollup.ID)
+		if handleError(c, ctx.Storage, err, 0) {
+			return
+		}
+
+		response := make([]SmartRollupStaker, len(stakers))
+		for i := range stakers {
+			response[i] = NewSmartRollupStaker(stakers[i])
+		}
+		c.SecureJSON(http.StatusOK, response)
+	}
+}
+
+// GetSmartRollupGames godoc
+// @Summary List smart rollup refutation games
+// @Description List refutation games of smart rollup with their moves. Game is started by `smart_rollup_refute` and is finished by the move or by `smart_rollup_timeout`.
+// @Tags smart-rollups
+// @ID list-smart-rollup-games
+// @Param network path string true "network"
+// @Param address path string true "expr address of smart rollup" minlength(36) maxlength(36)
+// @Param size query integer false "Games count" mininum(1) maximum(10)
+// @Param offset query integer false "Offset" mininum(1)
+// @Param sort query string false "Sort order" Enums(asc, desc)
+// @Accept json
+// @Produce json
+// @Success 200 {array} SmartRollupGame
+// @Failure 400 {object} Error
+// @Failure 404 {object} Error
+// @Failure 500 {object} Error
+// @Router /v1/smart_rollups/{network}/{address}/games [get]
+func GetSmartRollupGames() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.MustGet("context").(*config.Context)
+
+		var req getSmartRollupRequest
+		if err := c.ShouldBindUri(&req); handleError(c, ctx.Storage, err, http.StatusBadRequest) {
+			return
+		}
+		var args smartRollupListRequest
+		if err := c.ShouldBindQuery(&args); handleError(c, ctx.Storage, err, http.StatusBadRequest) {
+			return
+		}
+
+		rollup, err := ctx.Accounts.Get(c.Request.Context(), req.Address)
+		if handleError(c, ctx.Storage, err, 0) {
+			return
+		}
+
+		starts, err := ctx.SmartRollups.Games(c.Request.Context(), rollup.ID, args.toListRequest())
+		if handleError(c, ctx.Storage, err, 0) {
+			return
+		}
+
+		response := make([]SmartRollupGame, len(starts))
+		for i := range starts {
+			moves, err := ctx.SmartRollups.GameMoves(c.Request.Context(), rollup.ID, starts[i])
+			if handleError(c, ctx.Storage, err, 0) {
+				return
+			}
+			response[i] = NewSmartRollupGame(starts[i], moves)
+		}
+		c.SecureJSON(http.StatusOK, response)
+	}
+}
+
+// GetSmartRollupOutbox godoc
+// @Summary List executed outbox messages of smart rollup
+// @Description List outbox messages executed with `smart_rollup_execute_outbox_message`. Transactions of message are decoded as contract calls.
+// @Tags smart-rollups
+// @ID list-smart-rollup-outbox
+// @Param network path string true "network"
+// @Param address path string true "expr address of smart rollup" minlength(36) maxlength(36)
+// @Param size query integer false "Messages count" mininum(1) maximum(10)
+// @Param offset query integer false "Offset" mininum(1)
+// @Param sort query string false "Sort order" Enums(asc, desc)
+// @Accept json
+// @Produce json
+// @Success 200 {array} SmartRollupOutboxMessage
+// @Failure 400 {object} Error
+// @Failure 404 {object} Error
+// @Failure 500 {object} Error
+// @Router /v1/smart_rollups/{network}/{address}/outbox [get]
+func GetSmartRollupOutbox() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.MustGet("context").(*config.Context)
+
+		var req getSmartRollupRequest
+		if err := c.ShouldBindUri(&req); handleError(c, ctx.Storage, err, http.StatusBadRequest) {
+			return
+		}
+		var args smartRollupListRequest
+		if err := c.ShouldBindQuery(&args); handleError(c, ctx.Storage, err, http.StatusBadRequest) {
+			return
+		}
+
+		rollup, err := ctx.Accounts.Get(c.Request.Context(), req.Address)
+		if handleError(c, ctx.Storage, err, 0) {
+			return
+		}
+
+		messages, err := ctx.SmartRollups.OutboxMessages(c.Request.Context(), rollup.ID, args.toListRequest())
+		if handleError(c, ctx.Storage, err, 0) {
+			return
+		}
+
+		response := make([]SmartRollupOutboxMessage, len(messages))
+		for i := range messages {
+			response[i] = NewSmartRollupOutboxMessage(messages[i])
+
+			operations, err := ctx.Operations.GetByHashAndCounter(c.Request.Context(), messages[i].Hash, messages[i].Counter)
+			if handleError(c, ctx.Storage, err, 0) {
+				return
+			}
+			transactions := make([]operation.Operation, 0, len(operations))
+			for j := range operations {
+				if operations[j].Internal && operations[j].Source.Address == req.Address {
+					transactions = append(transactions, operations[j])
+				}
+			}
+			response[i].Transactions, err = PrepareOperations(c.Request.Context(), ctx, transactions, false)
+			if handleError(c, ctx.Storage, err, 0) {
+				return
+			}
+		}
+		c.SecureJSON(http.StatusOK, response)
+	}
+}
@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"testing"
+
+	"github.com/baking-bad/bcdhub/internal/models/account"
+	smartrollup "github.com/baking-bad/bcdhub/internal/models/smart_rollup"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewSmartRollupGame(t *testing.T) {
+	alice := account.Account{Address: "tz1gjaF81ZRRvdzjobyfVNsAeSC6PScjfQwN"}
+	bob := account.Account{Address: "tz1SiPXX4MYGNJNDsRc7n8hkvUqFzg8xqF9m"}
+
+	start := smartrollup.Refutation{
+		ID:                 1,
+		Level:              100,
+		Player:             alice,
+		Opponent:           bob,
+		Kind:               smartrollup.RefutationKindStart,
+		PlayerCommitment:   "src1",
+		OpponentCommitment: "src2",
+		Status:             smartrollup.GameStatusOngoing,
+	}
+
+	t.Run("ongoing", func(t *testing.T) {
+		moves := []smartrollup.Refutation{
+			{Level: 101, Player: bob, Opponent: alice, Kind: smartrollup.RefutationKindMove, Status: smartrollup.GameStatusOngoing},
+		}
+		game := NewSmartRollupGame(start, moves)
+		require.Equal(t, smartrollup.GameStatusOngoing, game.Status)
+		require.Equal(t, alice.Address, game.Initiator)
+		require.Equal(t, "src1", game.InitiatorCommitment)
+		require.Len(t, game.Moves, 1)
+		require.Equal(t, bob.Address, game.Moves[0].Player)
+		require.Empty(t, game.Loser)
+		require.Zero(t, game.EndLevel)
+	})
+
+	t.Run("ended by timeout", func(t *testing.T) {
+		moves := []smartrollup.Refutation{
+			{Level: 101, Player: bob, Opponent: alice, Kind: smartrollup.RefutationKindMove, Status: smartrollup.GameStatusOngoing},
+			{Level: 120, Player: alice, Opponent: bob, Loser: bob, Kind: smartrollup.RefutationKindTimeout, Status: smartrollup.GameStatusEnded, Result: smartrollup.GameResultLoser, Reason: "timeout"},
+		}
+		game := NewSmartRollupGame(start, moves)
+		require.Equal(t, smartrollup.GameStatusEnded, game.Status)
+		require.Equal(t, smartrollup.GameResultLoser, game.Result)
+		require.Equal(t, "timeout", game.Reason)
+		require.Equal(t, bob.Address, game.Loser)
+		require.EqualValues(t, 120, game.EndLevel)
+		require.Len(t, game.Moves, 2)
+	})
+}
@@ -3,10 +3,15 @@ package handlers
 import (
 	"net/http"
 	"sort"
+	"strings"
+	"time"
 
 	"github.com/baking-bad/bcdhub/internal/config"
+	"github.com/baking-bad/bcdhub/internal/models/stats"
 	"github.com/baking-bad/bcdhub/internal/models/types"
+	"github.com/baking-bad/bcdhub/internal/noderpc"
 	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
 )
 
 // GetStats godoc
@@ -133,3 +138,128 @@ func ContractsCount() gin.HandlerFunc {
 		c.SecureJSON(http.StatusOK, stats.ContractsCount)
 	}
 }
+
+// GetRPCStatus godoc
+// @Summary Get health of RPC nodes
+// @Description Get head level, lag and latency of RPC nodes which are used by API in the network. Nodes of pool are probed in background, single node is probed on request.
+// @Tags statistics
+// @ID get-rpc-status
+// @Param network path string true "Network"
+// @Accept  json
+// @Produce  json
+// @Success 200 {array} RPCNodeStatus
+// @Failure 400 {object} Error
+// @Failure 404 {object} Error
+// @Failure 500 {object} Error
+// @Router /v1/stats/{network}/rpc [get]
+func GetRPCStatus() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.MustGet("context").(*config.Context)
+		if ctx.RPC == nil {
+			handleError(c, ctx.Storage, errors.Errorf("RPC is not set for %s", ctx.Network), http.StatusNotFound)
+			return
+		}
+
+		var statuses []noderpc.NodeStatus
+		if reporter, ok := ctx.RPC.(noderpc.StatusReporter); ok {
+			statuses = reporter.Status()
+		} else {
+			uri := ctx.Config.RPC[ctx.Network.String()].URI
+			statuses = append(statuses, noderpc.CheckNode(c.Request.Context(), uri, ctx.RPC))
+		}
+
+		response := make([]RPCNodeStatus, len(statuses))
+		for i := range statuses {
+			response[i] = NewRPCNodeStatus(statuses[i])
+		}
+		c.SecureJSON(http.StatusOK, response)
+	}
+}
+
+// default ranges of series if `from` isn't set
+const (
+	defaultHourSeriesRange = time.Hour * 24 * 7
+	defaultDaySeriesRange  = time.Hour * 24 * 90
+)
+
+// GetStatsSeries godoc
+// @Summary Get time series of stats
+// @Description Get hourly or daily aggregates of network or contract operations. `operations`, `gas` and `burned` metrics can be filtered by operation kind and status. `callers` is a count of unique senders of transactions to contracts.
+// @Tags statistics
+// @ID get-stats-series
+// @Param network path string true "Network"
+// @Param metric query string true "Metric" Enums(operations, callers, gas, burned, originations, events)
+// @Param bucket query string false "Time bucket" Enums(hour, day)
+// @Param contract query string false "KT address of contract. Network-wide series are returned if it's empty" minlength(36) maxlength(36)
+// @Param kind query string false "Comma-separated operation kinds"
+// @Param status query string false "Operation status" Enums(applied, backtracked, failed, skipped)
+// @Param from query integer false "Timestamp of range start (inclusive). Default is 7 days before `to` for hourly buckets and 90 days before `to` for daily ones." mininum(0)
+// @Param to query integer false "Timestamp of range end (exclusive). Default is now." mininum(0)
+// @Accept  json
+// @Produce  json
+// @Success 200 {array} SeriesPoint
+// @Failure 400 {object} Error
+// @Failure 404 {object} Error
+// @Failure 500 {object} Error
+// @Router /v1/stats/{network}/series [get]
+func GetStatsSeries() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.MustGet("context").(*config.Context)
+
+		var args statsSeriesRequest
+		if err := c.ShouldBindQuery(&args); handleError(c, ctx.Storage, err, http.StatusBadRequest) {
+			return
+		}
+
+		req := newSeriesRequest(args, time.Now().UTC())
+		if args.Contract != "" {
+			acc, err := ctx.Accounts.Get(c.Request.Context(), args.Contract)
+			if handleError(c, ctx.Storage, err, 0) {
+				return
+			}
+			req.ContractId = acc.ID
+		}
+
+		points, err := ctx.Stats.Series(c.Request.Context(), req)
+		if handleError(c, ctx.Storage, err, 0) {
+			return
+		}
+
+		response := make([]SeriesPoint, len(points))
+		for i := range points {
+			response[i] = SeriesPoint{
+				Timestamp: points[i].Timestamp.UTC(),
+				Value:     points[i].Value,
+			}
+		}
+		c.SecureJSON(http.StatusOK, response)
+	}
+}
+
+func newSeriesRequest(args statsSeriesRequest, now time.Time) stats.SeriesRequest {
+	req := stats.SeriesRequest{
+		Metric: args.Metric,
+		Bucket: args.Bucket,
+		Status: types.NewOperationStatus(args.Status),
+		To:     now,
+	}
+	if req.Bucket == "" {
+		req.Bucket = stats.BucketDay
+	}
+	if args.Kind != "" {
+		for _, kind := range strings.Split(args.Kind, ",") {
+			req.Kinds = append(req.Kinds, types.NewOperationKind(kind))
+		}
+	}
+	if args.To > 0 {
+		req.To = time.Unix(args.To, 0).UTC()
+	}
+	if args.From > 0 {
+		req.From = time.Unix(args.From, 0).UTC()
+	} else if req.Bucket == stats.BucketHour {
+		req.From = req.To.Add(-defaultHourSeriesRange)
+	} else {
+		req.From = req.To.Add(-defaultDaySeriesRange)
+	}
+	return req
+}
@@ -0,0 +1,68 @@
+package handlers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/baking-bad/bcdhub/internal/models/stats"
+	modelTypes "github.com/baking-bad/bcdhub/internal/models/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewSeriesRequest(t *testing.T) {
+	now := time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		args statsSeriesRequest
+		want stats.SeriesRequest
+	}{
+		{
+			name: "defaults",
+			args: statsSeriesRequest{
+				Metric: stats.MetricOperations,
+			},
+			want: stats.SeriesRequest{
+				Metric: stats.MetricOperations,
+				Bucket: stats.BucketDay,
+				From:   now.Add(-defaultDaySeriesRange),
+				To:     now,
+			},
+		}, {
+			name: "hourly defaults",
+			args: statsSeriesRequest{
+				Metric: stats.MetricCallers,
+				Bucket: stats.BucketHour,
+			},
+			want: stats.SeriesRequest{
+				Metric: stats.MetricCallers,
+				Bucket: stats.BucketHour,
+				From:   now.Add(-defaultHourSeriesRange),
+				To:     now,
+			},
+		}, {
+			name: "all filters",
+			args: statsSeriesRequest{
+				Metric: stats.MetricGas,
+				Bucket: stats.BucketHour,
+				Kind:   "transaction,origination",
+				Status: "failed",
+				From:   1700000000,
+				To:     1700003600,
+			},
+			want: stats.SeriesRequest{
+				Metric: stats.MetricGas,
+				Bucket: stats.BucketHour,
+				Kinds:  []modelTypes.OperationKind{modelTypes.OperationKindTransaction, modelTypes.OperationKindOrigination},
+				Status: modelTypes.OperationStatusFailed,
+				From:   time.Date(2023, 11, 14, 22, 13, 20, 0, time.UTC),
+				To:     time.Date(2023, 11, 14, 23, 13, 20, 0, time.UTC),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, newSeriesRequest(tt.args, now))
+		})
+	}
+}
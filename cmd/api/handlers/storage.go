@@ -10,14 +10,20 @@ import (
 	"github.com/baking-bad/bcdhub/internal/config"
 	"github.com/baking-bad/bcdhub/internal/models/bigmapdiff"
 	"github.com/baking-bad/bcdhub/internal/models/block"
+	"github.com/baking-bad/bcdhub/internal/models/migration"
 	"github.com/baking-bad/bcdhub/internal/models/operation"
 	"github.com/baking-bad/bcdhub/internal/models/types"
+	"github.com/baking-bad/bcdhub/internal/postgres/core"
 	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
 )
 
+// errStorageNotIndexed - historical storage can't be rebuilt from indexed data
+var errStorageNotIndexed = errors.New("storage at the level isn't indexed")
+
 // GetContractStorage godoc
 // @Summary Get contract storage
-// @Description Get contract storage
+// @Description Get contract storage. Storage at the `level` is rebuilt from indexed operations and protocol migrations without requests to node. 404 is returned if it can't be rebuilt.
 // @Tags contract
 // @ID get-contract-storage
 // @Param network path string true "Network"
@@ -125,7 +131,7 @@ func GetContractStorageRaw() gin.HandlerFunc {
 
 // GetContractStorageRich godoc
 // @Summary Get contract rich storage
-// @Description Get contract rich storage
+// @Description Get contract rich storage: storage with big map contents. If `level` is set, big map contents are returned as of the level.
 // @Tags contract
 // @ID get-contract-storage-rich
 // @Param network path string true "Network"
@@ -156,24 +162,37 @@ func GetContractStorageRich() gin.HandlerFunc {
 			return
 		}
 
-		block, err := ctx.Blocks.Get(c.Request.Context(), int64(sReq.Level))
-		if handleError(c, ctx.Storage, err, 0) {
-			return
+		var header block.Block
+		if sReq.Level == 0 {
+			header, err = ctx.Blocks.Last(c.Request.Context())
+		} else {
+			header, err = ctx.Blocks.Get(c.Request.Context(), int64(sReq.Level))
 		}
-
-		storageType, err := getStorageType(c.Request.Context(), ctx.Contracts, req.Address, block.Protocol.SymLink)
 		if handleError(c, ctx.Storage, err, 0) {
 			return
 		}
 
-		states, err := ctx.BigMapDiffs.GetForAddress(c.Request.Context(), req.Address)
+		storageType, err := getStorageType(c.Request.Context(), ctx.Contracts, req.Address, header.Protocol.SymLink)
 		if handleError(c, ctx.Storage, err, 0) {
 			return
 		}
 
-		bmd := make([]bigmapdiff.BigMapDiff, 0, len(states))
-		for i := range states {
-			bmd = append(bmd, states[i].ToDiff())
+		var bmd []bigmapdiff.BigMapDiff
+		if sReq.Level == 0 {
+			states, err := ctx.BigMapDiffs.GetForAddress(c.Request.Context(), req.Address)
+			if handleError(c, ctx.Storage, err, 0) {
+				return
+			}
+
+			bmd = make([]bigmapdiff.BigMapDiff, 0, len(states))
+			for i := range states {
+				bmd = append(bmd, states[i].ToDiff())
+			}
+		} else {
+			bmd, err = getBigMapDiffsAtLevel(c.Request.Context(), ctx, req.Address, storageType, storage, int64(sReq.Level))
+			if handleError(c, ctx.Storage, err, 0) {
+				return
+			}
 		}
 
 		if err := prepareStorage(storageType, storage, bmd); handleError(c, ctx.Storage, err, 0) {
@@ -288,6 +307,10 @@ func GetContractStorageSchema() gin.HandlerFunc {
 }
 
 func getDeffattedStorage(c context.Context, ctx *config.Context, address string, level int64) ([]byte, error) {
+	if level > 0 {
+		return getHistoricalStorage(c, ctx, address, level)
+	}
+
 	destination, err := ctx.Accounts.Get(c, address)
 	if err != nil {
 		return nil, err
@@ -298,9 +321,6 @@ func getDeffattedStorage(c context.Context, ctx *config.Context, address string,
 		"status":         types.OperationStatusApplied,
 		"last_action":    destination.LastAction,
 	}
-	if level > 0 {
-		filters["level"] = level
-	}
 	operation, err := ctx.Operations.Last(c, filters, 0)
 	switch {
 	case err != nil && !ctx.Storage.IsRecordNotFound(err):
@@ -326,6 +346,94 @@ func getDeffattedStorage(c context.Context, ctx *config.Context, address string,
 	}
 }
 
+// getHistoricalStorage - rebuilds storage at the level from indexed data without requests to node: it's the storage of the last applied operation
+// to the contract at or before the level or the storage set by the last protocol migration if the contract was migrated after the operation.
+func getHistoricalStorage(c context.Context, ctx *config.Context, address string, level int64) ([]byte, error) {
+	destination, err := ctx.Accounts.Get(c, address)
+	if err != nil {
+		return nil, err
+	}
+
+	filters := map[string]interface{}{
+		"destination_id": destination.ID,
+		"status":         types.OperationStatusApplied,
+	}
+	// timestamps of blocks strictly increase, so operations before the next block are the operations at or before the level.
+	// If there isn't the next block the level is head and all operations are suitable.
+	next, err := ctx.Blocks.Get(c, level+1)
+	switch {
+	case err == nil:
+		filters["timestamp"] = core.TimestampFilter{
+			Lt: next.Timestamp,
+		}
+	case !ctx.Storage.IsRecordNotFound(err):
+		return nil, err
+	}
+
+	operation, err := ctx.Operations.Last(c, filters, 0)
+	if err != nil && !ctx.Storage.IsRecordNotFound(err) {
+		return nil, err
+	}
+	hasOperation := err == nil && len(operation.DeffatedStorage) > 0
+
+	migration, err := lastStorageMigration(c, ctx, address, level)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case migration != nil && (!hasOperation || migration.Level > operation.Level):
+		if len(migration.DeffatedStorage) == 0 {
+			return nil, errors.Wrapf(errStorageNotIndexed, "%s was migrated at level %d before migration storage was indexed", address, migration.Level)
+		}
+		return migration.DeffatedStorage, nil
+	case hasOperation:
+		return operation.DeffatedStorage, nil
+	default:
+		return nil, errors.Wrapf(errStorageNotIndexed, "%s at level %d", address, level)
+	}
+}
+
+// lastStorageMigration - returns the last protocol migration which updated contract's storage at or before the level. It returns nil if there isn't such migration.
+func lastStorageMigration(c context.Context, ctx *config.Context, address string, level int64) (*migration.Migration, error) {
+	contract, err := ctx.Contracts.Get(c, address)
+	if err != nil {
+		return nil, err
+	}
+	migrations, err := ctx.Migrations.Get(c, contract.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	var last *migration.Migration
+	for i := range migrations {
+		if migrations[i].Kind != types.MigrationKindUpdate || migrations[i].Level > level {
+			continue
+		}
+		if last == nil || migrations[i].Level > last.Level {
+			last = &migrations[i]
+		}
+	}
+	return last, nil
+}
+
+// getBigMapDiffsAtLevel - returns state of big maps which are referenced by the storage as of the level
+func getBigMapDiffsAtLevel(c context.Context, ctx *config.Context, address string, storageType *ast.TypedAst, storage []byte, level int64) ([]bigmapdiff.BigMapDiff, error) {
+	settled := &ast.TypedAst{
+		Nodes: []ast.Node{ast.Copy(storageType.Nodes[0])},
+	}
+	if err := settled.SettleFromBytes(storage); err != nil {
+		return nil, err
+	}
+
+	bigMaps := settled.FindBigMapByPtr()
+	ptrs := make([]int64, 0, len(bigMaps))
+	for ptr := range bigMaps {
+		ptrs = append(ptrs, ptr)
+	}
+	return ctx.BigMapDiffs.GetAtLevel(c, address, level, ptrs...)
+}
+
 func getInitialOperation(c context.Context, ctx *config.Context, address string) (operation.Operation, error) {
 	destination, err := ctx.Accounts.Get(c, address)
 	if err != nil {
@@ -0,0 +1,163 @@
+package handlers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/baking-bad/bcdhub/internal/config"
+	"github.com/baking-bad/bcdhub/internal/models/account"
+	"github.com/baking-bad/bcdhub/internal/models/block"
+	"github.com/baking-bad/bcdhub/internal/models/contract"
+	"github.com/baking-bad/bcdhub/internal/models/migration"
+	"github.com/baking-bad/bcdhub/internal/models/mock"
+	mock_accounts "github.com/baking-bad/bcdhub/internal/models/mock/account"
+	mock_block "github.com/baking-bad/bcdhub/internal/models/mock/block"
+	mock_contract "github.com/baking-bad/bcdhub/internal/models/mock/contract"
+	mock_migration "github.com/baking-bad/bcdhub/internal/models/mock/migration"
+	mock_operation "github.com/baking-bad/bcdhub/internal/models/mock/operation"
+	"github.com/baking-bad/bcdhub/internal/models/operation"
+	modelTypes "github.com/baking-bad/bcdhub/internal/models/types"
+	"github.com/baking-bad/bcdhub/internal/noderpc"
+	"github.com/baking-bad/bcdhub/internal/postgres/core"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestGetHistoricalStorage(t *testing.T) {
+	const (
+		address   = "KT1Hkg5qeNhfwpKW4fXvq7HGZB9z2EnmCCA9"
+		level     = int64(200)
+		opLevel   = int64(150)
+		indexed   = `{"int":"1"}`
+		migrated  = `{"int":"2"}`
+		accountID = int64(10)
+	)
+	nextTimestamp := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	errNotFound := errors.New("not found")
+
+	tests := []struct {
+		name       string
+		isHead     bool
+		opErr      error
+		migrations []migration.Migration
+		want       string
+		wantErr    error
+	}{
+		{
+			name: "from indexed operation",
+			want: indexed,
+		}, {
+			name:   "at head",
+			isHead: true,
+			want:   indexed,
+		}, {
+			name: "migration before operation",
+			migrations: []migration.Migration{
+				{Level: 100, Kind: modelTypes.MigrationKindUpdate, DeffatedStorage: []byte(migrated)},
+			},
+			want: indexed,
+		}, {
+			name: "lambda migration after operation",
+			migrations: []migration.Migration{
+				{Level: 180, Kind: modelTypes.MigrationKindLambda},
+			},
+			want: indexed,
+		}, {
+			name: "update migration after operation",
+			migrations: []migration.Migration{
+				{Level: 100, Kind: modelTypes.MigrationKindUpdate},
+				{Level: 180, Kind: modelTypes.MigrationKindUpdate, DeffatedStorage: []byte(migrated)},
+				{Level: 250, Kind: modelTypes.MigrationKindUpdate},
+			},
+			want: migrated,
+		}, {
+			name: "update migration without storage",
+			migrations: []migration.Migration{
+				{Level: 180, Kind: modelTypes.MigrationKindUpdate},
+			},
+			wantErr: errStorageNotIndexed,
+		}, {
+			name:  "migration without operations",
+			opErr: errNotFound,
+			migrations: []migration.Migration{
+				{Level: 180, Kind: modelTypes.MigrationKindUpdate, DeffatedStorage: []byte(migrated)},
+			},
+			want: migrated,
+		}, {
+			name:    "without operations",
+			opErr:   errNotFound,
+			wantErr: errStorageNotIndexed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			general := mock.NewMockGeneralRepository(ctrl)
+			general.EXPECT().IsRecordNotFound(gomock.Any()).
+				DoAndReturn(func(err error) bool { return errors.Is(err, errNotFound) }).
+				AnyTimes()
+
+			accounts := mock_accounts.NewMockRepository(ctrl)
+			accounts.EXPECT().Get(gomock.Any(), address).
+				Return(account.Account{ID: accountID, Address: address}, nil).
+				Times(1)
+
+			filters := map[string]interface{}{
+				"destination_id": accountID,
+				"status":         modelTypes.OperationStatusApplied,
+			}
+			blocks := mock_block.NewMockRepository(ctrl)
+			if tt.isHead {
+				blocks.EXPECT().Get(gomock.Any(), level+1).
+					Return(block.Block{}, errNotFound).
+					Times(1)
+			} else {
+				blocks.EXPECT().Get(gomock.Any(), level+1).
+					Return(block.Block{Level: level + 1, Timestamp: nextTimestamp}, nil).
+					Times(1)
+				filters["timestamp"] = core.TimestampFilter{Lt: nextTimestamp}
+			}
+
+			operations := mock_operation.NewMockRepository(ctrl)
+			operations.EXPECT().
+				Last(gomock.Any(), filters, int64(0)).
+				Return(operation.Operation{Level: opLevel, DeffatedStorage: []byte(indexed)}, tt.opErr).
+				Times(1)
+
+			contracts := mock_contract.NewMockRepository(ctrl)
+			contracts.EXPECT().Get(gomock.Any(), address).
+				Return(contract.Contract{ID: 5}, nil).
+				Times(1)
+			migrations := mock_migration.NewMockRepository(ctrl)
+			migrations.EXPECT().Get(gomock.Any(), int64(5)).
+				Return(tt.migrations, nil).
+				Times(1)
+
+			// node mustn't be requested
+			rpc := noderpc.NewMockINode(ctrl)
+
+			ctx := &config.Context{
+				RPC:        rpc,
+				Storage:    general,
+				Accounts:   accounts,
+				Blocks:     blocks,
+				Contracts:  contracts,
+				Migrations: migrations,
+				Operations: operations,
+			}
+
+			got, err := getDeffattedStorage(context.Background(), ctx, address, level)
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, string(got))
+		})
+	}
+}
@@ -0,0 +1,311 @@
+package handlers
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/baking-bad/bcdhub/internal/bcd"
+	"github.com/baking-bad/bcdhub/internal/bcd/consts"
+	"github.com/baking-bad/bcdhub/internal/config"
+	"github.com/baking-bad/bcdhub/internal/events"
+	"github.com/baking-bad/bcdhub/internal/models/operation"
+	"github.com/baking-bad/bcdhub/internal/models/types"
+	"github.com/dipdup-io/workerpool"
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	streamBufferSize = 64
+	streamPingPeriod = 30 * time.Second
+)
+
+// Stream message types
+const (
+	StreamMessageOperations = "operations"
+	StreamMessageRollback   = "rollback"
+)
+
+// StreamMessage -
+type StreamMessage struct {
+	Type       string      `json:"type"`
+	Network    string      `json:"network"`
+	Level      int64       `json:"level"`
+	Operations []Operation `json:"operations,omitempty"`
+}
+
+type streamSubscriber struct {
+	network  types.Network
+	filter   streamRequest
+	messages chan StreamMessage
+}
+
+func (s *streamSubscriber) match(op operation.Operation) bool {
+	if s.filter.Destination != "" && op.Destination.Address != s.filter.Destination {
+		return false
+	}
+	if s.filter.Entrypoint != "" && op.Entrypoint.String() != s.filter.Entrypoint {
+		return false
+	}
+	if s.filter.Tag != "" {
+		tag := types.NewTags([]string{s.filter.Tag})
+		if !op.Tags.Has(tag) {
+			return false
+		}
+	}
+	if s.filter.Status != "" && !slices.Contains(strings.Split(s.filter.Status, ","), op.Status.String()) {
+		return false
+	}
+	return true
+}
+
+// OperationsHub - receives indexer notifications and broadcasts newly indexed operations to stream subscribers
+type OperationsHub struct {
+	ctxs config.Contexts
+
+	subscribers map[*streamSubscriber]struct{}
+	mx          sync.RWMutex
+
+	g workerpool.Group
+}
+
+// NewOperationsHub -
+func NewOperationsHub(ctxs config.Contexts) *OperationsHub {
+	return &OperationsHub{
+		ctxs:        ctxs,
+		subscribers: make(map[*streamSubscriber]struct{}),
+		g:           workerpool.NewGroup(),
+	}
+}
+
+// Start -
+func (hub *OperationsHub) Start(ctx context.Context) {
+	for network, cfgCtx := range hub.ctxs {
+		notifications := cfgCtx.StorageDB.Listen(ctx, events.Channel(network))
+		hub.g.GoCtx(ctx, func(ctx context.Context) {
+			hub.listen(ctx, cfgCtx, notifications)
+		})
+	}
+}
+
+// Close -
+func (hub *OperationsHub) Close() error {
+	hub.g.Wait()
+
+	hub.mx.Lock()
+	for sub := range hub.subscribers {
+		close(sub.messages)
+		delete(hub.subscribers, sub)
+	}
+	hub.mx.Unlock()
+	return nil
+}
+
+func (hub *OperationsHub) subscribe(network types.Network, filter streamRequest) *streamSubscriber {
+	sub := &streamSubscriber{
+		network:  network,
+		filter:   filter,
+		messages: make(chan StreamMessage, streamBufferSize),
+	}
+
+	hub.mx.Lock()
+	hub.subscribers[sub] = struct{}{}
+	hub.mx.Unlock()
+	return sub
+}
+
+func (hub *OperationsHub) unsubscribe(sub *streamSubscriber) {
+	hub.mx.Lock()
+	if _, ok := hub.subscribers[sub]; ok {
+		close(sub.messages)
+		delete(hub.subscribers, sub)
+	}
+	hub.mx.Unlock()
+}
+
+func (hub *OperationsHub) networkSubscribers(network types.Network) []*streamSubscriber {
+	hub.mx.RLock()
+	defer hub.mx.RUnlock()
+
+	subs := make([]*streamSubscriber, 0)
+	for sub := range hub.subscribers {
+		if sub.network == network {
+			subs = append(subs, sub)
+		}
+	}
+	return subs
+}
+
+// send - drops subscriber which can't receive messages in time. Client should reconnect and request missed operations.
+func (hub *OperationsHub) send(sub *streamSubscriber, msg StreamMessage) {
+	hub.mx.Lock()
+	defer hub.mx.Unlock()
+
+	if _, ok := hub.subscribers[sub]; !ok {
+		return
+	}
+
+	select {
+	case sub.messages <- msg:
+	default:
+		log.Warn().Str("network", sub.network.String()).Msg("stream subscriber is too slow: disconnecting")
+		close(sub.messages)
+		delete(hub.subscribers, sub)
+	}
+}
+
+func (hub *OperationsHub) listen(ctx context.Context, cfgCtx *config.Context, notifications <-chan string) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case payload, ok := <-notifications:
+			if !ok {
+				return
+			}
+			msg, err := events.Parse(payload)
+			if err != nil {
+				log.Err(err).Str("network", cfgCtx.Network.String()).Msg("invalid indexer notification")
+				continue
+			}
+
+			switch msg.Type {
+			case events.MessageTypeBlock:
+				if err := hub.handleBlock(ctx, cfgCtx, msg.Level); err != nil {
+					log.Err(err).Str("network", cfgCtx.Network.String()).Int64("level", msg.Level).Msg("stream operations")
+				}
+			case events.MessageTypeRollback:
+				for _, sub := range hub.networkSubscribers(cfgCtx.Network) {
+					hub.send(sub, StreamMessage{
+						Type:    StreamMessageRollback,
+						Network: cfgCtx.Network.String(),
+						Level:   msg.Level,
+					})
+				}
+			}
+		}
+	}
+}
+
+func (hub *OperationsHub) handleBlock(ctx context.Context, cfgCtx *config.Context, level int64) error {
+	subs := hub.networkSubscribers(cfgCtx.Network)
+	if len(subs) == 0 {
+		return nil
+	}
+
+	operations, err := cfgCtx.Operations.GetByLevel(ctx, level)
+	if err != nil {
+		return err
+	}
+	if len(operations) == 0 {
+		return nil
+	}
+
+	prepared := make(map[int]Operation)
+	for _, sub := range subs {
+		response := make([]Operation, 0)
+		for i := range operations {
+			if !sub.match(operations[i]) {
+				continue
+			}
+			op, ok := prepared[i]
+			if !ok {
+				op, err = prepareOperation(ctx, cfgCtx, operations[i], false)
+				if err != nil {
+					return err
+				}
+				prepared[i] = op
+			}
+			response = append(response, op)
+		}
+
+		if len(response) == 0 {
+			continue
+		}
+
+		hub.send(sub, StreamMessage{
+			Type:       StreamMessageOperations,
+			Network:    cfgCtx.Network.String(),
+			Level:      level,
+			Operations: response,
+		})
+	}
+	return nil
+}
+
+// validateStreamEntrypoint - checks that destination contract has the entrypoint, so subscriber doesn't wait for operations which can't happen
+func validateStreamEntrypoint(ctx context.Context, cfgCtx *config.Context, req streamRequest) error {
+	if req.Entrypoint == "" || !bcd.IsContract(req.Destination) {
+		return nil
+	}
+	contract, err := cfgCtx.Contracts.Get(ctx, req.Destination)
+	if err != nil {
+		if cfgCtx.Storage.IsRecordNotFound(err) {
+			return errors.Errorf("unknown destination contract: %s", req.Destination)
+		}
+		return err
+	}
+	if req.Entrypoint == consts.DefaultEntrypoint {
+		return nil
+	}
+	if script := contract.CurrentScript(); script != nil && slices.Contains(script.Entrypoints, req.Entrypoint) {
+		return nil
+	}
+	return errors.Errorf("contract %s doesn't have entrypoint %s", req.Destination, req.Entrypoint)
+}
+
+// StreamOperations godoc
+// @Summary Stream of newly indexed operations
+// @Description Server-sent events stream. `operations` event contains operations of the indexed block matched to filters, `rollback` event contains level which indexer state was rolled back to. `ping` event is sent periodically to keep connection alive.
+// @Tags operations
+// @ID stream-operations
+// @Param network path string true "Network"
+// @Param destination query string false "Destination address" minlength(36) maxlength(36)
+// @Param entrypoint query string false "Entrypoint. It should be one of destination's entrypoints if destination is contract"
+// @Param tag query string false "Operation tag, e.g. fa2"
+// @Param status query string false "Comma-separated operation statuses: applied, failed, backtracked, skipped"
+// @Produce text/event-stream
+// @Success 200 {object} StreamMessage
+// @Failure 400 {object} Error
+// @Router /v1/stream/{network} [get]
+func StreamOperations(hub *OperationsHub) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.MustGet("context").(*config.Context)
+
+		var req streamRequest
+		if err := c.ShouldBindQuery(&req); handleError(c, ctx.Storage, err, http.StatusBadRequest) {
+			return
+		}
+		if err := validateStreamEntrypoint(c.Request.Context(), ctx, req); handleError(c, ctx.Storage, err, http.StatusBadRequest) {
+			return
+		}
+
+		sub := hub.subscribe(ctx.Network, req)
+		defer hub.unsubscribe(sub)
+
+		ticker := time.NewTicker(streamPingPeriod)
+		defer ticker.Stop()
+
+		c.Stream(func(w io.Writer) bool {
+			select {
+			case <-c.Request.Context().Done():
+				return false
+			case <-ticker.C:
+				c.SSEvent("ping", gin.H{})
+				return true
+			case msg, ok := <-sub.messages:
+				if !ok {
+					return false
+				}
+				c.SSEvent(msg.Type, msg)
+				return true
+			}
+		})
+	}
+}
@@ -0,0 +1,208 @@
+package handlers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/baking-bad/bcdhub/internal/cache"
+	"github.com/baking-bad/bcdhub/internal/config"
+	"github.com/baking-bad/bcdhub/internal/models/account"
+	"github.com/baking-bad/bcdhub/internal/models/contract"
+	mock_general "github.com/baking-bad/bcdhub/internal/models/mock"
+	mock_contract "github.com/baking-bad/bcdhub/internal/models/mock/contract"
+	mock_operation "github.com/baking-bad/bcdhub/internal/models/mock/operation"
+	mock_protocol "github.com/baking-bad/bcdhub/internal/models/mock/protocol"
+	"github.com/baking-bad/bcdhub/internal/models/operation"
+	"github.com/baking-bad/bcdhub/internal/models/protocol"
+	modelTypes "github.com/baking-bad/bcdhub/internal/models/types"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestStreamSubscriber_match(t *testing.T) {
+	entrypoint := "transfer"
+	op := operation.Operation{
+		Destination: account.Account{Address: "KT1Hkg5qeNhfwpKW4fXvq7HGZB9z2EnmCCA9"},
+		Entrypoint:  modelTypes.NewNullString(&entrypoint),
+		Tags:        modelTypes.FA2Tag,
+		Status:      modelTypes.OperationStatusApplied,
+	}
+
+	tests := []struct {
+		name   string
+		filter streamRequest
+		want   bool
+	}{
+		{
+			name: "empty filter",
+			want: true,
+		}, {
+			name: "all filters",
+			filter: streamRequest{
+				Destination: "KT1Hkg5qeNhfwpKW4fXvq7HGZB9z2EnmCCA9",
+				Entrypoint:  "transfer",
+				Tag:         "fa2",
+				Status:      "failed,applied",
+			},
+			want: true,
+		}, {
+			name:   "another destination",
+			filter: streamRequest{Destination: "KT1BSdLSx6TTyVwTTRUXAwD3kiVxf1q3q4st"},
+		}, {
+			name:   "another entrypoint",
+			filter: streamRequest{Entrypoint: "update_operators"},
+		}, {
+			name:   "another tag",
+			filter: streamRequest{Tag: "fa1-2"},
+		}, {
+			name:   "another status",
+			filter: streamRequest{Status: "failed"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sub := streamSubscriber{filter: tt.filter}
+			require.Equal(t, tt.want, sub.match(op))
+		})
+	}
+}
+
+func TestValidateStreamEntrypoint(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	const (
+		address = "KT1Hkg5qeNhfwpKW4fXvq7HGZB9z2EnmCCA9"
+		unknown = "KT1BSdLSx6TTyVwTTRUXAwD3kiVxf1q3q4st"
+	)
+
+	notFound := errors.New("not found")
+	contracts := mock_contract.NewMockRepository(ctrl)
+	contracts.EXPECT().
+		Get(gomock.Any(), address).
+		Return(contract.Contract{
+			AlphaID: 1,
+			Alpha: contract.Script{
+				ID:          1,
+				Entrypoints: []string{"transfer", "update_operators"},
+			},
+		}, nil).
+		AnyTimes()
+	contracts.EXPECT().
+		Get(gomock.Any(), unknown).
+		Return(contract.Contract{}, notFound).
+		AnyTimes()
+
+	storage := mock_general.NewMockGeneralRepository(ctrl)
+	storage.EXPECT().
+		IsRecordNotFound(notFound).
+		Return(true).
+		AnyTimes()
+
+	cfgCtx := &config.Context{
+		Contracts: contracts,
+		Storage:   storage,
+	}
+
+	tests := []struct {
+		name    string
+		req     streamRequest
+		wantErr bool
+	}{
+		{
+			name: "without entrypoint",
+			req:  streamRequest{Destination: unknown},
+		}, {
+			name: "without destination",
+			req:  streamRequest{Entrypoint: "transfer"},
+		}, {
+			name: "existing entrypoint",
+			req:  streamRequest{Destination: address, Entrypoint: "transfer"},
+		}, {
+			name: "default entrypoint",
+			req:  streamRequest{Destination: address, Entrypoint: "default"},
+		}, {
+			name:    "unknown entrypoint",
+			req:     streamRequest{Destination: address, Entrypoint: "mint"},
+			wantErr: true,
+		}, {
+			name:    "unknown contract",
+			req:     streamRequest{Destination: unknown, Entrypoint: "transfer"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateStreamEntrypoint(context.Background(), cfgCtx, tt.req)
+			require.Equal(t, tt.wantErr, err != nil, err)
+		})
+	}
+}
+
+func TestOperationsHub_handleBlock(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	protocols := mock_protocol.NewMockRepository(ctrl)
+	protocols.EXPECT().
+		GetByID(gomock.Any(), int64(1)).
+		Return(protocol.Protocol{
+			ID:   1,
+			Hash: "PtSeouLouXkxhg39oWzjxDWaCydNfR3RxCUrNe4Q9Ro8BTehcbh",
+		}, nil).
+		AnyTimes()
+
+	operations := mock_operation.NewMockRepository(ctrl)
+	operations.EXPECT().
+		GetByLevel(gomock.Any(), int64(100)).
+		Return([]operation.Operation{
+			{
+				Kind:        modelTypes.OperationKindEvent,
+				Level:       100,
+				ProtocolID:  1,
+				Status:      modelTypes.OperationStatusApplied,
+				PayloadType: []byte(`{"prim":"unit"}`),
+			}, {
+				Kind:        modelTypes.OperationKindEvent,
+				Level:       100,
+				ProtocolID:  1,
+				Status:      modelTypes.OperationStatusFailed,
+				PayloadType: []byte(`{"prim":"unit"}`),
+			},
+		}, nil).
+		Times(1)
+
+	cfgCtx := &config.Context{
+		Network:    modelTypes.Mainnet,
+		Operations: operations,
+		Cache:      cache.NewCache(nil, nil, nil, protocols, nil),
+	}
+	hub := NewOperationsHub(config.Contexts{modelTypes.Mainnet: cfgCtx})
+
+	all := hub.subscribe(modelTypes.Mainnet, streamRequest{})
+	applied := hub.subscribe(modelTypes.Mainnet, streamRequest{Status: "applied"})
+	backtracked := hub.subscribe(modelTypes.Mainnet, streamRequest{Status: "backtracked"})
+	other := hub.subscribe(modelTypes.Ghostnet, streamRequest{})
+
+	require.NoError(t, hub.handleBlock(context.Background(), cfgCtx, 100))
+
+	msg := <-all.messages
+	require.Equal(t, StreamMessageOperations, msg.Type)
+	require.Equal(t, "mainnet", msg.Network)
+	require.EqualValues(t, 100, msg.Level)
+	require.Len(t, msg.Operations, 2)
+
+	msg = <-applied.messages
+	require.Len(t, msg.Operations, 1)
+	require.Equal(t, "applied", msg.Operations[0].Status)
+
+	require.Empty(t, backtracked.messages)
+	require.Empty(t, other.messages)
+
+	hub.unsubscribe(all)
+	_, ok := <-all.messages
+	require.False(t, ok)
+
+	require.NoError(t, hub.Close())
+}
@@ -6,8 +6,12 @@ import (
 
 	"github.com/baking-bad/bcdhub/internal/bcd/encoding"
 	"github.com/baking-bad/bcdhub/internal/config"
+	"github.com/baking-bad/bcdhub/internal/models/account"
 	"github.com/baking-bad/bcdhub/internal/models/ticket"
+	"github.com/baking-bad/bcdhub/internal/models/types"
 	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+	"github.com/shopspring/decimal"
 )
 
 // GetContractTicketUpdates godoc
@@ -224,3 +228,419 @@ func prepareTickets(tickets []ticket.Ticket) ([]Ticket, error) {
 	}
 	return response, nil
 }
+
+// maxTicketFlowUpdates - limit of ticket updates which may be aggregated to flow graph by one request
+const maxTicketFlowUpdates = 10000
+
+var errTooManyTicketUpdates = errors.Errorf("too many ticket updates in level range, max is %d: narrow the range", maxTicketFlowUpdates)
+
+// kinds of ticket flow edges
+const (
+	ticketFlowTransfer = "transfer"
+	ticketFlowMint     = "mint"
+	ticketFlowBurn     = "burn"
+)
+
+// GetTicket godoc
+// @Summary Get ticket
+// @Description Get ticket by its hash with current total supply and holders count
+// @Tags tickets
+// @ID get-ticket
+// @Param network path string true "network"
+// @Param hash path string true "Ticket hash" minlength(64) maxlength(64)
+// @Accept json
+// @Produce json
+// @Success 200 {object} TicketInfo
+// @Failure 400 {object} Error
+// @Failure 404 {object} Error
+// @Failure 500 {object} Error
+// @Router /v1/ticket/{network}/{hash} [get]
+func GetTicket() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.MustGet("context").(*config.Context)
+
+		var req getTicketRequest
+		if err := c.ShouldBindUri(&req); handleError(c, ctx.Storage, err, http.StatusBadRequest) {
+			return
+		}
+
+		t, err := ctx.Tickets.Get(c.Request.Context(), req.Hash)
+		if handleError(c, ctx.Storage, err, 0) {
+			return
+		}
+		summary, err := ctx.Tickets.Summary(c.Request.Context(), t.ID)
+		if handleError(c, ctx.Storage, err, 0) {
+			return
+		}
+		response, err := NewTicket(t)
+		if handleError(c, ctx.Storage, err, 0) {
+			return
+		}
+		c.SecureJSON(http.StatusOK, TicketInfo{
+			Ticket:       response,
+			TicketId:     t.ID,
+			Hash:         t.Hash,
+			Supply:       summary.Supply.String(),
+			HoldersCount: summary.HoldersCount,
+		})
+	}
+}
+
+// GetTicketHolders godoc
+// @Summary Get ticket holders
+// @Description Get accounts with positive balance of ticket sorted by balance
+// @Tags tickets
+// @ID get-ticket-holders
+// @Param network path string true "network"
+// @Param hash path string true "Ticket hash" minlength(64) maxlength(64)
+// @Param size query integer false "Holders count" mininum(1) maximum(10)
+// @Param offset query integer false "Offset" mininum(1)
+// @Accept json
+// @Produce json
+// @Success 200 {array} TicketHolder
+// @Failure 400 {object} Error
+// @Failure 404 {object} Error
+// @Failure 500 {object} Error
+// @Router /v1/ticket/{network}/{hash}/holders [get]
+func GetTicketHolders() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.MustGet("context").(*config.Context)
+
+		var req getTicketRequest
+		if err := c.ShouldBindUri(&req); handleError(c, ctx.Storage, err, http.StatusBadRequest) {
+			return
+		}
+		var args pageableRequest
+		if err := c.ShouldBindQuery(&args); handleError(c, ctx.Storage, err, http.StatusBadRequest) {
+			return
+		}
+
+		t, err := ctx.Tickets.Get(c.Request.Context(), req.Hash)
+		if handleError(c, ctx.Storage, err, 0) {
+			return
+		}
+		balances, err := ctx.Tickets.Holders(c.Request.Context(), t.ID, args.Size, args.Offset)
+		if handleError(c, ctx.Storage, err, 0) {
+			return
+		}
+
+		response := make([]TicketHolder, len(balances))
+		for i := range balances {
+			response[i] = TicketHolder{
+				Address: balances[i].Account.Address,
+				Amount:  balances[i].Amount.String(),
+			}
+		}
+		c.SecureJSON(http.StatusOK, response)
+	}
+}
+
+// GetTicketSupply godoc
+// @Summary Get ticket supply history
+// @Description Get total supply of ticket after each level where it was changed
+// @Tags tickets
+// @ID get-ticket-supply
+// @Param network path string true "network"
+// @Param hash path string true "Ticket hash" minlength(64) maxlength(64)
+// @Param from_level query integer false "Lower bound of level range" mininum(0)
+// @Param to_level query integer false "Upper bound of level range" mininum(0)
+// @Param size query integer false "Points count" mininum(1) maximum(10)
+// @Param offset query integer false "Offset" mininum(1)
+// @Accept json
+// @Produce json
+// @Success 200 {array} TicketSupplyPoint
+// @Failure 400 {object} Error
+// @Failure 404 {object} Error
+// @Failure 500 {object} Error
+// @Router /v1/ticket/{network}/{hash}/supply [get]
+func GetTicketSupply() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.MustGet("context").(*config.Context)
+
+		var req getTicketRequest
+		if err := c.ShouldBindUri(&req); handleError(c, ctx.Storage, err, http.StatusBadRequest) {
+			return
+		}
+		var args ticketSupplyRequest
+		if err := c.ShouldBindQuery(&args); handleError(c, ctx.Storage, err, http.StatusBadRequest) {
+			return
+		}
+
+		t, err := ctx.Tickets.Get(c.Request.Context(), req.Hash)
+		if handleError(c, ctx.Storage, err, 0) {
+			return
+		}
+		points, err := ctx.Tickets.SupplyHistory(c.Request.Context(), t.ID, ticket.SupplyRequest{
+			FromLevel: args.FromLevel,
+			ToLevel:   args.ToLevel,
+			Limit:     args.Size,
+			Offset:    args.Offset,
+		})
+		if handleError(c, ctx.Storage, err, 0) {
+			return
+		}
+
+		response := make([]TicketSupplyPoint, len(points))
+		for i := range points {
+			response[i] = TicketSupplyPoint{
+				Level:     points[i].Level,
+				Timestamp: points[i].Timestamp.UTC(),
+				Delta:     points[i].Delta.String(),
+				Supply:    points[i].Supply.String(),
+			}
+		}
+		c.SecureJSON(http.StatusOK, response)
+	}
+}
+
+// GetTicketUpdates godoc
+// @Summary Get ticket updates
+// @Description Get updates of ticket balances
+// @Tags tickets
+// @ID get-ticket-updates
+// @Param network path string true "network"
+// @Param hash path string true "Ticket hash" minlength(64) maxlength(64)
+// @Param account query string false "Address" minlength(36) maxlength(36)
+// @Param size query integer false "Updates count" mininum(1) maximum(10)
+// @Param offset query integer false "Offset" mininum(1)
+// @Accept json
+// @Produce json
+// @Success 200 {array} TicketUpdate
+// @Failure 400 {object} Error
+// @Failure 404 {object} Error
+// @Failure 500 {object} Error
+// @Router /v1/ticket/{network}/{hash}/updates [get]
+func GetTicketUpdates() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.MustGet("context").(*config.Context)
+
+		var req getTicketRequest
+		if err := c.ShouldBindUri(&req); handleError(c, ctx.Storage, err, http.StatusBadRequest) {
+			return
+		}
+		var args ticketUpdatesRequest
+		if err := c.ShouldBindQuery(&args); handleError(c, ctx.Storage, err, http.StatusBadRequest) {
+			return
+		}
+
+		t, err := ctx.Tickets.Get(c.Request.Context(), req.Hash)
+		if handleError(c, ctx.Storage, err, 0) {
+			return
+		}
+		ticketId := uint64(t.ID)
+		updates, err := ctx.Tickets.Updates(c.Request.Context(), ticket.UpdatesRequest{
+			Account:  args.Account,
+			TicketId: &ticketId,
+			Limit:    args.Size,
+			Offset:   args.Offset,
+		})
+		if handleError(c, ctx.Storage, err, 0) {
+			return
+		}
+		response, err := prepareTicketUpdates(c.Request.Context(), ctx, updates, nil)
+		if handleError(c, ctx.Storage, err, 0) {
+			return
+		}
+		c.SecureJSON(http.StatusOK, response)
+	}
+}
+
+// GetTicketFlow godoc
+// @Summary Get ticket flow graph
+// @Description Get graph of ticket movements between accounts, contracts and rollups over level range. Movements of each operation are matched from accounts which balance was decreased to accounts which balance was increased. Unmatched increase is `mint`, unmatched decrease is `burn`.
+// @Tags tickets
+// @ID get-ticket-flow
+// @Param network path string true "network"
+// @Param hash path string true "Ticket hash" minlength(64) maxlength(64)
+// @Param from_level query integer true "Lower bound of level range" mininum(1)
+// @Param to_level query integer true "Upper bound of level range" mininum(1)
+// @Accept json
+// @Produce json
+// @Success 200 {object} TicketFlow
+// @Failure 400 {object} Error
+// @Failure 404 {object} Error
+// @Failure 500 {object} Error
+// @Router /v1/ticket/{network}/{hash}/flow [get]
+func GetTicketFlow() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.MustGet("context").(*config.Context)
+
+		var req getTicketRequest
+		if err := c.ShouldBindUri(&req); handleError(c, ctx.Storage, err, http.StatusBadRequest) {
+			return
+		}
+		var args ticketFlowRequest
+		if err := c.ShouldBindQuery(&args); handleError(c, ctx.Storage, err, http.StatusBadRequest) {
+			return
+		}
+
+		t, err := ctx.Tickets.Get(c.Request.Context(), req.Hash)
+		if handleError(c, ctx.Storage, err, 0) {
+			return
+		}
+
+		ticketId := uint64(t.ID)
+		updates := make([]ticket.TicketUpdate, 0)
+		err = ctx.Tickets.ExportUpdates(c.Request.Context(), ticket.UpdatesRequest{
+			TicketId:  &ticketId,
+			FromLevel: args.FromLevel,
+			ToLevel:   args.ToLevel,
+		}, func(batch []ticket.TicketUpdate) error {
+			if len(updates)+len(batch) > maxTicketFlowUpdates {
+				return errTooManyTicketUpdates
+			}
+			updates = append(updates, batch...)
+			return nil
+		})
+		if err != nil {
+			if errors.Is(err, errTooManyTicketUpdates) {
+				handleError(c, ctx.Storage, err, http.StatusBadRequest)
+				return
+			}
+			handleError(c, ctx.Storage, err, 0)
+			return
+		}
+
+		response := TicketFlow{
+			FromLevel: args.FromLevel,
+			ToLevel:   args.ToLevel,
+		}
+		response.Ticket, err = NewTicket(t)
+		if handleError(c, ctx.Storage, err, 0) {
+			return
+		}
+		response.Nodes, response.Edges = buildTicketFlow(updates)
+		c.SecureJSON(http.StatusOK, response)
+	}
+}
+
+type ticketFlowAmount struct {
+	address string
+	amount  decimal.Decimal
+}
+
+type ticketFlowKey struct {
+	from, to, kind string
+}
+
+type ticketFlowBuilder struct {
+	nodes         []TicketFlowNode
+	nodeIdx       map[string]int
+	sent          []decimal.Decimal
+	received      []decimal.Decimal
+	edges         []TicketFlowEdge
+	edgeIdx       map[ticketFlowKey]int
+	amounts       []decimal.Decimal
+	lastOperation []int64
+}
+
+// buildTicketFlow - aggregates ticket updates to graph. Updates are grouped by operation, inside operation decreases of balances are matched to increases in order of updates.
+func buildTicketFlow(updates []ticket.TicketUpdate) ([]TicketFlowNode, []TicketFlowEdge) {
+	b := ticketFlowBuilder{
+		nodes:   make([]TicketFlowNode, 0),
+		nodeIdx: make(map[string]int),
+		edges:   make([]TicketFlowEdge, 0),
+		edgeIdx: make(map[ticketFlowKey]int),
+	}
+
+	for start := 0; start < len(updates); {
+		end := start + 1
+		for end < len(updates) && updates[end].OperationId == updates[start].OperationId {
+			end++
+		}
+		b.addOperation(updates[start:end])
+		start = end
+	}
+
+	for i := range b.nodes {
+		b.nodes[i].Sent = b.sent[i].String()
+		b.nodes[i].Received = b.received[i].String()
+	}
+	for i := range b.edges {
+		b.edges[i].Amount = b.amounts[i].String()
+	}
+	return b.nodes, b.edges
+}
+
+func (b *ticketFlowBuilder) addOperation(updates []ticket.TicketUpdate) {
+	senders := make([]ticketFlowAmount, 0)
+	receivers := make([]ticketFlowAmount, 0)
+	for i := range updates {
+		b.addNode(updates[i].Account)
+		switch updates[i].Amount.Sign() {
+		case -1:
+			senders = append(senders, ticketFlowAmount{updates[i].Account.Address, updates[i].Amount.Neg()})
+		case 1:
+			receivers = append(receivers, ticketFlowAmount{updates[i].Account.Address, updates[i].Amount})
+		}
+	}
+
+	operationId := updates[0].OperationId
+	var s int
+	for r := range receivers {
+		for s < len(senders) && receivers[r].amount.IsPositive() {
+			amount := decimal.Min(senders[s].amount, receivers[r].amount)
+			b.addEdge(senders[s].address, receivers[r].address, ticketFlowTransfer, amount, operationId)
+			senders[s].amount = senders[s].amount.Sub(amount)
+			receivers[r].amount = receivers[r].amount.Sub(amount)
+			if !senders[s].amount.IsPositive() {
+				s++
+			}
+		}
+		if receivers[r].amount.IsPositive() {
+			b.addEdge("", receivers[r].address, ticketFlowMint, receivers[r].amount, operationId)
+		}
+	}
+	for ; s < len(senders); s++ {
+		if senders[s].amount.IsPositive() {
+			b.addEdge(senders[s].address, "", ticketFlowBurn, senders[s].amount, operationId)
+		}
+	}
+}
+
+func (b *ticketFlowBuilder) addNode(acc account.Account) {
+	if _, ok := b.nodeIdx[acc.Address]; ok {
+		return
+	}
+	b.nodeIdx[acc.Address] = len(b.nodes)
+	typ := acc.Type
+	if typ == types.AccountTypeUnknown {
+		typ = types.NewAccountType(acc.Address)
+	}
+	b.nodes = append(b.nodes, TicketFlowNode{
+		Address: acc.Address,
+		Type:    typ.String(),
+	})
+	b.sent = append(b.sent, decimal.Zero)
+	b.received = append(b.received, decimal.Zero)
+}
+
+func (b *ticketFlowBuilder) addEdge(from, to, kind string, amount decimal.Decimal, operationId int64) {
+	key := ticketFlowKey{from, to, kind}
+	idx, ok := b.edgeIdx[key]
+	if !ok {
+		idx = len(b.edges)
+		b.edgeIdx[key] = idx
+		b.edges = append(b.edges, TicketFlowEdge{
+			From: from,
+			To:   to,
+			Kind: kind,
+		})
+		b.amounts = append(b.amounts, decimal.Zero)
+		b.lastOperation = append(b.lastOperation, 0)
+	}
+	b.amounts[idx] = b.amounts[idx].Add(amount)
+	if b.lastOperation[idx] != operationId {
+		b.edges[idx].OperationsCount++
+		b.lastOperation[idx] = operationId
+	}
+
+	if from != "" {
+		i := b.nodeIdx[from]
+		b.sent[i] = b.sent[i].Add(amount)
+	}
+	if to != "" {
+		i := b.nodeIdx[to]
+		b.received[i] = b.received[i].Add(amount)
+	}
+}
@@ -0,0 +1,114 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/baking-bad/bcdhub/internal/config"
+	"github.com/baking-bad/bcdhub/internal/models/account"
+	"github.com/baking-bad/bcdhub/internal/models/mock"
+	mock_ticket "github.com/baking-bad/bcdhub/internal/models/mock/ticket"
+	"github.com/baking-bad/bcdhub/internal/models/ticket"
+	"github.com/baking-bad/bcdhub/internal/models/types"
+	"github.com/gin-gonic/gin"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestBuildTicketFlow(t *testing.T) {
+	ticketer := account.Account{Address: "KT1ThEdxfUcWUwqsdergy3QnbCWGHSUHeHJq", Type: types.AccountTypeContract}
+	alice := account.Account{Address: "tz1gjaF81ZRRvdzjobyfVNsAeSC6PScjfQwN", Type: types.AccountTypeTz}
+	rollup := account.Account{Address: "sr1LhGA2zC9VcYALSifpRBCgDiQfDSQ6bb4x", Type: types.AccountTypeSmartRollup}
+
+	update := func(operationId int64, acc account.Account, amount int64) ticket.TicketUpdate {
+		return ticket.TicketUpdate{
+			OperationId: operationId,
+			Account:     acc,
+			Amount:      decimal.NewFromInt(amount),
+		}
+	}
+
+	updates := []ticket.TicketUpdate{
+		// mint to ticketer
+		update(1, ticketer, 100),
+		// ticketer sends 60 to alice and 40 to rollup
+		update(2, ticketer, -100),
+		update(2, alice, 60),
+		update(2, rollup, 40),
+		// alice sends 10 to rollup
+		update(3, alice, -10),
+		update(3, rollup, 10),
+		// rollup withdraws 5 to alice, 15 are burned
+		update(4, rollup, -20),
+		update(4, alice, 5),
+	}
+
+	nodes, edges := buildTicketFlow(updates)
+	require.Equal(t, []TicketFlowNode{
+		{Address: ticketer.Address, Type: "contract", Sent: "100", Received: "100"},
+		{Address: alice.Address, Type: "account", Sent: "10", Received: "65"},
+		{Address: rollup.Address, Type: "smart_rollup", Sent: "20", Received: "50"},
+	}, nodes)
+	require.Equal(t, []TicketFlowEdge{
+		{To: ticketer.Address, Kind: ticketFlowMint, Amount: "100", OperationsCount: 1},
+		{From: ticketer.Address, To: alice.Address, Kind: ticketFlowTransfer, Amount: "60", OperationsCount: 1},
+		{From: ticketer.Address, To: rollup.Address, Kind: ticketFlowTransfer, Amount: "40", OperationsCount: 1},
+		{From: alice.Address, To: rollup.Address, Kind: ticketFlowTransfer, Amount: "10", OperationsCount: 1},
+		{From: rollup.Address, To: alice.Address, Kind: ticketFlowTransfer, Amount: "5", OperationsCount: 1},
+		{From: rollup.Address, Kind: ticketFlowBurn, Amount: "15", OperationsCount: 1},
+	}, edges)
+}
+
+func TestGetTicketFlow_errors(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	const hash = "a5e7ac27f8ab3a7d2ab7b5e5c9b6a2e5c2cd2a8c6e7f8b3e2cd1b0f0a9e8d7c6"
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	storage := mock.NewMockGeneralRepository(ctrl)
+	storage.EXPECT().IsRecordNotFound(gomock.Any()).Return(false).AnyTimes()
+
+	tests := []struct {
+		name     string
+		export   func(ctx context.Context, req ticket.UpdatesRequest, handler func([]ticket.TicketUpdate) error) error
+		wantCode int
+	}{
+		{
+			name: "too many updates",
+			export: func(ctx context.Context, req ticket.UpdatesRequest, handler func([]ticket.TicketUpdate) error) error {
+				return handler(make([]ticket.TicketUpdate, maxTicketFlowUpdates+1))
+			},
+			wantCode: http.StatusBadRequest,
+		}, {
+			name: "database failure",
+			export: func(ctx context.Context, req ticket.UpdatesRequest, handler func([]ticket.TicketUpdate) error) error {
+				return errors.New("connection refused")
+			},
+			wantCode: http.StatusInternalServerError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tickets := mock_ticket.NewMockRepository(ctrl)
+			tickets.EXPECT().Get(gomock.Any(), hash).Return(ticket.Ticket{ID: 1}, nil)
+			tickets.EXPECT().ExportUpdates(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(tt.export)
+
+			router := gin.New()
+			router.Use(func(c *gin.Context) {
+				c.Set("context", &config.Context{
+					Storage: storage,
+					Tickets: tickets,
+				})
+			})
+			router.GET("/v1/ticket/:network/:hash/flow", GetTicketFlow())
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/v1/ticket/mainnet/"+hash+"/flow?from_level=1&to_level=10", nil))
+			require.Equal(t, tt.wantCode, w.Code)
+		})
+	}
+}
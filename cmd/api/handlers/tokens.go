@@ -0,0 +1,238 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/baking-bad/bcdhub/internal/bcd/encoding"
+	"github.com/baking-bad/bcdhub/internal/config"
+	"github.com/baking-bad/bcdhub/internal/models/token"
+	"github.com/gin-gonic/gin"
+)
+
+// GetContractTokenHolders godoc
+// @Summary Get token holders of FA1.2 or FA2 contract
+// @Description Get token holders of FA1.2 or FA2 contract sorted by balance
+// @Tags contract
+// @ID get-contract-token-holders
+// @Param network  path  string  true  "network"
+// @Param address  path  string  true  "KT address"    minlength(36) maxlength(36)
+// @Param token_id query string  false "Token id"
+// @Param size     query integer false "Holders count" mininum(1) maximum(10)
+// @Param offset   query integer false "Offset"        mininum(1)
+// @Accept json
+// @Produce json
+// @Success 200 {array} TokenBalance
+// @Failure 400 {object} Error
+// @Failure 404 {object} Error
+// @Failure 500 {object} Error
+// @Router /v1/contract/{network}/{address}/token_holders [get]
+func GetContractTokenHolders() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.MustGet("context").(*config.Context)
+
+		var req getContractRequest
+		if err := c.ShouldBindUri(&req); handleError(c, ctx.Storage, err, http.StatusBadRequest) {
+			return
+		}
+
+		var args tokenPageableRequest
+		if err := c.ShouldBindQuery(&args); handleError(c, ctx.Storage, err, http.StatusBadRequest) {
+			return
+		}
+
+		acc, err := ctx.Accounts.Get(c.Request.Context(), req.Address)
+		if handleError(c, ctx.Storage, err, 0) {
+			return
+		}
+
+		balances, err := ctx.Tokens.Holders(c.Request.Context(), acc.ID, token.HoldersRequest{
+			TokenId: args.tokenID(),
+			Limit:   args.Size,
+			Offset:  args.Offset,
+		})
+		if handleError(c, ctx.Storage, err, 0) {
+			return
+		}
+
+		response := make([]TokenBalance, len(balances))
+		for i := range balances {
+			balances[i].Contract.Address = req.Address
+			response[i] = NewTokenBalance(balances[i])
+		}
+		c.SecureJSON(http.StatusOK, response)
+	}
+}
+
+// GetContractTokenTransfers godoc
+// @Summary Get token transfers of FA1.2 or FA2 contract
+// @Description Get token transfers of FA1.2 or FA2 contract. Empty `from` means mint, empty `to` means burn.
+// @Tags contract
+// @ID get-contract-token-transfers
+// @Param network  path  string  true  "network"
+// @Param address  path  string  true  "KT address"      minlength(36) maxlength(36)
+// @Param account  query string  false "Address"         minlength(36) maxlength(36)
+// @Param token_id query string  false "Token id"
+// @Param size     query integer false "Transfers count" mininum(1) maximum(10)
+// @Param offset   query integer false "Offset"          mininum(1)
+// @Accept json
+// @Produce json
+// @Success 200 {array} TokenTransfer
+// @Failure 400 {object} Error
+// @Failure 404 {object} Error
+// @Failure 500 {object} Error
+// @Router /v1/contract/{network}/{address}/token_transfers [get]
+func GetContractTokenTransfers() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.MustGet("context").(*config.Context)
+
+		var req getContractRequest
+		if err := c.ShouldBindUri(&req); handleError(c, ctx.Storage, err, http.StatusBadRequest) {
+			return
+		}
+
+		var args tokenTransfersRequest
+		if err := c.ShouldBindQuery(&args); handleError(c, ctx.Storage, err, http.StatusBadRequest) {
+			return
+		}
+
+		transfers, err := ctx.Tokens.Transfers(c.Request.Context(), token.TransfersRequest{
+			Contract: req.Address,
+			Account:  args.Account,
+			TokenId:  args.tokenID(),
+			Limit:    args.Size,
+			Offset:   args.Offset,
+		})
+		if handleError(c, ctx.Storage, err, 0) {
+			return
+		}
+		response, err := prepareTokenTransfers(c.Request.Context(), ctx, transfers)
+		if handleError(c, ctx.Storage, err, 0) {
+			return
+		}
+		c.SecureJSON(http.StatusOK, response)
+	}
+}
+
+// GetTokenBalancesForAccount godoc
+// @Summary Get FA1.2 and FA2 token balances of account
+// @Description Get FA1.2 and FA2 token balances of account
+// @Tags account
+// @ID get-account-token-balances
+// @Param network    path  string  true  "network"
+// @Param address    path  string  true  "Address"              minlength(36) maxlength(36)
+// @Param skip_empty query boolean false "Skip zero balances"
+// @Param size       query integer false "Balances count"       mininum(1) maximum(10)
+// @Param offset     query integer false "Offset"               mininum(1)
+// @Accept json
+// @Produce json
+// @Success 200 {array} TokenBalance
+// @Failure 400 {object} Error
+// @Failure 404 {object} Error
+// @Failure 500 {object} Error
+// @Router /v1/account/{network}/{address}/token_balances [get]
+func GetTokenBalancesForAccount() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.MustGet("context").(*config.Context)
+
+		var req getAccountRequest
+		if err := c.ShouldBindUri(&req); handleError(c, ctx.Storage, err, http.StatusBadRequest) {
+			return
+		}
+
+		var args tokenBalancesRequest
+		if err := c.ShouldBindQuery(&args); handleError(c, ctx.Storage, err, http.StatusBadRequest) {
+			return
+		}
+
+		acc, err := ctx.Accounts.Get(c.Request.Context(), req.Address)
+		if handleError(c, ctx.Storage, err, 0) {
+			return
+		}
+
+		balances, err := ctx.Tokens.BalancesForAccount(c.Request.Context(), acc.ID, token.BalanceRequest{
+			Limit:               args.Size,
+			Offset:              args.Offset,
+			WithoutZeroBalances: args.WithoutZeroBalances,
+		})
+		if handleError(c, ctx.Storage, err, 0) {
+			return
+		}
+
+		response := make([]TokenBalance, len(balances))
+		for i := range balances {
+			balances[i].Account.Address = req.Address
+			response[i] = NewTokenBalance(balances[i])
+		}
+		c.SecureJSON(http.StatusOK, response)
+	}
+}
+
+// GetTokenTransfersForAccount godoc
+// @Summary Get FA1.2 and FA2 token transfers of account
+// @Description Get FA1.2 and FA2 token transfers where account is sender or receiver
+// @Tags account
+// @ID get-account-token-transfers
+// @Param network  path  string  true  "network"
+// @Param address  path  string  true  "Address"         minlength(36) maxlength(36)
+// @Param token_id query string  false "Token id"
+// @Param size     query integer false "Transfers count" mininum(1) maximum(10)
+// @Param offset   query integer false "Offset"          mininum(1)
+// @Accept json
+// @Produce json
+// @Success 200 {array} TokenTransfer
+// @Failure 400 {object} Error
+// @Failure 404 {object} Error
+// @Failure 500 {object} Error
+// @Router /v1/account/{network}/{address}/token_transfers [get]
+func GetTokenTransfersForAccount() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.MustGet("context").(*config.Context)
+
+		var req getAccountRequest
+		if err := c.ShouldBindUri(&req); handleError(c, ctx.Storage, err, http.StatusBadRequest) {
+			return
+		}
+
+		var args tokenPageableRequest
+		if err := c.ShouldBindQuery(&args); handleError(c, ctx.Storage, err, http.StatusBadRequest) {
+			return
+		}
+
+		transfers, err := ctx.Tokens.Transfers(c.Request.Context(), token.TransfersRequest{
+			Account: req.Address,
+			TokenId: args.tokenID(),
+			Limit:   args.Size,
+			Offset:  args.Offset,
+		})
+		if handleError(c, ctx.Storage, err, 0) {
+			return
+		}
+		response, err := prepareTokenTransfers(c.Request.Context(), ctx, transfers)
+		if handleError(c, ctx.Storage, err, 0) {
+			return
+		}
+		c.SecureJSON(http.StatusOK, response)
+	}
+}
+
+func prepareTokenTransfers(c context.Context, ctx *config.Context, transfers []token.Transfer) ([]TokenTransfer, error) {
+	hashes := make(map[int64]string)
+	response := make([]TokenTransfer, len(transfers))
+	for i := range transfers {
+		transfer := NewTokenTransfer(transfers[i])
+
+		hash, ok := hashes[transfers[i].OperationId]
+		if !ok {
+			operation, err := ctx.Operations.GetByID(c, transfers[i].OperationId)
+			if err != nil {
+				return nil, err
+			}
+			hash = encoding.MustEncodeOperationHash(operation.Hash)
+			hashes[transfers[i].OperationId] = hash
+		}
+		transfer.OperationHash = hash
+		response[i] = transfer
+	}
+	return response, nil
+}
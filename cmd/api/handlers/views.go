@@ -13,6 +13,7 @@ import (
 	"github.com/baking-bad/bcdhub/internal/config"
 	"github.com/baking-bad/bcdhub/internal/models/block"
 	"github.com/baking-bad/bcdhub/internal/models/contract"
+	"github.com/baking-bad/bcdhub/internal/noderpc"
 	"github.com/baking-bad/bcdhub/internal/views"
 	"github.com/gin-gonic/gin"
 	"github.com/pkg/errors"
@@ -65,6 +66,14 @@ func GetViewsSchema() gin.HandlerFunc {
 			views = append(views, onChain...)
 		}
 
+		if args.Kind == EmptyView || args.Kind == OffchainView {
+			offChain, err := getOffChainViewsSchema(c.Request.Context(), ctx, req.Address)
+			if handleError(c, ctx.Storage, err, 0) {
+				return
+			}
+			views = append(views, offChain...)
+		}
+
 		c.SecureJSON(http.StatusOK, views)
 	}
 }
@@ -130,6 +139,29 @@ func getOffChainViewSchema(view contract.View) *ViewSchema {
 	return nil
 }
 
+func getOffChainViewsSchema(c context.Context, ctx *config.Context, address string) ([]ViewSchema, error) {
+	metadata, err := ctx.Metadata.Get(c, address)
+	if err != nil {
+		if ctx.Storage.IsRecordNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	views, err := metadata.Views()
+	if err != nil {
+		return nil, err
+	}
+
+	schemas := make([]ViewSchema, 0, len(views))
+	for i := range views {
+		if schema := getOffChainViewSchema(views[i]); schema != nil {
+			schemas = append(schemas, *schema)
+		}
+	}
+	return schemas, nil
+}
+
 func getOnChainViewsSchema(ctx context.Context, contracts contract.Repository, blocks block.Repository, address string) ([]ViewSchema, error) {
 	block, err := blocks.Last(ctx)
 	if err != nil {
@@ -187,7 +219,7 @@ func getOnChainViewsSchema(ctx context.Context, contracts contract.Repository, b
 
 // ExecuteView godoc
 // @Summary Execute view of contracts metadata
-// @Description Execute view of contracts metadata
+// @Description Execute view of contracts metadata. If `offline` is set or node isn't configured, view is executed by built-in interpreter against indexed storage and big maps.
 // @Tags contract
 // @ID contract-execute-view
 // @Param network path string true "Network"
@@ -225,7 +257,12 @@ func ExecuteView() gin.HandlerFunc {
 		timeoutContext, cancel := context.WithTimeout(c, 20*time.Second)
 		defer cancel()
 
-		response, err := view.Execute(timeoutContext, ctx.RPC, views.Args{
+		var rpc noderpc.INode = ctx.RPC
+		if isOffline(ctx, execView.Offline) {
+			rpc = newInterpreterNode(ctx, state, req.Address)
+		}
+
+		response, err := view.Execute(timeoutContext, rpc, views.Args{
 			Contract:                 req.Address,
 			Source:                   execView.Source,
 			Initiator:                execView.Sender,
@@ -335,7 +372,12 @@ func getViewForExecute(ctx context.Context, networkContext *config.Context, addr
 			return nil, nil, err
 		}
 
-		storageValue, err := getDeffattedStorage(ctx, networkContext, address, 0)
+		var storageValue []byte
+		if isOffline(networkContext, req.Offline) {
+			storageValue, err = chainState{ctx: networkContext, symLink: symLink}.storage(ctx, address)
+		} else {
+			storageValue, err = getDeffattedStorage(ctx, networkContext, address, 0)
+		}
 		if err != nil {
 			return nil, nil, err
 		}
@@ -12,6 +12,7 @@ import (
 	"github.com/baking-bad/bcdhub/internal/config"
 	"github.com/baking-bad/bcdhub/internal/helpers"
 	"github.com/baking-bad/bcdhub/internal/logger"
+	"github.com/baking-bad/bcdhub/internal/metrics"
 	"github.com/baking-bad/bcdhub/internal/periodic"
 	"github.com/baking-bad/bcdhub/internal/profiler"
 	sentrygin "github.com/getsentry/sentry-go/gin"
@@ -32,6 +33,9 @@ type app struct {
 	Contexts config.Contexts
 	Config   config.Config
 
+	hub       *handlers.OperationsHub
+	cancelHub context.CancelFunc
+
 	cancel   context.CancelFunc
 	worker   *periodic.GeneralWorker
 	profiler *pyroscope.Profiler
@@ -89,6 +93,11 @@ func newApp(ctx context.Context) *app {
 		config.WithLoadErrorDescriptions(),
 		config.WithConfigCopy(cfg))
 
+	hubCtx, cancelHub := context.WithCancel(ctx)
+	app.cancelHub = cancelHub
+	app.hub = handlers.NewOperationsHub(app.Contexts)
+	app.hub.Start(hubCtx)
+
 	app.makeRouter()
 
 	return app
@@ -125,6 +134,24 @@ func (api *app) makeRouter() {
 	}
 
 	r.Use(ginLogger.SetLogger())
+
+	if api.Config.Metrics != nil {
+		r.Use(handlers.MetricsMiddleware())
+		r.GET("metrics", gin.WrapH(metrics.Handler()))
+	}
+
+	// stream connection is long-lived, so the route is registered before timeout middleware
+	r.GET("v1/stream/:network", handlers.NetworkMiddleware(api.Contexts), handlers.StreamOperations(api.hub))
+
+	// exports of big collections take more time than timeout, so they are registered before timeout middleware too
+	export := r.Group("v1/export/:network")
+	export.Use(handlers.NetworkMiddleware(api.Contexts))
+	{
+		export.GET("operations/:address", handlers.ExportOperations())
+		export.GET("bigmap/:ptr/keys", handlers.ExportBigMapKeys())
+		export.GET("ticket_updates/:address", handlers.ExportTicketUpdates())
+	}
+
 	r.Use(timeout.New(
 		timeout.WithTimeout(30*time.Second),
 		timeout.WithHandler(func(c *gin.Context) {
@@ -147,6 +174,11 @@ func (api *app) makeRouter() {
 		v1.POST("off_chain_view", handlers.MainnetMiddleware(api.Contexts), handlers.OffChainView())
 		v1.POST("michelson", handlers.ContextsMiddleware(api.Contexts), handlers.CodeFromMichelson())
 		v1.POST("fork", handlers.ForkContract(api.Contexts))
+		v1.POST("forge", handlers.ForgeOperation(api.Contexts))
+		v1.POST("unforge", handlers.UnforgeOperation(api.Contexts))
+		v1.POST("run_operations/:network", handlers.NetworkMiddleware(api.Contexts), handlers.RunOperations())
+		v1.GET("search", handlers.ContextsMiddleware(api.Contexts), handlers.Search())
+		v1.GET("diff", handlers.ContextsMiddleware(api.Contexts), handlers.GetCodeDiff())
 
 		operation := v1.Group("operation/:network/:id")
 		operation.Use(handlers.NetworkMiddleware(api.Contexts))
@@ -165,6 +197,8 @@ func (api *app) makeRouter() {
 			{
 				networkStats.GET("recently_called_contracts", cache.CachePage(store, time.Second*10, handlers.RecentlyCalledContracts()))
 				networkStats.GET("contracts_count", cache.CachePage(store, time.Second*10, handlers.ContractsCount()))
+				networkStats.GET("series", cache.CachePage(store, time.Second*30, handlers.GetStatsSeries()))
+				networkStats.GET("rpc", cache.CachePage(store, time.Second*5, handlers.GetRPCStatus()))
 			}
 		}
 
@@ -197,6 +231,8 @@ func (api *app) makeRouter() {
 			contract.GET("global_constants", handlers.GetContractGlobalConstants())
 			contract.GET("ticket_updates", handlers.GetContractTicketUpdates())
 			contract.GET("tickets", handlers.GetContractTickets())
+			contract.GET("token_holders", handlers.GetContractTokenHolders())
+			contract.GET("token_transfers", handlers.GetContractTokenTransfers())
 			contract.GET("events", handlers.ListEvents())
 
 			storage := contract.Group("storage")
@@ -215,6 +251,7 @@ func (api *app) makeRouter() {
 				entrypoints.POST("data", handlers.GetEntrypointData())
 				entrypoints.POST("trace", handlers.RunCode())
 				entrypoints.POST("run_operation", handlers.RunOperation())
+				entrypoints.POST("estimate", handlers.Estimate())
 			}
 			views := contract.Group("views")
 			{
@@ -229,7 +266,10 @@ func (api *app) makeRouter() {
 			acc := account.Group(":address")
 			{
 				acc.GET("", handlers.GetInfo())
+				acc.GET("operations", handlers.GetAccountOperations())
 				acc.GET("ticket_balances", handlers.GetTicketBalancesForAccount())
+				acc.GET("token_balances", handlers.GetTokenBalancesForAccount())
+				acc.GET("token_transfers", handlers.GetTokenTransfersForAccount())
 			}
 		}
 
@@ -244,11 +284,32 @@ func (api *app) makeRouter() {
 			}
 		}
 
+		events := v1.Group("events/:network")
+		events.Use(handlers.NetworkMiddleware(api.Contexts))
+		{
+			events.GET("", handlers.SearchEvents())
+			events.GET("types", handlers.ListEventTypes())
+		}
+
+		tickets := v1.Group("ticket/:network/:hash")
+		tickets.Use(handlers.NetworkMiddleware(api.Contexts))
+		{
+			tickets.GET("", handlers.GetTicket())
+			tickets.GET("holders", handlers.GetTicketHolders())
+			tickets.GET("supply", handlers.GetTicketSupply())
+			tickets.GET("updates", handlers.GetTicketUpdates())
+			tickets.GET("flow", handlers.GetTicketFlow())
+		}
+
 		smartRollups := v1.Group("smart_rollups/:network")
 		smartRollups.Use(handlers.NetworkMiddleware(api.Contexts))
 		{
 			smartRollups.GET("", handlers.ListSmartRollups())
 			smartRollups.GET(":address", handlers.GetSmartRollup())
+			smartRollups.GET(":address/commitments", handlers.GetSmartRollupCommitments())
+			smartRollups.GET(":address/stakers", handlers.GetSmartRollupStakers())
+			smartRollups.GET(":address/games", handlers.GetSmartRollupGames())
+			smartRollups.GET(":address/outbox", handlers.GetSmartRollupOutbox())
 		}
 	}
 	api.Router = r
@@ -256,6 +317,11 @@ func (api *app) makeRouter() {
 
 // Close -
 func (api *app) Close() error {
+	api.cancelHub()
+	if err := api.hub.Close(); err != nil {
+		return err
+	}
+
 	api.cancel()
 
 	if api.profiler != nil {
@@ -2,12 +2,15 @@ package validations
 
 import (
 	"reflect"
+	"regexp"
 	"slices"
 	"strings"
 
 	"github.com/baking-bad/bcdhub/internal/bcd"
 	"github.com/baking-bad/bcdhub/internal/bcd/consts"
 	"github.com/baking-bad/bcdhub/internal/config"
+	"github.com/baking-bad/bcdhub/internal/models/search"
+	"github.com/baking-bad/bcdhub/internal/models/types"
 	"github.com/btcsuite/btcutil/base58"
 	"github.com/go-playground/validator/v10"
 )
@@ -38,6 +41,10 @@ func Register(v *validator.Validate, cfg config.APIConfig) error {
 		return err
 	}
 
+	if err := v.RegisterValidation("operation_kind", operationKindValidator()); err != nil {
+		return err
+	}
+
 	if err := v.RegisterValidation("faversion", faVersionValidator()); err != nil {
 		return err
 	}
@@ -50,6 +57,18 @@ func Register(v *validator.Validate, cfg config.APIConfig) error {
 		return err
 	}
 
+	if err := v.RegisterValidation("tag", tagValidator()); err != nil {
+		return err
+	}
+
+	if err := v.RegisterValidation("entrypoint", entrypointValidator()); err != nil {
+		return err
+	}
+
+	if err := v.RegisterValidation("search_type", searchTypeValidator()); err != nil {
+		return err
+	}
+
 	if err := v.RegisterValidation("gt_int64_ptr", greatThanInt64PtrValidator()); err != nil {
 		return err
 	}
@@ -123,6 +142,18 @@ func statusValidator() validator.Func {
 	}
 }
 
+func operationKindValidator() validator.Func {
+	return func(fl validator.FieldLevel) bool {
+		kinds := strings.Split(fl.Field().String(), ",")
+		for i := range kinds {
+			if types.NewOperationKind(kinds[i]) == 0 {
+				return false
+			}
+		}
+		return true
+	}
+}
+
 func faVersionValidator() validator.Func {
 	return func(fl validator.FieldLevel) bool {
 		version := fl.Field().String()
@@ -151,6 +182,27 @@ func searchStringValidator() validator.Func {
 	}
 }
 
+func tagValidator() validator.Func {
+	return func(fl validator.FieldLevel) bool {
+		return types.NewTags([]string{fl.Field().String()}) != 0
+	}
+}
+
+// entrypointRegexp - entrypoint name is at most 31 characters of Michelson annotation alphabet
+var entrypointRegexp = regexp.MustCompile(`^[A-Za-z0-9_.%@]{1,31}$`)
+
+func entrypointValidator() validator.Func {
+	return func(fl validator.FieldLevel) bool {
+		return entrypointRegexp.MatchString(fl.Field().String())
+	}
+}
+
+func searchTypeValidator() validator.Func {
+	return func(fl validator.FieldLevel) bool {
+		return search.IsHitType(fl.Field().String())
+	}
+}
+
 func maxSizeValidator(maxSize int64) validator.Func {
 	return func(fl validator.FieldLevel) bool {
 		if maxSize == 0 {
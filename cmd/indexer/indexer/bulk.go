@@ -0,0 +1,125 @@
+package indexer
+
+import (
+	"context"
+	"time"
+
+	"github.com/baking-bad/bcdhub/internal/config"
+	"github.com/baking-bad/bcdhub/internal/metrics"
+	"github.com/baking-bad/bcdhub/internal/models/block"
+	"github.com/baking-bad/bcdhub/internal/models/operation"
+	"github.com/baking-bad/bcdhub/internal/postgres/core"
+	"github.com/baking-bad/bcdhub/internal/postgres/store"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+)
+
+// blockBatch - blocks which are saved in one database transaction during bulk sync
+type blockBatch struct {
+	tx         *core.Batch
+	ctx        *config.Context
+	state      block.Block
+	operations []*operation.Operation
+	levels     []int64
+	count      int64
+	start      time.Time
+}
+
+// isBulk - returns true if blocks after `level` should be saved in batches
+func (bi *BlockchainIndexer) isBulk(level int64) bool {
+	return bi.bulkThreshold > 0 && bi.head.Load()-level > bi.bulkThreshold
+}
+
+// processBlock - saves block in the current batch during bulk sync or in its own transaction otherwise.
+// Batch is committed before protocol migrations and when indexer is close to head.
+func (bi *BlockchainIndexer) processBlock(ctx context.Context, block *Block) error {
+	if bi.isBulk(block.Header.Level) && !bi.isMigration(block.Header) {
+		return bi.handleBlockInBatch(ctx, block)
+	}
+	if err := bi.commitBatch(ctx); err != nil {
+		return errors.Wrap(err, "commit batch")
+	}
+	return bi.handleBlock(ctx, block)
+}
+
+func (bi *BlockchainIndexer) handleBlockInBatch(ctx context.Context, block *Block) error {
+	start := time.Now()
+
+	if bi.batch == nil {
+		tx, err := bi.StorageDB.NewBatch(ctx)
+		if err != nil {
+			return err
+		}
+		bi.batch = &blockBatch{
+			tx:    tx,
+			ctx:   bi.Context.WithDB(tx.DB()),
+			state: bi.state,
+			start: start,
+		}
+	}
+
+	s := store.NewBatchStore(bi.batch.tx, bi.batch.ctx.Stats)
+	if err := bi.parseBlock(ctx, bi.batch.ctx, block, s); err != nil {
+		bi.rollbackBatch()
+		return errors.Wrap(err, "block processing")
+	}
+	if err := s.Save(ctx); err != nil {
+		bi.rollbackBatch()
+		return errors.Wrap(err, "block processing")
+	}
+
+	bi.state = *s.Block
+	bi.batch.operations = append(bi.batch.operations, s.Operations...)
+	bi.batch.levels = append(bi.batch.levels, block.Header.Level)
+	bi.batch.count++
+
+	metrics.ObserveSince(metrics.BlockProcessingDuration.WithLabelValues(bi.Network.String()), start)
+
+	if bi.batch.count >= bi.bulkSize {
+		return bi.commitBatch(ctx)
+	}
+	return nil
+}
+
+// commitBatch - commits the current batch if it exists. Indexer state is returned to the start of the batch if commit failed.
+// Notification is published for each block of the batch, so stream subscribers receive operations of the batch too.
+func (bi *BlockchainIndexer) commitBatch(ctx context.Context) error {
+	if bi.batch == nil {
+		return nil
+	}
+	batch := bi.batch
+	bi.batch = nil
+
+	if err := batch.tx.Commit(); err != nil {
+		bi.state = batch.state
+		return err
+	}
+
+	bi.enqueueMetadata(batch.operations)
+
+	for _, level := range batch.levels {
+		bi.publishBlock(ctx, level)
+	}
+
+	bi.updateMetrics()
+
+	log.Info().
+		Str("network", bi.Network.String()).
+		Int64("blocks", batch.count).
+		Int64("processing_time_ms", time.Since(batch.start).Milliseconds()).
+		Int64("block", bi.state.Level).
+		Msg("indexed batch")
+
+	return nil
+}
+
+func (bi *BlockchainIndexer) rollbackBatch() {
+	if bi.batch == nil {
+		return
+	}
+	if err := bi.batch.tx.Rollback(); err != nil {
+		log.Err(err).Str("network", bi.Network.String()).Msg("rollback batch")
+	}
+	bi.state = bi.batch.state
+	bi.batch = nil
+}
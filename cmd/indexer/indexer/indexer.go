@@ -4,17 +4,21 @@ import (
 	"context"
 	"fmt"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/baking-bad/bcdhub/internal/bcd"
 	"github.com/baking-bad/bcdhub/internal/config"
+	"github.com/baking-bad/bcdhub/internal/events"
 	"github.com/baking-bad/bcdhub/internal/helpers"
+	"github.com/baking-bad/bcdhub/internal/metrics"
 	"github.com/baking-bad/bcdhub/internal/models/block"
 	"github.com/baking-bad/bcdhub/internal/models/protocol"
 	"github.com/baking-bad/bcdhub/internal/models/stats"
 	"github.com/baking-bad/bcdhub/internal/models/types"
 	"github.com/baking-bad/bcdhub/internal/noderpc"
 	"github.com/baking-bad/bcdhub/internal/parsers"
+	contractmetadata "github.com/baking-bad/bcdhub/internal/parsers/contract_metadata"
 	"github.com/baking-bad/bcdhub/internal/parsers/migrations"
 	"github.com/baking-bad/bcdhub/internal/parsers/operations"
 	"github.com/baking-bad/bcdhub/internal/parsers/protocols"
@@ -35,19 +39,29 @@ var errSameLevel = errors.New("Same level")
 type BlockchainIndexer struct {
 	*config.Context
 
-	receiver        *Receiver
+	receiver *Receiver
+
+	// mx - guards indexer state and the current batch which are changed by block processing and by head checks of the ticker
+	mx              sync.Mutex
 	state           block.Block
 	currentProtocol protocol.Protocol
 	blocks          map[int64]*Block
+	head            atomic.Int64
 
 	updateTicker *time.Ticker
 	Network      types.Network
 
 	refreshTimer chan struct{}
 
-	startLevel  int64
-	isPeriodic  bool
-	indicesInit sync.Once
+	startLevel    int64
+	isPeriodic    bool
+	indicesInit   sync.Once
+	bulkThreshold int64
+	bulkSize      int64
+	batch         *blockBatch
+
+	metadataParser *contractmetadata.Parser
+	metadataTasks  chan metadataTask
 
 	g workerpool.Group
 }
@@ -68,14 +82,19 @@ func NewBlockchainIndexer(ctx context.Context, cfg config.Config, network string
 	log.Info().Str("network", internalCtx.Network.String()).Msg("Creating indexer object...")
 
 	bi := &BlockchainIndexer{
-		Context:      internalCtx,
-		receiver:     NewReceiver(internalCtx.RPC, 20, indexerConfig.ReceiverThreads),
-		blocks:       make(map[int64]*Block),
-		Network:      networkType,
-		startLevel:   indexerConfig.ResolveStartLevel(),
-		isPeriodic:   indexerConfig.Periodic != nil,
-		refreshTimer: make(chan struct{}, 10),
-		g:            workerpool.NewGroup(),
+		Context:       internalCtx,
+		receiver:      NewReceiver(internalCtx.RPC, 20, indexerConfig.ReceiverThreads),
+		blocks:        make(map[int64]*Block),
+		Network:       networkType,
+		startLevel:    indexerConfig.ResolveStartLevel(),
+		isPeriodic:    indexerConfig.Periodic != nil,
+		bulkThreshold: indexerConfig.BulkThreshold,
+		bulkSize:      indexerConfig.ResolveBulkSize(),
+		refreshTimer:  make(chan struct{}, 10),
+		g:             workerpool.NewGroup(),
+
+		metadataParser: newMetadataParser(internalCtx, cfg.Indexer.Metadata),
+		metadataTasks:  make(chan metadataTask, metadataQueueSize),
 	}
 
 	if err := bi.init(ctx, bi.StorageDB); err != nil {
@@ -202,6 +221,9 @@ func (bi *BlockchainIndexer) Start(ctx context.Context) {
 	helpers.SetLocalTagSentry(localSentry, "network", bi.Network.String())
 
 	bi.g.GoCtx(ctx, bi.indexBlock)
+	if bi.metadataParser != nil {
+		bi.g.GoCtx(ctx, bi.resolveMetadata)
+	}
 
 	bi.receiver.Start(ctx)
 
@@ -277,39 +299,56 @@ func (bi *BlockchainIndexer) indexBlock(ctx context.Context) {
 	for {
 		select {
 		case <-ctx.Done():
+			bi.mx.Lock()
+			// blocks which are already parsed are saved on shutdown, so context mustn't be cancelled
+			if err := bi.commitBatch(context.WithoutCancel(ctx)); err != nil {
+				log.Err(err).Str("network", bi.Network.String()).Msg("commit batch")
+			}
+			bi.mx.Unlock()
 			return
 
 		case newBlock := <-bi.receiver.Blocks():
+			metrics.ReceiverQueueDepth.WithLabelValues(bi.Network.String()).Set(float64(bi.receiver.QueueSize()))
 			bi.blocks[newBlock.Header.Level] = newBlock
 
-			nextLevel := helpers.Max(bi.state.Level+1, bi.startLevel)
-			block, ok := bi.blocks[nextLevel]
-			for ok {
-				if bi.state.Level > 0 && block.Header.Predecessor != bi.state.Hash {
-					if err := bi.Rollback(ctx); err != nil {
-						log.Err(err).Msg("rollback")
-					}
-				} else {
-					if err := bi.handleBlock(ctx, block); err != nil {
-						log.Err(err).
-							Str("network", bi.Network.String()).
-							Int64("block", block.Header.Level).
-							Stack().
-							Msg("handleBlock")
-					}
-				}
+			bi.mx.Lock()
+			bi.processReceivedBlocks(ctx)
+			bi.mx.Unlock()
+		}
+	}
+}
 
-				delete(bi.blocks, block.Header.Level)
-				nextLevel = helpers.Max(bi.state.Level+1, bi.startLevel)
-				block, ok = bi.blocks[nextLevel]
+// processReceivedBlocks - processes received blocks which follow indexer state. It must be called under `mx` lock.
+func (bi *BlockchainIndexer) processReceivedBlocks(ctx context.Context) {
+	nextLevel := helpers.Max(bi.state.Level+1, bi.startLevel)
+	block, ok := bi.blocks[nextLevel]
+	for ok {
+		if bi.state.Level > 0 && block.Header.Predecessor != bi.state.Hash {
+			if err := bi.commitBatch(ctx); err != nil {
+				log.Err(err).Str("network", bi.Network.String()).Msg("commit batch")
+			} else if err := bi.rollback(ctx, block.Header); err != nil {
+				log.Err(err).Msg("rollback")
+			}
+		} else {
+			if err := bi.processBlock(ctx, block); err != nil {
+				log.Err(err).
+					Str("network", bi.Network.String()).
+					Int64("block", block.Header.Level).
+					Stack().
+					Msg("handleBlock")
 			}
 		}
+
+		delete(bi.blocks, block.Header.Level)
+		nextLevel = helpers.Max(bi.state.Level+1, bi.startLevel)
+		block, ok = bi.blocks[nextLevel]
 	}
 }
 
 // Index -
 func (bi *BlockchainIndexer) Index(ctx context.Context, head noderpc.Header) error {
-	for level := helpers.Max(bi.state.Level+1, bi.startLevel); level <= head.Level; level++ {
+	state := bi.getState()
+	for level := helpers.Max(state.Level+1, bi.startLevel); level <= head.Level; level++ {
 		helpers.SetTagSentry("block", fmt.Sprintf("%d", level))
 
 		select {
@@ -320,6 +359,11 @@ func (bi *BlockchainIndexer) Index(ctx context.Context, head noderpc.Header) err
 		}
 	}
 
+	// indices slow down bulk sync, so they are created after indexer catches up
+	if bi.isBulk(state.Level) {
+		return nil
+	}
+
 	bi.indicesInit.Do(func() {
 		if err := bi.createIndices(ctx); err != nil {
 			log.Err(err).Str("network", bi.Network.String()).Msg("can't create index")
@@ -340,6 +384,11 @@ func (bi *BlockchainIndexer) handleBlock(ctx context.Context, block *Block) erro
 		return errors.Wrap(err, "block processing")
 	}
 
+	bi.publishBlock(ctx, block.Header.Level)
+
+	metrics.ObserveSince(metrics.BlockProcessingDuration.WithLabelValues(bi.Network.String()), start)
+	bi.updateMetrics()
+
 	log.Info().
 		Str("network", bi.Network.String()).
 		Int64("processing_time_ms", time.Since(start).Milliseconds()).
@@ -349,30 +398,49 @@ func (bi *BlockchainIndexer) handleBlock(ctx context.Context, block *Block) erro
 	return nil
 }
 
+// publishBlock - notifies subscribers that block was saved
+func (bi *BlockchainIndexer) publishBlock(ctx context.Context, level int64) {
+	if err := events.Publish(ctx, bi.Storage, bi.Network, events.Message{
+		Type:  events.MessageTypeBlock,
+		Level: level,
+	}); err != nil {
+		log.Warn().Err(err).Str("network", bi.Network.String()).Int64("block", level).Msg("publish block notification")
+	}
+}
+
 func (bi *BlockchainIndexer) parseAndSaveBlock(ctx context.Context, block *Block) error {
 	store := store.NewStore(bi.StorageDB.DB, bi.Stats)
-	if err := bi.parseImplicitOperations(ctx, block, bi.currentProtocol, store); err != nil {
+	if err := bi.parseBlock(ctx, bi.Context, block, store); err != nil {
 		return err
 	}
 
-	if err := bi.getDataFromBlock(ctx, block, store); err != nil {
+	if err := store.Save(ctx); err != nil {
 		return err
 	}
+	bi.enqueueMetadata(store.Operations)
 
-	if err := bi.createBlock(ctx, block.Header, store); err != nil {
+	bi.state = *store.Block
+	return nil
+}
+
+func (bi *BlockchainIndexer) parseBlock(ctx context.Context, internalCtx *config.Context, block *Block, store parsers.Store) error {
+	if err := bi.parseImplicitOperations(ctx, internalCtx, block, bi.currentProtocol, store); err != nil {
 		return err
 	}
 
-	if err := store.Save(ctx); err != nil {
+	if err := bi.getDataFromBlock(ctx, internalCtx, block, store); err != nil {
 		return err
 	}
 
-	bi.state = *store.Block
-	return nil
+	return bi.createBlock(ctx, block.Header, store)
+}
+
+func (bi *BlockchainIndexer) isMigration(header noderpc.Header) bool {
+	return header.Protocol != bi.currentProtocol.Hash || header.Level <= 1
 }
 
 func (bi *BlockchainIndexer) doMigration(ctx context.Context, header noderpc.Header) error {
-	if header.Protocol == bi.currentProtocol.Hash && header.Level > 1 {
+	if !bi.isMigration(header) {
 		return nil
 	}
 
@@ -410,21 +478,46 @@ func (bi *BlockchainIndexer) migrate(ctx context.Context, head noderpc.Header) e
 	return nil
 }
 
-// Rollback -
-func (bi *BlockchainIndexer) Rollback(ctx context.Context) error {
-	log.Warn().Str("network", bi.Network.String()).Msgf("Rollback from %8d", bi.state.Level)
+// Rollback - rolls back indexed blocks which aren't in the node chain ending with `head`
+func (bi *BlockchainIndexer) Rollback(ctx context.Context, head noderpc.Header) error {
+	bi.mx.Lock()
+	defer bi.mx.Unlock()
+	return bi.rollback(ctx, head)
+}
 
-	lastLevel, err := bi.getLastRollbackBlock(ctx)
+// getState - returns the last indexed block
+func (bi *BlockchainIndexer) getState() block.Block {
+	bi.mx.Lock()
+	defer bi.mx.Unlock()
+	return bi.state
+}
+
+// rollback - implementation of `Rollback`. It must be called under `mx` lock.
+func (bi *BlockchainIndexer) rollback(ctx context.Context, head noderpc.Header) error {
+	ancestor, err := findCommonAncestor(ctx, bi.RPC, bi.Blocks, bi.state, head)
 	if err != nil {
-		return err
+		return errors.Wrap(err, "find common ancestor")
+	}
+	if ancestor >= bi.state.Level {
+		return nil
 	}
 
+	depth := bi.state.Level - ancestor
+	log.Warn().
+		Str("network", bi.Network.String()).
+		Int64("from", bi.state.Level).
+		Int64("to", ancestor).
+		Int64("depth", depth).
+		Msg("chain reorganisation is detected")
+	metrics.Rollbacks.WithLabelValues(bi.Network.String()).Inc()
+	metrics.ReorgDepth.WithLabelValues(bi.Network.String()).Observe(float64(depth))
+
 	saver, err := postgres.NewRollback(bi.StorageDB.DB)
 	if err != nil {
 		return err
 	}
 	manager := rollback.NewManager(bi.Storage, bi.Blocks, saver, bi.Stats)
-	if err := manager.Rollback(ctx, bi.Network, bi.state, lastLevel); err != nil {
+	if err := manager.Rollback(ctx, bi.Network, bi.state, ancestor); err != nil {
 		return err
 	}
 
@@ -433,49 +526,25 @@ func (bi *BlockchainIndexer) Rollback(ctx context.Context) error {
 		return err
 	}
 	bi.state = newState
+	bi.updateMetrics()
 	log.Info().Str("network", bi.Network.String()).Msgf("New indexer state: %8d", bi.state.Level)
 	log.Info().Str("network", bi.Network.String()).Msg("Rollback finished")
 	return nil
 }
 
-func (bi *BlockchainIndexer) getLastRollbackBlock(ctx context.Context) (int64, error) {
-	var lastLevel int64
-	level := bi.state.Level
-
-	for end := false; !end; level-- {
-		headAtLevel, err := bi.RPC.GetHeader(ctx, level)
-		if err != nil {
-			return 0, err
-		}
-
-		block, err := bi.Blocks.Get(ctx, level-1)
-		if err != nil {
-			return 0, err
-		}
-
-		if block.Hash == headAtLevel.Predecessor {
-			log.Warn().Str("network", bi.Network.String()).Msgf("Found equal predecessors at level: %7d", block.Level)
-			end = true
-			lastLevel = block.Level
-		}
-	}
-	return lastLevel, nil
-}
-
 func (bi *BlockchainIndexer) process(ctx context.Context) error {
 	head, err := bi.RPC.GetHead(ctx)
 	if err != nil {
 		return err
 	}
 
-	if !bi.state.Protocol.ValidateChainID(head.ChainID) {
-		return errors.Errorf("Invalid chain_id: %s (state) != %s (head)", bi.state.Protocol.ChainID, head.ChainID)
+	level, err := bi.checkHead(ctx, head)
+	if err != nil {
+		return err
 	}
 
-	log.Info().Str("network", bi.Network.String()).Int64("node", head.Level).Int64("indexer", bi.state.Level).Msg("current state")
-
 	switch {
-	case head.Level > bi.state.Level:
+	case head.Level > level:
 		if err := bi.Index(ctx, head); err != nil {
 			if errors.Is(err, errBcdQuit) {
 				return nil
@@ -485,30 +554,61 @@ func (bi *BlockchainIndexer) process(ctx context.Context) error {
 
 		log.Info().Str("network", bi.Network.String()).Msg("Synced")
 		return nil
-	case head.Level < bi.state.Level:
-		return bi.Rollback(ctx)
 	default:
 		return errSameLevel
 	}
 }
+
+// checkHead - validates node head and rolls back indexer state if it isn't in the node chain. Returns indexed level.
+// Lock is held while state is checked, so blocks aren't processed concurrently with rollback.
+func (bi *BlockchainIndexer) checkHead(ctx context.Context, head noderpc.Header) (int64, error) {
+	bi.mx.Lock()
+	defer bi.mx.Unlock()
+
+	if !bi.state.Protocol.ValidateChainID(head.ChainID) {
+		return 0, errors.Errorf("Invalid chain_id: %s (state) != %s (head)", bi.state.Protocol.ChainID, head.ChainID)
+	}
+
+	bi.head.Store(head.Level)
+	bi.updateMetrics()
+
+	log.Info().Str("network", bi.Network.String()).Int64("node", head.Level).Int64("indexer", bi.state.Level).Msg("current state")
+
+	// blocks of the current batch aren't visible yet, but reorganisation can't be so deep during bulk sync
+	if bi.state.Level > 0 && !bi.isBulk(bi.state.Level) {
+		if err := bi.rollback(ctx, head); err != nil {
+			return 0, err
+		}
+	}
+	return bi.state.Level, nil
+}
+
+// updateMetrics - reports indexed level and lag behind the last known node head
+func (bi *BlockchainIndexer) updateMetrics() {
+	network := bi.Network.String()
+	metrics.IndexerLevel.WithLabelValues(network).Set(float64(bi.state.Level))
+	metrics.IndexerHeadLag.WithLabelValues(network).Set(float64(max(bi.head.Load()-bi.state.Level, 0)))
+}
+
 func (bi *BlockchainIndexer) createBlock(ctx context.Context, head noderpc.Header, store parsers.Store) error {
 	newBlock := block.Block{
-		Hash:       head.Hash,
-		ProtocolID: bi.currentProtocol.ID,
-		Level:      head.Level,
-		Timestamp:  head.Timestamp,
+		Hash:        head.Hash,
+		Predecessor: head.Predecessor,
+		ProtocolID:  bi.currentProtocol.ID,
+		Level:       head.Level,
+		Timestamp:   head.Timestamp,
 	}
 	store.SetBlock(&newBlock)
 	return nil
 }
 
-func (bi *BlockchainIndexer) getDataFromBlock(ctx context.Context, block *Block, store parsers.Store) error {
+func (bi *BlockchainIndexer) getDataFromBlock(ctx context.Context, internalCtx *config.Context, block *Block, store parsers.Store) error {
 	if block.Header.Level <= 1 {
 		return nil
 	}
 	parserParams, err := operations.NewParseParams(
 		ctx,
-		bi.Context,
+		internalCtx,
 		operations.WithProtocol(&bi.currentProtocol),
 		operations.WithHead(block.Header),
 	)
@@ -525,17 +625,17 @@ func (bi *BlockchainIndexer) getDataFromBlock(ctx context.Context, block *Block,
 
 	return nil
 }
-func (bi *BlockchainIndexer) parseImplicitOperations(ctx context.Context, block *Block, protocol protocol.Protocol, store parsers.Store) error {
+func (bi *BlockchainIndexer) parseImplicitOperations(ctx context.Context, internalCtx *config.Context, block *Block, protocol protocol.Protocol, store parsers.Store) error {
 	if block == nil || block.Metadata == nil {
 		return nil
 	}
 
-	specific, err := protocols.Get(bi.Context, protocol.Hash)
+	specific, err := protocols.Get(internalCtx, protocol.Hash)
 	if err != nil {
 		return err
 	}
 
-	implicitParser, err := migrations.NewImplicitParser(bi.Context, bi.RPC, specific.ContractParser, protocol, bi.Contracts)
+	implicitParser, err := migrations.NewImplicitParser(internalCtx, internalCtx.RPC, specific.ContractParser, protocol, internalCtx.Contracts)
 	if err != nil {
 		return err
 	}
@@ -552,6 +652,9 @@ func (bi *BlockchainIndexer) reinit(ctx context.Context, cfg config.Config, inde
 	log.Info().Str("network", bi.Network.String()).Msg("Creating indexer object...")
 	bi.receiver = NewReceiver(bi.RPC, 20, indexerConfig.ReceiverThreads)
 	bi.startLevel = indexerConfig.ResolveStartLevel()
+	bi.bulkThreshold = indexerConfig.BulkThreshold
+	bi.bulkSize = indexerConfig.ResolveBulkSize()
+	bi.metadataParser = newMetadataParser(bi.Context, cfg.Indexer.Metadata)
 
 	bi.refreshTimer = make(chan struct{}, 10)
 	return bi.init(ctx, bi.StorageDB)
@@ -2,14 +2,26 @@ package indexer
 
 import (
 	"context"
+	"database/sql"
+	"database/sql/driver"
 	"net/url"
+	"sync"
 	"testing"
 	"time"
 
+	"github.com/baking-bad/bcdhub/internal/config"
+	"github.com/baking-bad/bcdhub/internal/models/block"
+	"github.com/baking-bad/bcdhub/internal/models/mock"
+	mock_block "github.com/baking-bad/bcdhub/internal/models/mock/block"
 	"github.com/baking-bad/bcdhub/internal/models/types"
+	"github.com/baking-bad/bcdhub/internal/noderpc"
+	"github.com/baking-bad/bcdhub/internal/postgres/core"
 	"github.com/getsentry/sentry-go"
 	"github.com/pkg/errors"
 	"github.com/stretchr/testify/require"
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect/pgdialect"
+	"go.uber.org/mock/gomock"
 )
 
 type capturingTransport struct {
@@ -65,3 +77,85 @@ func TestReportProcessError(t *testing.T) {
 		})
 	}
 }
+
+// txDriver - database driver which only opens and finishes transactions
+type txDriver struct{}
+
+func (txDriver) Connect(context.Context) (driver.Conn, error) { return txConn{}, nil }
+func (d txDriver) Driver() driver.Driver                      { return d }
+func (txDriver) Open(string) (driver.Conn, error)             { return txConn{}, nil }
+
+type txConn struct{}
+
+func (txConn) Prepare(string) (driver.Stmt, error) {
+	return nil, errors.New("queries aren't supported")
+}
+func (txConn) Close() error              { return nil }
+func (txConn) Begin() (driver.Tx, error) { return txConn{}, nil }
+func (txConn) Commit() error             { return nil }
+func (txConn) Rollback() error           { return nil }
+
+// Ticker checks head and rolls back indexer state while received blocks are processed:
+// both goroutines roll back the same reorganisation, so they read and write indexer state concurrently. Run it with -race.
+func TestBlockchainIndexer_ProcessWhileIndexing(t *testing.T) {
+	const (
+		indexedLevel = 10
+		iterations   = 50
+	)
+	ctrl := gomock.NewController(t)
+	indexed, node := forkedChains(indexedLevel, indexedLevel+1, indexedLevel-1, true)
+
+	rpc := noderpc.NewMockINode(ctrl)
+	rpc.EXPECT().GetHead(gomock.Any()).Return(node[indexedLevel], nil).AnyTimes()
+	rpc.EXPECT().
+		GetHeader(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, level int64) (noderpc.Header, error) {
+			return node[level], nil
+		}).
+		AnyTimes()
+
+	// indexed block is returned as the last one after each rollback, so every head check and every received block finds reorganisation
+	blocks := mock_block.NewMockRepository(ctrl)
+	blocks.EXPECT().Get(gomock.Any(), gomock.Any()).Return(block.Block{}, sql.ErrNoRows).AnyTimes()
+	blocks.EXPECT().Last(gomock.Any()).Return(indexed[indexedLevel], nil).AnyTimes()
+
+	storage := mock.NewMockGeneralRepository(ctrl)
+	storage.EXPECT().IsRecordNotFound(gomock.Any()).
+		DoAndReturn(func(err error) bool { return errors.Is(err, sql.ErrNoRows) }).
+		AnyTimes()
+	storage.EXPECT().Notify(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+
+	db := bun.NewDB(sql.OpenDB(txDriver{}), pgdialect.New())
+	defer db.Close()
+
+	bi := &BlockchainIndexer{
+		Context: &config.Context{
+			RPC:       rpc,
+			Blocks:    blocks,
+			Storage:   storage,
+			StorageDB: &core.Postgres{DB: db},
+		},
+		Network:  types.Mainnet,
+		receiver: NewReceiver(rpc, 20, 1),
+		blocks:   make(map[int64]*Block),
+		state:    indexed[indexedLevel],
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		bi.indexBlock(ctx)
+	}()
+
+	for range iterations {
+		header := node[indexedLevel+1]
+		bi.receiver.blocks <- &Block{Header: header}
+		require.ErrorIs(t, bi.process(ctx), errSameLevel)
+	}
+
+	cancel()
+	wg.Wait()
+	require.Equal(t, indexed[indexedLevel], bi.state)
+}
@@ -3,18 +3,29 @@ package indexer
 import (
 	"context"
 
+	"github.com/baking-bad/bcdhub/internal/models/account"
 	"github.com/baking-bad/bcdhub/internal/models/bigmapaction"
 	"github.com/baking-bad/bcdhub/internal/models/bigmapdiff"
 	"github.com/baking-bad/bcdhub/internal/models/contract"
+	contractmetadata "github.com/baking-bad/bcdhub/internal/models/contract_metadata"
 	"github.com/baking-bad/bcdhub/internal/models/migration"
 	"github.com/baking-bad/bcdhub/internal/models/operation"
+	smartrollup "github.com/baking-bad/bcdhub/internal/models/smart_rollup"
+	"github.com/baking-bad/bcdhub/internal/models/stats"
 	"github.com/baking-bad/bcdhub/internal/models/ticket"
+	"github.com/baking-bad/bcdhub/internal/models/token"
 	"github.com/rs/zerolog/log"
 )
 
 func (bi *BlockchainIndexer) createIndices(ctx context.Context) error {
 	log.Info().Str("network", bi.Network.String()).Msg("creating database indices...")
 
+	// Accounts
+	accounts := (*account.Account)(nil)
+	if err := bi.Storage.CreateIndex(ctx, "accounts_address_pattern_idx", "address text_pattern_ops", accounts); err != nil {
+		return err
+	}
+
 	// Big map action
 	action := (*bigmapaction.BigMapAction)(nil)
 	if err := bi.Storage.CreateIndex(ctx, "big_map_action_level_idx", "level", action); err != nil {
@@ -39,6 +50,12 @@ func (bi *BlockchainIndexer) createIndices(ctx context.Context) error {
 		return err
 	}
 
+	// Big map state
+	state := (*bigmapdiff.BigMapState)(nil)
+	if err := bi.Storage.CreateGinIndex(ctx, "big_map_state_key_trgm_idx", "(encode(key, 'escape')) public.gin_trgm_ops", state); err != nil {
+		return err
+	}
+
 	// Contracts
 	contractModel := (*contract.Contract)(nil)
 	if err := bi.Storage.CreateIndex(ctx, "contracts_level_idx", "level", contractModel); err != nil {
@@ -50,6 +67,9 @@ func (bi *BlockchainIndexer) createIndices(ctx context.Context) error {
 	if err := bi.Storage.CreateIndex(ctx, "global_constants_address_idx", "address", globalConstant); err != nil {
 		return err
 	}
+	if err := bi.Storage.CreateIndex(ctx, "global_constants_address_pattern_idx", "address text_pattern_ops", globalConstant); err != nil {
+		return err
+	}
 
 	// Migrations
 	migration := (*migration.Migration)(nil)
@@ -95,6 +115,27 @@ func (bi *BlockchainIndexer) createIndices(ctx context.Context) error {
 	if err := bi.Storage.CreateIndex(ctx, "operations_source_timestamp_idx", "source_id, timestamp", operation); err != nil {
 		return err
 	}
+	if err := bi.Storage.CreateIndex(ctx, "operations_initiator_timestamp_idx", "initiator_id, timestamp", operation); err != nil {
+		return err
+	}
+	if err := bi.Storage.CreateIndex(ctx, "operations_delegate_timestamp_idx", "delegate_id, timestamp", operation); err != nil {
+		return err
+	}
+	if err := bi.Storage.CreateIndex(ctx, "operations_tag_idx", "tag", operation); err != nil {
+		return err
+	}
+	if err := bi.Storage.CreateIndex(ctx, "operations_payload_type_hash_idx", "(encode(sha256(payload_type), 'hex'))", operation); err != nil {
+		return err
+	}
+
+	// Scripts
+	script := (*contract.Script)(nil)
+	if err := bi.Storage.CreateGinIndex(ctx, "scripts_entrypoints_trgm_idx", "(public.immutable_array_to_string(entrypoints)) public.gin_trgm_ops", script); err != nil {
+		return err
+	}
+	if err := bi.Storage.CreateGinIndex(ctx, "scripts_annotations_trgm_idx", "(public.immutable_array_to_string(annotations)) public.gin_trgm_ops", script); err != nil {
+		return err
+	}
 
 	// Scripts to global constants
 	scriptConstants := (*contract.ScriptConstants)(nil)
@@ -129,6 +170,65 @@ func (bi *BlockchainIndexer) createIndices(ctx context.Context) error {
 		return err
 	}
 
+	// Token transfers
+	tokenTransfer := (*token.Transfer)(nil)
+	if err := bi.Storage.CreateIndex(ctx, "token_transfers_level_idx", "level", tokenTransfer); err != nil {
+		return err
+	}
+	if err := bi.Storage.CreateIndex(ctx, "token_transfers_operation_id_idx", "operation_id", tokenTransfer); err != nil {
+		return err
+	}
+	if err := bi.Storage.CreateIndex(ctx, "token_transfers_contract_id_idx", "contract_id, token_id", tokenTransfer); err != nil {
+		return err
+	}
+	if err := bi.Storage.CreateIndex(ctx, "token_transfers_from_id_idx", "from_id", tokenTransfer); err != nil {
+		return err
+	}
+	if err := bi.Storage.CreateIndex(ctx, "token_transfers_to_id_idx", "to_id", tokenTransfer); err != nil {
+		return err
+	}
+
+	// Token balances
+	tokenBalance := (*token.Balance)(nil)
+	if err := bi.Storage.CreateIndex(ctx, "token_balances_account_id_idx", "account_id", tokenBalance); err != nil {
+		return err
+	}
+
+	// Contract metadata
+	if err := bi.Storage.CreateIndex(ctx, "contract_metadata_level_idx", "level", (*contractmetadata.ContractMetadata)(nil)); err != nil {
+		return err
+	}
+
+	// Smart rollup lifecycle
+	for name, model := range map[string]any{
+		"smart_rollup_commitments":     (*smartrollup.Commitment)(nil),
+		"smart_rollup_cementations":    (*smartrollup.Cementation)(nil),
+		"smart_rollup_refutations":     (*smartrollup.Refutation)(nil),
+		"smart_rollup_bond_recoveries": (*smartrollup.BondRecovery)(nil),
+		"smart_rollup_outbox_messages": (*smartrollup.OutboxMessage)(nil),
+	} {
+		if err := bi.Storage.CreateIndex(ctx, name+"_level_idx", "level", model); err != nil {
+			return err
+		}
+		if err := bi.Storage.CreateIndex(ctx, name+"_rollup_id_idx", "rollup_id", model); err != nil {
+			return err
+		}
+	}
+	if err := bi.Storage.CreateIndex(ctx, "smart_rollup_inbox_messages_level_idx", "level", (*smartrollup.InboxMessage)(nil)); err != nil {
+		return err
+	}
+
+	// Stats series
+	if err := bi.Storage.CreateIndex(ctx, "stats_series_contract_id_timestamp_idx", "contract_id, timestamp", (*stats.Series)(nil)); err != nil {
+		return err
+	}
+	if err := bi.Storage.CreateIndex(ctx, "stats_callers_contract_id_timestamp_idx", "contract_id, timestamp", (*stats.Caller)(nil)); err != nil {
+		return err
+	}
+	if err := bi.Storage.CreateIndex(ctx, "stats_callers_level_idx", "level", (*stats.Caller)(nil)); err != nil {
+		return err
+	}
+
 	log.Info().Str("network", bi.Network.String()).Msg("database indices was created")
 
 	return nil
@@ -10,6 +10,6 @@ import (
 type Indexer interface {
 	Start(ctx context.Context)
 	Index(ctx context.Context, head noderpc.Header) error
-	Rollback(ctx context.Context) error
+	Rollback(ctx context.Context, head noderpc.Header) error
 	Close() error
 }
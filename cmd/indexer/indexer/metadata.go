@@ -0,0 +1,212 @@
+package indexer
+
+import (
+	"context"
+	"time"
+
+	"github.com/baking-bad/bcdhub/internal/config"
+	modelMetadata "github.com/baking-bad/bcdhub/internal/models/contract_metadata"
+	"github.com/baking-bad/bcdhub/internal/models/operation"
+	contractmetadata "github.com/baking-bad/bcdhub/internal/parsers/contract_metadata"
+	"github.com/baking-bad/bcdhub/internal/postgres/core"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	metadataQueueSize      = 1024
+	metadataBackfillSize   = 100
+	metadataBackfillPeriod = 10 * time.Minute
+)
+
+type metadataTask struct {
+	address   string
+	ptr       int64
+	level     int64
+	timestamp time.Time
+}
+
+func newMetadataTask(update modelMetadata.Update) metadataTask {
+	return metadataTask{
+		address:   update.Address,
+		ptr:       update.Ptr,
+		level:     update.Level,
+		timestamp: update.Timestamp,
+	}
+}
+
+func newMetadataParser(ctx *config.Context, cfg config.MetadataConfig) *contractmetadata.Parser {
+	if !cfg.Enabled {
+		return nil
+	}
+
+	var fetcher contractmetadata.Fetcher
+	if cfg.Directory != "" {
+		fetcher = contractmetadata.NewDirectoryFetcher(cfg.Directory)
+	} else {
+		timeout := time.Duration(cfg.Timeout) * time.Second
+		if timeout <= 0 {
+			timeout = 10 * time.Second
+		}
+		fetcher = contractmetadata.NewHTTPFetcher(timeout, cfg.IPFSGateways...)
+	}
+
+	parser := contractmetadata.NewParser(ctx.BigMapDiffs, ctx.RPC, fetcher, ctx.Network.String())
+	return &parser
+}
+
+// enqueueMetadata - schedules metadata resolution for contracts which `%metadata` big map was changed by the operations.
+// Resolution may require network requests, so it's executed in background and doesn't block indexing.
+func (bi *BlockchainIndexer) enqueueMetadata(operations []*operation.Operation) {
+	if bi.metadataParser == nil {
+		return
+	}
+
+	queued := make(map[string]struct{})
+	for _, op := range operations {
+		ptr, ok := metadataUpdate(op)
+		if !ok {
+			continue
+		}
+		if _, ok := queued[op.Destination.Address]; ok {
+			continue
+		}
+		queued[op.Destination.Address] = struct{}{}
+
+		select {
+		case bi.metadataTasks <- metadataTask{
+			address:   op.Destination.Address,
+			ptr:       ptr,
+			level:     op.Level,
+			timestamp: op.Timestamp,
+		}:
+		default:
+			log.Warn().
+				Str("network", bi.Network.String()).
+				Str("address", op.Destination.Address).
+				Msg("metadata queue is full: resolution is postponed to backfill")
+		}
+	}
+}
+
+// metadataUpdate - returns pointer of `%metadata` big map if the operation changed it
+func metadataUpdate(op *operation.Operation) (int64, bool) {
+	if !op.IsApplied() || op.AST == nil || len(op.BigMapDiffs) == 0 || len(op.DeffatedStorage) == 0 {
+		return 0, false
+	}
+
+	storage, err := op.AST.StorageType()
+	if err != nil {
+		return 0, false
+	}
+	if err := storage.SettleFromBytes(op.DeffatedStorage); err != nil {
+		return 0, false
+	}
+	ptr, ok := contractmetadata.FindMetadataPointer(storage)
+	if !ok {
+		return 0, false
+	}
+
+	for _, diff := range op.BigMapDiffs {
+		if diff.Ptr == ptr {
+			return ptr, true
+		}
+	}
+	return 0, false
+}
+
+// resolveMetadata - resolves queued tasks and periodically backfills metadata which wasn't resolved:
+// contracts indexed before metadata resolution was enabled, tasks dropped on full queue or lost on restart.
+// Failed tasks are retried after restart or next change of metadata only.
+func (bi *BlockchainIndexer) resolveMetadata(ctx context.Context) {
+	// failed - level of the last failed resolution by address
+	failed := make(map[string]int64)
+	bi.backfillMetadata(ctx, failed)
+
+	ticker := time.NewTicker(metadataBackfillPeriod)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			bi.backfillMetadata(ctx, failed)
+		case task := <-bi.metadataTasks:
+			bi.resolveTask(ctx, task, failed)
+		}
+	}
+}
+
+func (bi *BlockchainIndexer) backfillMetadata(ctx context.Context, failed map[string]int64) {
+	var offset, resolved int
+	for {
+		updates, err := bi.Metadata.Unresolved(ctx, metadataBackfillSize, offset)
+		if err != nil {
+			log.Err(err).Str("network", bi.Network.String()).Msg("receive unresolved contract metadata")
+			return
+		}
+		if len(updates) == 0 {
+			break
+		}
+
+		for i := range updates {
+			if ctx.Err() != nil {
+				return
+			}
+			task := newMetadataTask(updates[i])
+			if level, ok := failed[task.address]; (ok && level >= task.level) || !bi.resolveTask(ctx, task, failed) {
+				// resolved metadata leaves the set of unresolved ones, failed is skipped
+				offset++
+				continue
+			}
+			resolved++
+		}
+	}
+
+	if resolved > 0 {
+		log.Info().Str("network", bi.Network.String()).Int("resolved", resolved).Msg("contract metadata backfill")
+	}
+}
+
+func (bi *BlockchainIndexer) resolveTask(ctx context.Context, task metadataTask, failed map[string]int64) bool {
+	if err := bi.saveMetadata(ctx, task); err != nil {
+		failed[task.address] = task.level
+		log.Warn().
+			Err(err).
+			Str("network", bi.Network.String()).
+			Str("address", task.address).
+			Msg("contract metadata resolution")
+		return false
+	}
+	return true
+}
+
+func (bi *BlockchainIndexer) saveMetadata(ctx context.Context, task metadataTask) error {
+	metadata, err := bi.metadataParser.Parse(ctx, task.address, task.ptr)
+	if err != nil {
+		return err
+	}
+	metadata.Level = task.level
+	metadata.Timestamp = task.timestamp
+
+	tx, err := core.NewTransaction(ctx, bi.StorageDB.DB)
+	if err != nil {
+		return err
+	}
+	if err := tx.ContractMetadata(ctx, metadata); err != nil {
+		if rbErr := tx.Rollback(); rbErr != nil {
+			log.Err(rbErr).Msg("rollback contract metadata transaction")
+		}
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	log.Info().
+		Str("network", bi.Network.String()).
+		Str("address", task.address).
+		Str("uri", metadata.URI).
+		Msg("contract metadata resolved")
+	return nil
+}
@@ -91,8 +91,8 @@ func (p *PeriodicIndexer) Index(ctx context.Context, head noderpc.Header) error
 }
 
 // Rollback -
-func (p *PeriodicIndexer) Rollback(ctx context.Context) error {
-	return p.indexer.Rollback(ctx)
+func (p *PeriodicIndexer) Rollback(ctx context.Context, head noderpc.Header) error {
+	return p.indexer.Rollback(ctx, head)
 }
 
 func (p *PeriodicIndexer) handleUrlChanged(ctx context.Context, network, url string) error {
@@ -71,6 +71,11 @@ func (r *Receiver) Blocks() <-chan *Block {
 	return r.blocks
 }
 
+// QueueSize - returns count of received blocks which aren't taken from the queue
+func (r *Receiver) QueueSize() int {
+	return len(r.blocks)
+}
+
 func (r *Receiver) get(ctx context.Context, level int64) (Block, error) {
 	var block Block
 	header, err := r.rpc.Block(ctx, level)
@@ -0,0 +1,75 @@
+package indexer
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/baking-bad/bcdhub/internal/noderpc"
+	"github.com/stretchr/testify/require"
+)
+
+func testBlockHandler(w http.ResponseWriter, r *http.Request) {
+	path := strings.TrimPrefix(r.URL.Path, "/chains/main/blocks/")
+	if path == "head/helpers/current_level" {
+		fmt.Fprint(w, `{"level":100}`)
+		return
+	}
+	fmt.Fprintf(w, `{"chain_id":"chain","hash":"block_%s","protocol":"proto","header":{"level":%[1]s,"predecessor":"pred_%[1]s"},"metadata":{},"operations":[[],[],[],[{"hash":"op_%[1]s","contents":[{"kind":"transaction"}]}]]}`, path)
+}
+
+func TestReceiver_cacheOnly(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	server := httptest.NewServer(http.HandlerFunc(testBlockHandler))
+	dir := t.TempDir()
+
+	online, err := noderpc.NewCache(noderpc.NewNodeRPC(server.URL), dir)
+	require.NoError(t, err)
+	for level := int64(10); level <= 12; level++ {
+		_, err := online.Block(ctx, level)
+		require.NoError(t, err)
+	}
+	server.Close()
+
+	cache, err := noderpc.NewCache(noderpc.NewNodeRPC(""), dir)
+	require.NoError(t, err)
+
+	head, err := cache.GetHead(ctx)
+	require.NoError(t, err)
+	require.EqualValues(t, 12, head.Level)
+	require.Equal(t, "block_12", head.Hash)
+	require.Equal(t, "chain", head.ChainID)
+
+	receiver := NewReceiver(cache, 10, 2)
+	receiver.Start(ctx)
+
+	for level := int64(10); level <= head.Level; level++ {
+		receiver.AddTask(level)
+	}
+
+	received := make(map[int64]*Block)
+	for len(received) < 3 {
+		select {
+		case <-ctx.Done():
+			t.Fatal("blocks aren't received from cache")
+		case block := <-receiver.Blocks():
+			received[block.Header.Level] = block
+		}
+	}
+	cancel()
+	require.NoError(t, receiver.Close())
+
+	for level := int64(10); level <= 12; level++ {
+		block, ok := received[level]
+		require.True(t, ok)
+		require.Equal(t, fmt.Sprintf("block_%d", level), block.Header.Hash)
+		require.Equal(t, fmt.Sprintf("pred_%d", level), block.Header.Predecessor)
+		require.Len(t, block.OPG, 1)
+	}
+}
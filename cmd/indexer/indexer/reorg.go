@@ -0,0 +1,66 @@
+package indexer
+
+import (
+	"context"
+
+	"github.com/baking-bad/bcdhub/internal/models/block"
+	"github.com/baking-bad/bcdhub/internal/noderpc"
+	"github.com/pkg/errors"
+)
+
+// findCommonAncestor - walks back the indexed chain ending with `state` and the node chain ending with `head`
+// until they agree and returns level of the last common block. It returns `state.Level` if there is no reorganisation.
+func findCommonAncestor(ctx context.Context, rpc noderpc.INode, blocks block.Repository, state block.Block, head noderpc.Header) (int64, error) {
+	if state.Level == 0 {
+		return 0, nil
+	}
+
+	// the most frequent case: head is the next block of the indexed chain
+	if head.Level == state.Level+1 && head.Predecessor == state.Hash {
+		return state.Level, nil
+	}
+
+	indexed := state
+	if head.Level < state.Level {
+		b, err := blocks.Get(ctx, head.Level)
+		if err != nil {
+			return 0, errors.Wrapf(err, "get indexed block %d", head.Level)
+		}
+		indexed = b
+	}
+
+	node := head
+	if node.Level != indexed.Level {
+		h, err := rpc.GetHeader(ctx, indexed.Level)
+		if err != nil {
+			return 0, errors.Wrapf(err, "get node header %d", indexed.Level)
+		}
+		node = h
+	}
+
+	for node.Hash != indexed.Hash {
+		// predecessors are stored since version with reorg detection, so blocks indexed before have empty ones
+		if indexed.Predecessor != "" && indexed.Predecessor == node.Predecessor {
+			return indexed.Level - 1, nil
+		}
+
+		level := indexed.Level - 1
+		if level < 1 {
+			return 0, errors.New("indexed chain doesn't have common blocks with node chain")
+		}
+
+		b, err := blocks.Get(ctx, level)
+		if err != nil {
+			return 0, errors.Wrapf(err, "get indexed block %d", level)
+		}
+		indexed = b
+
+		h, err := rpc.GetHeader(ctx, level)
+		if err != nil {
+			return 0, errors.Wrapf(err, "get node header %d", level)
+		}
+		node = h
+	}
+
+	return indexed.Level, nil
+}
@@ -0,0 +1,174 @@
+package indexer
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"testing"
+
+	"github.com/baking-bad/bcdhub/internal/models/block"
+	mock_block "github.com/baking-bad/bcdhub/internal/models/mock/block"
+	"github.com/baking-bad/bcdhub/internal/noderpc"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func forkHash(level, commonLevel int64, branch string) string {
+	if level <= commonLevel {
+		return fmt.Sprintf("common%d", level)
+	}
+	return fmt.Sprintf("%s%d", branch, level)
+}
+
+// forkedChains - returns indexed and node chains which are equal up to `commonLevel` and differ after it
+func forkedChains(indexedLevel, nodeLevel, commonLevel int64, withPredecessors bool) (map[int64]block.Block, map[int64]noderpc.Header) {
+	indexed := make(map[int64]block.Block)
+	for level := int64(1); level <= indexedLevel; level++ {
+		b := block.Block{
+			Level: level,
+			Hash:  forkHash(level, commonLevel, "indexed"),
+		}
+		if withPredecessors {
+			b.Predecessor = forkHash(level-1, commonLevel, "indexed")
+		}
+		indexed[level] = b
+	}
+
+	node := make(map[int64]noderpc.Header)
+	for level := int64(1); level <= nodeLevel; level++ {
+		node[level] = noderpc.Header{
+			Level:       level,
+			Hash:        forkHash(level, commonLevel, "node"),
+			Predecessor: forkHash(level-1, commonLevel, "node"),
+		}
+	}
+	return indexed, node
+}
+
+func TestFindCommonAncestor(t *testing.T) {
+	tests := []struct {
+		name             string
+		indexedLevel     int64
+		nodeLevel        int64
+		commonLevel      int64
+		withPredecessors bool
+		want             int64
+		wantRPCCalls     int
+		wantErr          bool
+	}{
+		{
+			name:             "next block",
+			indexedLevel:     10,
+			nodeLevel:        11,
+			commonLevel:      10,
+			withPredecessors: true,
+			want:             10,
+			wantRPCCalls:     0,
+		}, {
+			name:             "head is far ahead",
+			indexedLevel:     10,
+			nodeLevel:        15,
+			commonLevel:      10,
+			withPredecessors: true,
+			want:             10,
+			wantRPCCalls:     1,
+		}, {
+			name:             "same level with another hash",
+			indexedLevel:     10,
+			nodeLevel:        10,
+			commonLevel:      9,
+			withPredecessors: true,
+			want:             9,
+			wantRPCCalls:     0,
+		}, {
+			name:             "next block of another branch",
+			indexedLevel:     10,
+			nodeLevel:        11,
+			commonLevel:      8,
+			withPredecessors: true,
+			want:             8,
+			wantRPCCalls:     2,
+		}, {
+			name:             "deep reorganisation",
+			indexedLevel:     20,
+			nodeLevel:        22,
+			commonLevel:      14,
+			withPredecessors: true,
+			want:             14,
+			wantRPCCalls:     6,
+		}, {
+			name:             "node head is behind indexed state",
+			indexedLevel:     20,
+			nodeLevel:        17,
+			commonLevel:      15,
+			withPredecessors: true,
+			want:             15,
+			wantRPCCalls:     1,
+		}, {
+			name:             "node head is behind indexed state on the same chain",
+			indexedLevel:     20,
+			nodeLevel:        17,
+			commonLevel:      17,
+			withPredecessors: true,
+			want:             17,
+			wantRPCCalls:     0,
+		}, {
+			name:             "blocks without predecessors",
+			indexedLevel:     20,
+			nodeLevel:        20,
+			commonLevel:      12,
+			withPredecessors: false,
+			want:             12,
+			wantRPCCalls:     8,
+		}, {
+			name:             "no common blocks",
+			indexedLevel:     5,
+			nodeLevel:        5,
+			commonLevel:      0,
+			withPredecessors: false,
+			wantErr:          true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			indexed, node := forkedChains(tt.indexedLevel, tt.nodeLevel, tt.commonLevel, tt.withPredecessors)
+
+			rpc := noderpc.NewMockINode(ctrl)
+			var rpcCalls int
+			rpc.EXPECT().
+				GetHeader(gomock.Any(), gomock.Any()).
+				DoAndReturn(func(_ context.Context, level int64) (noderpc.Header, error) {
+					rpcCalls++
+					header, ok := node[level]
+					if !ok {
+						return header, noderpc.NewNodeUnavailiableError("https://node.test", 404)
+					}
+					return header, nil
+				}).
+				AnyTimes()
+
+			blocks := mock_block.NewMockRepository(ctrl)
+			blocks.EXPECT().
+				Get(gomock.Any(), gomock.Any()).
+				DoAndReturn(func(_ context.Context, level int64) (block.Block, error) {
+					b, ok := indexed[level]
+					if !ok {
+						return b, sql.ErrNoRows
+					}
+					return b, nil
+				}).
+				AnyTimes()
+
+			got, err := findCommonAncestor(context.Background(), rpc, blocks, indexed[tt.indexedLevel], node[tt.nodeLevel])
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+			require.Equal(t, tt.wantRPCCalls, rpcCalls)
+		})
+	}
+}
@@ -0,0 +1,235 @@
+package bcd
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+
+	"github.com/baking-bad/bcdhub/internal/bcd/consts"
+	"github.com/baking-bad/bcdhub/internal/bcd/formatter"
+	"github.com/pkg/errors"
+)
+
+// maxConstantExpansions - limit of substitutions in one script. Constant which is used several times in another constant grows code exponentially.
+const maxConstantExpansions = 10000
+
+// ConstantResolver - returns Micheline values of global constants by their hashes
+type ConstantResolver func(hashes ...string) (map[string][]byte, error)
+
+// ConstantExpansion - place in expanded code where global constant was substituted
+type ConstantExpansion struct {
+	Address string `json:"address"`
+	Path    string `json:"path"`
+	Depth   int    `json:"depth"`
+}
+
+// ExpandConstants - substitutes global constants in Micheline code recursively including constants which are used by other constants. Returns expanded code and list of substitutions with gjson paths to them in expanded code.
+func ExpandConstants(code []byte, resolve ConstantResolver) ([]byte, []ConstantExpansion, error) {
+	e := newConstantExpander(resolve, false)
+	expanded, err := e.expand(code)
+	if err != nil {
+		return nil, nil, err
+	}
+	return expanded, e.expansions, nil
+}
+
+// ExpandConstantsMarked - substitutes global constants in Micheline code recursively as ExpandConstants does, but keeps `constant` primitives and puts their expansions under formatter.ExpansionKey, so expansions can be folded back.
+func ExpandConstantsMarked(code []byte, resolve ConstantResolver) ([]byte, error) {
+	return newConstantExpander(resolve, true).expand(code)
+}
+
+type constantExpander struct {
+	resolve ConstantResolver
+	marked  bool
+
+	values     map[string]any
+	expansions []ConstantExpansion
+}
+
+func newConstantExpander(resolve ConstantResolver, marked bool) *constantExpander {
+	return &constantExpander{
+		resolve: resolve,
+		marked:  marked,
+		values:  make(map[string]any),
+	}
+}
+
+func (e *constantExpander) expand(code []byte) ([]byte, error) {
+	tree, err := decodeMicheline(code)
+	if err != nil {
+		return nil, err
+	}
+	if err := e.load(tree); err != nil {
+		return nil, err
+	}
+	expanded, err := e.substitute(tree, "", nil)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(expanded)
+}
+
+// load - receives values of all constants which are used in tree and in its constants
+func (e *constantExpander) load(tree any) error {
+	hashes := collectConstants(tree, nil)
+	for len(hashes) > 0 {
+		values, err := e.resolve(hashes...)
+		if err != nil {
+			return err
+		}
+
+		var nested []string
+		for _, hash := range hashes {
+			raw, ok := values[hash]
+			if !ok {
+				return errors.Errorf("unknown global constant: %s", hash)
+			}
+			value, err := decodeMicheline(raw)
+			if err != nil {
+				return errors.Wrapf(err, "global constant %s", hash)
+			}
+			e.values[hash] = value
+			nested = collectConstants(value, nested)
+		}
+
+		hashes = hashes[:0]
+		for _, hash := range nested {
+			if _, ok := e.values[hash]; !ok {
+				hashes = append(hashes, hash)
+			}
+		}
+	}
+	return nil
+}
+
+func (e *constantExpander) substitute(node any, path string, stack []string) (any, error) {
+	switch typ := node.(type) {
+	case []any:
+		result := make([]any, len(typ))
+		for i := range typ {
+			item, err := e.substitute(typ[i], joinPath(path, strconv.Itoa(i)), stack)
+			if err != nil {
+				return nil, err
+			}
+			result[i] = item
+		}
+		return result, nil
+	case map[string]any:
+		if hash, ok := constantHash(typ); ok {
+			return e.substituteConstant(typ, hash, path, stack)
+		}
+
+		args, ok := typ["args"].([]any)
+		if !ok {
+			return typ, nil
+		}
+		result := make(map[string]any, len(typ))
+		for key, value := range typ {
+			result[key] = value
+		}
+		expandedArgs, err := e.substitute(args, joinPath(path, "args"), stack)
+		if err != nil {
+			return nil, err
+		}
+		result["args"] = expandedArgs
+		return result, nil
+	default:
+		return node, nil
+	}
+}
+
+func (e *constantExpander) substituteConstant(node map[string]any, hash, path string, stack []string) (any, error) {
+	for i := range stack {
+		if stack[i] == hash {
+			return nil, errors.Errorf("global constant %s references itself", hash)
+		}
+	}
+	value, ok := e.values[hash]
+	if !ok {
+		return nil, errors.Errorf("unknown global constant: %s", hash)
+	}
+	if len(e.expansions) >= maxConstantExpansions {
+		return nil, errors.Errorf("too many expansions of global constants: more than %d", maxConstantExpansions)
+	}
+
+	valuePath := path
+	if e.marked {
+		valuePath = joinPath(path, formatter.ExpansionKey)
+	}
+	e.expansions = append(e.expansions, ConstantExpansion{
+		Address: hash,
+		Path:    valuePath,
+		Depth:   len(stack),
+	})
+
+	expanded, err := e.substitute(value, valuePath, append(stack, hash))
+	if err != nil {
+		return nil, err
+	}
+	if !e.marked {
+		return expanded, nil
+	}
+
+	result := make(map[string]any, len(node)+1)
+	for key, value := range node {
+		result[key] = value
+	}
+	result[formatter.ExpansionKey] = expanded
+	return result, nil
+}
+
+func collectConstants(node any, hashes []string) []string {
+	switch typ := node.(type) {
+	case []any:
+		for i := range typ {
+			hashes = collectConstants(typ[i], hashes)
+		}
+	case map[string]any:
+		if hash, ok := constantHash(typ); ok {
+			for i := range hashes {
+				if hashes[i] == hash {
+					return hashes
+				}
+			}
+			return append(hashes, hash)
+		}
+		if args, ok := typ["args"]; ok {
+			hashes = collectConstants(args, hashes)
+		}
+	}
+	return hashes
+}
+
+func constantHash(node map[string]any) (string, bool) {
+	if prim, ok := node["prim"].(string); !ok || prim != consts.CONSTANT {
+		return "", false
+	}
+	args, ok := node["args"].([]any)
+	if !ok || len(args) != 1 {
+		return "", false
+	}
+	arg, ok := args[0].(map[string]any)
+	if !ok {
+		return "", false
+	}
+	hash, ok := arg["string"].(string)
+	return hash, ok
+}
+
+func decodeMicheline(data []byte) (any, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var tree any
+	if err := decoder.Decode(&tree); err != nil {
+		return nil, err
+	}
+	return tree, nil
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
@@ -0,0 +1,103 @@
+package bcd
+
+import (
+	"testing"
+
+	"github.com/baking-bad/bcdhub/internal/bcd/formatter"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+)
+
+func testResolver(values map[string]string) ConstantResolver {
+	return func(hashes ...string) (map[string][]byte, error) {
+		result := make(map[string][]byte)
+		for _, hash := range hashes {
+			if value, ok := values[hash]; ok {
+				result[hash] = []byte(value)
+			}
+		}
+		return result, nil
+	}
+}
+
+func TestExpandConstants(t *testing.T) {
+	tests := []struct {
+		name           string
+		code           string
+		constants      map[string]string
+		want           string
+		wantExpansions []ConstantExpansion
+		wantErr        bool
+	}{
+		{
+			name: "without constants",
+			code: `[{"prim":"DROP"}]`,
+			want: `[{"prim":"DROP"}]`,
+		}, {
+			name: "single constant",
+			code: `[{"prim":"DUP"},{"prim":"constant","args":[{"string":"exprA"}]}]`,
+			constants: map[string]string{
+				"exprA": `[{"prim":"CAR"}]`,
+			},
+			want: `[{"prim":"DUP"},[{"prim":"CAR"}]]`,
+			wantExpansions: []ConstantExpansion{
+				{Address: "exprA", Path: "1"},
+			},
+		}, {
+			name: "nested constants",
+			code: `{"prim":"pair","args":[{"prim":"constant","args":[{"string":"exprA"}]},{"prim":"nat"}]}`,
+			constants: map[string]string{
+				"exprA": `{"prim":"option","args":[{"prim":"constant","args":[{"string":"exprB"}]}]}`,
+				"exprB": `{"prim":"int"}`,
+			},
+			want: `{"prim":"pair","args":[{"prim":"option","args":[{"prim":"int"}]},{"prim":"nat"}]}`,
+			wantExpansions: []ConstantExpansion{
+				{Address: "exprA", Path: "args.0"},
+				{Address: "exprB", Path: "args.0.args.0", Depth: 1},
+			},
+		}, {
+			name:    "unknown constant",
+			code:    `[{"prim":"constant","args":[{"string":"exprA"}]}]`,
+			wantErr: true,
+		}, {
+			name: "recursive constant",
+			code: `[{"prim":"constant","args":[{"string":"exprA"}]}]`,
+			constants: map[string]string{
+				"exprA": `[{"prim":"constant","args":[{"string":"exprB"}]}]`,
+				"exprB": `[{"prim":"constant","args":[{"string":"exprA"}]}]`,
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, expansions, err := ExpandConstants([]byte(tt.code), testResolver(tt.constants))
+			require.Equal(t, tt.wantErr, err != nil, err)
+			if tt.wantErr {
+				return
+			}
+			require.JSONEq(t, tt.want, string(got))
+			require.Equal(t, tt.wantExpansions, expansions)
+
+			for _, expansion := range expansions {
+				require.True(t, gjson.GetBytes(got, expansion.Path).Exists(), expansion.Path)
+			}
+		})
+	}
+}
+
+func TestExpandConstantsMarked(t *testing.T) {
+	code := `[{"prim":"DUP"},{"prim":"constant","args":[{"string":"exprA"}]}]`
+	constants := map[string]string{
+		"exprA": `[{"prim":"CAR"},{"prim":"constant","args":[{"string":"exprB"}]}]`,
+		"exprB": `{"prim":"DROP"}`,
+	}
+
+	got, err := ExpandConstantsMarked([]byte(code), testResolver(constants))
+	require.NoError(t, err)
+	require.JSONEq(t, `[{"prim":"DUP"},{"prim":"constant","args":[{"string":"exprA"}],"expansion":[{"prim":"CAR"},{"prim":"constant","args":[{"string":"exprB"}],"expansion":{"prim":"DROP"}}]}]`, string(got))
+
+	michelson, err := formatter.MichelineToMichelson(gjson.ParseBytes(got), true, formatter.DefLineSize)
+	require.NoError(t, err)
+	require.Equal(t, `{ DUP ; /* constant "exprA" */ { CAR ; /* constant "exprB" */ DROP } }`, michelson)
+}
@@ -13,7 +13,14 @@ const (
 	TransferTicket         = "transfer_ticket"
 	SrOriginate            = "smart_rollup_originate"
 	SrExecuteOutboxMessage = "smart_rollup_execute_outbox_message"
+	SrAddMessages          = "smart_rollup_add_messages"
+	SrCement               = "smart_rollup_cement"
+	SrPublish              = "smart_rollup_publish"
+	SrRefute               = "smart_rollup_refute"
+	SrTimeout              = "smart_rollup_timeout"
+	SrRecoverBond          = "smart_rollup_recover_bond"
 	Delegation             = "delegation"
+	Reveal                 = "reveal"
 )
 
 // Error IDs
@@ -6,4 +6,5 @@ var (
 	NewNftLedgerSingleAsset, _ = ast.NewTypedAstFromString(`{"prim":"big_map","args":[{"prim":"address"},{"prim":"nat"}]}`)
 	NewNftLedgerAsset, _       = ast.NewTypedAstFromString(`{"prim":"big_map","args":[{"prim":"nat"},{"prim":"address"}]}`)
 	NewNftLedgerMultiAsset, _  = ast.NewTypedAstFromString(`{"prim":"big_map","args":[{"prim":"pair","args":[{"prim":"address"},{"prim":"nat"}]},{"prim":"nat"}]}`)
+	NewFA12LedgerAllowances, _ = ast.NewTypedAstFromString(`{"prim":"big_map","args":[{"prim":"address"},{"prim":"pair","args":[{"prim":"nat"},{"prim":"map","args":[{"prim":"address"},{"prim":"nat"}]}]}]}`)
 )
@@ -59,7 +59,7 @@ func UnforgeAddress(str string) (string, error) {
 	case strings.HasPrefix(str, "0002"):
 		return encoding.EncodeBase58String(str[4:], []byte(encoding.PrefixPublicKeyTZ3))
 	case strings.HasPrefix(str, "0003"):
-		return encoding.EncodeBase58String(str[4:], []byte(encoding.PrefixPublicKeyTZ3))
+		return encoding.EncodeBase58String(str[4:], []byte(encoding.PrefixPublicKeyTZ4))
 	case strings.HasPrefix(str, "01") && strings.HasSuffix(str, "00"):
 		return encoding.EncodeBase58String(str[2:len(str)-2], []byte(encoding.PrefixPublicKeyKT1))
 	case strings.HasPrefix(str, "02") && strings.HasSuffix(str, "00"):
@@ -108,8 +108,11 @@ func UnforgeContract(str string) (string, error) {
 
 // PublicKey -
 func PublicKey(val string) ([]byte, error) {
-	prefix := val[4:]
-	decoded, err := encoding.DecodeBase58String(val)
+	if len(val) < 4 {
+		return nil, errors.Errorf("Invalid public key: %s", val)
+	}
+	prefix := val[:4]
+	decoded, err := encoding.DecodeBase58(val)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,346 @@
+package operations
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"math/big"
+	"slices"
+	"strconv"
+
+	"github.com/baking-bad/bcdhub/internal/bcd/base"
+	"github.com/baking-bad/bcdhub/internal/bcd/consts"
+	"github.com/baking-bad/bcdhub/internal/bcd/encoding"
+	"github.com/baking-bad/bcdhub/internal/bcd/forge"
+	"github.com/pkg/errors"
+)
+
+// Forge - forges operation group to bytes. Signature is appended if it's set.
+func Forge(operation Operation) ([]byte, error) {
+	branch, err := encoding.DecodeBase58(operation.Branch)
+	if err != nil {
+		return nil, errors.Wrap(err, "branch")
+	}
+	if len(branch) != branchLength {
+		return nil, errors.Errorf("invalid branch: %s", operation.Branch)
+	}
+	if len(operation.Contents) == 0 {
+		return nil, errors.New("empty operation contents")
+	}
+
+	var buf bytes.Buffer
+	buf.Write(branch)
+	for i := range operation.Contents {
+		if err := forgeContent(&buf, operation.Contents[i]); err != nil {
+			return nil, errors.Wrapf(err, "content %d", i)
+		}
+	}
+
+	if operation.Signature != "" {
+		signature, err := encoding.DecodeBase58(operation.Signature)
+		if err != nil {
+			return nil, errors.Wrap(err, "signature")
+		}
+		if len(signature) != signatureLength {
+			return nil, errors.Errorf("invalid signature: %s", operation.Signature)
+		}
+		buf.Write(signature)
+	}
+	return buf.Bytes(), nil
+}
+
+func forgeContent(buf *bytes.Buffer, content Content) error {
+	tag, ok := kindTags[content.Kind]
+	if !ok {
+		return errors.Wrap(ErrUnknownKind, content.Kind)
+	}
+	if tag == tagSrRefute {
+		return errors.Wrap(ErrUnsupportedKind, content.Kind)
+	}
+	buf.WriteByte(tag)
+
+	if err := forgePublicKeyHash(buf, content.Source); err != nil {
+		return errors.Wrap(err, "source")
+	}
+	for _, value := range []string{content.Fee, content.Counter, content.GasLimit, content.StorageLimit} {
+		if err := forgeNat(buf, value); err != nil {
+			return err
+		}
+	}
+
+	switch tag {
+	case tagReveal:
+		key, err := forge.PublicKey(content.PublicKey)
+		if err != nil {
+			return errors.Wrap(err, "public_key")
+		}
+		buf.Write(key)
+
+	case tagTransaction:
+		if err := forgeNat(buf, content.Amount); err != nil {
+			return err
+		}
+		if err := forgeContractID(buf, content.Destination); err != nil {
+			return errors.Wrap(err, "destination")
+		}
+		return forgeParameters(buf, content.Parameters)
+
+	case tagOrigination:
+		if err := forgeNat(buf, content.Balance); err != nil {
+			return err
+		}
+		if err := forgeOptionalPublicKeyHash(buf, content.Delegate); err != nil {
+			return err
+		}
+		if content.Script == nil {
+			return errors.New("empty script")
+		}
+		if err := forgeMicheline(buf, content.Script.Code); err != nil {
+			return errors.Wrap(err, "code")
+		}
+		return forgeMicheline(buf, content.Script.Storage)
+
+	case tagDelegation:
+		return forgeOptionalPublicKeyHash(buf, content.Delegate)
+
+	case tagRegisterGlobalConstant:
+		return forgeMicheline(buf, content.Value)
+
+	case tagTransferTicket:
+		if err := forgeMicheline(buf, content.TicketContents); err != nil {
+			return errors.Wrap(err, "ticket_contents")
+		}
+		if err := forgeMicheline(buf, content.TicketType); err != nil {
+			return errors.Wrap(err, "ticket_ty")
+		}
+		if err := forgeContractID(buf, content.TicketTicketer); err != nil {
+			return errors.Wrap(err, "ticket_ticketer")
+		}
+		if err := forgeNat(buf, content.TicketAmount); err != nil {
+			return err
+		}
+		if err := forgeContractID(buf, content.Destination); err != nil {
+			return errors.Wrap(err, "destination")
+		}
+		writeBytes(buf, []byte(content.Entrypoint))
+
+	case tagSrOriginate:
+		pvm := slices.Index(pvmKinds, content.PvmKind)
+		if pvm < 0 {
+			return errors.Errorf("unknown pvm kind: %s", content.PvmKind)
+		}
+		buf.WriteByte(byte(pvm))
+		kernel, err := hex.DecodeString(content.Kernel)
+		if err != nil {
+			return errors.Wrap(err, "kernel")
+		}
+		writeBytes(buf, kernel)
+		if err := forgeMicheline(buf, content.ParametersType); err != nil {
+			return errors.Wrap(err, "parameters_ty")
+		}
+		if len(content.Whitelist) == 0 {
+			buf.WriteByte(0)
+			return nil
+		}
+		buf.WriteByte(optionalFieldIsPresentByte)
+		var whitelist bytes.Buffer
+		for i := range content.Whitelist {
+			if err := forgePublicKeyHash(&whitelist, content.Whitelist[i]); err != nil {
+				return errors.Wrap(err, "whitelist")
+			}
+		}
+		writeBytes(buf, whitelist.Bytes())
+
+	case tagSrAddMessages:
+		var messages bytes.Buffer
+		for i := range content.Message {
+			message, err := hex.DecodeString(content.Message[i])
+			if err != nil {
+				return errors.Wrap(err, "message")
+			}
+			writeBytes(&messages, message)
+		}
+		writeBytes(buf, messages.Bytes())
+
+	case tagSrCement:
+		return forgeHash(buf, content.Rollup, rollupAddressLength)
+
+	case tagSrPublish:
+		if err := forgeHash(buf, content.Rollup, rollupAddressLength); err != nil {
+			return err
+		}
+		if content.Commitment == nil {
+			return errors.New("empty commitment")
+		}
+		if err := forgeHash(buf, content.Commitment.CompressedState, hashLength); err != nil {
+			return err
+		}
+		_ = binary.Write(buf, binary.BigEndian, content.Commitment.InboxLevel)
+		if err := forgeHash(buf, content.Commitment.Predecessor, hashLength); err != nil {
+			return err
+		}
+		ticks, err := strconv.ParseInt(content.Commitment.NumberOfTicks, 10, 64)
+		if err != nil {
+			return errors.Wrap(err, "number_of_ticks")
+		}
+		_ = binary.Write(buf, binary.BigEndian, ticks)
+
+	case tagSrTimeout:
+		if err := forgeHash(buf, content.Rollup, rollupAddressLength); err != nil {
+			return err
+		}
+		if content.Stakers == nil {
+			return errors.New("empty stakers")
+		}
+		if err := forgePublicKeyHash(buf, content.Stakers.Alice); err != nil {
+			return errors.Wrap(err, "alice")
+		}
+		return forgePublicKeyHash(buf, content.Stakers.Bob)
+
+	case tagSrExecuteOutboxMessage:
+		if err := forgeHash(buf, content.Rollup, rollupAddressLength); err != nil {
+			return err
+		}
+		if err := forgeHash(buf, content.CementedCommitment, hashLength); err != nil {
+			return err
+		}
+		proof, err := hex.DecodeString(content.OutputProof)
+		if err != nil {
+			return errors.Wrap(err, "output_proof")
+		}
+		writeBytes(buf, proof)
+
+	case tagSrRecoverBond:
+		if err := forgeHash(buf, content.Rollup, rollupAddressLength); err != nil {
+			return err
+		}
+		return forgePublicKeyHash(buf, content.Staker)
+	}
+	return nil
+}
+
+// forgeNat - forges natural number as zarith. Empty string is zero.
+func forgeNat(buf *bytes.Buffer, value string) error {
+	n := new(big.Int)
+	if value != "" {
+		if _, ok := n.SetString(value, 10); !ok {
+			return errors.Errorf("invalid natural number: %s", value)
+		}
+	}
+	if n.Sign() < 0 {
+		return errors.Errorf("invalid natural number: %s", value)
+	}
+
+	mask := big.NewInt(0x7f)
+	for {
+		b := byte(new(big.Int).And(n, mask).Uint64())
+		n.Rsh(n, 7)
+		if n.Sign() == 0 {
+			buf.WriteByte(b)
+			return nil
+		}
+		buf.WriteByte(b | 0x80)
+	}
+}
+
+func forgePublicKeyHash(buf *bytes.Buffer, address string) error {
+	if len(address) < 3 {
+		return errors.Wrap(consts.ErrInvalidAddress, address)
+	}
+	data, err := forge.Address(address, true)
+	if err != nil {
+		return err
+	}
+	if len(data) != publicKeyHashLength {
+		return errors.Wrap(consts.ErrInvalidAddress, address)
+	}
+	buf.Write(data)
+	return nil
+}
+
+func forgeOptionalPublicKeyHash(buf *bytes.Buffer, address string) error {
+	if address == "" {
+		buf.WriteByte(0)
+		return nil
+	}
+	buf.WriteByte(optionalFieldIsPresentByte)
+	return forgePublicKeyHash(buf, address)
+}
+
+func forgeContractID(buf *bytes.Buffer, address string) error {
+	if len(address) < 3 {
+		return errors.Wrap(consts.ErrInvalidAddress, address)
+	}
+	data, err := forge.Address(address, false)
+	if err != nil {
+		return err
+	}
+	if len(data) != contractIDLength {
+		return errors.Wrap(consts.ErrInvalidAddress, address)
+	}
+	buf.Write(data)
+	return nil
+}
+
+// forgeParameters - parameters are omitted if unit is passed to default entrypoint, as node does
+func forgeParameters(buf *bytes.Buffer, parameters *Parameters) error {
+	if parameters == nil || isUnitToDefault(parameters) {
+		buf.WriteByte(0)
+		return nil
+	}
+	buf.WriteByte(optionalFieldIsPresentByte)
+
+	entrypoint := parameters.Entrypoint
+	if entrypoint == "" {
+		entrypoint = consts.DefaultEntrypoint
+	}
+	if idx := slices.Index(entrypointTags, entrypoint); idx >= 0 {
+		buf.WriteByte(byte(idx))
+	} else {
+		if len(entrypoint) > maxEntrypointLength {
+			return errors.Errorf("too long entrypoint: %s", entrypoint)
+		}
+		buf.WriteByte(namedEntrypointTag)
+		buf.WriteByte(byte(len(entrypoint)))
+		buf.WriteString(entrypoint)
+	}
+	return forgeMicheline(buf, parameters.Value)
+}
+
+func isUnitToDefault(parameters *Parameters) bool {
+	if parameters.Entrypoint != "" && parameters.Entrypoint != consts.DefaultEntrypoint {
+		return false
+	}
+	value := parameters.Value
+	return value != nil && value.Prim == consts.Unit && len(value.Args) == 0 && len(value.Annots) == 0
+}
+
+func forgeMicheline(buf *bytes.Buffer, node *base.Node) error {
+	if node == nil {
+		return errors.New("empty micheline expression")
+	}
+	data, err := forge.Forge(node)
+	if err != nil {
+		return err
+	}
+	writeBytes(buf, data)
+	return nil
+}
+
+func forgeHash(buf *bytes.Buffer, value string, length int) error {
+	data, err := encoding.DecodeBase58(value)
+	if err != nil {
+		return errors.Wrap(err, value)
+	}
+	if len(data) != length {
+		return errors.Errorf("invalid hash length: %s", value)
+	}
+	buf.Write(data)
+	return nil
+}
+
+// writeBytes - writes dynamic size bytes with 4 bytes length prefix
+func writeBytes(buf *bytes.Buffer, data []byte) {
+	_ = binary.Write(buf, binary.BigEndian, uint32(len(data))) // #nosec G115 -- operation size is bounded by protocol limits, never close to uint32 max
+	buf.Write(data)
+}
@@ -0,0 +1,12 @@
+package operations
+
+import (
+	"github.com/baking-bad/bcdhub/internal/bcd/encoding"
+	"golang.org/x/crypto/blake2b"
+)
+
+// Hash - returns `op...` hash of forged signed operation group
+func Hash(data []byte) (string, error) {
+	hash := blake2b.Sum256(data)
+	return encoding.EncodeBase58(hash[:], []byte(encoding.PrefixOperationHash))
+}
@@ -0,0 +1,148 @@
+package operations
+
+import (
+	"github.com/baking-bad/bcdhub/internal/bcd/base"
+	"github.com/baking-bad/bcdhub/internal/bcd/consts"
+	"github.com/pkg/errors"
+)
+
+// errors
+var (
+	ErrUnknownKind     = errors.New("unknown operation kind")
+	ErrUnsupportedKind = errors.New("operation kind isn't supported")
+	ErrInvalidData     = errors.New("invalid operation bytes")
+)
+
+// tags of operation contents
+const (
+	tagReveal                 byte = 107
+	tagTransaction            byte = 108
+	tagOrigination            byte = 109
+	tagDelegation             byte = 110
+	tagRegisterGlobalConstant byte = 111
+	tagTransferTicket         byte = 158
+	tagSrOriginate            byte = 200
+	tagSrAddMessages          byte = 201
+	tagSrCement               byte = 202
+	tagSrPublish              byte = 203
+	tagSrRefute               byte = 204
+	tagSrTimeout              byte = 205
+	tagSrExecuteOutboxMessage byte = 206
+	tagSrRecoverBond          byte = 207
+)
+
+const (
+	branchLength                    = 32
+	signatureLength                 = 64
+	rollupAddressLength             = 20
+	hashLength                      = 32
+	publicKeyHashLength             = 21
+	contractIDLength                = 22
+	namedEntrypointTag         byte = 255
+	maxEntrypointLength             = 31
+	optionalFieldIsPresentByte byte = 255
+)
+
+var kindTags = map[string]byte{
+	consts.Reveal:                 tagReveal,
+	consts.Transaction:            tagTransaction,
+	consts.Origination:            tagOrigination,
+	consts.Delegation:             tagDelegation,
+	consts.RegisterGlobalConstant: tagRegisterGlobalConstant,
+	consts.TransferTicket:         tagTransferTicket,
+	consts.SrOriginate:            tagSrOriginate,
+	consts.SrAddMessages:          tagSrAddMessages,
+	consts.SrCement:               tagSrCement,
+	consts.SrPublish:              tagSrPublish,
+	consts.SrRefute:               tagSrRefute,
+	consts.SrTimeout:              tagSrTimeout,
+	consts.SrExecuteOutboxMessage: tagSrExecuteOutboxMessage,
+	consts.SrRecoverBond:          tagSrRecoverBond,
+}
+
+// entrypoints which are encoded by a single byte
+var entrypointTags = []string{
+	consts.DefaultEntrypoint, "root", "do", "set_delegate", "remove_delegate", "deposit", "stake", "unstake", "finalize_unstake", "set_delegate_parameters",
+}
+
+// kinds of smart rollup PVM
+var pvmKinds = []string{"arith", "wasm_2_0_0", "riscv"}
+
+// Operation - operation group in format of `forge/operations` RPC
+type Operation struct {
+	Branch    string    `json:"branch"`
+	Contents  []Content `json:"contents"`
+	Signature string    `json:"signature,omitempty"`
+}
+
+// Content - content of operation group. Fields are filled according to kind of operation. Amounts and limits are decimal strings as in node RPC.
+type Content struct {
+	Kind         string `json:"kind"`
+	Source       string `json:"source"`
+	Fee          string `json:"fee"`
+	Counter      string `json:"counter"`
+	GasLimit     string `json:"gas_limit"`
+	StorageLimit string `json:"storage_limit"`
+
+	PublicKey   string      `json:"public_key,omitempty"`
+	Amount      string      `json:"amount,omitempty"`
+	Destination string      `json:"destination,omitempty"`
+	Parameters  *Parameters `json:"parameters,omitempty"`
+	Balance     string      `json:"balance,omitempty"`
+	Delegate    string      `json:"delegate,omitempty"`
+	Script      *Script     `json:"script,omitempty"`
+	Value       *base.Node  `json:"value,omitempty"`
+
+	TicketContents *base.Node `json:"ticket_contents,omitempty"`
+	TicketType     *base.Node `json:"ticket_ty,omitempty"`
+	TicketTicketer string     `json:"ticket_ticketer,omitempty"`
+	TicketAmount   string     `json:"ticket_amount,omitempty"`
+	Entrypoint     string     `json:"entrypoint,omitempty"`
+
+	PvmKind            string      `json:"pvm_kind,omitempty"`
+	Kernel             string      `json:"kernel,omitempty"`
+	ParametersType     *base.Node  `json:"parameters_ty,omitempty"`
+	Whitelist          []string    `json:"whitelist,omitempty"`
+	Message            []string    `json:"message,omitempty"`
+	Rollup             string      `json:"rollup,omitempty"`
+	Commitment         *Commitment `json:"commitment,omitempty"`
+	CementedCommitment string      `json:"cemented_commitment,omitempty"`
+	OutputProof        string      `json:"output_proof,omitempty"`
+	Stakers            *Stakers    `json:"stakers,omitempty"`
+	Staker             string      `json:"staker,omitempty"`
+}
+
+// Parameters - parameters of transaction
+type Parameters struct {
+	Entrypoint string     `json:"entrypoint"`
+	Value      *base.Node `json:"value"`
+}
+
+// Script - script of originated contract
+type Script struct {
+	Code    *base.Node `json:"code"`
+	Storage *base.Node `json:"storage"`
+}
+
+// Commitment - smart rollup commitment which is published by staker
+type Commitment struct {
+	CompressedState string `json:"compressed_state"`
+	InboxLevel      int32  `json:"inbox_level"`
+	Predecessor     string `json:"predecessor"`
+	NumberOfTicks   string `json:"number_of_ticks"`
+}
+
+// Stakers - players of refutation game
+type Stakers struct {
+	Alice string `json:"alice"`
+	Bob   string `json:"bob"`
+}
+
+func kindByTag(tag byte) (string, bool) {
+	for kind, value := range kindTags {
+		if value == tag {
+			return kind, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,294 @@
+package operations
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/baking-bad/bcdhub/internal/bcd/base"
+	"github.com/baking-bad/bcdhub/internal/bcd/consts"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testBranch    = "BKqoHEY3C15u8zdGwi9Hhj3ArCz2Q8sRQuHVtcWZqUPopsfNZfh"
+	testSignature = "sigSTJNiwaPuZXmU2FscxNy9scPjjwpbxpPD5rY1QRBbyb4gHXYU7jN9Wcbs9sE4GMzuiSSG5S2egeyJhUjW1uJEgw4AWAXj"
+	testSource    = "tz1burnburnburnburnburnburnburjAYjjX"
+	testContract  = "KT1FgscaMyhxoVLbVirJVVKpRXgiSGtDG9Z4"
+	testRollup    = "sr1Ai4iEt4NGDnLxqMrwPrQEvCdqx7S4VEVz"
+
+	testForged = "1111111111111111111111111111111111111111111111111111111111111111" +
+		// transaction
+		"6c00b28066369a8ed09ba9d3d47f19598440266013f0820a0abc508102c0843d014df06cdd999b02e2e601bacf7f1812ecfece52f700" +
+		"ffff087472616e736665720000000a07070100000001610001" +
+		// delegation
+		"6e00b28066369a8ed09ba9d3d47f19598440266013f0e8070be8070000"
+	testHash = "opaAMe1PwDUpJZ5ErtGXGU8mKjTw5bbrtYQ4k9i9Fr87rS4Jar7"
+)
+
+func mustNode(data string) *base.Node {
+	var node base.Node
+	if err := jsoniter.UnmarshalFromString(data, &node); err != nil {
+		panic(err)
+	}
+	return &node
+}
+
+func testOperation() Operation {
+	return Operation{
+		Branch: testBranch,
+		Contents: []Content{
+			{
+				Kind:         consts.Transaction,
+				Source:       testSource,
+				Fee:          "1282",
+				Counter:      "10",
+				GasLimit:     "10300",
+				StorageLimit: "257",
+				Amount:       "1000000",
+				Destination:  testContract,
+				Parameters: &Parameters{
+					Entrypoint: "transfer",
+					Value:      mustNode(`{"prim":"Pair","args":[{"string":"a"},{"int":"1"}]}`),
+				},
+			}, {
+				Kind:         consts.Delegation,
+				Source:       testSource,
+				Fee:          "1000",
+				Counter:      "11",
+				GasLimit:     "1000",
+				StorageLimit: "0",
+			},
+		},
+	}
+}
+
+func TestForge(t *testing.T) {
+	data, err := Forge(testOperation())
+	require.NoError(t, err)
+	require.Equal(t, testForged, hex.EncodeToString(data))
+
+	signed := testOperation()
+	signed.Signature = testSignature
+	data, err = Forge(signed)
+	require.NoError(t, err)
+	require.Equal(t, testForged+hex.EncodeToString(make64(0x22)), hex.EncodeToString(data))
+
+	hash, err := Hash(data)
+	require.NoError(t, err)
+	require.Equal(t, testHash, hash)
+}
+
+func TestUnforge(t *testing.T) {
+	t.Run("unsigned", func(t *testing.T) {
+		operation, err := UnforgeString(testForged)
+		require.NoError(t, err)
+		require.Equal(t, testOperation(), operation)
+	})
+
+	t.Run("signed", func(t *testing.T) {
+		operation, err := UnforgeString(testForged + hex.EncodeToString(make64(0x22)))
+		require.NoError(t, err)
+
+		want := testOperation()
+		want.Signature = testSignature
+		require.Equal(t, want, operation)
+	})
+
+	t.Run("unknown kind", func(t *testing.T) {
+		_, err := UnforgeString(testForged[:64] + "01")
+		require.ErrorIs(t, err, ErrUnknownKind)
+	})
+
+	t.Run("truncated", func(t *testing.T) {
+		_, err := UnforgeString(testForged[:len(testForged)-2])
+		require.Error(t, err)
+	})
+}
+
+func TestForge_UnitToDefaultEntrypoint(t *testing.T) {
+	operation := testOperation()
+	operation.Contents = operation.Contents[:1]
+	operation.Contents[0].Parameters = &Parameters{
+		Entrypoint: consts.DefaultEntrypoint,
+		Value:      mustNode(`{"prim":"Unit"}`),
+	}
+
+	data, err := Forge(operation)
+	require.NoError(t, err)
+	require.Equal(t, "00", hex.EncodeToString(data[len(data)-1:]))
+
+	unforged, err := Unforge(data)
+	require.NoError(t, err)
+	require.Nil(t, unforged.Contents[0].Parameters)
+}
+
+func TestForge_RoundTrip(t *testing.T) {
+	manager := Content{
+		Source:       testSource,
+		Fee:          "100000",
+		Counter:      "123456789",
+		GasLimit:     "1040000",
+		StorageLimit: "60000",
+	}
+	tests := []struct {
+		name   string
+		fill   func(c *Content)
+		length int
+	}{
+		{
+			name: "reveal",
+			fill: func(c *Content) {
+				c.Kind = consts.Reveal
+				c.PublicKey = "edpkuRKcBj1i9qtnp9kAHAr1wDfhHrL2GjhoLcdSKBj9Q9wLD6UVBf"
+			},
+		}, {
+			name: "origination",
+			fill: func(c *Content) {
+				c.Kind = consts.Origination
+				c.Balance = "0"
+				c.Delegate = "tz2KCvBrU8T2ZF3wmcNRMGmQy3Wv9fNq1vBH"
+				c.Script = &Script{
+					Code:    mustNode(`[{"prim":"parameter","args":[{"prim":"unit"}]},{"prim":"storage","args":[{"prim":"unit"}]},{"prim":"code","args":[[{"prim":"CDR"},{"prim":"NIL","args":[{"prim":"operation"}]},{"prim":"PAIR"}]]}]`),
+					Storage: mustNode(`{"prim":"Unit"}`),
+				}
+			},
+		}, {
+			name: "transaction to implicit account with root entrypoint",
+			fill: func(c *Content) {
+				c.Kind = consts.Transaction
+				c.Amount = "0"
+				c.Destination = "tz2KCvBrU8T2ZF3wmcNRMGmQy3Wv9fNq1vBH"
+				c.Parameters = &Parameters{Entrypoint: "stake", Value: mustNode(`{"prim":"Unit"}`)}
+			},
+		}, {
+			name: "register global constant",
+			fill: func(c *Content) {
+				c.Kind = consts.RegisterGlobalConstant
+				c.Value = mustNode(`{"prim":"pair","args":[{"prim":"nat","annots":["%a"]},{"prim":"string"}]}`)
+			},
+		}, {
+			name: "transfer ticket",
+			fill: func(c *Content) {
+				c.Kind = consts.TransferTicket
+				c.TicketContents = mustNode(`{"string":"ticket"}`)
+				c.TicketType = mustNode(`{"prim":"string"}`)
+				c.TicketTicketer = testContract
+				c.TicketAmount = "17"
+				c.Destination = testContract
+				c.Entrypoint = "receive"
+			},
+		}, {
+			name: "smart rollup originate",
+			fill: func(c *Content) {
+				c.Kind = consts.SrOriginate
+				c.PvmKind = "wasm_2_0_0"
+				c.Kernel = "0061736d01000000"
+				c.ParametersType = mustNode(`{"prim":"bytes"}`)
+				c.Whitelist = []string{testSource, "tz2KCvBrU8T2ZF3wmcNRMGmQy3Wv9fNq1vBH"}
+			},
+		}, {
+			name: "smart rollup add messages",
+			fill: func(c *Content) {
+				c.Kind = consts.SrAddMessages
+				c.Message = []string{"0102", "", "ff"}
+			},
+		}, {
+			name: "smart rollup cement",
+			fill: func(c *Content) {
+				c.Kind = consts.SrCement
+				c.Rollup = testRollup
+			},
+		}, {
+			name: "smart rollup publish",
+			fill: func(c *Content) {
+				c.Kind = consts.SrPublish
+				c.Rollup = testRollup
+				c.Commitment = &Commitment{
+					CompressedState: "srs125a1JFijrjjAQZRkZzGdqsgrYymkgag5Gdm9KNrmJTCDUGcBuP",
+					InboxLevel:      4123456,
+					Predecessor:     "src12zNmSKaZy64Y54auQWQqd9umYyN1DtC97mvda1NNhYqcdG9D8h",
+					NumberOfTicks:   "880000000000",
+				}
+			},
+		}, {
+			name: "smart rollup timeout",
+			fill: func(c *Content) {
+				c.Kind = consts.SrTimeout
+				c.Rollup = testRollup
+				c.Stakers = &Stakers{Alice: testSource, Bob: "tz2KCvBrU8T2ZF3wmcNRMGmQy3Wv9fNq1vBH"}
+			},
+		}, {
+			name: "smart rollup execute outbox message",
+			fill: func(c *Content) {
+				c.Kind = consts.SrExecuteOutboxMessage
+				c.Rollup = testRollup
+				c.CementedCommitment = "src12zNmSKaZy64Y54auQWQqd9umYyN1DtC97mvda1NNhYqcdG9D8h"
+				c.OutputProof = "030405"
+			},
+		}, {
+			name: "smart rollup recover bond",
+			fill: func(c *Content) {
+				c.Kind = consts.SrRecoverBond
+				c.Rollup = testRollup
+				c.Staker = testSource
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			content := manager
+			tt.fill(&content)
+			operation := Operation{
+				Branch:    testBranch,
+				Contents:  []Content{content},
+				Signature: testSignature,
+			}
+
+			data, err := Forge(operation)
+			require.NoError(t, err)
+
+			got, err := Unforge(data)
+			require.NoError(t, err)
+			require.Equal(t, operation, got)
+		})
+	}
+}
+
+func TestForge_Errors(t *testing.T) {
+	operation := testOperation()
+	operation.Contents[0].Kind = "unknown"
+	_, err := Forge(operation)
+	require.ErrorIs(t, err, ErrUnknownKind)
+
+	operation = testOperation()
+	operation.Contents[0].Kind = consts.SrRefute
+	_, err = Forge(operation)
+	require.ErrorIs(t, err, ErrUnsupportedKind)
+
+	operation = testOperation()
+	operation.Contents[0].Fee = "-1"
+	_, err = Forge(operation)
+	require.Error(t, err)
+}
+
+func TestUnforge_Errors(t *testing.T) {
+	data, err := Forge(testOperation())
+	require.NoError(t, err)
+
+	data[branchLength] = tagSrRefute
+	_, err = Unforge(data)
+	require.ErrorIs(t, err, ErrUnsupportedKind)
+
+	data[branchLength] = 255
+	_, err = Unforge(data)
+	require.ErrorIs(t, err, ErrUnknownKind)
+}
+
+func make64(b byte) []byte {
+	data := make([]byte, signatureLength)
+	for i := range data {
+		data[i] = b
+	}
+	return data
+}
@@ -0,0 +1,434 @@
+package operations
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"strconv"
+
+	"github.com/baking-bad/bcdhub/internal/bcd/base"
+	"github.com/baking-bad/bcdhub/internal/bcd/encoding"
+	"github.com/baking-bad/bcdhub/internal/bcd/forge"
+	"github.com/pkg/errors"
+)
+
+// Unforge - decodes operation group from bytes. Bytes may be signed or not: if they can't be decoded as unsigned operation, the last 64 bytes are treated as signature.
+func Unforge(data []byte) (Operation, error) {
+	operation, err := unforge(data, false)
+	if err == nil {
+		return operation, nil
+	}
+	if len(data) < branchLength+signatureLength {
+		return operation, err
+	}
+	if signed, signedErr := unforge(data, true); signedErr == nil {
+		return signed, nil
+	}
+	return operation, err
+}
+
+// UnforgeString - decodes operation group from hex string
+func UnforgeString(str string) (Operation, error) {
+	data, err := hex.DecodeString(str)
+	if err != nil {
+		return Operation{}, err
+	}
+	return Unforge(data)
+}
+
+func unforge(data []byte, signed bool) (operation Operation, err error) {
+	if signed {
+		signature, err := forge.UnforgeSignature(hex.EncodeToString(data[len(data)-signatureLength:]))
+		if err != nil {
+			return operation, err
+		}
+		operation.Signature = signature
+		data = data[:len(data)-signatureLength]
+	}
+
+	d := &decoder{data: data}
+	branch, err := d.bytes(branchLength)
+	if err != nil {
+		return operation, err
+	}
+	if operation.Branch, err = encoding.EncodeBase58(branch, []byte(encoding.PrefixBlockHash)); err != nil {
+		return operation, err
+	}
+
+	for !d.empty() {
+		content, err := d.content()
+		if err != nil {
+			return operation, errors.Wrapf(err, "content %d", len(operation.Contents))
+		}
+		operation.Contents = append(operation.Contents, content)
+	}
+	if len(operation.Contents) == 0 {
+		return operation, errors.Wrap(ErrInvalidData, "empty operation contents")
+	}
+	return operation, nil
+}
+
+type decoder struct {
+	data []byte
+	pos  int
+}
+
+func (d *decoder) empty() bool {
+	return d.pos >= len(d.data)
+}
+
+func (d *decoder) bytes(n int) ([]byte, error) {
+	if n < 0 || d.pos+n > len(d.data) {
+		return nil, errors.Wrap(forge.ErrTooFewBytes, fmt.Sprintf("need %d bytes at %d", n, d.pos))
+	}
+	result := d.data[d.pos : d.pos+n]
+	d.pos += n
+	return result, nil
+}
+
+func (d *decoder) byte() (byte, error) {
+	data, err := d.bytes(1)
+	if err != nil {
+		return 0, err
+	}
+	return data[0], nil
+}
+
+func (d *decoder) bool() (bool, error) {
+	b, err := d.byte()
+	if err != nil {
+		return false, err
+	}
+	switch b {
+	case 0:
+		return false, nil
+	case optionalFieldIsPresentByte:
+		return true, nil
+	default:
+		return false, errors.Wrapf(ErrInvalidData, "invalid boolean byte %x", b)
+	}
+}
+
+// dynamic - reads bytes with 4 bytes length prefix
+func (d *decoder) dynamic() ([]byte, error) {
+	prefix, err := d.bytes(4)
+	if err != nil {
+		return nil, err
+	}
+	return d.bytes(int(binary.BigEndian.Uint32(prefix)))
+}
+
+// nat - reads zarith natural number
+func (d *decoder) nat() (string, error) {
+	value := new(big.Int)
+	var shift uint
+	for {
+		b, err := d.byte()
+		if err != nil {
+			return "", err
+		}
+		part := big.NewInt(int64(b & 0x7f))
+		value.Or(value, part.Lsh(part, shift))
+		if b < 0x80 {
+			if b == 0 && shift > 0 {
+				return "", errors.Wrap(ErrInvalidData, "trailing zero in natural number")
+			}
+			return value.String(), nil
+		}
+		shift += 7
+	}
+}
+
+func (d *decoder) publicKeyHash() (string, error) {
+	data, err := d.bytes(publicKeyHashLength)
+	if err != nil {
+		return "", err
+	}
+	return forge.UnforgeAddress(hex.EncodeToString(data))
+}
+
+func (d *decoder) optionalPublicKeyHash() (string, error) {
+	exists, err := d.bool()
+	if err != nil || !exists {
+		return "", err
+	}
+	return d.publicKeyHash()
+}
+
+func (d *decoder) contractID() (string, error) {
+	data, err := d.bytes(contractIDLength)
+	if err != nil {
+		return "", err
+	}
+	return forge.UnforgeAddress(hex.EncodeToString(data))
+}
+
+func (d *decoder) hash(length int, prefix string) (string, error) {
+	data, err := d.bytes(length)
+	if err != nil {
+		return "", err
+	}
+	return encoding.EncodeBase58(data, []byte(prefix))
+}
+
+func (d *decoder) micheline() (*base.Node, error) {
+	data, err := d.dynamic()
+	if err != nil {
+		return nil, err
+	}
+	unforger := forge.NewMichelson()
+	n, err := unforger.Unforge(data)
+	if err != nil {
+		return nil, err
+	}
+	if int(n) != len(data) || len(unforger.Nodes) != 1 {
+		return nil, errors.Wrap(ErrInvalidData, "invalid micheline expression")
+	}
+	return unforger.Nodes[0], nil
+}
+
+func (d *decoder) publicKey() (string, error) {
+	tag, err := d.byte()
+	if err != nil {
+		return "", err
+	}
+	length := 33
+	if tag == 0 {
+		length = 32
+	}
+	key, err := d.bytes(length)
+	if err != nil {
+		return "", err
+	}
+	result, err := forge.UnforgePublicKey(hex.EncodeToString(append([]byte{tag}, key...)))
+	if err != nil {
+		return "", err
+	}
+	if result == "" || tag > 2 {
+		return "", errors.Wrapf(ErrInvalidData, "unknown public key tag %d", tag)
+	}
+	return result, nil
+}
+
+func (d *decoder) parameters() (*Parameters, error) {
+	exists, err := d.bool()
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, nil
+	}
+
+	var parameters Parameters
+	tag, err := d.byte()
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case int(tag) < len(entrypointTags):
+		parameters.Entrypoint = entrypointTags[tag]
+	case tag == namedEntrypointTag:
+		length, err := d.byte()
+		if err != nil {
+			return nil, err
+		}
+		name, err := d.bytes(int(length))
+		if err != nil {
+			return nil, err
+		}
+		parameters.Entrypoint = string(name)
+	default:
+		return nil, errors.Wrapf(ErrInvalidData, "unknown entrypoint tag %d", tag)
+	}
+
+	if parameters.Value, err = d.micheline(); err != nil {
+		return nil, err
+	}
+	return &parameters, nil
+}
+
+func (d *decoder) content() (content Content, err error) {
+	tag, err := d.byte()
+	if err != nil {
+		return
+	}
+	kind, ok := kindByTag(tag)
+	if !ok {
+		return content, errors.Wrapf(ErrUnknownKind, "tag %d", tag)
+	}
+	if tag == tagSrRefute {
+		return content, errors.Wrap(ErrUnsupportedKind, kind)
+	}
+	content.Kind = kind
+
+	if content.Source, err = d.publicKeyHash(); err != nil {
+		return
+	}
+	for _, field := range []*string{&content.Fee, &content.Counter, &content.GasLimit, &content.StorageLimit} {
+		if *field, err = d.nat(); err != nil {
+			return
+		}
+	}
+
+	switch tag {
+	case tagReveal:
+		content.PublicKey, err = d.publicKey()
+
+	case tagTransaction:
+		if content.Amount, err = d.nat(); err != nil {
+			return
+		}
+		if content.Destination, err = d.contractID(); err != nil {
+			return
+		}
+		content.Parameters, err = d.parameters()
+
+	case tagOrigination:
+		if content.Balance, err = d.nat(); err != nil {
+			return
+		}
+		if content.Delegate, err = d.optionalPublicKeyHash(); err != nil {
+			return
+		}
+		content.Script = new(Script)
+		if content.Script.Code, err = d.micheline(); err != nil {
+			return
+		}
+		content.Script.Storage, err = d.micheline()
+
+	case tagDelegation:
+		content.Delegate, err = d.optionalPublicKeyHash()
+
+	case tagRegisterGlobalConstant:
+		content.Value, err = d.micheline()
+
+	case tagTransferTicket:
+		if content.TicketContents, err = d.micheline(); err != nil {
+			return
+		}
+		if content.TicketType, err = d.micheline(); err != nil {
+			return
+		}
+		if content.TicketTicketer, err = d.contractID(); err != nil {
+			return
+		}
+		if content.TicketAmount, err = d.nat(); err != nil {
+			return
+		}
+		if content.Destination, err = d.contractID(); err != nil {
+			return
+		}
+		var entrypoint []byte
+		if entrypoint, err = d.dynamic(); err != nil {
+			return
+		}
+		content.Entrypoint = string(entrypoint)
+
+	case tagSrOriginate:
+		var pvm byte
+		if pvm, err = d.byte(); err != nil {
+			return
+		}
+		if int(pvm) >= len(pvmKinds) {
+			return content, errors.Wrapf(ErrInvalidData, "unknown pvm kind %d", pvm)
+		}
+		content.PvmKind = pvmKinds[pvm]
+
+		var kernel []byte
+		if kernel, err = d.dynamic(); err != nil {
+			return
+		}
+		content.Kernel = hex.EncodeToString(kernel)
+		if content.ParametersType, err = d.micheline(); err != nil {
+			return
+		}
+
+		var exists bool
+		if exists, err = d.bool(); err != nil || !exists {
+			return
+		}
+		var whitelist []byte
+		if whitelist, err = d.dynamic(); err != nil {
+			return
+		}
+		list := &decoder{data: whitelist}
+		for !list.empty() {
+			var address string
+			if address, err = list.publicKeyHash(); err != nil {
+				return
+			}
+			content.Whitelist = append(content.Whitelist, address)
+		}
+
+	case tagSrAddMessages:
+		var messages []byte
+		if messages, err = d.dynamic(); err != nil {
+			return
+		}
+		list := &decoder{data: messages}
+		content.Message = make([]string, 0)
+		for !list.empty() {
+			var message []byte
+			if message, err = list.dynamic(); err != nil {
+				return
+			}
+			content.Message = append(content.Message, hex.EncodeToString(message))
+		}
+
+	case tagSrCement:
+		content.Rollup, err = d.hash(rollupAddressLength, encoding.PrefixOriginatedSmartRollup)
+
+	case tagSrPublish:
+		if content.Rollup, err = d.hash(rollupAddressLength, encoding.PrefixOriginatedSmartRollup); err != nil {
+			return
+		}
+		content.Commitment = new(Commitment)
+		if content.Commitment.CompressedState, err = d.hash(hashLength, encoding.PrefixSmartRollupState); err != nil {
+			return
+		}
+		var level []byte
+		if level, err = d.bytes(4); err != nil {
+			return
+		}
+		content.Commitment.InboxLevel = int32(binary.BigEndian.Uint32(level)) // #nosec G115 -- inbox level is encoded as int32
+		if content.Commitment.Predecessor, err = d.hash(hashLength, encoding.PrefixSmartRollupCommitment); err != nil {
+			return
+		}
+		var ticks []byte
+		if ticks, err = d.bytes(8); err != nil {
+			return
+		}
+		content.Commitment.NumberOfTicks = strconv.FormatInt(int64(binary.BigEndian.Uint64(ticks)), 10) // #nosec G115 -- number of ticks is encoded as int64
+
+	case tagSrTimeout:
+		if content.Rollup, err = d.hash(rollupAddressLength, encoding.PrefixOriginatedSmartRollup); err != nil {
+			return
+		}
+		content.Stakers = new(Stakers)
+		if content.Stakers.Alice, err = d.publicKeyHash(); err != nil {
+			return
+		}
+		content.Stakers.Bob, err = d.publicKeyHash()
+
+	case tagSrExecuteOutboxMessage:
+		if content.Rollup, err = d.hash(rollupAddressLength, encoding.PrefixOriginatedSmartRollup); err != nil {
+			return
+		}
+		if content.CementedCommitment, err = d.hash(hashLength, encoding.PrefixSmartRollupCommitment); err != nil {
+			return
+		}
+		var proof []byte
+		if proof, err = d.dynamic(); err != nil {
+			return
+		}
+		content.OutputProof = hex.EncodeToString(proof)
+
+	case tagSrRecoverBond:
+		if content.Rollup, err = d.hash(rollupAddressLength, encoding.PrefixOriginatedSmartRollup); err != nil {
+			return
+		}
+		content.Staker, err = d.publicKeyHash()
+	}
+	return
+}
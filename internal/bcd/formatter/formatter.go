@@ -12,6 +12,9 @@ import (
 // DefLineSize -
 const DefLineSize = 88
 
+// ExpansionKey - key of marked expansion of global constant in Micheline. Marked expansion is a `constant` primitive with expanded code under the key: `{"prim":"constant","args":[{"string":"expr..."}],"expansion":...}`. It's formatted as expanded code preceded by comment with constant hash.
+const ExpansionKey = "expansion"
+
 // IsFramed -
 func IsFramed(n gjson.Result) bool {
 	prim := n.Get("prim").String()
@@ -66,28 +69,34 @@ func MichelineToMichelsonInline(str string) (string, error) {
 }
 
 // MichelineStringToMichelson -
-func MichelineStringToMichelson(str string, inline bool, lineSize int) (string, error) {
-	return MichelineToMichelson(gjson.Parse(str), inline, lineSize)
+func MichelineStringToMichelson(str string, inline bool, lineSize int, opts ...Option) (string, error) {
+	return MichelineToMichelson(gjson.Parse(str), inline, lineSize, opts...)
 }
 
 // MichelineToMichelson -
-func MichelineToMichelson(n gjson.Result, inline bool, lineSize int) (string, error) {
-	return formatNode(n, "", inline, true, false, lineSize)
+func MichelineToMichelson(n gjson.Result, inline bool, lineSize int, opts ...Option) (string, error) {
+	o := options{
+		lineSize: lineSize,
+	}
+	for i := range opts {
+		opts[i](&o)
+	}
+	return formatNode(n, "", inline, true, false, o)
 }
 
-func formatNode(node gjson.Result, indent string, inline, isRoot, wrapped bool, lineSize int) (string, error) {
+func formatNode(node gjson.Result, indent string, inline, isRoot, wrapped bool, opts options) (string, error) {
 	if node.IsArray() {
-		return formatArray(node, indent, inline, isRoot, lineSize)
+		return formatArray(node, indent, inline, isRoot, opts)
 	}
 
 	if node.IsObject() {
-		return formatObject(node, indent, inline, isRoot, wrapped, lineSize)
+		return formatObject(node, indent, inline, isRoot, wrapped, opts)
 	}
 
 	return "", errors.Errorf("data is not array or object %v", node)
 }
 
-func formatArray(node gjson.Result, indent string, inline, isRoot bool, lineSize int) (string, error) {
+func formatArray(node gjson.Result, indent string, inline, isRoot bool, opts options) (string, error) {
 	seqIndent := indent
 	isScriptRoot := isRoot && IsScript(node)
 	if !isScriptRoot {
@@ -97,7 +106,12 @@ func formatArray(node gjson.Result, indent string, inline, isRoot bool, lineSize
 	items := make([]string, len(node.Array()))
 
 	for i, n := range node.Array() {
-		res, err := formatNode(n, seqIndent, inline, false, true, lineSize)
+		if opts.macros {
+			if macro, ok := foldMacro(n); ok {
+				n = macro
+			}
+		}
+		res, err := formatNode(n, seqIndent, inline, false, true, opts)
 		if err != nil {
 			return "", err
 		}
@@ -120,7 +134,7 @@ func formatArray(node gjson.Result, indent string, inline, isRoot bool, lineSize
 
 	var seq string
 
-	if inline || length < lineSize {
+	if inline || length < opts.lineSize {
 		seq = strings.Join(items, fmt.Sprintf("%v; ", space))
 	} else {
 		seq = strings.Join(items, fmt.Sprintf("%v;\n%v", space, seqIndent))
@@ -133,15 +147,18 @@ func formatArray(node gjson.Result, indent string, inline, isRoot bool, lineSize
 	return seq, nil
 }
 
-func formatObject(node gjson.Result, indent string, inline, isRoot, wrapped bool, lineSize int) (string, error) {
+func formatObject(node gjson.Result, indent string, inline, isRoot, wrapped bool, opts options) (string, error) {
+	if expansion := node.Get(ExpansionKey); expansion.Exists() {
+		return formatExpansion(node, expansion, indent, inline, isRoot, wrapped, opts)
+	}
 	if node.Get("prim").Exists() {
-		return formatPrimObject(node, indent, inline, isRoot, wrapped, lineSize)
+		return formatPrimObject(node, indent, inline, isRoot, wrapped, opts)
 	}
 
 	return formatNonPrimObject(node)
 }
 
-func formatPrimObject(node gjson.Result, indent string, inline, isRoot, wrapped bool, lineSize int) (string, error) {
+func formatPrimObject(node gjson.Result, indent string, inline, isRoot, wrapped bool, opts options) (string, error) {
 	res := []string{node.Get("prim").String()}
 
 	if annots := node.Get("annots"); annots.Exists() {
@@ -162,7 +179,7 @@ func formatPrimObject(node gjson.Result, indent string, inline, isRoot, wrapped
 		argIndent := indent + "  "
 		items := make([]string, len(args))
 		for i, a := range args {
-			res, err := formatNode(a, argIndent, inline, false, false, lineSize)
+			res, err := formatNode(a, argIndent, inline, false, false, opts)
 			if err != nil {
 				return "", err
 			}
@@ -176,7 +193,7 @@ func formatPrimObject(node gjson.Result, indent string, inline, isRoot, wrapped
 			length += len(item)
 		}
 
-		if inline || length < lineSize {
+		if inline || length < opts.lineSize {
 			expr = fmt.Sprintf("%v %v", expr, strings.Join(items, " "))
 		} else {
 			res := make([]string, 0, len(items)+1)
@@ -186,7 +203,7 @@ func formatPrimObject(node gjson.Result, indent string, inline, isRoot, wrapped
 		}
 	case len(args) == 1:
 		argIndent := indent + strings.Repeat(" ", len(expr)+1)
-		res, err := formatNode(args[0], argIndent, inline, false, false, lineSize)
+		res, err := formatNode(args[0], argIndent, inline, false, false, opts)
 		if err != nil {
 			return "", err
 		}
@@ -196,12 +213,12 @@ func formatPrimObject(node gjson.Result, indent string, inline, isRoot, wrapped
 		altIndent := indent + strings.Repeat(" ", len(expr)+2)
 
 		for _, arg := range args {
-			item, err := formatNode(arg, argIndent, inline, false, false, lineSize)
+			item, err := formatNode(arg, argIndent, inline, false, false, opts)
 			if err != nil {
 				return "", err
 			}
 			length := len(indent) + len(expr) + len(item) + 1
-			if inline || IsInline(node) || length < lineSize {
+			if inline || IsInline(node) || length < opts.lineSize {
 				argIndent = altIndent
 				expr = fmt.Sprintf("%v %v", expr, item)
 			} else {
@@ -216,6 +233,19 @@ func formatPrimObject(node gjson.Result, indent string, inline, isRoot, wrapped
 	return expr, nil
 }
 
+func formatExpansion(node, expansion gjson.Result, indent string, inline, isRoot, wrapped bool, opts options) (string, error) {
+	if opts.macros && expansion.IsArray() {
+		if macro, ok := foldMacro(expansion); ok {
+			expansion = macro
+		}
+	}
+	expr, err := formatNode(expansion, indent, inline, isRoot, wrapped, opts)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("/* constant %q */ %s", node.Get("args.0.string").String(), expr), nil
+}
+
 func formatNonPrimObject(node gjson.Result) (string, error) {
 	if len(node.Map()) != 1 {
 		return "", errors.Errorf("node keys count != 1: %v", node)
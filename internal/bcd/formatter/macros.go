@@ -0,0 +1,325 @@
+package formatter
+
+import (
+	"encoding/json"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/tidwall/gjson"
+)
+
+var comparisons = []string{"EQ", "NEQ", "LT", "GT", "LE", "GE"}
+
+// macro - Micheline primitive of folded macro
+type macro struct {
+	Prim   string            `json:"prim"`
+	Args   []json.RawMessage `json:"args,omitempty"`
+	Annots []string          `json:"annots,omitempty"`
+}
+
+func (m macro) node() (gjson.Result, bool) {
+	data, err := json.Marshal(m)
+	if err != nil {
+		return gjson.Result{}, false
+	}
+	return gjson.ParseBytes(data), true
+}
+
+// foldMacro - folds nested sequence which is an expansion of macro back into the macro. Returns false if sequence isn't recognized.
+func foldMacro(node gjson.Result) (gjson.Result, bool) {
+	if !node.IsArray() {
+		return node, false
+	}
+	items := node.Array()
+
+	var m macro
+	switch len(items) {
+	case 0:
+		return node, false
+	case 1:
+		m = foldSingle(items[0])
+	case 2:
+		m = foldDouble(items[0], items[1])
+	case 3:
+		if isPlain(items[0], "COMPARE") && isComparison(items[1]) && isPlainPrim(items[1]) && primOf(items[2]) == "IF" {
+			m = macro{
+				Prim:   "IFCMP" + primOf(items[1]),
+				Args:   rawArgs(items[2]),
+				Annots: annotsOf(items[2]),
+			}
+		}
+	}
+	if m.Prim == "" {
+		m = foldAccess(items)
+	}
+	if m.Prim == "" {
+		m = foldPair(items)
+	}
+	if m.Prim == "" {
+		return node, false
+	}
+	return m.node()
+}
+
+func foldSingle(item gjson.Result) macro {
+	args := argsOf(item)
+	switch primOf(item) {
+	case "DUP":
+		if len(args) == 1 {
+			if n, ok := intOf(args[0]); ok && n > 1 {
+				return macro{Prim: "D" + strings.Repeat("U", n) + "P", Annots: annotsOf(item)}
+			}
+		}
+	case "DIP":
+		if len(args) == 2 && args[1].IsArray() {
+			if n, ok := intOf(args[0]); ok && n > 1 {
+				return macro{Prim: "D" + strings.Repeat("I", n) + "P", Args: []json.RawMessage{json.RawMessage(args[1].Raw)}, Annots: annotsOf(item)}
+			}
+		}
+	case "IF":
+		if len(args) == 2 && len(annotsOf(item)) == 0 && isEmpty(args[0]) && isFailBranch(args[1]) {
+			return macro{Prim: "ASSERT"}
+		}
+	case "IF_NONE":
+		if len(args) != 2 || len(annotsOf(item)) > 0 {
+			break
+		}
+		if isEmpty(args[0]) && isFailBranch(args[1]) {
+			return macro{Prim: "ASSERT_NONE"}
+		}
+		if annots, ok := renamed(args[1]); ok && isFailBranch(args[0]) {
+			return macro{Prim: "ASSERT_SOME", Annots: annots}
+		}
+	case "IF_LEFT":
+		if len(args) != 2 || len(annotsOf(item)) > 0 {
+			break
+		}
+		if annots, ok := renamed(args[0]); ok && isFailBranch(args[1]) {
+			return macro{Prim: "ASSERT_LEFT", Annots: annots}
+		}
+		if annots, ok := renamed(args[1]); ok && isFailBranch(args[0]) {
+			return macro{Prim: "ASSERT_RIGHT", Annots: annots}
+		}
+	}
+	return macro{}
+}
+
+func foldDouble(first, second gjson.Result) macro {
+	switch {
+	case isPlain(first, "UNIT") && isPlain(second, "FAILWITH"):
+		return macro{Prim: "FAIL"}
+	case isPlain(first, "COMPARE") && isComparison(second) && len(argsOf(second)) == 0:
+		return macro{Prim: "CMP" + primOf(second), Annots: annotsOf(second)}
+	case primOf(second) != "IF":
+		return macro{}
+	}
+
+	args := argsOf(second)
+	isAssert := len(args) == 2 && len(annotsOf(second)) == 0 && isEmpty(args[0]) && isFailBranch(args[1])
+	switch {
+	case isComparison(first) && isPlainPrim(first):
+		if isAssert {
+			return macro{Prim: "ASSERT_" + primOf(first)}
+		}
+		return macro{Prim: "IF" + primOf(first), Args: rawArgs(second), Annots: annotsOf(second)}
+	case isAssert:
+		if cmp, ok := foldMacro(first); ok && strings.HasPrefix(cmp.Get("prim").String(), "CMP") && !cmp.Get("annots").Exists() {
+			return macro{Prim: "ASSERT_" + cmp.Get("prim").String()}
+		}
+	}
+	return macro{}
+}
+
+// foldAccess - folds `C[AD]+R` macros
+func foldAccess(items []gjson.Result) macro {
+	if len(items) < 2 {
+		return macro{}
+	}
+	var path strings.Builder
+	for i := range items {
+		if i < len(items)-1 && len(annotsOf(items[i])) > 0 {
+			return macro{}
+		}
+		if len(argsOf(items[i])) > 0 {
+			return macro{}
+		}
+		switch primOf(items[i]) {
+		case "CAR":
+			path.WriteByte('A')
+		case "CDR":
+			path.WriteByte('D')
+		default:
+			return macro{}
+		}
+	}
+	return macro{Prim: "C" + path.String() + "R", Annots: annotsOf(items[len(items)-1])}
+}
+
+// foldPair - folds `P[PAI]+R` and `UNP[PAI]+R` macros without annotations
+func foldPair(items []gjson.Result) macro {
+	if tree, ok := pairTree(items); ok && (tree.left != nil || tree.right != nil) {
+		return macro{Prim: tree.name() + "R"}
+	}
+	if tree, ok := unpairTree(items); ok && (tree.left != nil || tree.right != nil) {
+		return macro{Prim: "UN" + tree.name() + "R"}
+	}
+	return macro{}
+}
+
+type pairNode struct {
+	left, right *pairNode
+}
+
+func (node *pairNode) name() string {
+	left, right := "A", "I"
+	if node.left != nil {
+		left = node.left.name()
+	}
+	if node.right != nil {
+		right = node.right.name()
+	}
+	return "P" + left + right
+}
+
+// pairTree - recognizes `(left)R ; DIP { (right)R } ; PAIR`
+func pairTree(items []gjson.Result) (*pairNode, bool) {
+	if len(items) == 0 || !isPlain(items[len(items)-1], "PAIR") {
+		return nil, false
+	}
+	items = items[:len(items)-1]
+
+	var node pairNode
+	if len(items) > 0 {
+		if body, ok := dipBody(items[len(items)-1]); ok {
+			right, ok := pairTree(body)
+			if !ok {
+				return nil, false
+			}
+			node.right = right
+			items = items[:len(items)-1]
+		}
+	}
+	if len(items) > 0 {
+		left, ok := pairTree(items)
+		if !ok {
+			return nil, false
+		}
+		node.left = left
+	}
+	return &node, true
+}
+
+// unpairTree - recognizes `UNPAIR ; DIP { UN(right)R } ; UN(left)R`
+func unpairTree(items []gjson.Result) (*pairNode, bool) {
+	if len(items) == 0 || !isPlain(items[0], "UNPAIR") {
+		return nil, false
+	}
+	items = items[1:]
+
+	var node pairNode
+	if len(items) > 0 {
+		if body, ok := dipBody(items[0]); ok {
+			right, ok := unpairTree(body)
+			if !ok {
+				return nil, false
+			}
+			node.right = right
+			items = items[1:]
+		}
+	}
+	if len(items) > 0 {
+		left, ok := unpairTree(items)
+		if !ok {
+			return nil, false
+		}
+		node.left = left
+	}
+	return &node, true
+}
+
+func dipBody(item gjson.Result) ([]gjson.Result, bool) {
+	args := argsOf(item)
+	if primOf(item) != "DIP" || len(args) != 1 || !args[0].IsArray() || len(annotsOf(item)) > 0 {
+		return nil, false
+	}
+	return args[0].Array(), true
+}
+
+func primOf(node gjson.Result) string {
+	return node.Get("prim").String()
+}
+
+func argsOf(node gjson.Result) []gjson.Result {
+	return node.Get("args").Array()
+}
+
+func annotsOf(node gjson.Result) []string {
+	var annots []string
+	for _, annot := range node.Get("annots").Array() {
+		annots = append(annots, annot.String())
+	}
+	return annots
+}
+
+func rawArgs(node gjson.Result) []json.RawMessage {
+	args := argsOf(node)
+	result := make([]json.RawMessage, len(args))
+	for i := range args {
+		result[i] = json.RawMessage(args[i].Raw)
+	}
+	return result
+}
+
+func intOf(node gjson.Result) (int, bool) {
+	value := node.Get("int")
+	if !value.Exists() {
+		return 0, false
+	}
+	n, err := strconv.Atoi(value.String())
+	return n, err == nil
+}
+
+func isPlainPrim(node gjson.Result) bool {
+	return node.IsObject() && !node.Get("args").Exists() && !node.Get("annots").Exists()
+}
+
+func isPlain(node gjson.Result, prim string) bool {
+	return primOf(node) == prim && isPlainPrim(node)
+}
+
+func isComparison(node gjson.Result) bool {
+	return slices.Contains(comparisons, primOf(node))
+}
+
+func isEmpty(node gjson.Result) bool {
+	return node.IsArray() && len(node.Array()) == 0
+}
+
+// isFailBranch - checks if node is `{ { UNIT ; FAILWITH } }`, i.e. expansion of `{ FAIL }`
+func isFailBranch(node gjson.Result) bool {
+	if !node.IsArray() {
+		return false
+	}
+	items := node.Array()
+	if len(items) != 1 || !items[0].IsArray() {
+		return false
+	}
+	fail := items[0].Array()
+	return len(fail) == 2 && isPlain(fail[0], "UNIT") && isPlain(fail[1], "FAILWITH")
+}
+
+// renamed - checks if node is `{}` or `{ RENAME @annot }` and returns annotations
+func renamed(node gjson.Result) ([]string, bool) {
+	if isEmpty(node) {
+		return nil, true
+	}
+	if !node.IsArray() {
+		return nil, false
+	}
+	items := node.Array()
+	if len(items) != 1 || primOf(items[0]) != "RENAME" || len(argsOf(items[0])) > 0 {
+		return nil, false
+	}
+	return annotsOf(items[0]), true
+}
@@ -0,0 +1,61 @@
+package formatter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+)
+
+func TestMichelineToMichelson_WithMacros(t *testing.T) {
+	tests := []struct {
+		name string
+		code string
+		want string
+	}{
+		{
+			name: "FAIL",
+			code: `[[{"prim":"UNIT"},{"prim":"FAILWITH"}]]`,
+			want: `{ FAIL }`,
+		}, {
+			name: "CADR",
+			code: `[[{"prim":"CAR"},{"prim":"CDR","annots":["@x"]}],{"prim":"CAR"}]`,
+			want: `{ CADR @x ; CAR }`,
+		}, {
+			name: "annotated access isn't folded",
+			code: `[[{"prim":"CAR","annots":["@x"]},{"prim":"CDR"}]]`,
+			want: `{ { CAR @x ; CDR } }`,
+		}, {
+			name: "DUUP and DIIP",
+			code: `[[{"prim":"DUP","args":[{"int":"3"}]}],[{"prim":"DIP","args":[{"int":"2"},[{"prim":"DROP"}]]}],{"prim":"DUP","args":[{"int":"2"}]}]`,
+			want: `{ DUUUP ; DIIP { DROP } ; DUP 2 }`,
+		}, {
+			name: "IFCMPEQ with nested macros",
+			code: `[[{"prim":"COMPARE"},{"prim":"EQ"},{"prim":"IF","args":[[[{"prim":"CDR"},{"prim":"CAR"}]],[[{"prim":"UNIT"},{"prim":"FAILWITH"}]]]}]]`,
+			want: `{ IFCMPEQ { CDAR } { FAIL } }`,
+		}, {
+			name: "CMPLT and IFGT",
+			code: `[[{"prim":"COMPARE"},{"prim":"LT"}],[{"prim":"GT"},{"prim":"IF","args":[[],[{"prim":"UNIT"}]]}]]`,
+			want: `{ CMPLT ; IFGT {} { UNIT } }`,
+		}, {
+			name: "asserts",
+			code: `[[{"prim":"IF","args":[[],[[{"prim":"UNIT"},{"prim":"FAILWITH"}]]]}],[[{"prim":"COMPARE"},{"prim":"LE"}],{"prim":"IF","args":[[],[[{"prim":"UNIT"},{"prim":"FAILWITH"}]]]}],[{"prim":"IF_NONE","args":[[[{"prim":"UNIT"},{"prim":"FAILWITH"}]],[{"prim":"RENAME","annots":["@x"]}]]}]]`,
+			want: `{ ASSERT ; ASSERT_CMPLE ; ASSERT_SOME @x }`,
+		}, {
+			name: "pairs",
+			code: `[[{"prim":"DIP","args":[[{"prim":"PAIR"}]]},{"prim":"PAIR"}],[{"prim":"UNPAIR"},{"prim":"DIP","args":[[{"prim":"UNPAIR"}]]}]]`,
+			want: `{ PAPAIR ; UNPAPAIR }`,
+		}, {
+			name: "unknown sequence",
+			code: `[[{"prim":"SWAP"},{"prim":"DROP"}]]`,
+			want: `{ { SWAP ; DROP } }`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := MichelineToMichelson(gjson.Parse(tt.code), true, DefLineSize, WithMacros())
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,16 @@
+package formatter
+
+type options struct {
+	lineSize int
+	macros   bool
+}
+
+// Option -
+type Option func(*options)
+
+// WithMacros - folds recognisable expansions of macros back into macros, e.g. `{ CAR ; CDR }` is shown as `CADR`
+func WithMacros() Option {
+	return func(o *options) {
+		o.macros = true
+	}
+}
@@ -0,0 +1,466 @@
+package interpreter
+
+import (
+	"math/big"
+
+	"github.com/baking-bad/bcdhub/internal/bcd/base"
+	"github.com/baking-bad/bcdhub/internal/bcd/consts"
+	"github.com/pkg/errors"
+)
+
+// maxShift - maximal shift of `LSL` and `LSR` for naturals
+const maxShift = 256
+
+// maxBytesShift - maximal shift of `LSL` for bytes
+const maxBytesShift = 64000
+
+func isInteger(it item) bool {
+	return it.typ.Prim == consts.INT || it.typ.Prim == consts.NAT
+}
+
+func integers(a, b item) (*big.Int, *big.Int) {
+	return a.val.(*big.Int), b.val.(*big.Int)
+}
+
+// integerType - result type of arithmetic on naturals and integers: nat if both are nat, int otherwise
+func integerType(a, b item) *base.Node {
+	if a.typ.Prim == consts.NAT && b.typ.Prim == consts.NAT {
+		return newType(consts.NAT)
+	}
+	return newType(consts.INT)
+}
+
+func checkMutez(value *big.Int) error {
+	if value.Sign() < 0 {
+		return errors.Wrap(ErrUnderflow, "mutez")
+	}
+	if value.Cmp(maxMutez) > 0 {
+		return errors.Wrap(ErrOverflow, "mutez")
+	}
+	return nil
+}
+
+func (m *machine) popPair(instruction string) (item, item, error) {
+	items, err := m.popN(2)
+	if err != nil {
+		return item{}, item{}, err
+	}
+	for i := range items {
+		if items[i].typ.Prim == consts.BLS12381FR || items[i].typ.Prim == consts.BLS12381G1 || items[i].typ.Prim == consts.BLS12381G2 {
+			return item{}, item{}, errors.Wrapf(ErrUnsupportedType, "%s of %s", instruction, items[i].typ.Prim)
+		}
+	}
+	return items[0], items[1], nil
+}
+
+func invalidOperands(instruction string, a, b item) error {
+	return errors.Wrapf(ErrInvalidStack, "%s of %s and %s", instruction, a.typ.Prim, b.typ.Prim)
+}
+
+func (m *machine) add() error {
+	a, b, err := m.popPair("ADD")
+	if err != nil {
+		return err
+	}
+	switch {
+	case isInteger(a) && isInteger(b):
+		x, y := integers(a, b)
+		m.push(integerType(a, b), new(big.Int).Add(x, y))
+	case a.typ.Prim == consts.TIMESTAMP && b.typ.Prim == consts.INT,
+		a.typ.Prim == consts.INT && b.typ.Prim == consts.TIMESTAMP:
+		x, y := integers(a, b)
+		m.push(newType(consts.TIMESTAMP), new(big.Int).Add(x, y))
+	case a.typ.Prim == consts.MUTEZ && b.typ.Prim == consts.MUTEZ:
+		x, y := integers(a, b)
+		sum := new(big.Int).Add(x, y)
+		if err := checkMutez(sum); err != nil {
+			return err
+		}
+		m.push(newType(consts.MUTEZ), sum)
+	default:
+		return invalidOperands("ADD", a, b)
+	}
+	return nil
+}
+
+func (m *machine) sub() error {
+	a, b, err := m.popPair("SUB")
+	if err != nil {
+		return err
+	}
+	switch {
+	case isInteger(a) && isInteger(b),
+		a.typ.Prim == consts.TIMESTAMP && b.typ.Prim == consts.TIMESTAMP:
+		x, y := integers(a, b)
+		m.push(newType(consts.INT), new(big.Int).Sub(x, y))
+	case a.typ.Prim == consts.TIMESTAMP && b.typ.Prim == consts.INT:
+		x, y := integers(a, b)
+		m.push(newType(consts.TIMESTAMP), new(big.Int).Sub(x, y))
+	default:
+		return invalidOperands("SUB", a, b)
+	}
+	return nil
+}
+
+func (m *machine) subMutez() error {
+	a, b, err := m.popPair("SUB_MUTEZ")
+	if err != nil {
+		return err
+	}
+	if a.typ.Prim != consts.MUTEZ || b.typ.Prim != consts.MUTEZ {
+		return invalidOperands("SUB_MUTEZ", a, b)
+	}
+	x, y := integers(a, b)
+	diff := new(big.Int).Sub(x, y)
+	typ := newType(consts.OPTION, newType(consts.MUTEZ))
+	if diff.Sign() < 0 {
+		m.push(typ, none())
+	} else {
+		m.push(typ, some(diff))
+	}
+	return nil
+}
+
+func (m *machine) mul() error {
+	a, b, err := m.popPair("MUL")
+	if err != nil {
+		return err
+	}
+	switch {
+	case isInteger(a) && isInteger(b):
+		x, y := integers(a, b)
+		m.push(integerType(a, b), new(big.Int).Mul(x, y))
+	case a.typ.Prim == consts.MUTEZ && b.typ.Prim == consts.NAT,
+		a.typ.Prim == consts.NAT && b.typ.Prim == consts.MUTEZ:
+		x, y := integers(a, b)
+		product := new(big.Int).Mul(x, y)
+		if err := checkMutez(product); err != nil {
+			return err
+		}
+		m.push(newType(consts.MUTEZ), product)
+	default:
+		return invalidOperands("MUL", a, b)
+	}
+	return nil
+}
+
+// ediv - euclidean division: remainder is always non-negative
+func (m *machine) ediv() error {
+	a, b, err := m.popPair("EDIV")
+	if err != nil {
+		return err
+	}
+
+	var quotientType, remainderType *base.Node
+	switch {
+	case isInteger(a) && isInteger(b):
+		quotientType, remainderType = integerType(a, b), newType(consts.NAT)
+	case a.typ.Prim == consts.MUTEZ && b.typ.Prim == consts.NAT:
+		quotientType, remainderType = newType(consts.MUTEZ), newType(consts.MUTEZ)
+	case a.typ.Prim == consts.MUTEZ && b.typ.Prim == consts.MUTEZ:
+		quotientType, remainderType = newType(consts.NAT), newType(consts.MUTEZ)
+	default:
+		return invalidOperands("EDIV", a, b)
+	}
+
+	typ := newType(consts.OPTION, newType(consts.PAIR, quotientType, remainderType))
+	x, y := integers(a, b)
+	if y.Sign() == 0 {
+		m.push(typ, none())
+		return nil
+	}
+	quotient, remainder := new(big.Int).DivMod(x, y, new(big.Int))
+	m.push(typ, some(pairValue{left: quotient, right: remainder}))
+	return nil
+}
+
+func (m *machine) popInteger(instruction string, prims ...string) (item, error) {
+	it, err := m.pop()
+	if err != nil {
+		return item{}, err
+	}
+	if err := expectType(it, prims...); err != nil {
+		return item{}, errors.Wrap(err, instruction)
+	}
+	return it, nil
+}
+
+func (m *machine) abs() error {
+	it, err := m.popInteger("ABS", consts.INT)
+	if err != nil {
+		return err
+	}
+	m.push(newType(consts.NAT), new(big.Int).Abs(it.val.(*big.Int)))
+	return nil
+}
+
+func (m *machine) isNat() error {
+	it, err := m.popInteger("ISNAT", consts.INT)
+	if err != nil {
+		return err
+	}
+	value := it.val.(*big.Int)
+	typ := newType(consts.OPTION, newType(consts.NAT))
+	if value.Sign() < 0 {
+		m.push(typ, none())
+	} else {
+		m.push(typ, some(value))
+	}
+	return nil
+}
+
+func (m *machine) int() error {
+	it, err := m.popInteger("INT", consts.NAT, consts.BYTES)
+	if err != nil {
+		return err
+	}
+	if it.typ.Prim == consts.BYTES {
+		m.push(newType(consts.INT), bytesToInt(it.val.([]byte)))
+	} else {
+		m.push(newType(consts.INT), it.val)
+	}
+	return nil
+}
+
+func (m *machine) nat() error {
+	it, err := m.popInteger("NAT", consts.BYTES)
+	if err != nil {
+		return err
+	}
+	m.push(newType(consts.NAT), new(big.Int).SetBytes(it.val.([]byte)))
+	return nil
+}
+
+func (m *machine) bytes() error {
+	it, err := m.popInteger("BYTES", consts.INT, consts.NAT)
+	if err != nil {
+		return err
+	}
+	m.push(newType(consts.BYTES), intToBytes(it.val.(*big.Int)))
+	return nil
+}
+
+func (m *machine) neg() error {
+	it, err := m.popInteger("NEG", consts.INT, consts.NAT)
+	if err != nil {
+		return err
+	}
+	m.push(newType(consts.INT), new(big.Int).Neg(it.val.(*big.Int)))
+	return nil
+}
+
+// bytesToInt - decodes big-endian two's complement integer
+func bytesToInt(data []byte) *big.Int {
+	value := new(big.Int).SetBytes(data)
+	if len(data) > 0 && data[0]&0x80 != 0 {
+		value.Sub(value, new(big.Int).Lsh(big.NewInt(1), uint(len(data)*8)))
+	}
+	return value
+}
+
+// intToBytes - encodes integer as minimal big-endian two's complement
+func intToBytes(value *big.Int) []byte {
+	switch value.Sign() {
+	case 0:
+		return []byte{}
+	case 1:
+		data := value.Bytes()
+		if data[0]&0x80 != 0 {
+			data = append([]byte{0}, data...)
+		}
+		return data
+	default:
+		abs := new(big.Int).Neg(value)
+		abs.Sub(abs, big.NewInt(1))
+		size := abs.BitLen()/8 + 1
+		complement := new(big.Int).Lsh(big.NewInt(1), uint(size*8))
+		complement.Add(complement, value)
+		return complement.FillBytes(make([]byte, size))
+	}
+}
+
+func (m *machine) shift(left bool) error {
+	instruction := "LSR"
+	if left {
+		instruction = "LSL"
+	}
+	a, b, err := m.popPair(instruction)
+	if err != nil {
+		return err
+	}
+	if b.typ.Prim != consts.NAT {
+		return invalidOperands(instruction, a, b)
+	}
+	shift := b.val.(*big.Int)
+
+	switch a.typ.Prim {
+	case consts.NAT:
+		if shift.Cmp(bigInt(maxShift)) > 0 {
+			return errors.Wrap(ErrOverflow, instruction)
+		}
+		value := a.val.(*big.Int)
+		if left {
+			m.push(a.typ, new(big.Int).Lsh(value, uint(shift.Int64())))
+		} else {
+			m.push(a.typ, new(big.Int).Rsh(value, uint(shift.Int64())))
+		}
+
+	case consts.BYTES:
+		data := a.val.([]byte)
+		value := new(big.Int).SetBytes(data)
+		if left {
+			if shift.Cmp(bigInt(maxBytesShift)) > 0 {
+				return errors.Wrap(ErrOverflow, instruction)
+			}
+			n := int(shift.Int64())
+			value.Lsh(value, uint(n))
+			m.push(a.typ, value.FillBytes(make([]byte, len(data)+(n+7)/8)))
+		} else {
+			if !shift.IsInt64() || shift.Int64() >= int64(len(data)*8) {
+				m.push(a.typ, []byte{})
+				return nil
+			}
+			n := int(shift.Int64())
+			value.Rsh(value, uint(n))
+			m.push(a.typ, value.FillBytes(make([]byte, len(data)-n/8)))
+		}
+
+	default:
+		return invalidOperands(instruction, a, b)
+	}
+	return nil
+}
+
+func (m *machine) logic(instruction string) error {
+	a, b, err := m.popPair(instruction)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case a.typ.Prim == consts.BOOL && b.typ.Prim == consts.BOOL:
+		x, y := a.val.(bool), b.val.(bool)
+		var result bool
+		switch instruction {
+		case "OR":
+			result = x || y
+		case "AND":
+			result = x && y
+		case "XOR":
+			result = x != y
+		}
+		m.push(a.typ, result)
+
+	case a.typ.Prim == consts.NAT && b.typ.Prim == consts.NAT,
+		instruction == "AND" && a.typ.Prim == consts.INT && b.typ.Prim == consts.NAT:
+		x, y := integers(a, b)
+		result := new(big.Int)
+		switch instruction {
+		case "OR":
+			result.Or(x, y)
+		case "AND":
+			result.And(x, y)
+		case "XOR":
+			result.Xor(x, y)
+		}
+		m.push(newType(consts.NAT), result)
+
+	case a.typ.Prim == consts.BYTES && b.typ.Prim == consts.BYTES:
+		m.push(a.typ, bytesLogic(instruction, a.val.([]byte), b.val.([]byte)))
+
+	default:
+		return invalidOperands(instruction, a, b)
+	}
+	return nil
+}
+
+// bytesLogic - bitwise operations on bytes aligned to the right. Result of `AND` has length of shorter operand, others have length of longer one.
+func bytesLogic(instruction string, x, y []byte) []byte {
+	if len(x) < len(y) {
+		x, y = y, x
+	}
+	if instruction == "AND" {
+		result := make([]byte, len(y))
+		offset := len(x) - len(y)
+		for i := range result {
+			result[i] = x[offset+i] & y[i]
+		}
+		return result
+	}
+
+	result := append([]byte(nil), x...)
+	offset := len(x) - len(y)
+	for i := range y {
+		if instruction == "OR" {
+			result[offset+i] |= y[i]
+		} else {
+			result[offset+i] ^= y[i]
+		}
+	}
+	return result
+}
+
+func (m *machine) not() error {
+	it, err := m.pop()
+	if err != nil {
+		return err
+	}
+	switch it.typ.Prim {
+	case consts.BOOL:
+		m.push(it.typ, !it.val.(bool))
+	case consts.INT, consts.NAT:
+		m.push(newType(consts.INT), new(big.Int).Not(it.val.(*big.Int)))
+	case consts.BYTES:
+		data := it.val.([]byte)
+		result := make([]byte, len(data))
+		for i := range data {
+			result[i] = ^data[i]
+		}
+		m.push(it.typ, result)
+	default:
+		return expectType(it, consts.BOOL, consts.INT, consts.NAT, consts.BYTES)
+	}
+	return nil
+}
+
+func (m *machine) compare() error {
+	a, b, err := m.popPair("COMPARE")
+	if err != nil {
+		return err
+	}
+	if !equalTypes(a.typ, b.typ) || !isComparable(a.typ) {
+		return invalidOperands("COMPARE", a, b)
+	}
+	result, err := compareValues(mergeTypes(a.typ, b.typ), a.val, b.val)
+	if err != nil {
+		return err
+	}
+	m.push(newType(consts.INT), bigInt(int64(result)))
+	return nil
+}
+
+func (m *machine) compareResult(instruction string) error {
+	it, err := m.popInteger(instruction, consts.INT)
+	if err != nil {
+		return err
+	}
+	sign := it.val.(*big.Int).Sign()
+
+	var result bool
+	switch instruction {
+	case "EQ":
+		result = sign == 0
+	case "NEQ":
+		result = sign != 0
+	case "LT":
+		result = sign < 0
+	case "GT":
+		result = sign > 0
+	case "LE":
+		result = sign <= 0
+	case "GE":
+		result = sign >= 0
+	}
+	m.push(newType(consts.BOOL), result)
+	return nil
+}
@@ -0,0 +1,331 @@
+package interpreter
+
+import (
+	"sort"
+
+	"github.com/baking-bad/bcdhub/internal/bcd/base"
+	"github.com/baking-bad/bcdhub/internal/bcd/consts"
+)
+
+// bigMapValue - big map is loaded lazily: values of keys which aren't changed during execution are requested from chain.
+// Changed keys are kept in `entries` by key hash. Big map is immutable, so entries are copied on update.
+type bigMapValue struct {
+	ptr       *int64
+	final     *int64
+	keyType   *base.Node
+	valueType *base.Node
+	entries   map[string]bigMapEntry
+}
+
+// bigMapEntry - changed key of big map. Value is nil if key is removed.
+type bigMapEntry struct {
+	key   Value
+	value Value
+}
+
+func newBigMap(keyType, valueType *base.Node) *bigMapValue {
+	return &bigMapValue{
+		keyType:   keyType,
+		valueType: valueType,
+		entries:   make(map[string]bigMapEntry),
+	}
+}
+
+func newBigMapFromMap(keyType, valueType *base.Node, m *mapValue) (*bigMapValue, error) {
+	bm := newBigMap(keyType, valueType)
+	for i := range m.keys {
+		hash, err := keyHash(keyType, m.keys[i])
+		if err != nil {
+			return nil, err
+		}
+		bm.entries[hash] = bigMapEntry{key: m.keys[i], value: m.values[i]}
+	}
+	return bm, nil
+}
+
+func (bm *bigMapValue) get(s *session, key Value) (Value, bool, error) {
+	hash, err := keyHash(bm.keyType, key)
+	if err != nil {
+		return nil, false, err
+	}
+	if entry, ok := bm.entries[hash]; ok {
+		return entry.value, entry.value != nil, nil
+	}
+	if bm.ptr == nil {
+		return nil, false, nil
+	}
+
+	node, err := s.bigMapValue(*bm.ptr, hash)
+	if err != nil || node == nil {
+		return nil, false, err
+	}
+	value, err := parseValue(bm.valueType, node)
+	if err != nil {
+		return nil, false, err
+	}
+	return value, true, nil
+}
+
+// update - returns big map with updated key. Key is removed if `value` is nil.
+func (bm *bigMapValue) update(key, value Value) (*bigMapValue, error) {
+	hash, err := keyHash(bm.keyType, key)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &bigMapValue{
+		ptr:       bm.ptr,
+		keyType:   bm.keyType,
+		valueType: bm.valueType,
+		entries:   make(map[string]bigMapEntry, len(bm.entries)+1),
+	}
+	for k, v := range bm.entries {
+		result.entries[k] = v
+	}
+	if value == nil && bm.ptr == nil {
+		delete(result.entries, hash)
+	} else {
+		result.entries[hash] = bigMapEntry{key: key, value: value}
+	}
+	return result, nil
+}
+
+func (bm *bigMapValue) unparse(optimized bool) (*base.Node, error) {
+	switch {
+	case bm.final != nil:
+		return intNode(bigInt(*bm.final)), nil
+	case bm.ptr != nil:
+		return intNode(bigInt(*bm.ptr)), nil
+	}
+
+	keys := make([]Value, 0, len(bm.entries))
+	values := make([]Value, 0, len(bm.entries))
+	for _, hash := range sortedKeys(bm.entries) {
+		if entry := bm.entries[hash]; entry.value != nil {
+			keys = append(keys, entry.key)
+			values = append(values, entry.value)
+		}
+	}
+	var sortErr error
+	cmp := comparator(bm.keyType)
+	sort.Sort(&keyValueSorter{keys: keys, values: values, less: func(a, b Value) bool {
+		res, err := cmp(a, b)
+		if err != nil {
+			sortErr = err
+		}
+		return res < 0
+	}})
+	if sortErr != nil {
+		return nil, sortErr
+	}
+	return unparseMap(bm.keyType, bm.valueType, keys, values, optimized)
+}
+
+// updates - returns changes of big map sorted by key hash. Removed keys are skipped if `withRemoved` is false.
+func (bm *bigMapValue) updates(withRemoved bool) ([]BigMapUpdate, error) {
+	updates := make([]BigMapUpdate, 0, len(bm.entries))
+	for _, hash := range sortedKeys(bm.entries) {
+		entry := bm.entries[hash]
+		if entry.value == nil && !withRemoved {
+			continue
+		}
+		key, err := unparse(bm.keyType, entry.key, false)
+		if err != nil {
+			return nil, err
+		}
+		update := BigMapUpdate{
+			KeyHash: hash,
+			Key:     key,
+		}
+		if entry.value != nil {
+			update.Value, err = unparse(bm.valueType, entry.value, false)
+			if err != nil {
+				return nil, err
+			}
+		}
+		updates = append(updates, update)
+	}
+	return updates, nil
+}
+
+type keyValueSorter struct {
+	keys   []Value
+	values []Value
+	less   func(a, b Value) bool
+}
+
+func (s *keyValueSorter) Len() int           { return len(s.keys) }
+func (s *keyValueSorter) Less(i, j int) bool { return s.less(s.keys[i], s.keys[j]) }
+func (s *keyValueSorter) Swap(i, j int) {
+	s.keys[i], s.keys[j] = s.keys[j], s.keys[i]
+	s.values[i], s.values[j] = s.values[j], s.values[i]
+}
+
+func hasBigMap(typ *base.Node) bool {
+	if typ.Prim == consts.BIGMAP {
+		return true
+	}
+	if typ.Prim == consts.LAMBDA || typ.Prim == consts.CONTRACT {
+		return false
+	}
+	for i := range typ.Args {
+		if hasBigMap(typ.Args[i]) {
+			return true
+		}
+	}
+	return false
+}
+
+// walkBigMaps - calls `handler` for each big map in value and returns value where big maps are replaced by results of `handler`
+func walkBigMaps(typ *base.Node, value Value, handler func(*bigMapValue) (*bigMapValue, error)) (Value, error) {
+	if !hasBigMap(typ) {
+		return value, nil
+	}
+
+	switch typ.Prim {
+	case consts.BIGMAP:
+		return handler(value.(*bigMapValue))
+
+	case consts.PAIR:
+		pair := value.(pairValue)
+		left, err := walkBigMaps(typ.Args[0], pair.left, handler)
+		if err != nil {
+			return nil, err
+		}
+		right, err := walkBigMaps(typ.Args[1], pair.right, handler)
+		if err != nil {
+			return nil, err
+		}
+		return pairValue{left: left, right: right}, nil
+
+	case consts.OPTION:
+		option := value.(optionValue)
+		if !option.some {
+			return option, nil
+		}
+		inner, err := walkBigMaps(typ.Args[0], option.value, handler)
+		if err != nil {
+			return nil, err
+		}
+		return some(inner), nil
+
+	case consts.OR:
+		or := value.(orValue)
+		argType := typ.Args[0]
+		if or.right {
+			argType = typ.Args[1]
+		}
+		inner, err := walkBigMaps(argType, or.value, handler)
+		if err != nil {
+			return nil, err
+		}
+		return orValue{right: or.right, value: inner}, nil
+
+	case consts.LIST:
+		list := value.(listValue)
+		result := make(listValue, len(list))
+		for i := range list {
+			inner, err := walkBigMaps(typ.Args[0], list[i], handler)
+			if err != nil {
+				return nil, err
+			}
+			result[i] = inner
+		}
+		return result, nil
+
+	case consts.MAP:
+		m := value.(*mapValue)
+		result := &mapValue{
+			keys:   m.keys,
+			values: make([]Value, len(m.values)),
+		}
+		for i := range m.values {
+			inner, err := walkBigMaps(typ.Args[1], m.values[i], handler)
+			if err != nil {
+				return nil, err
+			}
+			result.values[i] = inner
+		}
+		return result, nil
+	}
+
+	return value, nil
+}
+
+// finalizeBigMaps - assigns pointers to big maps of result storage and returns their diffs.
+// Big map of initial storage is updated if it's used once, otherwise it's copied. New big maps are allocated with temporary negative pointers.
+// Big maps of initial storage which aren't used in result storage are removed.
+func (s *session) finalizeBigMaps(typ *base.Node, initial, result Value) (Value, []BigMapDiff, error) {
+	initialPtrs := make(map[int64]struct{})
+	if _, err := walkBigMaps(typ, initial, func(bm *bigMapValue) (*bigMapValue, error) {
+		if bm.ptr != nil {
+			initialPtrs[*bm.ptr] = struct{}{}
+		}
+		return bm, nil
+	}); err != nil {
+		return nil, nil, err
+	}
+
+	var diffs []BigMapDiff
+	used := make(map[int64]struct{})
+	result, err := walkBigMaps(typ, result, func(bm *bigMapValue) (*bigMapValue, error) {
+		final := *bm
+		diff := BigMapDiff{}
+
+		_, isInitial := initialPtrs[ptrValue(bm.ptr)]
+		_, isUsed := used[ptrValue(bm.ptr)]
+		var err error
+		switch {
+		case bm.ptr != nil && isInitial && !isUsed:
+			used[*bm.ptr] = struct{}{}
+			diff.Ptr = *bm.ptr
+			diff.Action = BigMapActionUpdate
+			diff.Updates, err = bm.updates(true)
+		case bm.ptr != nil:
+			diff.Ptr = s.newBigMapID()
+			diff.Action = BigMapActionCopy
+			diff.SourcePtr = bm.ptr
+			diff.Updates, err = bm.updates(true)
+		default:
+			diff.Ptr = s.newBigMapID()
+			diff.Action = BigMapActionAlloc
+			diff.KeyType = bm.keyType
+			diff.ValueType = bm.valueType
+			diff.Updates, err = bm.updates(false)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		final.final = &diff.Ptr
+		if diff.Action != BigMapActionUpdate || len(diff.Updates) > 0 {
+			diffs = append(diffs, diff)
+		}
+		return &final, nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	removed := make([]int64, 0)
+	for ptr := range initialPtrs {
+		if _, ok := used[ptr]; !ok {
+			removed = append(removed, ptr)
+		}
+	}
+	sort.Slice(removed, func(i, j int) bool { return removed[i] < removed[j] })
+	for _, ptr := range removed {
+		diffs = append(diffs, BigMapDiff{
+			Ptr:    ptr,
+			Action: BigMapActionRemove,
+		})
+	}
+	return result, diffs, nil
+}
+
+func ptrValue(ptr *int64) int64 {
+	if ptr == nil {
+		return 0
+	}
+	return *ptr
+}
@@ -0,0 +1,530 @@
+package interpreter
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/binary"
+	"math/big"
+	"strings"
+
+	"github.com/baking-bad/bcdhub/internal/bcd/ast"
+	"github.com/baking-bad/bcdhub/internal/bcd/base"
+	"github.com/baking-bad/bcdhub/internal/bcd/consts"
+	"github.com/baking-bad/bcdhub/internal/bcd/encoding"
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/blake2b"
+	"golang.org/x/crypto/sha3"
+)
+
+func (m *machine) now() *big.Int {
+	if m.env.Timestamp.IsZero() {
+		return bigInt(0)
+	}
+	return bigInt(m.env.Timestamp.Unix())
+}
+
+func isImplicit(address string) bool {
+	return strings.HasPrefix(address, "tz")
+}
+
+func (m *machine) self(node *base.Node) error {
+	entrypoint := fieldAnnotation(node)
+	if entrypoint == "" {
+		entrypoint = consts.DefaultEntrypoint
+	}
+	_, typ, ok := findEntrypoint(m.parameter, entrypoint)
+	if !ok {
+		return errors.Wrap(ErrUnknownEntrypoint, entrypoint)
+	}
+	parameter, err := m.s.normalize(typ)
+	if err != nil {
+		return err
+	}
+	m.push(newType(consts.CONTRACT, parameter), joinContract(m.env.Self, entrypoint))
+	return nil
+}
+
+func (m *machine) address() error {
+	it, err := m.popInteger("ADDRESS", consts.CONTRACT)
+	if err != nil {
+		return err
+	}
+	m.push(newType(consts.ADDRESS), it.val)
+	return nil
+}
+
+// contract - casts address to contract of given type. Result is `None` if contract doesn't exist or its entrypoint has another type.
+func (m *machine) contract(node *base.Node) error {
+	typ, err := m.typeArg(node, 0)
+	if err != nil {
+		return err
+	}
+	it, err := m.popInteger("CONTRACT", consts.ADDRESS)
+	if err != nil {
+		return err
+	}
+	resultType := newType(consts.OPTION, newType(consts.CONTRACT, typ))
+
+	address, entrypoint := splitContract(it.val.(string))
+	if annotation := fieldAnnotation(node); annotation != "" {
+		if entrypoint != consts.DefaultEntrypoint {
+			m.push(resultType, none())
+			return nil
+		}
+		entrypoint = annotation
+	}
+
+	parameter, err := m.entrypointType(address, entrypoint)
+	if err != nil {
+		return err
+	}
+	if parameter == nil || !equalTypes(parameter, typ) {
+		m.push(resultType, none())
+		return nil
+	}
+	m.push(resultType, some(joinContract(address, entrypoint)))
+	return nil
+}
+
+// entrypointType - returns type of entrypoint of account. It returns nil if account or entrypoint doesn't exist.
+func (m *machine) entrypointType(address, entrypoint string) (*base.Node, error) {
+	if isImplicit(address) {
+		if entrypoint != consts.DefaultEntrypoint {
+			return nil, nil
+		}
+		return newType(consts.UNIT), nil
+	}
+	if !strings.HasPrefix(address, encoding.PrefixPublicKeyKT1) {
+		return nil, nil
+	}
+
+	script := m.script
+	if address != m.env.Self {
+		state, err := m.s.contract(address)
+		if err != nil {
+			if errors.Is(err, ErrUnknownContract) {
+				return nil, nil
+			}
+			return nil, err
+		}
+		script = state.Script
+	}
+	_, typ, ok := findEntrypoint(nodeSeq(script.Parameter), entrypoint)
+	if !ok {
+		return nil, nil
+	}
+	return m.s.normalize(typ)
+}
+
+func (m *machine) implicitAccount() error {
+	it, err := m.popInteger("IMPLICIT_ACCOUNT", consts.KEYHASH)
+	if err != nil {
+		return err
+	}
+	m.push(newType(consts.CONTRACT, newType(consts.UNIT)), it.val)
+	return nil
+}
+
+func (m *machine) newOperation(kind string) *operationValue {
+	return &operationValue{
+		Operation: Operation{
+			Kind:   kind,
+			Source: m.env.Self,
+			Nonce:  m.s.nextNonce(),
+		},
+	}
+}
+
+func (m *machine) transferTokens() error {
+	items, err := m.popN(3)
+	if err != nil {
+		return err
+	}
+	parameter, amount, contract := items[0], items[1], items[2]
+	if err := expectType(amount, consts.MUTEZ); err != nil {
+		return err
+	}
+	if err := expectType(contract, consts.CONTRACT); err != nil {
+		return err
+	}
+
+	value, err := unparse(mergeTypes(contract.typ.Args[0], parameter.typ), parameter.val, false)
+	if err != nil {
+		return err
+	}
+	destination, entrypoint := splitContract(contract.val.(string))
+	op := m.newOperation(OperationKindTransaction)
+	op.Destination = destination
+	op.Entrypoint = entrypoint
+	op.Amount = amount.val.(*big.Int).Int64()
+	op.Parameters = value
+	m.push(newType(consts.OPERATION), op)
+	return nil
+}
+
+func (m *machine) setDelegate() error {
+	it, err := m.popInteger("SET_DELEGATE", consts.OPTION)
+	if err != nil {
+		return err
+	}
+	op := m.newOperation(OperationKindDelegation)
+	if delegate := it.val.(optionValue); delegate.some {
+		op.Delegate = delegate.value.(string)
+	}
+	m.push(newType(consts.OPERATION), op)
+	return nil
+}
+
+// createContract - originates contract. Address of contract is computed as node does it for zero operation hash.
+func (m *machine) createContract(node *base.Node) error {
+	code, err := codeArg(node, 0)
+	if err != nil {
+		return err
+	}
+	var script ast.Script
+	for _, section := range code.Args {
+		switch section.Prim {
+		case consts.STORAGE:
+			script.Storage = section.Args
+		case consts.PARAMETER:
+			script.Parameter = section.Args
+		case consts.CODE:
+			script.Code = section.Args
+		}
+	}
+	if len(script.Storage) == 0 {
+		return errors.Wrap(consts.ErrInvalidType, "CREATE_CONTRACT: storage")
+	}
+	storageType, err := m.s.normalize(nodeSeq(script.Storage))
+	if err != nil {
+		return err
+	}
+
+	items, err := m.popN(3)
+	if err != nil {
+		return err
+	}
+	delegate, amount, storage := items[0], items[1], items[2]
+	if err := expectType(delegate, consts.OPTION); err != nil {
+		return err
+	}
+	if err := expectType(amount, consts.MUTEZ); err != nil {
+		return err
+	}
+	storageNode, err := unparse(mergeTypes(storageType, storage.typ), storage.val, false)
+	if err != nil {
+		return err
+	}
+
+	op := m.newOperation(OperationKindOrigination)
+	address, err := originatedAddress(op.Nonce)
+	if err != nil {
+		return err
+	}
+	op.Destination = address
+	op.Amount = amount.val.(*big.Int).Int64()
+	op.Script = code
+	op.Storage = storageNode
+	if value := delegate.val.(optionValue); value.some {
+		op.Delegate = value.value.(string)
+	}
+
+	m.push(newType(consts.ADDRESS), address)
+	m.push(newType(consts.OPERATION), op)
+	return nil
+}
+
+// originatedAddress - KT1 address is hash of operation hash and origination nonce
+func originatedAddress(nonce int64) (string, error) {
+	hash, err := blake2b.New(20, nil)
+	if err != nil {
+		return "", err
+	}
+	data := make([]byte, 36)
+	binary.BigEndian.PutUint32(data[32:], uint32(nonce))
+	if _, err := hash.Write(data); err != nil {
+		return "", err
+	}
+	return encoding.EncodeBase58(hash.Sum(nil), []byte(encoding.PrefixPublicKeyKT1))
+}
+
+func (m *machine) emit(node *base.Node) error {
+	it, err := m.pop()
+	if err != nil {
+		return err
+	}
+	typ, typeNode := it.typ, it.typ
+	if len(node.Args) > 0 {
+		if typ, err = m.typeArg(node, 0); err != nil {
+			return err
+		}
+		typeNode = node.Args[0]
+	}
+	payload, err := unparse(mergeTypes(typ, it.typ), it.val, false)
+	if err != nil {
+		return err
+	}
+
+	op := m.newOperation(OperationKindEvent)
+	op.Tag = fieldAnnotation(node)
+	op.Type = typeNode
+	op.Payload = payload
+	m.push(newType(consts.OPERATION), op)
+	return nil
+}
+
+// view - executes on-chain view of another contract. Result is `None` if contract or view doesn't exist or types mismatch.
+func (m *machine) view(node *base.Node) error {
+	if len(node.Args) != 2 || node.Args[0].StringValue == nil {
+		return errors.Wrap(consts.ErrInvalidArgsCount, "VIEW")
+	}
+	name := *node.Args[0].StringValue
+	typ, err := m.typeArg(node, 1)
+	if err != nil {
+		return err
+	}
+	items, err := m.popN(2)
+	if err != nil {
+		return err
+	}
+	input, target := items[0], items[1]
+	if err := expectType(target, consts.ADDRESS); err != nil {
+		return err
+	}
+	resultType := newType(consts.OPTION, typ)
+
+	address, entrypoint := splitContract(target.val.(string))
+	if entrypoint != consts.DefaultEntrypoint || !strings.HasPrefix(address, encoding.PrefixPublicKeyKT1) {
+		m.push(resultType, none())
+		return nil
+	}
+	state, err := m.s.contract(address)
+	if err != nil {
+		if errors.Is(err, ErrUnknownContract) {
+			m.push(resultType, none())
+			return nil
+		}
+		return err
+	}
+	view, err := m.s.findView(state.Script, name)
+	if err != nil {
+		if errors.Is(err, ErrUnknownView) {
+			m.push(resultType, none())
+			return nil
+		}
+		return err
+	}
+	if !equalTypes(view.input, input.typ) || !equalTypes(view.output, typ) {
+		m.push(resultType, none())
+		return nil
+	}
+
+	value, err := m.s.execView(address, state, view, Environment{
+		Source:       m.env.Source,
+		Sender:       m.env.Self,
+		ChainID:      m.env.ChainID,
+		Level:        m.env.Level,
+		Timestamp:    m.env.Timestamp,
+		MinBlockTime: m.env.MinBlockTime,
+	}, input.val)
+	if err != nil {
+		return err
+	}
+	m.push(resultType, some(value))
+	return nil
+}
+
+func (m *machine) ticket() error {
+	items, err := m.popN(2)
+	if err != nil {
+		return err
+	}
+	content, amount := items[0], items[1]
+	if err := expectType(amount, consts.NAT); err != nil {
+		return err
+	}
+	typ := newType(consts.OPTION, newType(consts.TICKET, content.typ))
+	if amount.val.(*big.Int).Sign() == 0 {
+		m.push(typ, none())
+		return nil
+	}
+	m.push(typ, some(&ticketValue{
+		ticketer:    m.env.Self,
+		contentType: content.typ,
+		content:     content.val,
+		amount:      amount.val.(*big.Int),
+	}))
+	return nil
+}
+
+func (m *machine) readTicket() error {
+	it, err := m.peek(0)
+	if err != nil {
+		return err
+	}
+	if err := expectType(*it, consts.TICKET); err != nil {
+		return err
+	}
+	ticket := it.val.(*ticketValue)
+	m.push(ticketPayloadType(it.typ.Args[0]), newPair(ticket.ticketer, ticket.content, ticket.amount))
+	return nil
+}
+
+func (m *machine) splitTicket() error {
+	items, err := m.popN(2)
+	if err != nil {
+		return err
+	}
+	it, amounts := items[0], items[1]
+	if err := expectType(it, consts.TICKET); err != nil {
+		return err
+	}
+	if err := expectType(amounts, consts.PAIR); err != nil {
+		return err
+	}
+
+	ticket := it.val.(*ticketValue)
+	pair := amounts.val.(pairValue)
+	left, right := pair.left.(*big.Int), pair.right.(*big.Int)
+	typ := newType(consts.OPTION, newType(consts.PAIR, it.typ, it.typ))
+	if left.Sign() == 0 || right.Sign() == 0 || new(big.Int).Add(left, right).Cmp(ticket.amount) != 0 {
+		m.push(typ, none())
+		return nil
+	}
+
+	first, second := *ticket, *ticket
+	first.amount, second.amount = left, right
+	m.push(typ, some(pairValue{left: &first, right: &second}))
+	return nil
+}
+
+func (m *machine) joinTickets() error {
+	it, err := m.popInteger("JOIN_TICKETS", consts.PAIR)
+	if err != nil {
+		return err
+	}
+	if err := expectType(item{typ: it.typ.Args[0]}, consts.TICKET); err != nil {
+		return err
+	}
+	pair := it.val.(pairValue)
+	first, second := pair.left.(*ticketValue), pair.right.(*ticketValue)
+	typ := newType(consts.OPTION, it.typ.Args[0])
+	if first.ticketer != second.ticketer {
+		m.push(typ, none())
+		return nil
+	}
+	res, err := compareValues(it.typ.Args[0].Args[0], first.content, second.content)
+	if err != nil {
+		return err
+	}
+	if res != 0 {
+		m.push(typ, none())
+		return nil
+	}
+
+	joined := *first
+	joined.amount = new(big.Int).Add(first.amount, second.amount)
+	m.push(typ, some(&joined))
+	return nil
+}
+
+func (m *machine) hashKey() error {
+	it, err := m.popInteger("HASH_KEY", consts.KEY)
+	if err != nil {
+		return err
+	}
+	key, err := encodeKey(it.val.(string))
+	if err != nil {
+		return err
+	}
+	hash, err := blake2b.New(20, nil)
+	if err != nil {
+		return err
+	}
+	if _, err := hash.Write(key[1:]); err != nil {
+		return err
+	}
+	keyHash, err := encoding.EncodeBase58(hash.Sum(nil), []byte(keyHashPrefixes[key[0]]))
+	if err != nil {
+		return err
+	}
+	m.push(newType(consts.KEYHASH), keyHash)
+	return nil
+}
+
+func (m *machine) hash(instruction string) error {
+	it, err := m.popInteger(instruction, consts.BYTES)
+	if err != nil {
+		return err
+	}
+	data := it.val.([]byte)
+
+	var result []byte
+	switch instruction {
+	case "BLAKE2B":
+		hash := blake2b.Sum256(data)
+		result = hash[:]
+	case "SHA256":
+		hash := sha256.Sum256(data)
+		result = hash[:]
+	case "SHA512":
+		hash := sha512.Sum512(data)
+		result = hash[:]
+	case "SHA3":
+		hash := sha3.Sum256(data)
+		result = hash[:]
+	case "KECCAK":
+		hash := sha3.NewLegacyKeccak256()
+		hash.Write(data)
+		result = hash.Sum(nil)
+	}
+	m.push(newType(consts.BYTES), result)
+	return nil
+}
+
+// checkSignature - verifies signature of blake2b hash of message. Only ed25519 and p256 keys are supported.
+func (m *machine) checkSignature() error {
+	items, err := m.popN(3)
+	if err != nil {
+		return err
+	}
+	key, signature, message := items[0], items[1], items[2]
+	if err := expectType(key, consts.KEY); err != nil {
+		return err
+	}
+	if err := expectType(signature, consts.SIGNATURE); err != nil {
+		return err
+	}
+	if err := expectType(message, consts.BYTES); err != nil {
+		return err
+	}
+
+	publicKey, err := encodeKey(key.val.(string))
+	if err != nil {
+		return err
+	}
+	sig, err := encoding.DecodeBase58(signature.val.(string))
+	if err != nil {
+		return err
+	}
+	digest := blake2b.Sum256(message.val.([]byte))
+
+	var ok bool
+	switch publicKey[0] {
+	case 0:
+		ok = len(publicKey[1:]) == ed25519.PublicKeySize && ed25519.Verify(publicKey[1:], digest[:], sig)
+	case 2:
+		x, y := elliptic.UnmarshalCompressed(elliptic.P256(), publicKey[1:])
+		if x != nil && len(sig) == 64 {
+			r, s := new(big.Int).SetBytes(sig[:32]), new(big.Int).SetBytes(sig[32:])
+			ok = ecdsa.Verify(&ecdsa.PublicKey{Curve: elliptic.P256(), X: x, Y: y}, digest[:], r, s)
+		}
+	default:
+		return errors.Wrap(ErrUnsupportedType, "secp256k1 signatures")
+	}
+	m.push(newType(consts.BOOL), ok)
+	return nil
+}
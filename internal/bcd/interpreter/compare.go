@@ -0,0 +1,181 @@
+package interpreter
+
+import (
+	"bytes"
+	"math/big"
+	"strings"
+
+	"github.com/baking-bad/bcdhub/internal/bcd/base"
+	"github.com/baking-bad/bcdhub/internal/bcd/consts"
+	"github.com/baking-bad/bcdhub/internal/bcd/encoding"
+	"github.com/baking-bad/bcdhub/internal/bcd/forge"
+	"github.com/pkg/errors"
+)
+
+func comparator(typ *base.Node) compareFunc {
+	return func(a, b Value) (int, error) {
+		return compareValues(typ, a, b)
+	}
+}
+
+// compareValues - compares values of comparable type. It returns -1, 0 or 1.
+func compareValues(typ *base.Node, a, b Value) (int, error) {
+	switch typ.Prim {
+	case consts.UNIT:
+		return 0, nil
+
+	case consts.INT, consts.NAT, consts.MUTEZ, consts.TIMESTAMP:
+		x, okX := a.(*big.Int)
+		y, okY := b.(*big.Int)
+		if !okX || !okY {
+			return 0, errors.Wrap(ErrInvalidStack, "compare integers")
+		}
+		return x.Cmp(y), nil
+
+	case consts.STRING:
+		return strings.Compare(a.(string), b.(string)), nil
+
+	case consts.BYTES:
+		return bytes.Compare(a.([]byte), b.([]byte)), nil
+
+	case consts.BOOL:
+		x, y := a.(bool), b.(bool)
+		switch {
+		case x == y:
+			return 0, nil
+		case !x:
+			return -1, nil
+		default:
+			return 1, nil
+		}
+
+	case consts.ADDRESS:
+		return compareAddresses(a.(string), b.(string))
+
+	case consts.KEYHASH:
+		x, err := encodeKeyHash(a.(string))
+		if err != nil {
+			return 0, err
+		}
+		y, err := encodeKeyHash(b.(string))
+		if err != nil {
+			return 0, err
+		}
+		return bytes.Compare(x, y), nil
+
+	case consts.KEY:
+		x, err := encodeKey(a.(string))
+		if err != nil {
+			return 0, err
+		}
+		y, err := encodeKey(b.(string))
+		if err != nil {
+			return 0, err
+		}
+		return bytes.Compare(x, y), nil
+
+	case consts.SIGNATURE, consts.CHAINID:
+		x, err := encoding.DecodeBase58(a.(string))
+		if err != nil {
+			return 0, err
+		}
+		y, err := encoding.DecodeBase58(b.(string))
+		if err != nil {
+			return 0, err
+		}
+		return bytes.Compare(x, y), nil
+
+	case consts.PAIR:
+		x, y := a.(pairValue), b.(pairValue)
+		res, err := compareValues(typ.Args[0], x.left, y.left)
+		if err != nil || res != 0 {
+			return res, err
+		}
+		return compareValues(typ.Args[1], x.right, y.right)
+
+	case consts.OPTION:
+		x, y := a.(optionValue), b.(optionValue)
+		switch {
+		case !x.some && !y.some:
+			return 0, nil
+		case !x.some:
+			return -1, nil
+		case !y.some:
+			return 1, nil
+		default:
+			return compareValues(typ.Args[0], x.value, y.value)
+		}
+
+	case consts.OR:
+		x, y := a.(orValue), b.(orValue)
+		switch {
+		case x.right == y.right:
+			arg := typ.Args[0]
+			if x.right {
+				arg = typ.Args[1]
+			}
+			return compareValues(arg, x.value, y.value)
+		case !x.right:
+			return -1, nil
+		default:
+			return 1, nil
+		}
+	}
+
+	return 0, errors.Wrap(consts.ErrTypeIsNotComparable, typ.Prim)
+}
+
+// compareAddresses - addresses are compared by binary representation, entrypoints are compared as strings
+func compareAddresses(a, b string) (int, error) {
+	addressA, entrypointA, _ := strings.Cut(a, "%")
+	addressB, entrypointB, _ := strings.Cut(b, "%")
+	x, err := encodeAddress(addressA)
+	if err != nil {
+		return 0, err
+	}
+	y, err := encodeAddress(addressB)
+	if err != nil {
+		return 0, err
+	}
+	if res := bytes.Compare(x, y); res != 0 {
+		return res, nil
+	}
+	return strings.Compare(entrypointA, entrypointB), nil
+}
+
+func encodeAddress(address string) ([]byte, error) {
+	if len(address) < 3 {
+		return nil, errors.Wrap(consts.ErrInvalidAddress, address)
+	}
+	return forge.Address(address, false)
+}
+
+func encodeKeyHash(keyHash string) ([]byte, error) {
+	if len(keyHash) < 3 {
+		return nil, errors.Wrap(consts.ErrInvalidAddress, keyHash)
+	}
+	data, err := forge.Address(keyHash, true)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) != 21 {
+		return nil, errors.Wrap(consts.ErrInvalidAddress, keyHash)
+	}
+	return data, nil
+}
+
+func encodeKey(key string) ([]byte, error) {
+	if len(key) < 4 {
+		return nil, errors.Wrapf(ErrInvalidValue, "key %s", key)
+	}
+	decoded, err := encoding.DecodeBase58(key)
+	if err != nil {
+		return nil, err
+	}
+	for tag, prefix := range keyPrefixes {
+		if key[:4] == prefix {
+			return append([]byte{byte(tag)}, decoded...), nil
+		}
+	}
+	return nil, errors.Wrapf(ErrUnsupportedType, "key %s", key)
+}
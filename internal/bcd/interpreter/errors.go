@@ -0,0 +1,55 @@
+package interpreter
+
+import (
+	"fmt"
+
+	"github.com/baking-bad/bcdhub/internal/bcd/base"
+	"github.com/pkg/errors"
+)
+
+// Errors
+var (
+	ErrUnsupportedInstruction = errors.New("unsupported instruction")
+	ErrUnsupportedType        = errors.New("unsupported type")
+	ErrStackUnderflow         = errors.New("stack underflow")
+	ErrInvalidStack           = errors.New("invalid stack item")
+	ErrInvalidValue           = errors.New("invalid value")
+	ErrOverflow               = errors.New("overflow")
+	ErrUnderflow              = errors.New("underflow")
+	ErrStepsLimit             = errors.New("steps limit is reached")
+	ErrUnknownEntrypoint      = errors.New("unknown entrypoint")
+	ErrUnknownView            = errors.New("unknown view")
+	ErrUnknownContract        = errors.New("unknown contract")
+)
+
+// FailWithError - error which is raised by `FAILWITH` instruction
+type FailWithError struct {
+	Location int64
+	With     *base.Node
+}
+
+// Error -
+func (e *FailWithError) Error() string {
+	with, err := json.MarshalToString(e.With)
+	if err != nil {
+		with = e.With.String()
+	}
+	return fmt.Sprintf("script rejected at %d: %s", e.Location, with)
+}
+
+// RuntimeError - error of instruction execution with its location in script
+type RuntimeError struct {
+	Location    int64
+	Instruction string
+	Err         error
+}
+
+// Error -
+func (e *RuntimeError) Error() string {
+	return fmt.Sprintf("%s at %d: %s", e.Instruction, e.Location, e.Err.Error())
+}
+
+// Unwrap -
+func (e *RuntimeError) Unwrap() error {
+	return e.Err
+}
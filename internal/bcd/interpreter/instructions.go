@@ -0,0 +1,1190 @@
+package interpreter
+
+import (
+	"math/big"
+
+	"github.com/baking-bad/bcdhub/internal/bcd/base"
+	"github.com/baking-bad/bcdhub/internal/bcd/consts"
+	"github.com/pkg/errors"
+)
+
+// instruction - executes one instruction. Stack isn't type checked before execution, so types of items are checked by instructions.
+func (m *machine) instruction(node *base.Node) error {
+	switch node.Prim {
+	// stack manipulation
+	case "DROP":
+		return m.drop(node)
+	case "DUP":
+		return m.dup(node)
+	case "SWAP":
+		return m.swap()
+	case "DIG":
+		return m.dig(node)
+	case "DUG":
+		return m.dug(node)
+	case "PUSH":
+		return m.pushInstruction(node)
+
+	// data structures
+	case "SOME":
+		return m.someInstruction()
+	case "NONE":
+		return m.noneInstruction(node)
+	case "UNIT":
+		m.push(newType(consts.UNIT), unitValue{})
+		return nil
+	case "NEVER":
+		return errors.Wrap(ErrInvalidStack, "value of type never can't exist")
+	case "PAIR":
+		return m.pair(node)
+	case "UNPAIR":
+		return m.unpair(node)
+	case "CAR":
+		return m.carCdr(false)
+	case "CDR":
+		return m.carCdr(true)
+	case "LEFT":
+		return m.leftRight(node, false)
+	case "RIGHT":
+		return m.leftRight(node, true)
+	case "NIL":
+		return m.nilInstruction(node)
+	case "CONS":
+		return m.cons()
+	case "SIZE":
+		return m.size()
+	case "EMPTY_SET":
+		return m.emptySet(node)
+	case "EMPTY_MAP":
+		return m.emptyMap(node, consts.MAP)
+	case "EMPTY_BIG_MAP":
+		return m.emptyMap(node, consts.BIGMAP)
+	case "MEM":
+		return m.mem()
+	case "GET":
+		return m.get(node)
+	case "UPDATE":
+		return m.update(node)
+	case "GET_AND_UPDATE":
+		return m.getAndUpdate()
+	case "CAST":
+		return m.cast(node)
+	case "RENAME":
+		_, err := m.peek(0)
+		return err
+
+	// control structures
+	case "IF":
+		return m.ifInstruction(node)
+	case "IF_NONE":
+		return m.ifNone(node)
+	case "IF_LEFT":
+		return m.ifLeft(node)
+	case "IF_CONS":
+		return m.ifCons(node)
+	case "LOOP":
+		return m.loop(node)
+	case "LOOP_LEFT":
+		return m.loopLeft(node)
+	case "MAP":
+		return m.mapInstruction(node)
+	case "ITER":
+		return m.iter(node)
+	case "DIP":
+		return m.dip(node)
+	case "LAMBDA":
+		return m.lambda(node, false)
+	case "LAMBDA_REC":
+		return m.lambda(node, true)
+	case "EXEC":
+		return m.execInstruction()
+	case "APPLY":
+		return m.apply()
+	case "FAILWITH":
+		return m.failWith(node)
+
+	// strings and bytes
+	case "CONCAT":
+		return m.concat()
+	case "SLICE":
+		return m.slice()
+	case "PACK":
+		return m.packInstruction()
+	case "UNPACK":
+		return m.unpackInstruction(node)
+
+	// arithmetic and logic
+	case "ADD":
+		return m.add()
+	case "SUB":
+		return m.sub()
+	case "SUB_MUTEZ":
+		return m.subMutez()
+	case "MUL":
+		return m.mul()
+	case "EDIV":
+		return m.ediv()
+	case "ABS":
+		return m.abs()
+	case "ISNAT":
+		return m.isNat()
+	case "INT":
+		return m.int()
+	case "NAT":
+		return m.nat()
+	case "BYTES":
+		return m.bytes()
+	case "NEG":
+		return m.neg()
+	case "LSL":
+		return m.shift(true)
+	case "LSR":
+		return m.shift(false)
+	case "OR", "AND", "XOR":
+		return m.logic(node.Prim)
+	case "NOT":
+		return m.not()
+	case "COMPARE":
+		return m.compare()
+	case "EQ", "NEQ", "LT", "GT", "LE", "GE":
+		return m.compareResult(node.Prim)
+
+	// blockchain
+	case "SELF":
+		return m.self(node)
+	case "SELF_ADDRESS":
+		m.push(newType(consts.ADDRESS), m.env.Self)
+		return nil
+	case "ADDRESS":
+		return m.address()
+	case "CONTRACT":
+		return m.contract(node)
+	case "IMPLICIT_ACCOUNT":
+		return m.implicitAccount()
+	case "TRANSFER_TOKENS":
+		return m.transferTokens()
+	case "SET_DELEGATE":
+		return m.setDelegate()
+	case "CREATE_CONTRACT":
+		return m.createContract(node)
+	case "EMIT":
+		return m.emit(node)
+	case "AMOUNT":
+		m.push(newType(consts.MUTEZ), bigInt(m.env.Amount))
+		return nil
+	case "BALANCE":
+		m.push(newType(consts.MUTEZ), bigInt(m.env.Balance))
+		return nil
+	case "NOW":
+		m.push(newType(consts.TIMESTAMP), m.now())
+		return nil
+	case "LEVEL":
+		m.push(newType(consts.NAT), bigInt(m.env.Level))
+		return nil
+	case "SOURCE":
+		m.push(newType(consts.ADDRESS), m.env.Source)
+		return nil
+	case "SENDER":
+		m.push(newType(consts.ADDRESS), m.env.Sender)
+		return nil
+	case "CHAIN_ID":
+		m.push(newType(consts.CHAINID), m.env.ChainID)
+		return nil
+	case "MIN_BLOCK_TIME":
+		m.push(newType(consts.NAT), bigInt(m.env.MinBlockTime))
+		return nil
+	case "VIEW":
+		return m.view(node)
+
+	// tickets
+	case "TICKET":
+		return m.ticket()
+	case "READ_TICKET":
+		return m.readTicket()
+	case "SPLIT_TICKET":
+		return m.splitTicket()
+	case "JOIN_TICKETS":
+		return m.joinTickets()
+
+	// cryptography
+	case "HASH_KEY":
+		return m.hashKey()
+	case "BLAKE2B", "SHA256", "SHA512", "SHA3", "KECCAK":
+		return m.hash(node.Prim)
+	case "CHECK_SIGNATURE":
+		return m.checkSignature()
+	}
+
+	return errors.Wrap(ErrUnsupportedInstruction, node.Prim)
+}
+
+// intArg - returns integer argument of instruction, e.g. `DIG n`. It returns `defaultValue` if instruction has no arguments.
+func intArg(node *base.Node, defaultValue int) (int, error) {
+	if len(node.Args) == 0 {
+		return defaultValue, nil
+	}
+	if node.Args[0].IntValue == nil || !node.Args[0].IntValue.IsInt64() || node.Args[0].IntValue.Int64() > 1<<16 {
+		return 0, errors.Wrapf(ErrInvalidValue, "invalid argument of %s", node.Prim)
+	}
+	return int(node.Args[0].IntValue.Int64()), nil
+}
+
+// typeArg - returns normalized type argument of instruction
+func (m *machine) typeArg(node *base.Node, index int) (*base.Node, error) {
+	if len(node.Args) <= index {
+		return nil, errors.Wrapf(consts.ErrInvalidArgsCount, "%s: type argument %d", node.Prim, index)
+	}
+	return m.s.normalize(node.Args[index])
+}
+
+// codeArg - returns code argument of instruction
+func codeArg(node *base.Node, index int) (*base.Node, error) {
+	if len(node.Args) <= index || node.Args[index].Prim != consts.PrimArray {
+		return nil, errors.Wrapf(consts.ErrInvalidArgsCount, "%s: code argument %d", node.Prim, index)
+	}
+	return node.Args[index], nil
+}
+
+func expectType(it item, prims ...string) error {
+	for _, prim := range prims {
+		if it.typ.Prim == prim {
+			return nil
+		}
+	}
+	return errors.Wrapf(ErrInvalidStack, "expected %v, got %s", prims, it.typ.Prim)
+}
+
+func (m *machine) drop(node *base.Node) error {
+	count, err := intArg(node, 1)
+	if err != nil {
+		return err
+	}
+	_, err = m.popN(count)
+	return err
+}
+
+func (m *machine) dup(node *base.Node) error {
+	depth, err := intArg(node, 1)
+	if err != nil {
+		return err
+	}
+	if depth == 0 {
+		return errors.Wrap(ErrInvalidValue, "DUP 0")
+	}
+	it, err := m.peek(depth - 1)
+	if err != nil {
+		return err
+	}
+	m.push(it.typ, it.val)
+	return nil
+}
+
+func (m *machine) swap() error {
+	items, err := m.popN(2)
+	if err != nil {
+		return err
+	}
+	m.push(items[0].typ, items[0].val)
+	m.push(items[1].typ, items[1].val)
+	return nil
+}
+
+func (m *machine) dig(node *base.Node) error {
+	depth, err := intArg(node, 0)
+	if err != nil {
+		return err
+	}
+	if _, err := m.peek(depth); err != nil {
+		return err
+	}
+	idx := len(m.stack) - 1 - depth
+	it := m.stack[idx]
+	m.stack = append(m.stack[:idx], m.stack[idx+1:]...)
+	m.stack = append(m.stack, it)
+	return nil
+}
+
+func (m *machine) dug(node *base.Node) error {
+	depth, err := intArg(node, 0)
+	if err != nil {
+		return err
+	}
+	if _, err := m.peek(depth); err != nil {
+		return err
+	}
+	top, _ := m.pop()
+	idx := len(m.stack) - depth
+	m.stack = append(m.stack, item{})
+	copy(m.stack[idx+1:], m.stack[idx:])
+	m.stack[idx] = top
+	return nil
+}
+
+func (m *machine) pushInstruction(node *base.Node) error {
+	typ, err := m.typeArg(node, 0)
+	if err != nil {
+		return err
+	}
+	if len(node.Args) != 2 {
+		return errors.Wrap(consts.ErrInvalidArgsCount, "PUSH")
+	}
+	value, err := parseValue(typ, node.Args[1])
+	if err != nil {
+		return err
+	}
+	m.push(typ, value)
+	return nil
+}
+
+func (m *machine) someInstruction() error {
+	it, err := m.pop()
+	if err != nil {
+		return err
+	}
+	m.push(newType(consts.OPTION, it.typ), some(it.val))
+	return nil
+}
+
+func (m *machine) noneInstruction(node *base.Node) error {
+	typ, err := m.typeArg(node, 0)
+	if err != nil {
+		return err
+	}
+	m.push(newType(consts.OPTION, typ), none())
+	return nil
+}
+
+func (m *machine) pair(node *base.Node) error {
+	count, err := intArg(node, 2)
+	if err != nil {
+		return err
+	}
+	if count < 2 {
+		return errors.Wrapf(ErrInvalidValue, "PAIR %d", count)
+	}
+	items, err := m.popN(count)
+	if err != nil {
+		return err
+	}
+	types := make([]*base.Node, count)
+	values := make([]Value, count)
+	for i := range items {
+		types[i] = items[i].typ
+		values[i] = items[i].val
+	}
+	m.push(combType(types), newPair(values...))
+	return nil
+}
+
+func combType(types []*base.Node) *base.Node {
+	if len(types) == 1 {
+		return types[0]
+	}
+	return newType(consts.PAIR, types[0], combType(types[1:]))
+}
+
+func (m *machine) unpair(node *base.Node) error {
+	count, err := intArg(node, 2)
+	if err != nil {
+		return err
+	}
+	if count < 2 {
+		return errors.Wrapf(ErrInvalidValue, "UNPAIR %d", count)
+	}
+	it, err := m.pop()
+	if err != nil {
+		return err
+	}
+
+	items := make([]item, 0, count)
+	for i := 0; i < count-1; i++ {
+		if err := expectType(it, consts.PAIR); err != nil {
+			return err
+		}
+		pair := it.val.(pairValue)
+		items = append(items, item{typ: it.typ.Args[0], val: pair.left})
+		it = item{typ: it.typ.Args[1], val: pair.right}
+	}
+	items = append(items, it)
+	for i := len(items) - 1; i >= 0; i-- {
+		m.push(items[i].typ, items[i].val)
+	}
+	return nil
+}
+
+func (m *machine) carCdr(right bool) error {
+	it, err := m.pop()
+	if err != nil {
+		return err
+	}
+	if err := expectType(it, consts.PAIR); err != nil {
+		return err
+	}
+	pair := it.val.(pairValue)
+	if right {
+		m.push(it.typ.Args[1], pair.right)
+	} else {
+		m.push(it.typ.Args[0], pair.left)
+	}
+	return nil
+}
+
+func (m *machine) leftRight(node *base.Node, right bool) error {
+	typ, err := m.typeArg(node, 0)
+	if err != nil {
+		return err
+	}
+	it, err := m.pop()
+	if err != nil {
+		return err
+	}
+	if right {
+		m.push(newType(consts.OR, typ, it.typ), orValue{right: true, value: it.val})
+	} else {
+		m.push(newType(consts.OR, it.typ, typ), orValue{value: it.val})
+	}
+	return nil
+}
+
+func (m *machine) nilInstruction(node *base.Node) error {
+	typ, err := m.typeArg(node, 0)
+	if err != nil {
+		return err
+	}
+	m.push(newType(consts.LIST, typ), listValue{})
+	return nil
+}
+
+func (m *machine) cons() error {
+	items, err := m.popN(2)
+	if err != nil {
+		return err
+	}
+	head, list := items[0], items[1]
+	if err := expectType(list, consts.LIST); err != nil {
+		return err
+	}
+	tail := list.val.(listValue)
+	result := make(listValue, 0, len(tail)+1)
+	result = append(result, head.val)
+	result = append(result, tail...)
+	m.push(newType(consts.LIST, mergeTypes(list.typ.Args[0], head.typ)), result)
+	return nil
+}
+
+func (m *machine) size() error {
+	it, err := m.pop()
+	if err != nil {
+		return err
+	}
+	var size int
+	switch it.typ.Prim {
+	case consts.STRING:
+		size = len(it.val.(string))
+	case consts.BYTES:
+		size = len(it.val.([]byte))
+	case consts.LIST:
+		size = len(it.val.(listValue))
+	case consts.SET:
+		size = len(it.val.(*setValue).items)
+	case consts.MAP:
+		size = len(it.val.(*mapValue).keys)
+	default:
+		return expectType(it, consts.STRING, consts.BYTES, consts.LIST, consts.SET, consts.MAP)
+	}
+	m.push(newType(consts.NAT), bigInt(int64(size)))
+	return nil
+}
+
+func (m *machine) emptySet(node *base.Node) error {
+	typ, err := m.typeArg(node, 0)
+	if err != nil {
+		return err
+	}
+	m.push(newType(consts.SET, typ), new(setValue))
+	return nil
+}
+
+func (m *machine) emptyMap(node *base.Node, prim string) error {
+	keyType, err := m.typeArg(node, 0)
+	if err != nil {
+		return err
+	}
+	valueType, err := m.typeArg(node, 1)
+	if err != nil {
+		return err
+	}
+	if prim == consts.BIGMAP {
+		m.push(newType(prim, keyType, valueType), newBigMap(keyType, valueType))
+	} else {
+		m.push(newType(prim, keyType, valueType), new(mapValue))
+	}
+	return nil
+}
+
+// collectionGet - returns value of key in map or big map
+func (m *machine) collectionGet(collection, key item) (Value, bool, error) {
+	switch collection.typ.Prim {
+	case consts.MAP:
+		keyType := mergeTypes(collection.typ.Args[0], key.typ)
+		return collection.val.(*mapValue).get(key.val, comparator(keyType))
+	case consts.BIGMAP:
+		return collection.val.(*bigMapValue).get(m.s, key.val)
+	default:
+		return nil, false, expectType(collection, consts.MAP, consts.BIGMAP)
+	}
+}
+
+// collectionUpdate - updates key of map or big map. Key is removed if value is nil.
+func (m *machine) collectionUpdate(collection, key item, value Value) (Value, error) {
+	switch collection.typ.Prim {
+	case consts.MAP:
+		keyType := mergeTypes(collection.typ.Args[0], key.typ)
+		return collection.val.(*mapValue).update(key.val, value, comparator(keyType))
+	case consts.BIGMAP:
+		return collection.val.(*bigMapValue).update(key.val, value)
+	default:
+		return nil, expectType(collection, consts.MAP, consts.BIGMAP)
+	}
+}
+
+func (m *machine) mem() error {
+	items, err := m.popN(2)
+	if err != nil {
+		return err
+	}
+	key, collection := items[0], items[1]
+
+	var ok bool
+	if collection.typ.Prim == consts.SET {
+		keyType := mergeTypes(collection.typ.Args[0], key.typ)
+		_, ok, err = collection.val.(*setValue).find(key.val, comparator(keyType))
+	} else {
+		_, ok, err = m.collectionGet(collection, key)
+	}
+	if err != nil {
+		return err
+	}
+	m.push(newType(consts.BOOL), ok)
+	return nil
+}
+
+func (m *machine) get(node *base.Node) error {
+	if len(node.Args) > 0 {
+		index, err := intArg(node, 0)
+		if err != nil {
+			return err
+		}
+		it, err := m.pop()
+		if err != nil {
+			return err
+		}
+		for ; index > 1; index -= 2 {
+			if err := expectType(it, consts.PAIR); err != nil {
+				return err
+			}
+			it = item{typ: it.typ.Args[1], val: it.val.(pairValue).right}
+		}
+		if index == 1 {
+			if err := expectType(it, consts.PAIR); err != nil {
+				return err
+			}
+			it = item{typ: it.typ.Args[0], val: it.val.(pairValue).left}
+		}
+		m.push(it.typ, it.val)
+		return nil
+	}
+
+	items, err := m.popN(2)
+	if err != nil {
+		return err
+	}
+	key, collection := items[0], items[1]
+	value, ok, err := m.collectionGet(collection, key)
+	if err != nil {
+		return err
+	}
+	typ := newType(consts.OPTION, collection.typ.Args[1])
+	if ok {
+		m.push(typ, some(value))
+	} else {
+		m.push(typ, none())
+	}
+	return nil
+}
+
+func (m *machine) update(node *base.Node) error {
+	if len(node.Args) > 0 {
+		index, err := intArg(node, 0)
+		if err != nil {
+			return err
+		}
+		items, err := m.popN(2)
+		if err != nil {
+			return err
+		}
+		typ, value, err := updateComb(index, items[1], items[0])
+		if err != nil {
+			return err
+		}
+		m.push(typ, value)
+		return nil
+	}
+
+	items, err := m.popN(3)
+	if err != nil {
+		return err
+	}
+	key, value, collection := items[0], items[1], items[2]
+
+	var result Value
+	if collection.typ.Prim == consts.SET {
+		keyType := mergeTypes(collection.typ.Args[0], key.typ)
+		result, err = collection.val.(*setValue).update(key.val, value.val.(bool), comparator(keyType))
+		if err != nil {
+			return err
+		}
+		m.push(newType(consts.SET, keyType), result)
+		return nil
+	}
+
+	if err := expectType(value, consts.OPTION); err != nil {
+		return err
+	}
+	option := value.val.(optionValue)
+	var newValue Value
+	if option.some {
+		newValue = option.value
+	}
+	result, err = m.collectionUpdate(collection, key, newValue)
+	if err != nil {
+		return err
+	}
+	m.push(collectionType(collection.typ, key.typ, value.typ.Args[0]), result)
+	return nil
+}
+
+// collectionType - returns type of map where unknown types are replaced by types of updated key and value
+func collectionType(typ, keyType, valueType *base.Node) *base.Node {
+	return newType(typ.Prim, mergeTypes(typ.Args[0], keyType), mergeTypes(typ.Args[1], valueType))
+}
+
+// updateComb - replaces `index` element of right comb by `value`
+func updateComb(index int, comb, value item) (*base.Node, Value, error) {
+	if index == 0 {
+		return value.typ, value.val, nil
+	}
+	if err := expectType(comb, consts.PAIR); err != nil {
+		return nil, nil, err
+	}
+	pair := comb.val.(pairValue)
+	if index == 1 {
+		return newType(consts.PAIR, value.typ, comb.typ.Args[1]), pairValue{left: value.val, right: pair.right}, nil
+	}
+	typ, right, err := updateComb(index-2, item{typ: comb.typ.Args[1], val: pair.right}, value)
+	if err != nil {
+		return nil, nil, err
+	}
+	return newType(consts.PAIR, comb.typ.Args[0], typ), pairValue{left: pair.left, right: right}, nil
+}
+
+func (m *machine) getAndUpdate() error {
+	items, err := m.popN(3)
+	if err != nil {
+		return err
+	}
+	key, value, collection := items[0], items[1], items[2]
+	if err := expectType(value, consts.OPTION); err != nil {
+		return err
+	}
+
+	old, ok, err := m.collectionGet(collection, key)
+	if err != nil {
+		return err
+	}
+	option := value.val.(optionValue)
+	var newValue Value
+	if option.some {
+		newValue = option.value
+	}
+	result, err := m.collectionUpdate(collection, key, newValue)
+	if err != nil {
+		return err
+	}
+
+	typ := collectionType(collection.typ, key.typ, value.typ.Args[0])
+	m.push(typ, result)
+	if ok {
+		m.push(newType(consts.OPTION, typ.Args[1]), some(old))
+	} else {
+		m.push(newType(consts.OPTION, typ.Args[1]), none())
+	}
+	return nil
+}
+
+func (m *machine) cast(node *base.Node) error {
+	typ, err := m.typeArg(node, 0)
+	if err != nil {
+		return err
+	}
+	it, err := m.pop()
+	if err != nil {
+		return err
+	}
+	if !equalTypes(typ, it.typ) {
+		return errors.Wrapf(ErrInvalidStack, "can't cast %s to %s", it.typ.Prim, typ.Prim)
+	}
+	m.push(typ, it.val)
+	return nil
+}
+
+func (m *machine) ifInstruction(node *base.Node) error {
+	it, err := m.pop()
+	if err != nil {
+		return err
+	}
+	if err := expectType(it, consts.BOOL); err != nil {
+		return err
+	}
+	return m.branch(node, !it.val.(bool))
+}
+
+// branch - executes first or second code argument of instruction
+func (m *machine) branch(node *base.Node, second bool) error {
+	index := 0
+	if second {
+		index = 1
+	}
+	code, err := codeArg(node, index)
+	if err != nil {
+		return err
+	}
+	return m.exec(code)
+}
+
+func (m *machine) ifNone(node *base.Node) error {
+	it, err := m.pop()
+	if err != nil {
+		return err
+	}
+	if err := expectType(it, consts.OPTION); err != nil {
+		return err
+	}
+	option := it.val.(optionValue)
+	if option.some {
+		m.push(it.typ.Args[0], option.value)
+	}
+	return m.branch(node, option.some)
+}
+
+func (m *machine) ifLeft(node *base.Node) error {
+	it, err := m.pop()
+	if err != nil {
+		return err
+	}
+	if err := expectType(it, consts.OR); err != nil {
+		return err
+	}
+	or := it.val.(orValue)
+	if or.right {
+		m.push(it.typ.Args[1], or.value)
+	} else {
+		m.push(it.typ.Args[0], or.value)
+	}
+	return m.branch(node, or.right)
+}
+
+func (m *machine) ifCons(node *base.Node) error {
+	it, err := m.pop()
+	if err != nil {
+		return err
+	}
+	if err := expectType(it, consts.LIST); err != nil {
+		return err
+	}
+	list := it.val.(listValue)
+	if len(list) == 0 {
+		return m.branch(node, true)
+	}
+	m.push(it.typ, list[1:])
+	m.push(it.typ.Args[0], list[0])
+	return m.branch(node, false)
+}
+
+func (m *machine) loop(node *base.Node) error {
+	code, err := codeArg(node, 0)
+	if err != nil {
+		return err
+	}
+	for {
+		it, err := m.pop()
+		if err != nil {
+			return err
+		}
+		if err := expectType(it, consts.BOOL); err != nil {
+			return err
+		}
+		if !it.val.(bool) {
+			return nil
+		}
+		if err := m.exec(code); err != nil {
+			return err
+		}
+	}
+}
+
+func (m *machine) loopLeft(node *base.Node) error {
+	code, err := codeArg(node, 0)
+	if err != nil {
+		return err
+	}
+	for {
+		it, err := m.pop()
+		if err != nil {
+			return err
+		}
+		if err := expectType(it, consts.OR); err != nil {
+			return err
+		}
+		or := it.val.(orValue)
+		if or.right {
+			m.push(it.typ.Args[1], or.value)
+			return nil
+		}
+		m.push(it.typ.Args[0], or.value)
+		if err := m.exec(code); err != nil {
+			return err
+		}
+	}
+}
+
+func (m *machine) mapInstruction(node *base.Node) error {
+	code, err := codeArg(node, 0)
+	if err != nil {
+		return err
+	}
+	it, err := m.pop()
+	if err != nil {
+		return err
+	}
+
+	resultType := newType(unknownType)
+	apply := func(typ *base.Node, value Value) (Value, error) {
+		m.push(typ, value)
+		if err := m.exec(code); err != nil {
+			return nil, err
+		}
+		result, err := m.pop()
+		if err != nil {
+			return nil, err
+		}
+		resultType = result.typ
+		return result.val, nil
+	}
+
+	switch it.typ.Prim {
+	case consts.LIST:
+		list := it.val.(listValue)
+		result := make(listValue, len(list))
+		for i := range list {
+			if result[i], err = apply(it.typ.Args[0], list[i]); err != nil {
+				return err
+			}
+		}
+		m.push(newType(consts.LIST, resultType), result)
+
+	case consts.MAP:
+		source := it.val.(*mapValue)
+		result := &mapValue{
+			keys:   source.keys,
+			values: make([]Value, len(source.values)),
+		}
+		eltType := newType(consts.PAIR, it.typ.Args[0], it.typ.Args[1])
+		for i := range source.keys {
+			if result.values[i], err = apply(eltType, pairValue{left: source.keys[i], right: source.values[i]}); err != nil {
+				return err
+			}
+		}
+		m.push(newType(consts.MAP, it.typ.Args[0], resultType), result)
+
+	default:
+		return expectType(it, consts.LIST, consts.MAP)
+	}
+	return nil
+}
+
+func (m *machine) iter(node *base.Node) error {
+	code, err := codeArg(node, 0)
+	if err != nil {
+		return err
+	}
+	it, err := m.pop()
+	if err != nil {
+		return err
+	}
+
+	var (
+		eltType *base.Node
+		items   []Value
+	)
+	switch it.typ.Prim {
+	case consts.LIST:
+		eltType, items = it.typ.Args[0], it.val.(listValue)
+	case consts.SET:
+		eltType, items = it.typ.Args[0], it.val.(*setValue).items
+	case consts.MAP:
+		source := it.val.(*mapValue)
+		eltType = newType(consts.PAIR, it.typ.Args[0], it.typ.Args[1])
+		items = make([]Value, len(source.keys))
+		for i := range source.keys {
+			items[i] = pairValue{left: source.keys[i], right: source.values[i]}
+		}
+	default:
+		return expectType(it, consts.LIST, consts.SET, consts.MAP)
+	}
+
+	for i := range items {
+		m.push(eltType, items[i])
+		if err := m.exec(code); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *machine) dip(node *base.Node) error {
+	depth, codeIndex := 1, 0
+	if len(node.Args) == 2 {
+		var err error
+		if depth, err = intArg(node, 1); err != nil {
+			return err
+		}
+		codeIndex = 1
+	}
+	code, err := codeArg(node, codeIndex)
+	if err != nil {
+		return err
+	}
+	items, err := m.popN(depth)
+	if err != nil {
+		return err
+	}
+	if err := m.exec(code); err != nil {
+		return err
+	}
+	for i := len(items) - 1; i >= 0; i-- {
+		m.push(items[i].typ, items[i].val)
+	}
+	return nil
+}
+
+func (m *machine) lambda(node *base.Node, recursive bool) error {
+	parameter, err := m.typeArg(node, 0)
+	if err != nil {
+		return err
+	}
+	result, err := m.typeArg(node, 1)
+	if err != nil {
+		return err
+	}
+	code, err := codeArg(node, 2)
+	if err != nil {
+		return err
+	}
+	m.push(newType(consts.LAMBDA, parameter, result), &lambdaValue{
+		code:      code,
+		recursive: recursive,
+		parameter: parameter,
+		result:    result,
+	})
+	return nil
+}
+
+func (m *machine) execInstruction() error {
+	items, err := m.popN(2)
+	if err != nil {
+		return err
+	}
+	arg, lambda := items[0], items[1]
+	if err := expectType(lambda, consts.LAMBDA); err != nil {
+		return err
+	}
+	l := lambda.val.(*lambdaValue)
+	result, err := m.callLambda(lambda.typ, l, arg.val)
+	if err != nil {
+		return err
+	}
+	m.push(l.result, result)
+	return nil
+}
+
+// apply - partially applies lambda. Result lambda pushes captured value and pairs it with argument before original code.
+func (m *machine) apply() error {
+	items, err := m.popN(2)
+	if err != nil {
+		return err
+	}
+	captured, lambda := items[0], items[1]
+	if err := expectType(lambda, consts.LAMBDA); err != nil {
+		return err
+	}
+	l := lambda.val.(*lambdaValue)
+	if err := expectType(item{typ: l.parameter}, consts.PAIR); err != nil {
+		return err
+	}
+
+	capturedType := mergeTypes(l.parameter.Args[0], captured.typ)
+	value, err := unparse(capturedType, captured.val, false)
+	if err != nil {
+		return err
+	}
+	code := []*base.Node{
+		{Prim: "PUSH", Args: []*base.Node{capturedType, value}},
+		{Prim: "PAIR"},
+	}
+	if l.recursive {
+		code = append(code,
+			&base.Node{Prim: "LAMBDA_REC", Args: []*base.Node{l.parameter, l.result, l.code}},
+			&base.Node{Prim: "SWAP"},
+			&base.Node{Prim: "EXEC"},
+		)
+	} else {
+		code = append(code, l.code.Args...)
+	}
+
+	m.push(newType(consts.LAMBDA, l.parameter.Args[1], l.result), &lambdaValue{
+		code:      &base.Node{Prim: consts.PrimArray, Args: code},
+		parameter: l.parameter.Args[1],
+		result:    l.result,
+	})
+	return nil
+}
+
+func (m *machine) failWith(node *base.Node) error {
+	it, err := m.pop()
+	if err != nil {
+		return err
+	}
+	with, err := unparse(it.typ, it.val, false)
+	if err != nil {
+		return err
+	}
+	return &FailWithError{
+		Location: m.s.location(node),
+		With:     with,
+	}
+}
+
+func (m *machine) concat() error {
+	top, err := m.pop()
+	if err != nil {
+		return err
+	}
+
+	if top.typ.Prim == consts.LIST {
+		list := top.val.(listValue)
+		switch top.typ.Args[0].Prim {
+		case consts.STRING:
+			var result string
+			for i := range list {
+				result += list[i].(string)
+			}
+			m.push(newType(consts.STRING), result)
+		case consts.BYTES:
+			result := make([]byte, 0)
+			for i := range list {
+				result = append(result, list[i].([]byte)...)
+			}
+			m.push(newType(consts.BYTES), result)
+		case unknownType:
+			m.push(newType(consts.STRING), "")
+		default:
+			return expectType(item{typ: top.typ.Args[0]}, consts.STRING, consts.BYTES)
+		}
+		return nil
+	}
+
+	second, err := m.pop()
+	if err != nil {
+		return err
+	}
+	switch {
+	case top.typ.Prim == consts.STRING && second.typ.Prim == consts.STRING:
+		m.push(top.typ, top.val.(string)+second.val.(string))
+	case top.typ.Prim == consts.BYTES && second.typ.Prim == consts.BYTES:
+		result := make([]byte, 0, len(top.val.([]byte))+len(second.val.([]byte)))
+		result = append(result, top.val.([]byte)...)
+		result = append(result, second.val.([]byte)...)
+		m.push(top.typ, result)
+	default:
+		return errors.Wrapf(ErrInvalidStack, "CONCAT of %s and %s", top.typ.Prim, second.typ.Prim)
+	}
+	return nil
+}
+
+func (m *machine) slice() error {
+	items, err := m.popN(3)
+	if err != nil {
+		return err
+	}
+	if err := expectType(items[0], consts.NAT); err != nil {
+		return err
+	}
+	if err := expectType(items[1], consts.NAT); err != nil {
+		return err
+	}
+	offset, length, source := items[0].val.(*big.Int), items[1].val.(*big.Int), items[2]
+
+	var size int
+	switch source.typ.Prim {
+	case consts.STRING:
+		size = len(source.val.(string))
+	case consts.BYTES:
+		size = len(source.val.([]byte))
+	default:
+		return expectType(source, consts.STRING, consts.BYTES)
+	}
+
+	typ := newType(consts.OPTION, source.typ)
+	end := new(big.Int).Add(offset, length)
+	if end.Cmp(bigInt(int64(size))) > 0 {
+		m.push(typ, none())
+		return nil
+	}
+	from, to := int(offset.Int64()), int(end.Int64())
+	if source.typ.Prim == consts.STRING {
+		m.push(typ, some(source.val.(string)[from:to]))
+	} else {
+		m.push(typ, some(append([]byte(nil), source.val.([]byte)[from:to]...)))
+	}
+	return nil
+}
+
+func (m *machine) packInstruction() error {
+	it, err := m.pop()
+	if err != nil {
+		return err
+	}
+	data, err := pack(it.typ, it.val)
+	if err != nil {
+		return err
+	}
+	m.push(newType(consts.BYTES), data)
+	return nil
+}
+
+func (m *machine) unpackInstruction(node *base.Node) error {
+	typ, err := m.typeArg(node, 0)
+	if err != nil {
+		return err
+	}
+	it, err := m.pop()
+	if err != nil {
+		return err
+	}
+	if err := expectType(it, consts.BYTES); err != nil {
+		return err
+	}
+	m.push(newType(consts.OPTION, typ), unpack(typ, it.val.([]byte)))
+	return nil
+}
@@ -0,0 +1,232 @@
+package interpreter
+
+import (
+	"context"
+	"time"
+
+	"github.com/baking-bad/bcdhub/internal/bcd/ast"
+	"github.com/baking-bad/bcdhub/internal/bcd/base"
+	"github.com/baking-bad/bcdhub/internal/bcd/consts"
+	jsoniter "github.com/json-iterator/go"
+)
+
+var json = jsoniter.ConfigCompatibleWithStandardLibrary
+
+// DefaultMaxSteps - default limit of executed instructions
+const DefaultMaxSteps int64 = 1_000_000
+
+// Operation kinds
+const (
+	OperationKindTransaction = "transaction"
+	OperationKindOrigination = "origination"
+	OperationKindDelegation  = "delegation"
+	OperationKindEvent       = "event"
+)
+
+// Big map diff actions
+const (
+	BigMapActionAlloc  = "alloc"
+	BigMapActionUpdate = "update"
+	BigMapActionCopy   = "copy"
+	BigMapActionRemove = "remove"
+)
+
+// Chain - source of on-chain data which is required for execution. Big map values and contracts are requested by demand.
+type Chain interface {
+	// BigMapValue - returns current value of big map key. It returns nil if key doesn't exist.
+	BigMapValue(ctx context.Context, ptr int64, keyHash string) (*base.Node, error)
+	// Contract - returns script, storage and balance of originated contract. It returns `ErrUnknownContract` if contract doesn't exist.
+	Contract(ctx context.Context, address string) (ContractState, error)
+}
+
+// ContractState -
+type ContractState struct {
+	Script  *ast.Script
+	Storage *base.Node
+	Balance int64
+}
+
+// Environment - context of execution: values of `SELF`, `SENDER`, `AMOUNT`, `NOW` and other instructions
+type Environment struct {
+	Self         string
+	Source       string
+	Sender       string
+	Amount       int64
+	Balance      int64
+	ChainID      string
+	Level        int64
+	Timestamp    time.Time
+	MinBlockTime int64
+}
+
+// Request - parameters of script execution
+type Request struct {
+	Script     *ast.Script
+	Storage    *base.Node
+	Parameter  *base.Node
+	Entrypoint string
+	Environment
+}
+
+// ViewRequest - parameters of on-chain view execution
+type ViewRequest struct {
+	Name  string
+	Input *base.Node
+	Environment
+}
+
+// Result - result of script execution
+type Result struct {
+	Storage     *base.Node   `json:"storage"`
+	BigMapDiffs []BigMapDiff `json:"big_map_diffs,omitempty"`
+	Operations  []Operation  `json:"operations,omitempty"`
+	Events      []Event      `json:"events,omitempty"`
+	Trace       []TraceStep  `json:"trace,omitempty"`
+	Steps       int64        `json:"steps"`
+}
+
+// ViewResult - result of on-chain view execution
+type ViewResult struct {
+	Value *base.Node  `json:"value"`
+	Trace []TraceStep `json:"trace,omitempty"`
+	Steps int64       `json:"steps"`
+}
+
+// Operation - internal operation emitted by script
+type Operation struct {
+	Kind        string     `json:"kind"`
+	Source      string     `json:"source"`
+	Nonce       int64      `json:"nonce"`
+	Destination string     `json:"destination,omitempty"`
+	Amount      int64      `json:"amount,omitempty"`
+	Entrypoint  string     `json:"entrypoint,omitempty"`
+	Parameters  *base.Node `json:"parameters,omitempty"`
+	Delegate    string     `json:"delegate,omitempty"`
+	Script      *base.Node `json:"script,omitempty"`
+	Storage     *base.Node `json:"storage,omitempty"`
+	Tag         string     `json:"tag,omitempty"`
+	Type        *base.Node `json:"type,omitempty"`
+	Payload     *base.Node `json:"payload,omitempty"`
+}
+
+// Event - event emitted by `EMIT` instruction
+type Event struct {
+	Tag     string     `json:"tag,omitempty"`
+	Type    *base.Node `json:"type"`
+	Payload *base.Node `json:"payload"`
+}
+
+// BigMapDiff - changes of big map. Temporary big maps which are allocated during execution have negative pointers.
+type BigMapDiff struct {
+	Ptr       int64          `json:"ptr"`
+	Action    string         `json:"action"`
+	SourcePtr *int64         `json:"source_ptr,omitempty"`
+	KeyType   *base.Node     `json:"key_type,omitempty"`
+	ValueType *base.Node     `json:"value_type,omitempty"`
+	Updates   []BigMapUpdate `json:"updates,omitempty"`
+}
+
+// BigMapUpdate - update of big map key. Value is nil if key is removed.
+type BigMapUpdate struct {
+	KeyHash string     `json:"key_hash"`
+	Key     *base.Node `json:"key"`
+	Value   *base.Node `json:"value,omitempty"`
+}
+
+// TraceStep - state of stack after execution of instruction. Stack is printed from top to bottom.
+// Location is index of instruction node in script expression traversed in pre-order, as it's done by node.
+type TraceStep struct {
+	Location    int64        `json:"location"`
+	Instruction string       `json:"instruction"`
+	Stack       []*base.Node `json:"stack"`
+}
+
+// Interpreter - executes Michelson scripts without node
+type Interpreter struct {
+	chain    Chain
+	maxSteps int64
+	trace    bool
+}
+
+// Option -
+type Option func(*Interpreter)
+
+// WithTrace - collects stack after each instruction
+func WithTrace() Option {
+	return func(in *Interpreter) {
+		in.trace = true
+	}
+}
+
+// WithMaxSteps - sets limit of executed instructions. It protects from infinite loops since gas isn't calculated.
+func WithMaxSteps(steps int64) Option {
+	return func(in *Interpreter) {
+		if steps > 0 {
+			in.maxSteps = steps
+		}
+	}
+}
+
+// New - creates interpreter. `chain` is used to receive big map values and contracts which are used by script.
+func New(chain Chain, opts ...Option) *Interpreter {
+	in := &Interpreter{
+		chain:    chain,
+		maxSteps: DefaultMaxSteps,
+	}
+	for _, opt := range opts {
+		opt(in)
+	}
+	return in
+}
+
+// Run - executes script code with parameter and storage
+func (in *Interpreter) Run(ctx context.Context, req Request) (*Result, error) {
+	s := newSession(ctx, in)
+
+	var result *Result
+	err := s.safe(func() error {
+		m, err := s.newMachine(req.Script, req.Environment)
+		if err != nil {
+			return err
+		}
+
+		storageType, err := m.storageType()
+		if err != nil {
+			return err
+		}
+		storage, err := parseValue(storageType, req.Storage)
+		if err != nil {
+			return err
+		}
+		parameterType, parameter, err := m.entrypointParameter(req.Entrypoint, req.Parameter)
+		if err != nil {
+			return err
+		}
+
+		m.push(newType(consts.PAIR, parameterType, storageType), pairValue{left: parameter, right: storage})
+		if err := m.exec(nodeSeq(req.Script.Code)); err != nil {
+			return err
+		}
+
+		result, err = s.result(m, storageType, storage)
+		return err
+	})
+	if err != nil {
+		return &Result{Trace: s.trace, Steps: s.steps}, err
+	}
+	return result, nil
+}
+
+// RunView - executes on-chain view of contract `env.Self`
+func (in *Interpreter) RunView(ctx context.Context, req ViewRequest) (*ViewResult, error) {
+	s := newSession(ctx, in)
+	value, err := s.runView(req.Environment, req.Name, req.Input)
+	if err != nil {
+		return &ViewResult{Trace: s.trace, Steps: s.steps}, err
+	}
+	return &ViewResult{
+		Value: value,
+		Trace: s.trace,
+		Steps: s.steps,
+	}, nil
+}
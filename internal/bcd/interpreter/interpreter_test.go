@@ -0,0 +1,363 @@
+package interpreter
+
+import (
+	"context"
+	"encoding/hex"
+	"math/big"
+	"testing"
+
+	"github.com/baking-bad/bcdhub/internal/bcd/ast"
+	"github.com/baking-bad/bcdhub/internal/bcd/base"
+	"github.com/baking-bad/bcdhub/internal/bcd/consts"
+	"github.com/baking-bad/bcdhub/internal/bcd/types"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testSender   = "tz1burnburnburnburnburnburnburjAYjjX"
+	testContract = "KT1FgscaMyhxoVLbVirJVVKpRXgiSGtDG9Z4"
+)
+
+type testChain struct {
+	bigMaps   map[int64]map[string]string
+	contracts map[string]testContractState
+}
+
+type testContractState struct {
+	script  string
+	storage string
+}
+
+func (tc testChain) BigMapValue(ctx context.Context, ptr int64, keyHash string) (*base.Node, error) {
+	value, ok := tc.bigMaps[ptr][keyHash]
+	if !ok {
+		return nil, nil
+	}
+	return mustNode(value), nil
+}
+
+func (tc testChain) Contract(ctx context.Context, address string) (ContractState, error) {
+	state, ok := tc.contracts[address]
+	if !ok {
+		return ContractState{}, errors.Wrap(ErrUnknownContract, address)
+	}
+	script, err := ast.NewScript([]byte(state.script))
+	if err != nil {
+		return ContractState{}, err
+	}
+	return ContractState{
+		Script:  script,
+		Storage: mustNode(state.storage),
+	}, nil
+}
+
+func mustNode(data string) *base.Node {
+	var node base.Node
+	if err := json.UnmarshalFromString(data, &node); err != nil {
+		panic(err)
+	}
+	return &node
+}
+
+func mustKeyHash(typ *base.Node, value Value) string {
+	hash, err := keyHash(typ, value)
+	if err != nil {
+		panic(err)
+	}
+	return hash
+}
+
+func toJSON(t *testing.T, node *base.Node) string {
+	data, err := json.MarshalToString(node)
+	require.NoError(t, err)
+	return data
+}
+
+func run(t *testing.T, chain Chain, script, storage, parameter, entrypoint string, opts ...Option) (*Result, error) {
+	tree, err := ast.NewScript([]byte(script))
+	require.NoError(t, err)
+	return New(chain, opts...).Run(context.Background(), Request{
+		Script:     tree,
+		Storage:    mustNode(storage),
+		Parameter:  mustNode(parameter),
+		Entrypoint: entrypoint,
+		Environment: Environment{
+			Self:   consts.NullContract,
+			Source: testSender,
+			Sender: testSender,
+		},
+	})
+}
+
+func TestInterpreter_Run(t *testing.T) {
+	chain := testChain{
+		bigMaps: map[int64]map[string]string{
+			7: {
+				mustKeyHash(newType(consts.NAT), big.NewInt(1)): `{"string":"value"}`,
+			},
+		},
+		contracts: map[string]testContractState{
+			testContract: {
+				script:  `[{"prim":"parameter","args":[{"prim":"unit"}]},{"prim":"storage","args":[{"prim":"nat"}]},{"prim":"code","args":[[{"prim":"CDR"},{"prim":"NIL","args":[{"prim":"operation"}]},{"prim":"PAIR"}]]},{"prim":"view","args":[{"string":"add"},{"prim":"nat"},{"prim":"nat"},[{"prim":"UNPAIR"},{"prim":"ADD"}]]}]`,
+				storage: `{"int":"10"}`,
+			},
+		},
+	}
+
+	tests := []struct {
+		name        string
+		script      string
+		storage     string
+		parameter   string
+		entrypoint  string
+		wantStorage string
+		check       func(t *testing.T, result *Result)
+	}{
+		{
+			name:        "arithmetic",
+			script:      `[{"prim":"parameter","args":[{"prim":"nat"}]},{"prim":"storage","args":[{"prim":"nat"}]},{"prim":"code","args":[[{"prim":"UNPAIR"},{"prim":"ADD"},{"prim":"NIL","args":[{"prim":"operation"}]},{"prim":"PAIR"}]]}]`,
+			storage:     `{"int":"3"}`,
+			parameter:   `{"int":"2"}`,
+			wantStorage: `{"int":"5"}`,
+		},
+		{
+			name:        "entrypoint",
+			script:      `[{"prim":"parameter","args":[{"prim":"or","args":[{"prim":"int","annots":["%inc"]},{"prim":"int","annots":["%dec"]}]}]},{"prim":"storage","args":[{"prim":"int"}]},{"prim":"code","args":[[{"prim":"UNPAIR"},{"prim":"IF_LEFT","args":[[{"prim":"ADD"}],[{"prim":"SWAP"},{"prim":"SUB"}]]},{"prim":"NIL","args":[{"prim":"operation"}]},{"prim":"PAIR"}]]}]`,
+			storage:     `{"int":"10"}`,
+			parameter:   `{"int":"12"}`,
+			entrypoint:  "dec",
+			wantStorage: `{"int":"-2"}`,
+		},
+		{
+			name:        "euclidean division",
+			script:      `[{"prim":"parameter","args":[{"prim":"int"}]},{"prim":"storage","args":[{"prim":"pair","args":[{"prim":"int"},{"prim":"nat"}]}]},{"prim":"code","args":[[{"prim":"CAR"},{"prim":"PUSH","args":[{"prim":"int"},{"int":"3"}]},{"prim":"SWAP"},{"prim":"EDIV"},{"prim":"IF_NONE","args":[[{"prim":"PUSH","args":[{"prim":"string"},{"string":"division by zero"}]},{"prim":"FAILWITH"}],[]]},{"prim":"NIL","args":[{"prim":"operation"}]},{"prim":"PAIR"}]]}]`,
+			storage:     `{"prim":"Pair","args":[{"int":"0"},{"int":"0"}]}`,
+			parameter:   `{"int":"-7"}`,
+			wantStorage: `{"prim":"Pair","args":[{"int":"-3"},{"int":"2"}]}`,
+		},
+		{
+			name:        "recursive lambda",
+			script:      `[{"prim":"parameter","args":[{"prim":"nat"}]},{"prim":"storage","args":[{"prim":"nat"}]},{"prim":"code","args":[[{"prim":"CAR"},{"prim":"LAMBDA_REC","args":[{"prim":"nat"},{"prim":"nat"},[{"prim":"DUP"},{"prim":"INT"},{"prim":"EQ"},{"prim":"IF","args":[[{"prim":"DROP","args":[{"int":"2"}]},{"prim":"PUSH","args":[{"prim":"nat"},{"int":"1"}]}],[{"prim":"DUP"},{"prim":"PUSH","args":[{"prim":"int"},{"int":"1"}]},{"prim":"SWAP"},{"prim":"SUB"},{"prim":"ABS"},{"prim":"DIG","args":[{"int":"2"}]},{"prim":"SWAP"},{"prim":"EXEC"},{"prim":"MUL"}]]}]]},{"prim":"SWAP"},{"prim":"EXEC"},{"prim":"NIL","args":[{"prim":"operation"}]},{"prim":"PAIR"}]]}]`,
+			storage:     `{"int":"0"}`,
+			parameter:   `{"int":"5"}`,
+			wantStorage: `{"int":"120"}`,
+		},
+		{
+			name:        "partial application",
+			script:      `[{"prim":"parameter","args":[{"prim":"int"}]},{"prim":"storage","args":[{"prim":"int"}]},{"prim":"code","args":[[{"prim":"UNPAIR"},{"prim":"LAMBDA","args":[{"prim":"pair","args":[{"prim":"int"},{"prim":"int"}]},{"prim":"int"},[{"prim":"UNPAIR"},{"prim":"MUL"}]]},{"prim":"SWAP"},{"prim":"APPLY"},{"prim":"SWAP"},{"prim":"EXEC"},{"prim":"NIL","args":[{"prim":"operation"}]},{"prim":"PAIR"}]]}]`,
+			storage:     `{"int":"6"}`,
+			parameter:   `{"int":"7"}`,
+			wantStorage: `{"int":"42"}`,
+		},
+		{
+			name:        "map and iter",
+			script:      `[{"prim":"parameter","args":[{"prim":"list","args":[{"prim":"nat"}]}]},{"prim":"storage","args":[{"prim":"pair","args":[{"prim":"list","args":[{"prim":"nat"}]},{"prim":"set","args":[{"prim":"nat"}]}]}]},{"prim":"code","args":[[{"prim":"CAR"},{"prim":"MAP","args":[[{"prim":"PUSH","args":[{"prim":"nat"},{"int":"2"}]},{"prim":"MUL"}]]},{"prim":"EMPTY_SET","args":[{"prim":"nat"}]},{"prim":"DUP","args":[{"int":"2"}]},{"prim":"ITER","args":[[{"prim":"PUSH","args":[{"prim":"bool"},{"prim":"True"}]},{"prim":"SWAP"},{"prim":"UPDATE"}]]},{"prim":"SWAP"},{"prim":"PAIR"},{"prim":"NIL","args":[{"prim":"operation"}]},{"prim":"PAIR"}]]}]`,
+			storage:     `{"prim":"Pair","args":[[],[]]}`,
+			parameter:   `[{"int":"3"},{"int":"1"},{"int":"3"}]`,
+			wantStorage: `{"prim":"Pair","args":[[{"int":"6"},{"int":"2"},{"int":"6"}],[{"int":"2"},{"int":"6"}]]}`,
+		},
+		{
+			name:        "pack and unpack",
+			script:      `[{"prim":"parameter","args":[{"prim":"pair","args":[{"prim":"address"},{"prim":"nat"}]}]},{"prim":"storage","args":[{"prim":"option","args":[{"prim":"pair","args":[{"prim":"address"},{"prim":"nat"}]}]}]},{"prim":"code","args":[[{"prim":"CAR"},{"prim":"PACK"},{"prim":"UNPACK","args":[{"prim":"pair","args":[{"prim":"address"},{"prim":"nat"}]}]},{"prim":"NIL","args":[{"prim":"operation"}]},{"prim":"PAIR"}]]}]`,
+			storage:     `{"prim":"None"}`,
+			parameter:   `{"prim":"Pair","args":[{"string":"` + testContract + `%transfer"},{"int":"100"}]}`,
+			wantStorage: `{"prim":"Some","args":[{"prim":"Pair","args":[{"string":"` + testContract + `%transfer"},{"int":"100"}]}]}`,
+		},
+		{
+			name:        "big map get from chain",
+			script:      `[{"prim":"parameter","args":[{"prim":"nat"}]},{"prim":"storage","args":[{"prim":"pair","args":[{"prim":"big_map","args":[{"prim":"nat"},{"prim":"string"}]},{"prim":"string"}]}]},{"prim":"code","args":[[{"prim":"UNPAIR"},{"prim":"DIP","args":[[{"prim":"UNPAIR"},{"prim":"DUP"}]]},{"prim":"GET"},{"prim":"IF_NONE","args":[[{"prim":"PUSH","args":[{"prim":"string"},{"string":""}]}],[]]},{"prim":"SWAP"},{"prim":"DIP","args":[[{"prim":"SWAP"},{"prim":"DROP"}]]},{"prim":"PAIR"},{"prim":"NIL","args":[{"prim":"operation"}]},{"prim":"PAIR"}]]}]`,
+			storage:     `{"prim":"Pair","args":[{"int":"7"},{"string":""}]}`,
+			parameter:   `{"int":"1"}`,
+			wantStorage: `{"prim":"Pair","args":[{"int":"7"},{"string":"value"}]}`,
+			check: func(t *testing.T, result *Result) {
+				require.Empty(t, result.BigMapDiffs)
+			},
+		},
+		{
+			name:        "big map update",
+			script:      `[{"prim":"parameter","args":[{"prim":"pair","args":[{"prim":"nat"},{"prim":"string"}]}]},{"prim":"storage","args":[{"prim":"big_map","args":[{"prim":"nat"},{"prim":"string"}]}]},{"prim":"code","args":[[{"prim":"UNPAIR"},{"prim":"UNPAIR"},{"prim":"DIP","args":[[{"prim":"SOME"}]]},{"prim":"UPDATE"},{"prim":"NIL","args":[{"prim":"operation"}]},{"prim":"PAIR"}]]}]`,
+			storage:     `{"int":"7"}`,
+			parameter:   `{"prim":"Pair","args":[{"int":"2"},{"string":"new"}]}`,
+			wantStorage: `{"int":"7"}`,
+			check: func(t *testing.T, result *Result) {
+				require.Len(t, result.BigMapDiffs, 1)
+				diff := result.BigMapDiffs[0]
+				require.EqualValues(t, 7, diff.Ptr)
+				require.Equal(t, BigMapActionUpdate, diff.Action)
+				require.Len(t, diff.Updates, 1)
+
+				keyHash, err := ast.BigMapKeyHash(&base.Node{IntValue: types.NewBigInt(2)})
+				require.NoError(t, err)
+				require.Equal(t, keyHash, diff.Updates[0].KeyHash)
+				require.Equal(t, `{"string":"new"}`, toJSON(t, diff.Updates[0].Value))
+			},
+		},
+		{
+			name:        "big map alloc",
+			script:      `[{"prim":"parameter","args":[{"prim":"unit"}]},{"prim":"storage","args":[{"prim":"big_map","args":[{"prim":"nat"},{"prim":"nat"}]}]},{"prim":"code","args":[[{"prim":"CDR"},{"prim":"PUSH","args":[{"prim":"nat"},{"int":"1"}]},{"prim":"SOME"},{"prim":"PUSH","args":[{"prim":"nat"},{"int":"2"}]},{"prim":"UPDATE"},{"prim":"NIL","args":[{"prim":"operation"}]},{"prim":"PAIR"}]]}]`,
+			storage:     `[]`,
+			parameter:   `{"prim":"Unit"}`,
+			wantStorage: `{"int":"-1"}`,
+			check: func(t *testing.T, result *Result) {
+				require.Len(t, result.BigMapDiffs, 1)
+				diff := result.BigMapDiffs[0]
+				require.EqualValues(t, -1, diff.Ptr)
+				require.Equal(t, BigMapActionAlloc, diff.Action)
+				require.Equal(t, `{"prim":"nat"}`, toJSON(t, diff.KeyType))
+				require.Len(t, diff.Updates, 1)
+				require.Equal(t, `{"int":"2"}`, toJSON(t, diff.Updates[0].Key))
+				require.Equal(t, `{"int":"1"}`, toJSON(t, diff.Updates[0].Value))
+			},
+		},
+		{
+			name:        "operations and events",
+			script:      `[{"prim":"parameter","args":[{"prim":"unit"}]},{"prim":"storage","args":[{"prim":"unit"}]},{"prim":"code","args":[[{"prim":"DROP"},{"prim":"NIL","args":[{"prim":"operation"}]},{"prim":"PUSH","args":[{"prim":"nat"},{"int":"5"}]},{"prim":"EMIT","args":[{"prim":"nat"}],"annots":["%minted"]},{"prim":"CONS"},{"prim":"SENDER"},{"prim":"CONTRACT","args":[{"prim":"unit"}]},{"prim":"IF_NONE","args":[[{"prim":"PUSH","args":[{"prim":"string"},{"string":"no contract"}]},{"prim":"FAILWITH"}],[{"prim":"PUSH","args":[{"prim":"mutez"},{"int":"10"}]},{"prim":"UNIT"},{"prim":"TRANSFER_TOKENS"},{"prim":"CONS"}]]},{"prim":"UNIT"},{"prim":"SWAP"},{"prim":"PAIR"}]]}]`,
+			storage:     `{"prim":"Unit"}`,
+			parameter:   `{"prim":"Unit"}`,
+			wantStorage: `{"prim":"Unit"}`,
+			check: func(t *testing.T, result *Result) {
+				require.Len(t, result.Operations, 2)
+				require.Equal(t, OperationKindTransaction, result.Operations[0].Kind)
+				require.Equal(t, testSender, result.Operations[0].Destination)
+				require.EqualValues(t, 10, result.Operations[0].Amount)
+				require.EqualValues(t, 1, result.Operations[0].Nonce)
+				require.Equal(t, OperationKindEvent, result.Operations[1].Kind)
+
+				require.Len(t, result.Events, 1)
+				require.Equal(t, "minted", result.Events[0].Tag)
+				require.Equal(t, `{"int":"5"}`, toJSON(t, result.Events[0].Payload))
+			},
+		},
+		{
+			name:        "tickets",
+			script:      `[{"prim":"parameter","args":[{"prim":"unit"}]},{"prim":"storage","args":[{"prim":"option","args":[{"prim":"ticket","args":[{"prim":"string"}]}]}]},{"prim":"code","args":[[{"prim":"DROP"},{"prim":"PUSH","args":[{"prim":"nat"},{"int":"10"}]},{"prim":"PUSH","args":[{"prim":"string"},{"string":"token"}]},{"prim":"TICKET"},{"prim":"IF_NONE","args":[[{"prim":"PUSH","args":[{"prim":"string"},{"string":"zero"}]},{"prim":"FAILWITH"}],[]]},{"prim":"PUSH","args":[{"prim":"pair","args":[{"prim":"nat"},{"prim":"nat"}]},{"prim":"Pair","args":[{"int":"3"},{"int":"7"}]}]},{"prim":"SWAP"},{"prim":"SPLIT_TICKET"},{"prim":"IF_NONE","args":[[{"prim":"PUSH","args":[{"prim":"string"},{"string":"split"}]},{"prim":"FAILWITH"}],[{"prim":"CDR"}]]},{"prim":"SOME"},{"prim":"NIL","args":[{"prim":"operation"}]},{"prim":"PAIR"}]]}]`,
+			storage:     `{"prim":"None"}`,
+			parameter:   `{"prim":"Unit"}`,
+			wantStorage: `{"prim":"Some","args":[{"prim":"Pair","args":[{"string":"` + consts.NullContract + `"},{"prim":"Pair","args":[{"string":"token"},{"int":"7"}]}]}]}`,
+		},
+		{
+			name:        "view",
+			script:      `[{"prim":"parameter","args":[{"prim":"nat"}]},{"prim":"storage","args":[{"prim":"nat"}]},{"prim":"code","args":[[{"prim":"CAR"},{"prim":"PUSH","args":[{"prim":"address"},{"string":"` + testContract + `"}]},{"prim":"SWAP"},{"prim":"VIEW","args":[{"string":"add"},{"prim":"nat"}]},{"prim":"IF_NONE","args":[[{"prim":"PUSH","args":[{"prim":"nat"},{"int":"0"}]}],[]]},{"prim":"NIL","args":[{"prim":"operation"}]},{"prim":"PAIR"}]]}]`,
+			storage:     `{"int":"0"}`,
+			parameter:   `{"int":"5"}`,
+			wantStorage: `{"int":"15"}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := run(t, chain, tt.script, tt.storage, tt.parameter, tt.entrypoint)
+			require.NoError(t, err)
+			require.Equal(t, tt.wantStorage, toJSON(t, result.Storage))
+			if tt.check != nil {
+				tt.check(t, result)
+			}
+		})
+	}
+}
+
+func TestInterpreter_RunFailWith(t *testing.T) {
+	script := `[{"prim":"parameter","args":[{"prim":"string"}]},{"prim":"storage","args":[{"prim":"unit"}]},{"prim":"code","args":[[{"prim":"CAR"},{"prim":"FAILWITH"}]]}]`
+	result, err := run(t, testChain{}, script, `{"prim":"Unit"}`, `{"string":"oops"}`, "", WithTrace())
+	require.Error(t, err)
+
+	var failWith *FailWithError
+	require.ErrorAs(t, err, &failWith)
+	require.EqualValues(t, 8, failWith.Location)
+	require.Equal(t, `{"string":"oops"}`, toJSON(t, failWith.With))
+
+	require.Len(t, result.Trace, 1)
+	require.Equal(t, "CAR", result.Trace[0].Instruction)
+	require.EqualValues(t, 7, result.Trace[0].Location)
+}
+
+func TestInterpreter_RunTrace(t *testing.T) {
+	script := `[{"prim":"parameter","args":[{"prim":"nat"}]},{"prim":"storage","args":[{"prim":"nat"}]},{"prim":"code","args":[[{"prim":"UNPAIR"},{"prim":"ADD"},{"prim":"NIL","args":[{"prim":"operation"}]},{"prim":"PAIR"}]]}]`
+	result, err := run(t, testChain{}, script, `{"int":"3"}`, `{"int":"2"}`, "", WithTrace())
+	require.NoError(t, err)
+	require.EqualValues(t, 4, result.Steps)
+	require.Len(t, result.Trace, 4)
+
+	require.Equal(t, "UNPAIR", result.Trace[0].Instruction)
+	require.Len(t, result.Trace[0].Stack, 2)
+	require.Equal(t, `{"int":"2"}`, toJSON(t, result.Trace[0].Stack[0]))
+	require.Equal(t, "ADD", result.Trace[1].Instruction)
+	require.Len(t, result.Trace[1].Stack, 1)
+	require.Equal(t, `{"int":"5"}`, toJSON(t, result.Trace[1].Stack[0]))
+}
+
+func TestInterpreter_RunStepsLimit(t *testing.T) {
+	script := `[{"prim":"parameter","args":[{"prim":"unit"}]},{"prim":"storage","args":[{"prim":"unit"}]},{"prim":"code","args":[[{"prim":"PUSH","args":[{"prim":"bool"},{"prim":"True"}]},{"prim":"LOOP","args":[[{"prim":"PUSH","args":[{"prim":"bool"},{"prim":"True"}]}]]}]]}]`
+	_, err := run(t, testChain{}, script, `{"prim":"Unit"}`, `{"prim":"Unit"}`, "", WithMaxSteps(100))
+	require.ErrorIs(t, err, ErrStepsLimit)
+}
+
+func TestInterpreter_RunView(t *testing.T) {
+	chain := testChain{
+		contracts: map[string]testContractState{
+			testContract: {
+				script:  `[{"prim":"parameter","args":[{"prim":"unit"}]},{"prim":"storage","args":[{"prim":"nat"}]},{"prim":"code","args":[[{"prim":"CDR"},{"prim":"NIL","args":[{"prim":"operation"}]},{"prim":"PAIR"}]]},{"prim":"view","args":[{"string":"add"},{"prim":"nat"},{"prim":"nat"},[{"prim":"UNPAIR"},{"prim":"ADD"}]]}]`,
+				storage: `{"int":"10"}`,
+			},
+		},
+	}
+
+	result, err := New(chain).RunView(context.Background(), ViewRequest{
+		Name:  "add",
+		Input: mustNode(`{"int":"5"}`),
+		Environment: Environment{
+			Self:   testContract,
+			Sender: testSender,
+			Source: testSender,
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, `{"int":"15"}`, toJSON(t, result.Value))
+
+	_, err = New(chain).RunView(context.Background(), ViewRequest{
+		Name:        "unknown",
+		Environment: Environment{Self: testContract},
+	})
+	require.ErrorIs(t, err, ErrUnknownView)
+}
+
+func TestPack(t *testing.T) {
+	tests := []struct {
+		name  string
+		typ   *base.Node
+		value Value
+		want  string
+	}{
+		{
+			name:  "nat",
+			typ:   newType(consts.NAT),
+			value: big.NewInt(1),
+			want:  "050001",
+		},
+		{
+			name:  "pair",
+			typ:   newType(consts.PAIR, newType(consts.STRING), newType(consts.INT)),
+			value: pairValue{left: "a", right: big.NewInt(-1)},
+			want:  "0507070100000001610041",
+		},
+		{
+			name:  "address",
+			typ:   newType(consts.ADDRESS),
+			value: testSender,
+			want:  "050a000000160000" + "b28066369a8ed09ba9d3d47f19598440266013f0",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := pack(tt.typ, tt.value)
+			require.NoError(t, err)
+			require.Equal(t, tt.want, hex.EncodeToString(data))
+
+			unpacked := unpack(tt.typ, data)
+			require.True(t, unpacked.some)
+			res, err := compareValues(tt.typ, tt.value, unpacked.value)
+			require.NoError(t, err)
+			require.Zero(t, res)
+		})
+	}
+}
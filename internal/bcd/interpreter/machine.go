@@ -0,0 +1,466 @@
+package interpreter
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/baking-bad/bcdhub/internal/bcd/ast"
+	"github.com/baking-bad/bcdhub/internal/bcd/base"
+	"github.com/baking-bad/bcdhub/internal/bcd/consts"
+	"github.com/pkg/errors"
+)
+
+// checkContextPeriod - count of steps between checks of context cancellation
+const checkContextPeriod = 4096
+
+// session - state which is shared by all machines of one execution: main script, lambdas and called views
+type session struct {
+	ctx context.Context
+	in  *Interpreter
+
+	steps        int64
+	trace        []TraceStep
+	nonce        int64
+	lastBigMapID int64
+
+	locations    map[*base.Node]int64
+	indexed      map[*ast.Script]struct{}
+	types        map[*base.Node]*base.Node
+	bigMapValues map[string]*base.Node
+	contracts    map[string]*ContractState
+}
+
+func newSession(ctx context.Context, in *Interpreter) *session {
+	return &session{
+		ctx:          ctx,
+		in:           in,
+		locations:    make(map[*base.Node]int64),
+		indexed:      make(map[*ast.Script]struct{}),
+		types:        make(map[*base.Node]*base.Node),
+		bigMapValues: make(map[string]*base.Node),
+		contracts:    make(map[string]*ContractState),
+	}
+}
+
+// machine - stack machine which executes code of one script
+type machine struct {
+	s         *session
+	env       Environment
+	script    *ast.Script
+	parameter *base.Node
+	stack     []item
+}
+
+func (s *session) newMachine(script *ast.Script, env Environment) (*machine, error) {
+	if script == nil || len(script.Code) == 0 || len(script.Parameter) == 0 || len(script.Storage) == 0 {
+		return nil, errors.Wrap(consts.ErrInvalidType, "script must contain parameter, storage and code")
+	}
+	s.index(script)
+	return &machine{
+		s:         s,
+		env:       env,
+		script:    script,
+		parameter: nodeSeq(script.Parameter),
+	}, nil
+}
+
+// index - computes locations of script nodes. Locations are indexes of nodes in pre-order traversal of script
+// where sections go in canonical order: parameter, storage, code and views.
+func (s *session) index(script *ast.Script) {
+	if _, ok := s.indexed[script]; ok {
+		return
+	}
+	s.indexed[script] = struct{}{}
+
+	location := int64(1)
+	sections := []ast.UntypedAST{script.Parameter, script.Storage, script.Code}
+	sections = append(sections, script.Views...)
+	for _, section := range sections {
+		location++
+		for i := range section {
+			location = s.indexNode(section[i], location)
+		}
+	}
+}
+
+func (s *session) indexNode(node *base.Node, location int64) int64 {
+	if _, ok := s.locations[node]; !ok {
+		s.locations[node] = location
+	}
+	location++
+	for i := range node.Args {
+		location = s.indexNode(node.Args[i], location)
+	}
+	return location
+}
+
+func (s *session) location(node *base.Node) int64 {
+	if location, ok := s.locations[node]; ok {
+		return location
+	}
+	return -1
+}
+
+// normalize - returns normalized type. Results are cached since the same type nodes of script are used many times.
+func (s *session) normalize(node *base.Node) (*base.Node, error) {
+	if typ, ok := s.types[node]; ok {
+		return typ, nil
+	}
+	typ, err := normalizeType(node)
+	if err != nil {
+		return nil, err
+	}
+	s.types[node] = typ
+	return typ, nil
+}
+
+func (s *session) newBigMapID() int64 {
+	s.lastBigMapID--
+	return s.lastBigMapID
+}
+
+func (s *session) nextNonce() int64 {
+	nonce := s.nonce
+	s.nonce++
+	return nonce
+}
+
+func (s *session) bigMapValue(ptr int64, keyHash string) (*base.Node, error) {
+	if ptr < 0 || s.in.chain == nil {
+		return nil, nil
+	}
+	key := fmt.Sprintf("%d:%s", ptr, keyHash)
+	if value, ok := s.bigMapValues[key]; ok {
+		return value, nil
+	}
+	value, err := s.in.chain.BigMapValue(s.ctx, ptr, keyHash)
+	if err != nil {
+		return nil, err
+	}
+	s.bigMapValues[key] = value
+	return value, nil
+}
+
+// contract - returns state of contract. It returns `ErrUnknownContract` if contract isn't found.
+func (s *session) contract(address string) (*ContractState, error) {
+	if state, ok := s.contracts[address]; ok {
+		if state == nil {
+			return nil, errors.Wrap(ErrUnknownContract, address)
+		}
+		return state, nil
+	}
+	if s.in.chain == nil {
+		return nil, errors.Wrap(ErrUnknownContract, address)
+	}
+	state, err := s.in.chain.Contract(s.ctx, address)
+	if err != nil {
+		if errors.Is(err, ErrUnknownContract) {
+			s.contracts[address] = nil
+		}
+		return nil, err
+	}
+	s.contracts[address] = &state
+	return &state, nil
+}
+
+// safe - converts panics which are caused by ill-typed scripts to errors
+func (s *session) safe(f func() error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = errors.Wrapf(ErrInvalidStack, "%v", r)
+		}
+	}()
+	return f()
+}
+
+func (m *machine) storageType() (*base.Node, error) {
+	return m.s.normalize(nodeSeq(m.script.Storage))
+}
+
+// entrypointParameter - returns full parameter type and value of parameter wrapped to `Left` and `Right` according to entrypoint
+func (m *machine) entrypointParameter(entrypoint string, node *base.Node) (*base.Node, Value, error) {
+	parameterType, err := m.s.normalize(m.parameter)
+	if err != nil {
+		return nil, nil, err
+	}
+	path, typ, ok := findEntrypoint(m.parameter, entrypoint)
+	if !ok {
+		return nil, nil, errors.Wrap(ErrUnknownEntrypoint, entrypoint)
+	}
+	entrypointType, err := m.s.normalize(typ)
+	if err != nil {
+		return nil, nil, err
+	}
+	if node == nil {
+		node = &base.Node{Prim: consts.Unit}
+	}
+	value, err := parseValue(entrypointType, node)
+	if err != nil {
+		return nil, nil, err
+	}
+	for i := len(path) - 1; i >= 0; i-- {
+		value = orValue{right: path[i], value: value}
+	}
+	return parameterType, value, nil
+}
+
+func (m *machine) push(typ *base.Node, value Value) {
+	m.stack = append(m.stack, item{typ: typ, val: value})
+}
+
+func (m *machine) pop() (item, error) {
+	if len(m.stack) == 0 {
+		return item{}, ErrStackUnderflow
+	}
+	top := m.stack[len(m.stack)-1]
+	m.stack = m.stack[:len(m.stack)-1]
+	return top, nil
+}
+
+func (m *machine) popN(count int) ([]item, error) {
+	if count < 0 || len(m.stack) < count {
+		return nil, ErrStackUnderflow
+	}
+	items := make([]item, count)
+	for i := range items {
+		items[i] = m.stack[len(m.stack)-1-i]
+	}
+	m.stack = m.stack[:len(m.stack)-count]
+	return items, nil
+}
+
+// peek - returns stack item at `depth` where 0 is top of stack
+func (m *machine) peek(depth int) (*item, error) {
+	if depth < 0 || len(m.stack) <= depth {
+		return nil, ErrStackUnderflow
+	}
+	return &m.stack[len(m.stack)-1-depth], nil
+}
+
+// exec - executes instruction or sequence of instructions
+func (m *machine) exec(code *base.Node) error {
+	if code.Prim == consts.PrimArray {
+		for i := range code.Args {
+			if err := m.exec(code.Args[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	m.s.steps++
+	if m.s.steps > m.s.in.maxSteps {
+		return ErrStepsLimit
+	}
+	if m.s.steps%checkContextPeriod == 0 {
+		if err := m.s.ctx.Err(); err != nil {
+			return err
+		}
+	}
+
+	if err := m.instruction(code); err != nil {
+		var (
+			failWith *FailWithError
+			runtime  *RuntimeError
+		)
+		if errors.As(err, &failWith) || errors.As(err, &runtime) || errors.Is(err, ErrStepsLimit) || m.s.ctx.Err() != nil {
+			return err
+		}
+		return &RuntimeError{
+			Location:    m.s.location(code),
+			Instruction: code.Prim,
+			Err:         err,
+		}
+	}
+
+	if m.s.in.trace {
+		m.s.trace = append(m.s.trace, TraceStep{
+			Location:    m.s.location(code),
+			Instruction: code.Prim,
+			Stack:       m.snapshot(),
+		})
+	}
+	return nil
+}
+
+// snapshot - returns stack from top to bottom in readable form
+func (m *machine) snapshot() []*base.Node {
+	stack := make([]*base.Node, 0, len(m.stack))
+	for i := len(m.stack) - 1; i >= 0; i-- {
+		node, err := unparse(m.stack[i].typ, m.stack[i].val, false)
+		if err != nil {
+			node = stringNode(fmt.Sprintf("<%s>", m.stack[i].typ.Prim))
+		}
+		stack = append(stack, node)
+	}
+	return stack
+}
+
+// callLambda - executes lambda on separate stack. Recursive lambda receives itself as second stack item.
+func (m *machine) callLambda(lambdaType *base.Node, lambda *lambdaValue, arg Value) (Value, error) {
+	saved := m.stack
+	defer func() {
+		m.stack = saved
+	}()
+
+	m.stack = make([]item, 0, 2)
+	if lambda.recursive {
+		m.push(lambdaType, lambda)
+	}
+	m.push(lambda.parameter, arg)
+	if err := m.exec(lambda.code); err != nil {
+		return nil, err
+	}
+	if len(m.stack) != 1 {
+		return nil, errors.Wrapf(ErrInvalidStack, "lambda returned %d stack items", len(m.stack))
+	}
+	return m.stack[0].val, nil
+}
+
+// scriptView - on-chain view with normalized types
+type scriptView struct {
+	input  *base.Node
+	output *base.Node
+	code   *base.Node
+}
+
+func (s *session) findView(script *ast.Script, name string) (*scriptView, error) {
+	for _, view := range script.Views {
+		if len(view) != 4 || view[0].StringValue == nil || *view[0].StringValue != name {
+			continue
+		}
+		input, err := s.normalize(view[1])
+		if err != nil {
+			return nil, err
+		}
+		output, err := s.normalize(view[2])
+		if err != nil {
+			return nil, err
+		}
+		return &scriptView{
+			input:  input,
+			output: output,
+			code:   view[3],
+		}, nil
+	}
+	return nil, errors.Wrap(ErrUnknownView, name)
+}
+
+// execView - executes view of contract `address`. Input of view code is pair of argument and current storage of contract.
+func (s *session) execView(address string, state *ContractState, view *scriptView, env Environment, input Value) (Value, error) {
+	m, err := s.newMachine(state.Script, Environment{
+		Self:         address,
+		Source:       env.Source,
+		Sender:       env.Sender,
+		Balance:      state.Balance,
+		ChainID:      env.ChainID,
+		Level:        env.Level,
+		Timestamp:    env.Timestamp,
+		MinBlockTime: env.MinBlockTime,
+	})
+	if err != nil {
+		return nil, err
+	}
+	storageType, err := m.storageType()
+	if err != nil {
+		return nil, err
+	}
+	storage, err := parseValue(storageType, state.Storage)
+	if err != nil {
+		return nil, err
+	}
+
+	m.push(newType(consts.PAIR, view.input, storageType), pairValue{left: input, right: storage})
+	if err := m.exec(view.code); err != nil {
+		return nil, err
+	}
+	if len(m.stack) != 1 {
+		return nil, errors.Wrapf(ErrInvalidStack, "view returned %d stack items", len(m.stack))
+	}
+	return m.stack[0].val, nil
+}
+
+func (s *session) runView(env Environment, name string, input *base.Node) (*base.Node, error) {
+	var result *base.Node
+	err := s.safe(func() error {
+		state, err := s.contract(env.Self)
+		if err != nil {
+			return err
+		}
+		view, err := s.findView(state.Script, name)
+		if err != nil {
+			return err
+		}
+		if input == nil {
+			input = &base.Node{Prim: consts.Unit}
+		}
+		arg, err := parseValue(view.input, input)
+		if err != nil {
+			return err
+		}
+		value, err := s.execView(env.Self, state, view, env, arg)
+		if err != nil {
+			return err
+		}
+		result, err = unparse(view.output, value, false)
+		return err
+	})
+	return result, err
+}
+
+// result - converts final stack of script to result: operations, storage and big map diffs
+func (s *session) result(m *machine, storageType *base.Node, initial Value) (*Result, error) {
+	if len(m.stack) != 1 {
+		return nil, errors.Wrapf(ErrInvalidStack, "script returned %d stack items", len(m.stack))
+	}
+	pair, ok := m.stack[0].val.(pairValue)
+	if !ok {
+		return nil, errors.Wrap(ErrInvalidStack, "script must return pair of operations and storage")
+	}
+	list, ok := pair.left.(listValue)
+	if !ok {
+		return nil, errors.Wrap(ErrInvalidStack, "script must return list of operations")
+	}
+
+	storage, diffs, err := s.finalizeBigMaps(storageType, initial, pair.right)
+	if err != nil {
+		return nil, err
+	}
+	storageNode, err := unparse(storageType, storage, false)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Result{
+		Storage:     storageNode,
+		BigMapDiffs: diffs,
+		Trace:       s.trace,
+		Steps:       s.steps,
+	}
+	for i := range list {
+		op, ok := list[i].(*operationValue)
+		if !ok {
+			return nil, errors.Wrap(ErrInvalidStack, "script must return list of operations")
+		}
+		result.Operations = append(result.Operations, op.Operation)
+		if op.Kind == OperationKindEvent {
+			result.Events = append(result.Events, Event{
+				Tag:     op.Tag,
+				Type:    op.Type,
+				Payload: op.Payload,
+			})
+		}
+	}
+	return result, nil
+}
+
+// nodeSeq - returns sequence node of untyped tree
+func nodeSeq(tree ast.UntypedAST) *base.Node {
+	if len(tree) == 1 {
+		return tree[0]
+	}
+	return &base.Node{
+		Prim: consts.PrimArray,
+		Args: tree,
+	}
+}
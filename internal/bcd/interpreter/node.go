@@ -0,0 +1,293 @@
+package interpreter
+
+import (
+	"context"
+	stdJSON "encoding/json"
+
+	"github.com/baking-bad/bcdhub/internal/bcd/ast"
+	"github.com/baking-bad/bcdhub/internal/bcd/base"
+	"github.com/baking-bad/bcdhub/internal/bcd/consts"
+	"github.com/baking-bad/bcdhub/internal/bcd/tezerrors"
+	"github.com/baking-bad/bcdhub/internal/noderpc"
+	"github.com/pkg/errors"
+)
+
+// protocolName - protocol part of error identifiers which are returned by interpreter
+const protocolName = "proto.interpreter"
+
+// zeroAddress - default source of execution
+const zeroAddress = "tz1burnburnburnburnburnburnburjAYjjX"
+
+// Node - node RPC where `RunCode` and `RunScriptView` are executed by interpreter. Other methods are delegated to fallback node, so it may be nil only if they aren't called.
+type Node struct {
+	noderpc.INode
+
+	interpreter *Interpreter
+	env         Environment
+}
+
+// NewNode - creates node. `env` is used as default execution context: self address, balance, level, timestamp.
+func NewNode(chain Chain, fallback noderpc.INode, env Environment, opts ...Option) *Node {
+	return &Node{
+		INode:       fallback,
+		interpreter: New(chain, opts...),
+		env:         env,
+	}
+}
+
+// RunCode - executes script as `run_code` RPC does: `source` is sender of transaction and `payer` is its source.
+func (n *Node) RunCode(ctx context.Context, script, storage, input []byte, chainID, source, payer, entrypoint, proto string, amount, gas int64) (noderpc.RunCodeResponse, error) {
+	var response noderpc.RunCodeResponse
+
+	tree, err := ast.NewScript(script)
+	if err != nil {
+		return response, err
+	}
+	var storageNode, inputNode base.Node
+	if err := json.Unmarshal(storage, &storageNode); err != nil {
+		return response, err
+	}
+	if err := json.Unmarshal(input, &inputNode); err != nil {
+		return response, err
+	}
+
+	env := n.environment(chainID, source, payer, amount)
+	result, err := n.interpreter.Run(ctx, Request{
+		Script:      tree,
+		Storage:     &storageNode,
+		Parameter:   &inputNode,
+		Entrypoint:  entrypoint,
+		Environment: env,
+	})
+	if result != nil {
+		response.Trace, err = convertTrace(result.Trace, err)
+	}
+	if err != nil {
+		return response, convertError(err)
+	}
+
+	if response.Storage, err = json.Marshal(result.Storage); err != nil {
+		return response, err
+	}
+	if response.Operations, err = convertOperations(result.Operations); err != nil {
+		return response, err
+	}
+	if response.LazyStorageDiffs, err = convertBigMapDiffs(result.BigMapDiffs); err != nil {
+		return response, err
+	}
+	return response, nil
+}
+
+// RunScriptView - executes on-chain view of contract
+func (n *Node) RunScriptView(ctx context.Context, request noderpc.RunScriptViewRequest) ([]byte, error) {
+	var input *base.Node
+	if len(request.Input) > 0 {
+		input = new(base.Node)
+		if err := json.Unmarshal(request.Input, input); err != nil {
+			return nil, err
+		}
+	}
+
+	env := n.environment(request.ChainID, request.Source, request.Payer, 0)
+	env.Self = request.Contract
+	result, err := n.interpreter.RunView(ctx, ViewRequest{
+		Name:        request.View,
+		Input:       input,
+		Environment: env,
+	})
+	if err != nil {
+		return nil, convertError(err)
+	}
+	return json.Marshal(result.Value)
+}
+
+func (n *Node) environment(chainID, sender, source string, amount int64) Environment {
+	env := n.env
+	if env.Self == "" {
+		env.Self = consts.NullContract
+	}
+	if chainID != "" {
+		env.ChainID = chainID
+	}
+	if sender != "" {
+		env.Sender = sender
+	}
+	if source != "" {
+		env.Source = source
+	}
+	switch {
+	case env.Sender == "" && env.Source == "":
+		env.Sender, env.Source = zeroAddress, zeroAddress
+	case env.Sender == "":
+		env.Sender = env.Source
+	case env.Source == "":
+		env.Source = env.Sender
+	}
+	env.Amount = amount
+	if env.Balance < amount {
+		env.Balance = amount
+	}
+	return env
+}
+
+// convertError - converts errors of script to errors of node, so they are handled as errors of `run_code` RPC
+func convertError(err error) error {
+	var (
+		failWith *FailWithError
+		runtime  *RuntimeError
+	)
+	var items []map[string]any
+	switch {
+	case errors.As(err, &failWith):
+		items = append(items, map[string]any{
+			"kind":     "temporary",
+			"id":       protocolName + ".michelson_v1.script_rejected",
+			"location": failWith.Location,
+			"with":     failWith.With,
+		})
+	case errors.As(err, &runtime):
+		items = append(items, map[string]any{
+			"kind":     "temporary",
+			"id":       protocolName + ".michelson_v1.runtime_error",
+			"location": runtime.Location,
+		}, map[string]any{
+			"kind":     "temporary",
+			"id":       protocolName + ".interpreter." + runtime.Instruction,
+			"title":    "Interpreter error",
+			"descr":    runtime.Error(),
+			"location": runtime.Location,
+		})
+	default:
+		return err
+	}
+
+	raw, marshalErr := json.Marshal(items)
+	if marshalErr != nil {
+		return err
+	}
+	if _, parseErr := tezerrors.ParseArray(raw); parseErr != nil {
+		return err
+	}
+	return noderpc.InvalidNodeResponse{
+		Errors: []noderpc.RunCodeError{{ID: items[0]["id"].(string), Kind: "temporary"}},
+		Raw:    raw,
+	}
+}
+
+// convertTrace - converts trace to node format. Trace is returned with error of execution.
+func convertTrace(trace []TraceStep, err error) ([]noderpc.TraceStep, error) {
+	if len(trace) == 0 {
+		return nil, err
+	}
+	result := make([]noderpc.TraceStep, len(trace))
+	for i := range trace {
+		result[i] = noderpc.TraceStep{
+			Location:    trace[i].Location,
+			Instruction: trace[i].Instruction,
+			Stack:       make([]stdJSON.RawMessage, len(trace[i].Stack)),
+		}
+		for j := range trace[i].Stack {
+			data, marshalErr := json.Marshal(trace[i].Stack[j])
+			if marshalErr != nil {
+				return nil, marshalErr
+			}
+			result[i].Stack[j] = data
+		}
+	}
+	return result, err
+}
+
+func convertOperations(operations []Operation) ([]noderpc.Operation, error) {
+	result := make([]noderpc.Operation, len(operations))
+	for i := range operations {
+		op := operations[i]
+		nonce := op.Nonce
+		result[i] = noderpc.Operation{
+			Kind:     op.Kind,
+			Source:   op.Source,
+			Nonce:    &nonce,
+			Delegate: op.Delegate,
+		}
+
+		var err error
+		switch op.Kind {
+		case OperationKindTransaction:
+			destination, amount := op.Destination, op.Amount
+			result[i].Destination = &destination
+			result[i].Amount = &amount
+			result[i].Parameters, err = json.Marshal(map[string]any{
+				"entrypoint": op.Entrypoint,
+				"value":      op.Parameters,
+			})
+		case OperationKindOrigination:
+			balance := op.Amount
+			result[i].Balance = &balance
+			result[i].Script, err = json.Marshal(map[string]any{
+				"code":    op.Script,
+				"storage": op.Storage,
+			})
+		case OperationKindEvent:
+			if op.Tag != "" {
+				tag := op.Tag
+				result[i].Tag = &tag
+			}
+			if result[i].Type, err = json.Marshal(op.Type); err != nil {
+				return nil, err
+			}
+			result[i].Payload, err = json.Marshal(op.Payload)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+func convertBigMapDiffs(diffs []BigMapDiff) ([]noderpc.LazyStorageDiff, error) {
+	result := make([]noderpc.LazyStorageDiff, 0, len(diffs))
+	for i := range diffs {
+		diff := &noderpc.LazyBigMapDiff{
+			Action:  diffs[i].Action,
+			Source:  diffs[i].SourcePtr,
+			Updates: make([]noderpc.LazyBigMapUpdate, len(diffs[i].Updates)),
+		}
+		var err error
+		if diffs[i].KeyType != nil {
+			if diff.KeyType, err = json.Marshal(diffs[i].KeyType); err != nil {
+				return nil, err
+			}
+		}
+		if diffs[i].ValueType != nil {
+			if diff.ValueType, err = json.Marshal(diffs[i].ValueType); err != nil {
+				return nil, err
+			}
+		}
+		for j, update := range diffs[i].Updates {
+			diff.Updates[j].KeyHash = update.KeyHash
+			if diff.Updates[j].Key, err = json.Marshal(update.Key); err != nil {
+				return nil, err
+			}
+			if update.Value != nil {
+				if diff.Updates[j].Value, err = json.Marshal(update.Value); err != nil {
+					return nil, err
+				}
+			}
+		}
+
+		raw, err := json.Marshal(diff)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, noderpc.LazyStorageDiff{
+			LazyStorageDiffKind: noderpc.LazyStorageDiffKind{
+				Kind: "big_map",
+				ID:   diffs[i].Ptr,
+				Raw:  raw,
+			},
+			Diff: &noderpc.Diff{
+				BigMap: diff,
+			},
+		})
+	}
+	return result, nil
+}
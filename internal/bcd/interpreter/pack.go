@@ -0,0 +1,53 @@
+package interpreter
+
+import (
+	"math/big"
+
+	"github.com/baking-bad/bcdhub/internal/bcd/base"
+	"github.com/baking-bad/bcdhub/internal/bcd/encoding"
+	"github.com/baking-bad/bcdhub/internal/bcd/forge"
+	"golang.org/x/crypto/blake2b"
+)
+
+// maxMutez - maximal value of mutez: 2^63 - 1
+var maxMutez = new(big.Int).SetUint64(1<<63 - 1)
+
+func bigInt(value int64) *big.Int {
+	return big.NewInt(value)
+}
+
+// pack - serializes value as `PACK` instruction does
+func pack(typ *base.Node, value Value) ([]byte, error) {
+	node, err := unparse(typ, value, true)
+	if err != nil {
+		return nil, err
+	}
+	data, err := forge.Forge(node)
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte{forge.PackPrefixByte}, data...), nil
+}
+
+// unpack - deserializes value as `UNPACK` instruction does. It returns `None` if data can't be unpacked to `typ`.
+func unpack(typ *base.Node, data []byte) optionValue {
+	nodes, err := forge.Unpack(data)
+	if err != nil || len(nodes) != 1 {
+		return none()
+	}
+	value, err := parseValue(typ, nodes[0])
+	if err != nil {
+		return none()
+	}
+	return some(value)
+}
+
+// keyHash - returns hash of big map key: `expr` base58 encoded blake2b hash of packed key
+func keyHash(typ *base.Node, value Value) (string, error) {
+	data, err := pack(typ, value)
+	if err != nil {
+		return "", err
+	}
+	hash := blake2b.Sum256(data)
+	return encoding.EncodeBase58(hash[:], []byte(encoding.PrefixScriptExpr))
+}
@@ -0,0 +1,386 @@
+package interpreter
+
+import (
+	"encoding/hex"
+	"math/big"
+	"time"
+
+	"github.com/baking-bad/bcdhub/internal/bcd/base"
+	"github.com/baking-bad/bcdhub/internal/bcd/consts"
+	"github.com/baking-bad/bcdhub/internal/bcd/encoding"
+	"github.com/pkg/errors"
+)
+
+// parseValue - converts Micheline value of normalized type to runtime value. Both readable and optimized forms are accepted.
+func parseValue(typ, node *base.Node) (Value, error) {
+	if node == nil {
+		return nil, errors.Wrapf(ErrInvalidValue, "empty value of %s", typ.Prim)
+	}
+
+	switch typ.Prim {
+	case consts.INT, consts.NAT, consts.MUTEZ:
+		if node.IntValue == nil {
+			return nil, invalidValue(typ, node)
+		}
+		value := new(big.Int).Set(node.IntValue.Int)
+		if typ.Prim != consts.INT && value.Sign() < 0 {
+			return nil, invalidValue(typ, node)
+		}
+		if typ.Prim == consts.MUTEZ && value.Cmp(maxMutez) > 0 {
+			return nil, errors.Wrap(ErrOverflow, "mutez")
+		}
+		return value, nil
+
+	case consts.TIMESTAMP:
+		switch {
+		case node.IntValue != nil:
+			return new(big.Int).Set(node.IntValue.Int), nil
+		case node.StringValue != nil:
+			ts, err := time.Parse(time.RFC3339, *node.StringValue)
+			if err != nil {
+				return nil, invalidValue(typ, node)
+			}
+			return big.NewInt(ts.Unix()), nil
+		}
+
+	case consts.STRING:
+		if node.StringValue != nil {
+			return *node.StringValue, nil
+		}
+
+	case consts.BYTES, consts.BLS12381FR, consts.BLS12381G1, consts.BLS12381G2, consts.CHEST, consts.CHESTKEY:
+		if node.BytesValue != nil {
+			data, err := hex.DecodeString(*node.BytesValue)
+			if err != nil {
+				return nil, invalidValue(typ, node)
+			}
+			return data, nil
+		}
+
+	case consts.BOOL:
+		switch node.Prim {
+		case consts.True:
+			return true, nil
+		case consts.False:
+			return false, nil
+		}
+
+	case consts.UNIT:
+		if node.Prim == consts.Unit {
+			return unitValue{}, nil
+		}
+
+	case consts.ADDRESS, consts.CONTRACT, consts.KEYHASH, consts.KEY, consts.SIGNATURE, consts.CHAINID, consts.TXROLLUPL2ADDRESS:
+		return parseDomainValue(typ, node)
+
+	case consts.PAIR:
+		if err := mustArgs(typ, 2); err != nil {
+			return nil, err
+		}
+		args, ok := pairArgs(node)
+		if !ok {
+			break
+		}
+		left, err := parseValue(typ.Args[0], args[0])
+		if err != nil {
+			return nil, err
+		}
+		rightNode := args[1]
+		if len(args) > 2 {
+			rightNode = &base.Node{Prim: consts.Pair, Args: args[1:]}
+		}
+		right, err := parseValue(typ.Args[1], rightNode)
+		if err != nil {
+			return nil, err
+		}
+		return pairValue{left: left, right: right}, nil
+
+	case consts.OPTION:
+		if err := mustArgs(typ, 1); err != nil {
+			return nil, err
+		}
+		switch node.Prim {
+		case consts.None:
+			return none(), nil
+		case consts.Some:
+			if len(node.Args) != 1 {
+				break
+			}
+			value, err := parseValue(typ.Args[0], node.Args[0])
+			if err != nil {
+				return nil, err
+			}
+			return some(value), nil
+		}
+
+	case consts.OR:
+		if err := mustArgs(typ, 2); err != nil {
+			return nil, err
+		}
+		if len(node.Args) != 1 {
+			break
+		}
+		switch node.Prim {
+		case consts.Left:
+			value, err := parseValue(typ.Args[0], node.Args[0])
+			if err != nil {
+				return nil, err
+			}
+			return orValue{value: value}, nil
+		case consts.Right:
+			value, err := parseValue(typ.Args[1], node.Args[0])
+			if err != nil {
+				return nil, err
+			}
+			return orValue{right: true, value: value}, nil
+		}
+
+	case consts.LIST:
+		if err := mustArgs(typ, 1); err != nil {
+			return nil, err
+		}
+		if node.Prim != consts.PrimArray {
+			break
+		}
+		list := make(listValue, len(node.Args))
+		for i := range node.Args {
+			value, err := parseValue(typ.Args[0], node.Args[i])
+			if err != nil {
+				return nil, err
+			}
+			list[i] = value
+		}
+		return list, nil
+
+	case consts.SET:
+		if err := mustArgs(typ, 1); err != nil {
+			return nil, err
+		}
+		if node.Prim != consts.PrimArray {
+			break
+		}
+		set := new(setValue)
+		for i := range node.Args {
+			value, err := parseValue(typ.Args[0], node.Args[i])
+			if err != nil {
+				return nil, err
+			}
+			set, err = set.update(value, true, comparator(typ.Args[0]))
+			if err != nil {
+				return nil, err
+			}
+		}
+		return set, nil
+
+	case consts.MAP:
+		if err := mustArgs(typ, 2); err != nil {
+			return nil, err
+		}
+		if node.Prim != consts.PrimArray {
+			break
+		}
+		return parseMap(typ, node)
+
+	case consts.BIGMAP:
+		if err := mustArgs(typ, 2); err != nil {
+			return nil, err
+		}
+		switch {
+		case node.IntValue != nil:
+			ptr := node.IntValue.Int64()
+			return &bigMapValue{
+				ptr:       &ptr,
+				keyType:   typ.Args[0],
+				valueType: typ.Args[1],
+				entries:   make(map[string]bigMapEntry),
+			}, nil
+		case node.Prim == consts.PrimArray:
+			m, err := parseMap(typ, node)
+			if err != nil {
+				return nil, err
+			}
+			return newBigMapFromMap(typ.Args[0], typ.Args[1], m)
+		}
+
+	case consts.LAMBDA:
+		if err := mustArgs(typ, 2); err != nil {
+			return nil, err
+		}
+		lambda := &lambdaValue{
+			code:      node,
+			parameter: typ.Args[0],
+			result:    typ.Args[1],
+		}
+		switch {
+		case node.Prim == consts.PrimArray:
+			return lambda, nil
+		case node.Prim == primLambdaRec && len(node.Args) == 1:
+			lambda.code = node.Args[0]
+			lambda.recursive = true
+			return lambda, nil
+		}
+
+	case consts.TICKET:
+		if err := mustArgs(typ, 1); err != nil {
+			return nil, err
+		}
+		value, err := parseValue(ticketPayloadType(typ.Args[0]), node)
+		if err != nil {
+			return nil, err
+		}
+		payload := value.(pairValue)
+		inner := payload.right.(pairValue)
+		return &ticketValue{
+			ticketer:    payload.left.(string),
+			contentType: typ.Args[0],
+			content:     inner.left,
+			amount:      inner.right.(*big.Int),
+		}, nil
+
+	case unknownType:
+		return nil, errors.Wrap(ErrUnsupportedType, "value of unknown type")
+
+	default:
+		return nil, errors.Wrap(ErrUnsupportedType, typ.Prim)
+	}
+
+	return nil, invalidValue(typ, node)
+}
+
+func parseMap(typ, node *base.Node) (*mapValue, error) {
+	m := new(mapValue)
+	cmp := comparator(typ.Args[0])
+	for i := range node.Args {
+		elt := node.Args[i]
+		if elt.Prim != consts.Elt || len(elt.Args) != 2 {
+			return nil, invalidValue(typ, node)
+		}
+		key, err := parseValue(typ.Args[0], elt.Args[0])
+		if err != nil {
+			return nil, err
+		}
+		value, err := parseValue(typ.Args[1], elt.Args[1])
+		if err != nil {
+			return nil, err
+		}
+		m, err = m.update(key, value, cmp)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+// pairArgs - returns arguments of `Pair` or sequence which is a comb of pairs
+func pairArgs(node *base.Node) ([]*base.Node, bool) {
+	if (node.Prim == consts.Pair || node.Prim == consts.PrimArray) && len(node.Args) >= 2 {
+		return node.Args, true
+	}
+	return nil, false
+}
+
+// ticketPayloadType - type of ticket representation `Pair ticketer content amount`
+func ticketPayloadType(content *base.Node) *base.Node {
+	return newType(consts.PAIR,
+		newType(consts.ADDRESS),
+		newType(consts.PAIR, content, newType(consts.NAT)),
+	)
+}
+
+func parseDomainValue(typ, node *base.Node) (Value, error) {
+	if node.StringValue != nil {
+		return *node.StringValue, nil
+	}
+	if node.BytesValue == nil {
+		return nil, invalidValue(typ, node)
+	}
+	data, err := hex.DecodeString(*node.BytesValue)
+	if err != nil {
+		return nil, invalidValue(typ, node)
+	}
+
+	var value string
+	switch typ.Prim {
+	case consts.ADDRESS, consts.CONTRACT:
+		value, err = decodeAddress(data)
+	case consts.KEYHASH:
+		value, err = decodeKeyHash(data)
+	case consts.KEY:
+		value, err = decodeKey(data)
+	case consts.SIGNATURE:
+		value, err = encoding.EncodeBase58(data, []byte(encoding.PrefixGenericSignature))
+	case consts.CHAINID:
+		value, err = encoding.EncodeBase58(data, []byte(encoding.PrefixChainID))
+	default:
+		return nil, errors.Wrap(ErrUnsupportedType, typ.Prim)
+	}
+	if err != nil {
+		return nil, invalidValue(typ, node)
+	}
+	return value, nil
+}
+
+var keyHashPrefixes = []string{
+	encoding.PrefixPublicKeyTZ1,
+	encoding.PrefixPublicKeyTZ2,
+	encoding.PrefixPublicKeyTZ3,
+	encoding.PrefixPublicKeyTZ4,
+}
+
+var keyPrefixes = []string{
+	encoding.PrefixED25519PublicKey,
+	encoding.PrefixSecp256k1PublicKey,
+	encoding.PrefixP256PublicKey,
+}
+
+// decodeKeyHash - decodes binary key hash: tag of curve and 20 bytes of hash
+func decodeKeyHash(data []byte) (string, error) {
+	if len(data) != 21 || int(data[0]) >= len(keyHashPrefixes) {
+		return "", errors.Wrap(consts.ErrInvalidAddress, hex.EncodeToString(data))
+	}
+	return encoding.EncodeBase58(data[1:], []byte(keyHashPrefixes[data[0]]))
+}
+
+// decodeKey - decodes binary public key: tag of curve and key bytes
+func decodeKey(data []byte) (string, error) {
+	if len(data) < 2 || int(data[0]) >= len(keyPrefixes) {
+		return "", errors.Wrapf(ErrUnsupportedType, "key %s", hex.EncodeToString(data))
+	}
+	return encoding.EncodeBase58(data[1:], []byte(keyPrefixes[data[0]]))
+}
+
+// decodeAddress - decodes binary address with optional entrypoint
+func decodeAddress(data []byte) (string, error) {
+	if len(data) < 22 {
+		return "", errors.Wrap(consts.ErrInvalidAddress, hex.EncodeToString(data))
+	}
+
+	var (
+		address string
+		err     error
+	)
+	switch data[0] {
+	case 0:
+		address, err = decodeKeyHash(data[1:22])
+	case 1:
+		address, err = encoding.EncodeBase58(data[1:21], []byte(encoding.PrefixPublicKeyKT1))
+	case 2:
+		address, err = encoding.EncodeBase58(data[1:21], []byte(encoding.PrefixPublicKeyTxr1))
+	case 3:
+		address, err = encoding.EncodeBase58(data[1:21], []byte(encoding.PrefixOriginatedSmartRollup))
+	default:
+		return "", errors.Wrap(consts.ErrInvalidAddress, hex.EncodeToString(data))
+	}
+	if err != nil {
+		return "", err
+	}
+	return joinContract(address, string(data[22:])), nil
+}
+
+func invalidValue(typ, node *base.Node) error {
+	value, err := json.MarshalToString(node)
+	if err != nil {
+		value = node.String()
+	}
+	return errors.Wrapf(ErrInvalidValue, "%s is expected: %s", typ.Prim, value)
+}
@@ -0,0 +1,193 @@
+package interpreter
+
+import (
+	"strings"
+
+	"github.com/baking-bad/bcdhub/internal/bcd/base"
+	"github.com/baking-bad/bcdhub/internal/bcd/consts"
+	"github.com/pkg/errors"
+)
+
+// unknownType - type of elements of empty collections which types can't be inferred without type checking (e.g. result of `MAP` over empty list).
+// It's equal to any other type and it's replaced by concrete type when it's known.
+const unknownType = "unknown"
+
+func newType(prim string, args ...*base.Node) *base.Node {
+	return &base.Node{
+		Prim: prim,
+		Args: args,
+	}
+}
+
+// normalizeType - removes annotations and converts combs to right-nested pairs
+func normalizeType(node *base.Node) (*base.Node, error) {
+	if node == nil {
+		return nil, errors.Wrap(consts.ErrInvalidType, "empty type")
+	}
+	if node.Prim == consts.PrimArray {
+		if len(node.Args) != 1 {
+			return nil, errors.Wrapf(consts.ErrInvalidType, "sequence of %d types", len(node.Args))
+		}
+		return normalizeType(node.Args[0])
+	}
+	if node.Prim == "" {
+		// memo size of sapling types
+		return &base.Node{IntValue: node.IntValue}, nil
+	}
+
+	typ := &base.Node{
+		Prim: node.Prim,
+		Args: make([]*base.Node, len(node.Args)),
+	}
+	for i := range node.Args {
+		arg, err := normalizeType(node.Args[i])
+		if err != nil {
+			return nil, err
+		}
+		typ.Args[i] = arg
+	}
+
+	if typ.Prim == consts.PAIR && len(typ.Args) > 2 {
+		typ.Args = []*base.Node{
+			typ.Args[0],
+			newType(consts.PAIR, typ.Args[1:]...),
+		}
+		right, err := normalizeType(typ.Args[1])
+		if err != nil {
+			return nil, err
+		}
+		typ.Args[1] = right
+	}
+	return typ, nil
+}
+
+func mustArgs(typ *base.Node, count int) error {
+	if len(typ.Args) != count {
+		return errors.Wrapf(consts.ErrInvalidArgsCount, "%s: expected %d, got %d", typ.Prim, count, len(typ.Args))
+	}
+	return nil
+}
+
+// equalTypes - compares normalized types. Unknown type is equal to any type.
+func equalTypes(a, b *base.Node) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	if a.Prim == unknownType || b.Prim == unknownType {
+		return true
+	}
+	if a.Prim != b.Prim || len(a.Args) != len(b.Args) {
+		return false
+	}
+	if a.Prim == "" {
+		return a.IntValue != nil && b.IntValue != nil && a.IntValue.Cmp(b.IntValue.Int) == 0
+	}
+	for i := range a.Args {
+		if !equalTypes(a.Args[i], b.Args[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// mergeTypes - returns `a` where unknown types are replaced by corresponding parts of `b`
+func mergeTypes(a, b *base.Node) *base.Node {
+	if a == nil || b == nil {
+		return a
+	}
+	if a.Prim == unknownType {
+		return b
+	}
+	if len(a.Args) != len(b.Args) {
+		return a
+	}
+	var merged *base.Node
+	for i := range a.Args {
+		arg := mergeTypes(a.Args[i], b.Args[i])
+		if arg == a.Args[i] {
+			continue
+		}
+		if merged == nil {
+			merged = &base.Node{Prim: a.Prim, Args: append([]*base.Node(nil), a.Args...)}
+		}
+		merged.Args[i] = arg
+	}
+	if merged == nil {
+		return a
+	}
+	return merged
+}
+
+func isComparable(typ *base.Node) bool {
+	switch typ.Prim {
+	case consts.UNIT, consts.NEVER, consts.BOOL, consts.INT, consts.NAT, consts.STRING, consts.BYTES,
+		consts.MUTEZ, consts.KEYHASH, consts.KEY, consts.SIGNATURE, consts.TIMESTAMP, consts.ADDRESS,
+		consts.CHAINID, unknownType:
+		return true
+	case consts.PAIR, consts.OR:
+		return len(typ.Args) == 2 && isComparable(typ.Args[0]) && isComparable(typ.Args[1])
+	case consts.OPTION:
+		return len(typ.Args) == 1 && isComparable(typ.Args[0])
+	default:
+		return false
+	}
+}
+
+// fieldAnnotation - returns field annotation of node without prefix
+func fieldAnnotation(node *base.Node) string {
+	for _, annot := range node.Annots {
+		if len(annot) > 1 && annot[0] == consts.AnnotPrefixFieldName {
+			return annot[1:]
+		}
+	}
+	return ""
+}
+
+// findEntrypoint - finds entrypoint in parameter type with annotations. It returns path to entrypoint in `or` tree (true is right branch) and its type.
+func findEntrypoint(parameter *base.Node, name string) ([]bool, *base.Node, bool) {
+	if parameter.Prim == consts.PrimArray && len(parameter.Args) == 1 {
+		parameter = parameter.Args[0]
+	}
+	if name == "" {
+		name = consts.DefaultEntrypoint
+	}
+	if path, typ, ok := searchEntrypoint(parameter, name, nil); ok {
+		return path, typ, true
+	}
+	if name == consts.DefaultEntrypoint {
+		return nil, parameter, true
+	}
+	return nil, nil, false
+}
+
+func searchEntrypoint(node *base.Node, name string, path []bool) ([]bool, *base.Node, bool) {
+	if fieldAnnotation(node) == name {
+		return path, node, true
+	}
+	if node.Prim != consts.OR || len(node.Args) != 2 {
+		return nil, nil, false
+	}
+	for i, right := range []bool{false, true} {
+		branch := append(append([]bool(nil), path...), right)
+		if p, typ, ok := searchEntrypoint(node.Args[i], name, branch); ok {
+			return p, typ, true
+		}
+	}
+	return nil, nil, false
+}
+
+// splitContract - splits contract string `address%entrypoint` to address and entrypoint
+func splitContract(value string) (string, string) {
+	address, entrypoint, _ := strings.Cut(value, "%")
+	if entrypoint == "" {
+		entrypoint = consts.DefaultEntrypoint
+	}
+	return address, entrypoint
+}
+
+func joinContract(address, entrypoint string) string {
+	if entrypoint == "" || entrypoint == consts.DefaultEntrypoint {
+		return address
+	}
+	return address + "%" + entrypoint
+}
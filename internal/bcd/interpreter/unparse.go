@@ -0,0 +1,246 @@
+package interpreter
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"sort"
+	"time"
+
+	"github.com/baking-bad/bcdhub/internal/bcd/base"
+	"github.com/baking-bad/bcdhub/internal/bcd/consts"
+	"github.com/baking-bad/bcdhub/internal/bcd/encoding"
+	"github.com/baking-bad/bcdhub/internal/bcd/types"
+	"github.com/pkg/errors"
+)
+
+const primLambdaRec = "Lambda_rec"
+
+var (
+	minReadableTimestamp = time.Date(0, 1, 1, 0, 0, 0, 0, time.UTC).Unix()
+	maxReadableTimestamp = time.Date(9999, 12, 31, 23, 59, 59, 0, time.UTC).Unix()
+)
+
+// unparse - converts runtime value to Micheline. Optimized form is the one which is used by `PACK`: domain types are bytes and timestamps are integers.
+func unparse(typ *base.Node, value Value, optimized bool) (*base.Node, error) {
+	switch typ.Prim {
+	case consts.INT, consts.NAT, consts.MUTEZ:
+		return intNode(value.(*big.Int)), nil
+
+	case consts.TIMESTAMP:
+		ts := value.(*big.Int)
+		if optimized || !ts.IsInt64() || ts.Int64() < minReadableTimestamp || ts.Int64() > maxReadableTimestamp {
+			return intNode(ts), nil
+		}
+		return stringNode(time.Unix(ts.Int64(), 0).UTC().Format(time.RFC3339)), nil
+
+	case consts.STRING:
+		return stringNode(value.(string)), nil
+
+	case consts.BYTES, consts.BLS12381FR, consts.BLS12381G1, consts.BLS12381G2, consts.CHEST, consts.CHESTKEY:
+		return bytesNode(value.([]byte)), nil
+
+	case consts.BOOL:
+		if value.(bool) {
+			return &base.Node{Prim: consts.True}, nil
+		}
+		return &base.Node{Prim: consts.False}, nil
+
+	case consts.UNIT:
+		return &base.Node{Prim: consts.Unit}, nil
+
+	case consts.ADDRESS, consts.CONTRACT, consts.KEYHASH, consts.KEY, consts.SIGNATURE, consts.CHAINID, consts.TXROLLUPL2ADDRESS:
+		if !optimized {
+			return stringNode(value.(string)), nil
+		}
+		data, err := encodeDomainValue(typ.Prim, value.(string))
+		if err != nil {
+			return nil, err
+		}
+		return bytesNode(data), nil
+
+	case consts.PAIR:
+		pair := value.(pairValue)
+		left, err := unparse(typ.Args[0], pair.left, optimized)
+		if err != nil {
+			return nil, err
+		}
+		right, err := unparse(typ.Args[1], pair.right, optimized)
+		if err != nil {
+			return nil, err
+		}
+		return &base.Node{Prim: consts.Pair, Args: []*base.Node{left, right}}, nil
+
+	case consts.OPTION:
+		option := value.(optionValue)
+		if !option.some {
+			return &base.Node{Prim: consts.None}, nil
+		}
+		arg, err := unparse(typ.Args[0], option.value, optimized)
+		if err != nil {
+			return nil, err
+		}
+		return &base.Node{Prim: consts.Some, Args: []*base.Node{arg}}, nil
+
+	case consts.OR:
+		or := value.(orValue)
+		prim, argType := consts.Left, typ.Args[0]
+		if or.right {
+			prim, argType = consts.Right, typ.Args[1]
+		}
+		arg, err := unparse(argType, or.value, optimized)
+		if err != nil {
+			return nil, err
+		}
+		return &base.Node{Prim: prim, Args: []*base.Node{arg}}, nil
+
+	case consts.LIST:
+		return unparseSequence(typ.Args[0], value.(listValue), optimized)
+
+	case consts.SET:
+		return unparseSequence(typ.Args[0], value.(*setValue).items, optimized)
+
+	case consts.MAP:
+		m := value.(*mapValue)
+		return unparseMap(typ.Args[0], typ.Args[1], m.keys, m.values, optimized)
+
+	case consts.BIGMAP:
+		return value.(*bigMapValue).unparse(optimized)
+
+	case consts.LAMBDA:
+		lambda := value.(*lambdaValue)
+		if lambda.recursive {
+			return &base.Node{Prim: primLambdaRec, Args: []*base.Node{lambda.code}}, nil
+		}
+		return lambda.code, nil
+
+	case consts.TICKET:
+		ticket := value.(*ticketValue)
+		return unparse(ticketPayloadType(typ.Args[0]), newPair(ticket.ticketer, ticket.content, ticket.amount), optimized)
+
+	case consts.OPERATION:
+		// operations can't be represented in Micheline, so only description is returned for traces
+		op := value.(*operationValue)
+		return stringNode(op.String()), nil
+
+	case unknownType:
+		return unparseUnknown(value, optimized)
+	}
+
+	return nil, errors.Wrap(ErrUnsupportedType, typ.Prim)
+}
+
+// unparseUnknown - unparses value which type is unknown. Only empty collections may have such types.
+func unparseUnknown(value Value, optimized bool) (*base.Node, error) {
+	switch v := value.(type) {
+	case listValue:
+		if len(v) == 0 {
+			return &base.Node{Prim: consts.PrimArray, Args: []*base.Node{}}, nil
+		}
+	case *setValue:
+		if len(v.items) == 0 {
+			return &base.Node{Prim: consts.PrimArray, Args: []*base.Node{}}, nil
+		}
+	case *mapValue:
+		if len(v.keys) == 0 {
+			return &base.Node{Prim: consts.PrimArray, Args: []*base.Node{}}, nil
+		}
+	}
+	return nil, errors.Wrap(ErrUnsupportedType, "value of unknown type")
+}
+
+func unparseSequence(typ *base.Node, items []Value, optimized bool) (*base.Node, error) {
+	node := &base.Node{
+		Prim: consts.PrimArray,
+		Args: make([]*base.Node, len(items)),
+	}
+	for i := range items {
+		arg, err := unparse(typ, items[i], optimized)
+		if err != nil {
+			return nil, err
+		}
+		node.Args[i] = arg
+	}
+	return node, nil
+}
+
+func unparseMap(keyType, valueType *base.Node, keys, values []Value, optimized bool) (*base.Node, error) {
+	node := &base.Node{
+		Prim: consts.PrimArray,
+		Args: make([]*base.Node, len(keys)),
+	}
+	for i := range keys {
+		key, err := unparse(keyType, keys[i], optimized)
+		if err != nil {
+			return nil, err
+		}
+		value, err := unparse(valueType, values[i], optimized)
+		if err != nil {
+			return nil, err
+		}
+		node.Args[i] = &base.Node{Prim: consts.Elt, Args: []*base.Node{key, value}}
+	}
+	return node, nil
+}
+
+func encodeDomainValue(prim, value string) ([]byte, error) {
+	switch prim {
+	case consts.ADDRESS, consts.CONTRACT:
+		address, entrypoint := splitContract(value)
+		data, err := encodeAddress(address)
+		if err != nil {
+			return nil, err
+		}
+		if entrypoint != consts.DefaultEntrypoint {
+			data = append(data, entrypoint...)
+		}
+		return data, nil
+	case consts.KEYHASH:
+		return encodeKeyHash(value)
+	case consts.KEY:
+		return encodeKey(value)
+	case consts.SIGNATURE, consts.CHAINID:
+		return encoding.DecodeBase58(value)
+	default:
+		return nil, errors.Wrap(ErrUnsupportedType, prim)
+	}
+}
+
+func intNode(value *big.Int) *base.Node {
+	return &base.Node{IntValue: &types.BigInt{Int: new(big.Int).Set(value)}}
+}
+
+func stringNode(value string) *base.Node {
+	return &base.Node{StringValue: &value}
+}
+
+func bytesNode(value []byte) *base.Node {
+	s := hex.EncodeToString(value)
+	return &base.Node{BytesValue: &s}
+}
+
+// String - short description of operation for traces
+func (op *operationValue) String() string {
+	switch op.Kind {
+	case OperationKindTransaction:
+		return fmt.Sprintf("transaction of %d mutez to %s", op.Amount, joinContract(op.Destination, op.Entrypoint))
+	case OperationKindOrigination:
+		return fmt.Sprintf("origination of %s with %d mutez", op.Destination, op.Amount)
+	case OperationKindDelegation:
+		return fmt.Sprintf("delegation to %s", op.Delegate)
+	case OperationKindEvent:
+		return fmt.Sprintf("event %s", op.Tag)
+	default:
+		return op.Kind
+	}
+}
+
+// sortedKeys - returns sorted keys of map for deterministic output
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
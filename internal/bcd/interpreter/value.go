@@ -0,0 +1,179 @@
+package interpreter
+
+import (
+	"math/big"
+	"sort"
+
+	"github.com/baking-bad/bcdhub/internal/bcd/base"
+)
+
+// Value - runtime value of Michelson. Its type is known from stack item, so the same Go types are used for different Michelson types:
+//   - *big.Int: int, nat, mutez, timestamp (unix seconds)
+//   - string: string, address, contract (address with entrypoint), key, key_hash, signature, chain_id
+//   - []byte: bytes, bls12_381_fr, bls12_381_g1, bls12_381_g2, chest, chest_key
+//   - bool, unitValue, pairValue, optionValue, orValue, listValue, *setValue, *mapValue, *bigMapValue, *lambdaValue, *ticketValue, *operationValue
+type Value interface{}
+
+type unitValue struct{}
+
+type pairValue struct {
+	left  Value
+	right Value
+}
+
+type optionValue struct {
+	some  bool
+	value Value
+}
+
+type orValue struct {
+	right bool
+	value Value
+}
+
+type listValue []Value
+
+// setValue - immutable set which items are sorted by `compare`
+type setValue struct {
+	items []Value
+}
+
+// mapValue - immutable map which keys are sorted by `compare`
+type mapValue struct {
+	keys   []Value
+	values []Value
+}
+
+type lambdaValue struct {
+	code      *base.Node
+	recursive bool
+	parameter *base.Node
+	result    *base.Node
+}
+
+type ticketValue struct {
+	ticketer    string
+	contentType *base.Node
+	content     Value
+	amount      *big.Int
+}
+
+// operationValue - operation emitted by script
+type operationValue struct {
+	Operation
+}
+
+// item - stack item
+type item struct {
+	typ *base.Node
+	val Value
+}
+
+type compareFunc func(a, b Value) (int, error)
+
+func (s *setValue) find(key Value, cmp compareFunc) (int, bool, error) {
+	var err error
+	idx := sort.Search(len(s.items), func(i int) bool {
+		if err != nil {
+			return true
+		}
+		var res int
+		res, err = cmp(s.items[i], key)
+		return res >= 0
+	})
+	if err != nil || idx == len(s.items) {
+		return idx, false, err
+	}
+	res, err := cmp(s.items[idx], key)
+	return idx, res == 0, err
+}
+
+func (s *setValue) update(key Value, add bool, cmp compareFunc) (*setValue, error) {
+	idx, ok, err := s.find(key, cmp)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case ok && !add:
+		items := make([]Value, 0, len(s.items)-1)
+		items = append(items, s.items[:idx]...)
+		items = append(items, s.items[idx+1:]...)
+		return &setValue{items: items}, nil
+	case !ok && add:
+		items := make([]Value, 0, len(s.items)+1)
+		items = append(items, s.items[:idx]...)
+		items = append(items, key)
+		items = append(items, s.items[idx:]...)
+		return &setValue{items: items}, nil
+	default:
+		return s, nil
+	}
+}
+
+func (m *mapValue) find(key Value, cmp compareFunc) (int, bool, error) {
+	var err error
+	idx := sort.Search(len(m.keys), func(i int) bool {
+		if err != nil {
+			return true
+		}
+		var res int
+		res, err = cmp(m.keys[i], key)
+		return res >= 0
+	})
+	if err != nil || idx == len(m.keys) {
+		return idx, false, err
+	}
+	res, err := cmp(m.keys[idx], key)
+	return idx, res == 0, err
+}
+
+func (m *mapValue) get(key Value, cmp compareFunc) (Value, bool, error) {
+	idx, ok, err := m.find(key, cmp)
+	if err != nil || !ok {
+		return nil, false, err
+	}
+	return m.values[idx], true, nil
+}
+
+// update - returns map with updated key. Key is removed if `value` is nil.
+func (m *mapValue) update(key, value Value, cmp compareFunc) (*mapValue, error) {
+	idx, ok, err := m.find(key, cmp)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &mapValue{
+		keys:   make([]Value, 0, len(m.keys)+1),
+		values: make([]Value, 0, len(m.keys)+1),
+	}
+	result.keys = append(result.keys, m.keys[:idx]...)
+	result.values = append(result.values, m.values[:idx]...)
+	if value != nil {
+		result.keys = append(result.keys, key)
+		result.values = append(result.values, value)
+	}
+	if ok {
+		idx++
+	}
+	result.keys = append(result.keys, m.keys[idx:]...)
+	result.values = append(result.values, m.values[idx:]...)
+	return result, nil
+}
+
+func newPair(values ...Value) Value {
+	if len(values) == 1 {
+		return values[0]
+	}
+	return pairValue{
+		left:  values[0],
+		right: newPair(values[1:]...),
+	}
+}
+
+func some(value Value) optionValue {
+	return optionValue{some: true, value: value}
+}
+
+func none() optionValue {
+	return optionValue{}
+}
@@ -0,0 +1,501 @@
+package translator
+
+import (
+	"encoding/json"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/yhirose/go-peg"
+)
+
+var macroPattern = regexp.MustCompile(`^(FAIL|(CMP|IF|IFCMP|ASSERT_|ASSERT_CMP)(EQ|NEQ|LT|GT|LE|GE)|ASSERT|ASSERT_(NONE|SOME|LEFT|RIGHT)|IF_SOME|IF_RIGHT|C[AD]{2,}R|(SET|MAP)_C[AD]+R|DI{2,}P|DU{2,}P|P[PAI]{3,}R|UNP[PAI]{3,}R)$`)
+
+// instr - Micheline primitive of macro expansion
+type instr struct {
+	Prim   string   `json:"prim"`
+	Args   []any    `json:"args,omitempty"`
+	Annots []string `json:"annots,omitempty"`
+}
+
+// seq - Micheline sequence of macro expansion
+type seq []any
+
+type intArg struct {
+	Int string `json:"int"`
+}
+
+func newInt(value int) intArg {
+	return intArg{strconv.Itoa(value)}
+}
+
+func failSeq() seq {
+	return seq{instr{Prim: "UNIT"}, instr{Prim: "FAILWITH"}}
+}
+
+// isMacro - checks if expression is a macro. `CAR n` and `CDR n` are macros too.
+func isMacro(ast *peg.Ast) bool {
+	if len(ast.Nodes) == 0 || ast.Nodes[0].Name != "prim" {
+		return false
+	}
+	prim := ast.Nodes[0].Token
+	if prim == "CAR" || prim == "CDR" {
+		for i := range ast.Nodes {
+			if ast.Nodes[i].Name == "args" {
+				return true
+			}
+		}
+		return false
+	}
+	return macroPattern.MatchString(prim)
+}
+
+func (t *MichelineTranslator) macroTranslate(ast *peg.Ast) (string, error) {
+	var (
+		prim   string
+		annots []string
+		args   []json.RawMessage
+	)
+	for i := range ast.Nodes {
+		switch ast.Nodes[i].Name {
+		case "prim":
+			prim = ast.Nodes[i].Token
+		case "annots":
+			for _, annot := range ast.Nodes[i].Nodes {
+				annots = append(annots, annot.Token)
+			}
+		case "args":
+			for _, arg := range ast.Nodes[i].Nodes {
+				data, err := t.Translate(arg)
+				if err != nil {
+					return "", err
+				}
+				if data != "" {
+					args = append(args, json.RawMessage(data))
+				}
+			}
+		}
+	}
+
+	expansion, err := expandMacro(prim, annots, args)
+	if err != nil {
+		return "", err
+	}
+	data, err := json.Marshal(expansion)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// expandMacro - expands macro to sequence of instructions the same way as octez client does
+func expandMacro(prim string, annots []string, args []json.RawMessage) (seq, error) {
+	switch {
+	case prim == "FAIL":
+		if err := checkMacroArgs(prim, args, 0); err != nil {
+			return nil, err
+		}
+		return failSeq(), nil
+
+	case prim == "CAR" || prim == "CDR":
+		if len(args) != 1 {
+			return nil, errors.Errorf("macro %s expects 1 argument", prim)
+		}
+		var arg intArg
+		if err := json.Unmarshal(args[0], &arg); err != nil || arg.Int == "" {
+			return nil, errors.Errorf("macro %s expects integer argument", prim)
+		}
+		n, err := strconv.Atoi(arg.Int)
+		if err != nil || n < 0 {
+			return nil, errors.Errorf("invalid argument of macro %s: %s", prim, arg.Int)
+		}
+		index := 2 * n
+		if prim == "CAR" {
+			index++
+		}
+		return seq{instr{Prim: "GET", Args: []any{newInt(index)}, Annots: annots}}, nil
+
+	case prim == "ASSERT":
+		if err := checkMacroArgs(prim, args, 0); err != nil {
+			return nil, err
+		}
+		return seq{instr{Prim: "IF", Args: failFalse(nil)}}, nil
+
+	case prim == "ASSERT_NONE":
+		if err := checkMacroArgs(prim, args, 0); err != nil {
+			return nil, err
+		}
+		return seq{instr{Prim: "IF_NONE", Args: failFalse(nil)}}, nil
+
+	case prim == "ASSERT_SOME":
+		if err := checkMacroArgs(prim, args, 0); err != nil {
+			return nil, err
+		}
+		return seq{instr{Prim: "IF_NONE", Args: failTrue(annots)}}, nil
+
+	case prim == "ASSERT_LEFT":
+		if err := checkMacroArgs(prim, args, 0); err != nil {
+			return nil, err
+		}
+		return seq{instr{Prim: "IF_LEFT", Args: failFalse(annots)}}, nil
+
+	case prim == "ASSERT_RIGHT":
+		if err := checkMacroArgs(prim, args, 0); err != nil {
+			return nil, err
+		}
+		return seq{instr{Prim: "IF_LEFT", Args: failTrue(annots)}}, nil
+
+	case strings.HasPrefix(prim, "ASSERT_CMP"):
+		if err := checkMacroArgs(prim, args, 0); err != nil {
+			return nil, err
+		}
+		return seq{
+			seq{instr{Prim: "COMPARE"}, instr{Prim: strings.TrimPrefix(prim, "ASSERT_CMP")}},
+			instr{Prim: "IF", Args: failFalse(nil)},
+		}, nil
+
+	case strings.HasPrefix(prim, "ASSERT_"):
+		if err := checkMacroArgs(prim, args, 0); err != nil {
+			return nil, err
+		}
+		return seq{
+			instr{Prim: strings.TrimPrefix(prim, "ASSERT_")},
+			instr{Prim: "IF", Args: failFalse(nil)},
+		}, nil
+
+	case strings.HasPrefix(prim, "CMP"):
+		if err := checkMacroArgs(prim, args, 0); err != nil {
+			return nil, err
+		}
+		return seq{instr{Prim: "COMPARE"}, instr{Prim: strings.TrimPrefix(prim, "CMP"), Annots: annots}}, nil
+
+	case prim == "IF_SOME" || prim == "IF_RIGHT":
+		if err := checkMacroArgs(prim, args, 2); err != nil {
+			return nil, err
+		}
+		instruction := "IF_NONE"
+		if prim == "IF_RIGHT" {
+			instruction = "IF_LEFT"
+		}
+		return seq{instr{Prim: instruction, Args: []any{args[1], args[0]}, Annots: annots}}, nil
+
+	case strings.HasPrefix(prim, "IFCMP"):
+		if err := checkMacroArgs(prim, args, 2); err != nil {
+			return nil, err
+		}
+		return seq{
+			instr{Prim: "COMPARE"},
+			instr{Prim: strings.TrimPrefix(prim, "IFCMP")},
+			instr{Prim: "IF", Args: []any{args[0], args[1]}, Annots: annots},
+		}, nil
+
+	case strings.HasPrefix(prim, "IF"):
+		if err := checkMacroArgs(prim, args, 2); err != nil {
+			return nil, err
+		}
+		return seq{
+			instr{Prim: strings.TrimPrefix(prim, "IF")},
+			instr{Prim: "IF", Args: []any{args[0], args[1]}, Annots: annots},
+		}, nil
+
+	case strings.HasPrefix(prim, "DU"):
+		if err := checkMacroArgs(prim, args, 0); err != nil {
+			return nil, err
+		}
+		return seq{instr{Prim: "DUP", Args: []any{newInt(len(prim) - 2)}, Annots: annots}}, nil
+
+	case strings.HasPrefix(prim, "DI"):
+		if err := checkMacroArgs(prim, args, 1); err != nil {
+			return nil, err
+		}
+		return seq{instr{Prim: "DIP", Args: []any{newInt(len(prim) - 2), args[0]}, Annots: annots}}, nil
+
+	case strings.HasPrefix(prim, "SET_"):
+		if err := checkMacroArgs(prim, args, 0); err != nil {
+			return nil, err
+		}
+		return expandSetCadr(prim[5:len(prim)-1], annots), nil
+
+	case strings.HasPrefix(prim, "MAP_"):
+		if err := checkMacroArgs(prim, args, 1); err != nil {
+			return nil, err
+		}
+		return expandMapCadr(prim[5:len(prim)-1], annots, args[0]), nil
+
+	case strings.HasPrefix(prim, "C"):
+		if err := checkMacroArgs(prim, args, 0); err != nil {
+			return nil, err
+		}
+		path := prim[1 : len(prim)-1]
+		result := make(seq, len(path))
+		for i := range path {
+			result[i] = instr{Prim: cadrPrim(path[i])}
+		}
+		result[len(result)-1] = instr{Prim: cadrPrim(path[len(path)-1]), Annots: annots}
+		return result, nil
+
+	case strings.HasPrefix(prim, "UNP"):
+		if err := checkMacroArgs(prim, args, 0); err != nil {
+			return nil, err
+		}
+		tree, err := parsePairMacro(prim[2:])
+		if err != nil {
+			return nil, err
+		}
+		return tree.unpair(annots), nil
+
+	case strings.HasPrefix(prim, "P"):
+		if err := checkMacroArgs(prim, args, 0); err != nil {
+			return nil, err
+		}
+		tree, err := parsePairMacro(prim)
+		if err != nil {
+			return nil, err
+		}
+		var fields, variables []string
+		for i := range annots {
+			if strings.HasPrefix(annots[i], "%") {
+				fields = append(fields, annots[i])
+			} else {
+				variables = append(variables, annots[i])
+			}
+		}
+		result := tree.pair(fields)
+		if len(variables) > 0 {
+			last := result[len(result)-1].(instr)
+			last.Annots = append(last.Annots, variables...)
+			result[len(result)-1] = last
+		}
+		return result, nil
+	}
+	return nil, errors.Errorf("unknown macro %s", prim)
+}
+
+func checkMacroArgs(prim string, args []json.RawMessage, count int) error {
+	if len(args) != count {
+		return errors.Errorf("macro %s expects %d arguments, got %d", prim, count, len(args))
+	}
+	for i := range args {
+		if !strings.HasPrefix(string(args[i]), "[") {
+			return errors.Errorf("argument of macro %s should be a sequence of instructions", prim)
+		}
+	}
+	return nil
+}
+
+func mayRename(annots []string) seq {
+	if len(annots) == 0 {
+		return seq{}
+	}
+	return seq{instr{Prim: "RENAME", Annots: annots}}
+}
+
+func failFalse(annots []string) []any {
+	return []any{mayRename(annots), seq{failSeq()}}
+}
+
+func failTrue(annots []string) []any {
+	return []any{seq{failSeq()}, mayRename(annots)}
+}
+
+func cadrPrim(letter byte) string {
+	if letter == 'A' {
+		return "CAR"
+	}
+	return "CDR"
+}
+
+func splitFieldAnnot(annots []string) (field string, others []string) {
+	for i := range annots {
+		if field == "" && strings.HasPrefix(annots[i], "%") {
+			field = annots[i]
+			continue
+		}
+		others = append(others, annots[i])
+	}
+	return
+}
+
+// expandSetCadr - expands `SET_C[AD]+R`. `path` is letters between `SET_C` and `R`.
+func expandSetCadr(path string, annots []string) seq {
+	field, others := splitFieldAnnot(annots)
+	pairField := field
+	if pairField == "" {
+		pairField = "%"
+	}
+
+	var result seq
+	if field != "" {
+		result = append(result, instr{Prim: "DUP"}, instr{Prim: cadrPrim(path[len(path)-1]), Annots: []string{field}}, instr{Prim: "DROP"})
+	}
+	if path[len(path)-1] == 'A' {
+		result = append(result,
+			instr{Prim: "CDR", Annots: []string{"@%%"}},
+			instr{Prim: "SWAP"},
+			instr{Prim: "PAIR", Annots: []string{pairField, "%@"}},
+		)
+	} else {
+		result = append(result,
+			instr{Prim: "CAR", Annots: []string{"@%%"}},
+			instr{Prim: "PAIR", Annots: []string{"%@", pairField}},
+		)
+	}
+	return wrapCadr(path[:len(path)-1], result, others)
+}
+
+// expandMapCadr - expands `MAP_C[AD]+R`. `path` is letters between `MAP_C` and `R`.
+func expandMapCadr(path string, annots []string, code json.RawMessage) seq {
+	field, others := splitFieldAnnot(annots)
+	pairField := field
+	var crAnnots []string
+	if field == "" {
+		pairField = "%"
+	} else {
+		crAnnots = []string{"@" + field[1:]}
+	}
+
+	var result seq
+	if path[len(path)-1] == 'A' {
+		result = seq{
+			instr{Prim: "DUP"},
+			instr{Prim: "CDR", Annots: []string{"@%%"}},
+			instr{Prim: "DIP", Args: []any{seq{instr{Prim: "CAR", Annots: crAnnots}, code}}},
+			instr{Prim: "SWAP"},
+			instr{Prim: "PAIR", Annots: []string{pairField, "%@"}},
+		}
+	} else {
+		result = seq{
+			instr{Prim: "DUP"},
+			instr{Prim: "CDR", Annots: crAnnots},
+			code,
+			instr{Prim: "SWAP"},
+			instr{Prim: "CAR", Annots: []string{"@%%"}},
+			instr{Prim: "PAIR", Annots: []string{"%@", pairField}},
+		}
+	}
+	return wrapCadr(path[:len(path)-1], result, others)
+}
+
+// wrapCadr - wraps update of nested pair by accesses to outer pairs from the innermost to the outermost one
+func wrapCadr(path string, inner seq, annots []string) seq {
+	result := inner
+	for i := len(path) - 1; i >= 0; i-- {
+		access, rest := "CAR", "CDR"
+		if path[i] == 'D' {
+			access, rest = rest, access
+		}
+		wrapped := seq{
+			instr{Prim: "DUP"},
+			instr{Prim: "DIP", Args: []any{seq{instr{Prim: access, Annots: []string{"@%%"}}, result}}},
+			instr{Prim: rest, Annots: []string{"@%%"}},
+		}
+		if path[i] == 'A' {
+			wrapped = append(wrapped, instr{Prim: "SWAP"})
+		}
+		result = append(wrapped, instr{Prim: "PAIR", Annots: []string{"%@", "%@"}})
+	}
+	if len(annots) > 0 {
+		last := result[len(result)-1].(instr)
+		last.Annots = append(last.Annots, annots...)
+		result[len(result)-1] = last
+	}
+	return result
+}
+
+// pairTree - tree of `PAIR` macro. Nil children are leaves: `A` on the left and `I` on the right.
+type pairTree struct {
+	left, right *pairTree
+}
+
+// parsePairMacro - parses `P(left)(right)R` where `left` is `A` or `P(left)(right)` and `right` is `I` or `P(left)(right)`
+func parsePairMacro(prim string) (*pairTree, error) {
+	if !strings.HasSuffix(prim, "R") {
+		return nil, errors.Errorf("invalid macro %s", prim)
+	}
+	tree, pos, ok := parsePairNode(prim, 0)
+	if !ok || pos != len(prim)-1 {
+		return nil, errors.Errorf("invalid macro %s", prim)
+	}
+	return tree, nil
+}
+
+func parsePairNode(s string, pos int) (*pairTree, int, bool) {
+	if pos >= len(s) || s[pos] != 'P' {
+		return nil, pos, false
+	}
+	pos++
+
+	var tree pairTree
+	switch {
+	case pos < len(s) && s[pos] == 'A':
+		pos++
+	default:
+		left, next, ok := parsePairNode(s, pos)
+		if !ok {
+			return nil, pos, false
+		}
+		tree.left, pos = left, next
+	}
+
+	switch {
+	case pos < len(s) && s[pos] == 'I':
+		pos++
+	default:
+		right, next, ok := parsePairNode(s, pos)
+		if !ok {
+			return nil, pos, false
+		}
+		tree.right, pos = right, next
+	}
+	return &tree, pos, true
+}
+
+func (tree *pairTree) leaves() int {
+	if tree == nil {
+		return 1
+	}
+	return tree.left.leaves() + tree.right.leaves()
+}
+
+// pair - expansion of `PAIR` macro. Field annotations are assigned to leaves from left to right.
+func (tree *pairTree) pair(fields []string) seq {
+	leftCount := min(tree.left.leaves(), len(fields))
+	leftFields, rightFields := fields[:leftCount], fields[leftCount:]
+
+	var result seq
+	if tree.left != nil {
+		result = append(result, tree.left.pair(leftFields)...)
+	}
+	if tree.right != nil {
+		result = append(result, instr{Prim: "DIP", Args: []any{tree.right.pair(rightFields)}})
+	}
+
+	annots := []string{"%", "%"}
+	if tree.left == nil && len(leftFields) > 0 {
+		annots[0] = leftFields[0]
+	}
+	if tree.right == nil && len(rightFields) > 0 {
+		annots[1] = rightFields[0]
+	}
+	switch {
+	case annots[1] != "%":
+	case annots[0] != "%":
+		annots = annots[:1]
+	default:
+		annots = nil
+	}
+	return append(result, instr{Prim: "PAIR", Annots: annots})
+}
+
+// unpair - expansion of `UNPAIR` macro
+func (tree *pairTree) unpair(annots []string) seq {
+	result := seq{instr{Prim: "UNPAIR", Annots: annots}}
+	if tree.right != nil {
+		result = append(result, instr{Prim: "DIP", Args: []any{tree.right.unpair(nil)}})
+	}
+	if tree.left != nil {
+		result = append(result, tree.left.unpair(nil)...)
+	}
+	return result
+}
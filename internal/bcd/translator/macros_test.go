@@ -0,0 +1,117 @@
+package translator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestConverter_Macros(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{
+			name:  "FAIL",
+			input: `FAIL`,
+			want:  `[[{"prim":"UNIT"},{"prim":"FAILWITH"}]]`,
+		}, {
+			name:  "CADR",
+			input: `CADR @x`,
+			want:  `[[{"prim":"CAR"},{"prim":"CDR","annots":["@x"]}]]`,
+		}, {
+			name:  "CAR n",
+			input: `CAR 2; CDR 1`,
+			want:  `[[{"prim":"GET","args":[{"int":"5"}]}],[{"prim":"GET","args":[{"int":"2"}]}]]`,
+		}, {
+			name:  "DUUP",
+			input: `DUUUP`,
+			want:  `[[{"prim":"DUP","args":[{"int":"3"}]}]]`,
+		}, {
+			name:  "DIIP",
+			input: `DIIP { DROP }`,
+			want:  `[[{"prim":"DIP","args":[{"int":"2"},[{"prim":"DROP"}]]}]]`,
+		}, {
+			name:  "CMPEQ",
+			input: `CMPEQ`,
+			want:  `[[{"prim":"COMPARE"},{"prim":"EQ"}]]`,
+		}, {
+			name:  "IFCMPGT",
+			input: `IFCMPGT { UNIT } {}`,
+			want:  `[[{"prim":"COMPARE"},{"prim":"GT"},{"prim":"IF","args":[[{"prim":"UNIT"}],[]]}]]`,
+		}, {
+			name:  "IFEQ",
+			input: `IFEQ {} { FAIL }`,
+			want:  `[[{"prim":"EQ"},{"prim":"IF","args":[[],[[{"prim":"UNIT"},{"prim":"FAILWITH"}]]]}]]`,
+		}, {
+			name:  "IF_SOME",
+			input: `IF_SOME { DROP } { UNIT }`,
+			want:  `[[{"prim":"IF_NONE","args":[[{"prim":"UNIT"}],[{"prim":"DROP"}]]}]]`,
+		}, {
+			name:  "ASSERT",
+			input: `ASSERT`,
+			want:  `[[{"prim":"IF","args":[[],[[{"prim":"UNIT"},{"prim":"FAILWITH"}]]]}]]`,
+		}, {
+			name:  "ASSERT_CMPLT",
+			input: `ASSERT_CMPLT`,
+			want:  `[[[{"prim":"COMPARE"},{"prim":"LT"}],{"prim":"IF","args":[[],[[{"prim":"UNIT"},{"prim":"FAILWITH"}]]]}]]`,
+		}, {
+			name:  "ASSERT_SOME",
+			input: `ASSERT_SOME @x`,
+			want:  `[[{"prim":"IF_NONE","args":[[[{"prim":"UNIT"},{"prim":"FAILWITH"}]],[{"prim":"RENAME","annots":["@x"]}]]}]]`,
+		}, {
+			name:  "PAPAIR",
+			input: `PAPAIR %a %b %c`,
+			want:  `[[{"prim":"DIP","args":[[{"prim":"PAIR","annots":["%b","%c"]}]]},{"prim":"PAIR","annots":["%a"]}]]`,
+		}, {
+			name:  "PPAIPAIR",
+			input: `PPAIPAIR @p`,
+			want:  `[[{"prim":"PAIR"},{"prim":"DIP","args":[[{"prim":"PAIR"}]]},{"prim":"PAIR","annots":["@p"]}]]`,
+		}, {
+			name:  "UNPAPAIR",
+			input: `UNPAPAIR`,
+			want:  `[[{"prim":"UNPAIR"},{"prim":"DIP","args":[[{"prim":"UNPAIR"}]]}]]`,
+		}, {
+			name:  "SET_CAR",
+			input: `SET_CAR`,
+			want:  `[[{"prim":"CDR","annots":["@%%"]},{"prim":"SWAP"},{"prim":"PAIR","annots":["%","%@"]}]]`,
+		}, {
+			name:  "SET_CDR with field",
+			input: `SET_CDR %b`,
+			want:  `[[{"prim":"DUP"},{"prim":"CDR","annots":["%b"]},{"prim":"DROP"},{"prim":"CAR","annots":["@%%"]},{"prim":"PAIR","annots":["%@","%b"]}]]`,
+		}, {
+			name:  "MAP_CDR",
+			input: `MAP_CDR { PUSH nat 1 ; ADD }`,
+			want:  `[[{"prim":"DUP"},{"prim":"CDR"},[{"prim":"PUSH","args":[{"prim":"nat"},{"int":"1"}]},{"prim":"ADD"}],{"prim":"SWAP"},{"prim":"CAR","annots":["@%%"]},{"prim":"PAIR","annots":["%@","%"]}]]`,
+		}, {
+			name:  "nested macros",
+			input: `IFCMPEQ { CDAR } { FAIL }`,
+			want:  `[[{"prim":"COMPARE"},{"prim":"EQ"},{"prim":"IF","args":[[[{"prim":"CDR"},{"prim":"CAR"}]],[[{"prim":"UNIT"},{"prim":"FAILWITH"}]]]}]]`,
+		}, {
+			name:    "IFEQ without branches",
+			input:   `IFEQ`,
+			wantErr: true,
+		}, {
+			name:    "invalid pair macro",
+			input:   `PAPIIR`,
+			wantErr: true,
+		},
+	}
+
+	c, err := NewConverter()
+	require.NoError(t, err)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := c.FromString(tt.input)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.JSONEq(t, tt.want, got)
+		})
+	}
+}
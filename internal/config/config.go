@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"regexp"
+	"slices"
 
 	"github.com/baking-bad/bcdhub/internal/periodic"
 	"github.com/baking-bad/bcdhub/internal/postgres/core"
@@ -28,6 +29,7 @@ type Config struct {
 	SharePath string                   `yaml:"share_path"`
 	BaseURL   string                   `yaml:"base_url"`
 	Profiler  *Profiler                `yaml:"profiler"`
+	Metrics   *Metrics                 `yaml:"metrics"`
 	LogLevel  string                   `yaml:"log_level"`
 
 	API APIConfig `yaml:"api"`
@@ -36,6 +38,7 @@ type Config struct {
 		Networks      map[string]IndexerConfig `yaml:"networks"`
 		ProjectName   string                   `yaml:"project_name"`
 		SentryEnabled bool                     `yaml:"sentry_enabled"`
+		Metadata      MetadataConfig           `yaml:"metadata"`
 	} `yaml:"indexer"`
 
 	Scripts struct {
@@ -56,20 +59,63 @@ type Profiler struct {
 	Server string `yaml:"server"`
 }
 
-// IndexerConfig -
+// Metrics - settings of Prometheus metrics. API serves `/metrics` on its own address, indexer serves it on `bind` address.
+type Metrics struct {
+	Bind string `yaml:"bind"`
+}
+
+// IndexerConfig - settings of network indexer.
+// If indexer is behind node head by more than `bulk_threshold` levels, blocks are saved by batches of `bulk_size` blocks in one transaction
+// and creation of indices is deferred until indexer catches up. Zero `bulk_threshold` disables bulk sync.
 type IndexerConfig struct {
 	ReceiverThreads int64            `yaml:"receiver_threads"`
 	StartLevel      int64            `yaml:"start_level"`
+	BulkThreshold   int64            `yaml:"bulk_threshold"`
+	BulkSize        int64            `yaml:"bulk_size"`
 	Periodic        *periodic.Config `yaml:"periodic"`
 }
 
-// RPCConfig -
+// MetadataConfig - settings of TZIP-16 metadata resolution. If `directory` is set, off-chain documents are read from it instead of network.
+type MetadataConfig struct {
+	Enabled      bool     `yaml:"enabled"`
+	Timeout      int      `yaml:"timeout"`
+	IPFSGateways []string `yaml:"ipfs_gateways"`
+	Directory    string   `yaml:"directory"`
+}
+
+// RPCConfig - settings of node RPC. If `cache` directory is set, responses at finalized levels are stored there. `cache_size` limits size of the directory in megabytes. If `uri` is empty, only cached responses are available.
+// If `uris` is set, requests are routed between `uri` and `uris` nodes: nodes are probed every `health_check_interval` seconds and nodes which are failed or behind the highest head by more than `max_lag` levels are skipped.
+// Disk cache is bound to the single node, so `cache` can't be set with several nodes.
 type RPCConfig struct {
-	URI               string `yaml:"uri"`
-	Timeout           int    `yaml:"timeout"`
-	Cache             string `yaml:"cache"`
-	RequestsPerSecond int    `yaml:"requests_per_second"`
-	Log               bool   `yaml:"log"`
+	URI                 string   `yaml:"uri"`
+	URIs                []string `yaml:"uris"`
+	Timeout             int      `yaml:"timeout"`
+	Cache               string   `yaml:"cache"`
+	CacheSize           int64    `yaml:"cache_size"`
+	RequestsPerSecond   int      `yaml:"requests_per_second"`
+	Log                 bool     `yaml:"log"`
+	HealthCheckInterval int      `yaml:"health_check_interval"`
+	MaxLag              *int64   `yaml:"max_lag"`
+}
+
+// NodeURIs - returns URIs of all configured nodes without duplicates
+func (cfg RPCConfig) NodeURIs() []string {
+	uris := make([]string, 0, len(cfg.URIs)+1)
+	for _, uri := range append([]string{cfg.URI}, cfg.URIs...) {
+		if uri == "" || slices.Contains(uris, uri) {
+			continue
+		}
+		uris = append(uris, uri)
+	}
+	return uris
+}
+
+// Validate - checks that settings don't conflict
+func (cfg RPCConfig) Validate() error {
+	if cfg.Cache != "" && len(cfg.NodeURIs()) > 1 {
+		return fmt.Errorf("cache %s can't be used with several nodes", cfg.Cache)
+	}
+	return nil
 }
 
 // ServiceConfig -
@@ -211,6 +257,12 @@ func LoadConfig(filename string) (Config, error) {
 		return config, fmt.Errorf("unmarshaling configuration file %s error: %w", filename, err)
 	}
 
+	for network, rpc := range config.RPC {
+		if err := rpc.Validate(); err != nil {
+			return config, fmt.Errorf("invalid rpc configuration of %s: %w", network, err)
+		}
+	}
+
 	return config, nil
 }
 
@@ -238,3 +290,15 @@ func (c IndexerConfig) ResolveStartLevel() int64 {
 	}
 	return c.StartLevel
 }
+
+// defaultBulkSize - count of blocks in one batch of bulk sync if `bulk_size` isn't set
+const defaultBulkSize = 100
+
+// ResolveBulkSize returns the configured count of blocks in one batch of
+// bulk sync, defaulting to 100 when no explicit value is provided.
+func (c IndexerConfig) ResolveBulkSize() int64 {
+	if c.BulkSize < 1 {
+		return defaultBulkSize
+	}
+	return c.BulkSize
+}
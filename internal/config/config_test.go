@@ -38,3 +38,58 @@ func Test_expandEnv(t *testing.T) {
 		})
 	}
 }
+
+func TestRPCConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     RPCConfig
+		wantErr bool
+	}{
+		{
+			name: "single node with cache",
+			cfg:  RPCConfig{URI: "https://rpc.tzkt.io/mainnet", Cache: "/cache"},
+		}, {
+			name: "several nodes without cache",
+			cfg:  RPCConfig{URI: "https://rpc.tzkt.io/mainnet", URIs: []string{"https://mainnet.api.tez.ie"}},
+		}, {
+			name: "duplicated node with cache",
+			cfg:  RPCConfig{URI: "https://rpc.tzkt.io/mainnet", URIs: []string{"https://rpc.tzkt.io/mainnet"}, Cache: "/cache"},
+		}, {
+			name:    "several nodes with cache",
+			cfg:     RPCConfig{URI: "https://rpc.tzkt.io/mainnet", URIs: []string{"https://mainnet.api.tez.ie"}, Cache: "/cache"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.cfg.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_poolOptions(t *testing.T) {
+	zero := int64(0)
+	tests := []struct {
+		name   string
+		maxLag *int64
+		want   int
+	}{
+		{
+			name: "max lag isn't set",
+			want: 1,
+		}, {
+			name:   "zero max lag",
+			maxLag: &zero,
+			want:   2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := poolOptions(RPCConfig{MaxLag: tt.maxLag}); len(got) != tt.want {
+				t.Errorf("poolOptions() = %d options, want %d", len(got), tt.want)
+			}
+		})
+	}
+}
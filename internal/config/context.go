@@ -1,6 +1,8 @@
 package config
 
 import (
+	"io"
+
 	"github.com/baking-bad/bcdhub/internal/cache"
 	"github.com/baking-bad/bcdhub/internal/models"
 	"github.com/baking-bad/bcdhub/internal/models/account"
@@ -8,18 +10,22 @@ import (
 	"github.com/baking-bad/bcdhub/internal/models/bigmapdiff"
 	"github.com/baking-bad/bcdhub/internal/models/block"
 	"github.com/baking-bad/bcdhub/internal/models/contract"
+	contractmetadata "github.com/baking-bad/bcdhub/internal/models/contract_metadata"
 	"github.com/baking-bad/bcdhub/internal/models/domains"
 	"github.com/baking-bad/bcdhub/internal/models/migration"
 	"github.com/baking-bad/bcdhub/internal/models/operation"
 	"github.com/baking-bad/bcdhub/internal/models/protocol"
+	"github.com/baking-bad/bcdhub/internal/models/search"
 	smartrollup "github.com/baking-bad/bcdhub/internal/models/smart_rollup"
 	"github.com/baking-bad/bcdhub/internal/models/stats"
 	"github.com/baking-bad/bcdhub/internal/models/ticket"
+	"github.com/baking-bad/bcdhub/internal/models/token"
 	"github.com/baking-bad/bcdhub/internal/models/types"
 	"github.com/baking-bad/bcdhub/internal/noderpc"
 	"github.com/baking-bad/bcdhub/internal/postgres/core"
 	"github.com/pkg/errors"
 	"github.com/rs/zerolog/log"
+	"github.com/uptrace/bun"
 )
 
 // Context -
@@ -38,14 +44,17 @@ type Context struct {
 	BigMapDiffs     bigmapdiff.Repository
 	Blocks          block.Repository
 	Contracts       contract.Repository
+	Metadata        contractmetadata.Repository
 	GlobalConstants contract.ConstantRepository
 	Migrations      migration.Repository
 	Operations      operation.Repository
 	Protocols       protocol.Repository
 	Tickets         ticket.Repository
+	Tokens          token.Repository
 	Domains         domains.Repository
 	Scripts         contract.ScriptRepository
 	SmartRollups    smartrollup.Repository
+	Search          search.Repository
 	Stats           stats.Repository
 
 	Cache *cache.Cache
@@ -67,8 +76,24 @@ func NewContext(network types.Network, opts ...ContextOption) *Context {
 	return ctx
 }
 
+// WithDB - returns copy of context which reads and writes storage through `db`, e.g. inside a batch transaction.
+// The copy shares connections with the origin, so it mustn't be closed.
+func (ctx *Context) WithDB(db bun.IDB) *Context {
+	clone := *ctx
+	setStorage(&clone, ctx.StorageDB.WithDB(db))
+	clone.Cache = cache.NewCache(
+		clone.RPC, clone.Accounts, clone.Contracts, clone.Protocols, clone.Domains,
+	)
+	return &clone
+}
+
 // Close -
 func (ctx *Context) Close() error {
+	if closer, ok := ctx.RPC.(io.Closer); ok {
+		if err := closer.Close(); err != nil {
+			return err
+		}
+	}
 	if ctx.StorageDB != nil {
 		if err := ctx.StorageDB.Close(); err != nil {
 			return err
@@ -8,20 +8,24 @@ import (
 	"github.com/baking-bad/bcdhub/internal/postgres/account"
 	"github.com/baking-bad/bcdhub/internal/postgres/bigmapdiff"
 	"github.com/baking-bad/bcdhub/internal/postgres/contract"
+	contractmetadata "github.com/baking-bad/bcdhub/internal/postgres/contract_metadata"
 	"github.com/baking-bad/bcdhub/internal/postgres/domains"
 	"github.com/baking-bad/bcdhub/internal/postgres/global_constant"
 	"github.com/baking-bad/bcdhub/internal/postgres/migration"
 	"github.com/baking-bad/bcdhub/internal/postgres/operation"
 	"github.com/baking-bad/bcdhub/internal/postgres/protocol"
+	"github.com/baking-bad/bcdhub/internal/postgres/search"
 	smartrollup "github.com/baking-bad/bcdhub/internal/postgres/smart_rollup"
 	"github.com/baking-bad/bcdhub/internal/postgres/stats"
 	"github.com/baking-bad/bcdhub/internal/postgres/ticket"
+	"github.com/baking-bad/bcdhub/internal/postgres/token"
 
 	"github.com/baking-bad/bcdhub/internal/postgres/bigmapaction"
 	"github.com/baking-bad/bcdhub/internal/postgres/block"
 	pgCore "github.com/baking-bad/bcdhub/internal/postgres/core"
 
 	"github.com/baking-bad/bcdhub/internal/noderpc"
+	"github.com/rs/zerolog/log"
 )
 
 // ContextOption -
@@ -31,18 +35,24 @@ type ContextOption func(ctx *Context)
 func WithRPC(rpcConfig map[string]RPCConfig) ContextOption {
 	return func(ctx *Context) {
 		if rpcProvider, ok := rpcConfig[ctx.Network.String()]; ok {
-			if rpcProvider.URI == "" {
+			if rpcProvider.URI == "" && rpcProvider.Cache == "" {
 				return
 			}
 			opts := []noderpc.NodeOption{
 				noderpc.WithTimeout(time.Second * time.Duration(rpcProvider.Timeout)),
 				noderpc.WithRateLimit(rpcProvider.RequestsPerSecond),
+				noderpc.WithNetwork(ctx.Network.String()),
 			}
 			if rpcProvider.Log {
 				opts = append(opts, noderpc.WithLog())
 			}
 
-			ctx.RPC = noderpc.NewNodeRPC(rpcProvider.URI, opts...)
+			if uris := rpcProvider.NodeURIs(); len(uris) > 1 {
+				ctx.RPC = newRPCPool(noderpc.NewPool(uris, opts, poolOptions(rpcProvider)...))
+				return
+			}
+
+			ctx.RPC = withRPCCache(noderpc.NewNodeRPC(rpcProvider.URI, opts...), rpcProvider)
 		}
 	}
 }
@@ -51,22 +61,58 @@ func WithRPC(rpcConfig map[string]RPCConfig) ContextOption {
 func WithWaitRPC(rpcConfig map[string]RPCConfig) ContextOption {
 	return func(ctx *Context) {
 		if rpcProvider, ok := rpcConfig[ctx.Network.String()]; ok {
-			if rpcProvider.URI == "" {
+			uris := rpcProvider.NodeURIs()
+			if len(uris) == 0 {
 				return
 			}
 			opts := []noderpc.NodeOption{
 				noderpc.WithTimeout(time.Second * time.Duration(rpcProvider.Timeout)),
 				noderpc.WithRateLimit(rpcProvider.RequestsPerSecond),
+				noderpc.WithNetwork(ctx.Network.String()),
 			}
 			if rpcProvider.Log {
 				opts = append(opts, noderpc.WithLog())
 			}
 
-			ctx.RPC = noderpc.NewWaitNodeRPC(rpcProvider.URI, opts...)
+			if len(uris) > 1 {
+				ctx.RPC = newRPCPool(noderpc.NewWaitPool(uris, opts, poolOptions(rpcProvider)...))
+				return
+			}
+
+			ctx.RPC = withRPCCache(noderpc.NewWaitNodeRPC(uris[0], opts...), rpcProvider)
 		}
 	}
 }
 
+// withRPCCache - wraps node with disk cache if it's set in config
+func withRPCCache(node *noderpc.NodeRPC, cfg RPCConfig) noderpc.INode {
+	if cfg.Cache == "" {
+		return node
+	}
+	cache, err := noderpc.NewCache(node, cfg.Cache, noderpc.WithCacheSize(cfg.CacheSize*1024*1024))
+	if err != nil {
+		log.Err(err).Str("directory", cfg.Cache).Msg("can't create RPC cache. Node is used without cache")
+		return node
+	}
+	return cache
+}
+
+func poolOptions(cfg RPCConfig) []noderpc.PoolOption {
+	opts := []noderpc.PoolOption{
+		noderpc.WithCheckInterval(time.Second * time.Duration(cfg.HealthCheckInterval)),
+	}
+	if cfg.MaxLag != nil {
+		opts = append(opts, noderpc.WithMaxLag(*cfg.MaxLag))
+	}
+	return opts
+}
+
+// newRPCPool - starts health checks of pool. Disk cache isn't supported by pool because cache is bound to the single node: such config is rejected by `RPCConfig.Validate`.
+func newRPCPool(pool *noderpc.Pool) noderpc.INode {
+	pool.Start(context.Background())
+	return pool
+}
+
 // WithStorage -
 func WithStorage(cctx context.Context, cfg StorageConfig, appName string, maxPageSize int64, timeout time.Duration) ContextOption {
 	return func(ctx *Context) {
@@ -89,26 +135,33 @@ func WithStorage(cctx context.Context, cfg StorageConfig, appName string, maxPag
 			appName, cfg.Timeout, opts...,
 		)
 
-		contractStorage := contract.NewStorage(conn)
-		ctx.StorageDB = conn
-		ctx.Storage = conn
-		ctx.Accounts = account.NewStorage(conn)
-		ctx.BigMapActions = bigmapaction.NewStorage(conn)
-		ctx.Blocks = block.NewStorage(conn)
-		ctx.BigMapDiffs = bigmapdiff.NewStorage(conn)
-		ctx.Contracts = contractStorage
-		ctx.Migrations = migration.NewStorage(conn)
-		ctx.Operations = operation.NewStorage(conn)
-		ctx.Protocols = protocol.NewStorage(conn)
-		ctx.GlobalConstants = global_constant.NewStorage(conn)
-		ctx.Domains = domains.NewStorage(conn)
-		ctx.Tickets = ticket.NewStorage(conn)
-		ctx.Scripts = contractStorage
-		ctx.SmartRollups = smartrollup.NewStorage(conn)
-		ctx.Stats = stats.NewStorage(conn)
+		setStorage(ctx, conn)
 	}
 }
 
+func setStorage(ctx *Context, conn *pgCore.Postgres) {
+	contractStorage := contract.NewStorage(conn)
+	ctx.StorageDB = conn
+	ctx.Storage = conn
+	ctx.Accounts = account.NewStorage(conn)
+	ctx.BigMapActions = bigmapaction.NewStorage(conn)
+	ctx.Blocks = block.NewStorage(conn)
+	ctx.BigMapDiffs = bigmapdiff.NewStorage(conn)
+	ctx.Contracts = contractStorage
+	ctx.Metadata = contractmetadata.NewStorage(conn)
+	ctx.Migrations = migration.NewStorage(conn)
+	ctx.Operations = operation.NewStorage(conn)
+	ctx.Protocols = protocol.NewStorage(conn)
+	ctx.GlobalConstants = global_constant.NewStorage(conn)
+	ctx.Domains = domains.NewStorage(conn)
+	ctx.Tickets = ticket.NewStorage(conn)
+	ctx.Tokens = token.NewStorage(conn)
+	ctx.Scripts = contractStorage
+	ctx.SmartRollups = smartrollup.NewStorage(conn)
+	ctx.Search = search.NewStorage(conn)
+	ctx.Stats = stats.NewStorage(conn)
+}
+
 // WithConfigCopy -
 func WithConfigCopy(cfg Config) ContextOption {
 	return func(ctx *Context) {
@@ -0,0 +1,48 @@
+package events
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/baking-bad/bcdhub/internal/models/types"
+	jsoniter "github.com/json-iterator/go"
+)
+
+var json = jsoniter.ConfigCompatibleWithStandardLibrary
+
+// Message types
+const (
+	MessageTypeBlock    = "block"
+	MessageTypeRollback = "rollback"
+)
+
+// Message - notification which is sent by indexer when block was saved or indexer state was rolled back
+type Message struct {
+	Type  string `json:"type"`
+	Level int64  `json:"level"`
+}
+
+// Notifier -
+type Notifier interface {
+	Notify(ctx context.Context, channel, payload string) error
+}
+
+// Channel - returns name of notification channel for the network
+func Channel(network types.Network) string {
+	return fmt.Sprintf("bcd_%s", network.String())
+}
+
+// Publish - sends message to the network channel
+func Publish(ctx context.Context, notifier Notifier, network types.Network, msg Message) error {
+	payload, err := json.MarshalToString(msg)
+	if err != nil {
+		return err
+	}
+	return notifier.Notify(ctx, Channel(network), payload)
+}
+
+// Parse - decodes message from notification payload
+func Parse(payload string) (msg Message, err error) {
+	err = json.UnmarshalFromString(payload, &msg)
+	return
+}
@@ -0,0 +1,187 @@
+package export
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/baking-bad/bcdhub/internal/bcd/ast"
+	"github.com/baking-bad/bcdhub/internal/bcd/consts"
+	"github.com/baking-bad/bcdhub/internal/config"
+	"github.com/baking-bad/bcdhub/internal/models/bigmapdiff"
+	"github.com/baking-bad/bcdhub/internal/models/types"
+	"github.com/pkg/errors"
+)
+
+// BigMapKeyRow -
+type BigMapKeyRow struct {
+	Ptr       int64           `json:"ptr"`
+	Contract  string          `json:"contract"`
+	KeyHash   string          `json:"key_hash"`
+	Key       *ast.MiguelNode `json:"key,omitempty"`
+	Value     *ast.MiguelNode `json:"value,omitempty"`
+	Level     int64           `json:"level"`
+	Timestamp time.Time       `json:"timestamp"`
+	IsActive  bool            `json:"is_active"`
+	Count     int64           `json:"count"`
+}
+
+// Header -
+func (BigMapKeyRow) Header() []string {
+	return []string{"ptr", "contract", "key_hash", "key", "value", "level", "timestamp", "is_active", "count"}
+}
+
+// Record -
+func (row BigMapKeyRow) Record() ([]string, error) {
+	key, err := nodeCell(row.Key)
+	if err != nil {
+		return nil, err
+	}
+	value, err := nodeCell(row.Value)
+	if err != nil {
+		return nil, err
+	}
+	return []string{
+		strconv.FormatInt(row.Ptr, 10),
+		row.Contract,
+		row.KeyHash,
+		key,
+		value,
+		strconv.FormatInt(row.Level, 10),
+		row.Timestamp.UTC().Format(time.RFC3339),
+		strconv.FormatBool(row.IsActive),
+		strconv.FormatInt(row.Count, 10),
+	}, nil
+}
+
+// BigMapKeys - writes current state of big map keys matched to `req` with decoded keys and values. `req.Ptr` is required.
+func BigMapKeys(ctx context.Context, cfgCtx *config.Context, req bigmapdiff.GetContext, w Writer) error {
+	if req.Ptr == nil {
+		return errors.New("big map pointer is required")
+	}
+	if err := w.WriteHeader(BigMapKeyRow{}.Header()); err != nil {
+		return err
+	}
+
+	req.Size = BatchSize
+
+	var bigMapType *ast.BigMap
+	return cfgCtx.BigMapDiffs.ExportKeys(ctx, req, func(states []bigmapdiff.BigMapState) error {
+		if bigMapType == nil {
+			typ, err := getBigMapType(ctx, cfgCtx, states[0].Contract, *req.Ptr)
+			if err != nil {
+				return err
+			}
+			bigMapType = typ
+		}
+
+		for i := range states {
+			row, err := newBigMapKeyRow(states[i], bigMapType)
+			if err != nil {
+				return err
+			}
+			if err := w.Write(row); err != nil {
+				return err
+			}
+		}
+		return w.Flush()
+	})
+}
+
+func newBigMapKeyRow(state bigmapdiff.BigMapState, bigMapType *ast.BigMap) (row BigMapKeyRow, err error) {
+	row = BigMapKeyRow{
+		Ptr:       state.Ptr,
+		Contract:  state.Contract,
+		KeyHash:   state.KeyHash,
+		Level:     state.LastUpdateLevel,
+		Timestamp: state.LastUpdateTime,
+		IsActive:  !state.Removed,
+		Count:     state.Count,
+	}
+
+	row.Key, err = decodeValue(bigMapType.KeyType, state.Key)
+	if err != nil {
+		return
+	}
+	if !state.Removed {
+		row.Value, err = decodeValue(bigMapType.ValueType, state.Value)
+	}
+	return
+}
+
+// getBigMapType - receives big map type from contract storage which contains the big map
+func getBigMapType(ctx context.Context, cfgCtx *config.Context, contract string, ptr int64) (*ast.BigMap, error) {
+	head, err := cfgCtx.Blocks.Last(ctx)
+	if err != nil {
+		return nil, err
+	}
+	data, err := cfgCtx.Contracts.ScriptPart(ctx, contract, head.Protocol.SymLink, consts.STORAGE)
+	if err != nil {
+		return nil, err
+	}
+	storageType, err := ast.NewTypedAstFromBytes(data)
+	if err != nil {
+		return nil, err
+	}
+
+	storage, err := getStorageWithBigMap(ctx, cfgCtx, contract, ptr)
+	if err != nil {
+		return nil, err
+	}
+	if err := storageType.SettleFromBytes(storage); err != nil {
+		return nil, err
+	}
+
+	bigMapType, ok := storageType.FindBigMapByPtr()[ptr]
+	if !ok {
+		return nil, errors.Errorf("can't find ptr in storage: %d", ptr)
+	}
+	return bigMapType, nil
+}
+
+// getStorageWithBigMap - returns storage of the operation which created the big map. If there is no such operation the last stored contract storage is returned.
+func getStorageWithBigMap(ctx context.Context, cfgCtx *config.Context, contract string, ptr int64) ([]byte, error) {
+	actions, err := cfgCtx.BigMapActions.Get(ctx, ptr, 2, 0)
+	if err != nil {
+		return nil, err
+	}
+	if len(actions) > 0 {
+		operationID := actions[0].OperationID
+		if actions[0].Action == types.BigMapActionRemove && len(actions) >= 2 {
+			operationID = actions[1].OperationID
+		}
+		operation, err := cfgCtx.Operations.GetByID(ctx, operationID)
+		if err != nil {
+			return nil, err
+		}
+		return operation.DeffatedStorage, nil
+	}
+
+	account, err := cfgCtx.Accounts.Get(ctx, contract)
+	if err != nil {
+		return nil, err
+	}
+	operation, err := cfgCtx.Operations.Last(ctx, map[string]interface{}{
+		"destination_id": account.ID,
+		"status":         types.OperationStatusApplied,
+	}, 0)
+	if err != nil {
+		return nil, err
+	}
+	return operation.DeffatedStorage, nil
+}
+
+func decodeValue(typ ast.Node, raw []byte) (*ast.MiguelNode, error) {
+	if len(raw) == 0 {
+		return nil, nil
+	}
+	var data ast.UntypedAST
+	if err := json.Unmarshal(raw, &data); err != nil {
+		return nil, err
+	}
+	node := ast.Copy(typ)
+	if err := node.ParseValue(data[0]); err != nil {
+		return nil, err
+	}
+	return node.ToMiguel()
+}
@@ -0,0 +1,61 @@
+package export
+
+import (
+	"context"
+
+	"github.com/baking-bad/bcdhub/internal/bcd/ast"
+	"github.com/baking-bad/bcdhub/internal/config"
+)
+
+// BatchSize - count of rows which are requested from database at once
+const BatchSize = 1000
+
+// typeCache - keeps script parts during export, so type of every contract is requested once
+type typeCache struct {
+	parts map[string][]byte
+}
+
+func newTypeCache() *typeCache {
+	return &typeCache{
+		parts: make(map[string][]byte),
+	}
+}
+
+// get - returns new typed tree of script part, so it can be settled by caller
+func (tc *typeCache) get(ctx context.Context, cfgCtx *config.Context, address, symLink, part string) (*ast.TypedAst, error) {
+	key := address + symLink + part
+	data, ok := tc.parts[key]
+	if !ok {
+		bytes, err := cfgCtx.Contracts.ScriptPart(ctx, address, symLink, part)
+		if err != nil {
+			return nil, err
+		}
+		tc.parts[key] = bytes
+		data = bytes
+	}
+	return ast.NewTypedAstFromBytes(data)
+}
+
+// nodesCell - returns JSON representation of decoded value for CSV cell. Empty value is written as empty cell.
+func nodesCell(nodes []*ast.MiguelNode) (string, error) {
+	if len(nodes) == 0 {
+		return "", nil
+	}
+	return marshalCell(nodes)
+}
+
+// nodeCell - returns JSON representation of decoded value for CSV cell. Nil value is written as empty cell.
+func nodeCell(node *ast.MiguelNode) (string, error) {
+	if node == nil {
+		return "", nil
+	}
+	return marshalCell(node)
+}
+
+func marshalCell(value any) (string, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
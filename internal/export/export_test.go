@@ -0,0 +1,125 @@
+package export
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/baking-bad/bcdhub/internal/cache"
+	"github.com/baking-bad/bcdhub/internal/config"
+	"github.com/baking-bad/bcdhub/internal/models/account"
+	mock_contract "github.com/baking-bad/bcdhub/internal/models/mock/contract"
+	mock_operation "github.com/baking-bad/bcdhub/internal/models/mock/operation"
+	mock_protocol "github.com/baking-bad/bcdhub/internal/models/mock/protocol"
+	"github.com/baking-bad/bcdhub/internal/models/operation"
+	"github.com/baking-bad/bcdhub/internal/models/protocol"
+	"github.com/baking-bad/bcdhub/internal/models/types"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestOperations(t *testing.T) {
+	const (
+		contractAddress = "KT1Hkg5qeNhfwpKW4fXvq7HGZB9z2EnmCCA9"
+		sourceAddress   = "tz1aCzsYRUgDZBV7zb7Si6q2AobrocFW5qwb"
+		parameterType   = `[{"prim":"or","args":[{"prim":"nat","annots":["%increment"]},{"prim":"nat","annots":["%decrement"]}]}]`
+	)
+	timestamp := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	operations := []operation.Operation{
+		{
+			ID:          1,
+			Level:       100,
+			Counter:     10,
+			Timestamp:   timestamp,
+			Kind:        types.OperationKindTransaction,
+			Status:      types.OperationStatusApplied,
+			ProtocolID:  1,
+			Amount:      1000,
+			Source:      account.Account{Address: sourceAddress},
+			Destination: account.Account{Address: contractAddress},
+			Entrypoint:  types.NullString{Str: "increment", Valid: true},
+			Parameters:  []byte(`{"entrypoint":"increment","value":{"int":"5"}}`),
+		}, {
+			ID:          2,
+			Level:       101,
+			Counter:     11,
+			Timestamp:   timestamp.Add(time.Minute),
+			Kind:        types.OperationKindTransaction,
+			Status:      types.OperationStatusFailed,
+			ProtocolID:  1,
+			Source:      account.Account{Address: sourceAddress},
+			Destination: account.Account{Address: sourceAddress},
+		},
+	}
+
+	tests := []struct {
+		name   string
+		format Format
+		want   string
+	}{
+		{
+			name:   "csv",
+			format: FormatCSV,
+			want: "id,hash,counter,nonce,level,timestamp,kind,status,source,initiator,destination,delegate,amount,fee,consumed_gas,burned,entrypoint,parameters\n" +
+				`1,,10,,100,2023-01-01T00:00:00Z,transaction,applied,tz1aCzsYRUgDZBV7zb7Si6q2AobrocFW5qwb,,KT1Hkg5qeNhfwpKW4fXvq7HGZB9z2EnmCCA9,,1000,0,0,0,increment,"[{""prim"":""nat"",""type"":""nat"",""name"":""increment"",""value"":""5""}]"` + "\n" +
+				"2,,11,,101,2023-01-01T00:01:00Z,transaction,failed,tz1aCzsYRUgDZBV7zb7Si6q2AobrocFW5qwb,,tz1aCzsYRUgDZBV7zb7Si6q2AobrocFW5qwb,,0,0,0,0,,\n",
+		}, {
+			name:   "ndjson",
+			format: FormatNDJSON,
+			want: `{"id":1,"counter":10,"level":100,"timestamp":"2023-01-01T00:00:00Z","kind":"transaction","status":"applied","source":"tz1aCzsYRUgDZBV7zb7Si6q2AobrocFW5qwb","destination":"KT1Hkg5qeNhfwpKW4fXvq7HGZB9z2EnmCCA9","amount":1000,"fee":0,"consumed_gas":0,"burned":0,"entrypoint":"increment","parameters":[{"prim":"nat","type":"nat","name":"increment","value":"5"}]}` + "\n" +
+				`{"id":2,"counter":11,"level":101,"timestamp":"2023-01-01T00:01:00Z","kind":"transaction","status":"failed","source":"tz1aCzsYRUgDZBV7zb7Si6q2AobrocFW5qwb","destination":"tz1aCzsYRUgDZBV7zb7Si6q2AobrocFW5qwb","amount":0,"fee":0,"consumed_gas":0,"burned":0}` + "\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			operationsRepo := mock_operation.NewMockRepository(ctrl)
+			operationsRepo.EXPECT().
+				Export(gomock.Any(), int64(5), operation.HistoryRequest{Limit: BatchSize}, gomock.Any()).
+				DoAndReturn(func(_ context.Context, _ int64, _ operation.HistoryRequest, handler func([]operation.Operation) error) error {
+					return handler(operations)
+				}).
+				Times(1)
+
+			contractsRepo := mock_contract.NewMockRepository(ctrl)
+			contractsRepo.EXPECT().
+				ScriptPart(gomock.Any(), contractAddress, "PtLimaPtLMwf", "parameter").
+				Return([]byte(parameterType), nil).
+				Times(1)
+
+			protocolsRepo := mock_protocol.NewMockRepository(ctrl)
+			protocolsRepo.EXPECT().
+				GetByID(gomock.Any(), int64(1)).
+				Return(protocol.Protocol{ID: 1, SymLink: "PtLimaPtLMwf"}, nil).
+				Times(1)
+
+			cfgCtx := &config.Context{
+				Operations: operationsRepo,
+				Contracts:  contractsRepo,
+				Cache:      cache.NewCache(nil, nil, contractsRepo, protocolsRepo, nil),
+			}
+
+			var buf bytes.Buffer
+			writer, err := NewWriter(tt.format, &buf)
+			require.NoError(t, err)
+
+			err = Operations(context.Background(), cfgCtx, 5, operation.HistoryRequest{}, writer)
+			require.NoError(t, err)
+			require.Equal(t, tt.want, buf.String())
+		})
+	}
+}
+
+func TestNewWriter(t *testing.T) {
+	_, err := NewWriter("xml", &bytes.Buffer{})
+	require.Error(t, err)
+
+	writer, err := NewWriter("", &bytes.Buffer{})
+	require.NoError(t, err)
+	require.IsType(t, &csvWriter{}, writer)
+}
@@ -0,0 +1,152 @@
+package export
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/baking-bad/bcdhub/internal/bcd"
+	"github.com/baking-bad/bcdhub/internal/bcd/ast"
+	"github.com/baking-bad/bcdhub/internal/bcd/consts"
+	"github.com/baking-bad/bcdhub/internal/bcd/encoding"
+	bcdTypes "github.com/baking-bad/bcdhub/internal/bcd/types"
+	"github.com/baking-bad/bcdhub/internal/config"
+	"github.com/baking-bad/bcdhub/internal/models/operation"
+)
+
+// OperationRow -
+type OperationRow struct {
+	ID          int64             `json:"id"`
+	Hash        string            `json:"hash,omitempty"`
+	Counter     int64             `json:"counter"`
+	Nonce       *int64            `json:"nonce,omitempty"`
+	Level       int64             `json:"level"`
+	Timestamp   time.Time         `json:"timestamp"`
+	Kind        string            `json:"kind"`
+	Status      string            `json:"status"`
+	Source      string            `json:"source,omitempty"`
+	Initiator   string            `json:"initiator,omitempty"`
+	Destination string            `json:"destination,omitempty"`
+	Delegate    string            `json:"delegate,omitempty"`
+	Amount      int64             `json:"amount"`
+	Fee         int64             `json:"fee"`
+	ConsumedGas int64             `json:"consumed_gas"`
+	Burned      int64             `json:"burned"`
+	Entrypoint  string            `json:"entrypoint,omitempty"`
+	Parameters  []*ast.MiguelNode `json:"parameters,omitempty"`
+}
+
+// Header -
+func (OperationRow) Header() []string {
+	return []string{
+		"id", "hash", "counter", "nonce", "level", "timestamp", "kind", "status",
+		"source", "initiator", "destination", "delegate",
+		"amount", "fee", "consumed_gas", "burned", "entrypoint", "parameters",
+	}
+}
+
+// Record -
+func (row OperationRow) Record() ([]string, error) {
+	parameters, err := nodesCell(row.Parameters)
+	if err != nil {
+		return nil, err
+	}
+	var nonce string
+	if row.Nonce != nil {
+		nonce = strconv.FormatInt(*row.Nonce, 10)
+	}
+	return []string{
+		strconv.FormatInt(row.ID, 10),
+		row.Hash,
+		strconv.FormatInt(row.Counter, 10),
+		nonce,
+		strconv.FormatInt(row.Level, 10),
+		row.Timestamp.UTC().Format(time.RFC3339),
+		row.Kind,
+		row.Status,
+		row.Source,
+		row.Initiator,
+		row.Destination,
+		row.Delegate,
+		strconv.FormatInt(row.Amount, 10),
+		strconv.FormatInt(row.Fee, 10),
+		strconv.FormatInt(row.ConsumedGas, 10),
+		strconv.FormatInt(row.Burned, 10),
+		row.Entrypoint,
+		parameters,
+	}, nil
+}
+
+// Operations - writes all operations of account matched to `req` with decoded parameters. Operations are written from oldest to newest.
+func Operations(ctx context.Context, cfgCtx *config.Context, accountID int64, req operation.HistoryRequest, w Writer) error {
+	if err := w.WriteHeader(OperationRow{}.Header()); err != nil {
+		return err
+	}
+
+	req.Limit = BatchSize
+	scripts := newTypeCache()
+	return cfgCtx.Operations.Export(ctx, accountID, req, func(operations []operation.Operation) error {
+		for i := range operations {
+			row, err := newOperationRow(ctx, cfgCtx, scripts, operations[i])
+			if err != nil {
+				return err
+			}
+			if err := w.Write(row); err != nil {
+				return err
+			}
+		}
+		return w.Flush()
+	})
+}
+
+func newOperationRow(ctx context.Context, cfgCtx *config.Context, scripts *typeCache, op operation.Operation) (OperationRow, error) {
+	row := OperationRow{
+		ID:          op.ID,
+		Counter:     op.Counter,
+		Nonce:       op.Nonce,
+		Level:       op.Level,
+		Timestamp:   op.Timestamp,
+		Kind:        op.Kind.String(),
+		Status:      op.Status.String(),
+		Source:      op.Source.Address,
+		Initiator:   op.Initiator.Address,
+		Destination: op.Destination.Address,
+		Delegate:    op.Delegate.Address,
+		Amount:      op.Amount,
+		Fee:         op.Fee,
+		ConsumedGas: op.ConsumedGas,
+		Burned:      op.Burned,
+		Entrypoint:  op.Entrypoint.String(),
+	}
+	if len(op.Hash) > 0 {
+		row.Hash = encoding.MustEncodeOperationHash(op.Hash)
+	}
+
+	if !op.IsCall() || len(op.Parameters) == 0 || !bcd.IsContract(row.Destination) {
+		return row, nil
+	}
+
+	proto, err := cfgCtx.Cache.ProtocolByID(ctx, op.ProtocolID)
+	if err != nil {
+		return row, err
+	}
+	parameterType, err := scripts.get(ctx, cfgCtx, row.Destination, proto.SymLink, consts.PARAMETER)
+	if err != nil {
+		return row, err
+	}
+	row.Parameters = decodeParameters(op.Parameters, parameterType)
+	return row, nil
+}
+
+// decodeParameters - returns nil if parameters can not be decoded (e.g. operation failed because of invalid parameters), so single broken operation does not interrupt export
+func decodeParameters(data []byte, parameterType *ast.TypedAst) []*ast.MiguelNode {
+	tree, err := parameterType.FromParameters(bcdTypes.NewParameters(data))
+	if err != nil {
+		return nil
+	}
+	miguel, err := tree.ToMiguel()
+	if err != nil {
+		return nil
+	}
+	return miguel
+}
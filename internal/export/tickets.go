@@ -0,0 +1,118 @@
+package export
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/baking-bad/bcdhub/internal/bcd/ast"
+	"github.com/baking-bad/bcdhub/internal/bcd/encoding"
+	"github.com/baking-bad/bcdhub/internal/config"
+	"github.com/baking-bad/bcdhub/internal/models/ticket"
+)
+
+// TicketUpdateRow -
+type TicketUpdateRow struct {
+	ID            int64           `json:"id"`
+	OperationID   int64           `json:"operation_id"`
+	OperationHash string          `json:"operation_hash,omitempty"`
+	Level         int64           `json:"level"`
+	Timestamp     time.Time       `json:"timestamp"`
+	Ticketer      string          `json:"ticketer"`
+	TicketID      int64           `json:"ticket_id"`
+	Content       *ast.MiguelNode `json:"content,omitempty"`
+	Account       string          `json:"account"`
+	Amount        string          `json:"amount"`
+}
+
+// Header -
+func (TicketUpdateRow) Header() []string {
+	return []string{"id", "operation_id", "operation_hash", "level", "timestamp", "ticketer", "ticket_id", "content", "account", "amount"}
+}
+
+// Record -
+func (row TicketUpdateRow) Record() ([]string, error) {
+	content, err := nodeCell(row.Content)
+	if err != nil {
+		return nil, err
+	}
+	return []string{
+		strconv.FormatInt(row.ID, 10),
+		strconv.FormatInt(row.OperationID, 10),
+		row.OperationHash,
+		strconv.FormatInt(row.Level, 10),
+		row.Timestamp.UTC().Format(time.RFC3339),
+		row.Ticketer,
+		strconv.FormatInt(row.TicketID, 10),
+		content,
+		row.Account,
+		row.Amount,
+	}, nil
+}
+
+// TicketUpdates - writes ticket updates matched to `req` with decoded ticket contents
+func TicketUpdates(ctx context.Context, cfgCtx *config.Context, req ticket.UpdatesRequest, w Writer) error {
+	if err := w.WriteHeader(TicketUpdateRow{}.Header()); err != nil {
+		return err
+	}
+
+	req.Limit = BatchSize
+
+	// updates of single operation are neighbours, so only hash of the last operation is kept
+	var (
+		lastOperationID   int64
+		lastOperationHash string
+	)
+	return cfgCtx.Tickets.ExportUpdates(ctx, req, func(updates []ticket.TicketUpdate) error {
+		for i := range updates {
+			row, err := newTicketUpdateRow(updates[i])
+			if err != nil {
+				return err
+			}
+
+			if updates[i].OperationId != lastOperationID {
+				operation, err := cfgCtx.Operations.GetByID(ctx, updates[i].OperationId)
+				if err != nil {
+					return err
+				}
+				lastOperationID = updates[i].OperationId
+				lastOperationHash = encoding.MustEncodeOperationHash(operation.Hash)
+			}
+			row.OperationHash = lastOperationHash
+
+			if err := w.Write(row); err != nil {
+				return err
+			}
+		}
+		return w.Flush()
+	})
+}
+
+func newTicketUpdateRow(update ticket.TicketUpdate) (TicketUpdateRow, error) {
+	row := TicketUpdateRow{
+		ID:          update.ID,
+		OperationID: update.OperationId,
+		Level:       update.Level,
+		Timestamp:   update.Timestamp,
+		Ticketer:    update.Ticket.Ticketer.Address,
+		TicketID:    update.TicketId,
+		Account:     update.Account.Address,
+		Amount:      update.Amount.String(),
+	}
+
+	content, err := ast.NewTypedAstFromBytes(update.Ticket.ContentType)
+	if err != nil {
+		return row, err
+	}
+	if err := content.SettleFromBytes(update.Ticket.Content); err != nil {
+		return row, err
+	}
+	miguel, err := content.ToMiguel()
+	if err != nil {
+		return row, err
+	}
+	if len(miguel) > 0 {
+		row.Content = miguel[0]
+	}
+	return row, nil
+}
@@ -0,0 +1,130 @@
+package export
+
+import (
+	"bufio"
+	"encoding/csv"
+	"io"
+	"net/http"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/pkg/errors"
+)
+
+var json = jsoniter.ConfigCompatibleWithStandardLibrary
+
+// Format - output format of export
+type Format string
+
+// Formats
+const (
+	FormatCSV    Format = "csv"
+	FormatNDJSON Format = "ndjson"
+)
+
+// ContentType - returns MIME type of format
+func (f Format) ContentType() string {
+	switch f {
+	case FormatNDJSON:
+		return "application/x-ndjson"
+	default:
+		return "text/csv"
+	}
+}
+
+// Row - exported item. Row is written as a record of `Header` columns in CSV and as JSON object in NDJSON.
+type Row interface {
+	Header() []string
+	Record() ([]string, error)
+}
+
+// Writer - writes exported rows to output
+type Writer interface {
+	WriteHeader(columns []string) error
+	Write(row Row) error
+	Flush() error
+}
+
+// NewWriter - creates writer of `format`. Empty format means CSV.
+func NewWriter(format Format, output io.Writer) (Writer, error) {
+	switch format {
+	case FormatCSV, "":
+		return &csvWriter{
+			output: output,
+			csv:    csv.NewWriter(output),
+		}, nil
+	case FormatNDJSON:
+		return &ndjsonWriter{
+			output: output,
+			buf:    bufio.NewWriter(output),
+		}, nil
+	default:
+		return nil, errors.Errorf("unknown export format: %s", format)
+	}
+}
+
+type csvWriter struct {
+	output io.Writer
+	csv    *csv.Writer
+}
+
+// WriteHeader -
+func (w *csvWriter) WriteHeader(columns []string) error {
+	return w.csv.Write(columns)
+}
+
+// Write -
+func (w *csvWriter) Write(row Row) error {
+	record, err := row.Record()
+	if err != nil {
+		return err
+	}
+	return w.csv.Write(record)
+}
+
+// Flush -
+func (w *csvWriter) Flush() error {
+	w.csv.Flush()
+	if err := w.csv.Error(); err != nil {
+		return err
+	}
+	flush(w.output)
+	return nil
+}
+
+type ndjsonWriter struct {
+	output io.Writer
+	buf    *bufio.Writer
+}
+
+// WriteHeader - NDJSON has no header
+func (w *ndjsonWriter) WriteHeader(columns []string) error {
+	return nil
+}
+
+// Write -
+func (w *ndjsonWriter) Write(row Row) error {
+	data, err := json.Marshal(row)
+	if err != nil {
+		return err
+	}
+	if _, err := w.buf.Write(data); err != nil {
+		return err
+	}
+	return w.buf.WriteByte('\n')
+}
+
+// Flush -
+func (w *ndjsonWriter) Flush() error {
+	if err := w.buf.Flush(); err != nil {
+		return err
+	}
+	flush(w.output)
+	return nil
+}
+
+// flush - sends written data to HTTP client immediately if output is HTTP response
+func flush(output io.Writer) {
+	if flusher, ok := output.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
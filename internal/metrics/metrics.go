@@ -0,0 +1,161 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/rs/zerolog/log"
+)
+
+const namespace = "bcd"
+
+// kinds of database transactions
+const (
+	TransactionKindSave     = "save"
+	TransactionKindRollback = "rollback"
+	TransactionKindBatch    = "batch"
+)
+
+// results of database transactions
+const (
+	TransactionResultCommit   = "commit"
+	TransactionResultRollback = "rollback"
+)
+
+var (
+	// IndexerLevel - last indexed level
+	IndexerLevel = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "indexer",
+		Name:      "level",
+		Help:      "Last indexed level",
+	}, []string{"network"})
+
+	// IndexerHeadLag - count of levels which indexer is behind node head
+	IndexerHeadLag = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "indexer",
+		Name:      "head_lag_blocks",
+		Help:      "Count of levels which indexer is behind node head",
+	}, []string{"network"})
+
+	// BlockProcessingDuration - time of parsing and saving block
+	BlockProcessingDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "indexer",
+		Name:      "block_processing_duration_seconds",
+		Help:      "Time of parsing and saving block",
+		Buckets:   prometheus.ExponentialBuckets(0.01, 2, 12),
+	}, []string{"network"})
+
+	// ReceiverQueueDepth - count of received blocks which wait for processing
+	ReceiverQueueDepth = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "indexer",
+		Name:      "receiver_queue_depth",
+		Help:      "Count of received blocks which wait for processing",
+	}, []string{"network"})
+
+	// Rollbacks - count of rollbacks
+	Rollbacks = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "indexer",
+		Name:      "rollbacks_total",
+		Help:      "Count of rollbacks",
+	}, []string{"network"})
+
+	// ReorgDepth - count of indexed blocks which were replaced by chain reorganisation
+	ReorgDepth = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "indexer",
+		Name:      "reorg_depth_blocks",
+		Help:      "Count of indexed blocks which were replaced by chain reorganisation",
+		Buckets:   prometheus.ExponentialBuckets(1, 2, 8),
+	}, []string{"network"})
+
+	// RPCRequestDuration - latency of node RPC requests
+	RPCRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "rpc",
+		Name:      "request_duration_seconds",
+		Help:      "Latency of node RPC requests",
+		Buckets:   prometheus.ExponentialBuckets(0.005, 2, 14),
+	}, []string{"network", "method"})
+
+	// RPCErrors - count of failed node RPC requests
+	RPCErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "rpc",
+		Name:      "errors_total",
+		Help:      "Count of failed node RPC requests",
+	}, []string{"network", "method"})
+
+	// PostgresTransactionDuration - time from begin to end of database transaction
+	PostgresTransactionDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "postgres",
+		Name:      "transaction_duration_seconds",
+		Help:      "Time from begin to commit or rollback of database transaction",
+		Buckets:   prometheus.ExponentialBuckets(0.005, 2, 14),
+	}, []string{"kind", "result"})
+
+	// APIRequestDuration - latency of API requests
+	APIRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "api",
+		Name:      "request_duration_seconds",
+		Help:      "Latency of API requests",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+)
+
+// Handler - returns HTTP handler which exposes metrics in Prometheus text format
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
+
+// Server - standalone HTTP server of `/metrics` endpoint for services without own HTTP API
+type Server struct {
+	server *http.Server
+}
+
+// NewServer -
+func NewServer(bind string) *Server {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", Handler())
+
+	return &Server{
+		server: &http.Server{
+			Addr:              bind,
+			Handler:           mux,
+			ReadHeaderTimeout: time.Second * 10,
+		},
+	}
+}
+
+// Start - starts listening in background
+func (s *Server) Start() {
+	go func() {
+		log.Info().Str("bind", s.server.Addr).Msg("serving metrics")
+		if err := s.server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Err(err).Msg("metrics server")
+		}
+	}()
+}
+
+// Close -
+func (s *Server) Close() error {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	return s.server.Shutdown(ctx)
+}
+
+// ObserveSince - observes duration since `start` in seconds
+func ObserveSince(observer prometheus.Observer, start time.Time) {
+	observer.Observe(time.Since(start).Seconds())
+}
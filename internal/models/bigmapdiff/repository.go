@@ -9,9 +9,12 @@ type Repository interface {
 	GetByPtr(ctx context.Context, contract string, ptr int64) ([]BigMapState, error)
 	GetByPtrAndKeyHash(ctx context.Context, ptr int64, keyHash string, size int64, offset int64) ([]BigMapDiff, int64, error)
 	GetForAddress(ctx context.Context, address string) ([]BigMapState, error)
+	GetAtLevel(ctx context.Context, contract string, level int64, ptrs ...int64) ([]BigMapDiff, error)
 	Count(ctx context.Context, ptr int64) (int, error)
 	Current(ctx context.Context, keyHash string, ptr int64) (BigMapState, error)
 	Previous(ctx context.Context, diffs []BigMapDiff) ([]BigMapDiff, error)
 	GetStats(ctx context.Context, ptr int64) (Stats, error)
 	Keys(ctx context.Context, reqCtx GetContext) (states []BigMapState, err error)
+	ExportKeys(ctx context.Context, reqCtx GetContext, handler func(states []BigMapState) error) error
+	KeysAtLevel(ctx context.Context, reqCtx GetContext, level int64) (states []BigMapState, err error)
 }
@@ -11,11 +11,12 @@ import (
 type Block struct {
 	bun.BaseModel `bun:"blocks"`
 
-	ID         int64     `bun:"id,pk,notnull,autoincrement"`
-	Hash       string    `bun:"hash,type:text"`
-	Timestamp  time.Time `bun:"timestamp,pk,notnull"`
-	Level      int64     `bun:"level"`
-	ProtocolID int64     `bun:"protocol_id,type:SMALLINT"`
+	ID          int64     `bun:"id,pk,notnull,autoincrement"`
+	Hash        string    `bun:"hash,type:text"`
+	Predecessor string    `bun:"predecessor,type:text"`
+	Timestamp   time.Time `bun:"timestamp,pk,notnull"`
+	Level       int64     `bun:"level"`
+	ProtocolID  int64     `bun:"protocol_id,type:SMALLINT"`
 
 	Protocol protocol.Protocol `bun:",rel:belongs-to"`
 }
@@ -6,32 +6,45 @@ import (
 	"github.com/baking-bad/bcdhub/internal/models/bigmapdiff"
 	"github.com/baking-bad/bcdhub/internal/models/block"
 	"github.com/baking-bad/bcdhub/internal/models/contract"
+	contractmetadata "github.com/baking-bad/bcdhub/internal/models/contract_metadata"
 	"github.com/baking-bad/bcdhub/internal/models/migration"
 	"github.com/baking-bad/bcdhub/internal/models/operation"
 	"github.com/baking-bad/bcdhub/internal/models/protocol"
 	smartrollup "github.com/baking-bad/bcdhub/internal/models/smart_rollup"
 	"github.com/baking-bad/bcdhub/internal/models/stats"
 	"github.com/baking-bad/bcdhub/internal/models/ticket"
+	"github.com/baking-bad/bcdhub/internal/models/token"
 )
 
 // Document names
 const (
-	DocAccounts        = "accounts"
-	DocBigMapActions   = "big_map_actions"
-	DocBigMapDiff      = "big_map_diffs"
-	DocBigMapState     = "big_map_states"
-	DocBlocks          = "blocks"
-	DocContracts       = "contracts"
-	DocGlobalConstants = "global_constants"
-	DocMigrations      = "migrations"
-	DocOperations      = "operations"
-	DocProtocol        = "protocols"
-	DocScripts         = "scripts"
-	DocTicketUpdates   = "ticket_updates"
-	DocTickets         = "tickets"
-	DocTicketBalances  = "ticket_balances"
-	DocSmartRollups    = "smart_rollup"
-	DocStats           = "stats"
+	DocAccounts         = "accounts"
+	DocBigMapActions    = "big_map_actions"
+	DocBigMapDiff       = "big_map_diffs"
+	DocBigMapState      = "big_map_states"
+	DocBlocks           = "blocks"
+	DocContracts        = "contracts"
+	DocContractMetadata = "contract_metadata"
+	DocGlobalConstants  = "global_constants"
+	DocMigrations       = "migrations"
+	DocOperations       = "operations"
+	DocProtocol         = "protocols"
+	DocScripts          = "scripts"
+	DocTicketUpdates    = "ticket_updates"
+	DocTickets          = "tickets"
+	DocTicketBalances   = "ticket_balances"
+	DocTokenTransfers   = "token_transfers"
+	DocTokenBalances    = "token_balances"
+	DocSmartRollups     = "smart_rollup"
+	DocSrCommitments    = "smart_rollup_commitments"
+	DocSrCementations   = "smart_rollup_cementations"
+	DocSrRefutations    = "smart_rollup_refutations"
+	DocSrBondRecoveries = "smart_rollup_bond_recoveries"
+	DocSrOutboxMessages = "smart_rollup_outbox_messages"
+	DocSrInboxMessages  = "smart_rollup_inbox_messages"
+	DocStats            = "stats"
+	DocStatsSeries      = "stats_series"
+	DocStatsCallers     = "stats_callers"
 )
 
 // AllDocuments - returns all document names
@@ -43,6 +56,7 @@ func AllDocuments() []string {
 		DocBigMapState,
 		DocBlocks,
 		DocContracts,
+		DocContractMetadata,
 		DocGlobalConstants,
 		DocMigrations,
 		DocOperations,
@@ -51,8 +65,18 @@ func AllDocuments() []string {
 		DocTicketUpdates,
 		DocTicketBalances,
 		DocTickets,
+		DocTokenTransfers,
+		DocTokenBalances,
 		DocSmartRollups,
+		DocSrCommitments,
+		DocSrCementations,
+		DocSrRefutations,
+		DocSrBondRecoveries,
+		DocSrOutboxMessages,
+		DocSrInboxMessages,
 		DocStats,
+		DocStatsSeries,
+		DocStatsCallers,
 	}
 }
 
@@ -68,14 +92,25 @@ func AllModels() []Model {
 		&ticket.Ticket{},
 		&ticket.TicketUpdate{},
 		&ticket.Balance{},
+		&token.Transfer{},
+		&token.Balance{},
 		&operation.Operation{},
 		&contract.GlobalConstant{},
 		&contract.Script{},
 		&contract.ScriptConstants{},
 		&contract.Contract{},
+		&contractmetadata.ContractMetadata{},
 		&migration.Migration{},
 		&smartrollup.SmartRollup{},
+		&smartrollup.Commitment{},
+		&smartrollup.Cementation{},
+		&smartrollup.Refutation{},
+		&smartrollup.BondRecovery{},
+		&smartrollup.OutboxMessage{},
+		&smartrollup.InboxMessage{},
 		&stats.Stats{},
+		&stats.Series{},
+		&stats.Caller{},
 	}
 }
 
@@ -0,0 +1,88 @@
+package contractmetadata
+
+import (
+	"time"
+
+	"github.com/baking-bad/bcdhub/internal/models/contract"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/lib/pq"
+	"github.com/uptrace/bun"
+)
+
+var json = jsoniter.ConfigCompatibleWithStandardLibrary
+
+// ContractMetadata - resolved TZIP-16 metadata of contract. Every resolved version is kept by level, so rollback restores the previous one.
+type ContractMetadata struct {
+	bun.BaseModel `bun:"contract_metadata"`
+
+	ID          int64          `bun:"id,pk,notnull,autoincrement"`
+	Address     string         `bun:"address,notnull,unique:contract_metadata_address_level"`
+	Level       int64          `bun:"level,notnull,unique:contract_metadata_address_level"`
+	Timestamp   time.Time      `bun:"timestamp"`
+	Ptr         int64          `bun:"ptr"`
+	URI         string         `bun:"uri"`
+	Name        string         `bun:"name"`
+	Description string         `bun:"description"`
+	Version     string         `bun:"version"`
+	Interfaces  pq.StringArray `bun:",type:text[]"`
+	Metadata    []byte         `bun:",type:bytea"`
+}
+
+// GetID -
+func (m *ContractMetadata) GetID() int64 {
+	return m.ID
+}
+
+func (ContractMetadata) TableName() string {
+	return "contract_metadata"
+}
+
+// LogFields -
+func (m *ContractMetadata) LogFields() map[string]interface{} {
+	return map[string]interface{}{
+		"address": m.Address,
+		"block":   m.Level,
+		"uri":     m.URI,
+	}
+}
+
+// Views - returns off-chain views of metadata which have michelson storage implementation
+func (m *ContractMetadata) Views() (contract.Views, error) {
+	if len(m.Metadata) == 0 {
+		return nil, nil
+	}
+	var metadata Metadata
+	if err := json.Unmarshal(m.Metadata, &metadata); err != nil {
+		return nil, err
+	}
+	return metadata.StorageViews(), nil
+}
+
+// Metadata - TZIP-16 metadata document
+type Metadata struct {
+	Name        string         `json:"name,omitempty"`
+	Description string         `json:"description,omitempty"`
+	Version     string         `json:"version,omitempty"`
+	Interfaces  []string       `json:"interfaces,omitempty"`
+	Views       contract.Views `json:"views,omitempty"`
+}
+
+// StorageViews - returns views which have at least one `michelsonStorageView` implementation. Other implementations (e.g. `restApiQuery`) are skipped.
+func (m Metadata) StorageViews() contract.Views {
+	views := make(contract.Views, 0, len(m.Views))
+	for _, view := range m.Views {
+		implementations := make([]contract.ViewImplementation, 0, len(view.Implementations))
+		for _, impl := range view.Implementations {
+			if len(impl.MichelsonStorageView.Code) == 0 || impl.MichelsonStorageView.Empty() {
+				continue
+			}
+			implementations = append(implementations, impl)
+		}
+		if len(implementations) == 0 {
+			continue
+		}
+		view.Implementations = implementations
+		views = append(views, view)
+	}
+	return views
+}
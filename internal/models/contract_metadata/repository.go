@@ -0,0 +1,22 @@
+package contractmetadata
+
+import (
+	"context"
+	"time"
+)
+
+// Update - change of `%metadata` big map which isn't followed by resolved metadata
+type Update struct {
+	Address   string
+	Ptr       int64
+	Level     int64
+	Timestamp time.Time
+}
+
+//go:generate mockgen -source=$GOFILE -destination=../mock/contract_metadata/mock.go -package=contract_metadata -typed
+type Repository interface {
+	// Get - returns the last resolved version of contract metadata
+	Get(ctx context.Context, address string) (ContractMetadata, error)
+	// Unresolved - returns changes of `%metadata` big maps made after the last resolved version of contract metadata, ordered by level
+	Unresolved(ctx context.Context, limit, offset int) ([]Update, error)
+}
@@ -8,12 +8,14 @@ import (
 	"github.com/baking-bad/bcdhub/internal/models/bigmapdiff"
 	"github.com/baking-bad/bcdhub/internal/models/block"
 	"github.com/baking-bad/bcdhub/internal/models/contract"
+	contractmetadata "github.com/baking-bad/bcdhub/internal/models/contract_metadata"
 	"github.com/baking-bad/bcdhub/internal/models/migration"
 	"github.com/baking-bad/bcdhub/internal/models/operation"
 	"github.com/baking-bad/bcdhub/internal/models/protocol"
 	smartrollup "github.com/baking-bad/bcdhub/internal/models/smart_rollup"
 	"github.com/baking-bad/bcdhub/internal/models/stats"
 	"github.com/baking-bad/bcdhub/internal/models/ticket"
+	"github.com/baking-bad/bcdhub/internal/models/token"
 )
 
 //go:generate mockgen -source=$GOFILE -destination=mock/general.go -package=mock -typed
@@ -21,7 +23,9 @@ type GeneralRepository interface {
 	InitDatabase(ctx context.Context) error
 	TablesExist(ctx context.Context) bool
 	CreateIndex(ctx context.Context, name, columns string, model any) error
+	CreateGinIndex(ctx context.Context, name, columns string, model any) error
 	IsRecordNotFound(err error) bool
+	Notify(ctx context.Context, channel, payload string) error
 
 	// Drop - drops full database
 	Drop(ctx context.Context) error
@@ -45,8 +49,13 @@ type Transaction interface {
 	Block(ctx context.Context, block *block.Block) error
 	Protocol(ctx context.Context, proto *protocol.Protocol) error
 	UpdateStats(ctx context.Context, stats stats.Stats) error
+	StatsSeries(ctx context.Context, series ...*stats.Series) error
+	StatsCallers(ctx context.Context, callers ...*stats.Caller) error
 	Tickets(ctx context.Context, tickets ...*ticket.Ticket) error
 	TicketBalances(ctx context.Context, balances ...*ticket.Balance) error
+	TokenTransfers(ctx context.Context, transfers ...*token.Transfer) error
+	TokenBalances(ctx context.Context, balances ...*token.Balance) error
+	ContractMetadata(ctx context.Context, metadata *contractmetadata.ContractMetadata) error
 
 	ToBabylon(ctx context.Context) error
 	BabylonUpdateNonDelegator(ctx context.Context, contract *contract.Contract) error
@@ -55,6 +64,7 @@ type Transaction interface {
 	JakartaUpdateNonDelegator(ctx context.Context, contract *contract.Contract) error
 	BabylonUpdateBigMapDiffs(ctx context.Context, contract string, ptr int64) (int, error)
 	DeleteBigMapStatesByContract(ctx context.Context, contract string) ([]bigmapdiff.BigMapState, error)
+	AccountCounters(ctx context.Context, accountID int64) (account.Account, operation.AccountCounters, error)
 
 	Commit() error
 	Rollback() error
@@ -19,8 +19,10 @@ type Migration struct {
 	Timestamp      time.Time `bun:"timestamp,pk,notnull"`
 	Level          int64
 	Kind           types.MigrationKind `bun:"kind,type:SMALLINT"`
-	ContractID     int64
-	Contract       contract.Contract `bun:"rel:belongs-to"`
+	// DeffatedStorage - storage of contract after migration. It's set for update migrations only.
+	DeffatedStorage []byte `bun:",type:bytea"`
+	ContractID      int64
+	Contract        contract.Contract `bun:"rel:belongs-to"`
 }
 
 // GetID -
@@ -119,6 +119,44 @@ func (c *MockRepositoryCurrentCall) DoAndReturn(f func(context.Context, string,
 	return c
 }
 
+// ExportKeys mocks base method.
+func (m *MockRepository) ExportKeys(ctx context.Context, reqCtx bigmapdiff.GetContext, handler func([]bigmapdiff.BigMapState) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExportKeys", ctx, reqCtx, handler)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ExportKeys indicates an expected call of ExportKeys.
+func (mr *MockRepositoryMockRecorder) ExportKeys(ctx, reqCtx, handler any) *MockRepositoryExportKeysCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExportKeys", reflect.TypeOf((*MockRepository)(nil).ExportKeys), ctx, reqCtx, handler)
+	return &MockRepositoryExportKeysCall{Call: call}
+}
+
+// MockRepositoryExportKeysCall wrap *gomock.Call
+type MockRepositoryExportKeysCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockRepositoryExportKeysCall) Return(arg0 error) *MockRepositoryExportKeysCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockRepositoryExportKeysCall) Do(f func(context.Context, bigmapdiff.GetContext, func([]bigmapdiff.BigMapState) error) error) *MockRepositoryExportKeysCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockRepositoryExportKeysCall) DoAndReturn(f func(context.Context, bigmapdiff.GetContext, func([]bigmapdiff.BigMapState) error) error) *MockRepositoryExportKeysCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // Get mocks base method.
 func (m *MockRepository) Get(ctx context.Context, reqCtx bigmapdiff.GetContext) ([]bigmapdiff.Bucket, error) {
 	m.ctrl.T.Helper()
@@ -158,6 +196,50 @@ func (c *MockRepositoryGetCall) DoAndReturn(f func(context.Context, bigmapdiff.G
 	return c
 }
 
+// GetAtLevel mocks base method.
+func (m *MockRepository) GetAtLevel(ctx context.Context, contract string, level int64, ptrs ...int64) ([]bigmapdiff.BigMapDiff, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, contract, level}
+	for _, a := range ptrs {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "GetAtLevel", varargs...)
+	ret0, _ := ret[0].([]bigmapdiff.BigMapDiff)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAtLevel indicates an expected call of GetAtLevel.
+func (mr *MockRepositoryMockRecorder) GetAtLevel(ctx, contract, level any, ptrs ...any) *MockRepositoryGetAtLevelCall {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, contract, level}, ptrs...)
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAtLevel", reflect.TypeOf((*MockRepository)(nil).GetAtLevel), varargs...)
+	return &MockRepositoryGetAtLevelCall{Call: call}
+}
+
+// MockRepositoryGetAtLevelCall wrap *gomock.Call
+type MockRepositoryGetAtLevelCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockRepositoryGetAtLevelCall) Return(arg0 []bigmapdiff.BigMapDiff, arg1 error) *MockRepositoryGetAtLevelCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockRepositoryGetAtLevelCall) Do(f func(context.Context, string, int64, ...int64) ([]bigmapdiff.BigMapDiff, error)) *MockRepositoryGetAtLevelCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockRepositoryGetAtLevelCall) DoAndReturn(f func(context.Context, string, int64, ...int64) ([]bigmapdiff.BigMapDiff, error)) *MockRepositoryGetAtLevelCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // GetByPtr mocks base method.
 func (m *MockRepository) GetByPtr(ctx context.Context, contract string, ptr int64) ([]bigmapdiff.BigMapState, error) {
 	m.ctrl.T.Helper()
@@ -393,6 +475,45 @@ func (c *MockRepositoryKeysCall) DoAndReturn(f func(context.Context, bigmapdiff.
 	return c
 }
 
+// KeysAtLevel mocks base method.
+func (m *MockRepository) KeysAtLevel(ctx context.Context, reqCtx bigmapdiff.GetContext, level int64) ([]bigmapdiff.BigMapState, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "KeysAtLevel", ctx, reqCtx, level)
+	ret0, _ := ret[0].([]bigmapdiff.BigMapState)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// KeysAtLevel indicates an expected call of KeysAtLevel.
+func (mr *MockRepositoryMockRecorder) KeysAtLevel(ctx, reqCtx, level any) *MockRepositoryKeysAtLevelCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "KeysAtLevel", reflect.TypeOf((*MockRepository)(nil).KeysAtLevel), ctx, reqCtx, level)
+	return &MockRepositoryKeysAtLevelCall{Call: call}
+}
+
+// MockRepositoryKeysAtLevelCall wrap *gomock.Call
+type MockRepositoryKeysAtLevelCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockRepositoryKeysAtLevelCall) Return(states []bigmapdiff.BigMapState, err error) *MockRepositoryKeysAtLevelCall {
+	c.Call = c.Call.Return(states, err)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockRepositoryKeysAtLevelCall) Do(f func(context.Context, bigmapdiff.GetContext, int64) ([]bigmapdiff.BigMapState, error)) *MockRepositoryKeysAtLevelCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockRepositoryKeysAtLevelCall) DoAndReturn(f func(context.Context, bigmapdiff.GetContext, int64) ([]bigmapdiff.BigMapState, error)) *MockRepositoryKeysAtLevelCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // Previous mocks base method.
 func (m *MockRepository) Previous(ctx context.Context, diffs []bigmapdiff.BigMapDiff) ([]bigmapdiff.BigMapDiff, error) {
 	m.ctrl.T.Helper()
@@ -18,12 +18,14 @@ import (
 	bigmapdiff "github.com/baking-bad/bcdhub/internal/models/bigmapdiff"
 	block "github.com/baking-bad/bcdhub/internal/models/block"
 	contract "github.com/baking-bad/bcdhub/internal/models/contract"
+	contractmetadata "github.com/baking-bad/bcdhub/internal/models/contract_metadata"
 	migration "github.com/baking-bad/bcdhub/internal/models/migration"
 	operation "github.com/baking-bad/bcdhub/internal/models/operation"
 	protocol "github.com/baking-bad/bcdhub/internal/models/protocol"
 	smartrollup "github.com/baking-bad/bcdhub/internal/models/smart_rollup"
 	stats "github.com/baking-bad/bcdhub/internal/models/stats"
 	ticket "github.com/baking-bad/bcdhub/internal/models/ticket"
+	token "github.com/baking-bad/bcdhub/internal/models/token"
 	gomock "go.uber.org/mock/gomock"
 )
 
@@ -51,6 +53,44 @@ func (m *MockGeneralRepository) EXPECT() *MockGeneralRepositoryMockRecorder {
 	return m.recorder
 }
 
+// CreateGinIndex mocks base method.
+func (m *MockGeneralRepository) CreateGinIndex(ctx context.Context, name, columns string, model any) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateGinIndex", ctx, name, columns, model)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateGinIndex indicates an expected call of CreateGinIndex.
+func (mr *MockGeneralRepositoryMockRecorder) CreateGinIndex(ctx, name, columns, model any) *MockGeneralRepositoryCreateGinIndexCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateGinIndex", reflect.TypeOf((*MockGeneralRepository)(nil).CreateGinIndex), ctx, name, columns, model)
+	return &MockGeneralRepositoryCreateGinIndexCall{Call: call}
+}
+
+// MockGeneralRepositoryCreateGinIndexCall wrap *gomock.Call
+type MockGeneralRepositoryCreateGinIndexCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockGeneralRepositoryCreateGinIndexCall) Return(arg0 error) *MockGeneralRepositoryCreateGinIndexCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockGeneralRepositoryCreateGinIndexCall) Do(f func(context.Context, string, string, any) error) *MockGeneralRepositoryCreateGinIndexCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockGeneralRepositoryCreateGinIndexCall) DoAndReturn(f func(context.Context, string, string, any) error) *MockGeneralRepositoryCreateGinIndexCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // CreateIndex mocks base method.
 func (m *MockGeneralRepository) CreateIndex(ctx context.Context, name, columns string, model any) error {
 	m.ctrl.T.Helper()
@@ -203,6 +243,44 @@ func (c *MockGeneralRepositoryIsRecordNotFoundCall) DoAndReturn(f func(error) bo
 	return c
 }
 
+// Notify mocks base method.
+func (m *MockGeneralRepository) Notify(ctx context.Context, channel, payload string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Notify", ctx, channel, payload)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Notify indicates an expected call of Notify.
+func (mr *MockGeneralRepositoryMockRecorder) Notify(ctx, channel, payload any) *MockGeneralRepositoryNotifyCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Notify", reflect.TypeOf((*MockGeneralRepository)(nil).Notify), ctx, channel, payload)
+	return &MockGeneralRepositoryNotifyCall{Call: call}
+}
+
+// MockGeneralRepositoryNotifyCall wrap *gomock.Call
+type MockGeneralRepositoryNotifyCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockGeneralRepositoryNotifyCall) Return(arg0 error) *MockGeneralRepositoryNotifyCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockGeneralRepositoryNotifyCall) Do(f func(context.Context, string, string) error) *MockGeneralRepositoryNotifyCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockGeneralRepositoryNotifyCall) DoAndReturn(f func(context.Context, string, string) error) *MockGeneralRepositoryNotifyCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // TablesExist mocks base method.
 func (m *MockGeneralRepository) TablesExist(ctx context.Context) bool {
 	m.ctrl.T.Helper()
@@ -265,6 +343,46 @@ func (m *MockTransaction) EXPECT() *MockTransactionMockRecorder {
 	return m.recorder
 }
 
+// AccountCounters mocks base method.
+func (m *MockTransaction) AccountCounters(ctx context.Context, accountID int64) (account.Account, operation.AccountCounters, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AccountCounters", ctx, accountID)
+	ret0, _ := ret[0].(account.Account)
+	ret1, _ := ret[1].(operation.AccountCounters)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// AccountCounters indicates an expected call of AccountCounters.
+func (mr *MockTransactionMockRecorder) AccountCounters(ctx, accountID any) *MockTransactionAccountCountersCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AccountCounters", reflect.TypeOf((*MockTransaction)(nil).AccountCounters), ctx, accountID)
+	return &MockTransactionAccountCountersCall{Call: call}
+}
+
+// MockTransactionAccountCountersCall wrap *gomock.Call
+type MockTransactionAccountCountersCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockTransactionAccountCountersCall) Return(arg0 account.Account, arg1 operation.AccountCounters, arg2 error) *MockTransactionAccountCountersCall {
+	c.Call = c.Call.Return(arg0, arg1, arg2)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockTransactionAccountCountersCall) Do(f func(context.Context, int64) (account.Account, operation.AccountCounters, error)) *MockTransactionAccountCountersCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockTransactionAccountCountersCall) DoAndReturn(f func(context.Context, int64) (account.Account, operation.AccountCounters, error)) *MockTransactionAccountCountersCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // Accounts mocks base method.
 func (m *MockTransaction) Accounts(ctx context.Context, accounts ...*account.Account) error {
 	m.ctrl.T.Helper()
@@ -590,6 +708,44 @@ func (c *MockTransactionCommitCall) DoAndReturn(f func() error) *MockTransaction
 	return c
 }
 
+// ContractMetadata mocks base method.
+func (m *MockTransaction) ContractMetadata(ctx context.Context, metadata *contractmetadata.ContractMetadata) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ContractMetadata", ctx, metadata)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ContractMetadata indicates an expected call of ContractMetadata.
+func (mr *MockTransactionMockRecorder) ContractMetadata(ctx, metadata any) *MockTransactionContractMetadataCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ContractMetadata", reflect.TypeOf((*MockTransaction)(nil).ContractMetadata), ctx, metadata)
+	return &MockTransactionContractMetadataCall{Call: call}
+}
+
+// MockTransactionContractMetadataCall wrap *gomock.Call
+type MockTransactionContractMetadataCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockTransactionContractMetadataCall) Return(arg0 error) *MockTransactionContractMetadataCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockTransactionContractMetadataCall) Do(f func(context.Context, *contractmetadata.ContractMetadata) error) *MockTransactionContractMetadataCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockTransactionContractMetadataCall) DoAndReturn(f func(context.Context, *contractmetadata.ContractMetadata) error) *MockTransactionContractMetadataCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // Contracts mocks base method.
 func (m *MockTransaction) Contracts(ctx context.Context, contracts ...*contract.Contract) error {
 	m.ctrl.T.Helper()
@@ -1325,6 +1481,92 @@ func (c *MockTransactionToJakartaCall) DoAndReturn(f func(context.Context) error
 	return c
 }
 
+// TokenBalances mocks base method.
+func (m *MockTransaction) TokenBalances(ctx context.Context, balances ...*token.Balance) error {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx}
+	for _, a := range balances {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "TokenBalances", varargs...)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// TokenBalances indicates an expected call of TokenBalances.
+func (mr *MockTransactionMockRecorder) TokenBalances(ctx any, balances ...any) *MockTransactionTokenBalancesCall {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx}, balances...)
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TokenBalances", reflect.TypeOf((*MockTransaction)(nil).TokenBalances), varargs...)
+	return &MockTransactionTokenBalancesCall{Call: call}
+}
+
+// MockTransactionTokenBalancesCall wrap *gomock.Call
+type MockTransactionTokenBalancesCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockTransactionTokenBalancesCall) Return(arg0 error) *MockTransactionTokenBalancesCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockTransactionTokenBalancesCall) Do(f func(context.Context, ...*token.Balance) error) *MockTransactionTokenBalancesCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockTransactionTokenBalancesCall) DoAndReturn(f func(context.Context, ...*token.Balance) error) *MockTransactionTokenBalancesCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// TokenTransfers mocks base method.
+func (m *MockTransaction) TokenTransfers(ctx context.Context, transfers ...*token.Transfer) error {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx}
+	for _, a := range transfers {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "TokenTransfers", varargs...)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// TokenTransfers indicates an expected call of TokenTransfers.
+func (mr *MockTransactionMockRecorder) TokenTransfers(ctx any, transfers ...any) *MockTransactionTokenTransfersCall {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx}, transfers...)
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TokenTransfers", reflect.TypeOf((*MockTransaction)(nil).TokenTransfers), varargs...)
+	return &MockTransactionTokenTransfersCall{Call: call}
+}
+
+// MockTransactionTokenTransfersCall wrap *gomock.Call
+type MockTransactionTokenTransfersCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockTransactionTokenTransfersCall) Return(arg0 error) *MockTransactionTokenTransfersCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockTransactionTokenTransfersCall) Do(f func(context.Context, ...*token.Transfer) error) *MockTransactionTokenTransfersCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockTransactionTokenTransfersCall) DoAndReturn(f func(context.Context, ...*token.Transfer) error) *MockTransactionTokenTransfersCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // UpdateStats mocks base method.
 func (m *MockTransaction) UpdateStats(ctx context.Context, arg1 stats.Stats) error {
 	m.ctrl.T.Helper()
@@ -1362,3 +1604,89 @@ func (c *MockTransactionUpdateStatsCall) DoAndReturn(f func(context.Context, sta
 	c.Call = c.Call.DoAndReturn(f)
 	return c
 }
+
+// StatsSeries mocks base method.
+func (m *MockTransaction) StatsSeries(ctx context.Context, series ...*stats.Series) error {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx}
+	for _, a := range series {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "StatsSeries", varargs...)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// StatsSeries indicates an expected call of StatsSeries.
+func (mr *MockTransactionMockRecorder) StatsSeries(ctx any, series ...any) *MockTransactionStatsSeriesCall {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx}, series...)
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StatsSeries", reflect.TypeOf((*MockTransaction)(nil).StatsSeries), varargs...)
+	return &MockTransactionStatsSeriesCall{Call: call}
+}
+
+// MockTransactionStatsSeriesCall wrap *gomock.Call
+type MockTransactionStatsSeriesCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockTransactionStatsSeriesCall) Return(arg0 error) *MockTransactionStatsSeriesCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockTransactionStatsSeriesCall) Do(f func(context.Context, ...*stats.Series) error) *MockTransactionStatsSeriesCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockTransactionStatsSeriesCall) DoAndReturn(f func(context.Context, ...*stats.Series) error) *MockTransactionStatsSeriesCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// StatsCallers mocks base method.
+func (m *MockTransaction) StatsCallers(ctx context.Context, callers ...*stats.Caller) error {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx}
+	for _, a := range callers {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "StatsCallers", varargs...)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// StatsCallers indicates an expected call of StatsCallers.
+func (mr *MockTransactionMockRecorder) StatsCallers(ctx any, callers ...any) *MockTransactionStatsCallersCall {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx}, callers...)
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StatsCallers", reflect.TypeOf((*MockTransaction)(nil).StatsCallers), varargs...)
+	return &MockTransactionStatsCallersCall{Call: call}
+}
+
+// MockTransactionStatsCallersCall wrap *gomock.Call
+type MockTransactionStatsCallersCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockTransactionStatsCallersCall) Return(arg0 error) *MockTransactionStatsCallersCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockTransactionStatsCallersCall) Do(f func(context.Context, ...*stats.Caller) error) *MockTransactionStatsCallersCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockTransactionStatsCallersCall) DoAndReturn(f func(context.Context, ...*stats.Caller) error) *MockTransactionStatsCallersCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
@@ -41,6 +41,83 @@ func (m *MockRepository) EXPECT() *MockRepositoryMockRecorder {
 	return m.recorder
 }
 
+// CountersForAccount mocks base method.
+func (m *MockRepository) CountersForAccount(ctx context.Context, accountID int64) (operation.AccountCounters, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountersForAccount", ctx, accountID)
+	ret0, _ := ret[0].(operation.AccountCounters)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountersForAccount indicates an expected call of CountersForAccount.
+func (mr *MockRepositoryMockRecorder) CountersForAccount(ctx, accountID any) *MockRepositoryCountersForAccountCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountersForAccount", reflect.TypeOf((*MockRepository)(nil).CountersForAccount), ctx, accountID)
+	return &MockRepositoryCountersForAccountCall{Call: call}
+}
+
+// MockRepositoryCountersForAccountCall wrap *gomock.Call
+type MockRepositoryCountersForAccountCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockRepositoryCountersForAccountCall) Return(arg0 operation.AccountCounters, arg1 error) *MockRepositoryCountersForAccountCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockRepositoryCountersForAccountCall) Do(f func(context.Context, int64) (operation.AccountCounters, error)) *MockRepositoryCountersForAccountCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockRepositoryCountersForAccountCall) DoAndReturn(f func(context.Context, int64) (operation.AccountCounters, error)) *MockRepositoryCountersForAccountCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// Export mocks base method.
+func (m *MockRepository) Export(ctx context.Context, accountID int64, req operation.HistoryRequest, handler func([]operation.Operation) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Export", ctx, accountID, req, handler)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Export indicates an expected call of Export.
+func (mr *MockRepositoryMockRecorder) Export(ctx, accountID, req, handler any) *MockRepositoryExportCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Export", reflect.TypeOf((*MockRepository)(nil).Export), ctx, accountID, req, handler)
+	return &MockRepositoryExportCall{Call: call}
+}
+
+// MockRepositoryExportCall wrap *gomock.Call
+type MockRepositoryExportCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockRepositoryExportCall) Return(arg0 error) *MockRepositoryExportCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockRepositoryExportCall) Do(f func(context.Context, int64, operation.HistoryRequest, func([]operation.Operation) error) error) *MockRepositoryExportCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockRepositoryExportCall) DoAndReturn(f func(context.Context, int64, operation.HistoryRequest, func([]operation.Operation) error) error) *MockRepositoryExportCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // GetByHash mocks base method.
 func (m *MockRepository) GetByHash(ctx context.Context, hash []byte) ([]operation.Operation, error) {
 	m.ctrl.T.Helper()
@@ -158,6 +235,84 @@ func (c *MockRepositoryGetByIDCall) DoAndReturn(f func(context.Context, int64) (
 	return c
 }
 
+// GetByLevel mocks base method.
+func (m *MockRepository) GetByLevel(ctx context.Context, level int64) ([]operation.Operation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByLevel", ctx, level)
+	ret0, _ := ret[0].([]operation.Operation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByLevel indicates an expected call of GetByLevel.
+func (mr *MockRepositoryMockRecorder) GetByLevel(ctx, level any) *MockRepositoryGetByLevelCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByLevel", reflect.TypeOf((*MockRepository)(nil).GetByLevel), ctx, level)
+	return &MockRepositoryGetByLevelCall{Call: call}
+}
+
+// MockRepositoryGetByLevelCall wrap *gomock.Call
+type MockRepositoryGetByLevelCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockRepositoryGetByLevelCall) Return(arg0 []operation.Operation, arg1 error) *MockRepositoryGetByLevelCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockRepositoryGetByLevelCall) Do(f func(context.Context, int64) ([]operation.Operation, error)) *MockRepositoryGetByLevelCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockRepositoryGetByLevelCall) DoAndReturn(f func(context.Context, int64) ([]operation.Operation, error)) *MockRepositoryGetByLevelCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// History mocks base method.
+func (m *MockRepository) History(ctx context.Context, accountID int64, req operation.HistoryRequest) ([]operation.Operation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "History", ctx, accountID, req)
+	ret0, _ := ret[0].([]operation.Operation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// History indicates an expected call of History.
+func (mr *MockRepositoryMockRecorder) History(ctx, accountID, req any) *MockRepositoryHistoryCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "History", reflect.TypeOf((*MockRepository)(nil).History), ctx, accountID, req)
+	return &MockRepositoryHistoryCall{Call: call}
+}
+
+// MockRepositoryHistoryCall wrap *gomock.Call
+type MockRepositoryHistoryCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockRepositoryHistoryCall) Return(arg0 []operation.Operation, arg1 error) *MockRepositoryHistoryCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockRepositoryHistoryCall) Do(f func(context.Context, int64, operation.HistoryRequest) ([]operation.Operation, error)) *MockRepositoryHistoryCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockRepositoryHistoryCall) DoAndReturn(f func(context.Context, int64, operation.HistoryRequest) ([]operation.Operation, error)) *MockRepositoryHistoryCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // Last mocks base method.
 func (m *MockRepository) Last(ctx context.Context, filter map[string]any, lastID int64) (operation.Operation, error) {
 	m.ctrl.T.Helper()
@@ -313,3 +468,81 @@ func (c *MockRepositoryOriginationCall) DoAndReturn(f func(context.Context, int6
 	c.Call = c.Call.DoAndReturn(f)
 	return c
 }
+
+// Events mocks base method.
+func (m *MockRepository) Events(ctx context.Context, req operation.EventsRequest) ([]operation.Operation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Events", ctx, req)
+	ret0, _ := ret[0].([]operation.Operation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Events indicates an expected call of Events.
+func (mr *MockRepositoryMockRecorder) Events(ctx, req any) *MockRepositoryEventsCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Events", reflect.TypeOf((*MockRepository)(nil).Events), ctx, req)
+	return &MockRepositoryEventsCall{Call: call}
+}
+
+// MockRepositoryEventsCall wrap *gomock.Call
+type MockRepositoryEventsCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockRepositoryEventsCall) Return(arg0 []operation.Operation, arg1 error) *MockRepositoryEventsCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockRepositoryEventsCall) Do(f func(context.Context, operation.EventsRequest) ([]operation.Operation, error)) *MockRepositoryEventsCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockRepositoryEventsCall) DoAndReturn(f func(context.Context, operation.EventsRequest) ([]operation.Operation, error)) *MockRepositoryEventsCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// EventTypes mocks base method.
+func (m *MockRepository) EventTypes(ctx context.Context, req operation.EventTypesRequest) ([]operation.EventType, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EventTypes", ctx, req)
+	ret0, _ := ret[0].([]operation.EventType)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// EventTypes indicates an expected call of EventTypes.
+func (mr *MockRepositoryMockRecorder) EventTypes(ctx, req any) *MockRepositoryEventTypesCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EventTypes", reflect.TypeOf((*MockRepository)(nil).EventTypes), ctx, req)
+	return &MockRepositoryEventTypesCall{Call: call}
+}
+
+// MockRepositoryEventTypesCall wrap *gomock.Call
+type MockRepositoryEventTypesCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockRepositoryEventTypesCall) Return(arg0 []operation.EventType, arg1 error) *MockRepositoryEventTypesCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockRepositoryEventTypesCall) Do(f func(context.Context, operation.EventTypesRequest) ([]operation.EventType, error)) *MockRepositoryEventTypesCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockRepositoryEventTypesCall) DoAndReturn(f func(context.Context, operation.EventTypesRequest) ([]operation.EventType, error)) *MockRepositoryEventTypesCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
@@ -21,6 +21,7 @@ import (
 	operation "github.com/baking-bad/bcdhub/internal/models/operation"
 	stats "github.com/baking-bad/bcdhub/internal/models/stats"
 	ticket "github.com/baking-bad/bcdhub/internal/models/ticket"
+	token "github.com/baking-bad/bcdhub/internal/models/token"
 	gomock "go.uber.org/mock/gomock"
 )
 
@@ -439,6 +440,45 @@ func (c *MockRollbackGetTicketUpdatesCall) DoAndReturn(f func(context.Context, i
 	return c
 }
 
+// GetTokenTransfers mocks base method.
+func (m *MockRollback) GetTokenTransfers(ctx context.Context, level int64) ([]token.Transfer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTokenTransfers", ctx, level)
+	ret0, _ := ret[0].([]token.Transfer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTokenTransfers indicates an expected call of GetTokenTransfers.
+func (mr *MockRollbackMockRecorder) GetTokenTransfers(ctx, level any) *MockRollbackGetTokenTransfersCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTokenTransfers", reflect.TypeOf((*MockRollback)(nil).GetTokenTransfers), ctx, level)
+	return &MockRollbackGetTokenTransfersCall{Call: call}
+}
+
+// MockRollbackGetTokenTransfersCall wrap *gomock.Call
+type MockRollbackGetTokenTransfersCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockRollbackGetTokenTransfersCall) Return(arg0 []token.Transfer, arg1 error) *MockRollbackGetTokenTransfersCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockRollbackGetTokenTransfersCall) Do(f func(context.Context, int64) ([]token.Transfer, error)) *MockRollbackGetTokenTransfersCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockRollbackGetTokenTransfersCall) DoAndReturn(f func(context.Context, int64) ([]token.Transfer, error)) *MockRollbackGetTokenTransfersCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // GlobalConstants mocks base method.
 func (m *MockRollback) GlobalConstants(ctx context.Context, level int64) ([]contract.GlobalConstant, error) {
 	m.ctrl.T.Helper()
@@ -752,6 +792,49 @@ func (c *MockRollbackTicketBalancesCall) DoAndReturn(f func(context.Context, ...
 	return c
 }
 
+// TokenBalances mocks base method.
+func (m *MockRollback) TokenBalances(ctx context.Context, balances ...*token.Balance) error {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx}
+	for _, a := range balances {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "TokenBalances", varargs...)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// TokenBalances indicates an expected call of TokenBalances.
+func (mr *MockRollbackMockRecorder) TokenBalances(ctx any, balances ...any) *MockRollbackTokenBalancesCall {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx}, balances...)
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TokenBalances", reflect.TypeOf((*MockRollback)(nil).TokenBalances), varargs...)
+	return &MockRollbackTokenBalancesCall{Call: call}
+}
+
+// MockRollbackTokenBalancesCall wrap *gomock.Call
+type MockRollbackTokenBalancesCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockRollbackTokenBalancesCall) Return(arg0 error) *MockRollbackTokenBalancesCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockRollbackTokenBalancesCall) Do(f func(context.Context, ...*token.Balance) error) *MockRollbackTokenBalancesCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockRollbackTokenBalancesCall) DoAndReturn(f func(context.Context, ...*token.Balance) error) *MockRollbackTokenBalancesCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // UpdateAccountStats mocks base method.
 func (m *MockRollback) UpdateAccountStats(ctx context.Context, arg1 account.Account) error {
 	m.ctrl.T.Helper()
@@ -865,3 +948,46 @@ func (c *MockRollbackUpdateTicketCall) DoAndReturn(f func(context.Context, ticke
 	c.Call = c.Call.DoAndReturn(f)
 	return c
 }
+
+// UpdateSeries mocks base method.
+func (m *MockRollback) UpdateSeries(ctx context.Context, series ...*stats.Series) error {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx}
+	for _, a := range series {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "UpdateSeries", varargs...)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateSeries indicates an expected call of UpdateSeries.
+func (mr *MockRollbackMockRecorder) UpdateSeries(ctx any, series ...any) *MockRollbackUpdateSeriesCall {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx}, series...)
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateSeries", reflect.TypeOf((*MockRollback)(nil).UpdateSeries), varargs...)
+	return &MockRollbackUpdateSeriesCall{Call: call}
+}
+
+// MockRollbackUpdateSeriesCall wrap *gomock.Call
+type MockRollbackUpdateSeriesCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockRollbackUpdateSeriesCall) Return(arg0 error) *MockRollbackUpdateSeriesCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockRollbackUpdateSeriesCall) Do(f func(context.Context, ...*stats.Series) error) *MockRollbackUpdateSeriesCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockRollbackUpdateSeriesCall) DoAndReturn(f func(context.Context, ...*stats.Series) error) *MockRollbackUpdateSeriesCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
@@ -118,3 +118,242 @@ func (c *MockRepositoryListCall) DoAndReturn(f func(context.Context, int64, int6
 	c.Call = c.Call.DoAndReturn(f)
 	return c
 }
+
+// Commitments mocks base method.
+func (m *MockRepository) Commitments(ctx context.Context, rollupId int64, req smartrollup.ListRequest) ([]smartrollup.Commitment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Commitments", ctx, rollupId, req)
+	ret0, _ := ret[0].([]smartrollup.Commitment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Commitments indicates an expected call of Commitments.
+func (mr *MockRepositoryMockRecorder) Commitments(ctx, rollupId, req any) *MockRepositoryCommitmentsCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Commitments", reflect.TypeOf((*MockRepository)(nil).Commitments), ctx, rollupId, req)
+	return &MockRepositoryCommitmentsCall{Call: call}
+}
+
+// MockRepositoryCommitmentsCall wrap *gomock.Call
+type MockRepositoryCommitmentsCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockRepositoryCommitmentsCall) Return(arg0 []smartrollup.Commitment, arg1 error) *MockRepositoryCommitmentsCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockRepositoryCommitmentsCall) Do(f func(context.Context, int64, smartrollup.ListRequest) ([]smartrollup.Commitment, error)) *MockRepositoryCommitmentsCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockRepositoryCommitmentsCall) DoAndReturn(f func(context.Context, int64, smartrollup.ListRequest) ([]smartrollup.Commitment, error)) *MockRepositoryCommitmentsCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// Cementations mocks base method.
+func (m *MockRepository) Cementations(ctx context.Context, rollupId int64, hashes ...string) ([]smartrollup.Cementation, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, rollupId}
+	for _, a := range hashes {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Cementations", varargs...)
+	ret0, _ := ret[0].([]smartrollup.Cementation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Cementations indicates an expected call of Cementations.
+func (mr *MockRepositoryMockRecorder) Cementations(ctx, rollupId any, hashes ...any) *MockRepositoryCementationsCall {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, rollupId}, hashes...)
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Cementations", reflect.TypeOf((*MockRepository)(nil).Cementations), varargs...)
+	return &MockRepositoryCementationsCall{Call: call}
+}
+
+// MockRepositoryCementationsCall wrap *gomock.Call
+type MockRepositoryCementationsCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockRepositoryCementationsCall) Return(arg0 []smartrollup.Cementation, arg1 error) *MockRepositoryCementationsCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockRepositoryCementationsCall) Do(f func(context.Context, int64, ...string) ([]smartrollup.Cementation, error)) *MockRepositoryCementationsCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockRepositoryCementationsCall) DoAndReturn(f func(context.Context, int64, ...string) ([]smartrollup.Cementation, error)) *MockRepositoryCementationsCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// Stakers mocks base method.
+func (m *MockRepository) Stakers(ctx context.Context, rollupId int64) ([]smartrollup.Staker, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Stakers", ctx, rollupId)
+	ret0, _ := ret[0].([]smartrollup.Staker)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Stakers indicates an expected call of Stakers.
+func (mr *MockRepositoryMockRecorder) Stakers(ctx, rollupId any) *MockRepositoryStakersCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Stakers", reflect.TypeOf((*MockRepository)(nil).Stakers), ctx, rollupId)
+	return &MockRepositoryStakersCall{Call: call}
+}
+
+// MockRepositoryStakersCall wrap *gomock.Call
+type MockRepositoryStakersCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockRepositoryStakersCall) Return(arg0 []smartrollup.Staker, arg1 error) *MockRepositoryStakersCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockRepositoryStakersCall) Do(f func(context.Context, int64) ([]smartrollup.Staker, error)) *MockRepositoryStakersCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockRepositoryStakersCall) DoAndReturn(f func(context.Context, int64) ([]smartrollup.Staker, error)) *MockRepositoryStakersCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// Games mocks base method.
+func (m *MockRepository) Games(ctx context.Context, rollupId int64, req smartrollup.ListRequest) ([]smartrollup.Refutation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Games", ctx, rollupId, req)
+	ret0, _ := ret[0].([]smartrollup.Refutation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Games indicates an expected call of Games.
+func (mr *MockRepositoryMockRecorder) Games(ctx, rollupId, req any) *MockRepositoryGamesCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Games", reflect.TypeOf((*MockRepository)(nil).Games), ctx, rollupId, req)
+	return &MockRepositoryGamesCall{Call: call}
+}
+
+// MockRepositoryGamesCall wrap *gomock.Call
+type MockRepositoryGamesCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockRepositoryGamesCall) Return(arg0 []smartrollup.Refutation, arg1 error) *MockRepositoryGamesCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockRepositoryGamesCall) Do(f func(context.Context, int64, smartrollup.ListRequest) ([]smartrollup.Refutation, error)) *MockRepositoryGamesCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockRepositoryGamesCall) DoAndReturn(f func(context.Context, int64, smartrollup.ListRequest) ([]smartrollup.Refutation, error)) *MockRepositoryGamesCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// GameMoves mocks base method.
+func (m *MockRepository) GameMoves(ctx context.Context, rollupId int64, start smartrollup.Refutation) ([]smartrollup.Refutation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GameMoves", ctx, rollupId, start)
+	ret0, _ := ret[0].([]smartrollup.Refutation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GameMoves indicates an expected call of GameMoves.
+func (mr *MockRepositoryMockRecorder) GameMoves(ctx, rollupId, start any) *MockRepositoryGameMovesCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GameMoves", reflect.TypeOf((*MockRepository)(nil).GameMoves), ctx, rollupId, start)
+	return &MockRepositoryGameMovesCall{Call: call}
+}
+
+// MockRepositoryGameMovesCall wrap *gomock.Call
+type MockRepositoryGameMovesCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockRepositoryGameMovesCall) Return(arg0 []smartrollup.Refutation, arg1 error) *MockRepositoryGameMovesCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockRepositoryGameMovesCall) Do(f func(context.Context, int64, smartrollup.Refutation) ([]smartrollup.Refutation, error)) *MockRepositoryGameMovesCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockRepositoryGameMovesCall) DoAndReturn(f func(context.Context, int64, smartrollup.Refutation) ([]smartrollup.Refutation, error)) *MockRepositoryGameMovesCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// OutboxMessages mocks base method.
+func (m *MockRepository) OutboxMessages(ctx context.Context, rollupId int64, req smartrollup.ListRequest) ([]smartrollup.OutboxMessage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "OutboxMessages", ctx, rollupId, req)
+	ret0, _ := ret[0].([]smartrollup.OutboxMessage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// OutboxMessages indicates an expected call of OutboxMessages.
+func (mr *MockRepositoryMockRecorder) OutboxMessages(ctx, rollupId, req any) *MockRepositoryOutboxMessagesCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OutboxMessages", reflect.TypeOf((*MockRepository)(nil).OutboxMessages), ctx, rollupId, req)
+	return &MockRepositoryOutboxMessagesCall{Call: call}
+}
+
+// MockRepositoryOutboxMessagesCall wrap *gomock.Call
+type MockRepositoryOutboxMessagesCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockRepositoryOutboxMessagesCall) Return(arg0 []smartrollup.OutboxMessage, arg1 error) *MockRepositoryOutboxMessagesCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockRepositoryOutboxMessagesCall) Do(f func(context.Context, int64, smartrollup.ListRequest) ([]smartrollup.OutboxMessage, error)) *MockRepositoryOutboxMessagesCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockRepositoryOutboxMessagesCall) DoAndReturn(f func(context.Context, int64, smartrollup.ListRequest) ([]smartrollup.OutboxMessage, error)) *MockRepositoryOutboxMessagesCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
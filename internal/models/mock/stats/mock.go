@@ -79,3 +79,42 @@ func (c *MockRepositoryGetCall) DoAndReturn(f func(context.Context) (stats.Stats
 	c.Call = c.Call.DoAndReturn(f)
 	return c
 }
+
+// Series mocks base method.
+func (m *MockRepository) Series(ctx context.Context, req stats.SeriesRequest) ([]stats.SeriesPoint, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Series", ctx, req)
+	ret0, _ := ret[0].([]stats.SeriesPoint)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Series indicates an expected call of Series.
+func (mr *MockRepositoryMockRecorder) Series(ctx, req any) *MockRepositorySeriesCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Series", reflect.TypeOf((*MockRepository)(nil).Series), ctx, req)
+	return &MockRepositorySeriesCall{Call: call}
+}
+
+// MockRepositorySeriesCall wrap *gomock.Call
+type MockRepositorySeriesCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockRepositorySeriesCall) Return(arg0 []stats.SeriesPoint, arg1 error) *MockRepositorySeriesCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockRepositorySeriesCall) Do(f func(context.Context, stats.SeriesRequest) ([]stats.SeriesPoint, error)) *MockRepositorySeriesCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockRepositorySeriesCall) DoAndReturn(f func(context.Context, stats.SeriesRequest) ([]stats.SeriesPoint, error)) *MockRepositorySeriesCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
@@ -80,6 +80,44 @@ func (c *MockRepositoryBalancesForAccountCall) DoAndReturn(f func(context.Contex
 	return c
 }
 
+// ExportUpdates mocks base method.
+func (m *MockRepository) ExportUpdates(ctx context.Context, req ticket.UpdatesRequest, handler func([]ticket.TicketUpdate) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExportUpdates", ctx, req, handler)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ExportUpdates indicates an expected call of ExportUpdates.
+func (mr *MockRepositoryMockRecorder) ExportUpdates(ctx, req, handler any) *MockRepositoryExportUpdatesCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExportUpdates", reflect.TypeOf((*MockRepository)(nil).ExportUpdates), ctx, req, handler)
+	return &MockRepositoryExportUpdatesCall{Call: call}
+}
+
+// MockRepositoryExportUpdatesCall wrap *gomock.Call
+type MockRepositoryExportUpdatesCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockRepositoryExportUpdatesCall) Return(arg0 error) *MockRepositoryExportUpdatesCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockRepositoryExportUpdatesCall) Do(f func(context.Context, ticket.UpdatesRequest, func([]ticket.TicketUpdate) error) error) *MockRepositoryExportUpdatesCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockRepositoryExportUpdatesCall) DoAndReturn(f func(context.Context, ticket.UpdatesRequest, func([]ticket.TicketUpdate) error) error) *MockRepositoryExportUpdatesCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // List mocks base method.
 func (m *MockRepository) List(ctx context.Context, ticketer string, limit, offset int64) ([]ticket.Ticket, error) {
 	m.ctrl.T.Helper()
@@ -196,3 +234,159 @@ func (c *MockRepositoryUpdatesForOperationCall) DoAndReturn(f func(context.Conte
 	c.Call = c.Call.DoAndReturn(f)
 	return c
 }
+
+// Get mocks base method.
+func (m *MockRepository) Get(ctx context.Context, hash string) (ticket.Ticket, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", ctx, hash)
+	ret0, _ := ret[0].(ticket.Ticket)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockRepositoryMockRecorder) Get(ctx, hash any) *MockRepositoryGetCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockRepository)(nil).Get), ctx, hash)
+	return &MockRepositoryGetCall{Call: call}
+}
+
+// MockRepositoryGetCall wrap *gomock.Call
+type MockRepositoryGetCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockRepositoryGetCall) Return(arg0 ticket.Ticket, arg1 error) *MockRepositoryGetCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockRepositoryGetCall) Do(f func(context.Context, string) (ticket.Ticket, error)) *MockRepositoryGetCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockRepositoryGetCall) DoAndReturn(f func(context.Context, string) (ticket.Ticket, error)) *MockRepositoryGetCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// Holders mocks base method.
+func (m *MockRepository) Holders(ctx context.Context, ticketId, limit, offset int64) ([]ticket.Balance, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Holders", ctx, ticketId, limit, offset)
+	ret0, _ := ret[0].([]ticket.Balance)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Holders indicates an expected call of Holders.
+func (mr *MockRepositoryMockRecorder) Holders(ctx, ticketId, limit, offset any) *MockRepositoryHoldersCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Holders", reflect.TypeOf((*MockRepository)(nil).Holders), ctx, ticketId, limit, offset)
+	return &MockRepositoryHoldersCall{Call: call}
+}
+
+// MockRepositoryHoldersCall wrap *gomock.Call
+type MockRepositoryHoldersCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockRepositoryHoldersCall) Return(arg0 []ticket.Balance, arg1 error) *MockRepositoryHoldersCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockRepositoryHoldersCall) Do(f func(context.Context, int64, int64, int64) ([]ticket.Balance, error)) *MockRepositoryHoldersCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockRepositoryHoldersCall) DoAndReturn(f func(context.Context, int64, int64, int64) ([]ticket.Balance, error)) *MockRepositoryHoldersCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// Summary mocks base method.
+func (m *MockRepository) Summary(ctx context.Context, ticketId int64) (ticket.Summary, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Summary", ctx, ticketId)
+	ret0, _ := ret[0].(ticket.Summary)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Summary indicates an expected call of Summary.
+func (mr *MockRepositoryMockRecorder) Summary(ctx, ticketId any) *MockRepositorySummaryCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Summary", reflect.TypeOf((*MockRepository)(nil).Summary), ctx, ticketId)
+	return &MockRepositorySummaryCall{Call: call}
+}
+
+// MockRepositorySummaryCall wrap *gomock.Call
+type MockRepositorySummaryCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockRepositorySummaryCall) Return(arg0 ticket.Summary, arg1 error) *MockRepositorySummaryCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockRepositorySummaryCall) Do(f func(context.Context, int64) (ticket.Summary, error)) *MockRepositorySummaryCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockRepositorySummaryCall) DoAndReturn(f func(context.Context, int64) (ticket.Summary, error)) *MockRepositorySummaryCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// SupplyHistory mocks base method.
+func (m *MockRepository) SupplyHistory(ctx context.Context, ticketId int64, req ticket.SupplyRequest) ([]ticket.SupplyPoint, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SupplyHistory", ctx, ticketId, req)
+	ret0, _ := ret[0].([]ticket.SupplyPoint)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SupplyHistory indicates an expected call of SupplyHistory.
+func (mr *MockRepositoryMockRecorder) SupplyHistory(ctx, ticketId, req any) *MockRepositorySupplyHistoryCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SupplyHistory", reflect.TypeOf((*MockRepository)(nil).SupplyHistory), ctx, ticketId, req)
+	return &MockRepositorySupplyHistoryCall{Call: call}
+}
+
+// MockRepositorySupplyHistoryCall wrap *gomock.Call
+type MockRepositorySupplyHistoryCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockRepositorySupplyHistoryCall) Return(arg0 []ticket.SupplyPoint, arg1 error) *MockRepositorySupplyHistoryCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockRepositorySupplyHistoryCall) Do(f func(context.Context, int64, ticket.SupplyRequest) ([]ticket.SupplyPoint, error)) *MockRepositorySupplyHistoryCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockRepositorySupplyHistoryCall) DoAndReturn(f func(context.Context, int64, ticket.SupplyRequest) ([]ticket.SupplyPoint, error)) *MockRepositorySupplyHistoryCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
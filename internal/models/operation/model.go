@@ -12,6 +12,7 @@ import (
 	"github.com/baking-bad/bcdhub/internal/models/bigmapdiff"
 	"github.com/baking-bad/bcdhub/internal/models/protocol"
 	"github.com/baking-bad/bcdhub/internal/models/ticket"
+	"github.com/baking-bad/bcdhub/internal/models/token"
 	"github.com/baking-bad/bcdhub/internal/models/types"
 	"github.com/uptrace/bun"
 )
@@ -65,9 +66,10 @@ type Operation struct {
 
 	AST *ast.Script `bun:"-"`
 
-	BigMapDiffs   []*bigmapdiff.BigMapDiff     `bun:"rel:has-many"`
-	BigMapActions []*bigmapaction.BigMapAction `bun:"rel:has-many"`
-	TicketUpdates []*ticket.TicketUpdate       `bun:"rel:has-many"`
+	BigMapDiffs    []*bigmapdiff.BigMapDiff     `bun:"rel:has-many"`
+	BigMapActions  []*bigmapaction.BigMapAction `bun:"rel:has-many"`
+	TicketUpdates  []*ticket.TicketUpdate       `bun:"rel:has-many"`
+	TokenTransfers []*token.Transfer            `bun:"rel:has-many"`
 
 	AllocatedDestinationContract bool
 	Internal                     bool
@@ -2,8 +2,70 @@ package operation
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+
+	"github.com/baking-bad/bcdhub/internal/models/types"
 )
 
+// HistoryRequest - filters of account operation history. Zero values mean that filter is not applied.
+type HistoryRequest struct {
+	Kinds          []types.OperationKind
+	Statuses       []types.OperationStatus
+	Entrypoints    []string
+	From           time.Time
+	To             time.Time
+	CounterpartyID int64
+	LastID         int64
+	Limit          int64
+}
+
+// EventsRequest - filters of network-wide event search. Zero values mean that filter is not applied.
+type EventsRequest struct {
+	Tag       string
+	EmitterID int64
+	TypeHash  string
+	FromLevel int64
+	ToLevel   int64
+	LastID    int64
+	Limit     int64
+}
+
+// EventTypesRequest - filters of event catalogue. Zero values mean that filter is not applied.
+type EventTypesRequest struct {
+	Tag       string
+	EmitterID int64
+	Limit     int64
+	Offset    int64
+}
+
+// EventType - distinct signature of events emitted by contract
+type EventType struct {
+	EmitterID   int64            `bun:"source_id"`
+	Emitter     string           `bun:"emitter"`
+	Tag         types.NullString `bun:"tag"`
+	PayloadType []byte           `bun:"payload_type"`
+	Count       int64            `bun:"count"`
+	FirstLevel  int64            `bun:"first_level"`
+	LastLevel   int64            `bun:"last_level"`
+}
+
+// AccountCounters - counters of account calculated by its operations
+type AccountCounters struct {
+	OperationsCount int64 `bun:"operations_count"`
+	EventsCount     int64 `bun:"events_count"`
+}
+
+// PayloadTypeHash - hex encoded sha256 hash of event payload type
+func PayloadTypeHash(payloadType []byte) string {
+	if len(payloadType) == 0 {
+		return ""
+	}
+	h := sha256.Sum256(payloadType)
+	return hex.EncodeToString(h[:])
+}
+
 //go:generate mockgen -source=$GOFILE -destination=../mock/operation/mock.go -package=operation -typed
 type Repository interface {
 	Last(ctx context.Context, filter map[string]interface{}, lastID int64) (Operation, error)
@@ -12,5 +74,11 @@ type Repository interface {
 	OPG(ctx context.Context, address string, size, lastID int64) ([]OPG, error)
 	Origination(ctx context.Context, accountID int64) (Operation, error)
 	GetByID(ctx context.Context, id int64) (Operation, error)
+	GetByLevel(ctx context.Context, level int64) ([]Operation, error)
 	ListEvents(ctx context.Context, accountID int64, size, offset int64) ([]Operation, error)
+	Events(ctx context.Context, req EventsRequest) ([]Operation, error)
+	EventTypes(ctx context.Context, req EventTypesRequest) ([]EventType, error)
+	History(ctx context.Context, accountID int64, req HistoryRequest) ([]Operation, error)
+	Export(ctx context.Context, accountID int64, req HistoryRequest, handler func(operations []Operation) error) error
+	CountersForAccount(ctx context.Context, accountID int64) (AccountCounters, error)
 }
@@ -11,6 +11,7 @@ import (
 	"github.com/baking-bad/bcdhub/internal/models/operation"
 	"github.com/baking-bad/bcdhub/internal/models/stats"
 	"github.com/baking-bad/bcdhub/internal/models/ticket"
+	"github.com/baking-bad/bcdhub/internal/models/token"
 )
 
 type LastAction struct {
@@ -36,9 +37,12 @@ type Rollback interface {
 	DeleteScriptsConstants(ctx context.Context, scriptIds []int64, constantsIds []int64) error
 	Protocols(ctx context.Context, level int64) error
 	UpdateStats(ctx context.Context, stats stats.Stats) error
+	UpdateSeries(ctx context.Context, series ...*stats.Series) error
 	TicketBalances(ctx context.Context, balances ...*ticket.Balance) error
 	DeleteTickets(ctx context.Context, level int64) (ids []int64, err error)
 	DeleteTicketBalances(ctx context.Context, ticketIds []int64) (err error)
+	GetTokenTransfers(ctx context.Context, level int64) ([]token.Transfer, error)
+	TokenBalances(ctx context.Context, balances ...*token.Balance) error
 
 	Commit() error
 	Rollback() error
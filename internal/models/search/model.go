@@ -0,0 +1,73 @@
+package search
+
+import "github.com/baking-bad/bcdhub/internal/models/types"
+
+// Hit types
+const (
+	HitTypeEntrypoint     = "entrypoint"
+	HitTypeAnnotation     = "annotation"
+	HitTypeTag            = "tag"
+	HitTypeGlobalConstant = "global_constant"
+	HitTypeBigMapKey      = "big_map_key"
+)
+
+// MaxDepth - the deepest position of hit which can be requested, i.e. the biggest sum of offset and size
+const MaxDepth = 1000
+
+// accountHitTypes - account types which can be found by address
+var accountHitTypes = []types.AccountType{
+	types.AccountTypeContract,
+	types.AccountTypeTz,
+	types.AccountTypeRollup,
+	types.AccountTypeSmartRollup,
+}
+
+// AccountTypes - returns account types of address hits among `hitTypes`. All searchable account types are returned if `hitTypes` is empty.
+func AccountTypes(hitTypes ...string) []types.AccountType {
+	if len(hitTypes) == 0 {
+		return accountHitTypes
+	}
+	result := make([]types.AccountType, 0)
+	for _, typ := range accountHitTypes {
+		for i := range hitTypes {
+			if hitTypes[i] == typ.String() {
+				result = append(result, typ)
+				break
+			}
+		}
+	}
+	return result
+}
+
+// IsHitType - returns true if `typ` is one of account types which can be found by address or one of `HitType*` constants
+func IsHitType(typ string) bool {
+	switch typ {
+	case HitTypeEntrypoint, HitTypeAnnotation, HitTypeTag, HitTypeGlobalConstant, HitTypeBigMapKey:
+		return true
+	}
+	for i := range accountHitTypes {
+		if accountHitTypes[i].String() == typ {
+			return true
+		}
+	}
+	return false
+}
+
+// Match ranks: lower is better
+const (
+	RankExact = iota
+	RankPrefix
+	RankContains
+)
+
+// Hit - single search result. Type is one of account types (`contract`, `account`, `smart_rollup` and etc.) for address hits or one of `HitType*` constants.
+type Hit struct {
+	Type    string
+	Address string
+	Value   string
+	Level   int64
+	Rank    int
+
+	Ptr     *int64
+	KeyHash string
+}
@@ -0,0 +1,12 @@
+package search
+
+import "context"
+
+//go:generate mockgen -source=$GOFILE -destination=../mock/search/mock.go -package=search -typed
+type Repository interface {
+	// Search - returns hits matched to `text`. At most `limit` hits sorted by rank and level in descending order are returned for every hit type,
+	// so the first `limit` hits of merged result are correct. `limit` is capped by `MaxDepth`.
+	Search(ctx context.Context, text string, limit int64, types ...string) ([]Hit, error)
+	// Count - returns count of hits matched to `text`. Count of every hit type is capped by `MaxDepth`.
+	Count(ctx context.Context, text string, types ...string) (int64, error)
+}
@@ -0,0 +1,82 @@
+package smartrollup
+
+import (
+	"time"
+
+	"github.com/baking-bad/bcdhub/internal/models/account"
+	"github.com/shopspring/decimal"
+	"github.com/uptrace/bun"
+)
+
+// Commitment - commitment published by staker with `smart_rollup_publish`. Several stakers may stake on the same commitment, so hash isn't unique.
+type Commitment struct {
+	bun.BaseModel `bun:"smart_rollup_commitments"`
+
+	ID        int64 `bun:"id,pk,notnull,autoincrement"`
+	Level     int64
+	Timestamp time.Time
+
+	RollupId    int64
+	Rollup      account.Account `bun:",rel:belongs-to"`
+	PublisherId int64
+	Publisher   account.Account `bun:",rel:belongs-to"`
+
+	Hash            string          `bun:"hash,type:text"`
+	Predecessor     string          `bun:"predecessor,type:text"`
+	CompressedState string          `bun:"compressed_state,type:text"`
+	InboxLevel      int64           `bun:"inbox_level"`
+	NumberOfTicks   decimal.Decimal `bun:"number_of_ticks,type:numeric(20,0)"`
+}
+
+// GetID -
+func (c *Commitment) GetID() int64 {
+	return c.ID
+}
+
+func (Commitment) TableName() string {
+	return "smart_rollup_commitments"
+}
+
+// LogFields -
+func (c *Commitment) LogFields() map[string]interface{} {
+	return map[string]interface{}{
+		"rollup": c.Rollup.Address,
+		"hash":   c.Hash,
+		"block":  c.Level,
+	}
+}
+
+// Cementation - commitment cemented with `smart_rollup_cement`
+type Cementation struct {
+	bun.BaseModel `bun:"smart_rollup_cementations"`
+
+	ID        int64 `bun:"id,pk,notnull,autoincrement"`
+	Level     int64
+	Timestamp time.Time
+
+	RollupId int64
+	Rollup   account.Account `bun:",rel:belongs-to"`
+	SenderId int64
+	Sender   account.Account `bun:",rel:belongs-to"`
+
+	CommitmentHash string `bun:"commitment_hash,type:text"`
+	InboxLevel     int64  `bun:"inbox_level"`
+}
+
+// GetID -
+func (c *Cementation) GetID() int64 {
+	return c.ID
+}
+
+func (Cementation) TableName() string {
+	return "smart_rollup_cementations"
+}
+
+// LogFields -
+func (c *Cementation) LogFields() map[string]interface{} {
+	return map[string]interface{}{
+		"rollup":     c.Rollup.Address,
+		"commitment": c.CommitmentHash,
+		"block":      c.Level,
+	}
+}
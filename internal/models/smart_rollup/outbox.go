@@ -0,0 +1,79 @@
+package smartrollup
+
+import (
+	"time"
+
+	"github.com/baking-bad/bcdhub/internal/models/account"
+	"github.com/uptrace/bun"
+)
+
+// OutboxMessage - outbox message executed with `smart_rollup_execute_outbox_message`. Transactions of the message are stored as internal operations with the same hash and counter.
+type OutboxMessage struct {
+	bun.BaseModel `bun:"smart_rollup_outbox_messages"`
+
+	ID        int64 `bun:"id,pk,notnull,autoincrement"`
+	Level     int64
+	Timestamp time.Time
+
+	RollupId   int64
+	Rollup     account.Account `bun:",rel:belongs-to"`
+	ExecutorId int64
+	Executor   account.Account `bun:",rel:belongs-to"`
+
+	Hash               []byte `bun:"hash,type:bytea"`
+	Counter            int64  `bun:"counter"`
+	CementedCommitment string `bun:"cemented_commitment,type:text"`
+	TransactionsCount  int    `bun:"transactions_count"`
+}
+
+// GetID -
+func (m *OutboxMessage) GetID() int64 {
+	return m.ID
+}
+
+func (OutboxMessage) TableName() string {
+	return "smart_rollup_outbox_messages"
+}
+
+// LogFields -
+func (m *OutboxMessage) LogFields() map[string]interface{} {
+	return map[string]interface{}{
+		"rollup": m.Rollup.Address,
+		"block":  m.Level,
+	}
+}
+
+// InboxMessage - external message which is added to shared inbox of smart rollups with `smart_rollup_add_messages`. Index is position of message in the operation.
+type InboxMessage struct {
+	bun.BaseModel `bun:"smart_rollup_inbox_messages"`
+
+	ID        int64 `bun:"id,pk,notnull,autoincrement"`
+	Level     int64
+	Timestamp time.Time
+
+	SenderId int64
+	Sender   account.Account `bun:",rel:belongs-to"`
+
+	Hash    []byte `bun:"hash,type:bytea"`
+	Counter int64  `bun:"counter"`
+	Index   int64  `bun:"index"`
+	Payload []byte `bun:"payload,type:bytea"`
+}
+
+// GetID -
+func (m *InboxMessage) GetID() int64 {
+	return m.ID
+}
+
+func (InboxMessage) TableName() string {
+	return "smart_rollup_inbox_messages"
+}
+
+// LogFields -
+func (m *InboxMessage) LogFields() map[string]interface{} {
+	return map[string]interface{}{
+		"sender": m.Sender.Address,
+		"index":  m.Index,
+		"block":  m.Level,
+	}
+}
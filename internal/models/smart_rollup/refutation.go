@@ -0,0 +1,77 @@
+package smartrollup
+
+import (
+	"time"
+
+	"github.com/baking-bad/bcdhub/internal/models/account"
+	"github.com/uptrace/bun"
+)
+
+// kinds of refutation
+const (
+	RefutationKindStart   = "start"
+	RefutationKindMove    = "move"
+	RefutationKindTimeout = "timeout"
+)
+
+// statuses of refutation game
+const (
+	GameStatusOngoing = "ongoing"
+	GameStatusEnded   = "ended"
+)
+
+// results of ended refutation game
+const (
+	GameResultLoser = "loser"
+	GameResultDraw  = "draw"
+)
+
+// Refutation - step of refutation game between two stakers: start and moves are made by `smart_rollup_refute`, game may be finished by `smart_rollup_timeout`.
+// Player is source of `smart_rollup_refute` or the first staker of `smart_rollup_timeout`.
+type Refutation struct {
+	bun.BaseModel `bun:"smart_rollup_refutations"`
+
+	ID        int64 `bun:"id,pk,notnull,autoincrement"`
+	Level     int64
+	Timestamp time.Time
+
+	RollupId   int64
+	Rollup     account.Account `bun:",rel:belongs-to"`
+	PlayerId   int64
+	Player     account.Account `bun:",rel:belongs-to"`
+	OpponentId int64
+	Opponent   account.Account `bun:",rel:belongs-to"`
+	LoserId    int64
+	Loser      account.Account `bun:",rel:belongs-to"`
+
+	Kind               string `bun:"kind,type:text"`
+	PlayerCommitment   string `bun:"player_commitment,type:text"`
+	OpponentCommitment string `bun:"opponent_commitment,type:text"`
+	Status             string `bun:"status,type:text"`
+	Result             string `bun:"result,type:text"`
+	Reason             string `bun:"reason,type:text"`
+}
+
+// GetID -
+func (r *Refutation) GetID() int64 {
+	return r.ID
+}
+
+func (Refutation) TableName() string {
+	return "smart_rollup_refutations"
+}
+
+// LogFields -
+func (r *Refutation) LogFields() map[string]interface{} {
+	return map[string]interface{}{
+		"rollup":   r.Rollup.Address,
+		"player":   r.Player.Address,
+		"opponent": r.Opponent.Address,
+		"block":    r.Level,
+	}
+}
+
+// IsEnded -
+func (r Refutation) IsEnded() bool {
+	return r.Status == GameStatusEnded
+}
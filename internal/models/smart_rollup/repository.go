@@ -2,8 +2,21 @@ package smartrollup
 
 import "context"
 
+// ListRequest -
+type ListRequest struct {
+	Limit  int64
+	Offset int64
+	Sort   string
+}
+
 //go:generate mockgen -source=$GOFILE -destination=../mock/smart_rollup/mock.go -package=smart_rollup -typed
 type Repository interface {
 	Get(ctx context.Context, address string) (SmartRollup, error)
 	List(ctx context.Context, limit, offset int64, sort string) ([]SmartRollup, error)
+	Commitments(ctx context.Context, rollupId int64, req ListRequest) ([]Commitment, error)
+	Cementations(ctx context.Context, rollupId int64, hashes ...string) ([]Cementation, error)
+	Stakers(ctx context.Context, rollupId int64) ([]Staker, error)
+	Games(ctx context.Context, rollupId int64, req ListRequest) ([]Refutation, error)
+	GameMoves(ctx context.Context, rollupId int64, start Refutation) ([]Refutation, error)
+	OutboxMessages(ctx context.Context, rollupId int64, req ListRequest) ([]OutboxMessage, error)
 }
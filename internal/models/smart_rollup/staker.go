@@ -0,0 +1,58 @@
+package smartrollup
+
+import (
+	"time"
+
+	"github.com/baking-bad/bcdhub/internal/models/account"
+	"github.com/uptrace/bun"
+)
+
+// BondRecovery - bond of staker recovered with `smart_rollup_recover_bond`
+type BondRecovery struct {
+	bun.BaseModel `bun:"smart_rollup_bond_recoveries"`
+
+	ID        int64 `bun:"id,pk,notnull,autoincrement"`
+	Level     int64
+	Timestamp time.Time
+
+	RollupId int64
+	Rollup   account.Account `bun:",rel:belongs-to"`
+	StakerId int64
+	Staker   account.Account `bun:",rel:belongs-to"`
+}
+
+// GetID -
+func (b *BondRecovery) GetID() int64 {
+	return b.ID
+}
+
+func (BondRecovery) TableName() string {
+	return "smart_rollup_bond_recoveries"
+}
+
+// LogFields -
+func (b *BondRecovery) LogFields() map[string]interface{} {
+	return map[string]interface{}{
+		"rollup": b.Rollup.Address,
+		"staker": b.Staker.Address,
+		"block":  b.Level,
+	}
+}
+
+// Staker - state of rollup staker which is aggregated from its commitments, bond recoveries and lost refutation games.
+// Staker is active if it published commitment after last recovery of bond and last lost game.
+type Staker struct {
+	StakerId         int64  `bun:"staker_id"`
+	Address          string `bun:"address"`
+	FirstLevel       int64  `bun:"first_level"`
+	LastLevel        int64  `bun:"last_level"`
+	CommitmentsCount int64  `bun:"commitments_count"`
+	LastCommitment   string `bun:"last_commitment"`
+	RecoveredLevel   int64  `bun:"recovered_level"`
+	LostLevel        int64  `bun:"lost_level"`
+}
+
+// IsActive -
+func (s Staker) IsActive() bool {
+	return s.LastLevel > s.RecoveredLevel && s.LastLevel > s.LostLevel
+}
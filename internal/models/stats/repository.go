@@ -1,8 +1,47 @@
 package stats
 
-import "context"
+import (
+	"context"
+	"time"
+
+	"github.com/baking-bad/bcdhub/internal/models/types"
+)
 
 //go:generate mockgen -source=$GOFILE -destination=../mock/stats/mock.go -package=stats -typed
 type Repository interface {
 	Get(ctx context.Context) (Stats, error)
+	Series(ctx context.Context, req SeriesRequest) ([]SeriesPoint, error)
+}
+
+// Metrics of series
+const (
+	MetricOperations   = "operations"
+	MetricCallers      = "callers"
+	MetricGas          = "gas"
+	MetricBurned       = "burned"
+	MetricOriginations = "originations"
+	MetricEvents       = "events"
+)
+
+// Buckets of series
+const (
+	BucketHour = "hour"
+	BucketDay  = "day"
+)
+
+// SeriesRequest - request of time series. ContractId is 0 for network-wide series. Kinds and Status filter operations for all metrics except callers. Range is [From, To).
+type SeriesRequest struct {
+	Metric     string
+	Bucket     string
+	ContractId int64
+	Kinds      []types.OperationKind
+	Status     types.OperationStatus
+	From       time.Time
+	To         time.Time
+}
+
+// SeriesPoint -
+type SeriesPoint struct {
+	Timestamp time.Time `bun:"timestamp"`
+	Value     int64     `bun:"value"`
 }
@@ -0,0 +1,152 @@
+package stats
+
+import (
+	"time"
+
+	"github.com/baking-bad/bcdhub/internal/models/operation"
+	"github.com/baking-bad/bcdhub/internal/models/types"
+	"github.com/uptrace/bun"
+)
+
+// Series - hourly aggregate of operations with the same kind and status. ContractId is 0 for network-wide aggregates.
+type Series struct {
+	bun.BaseModel `bun:"stats_series"`
+
+	Timestamp       time.Time             `bun:"timestamp,pk,notnull"`
+	ContractId      int64                 `bun:"contract_id,pk,notnull"`
+	Kind            types.OperationKind   `bun:"kind,pk,type:SMALLINT"`
+	Status          types.OperationStatus `bun:"status,pk,type:SMALLINT"`
+	OperationsCount int64                 `bun:"operations_count"`
+	ConsumedGas     int64                 `bun:"consumed_gas"`
+	Burned          int64                 `bun:"burned"`
+}
+
+// GetID -
+func (Series) GetID() int64 {
+	return 0
+}
+
+func (Series) TableName() string {
+	return "stats_series"
+}
+
+// Caller - account which called contract during the hour. ContractId is 0 for calls of any contract. Level is the first level of the hour when account called contract, so caller is removed on rollback of this level only.
+type Caller struct {
+	bun.BaseModel `bun:"stats_callers"`
+
+	Timestamp  time.Time `bun:"timestamp,pk,notnull"`
+	ContractId int64     `bun:"contract_id,pk,notnull"`
+	CallerId   int64     `bun:"caller_id,pk,notnull"`
+	Level      int64     `bun:"level"`
+}
+
+// GetID -
+func (Caller) GetID() int64 {
+	return 0
+}
+
+func (Caller) TableName() string {
+	return "stats_callers"
+}
+
+type seriesKey struct {
+	timestamp  time.Time
+	contractId int64
+	kind       types.OperationKind
+	status     types.OperationStatus
+}
+
+type callerKey struct {
+	timestamp  time.Time
+	contractId int64
+	callerId   int64
+}
+
+// SeriesBuilder - aggregates operations into hourly series of network and of contracts
+type SeriesBuilder struct {
+	series  map[seriesKey]*Series
+	callers map[callerKey]*Caller
+}
+
+// NewSeriesBuilder -
+func NewSeriesBuilder() *SeriesBuilder {
+	return &SeriesBuilder{
+		series:  make(map[seriesKey]*Series),
+		callers: make(map[callerKey]*Caller),
+	}
+}
+
+// Add - adds operation to series. Account identities of operation have to be set.
+func (b *SeriesBuilder) Add(op *operation.Operation) {
+	timestamp := op.Timestamp.UTC().Truncate(time.Hour)
+
+	contractId := seriesContract(op)
+	b.addSeries(timestamp, 0, op)
+	if contractId > 0 {
+		b.addSeries(timestamp, contractId, op)
+	}
+
+	if op.Kind == types.OperationKindTransaction && contractId > 0 && op.SourceID > 0 {
+		b.addCaller(timestamp, 0, op)
+		b.addCaller(timestamp, contractId, op)
+	}
+}
+
+// Series - returns aggregated series
+func (b *SeriesBuilder) Series() []*Series {
+	result := make([]*Series, 0, len(b.series))
+	for _, s := range b.series {
+		result = append(result, s)
+	}
+	return result
+}
+
+// Callers - returns unique callers
+func (b *SeriesBuilder) Callers() []*Caller {
+	result := make([]*Caller, 0, len(b.callers))
+	for _, c := range b.callers {
+		result = append(result, c)
+	}
+	return result
+}
+
+func (b *SeriesBuilder) addSeries(timestamp time.Time, contractId int64, op *operation.Operation) {
+	key := seriesKey{timestamp, contractId, op.Kind, op.Status}
+	s, ok := b.series[key]
+	if !ok {
+		s = &Series{
+			Timestamp:  timestamp,
+			ContractId: contractId,
+			Kind:       op.Kind,
+			Status:     op.Status,
+		}
+		b.series[key] = s
+	}
+	s.OperationsCount += 1
+	s.ConsumedGas += op.ConsumedGas
+	s.Burned += op.Burned + op.AllocatedDestinationContractBurned
+}
+
+func (b *SeriesBuilder) addCaller(timestamp time.Time, contractId int64, op *operation.Operation) {
+	key := callerKey{timestamp, contractId, op.SourceID}
+	if _, ok := b.callers[key]; ok {
+		return
+	}
+	b.callers[key] = &Caller{
+		Timestamp:  timestamp,
+		ContractId: contractId,
+		CallerId:   op.SourceID,
+		Level:      op.Level,
+	}
+}
+
+// seriesContract - returns identity of contract which operation is related to: emitter of event or contract destination
+func seriesContract(op *operation.Operation) int64 {
+	if op.Kind == types.OperationKindEvent {
+		return op.SourceID
+	}
+	if op.Destination.Type == types.AccountTypeContract {
+		return op.DestinationID
+	}
+	return 0
+}
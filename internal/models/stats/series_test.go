@@ -0,0 +1,90 @@
+package stats
+
+import (
+	"testing"
+	"time"
+
+	"github.com/baking-bad/bcdhub/internal/models/account"
+	"github.com/baking-bad/bcdhub/internal/models/operation"
+	"github.com/baking-bad/bcdhub/internal/models/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSeriesBuilder(t *testing.T) {
+	ts := time.Date(2024, 1, 1, 10, 15, 0, 0, time.UTC)
+	hour := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	contract := account.Account{ID: 1, Type: types.AccountTypeContract}
+	implicit := account.Account{ID: 2, Type: types.AccountTypeTz}
+
+	builder := NewSeriesBuilder()
+	for _, op := range []*operation.Operation{
+		{
+			Level:         100,
+			Timestamp:     ts,
+			Kind:          types.OperationKindTransaction,
+			Status:        types.OperationStatusApplied,
+			SourceID:      implicit.ID,
+			Source:        implicit,
+			DestinationID: contract.ID,
+			Destination:   contract,
+			ConsumedGas:   1000,
+			Burned:        10,
+		}, {
+			Level:         101,
+			Timestamp:     ts.Add(time.Minute * 30),
+			Kind:          types.OperationKindTransaction,
+			Status:        types.OperationStatusApplied,
+			SourceID:      implicit.ID,
+			Source:        implicit,
+			DestinationID: contract.ID,
+			Destination:   contract,
+			ConsumedGas:   500,
+		}, {
+			Level:     101,
+			Timestamp: ts.Add(time.Minute * 30),
+			Kind:      types.OperationKindEvent,
+			Status:    types.OperationStatusApplied,
+			SourceID:  contract.ID,
+			Source:    contract,
+		}, {
+			Level:         102,
+			Timestamp:     ts.Add(time.Hour),
+			Kind:          types.OperationKindTransaction,
+			Status:        types.OperationStatusFailed,
+			SourceID:      contract.ID,
+			Source:        contract,
+			DestinationID: implicit.ID,
+			Destination:   implicit,
+		},
+	} {
+		builder.Add(op)
+	}
+
+	series := make(map[seriesKey]Series)
+	for _, s := range builder.Series() {
+		series[seriesKey{s.Timestamp, s.ContractId, s.Kind, s.Status}] = *s
+	}
+	require.Len(t, series, 5)
+
+	network := series[seriesKey{hour, 0, types.OperationKindTransaction, types.OperationStatusApplied}]
+	require.EqualValues(t, 2, network.OperationsCount)
+	require.EqualValues(t, 1500, network.ConsumedGas)
+	require.EqualValues(t, 10, network.Burned)
+
+	calls := series[seriesKey{hour, contract.ID, types.OperationKindTransaction, types.OperationStatusApplied}]
+	require.EqualValues(t, 2, calls.OperationsCount)
+
+	events := series[seriesKey{hour, contract.ID, types.OperationKindEvent, types.OperationStatusApplied}]
+	require.EqualValues(t, 1, events.OperationsCount)
+
+	failed := series[seriesKey{hour.Add(time.Hour), 0, types.OperationKindTransaction, types.OperationStatusFailed}]
+	require.EqualValues(t, 1, failed.OperationsCount)
+
+	callers := builder.Callers()
+	require.Len(t, callers, 2)
+	for _, caller := range callers {
+		require.Equal(t, hour, caller.Timestamp)
+		require.Equal(t, implicit.ID, caller.CallerId)
+		require.EqualValues(t, 100, caller.Level)
+	}
+}
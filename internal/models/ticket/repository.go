@@ -1,6 +1,25 @@
 package ticket
 
-import "context"
+import (
+	"context"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// Summary - current total supply of ticket and count of accounts with positive balance
+type Summary struct {
+	Supply       decimal.Decimal `bun:"supply"`
+	HoldersCount int64           `bun:"holders_count"`
+}
+
+// SupplyPoint - total supply of ticket after the level where it was changed
+type SupplyPoint struct {
+	Level     int64           `bun:"level"`
+	Timestamp time.Time       `bun:"timestamp"`
+	Delta     decimal.Decimal `bun:"delta"`
+	Supply    decimal.Decimal `bun:"supply"`
+}
 
 type BalanceRequest struct {
 	Limit               int64
@@ -9,17 +28,31 @@ type BalanceRequest struct {
 }
 
 type UpdatesRequest struct {
-	Account  string
-	Ticketer string
-	TicketId *uint64
-	Limit    int64
-	Offset   int64
+	Account   string
+	Ticketer  string
+	TicketId  *uint64
+	FromLevel int64
+	ToLevel   int64
+	Limit     int64
+	Offset    int64
+}
+
+type SupplyRequest struct {
+	FromLevel int64
+	ToLevel   int64
+	Limit     int64
+	Offset    int64
 }
 
 //go:generate mockgen -source=$GOFILE -destination=../mock/ticket/mock.go -package=ticket -typed
 type Repository interface {
+	Get(ctx context.Context, hash string) (Ticket, error)
 	List(ctx context.Context, ticketer string, limit, offset int64) ([]Ticket, error)
+	Holders(ctx context.Context, ticketId, limit, offset int64) ([]Balance, error)
+	Summary(ctx context.Context, ticketId int64) (Summary, error)
+	SupplyHistory(ctx context.Context, ticketId int64, req SupplyRequest) ([]SupplyPoint, error)
 	Updates(ctx context.Context, req UpdatesRequest) ([]TicketUpdate, error)
+	ExportUpdates(ctx context.Context, req UpdatesRequest, handler func(updates []TicketUpdate) error) error
 	UpdatesForOperation(ctx context.Context, operationId int64) ([]TicketUpdate, error)
 	BalancesForAccount(ctx context.Context, accountId int64, req BalanceRequest) ([]Balance, error)
 }
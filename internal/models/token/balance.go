@@ -0,0 +1,43 @@
+package token
+
+import (
+	"fmt"
+
+	"github.com/baking-bad/bcdhub/internal/models/account"
+	"github.com/shopspring/decimal"
+	"github.com/uptrace/bun"
+)
+
+type Balance struct {
+	bun.BaseModel `bun:"token_balances"`
+
+	ContractId int64           `bun:"contract_id,pk,notnull"`
+	AccountId  int64           `bun:"account_id,pk,notnull"`
+	TokenId    decimal.Decimal `bun:"token_id,pk,notnull,type:numeric(200,0)"`
+	Amount     decimal.Decimal `bun:"amount,type:numeric(200,0)"`
+
+	Contract account.Account `bun:"rel:belongs-to"`
+	Account  account.Account `bun:"rel:belongs-to"`
+}
+
+func (Balance) GetID() int64 {
+	return 0
+}
+
+func (Balance) TableName() string {
+	return "token_balances"
+}
+
+// LogFields -
+func (b Balance) LogFields() map[string]interface{} {
+	return map[string]interface{}{
+		"contract_id": b.ContractId,
+		"account_id":  b.AccountId,
+		"token_id":    b.TokenId.String(),
+		"amount":      b.Amount.String(),
+	}
+}
+
+func (b Balance) String() string {
+	return fmt.Sprintf("%s_%s_%s", b.Contract.Address, b.TokenId.String(), b.Account.Address)
+}
@@ -0,0 +1,34 @@
+package token
+
+import (
+	"context"
+
+	"github.com/shopspring/decimal"
+)
+
+type BalanceRequest struct {
+	Limit               int64
+	Offset              int64
+	WithoutZeroBalances bool
+}
+
+type HoldersRequest struct {
+	TokenId *decimal.Decimal
+	Limit   int64
+	Offset  int64
+}
+
+type TransfersRequest struct {
+	Contract string
+	Account  string
+	TokenId  *decimal.Decimal
+	Limit    int64
+	Offset   int64
+}
+
+//go:generate mockgen -source=$GOFILE -destination=../mock/token/mock.go -package=token -typed
+type Repository interface {
+	Holders(ctx context.Context, contractId int64, req HoldersRequest) ([]Balance, error)
+	BalancesForAccount(ctx context.Context, accountId int64, req BalanceRequest) ([]Balance, error)
+	Transfers(ctx context.Context, req TransfersRequest) ([]Transfer, error)
+}
@@ -0,0 +1,47 @@
+package token
+
+import (
+	"time"
+
+	"github.com/baking-bad/bcdhub/internal/models/account"
+	"github.com/shopspring/decimal"
+	"github.com/uptrace/bun"
+)
+
+// Transfer - FA1.2 or FA2 token transfer. Empty sender means mint, empty receiver means burn.
+type Transfer struct {
+	bun.BaseModel `bun:"token_transfers"`
+
+	ID          int64           `bun:"id,pk,notnull,autoincrement"`
+	Timestamp   time.Time       `bun:"timestamp,pk,notnull"`
+	Level       int64           `bun:"level"`
+	OperationId int64           `bun:"operation_id"`
+	ContractId  int64           `bun:"contract_id"`
+	TokenId     decimal.Decimal `bun:"token_id,type:numeric(200,0)"`
+	FromId      int64           `bun:"from_id"`
+	ToId        int64           `bun:"to_id"`
+	Amount      decimal.Decimal `bun:"amount,type:numeric(200,0)"`
+
+	Contract account.Account `bun:"rel:belongs-to"`
+	From     account.Account `bun:"rel:belongs-to"`
+	To       account.Account `bun:"rel:belongs-to"`
+}
+
+// GetID -
+func (t *Transfer) GetID() int64 {
+	return t.ID
+}
+
+func (Transfer) TableName() string {
+	return "token_transfers"
+}
+
+// LogFields -
+func (t *Transfer) LogFields() map[string]interface{} {
+	return map[string]interface{}{
+		"id":          t.ID,
+		"block":       t.Level,
+		"contract_id": t.ContractId,
+		"token_id":    t.TokenId.String(),
+	}
+}
@@ -0,0 +1,506 @@
+package noderpc
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	stdJSON "encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	cacheRefsDir    = "refs"
+	cacheObjectsDir = "objects"
+	cacheTempPrefix = ".tmp-"
+	cacheHeadFile   = "head"
+
+	// DefaultFinalityDepth - count of blocks after which block can't be reorganized
+	DefaultFinalityDepth int64 = 2
+)
+
+// Cache - node RPC which keeps immutable responses (blocks, operations, block metadata and scripts at finalized levels) on local disk.
+// Responses are stored by hash of their content (`objects`) and requests are linked to them by hash of URI (`refs`), so identical responses are stored once.
+// Cached responses are returned without requests to the node, so indexing can be replayed without the node.
+// If node URI is empty, cache works in cache-only mode: missed responses aren't requested and head is the highest cached block.
+type Cache struct {
+	*NodeRPC
+
+	dir           string
+	maxSize       int64
+	finalityDepth int64
+
+	head atomic.Int64
+
+	headMx     sync.Mutex
+	cachedHead int64
+
+	mx      sync.Mutex
+	size    int64
+	entries map[string]*cacheEntry
+}
+
+type cacheEntry struct {
+	size       int64
+	accessTime time.Time
+}
+
+// CacheOption -
+type CacheOption func(*Cache)
+
+// WithCacheSize - sets max size of cache directory in bytes. Least recently used files are removed if it's exceeded. Zero means unlimited size.
+func WithCacheSize(size int64) CacheOption {
+	return func(c *Cache) {
+		if size > 0 {
+			c.maxSize = size
+		}
+	}
+}
+
+// WithFinalityDepth - sets count of blocks after which responses of the level can be cached
+func WithFinalityDepth(depth int64) CacheOption {
+	return func(c *Cache) {
+		if depth >= 0 {
+			c.finalityDepth = depth
+		}
+	}
+}
+
+// NewCache - creates cache of `node` responses in `dir`. Files which already exist in `dir` are used.
+func NewCache(node *NodeRPC, dir string, opts ...CacheOption) (*Cache, error) {
+	c := &Cache{
+		NodeRPC:       node,
+		dir:           dir,
+		finalityDepth: DefaultFinalityDepth,
+		entries:       make(map[string]*cacheEntry),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	for _, sub := range []string{cacheRefsDir, cacheObjectsDir} {
+		if err := os.MkdirAll(filepath.Join(dir, sub), 0o750); err != nil {
+			return nil, errors.Wrap(err, "create cache directory")
+		}
+	}
+	if err := c.scan(); err != nil {
+		return nil, err
+	}
+	if err := c.loadCachedHead(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Size - returns total size of cached files in bytes
+func (c *Cache) Size() int64 {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+	return c.size
+}
+
+// Block - returns block
+func (c *Cache) Block(ctx context.Context, level int64) (block Block, err error) {
+	err = c.get(ctx, "Block", level, fmt.Sprintf("chains/main/blocks/%s", getBlockString(level)), &block)
+	if err == nil {
+		c.setCachedHead(level)
+	}
+	return
+}
+
+// BlockHash - returns block's hash, its unique identifier.
+func (c *Cache) BlockHash(ctx context.Context, level int64) (hash string, err error) {
+	err = c.get(ctx, "BlockHash", level, fmt.Sprintf("chains/main/blocks/%s/hash", getBlockString(level)), &hash)
+	return
+}
+
+// GetHeader - get head for certain level
+func (c *Cache) GetHeader(ctx context.Context, level int64) (header Header, err error) {
+	err = c.get(ctx, "GetHeader", level, fmt.Sprintf("chains/main/blocks/%s/header", getBlockString(level)), &header)
+	if err == nil {
+		c.setCachedHead(level)
+	}
+	return
+}
+
+// GetHead - get head. In cache-only mode header of the highest cached block is returned.
+func (c *Cache) GetHead(ctx context.Context) (Header, error) {
+	if c.isCacheOnly() {
+		level, err := c.GetLevel(ctx)
+		if err != nil {
+			return Header{}, err
+		}
+		if header, err := c.GetHeader(ctx, level); err == nil {
+			return header, nil
+		}
+		// only blocks may be cached at the level, so header is taken from the block
+		block, err := c.Block(ctx, level)
+		if err != nil {
+			return Header{}, err
+		}
+		header := block.Header
+		header.ChainID = block.ChainID
+		header.Hash = block.Hash
+		header.Protocol = block.Protocol
+		return header, nil
+	}
+
+	header, err := c.NodeRPC.GetHead(ctx)
+	if err == nil {
+		c.setHead(header.Level)
+	}
+	return header, err
+}
+
+// GetLevel - get head level. In cache-only mode the highest cached level is returned.
+func (c *Cache) GetLevel(ctx context.Context) (int64, error) {
+	if c.isCacheOnly() {
+		c.headMx.Lock()
+		defer c.headMx.Unlock()
+
+		if c.cachedHead == 0 {
+			return 0, errors.Wrap(ErrNotFound, "noderpc cache: there are no cached blocks")
+		}
+		return c.cachedHead, nil
+	}
+
+	level, err := c.NodeRPC.GetLevel(ctx)
+	if err == nil {
+		c.setHead(level)
+	}
+	return level, err
+}
+
+// GetScriptJSON -
+func (c *Cache) GetScriptJSON(ctx context.Context, address string, level int64) (script Script, err error) {
+	err = c.get(ctx, "GetScriptJSON", level, fmt.Sprintf("chains/main/blocks/%s/context/contracts/%s/script", getBlockString(level), address), &script)
+	return
+}
+
+// GetRawScript -
+func (c *Cache) GetRawScript(ctx context.Context, address string, level int64) ([]byte, error) {
+	return c.getRaw(ctx, "GetRawScript", level, fmt.Sprintf("chains/main/blocks/%s/context/contracts/%s/script", getBlockString(level), address))
+}
+
+// GetScriptStorageRaw -
+func (c *Cache) GetScriptStorageRaw(ctx context.Context, address string, level int64) ([]byte, error) {
+	var response struct {
+		Storage stdJSON.RawMessage `json:"storage"`
+	}
+	err := c.get(ctx, "GetScriptStorageRaw", level, fmt.Sprintf("chains/main/blocks/%s/context/contracts/%s/script", getBlockString(level), address), &response)
+	return response.Storage, err
+}
+
+// GetOPG -
+func (c *Cache) GetOPG(ctx context.Context, block int64) (group []OperationGroup, err error) {
+	err = c.get(ctx, "GetOPG", block, fmt.Sprintf("chains/main/blocks/%s/operations/3", getBlockString(block)), &group)
+	return
+}
+
+// GetLightOPG -
+func (c *Cache) GetLightOPG(ctx context.Context, block int64) (group []LightOperationGroup, err error) {
+	err = c.get(ctx, "GetLightOPG", block, fmt.Sprintf("chains/main/blocks/%s/operations/3", getBlockString(block)), &group)
+	return
+}
+
+// GetBlockMetadata -
+func (c *Cache) GetBlockMetadata(ctx context.Context, level int64) (metadata Metadata, err error) {
+	err = c.get(ctx, "GetBlockMetadata", level, fmt.Sprintf("chains/main/blocks/%s/metadata", getBlockString(level)), &metadata)
+	return
+}
+
+func (c *Cache) get(ctx context.Context, method string, level int64, uri string, response interface{}) error {
+	data, err := c.getRaw(ctx, method, level, uri)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, response)
+}
+
+func (c *Cache) getRaw(ctx context.Context, method string, level int64, uri string) ([]byte, error) {
+	if level <= 0 {
+		return c.NodeRPC.getRaw(ctx, method, uri)
+	}
+
+	if data, ok := c.load(uri); ok {
+		return data, nil
+	}
+	if c.isCacheOnly() {
+		return nil, fmt.Errorf("%s: response isn't cached: %w", uri, ErrNotFound)
+	}
+
+	data, err := c.NodeRPC.getRaw(ctx, method, uri)
+	if err != nil {
+		return nil, err
+	}
+
+	finalized, err := c.isFinalized(ctx, level)
+	if err != nil {
+		log.Warn().Err(err).Str("uri", uri).Msg("noderpc cache: can't receive head level")
+		return data, nil
+	}
+	if finalized {
+		if err := c.store(uri, data); err != nil {
+			log.Warn().Err(err).Str("uri", uri).Msg("noderpc cache: can't store response")
+		}
+	}
+	return data, nil
+}
+
+func (c *Cache) isFinalized(ctx context.Context, level int64) (bool, error) {
+	if level <= c.head.Load()-c.finalityDepth {
+		return true, nil
+	}
+	head, err := c.GetLevel(ctx)
+	if err != nil {
+		return false, err
+	}
+	return level <= head-c.finalityDepth, nil
+}
+
+func (c *Cache) isCacheOnly() bool {
+	return c.baseURL == ""
+}
+
+// setCachedHead - remembers the highest level of cached blocks. It's stored in the cache directory, so it's known in cache-only mode after restart.
+func (c *Cache) setCachedHead(level int64) {
+	c.headMx.Lock()
+	defer c.headMx.Unlock()
+
+	// responses of not finalized levels aren't cached
+	if level <= c.cachedHead || (!c.isCacheOnly() && level > c.head.Load()-c.finalityDepth) {
+		return
+	}
+	c.cachedHead = level
+	if err := writeFile(filepath.Join(c.dir, cacheHeadFile), []byte(strconv.FormatInt(level, 10))); err != nil {
+		log.Warn().Err(err).Int64("level", level).Msg("noderpc cache: can't store head level")
+	}
+}
+
+func (c *Cache) loadCachedHead() error {
+	data, err := os.ReadFile(filepath.Join(c.dir, cacheHeadFile)) // #nosec G304 -- path is in the configured cache directory
+	switch {
+	case os.IsNotExist(err):
+		return nil
+	case err != nil:
+		return errors.Wrap(err, "read cached head")
+	}
+	level, err := strconv.ParseInt(strings.TrimSpace(string(data)), 10, 64)
+	if err != nil {
+		return errors.Wrap(err, "parse cached head")
+	}
+	c.cachedHead = level
+	return nil
+}
+
+func (c *Cache) setHead(level int64) {
+	for {
+		current := c.head.Load()
+		if level <= current || c.head.CompareAndSwap(current, level) {
+			return
+		}
+	}
+}
+
+func (c *Cache) load(uri string) ([]byte, bool) {
+	refPath := c.refPath(uri)
+	ref, err := os.ReadFile(refPath) // #nosec G304 -- path is built from hash in the configured cache directory
+	if err != nil || len(ref) != sha256.Size*2 {
+		return nil, false
+	}
+
+	objectPath := c.objectPath(string(ref))
+	data, err := os.ReadFile(objectPath) // #nosec G304 -- path is built from hash in the configured cache directory
+	if err != nil || !strings.EqualFold(hashOf(data), string(ref)) {
+		// object was evicted or corrupted, so the reference is useless
+		c.remove(refPath)
+		if err == nil {
+			c.remove(objectPath)
+		}
+		return nil, false
+	}
+
+	c.touch(refPath)
+	c.touch(objectPath)
+	return data, true
+}
+
+func (c *Cache) store(uri string, data []byte) error {
+	hash := hashOf(data)
+
+	objectPath := c.objectPath(hash)
+	if _, err := os.Stat(objectPath); err == nil {
+		c.touch(objectPath)
+	} else if err := c.write(objectPath, data); err != nil {
+		return err
+	}
+
+	if err := c.write(c.refPath(uri), []byte(hash)); err != nil {
+		return err
+	}
+
+	c.evict()
+	return nil
+}
+
+func (c *Cache) write(path string, data []byte) error {
+	if err := writeFile(path, data); err != nil {
+		return err
+	}
+
+	c.mx.Lock()
+	c.add(path, int64(len(data)), time.Now())
+	c.mx.Unlock()
+	return nil
+}
+
+// writeFile - writes to temporary file and renames it, so readers never see partially written files
+func writeFile(path string, data []byte) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return err
+	}
+
+	file, err := os.CreateTemp(dir, cacheTempPrefix)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(data); err != nil {
+		_ = file.Close()
+		_ = os.Remove(file.Name())
+		return err
+	}
+	if err := file.Close(); err != nil {
+		_ = os.Remove(file.Name())
+		return err
+	}
+	if err := os.Rename(file.Name(), path); err != nil {
+		_ = os.Remove(file.Name())
+		return err
+	}
+	return nil
+}
+
+func (c *Cache) touch(path string) {
+	now := time.Now()
+	if err := os.Chtimes(path, now, now); err != nil {
+		return
+	}
+
+	c.mx.Lock()
+	if entry, ok := c.entries[path]; ok {
+		entry.accessTime = now
+	}
+	c.mx.Unlock()
+}
+
+func (c *Cache) remove(path string) {
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		log.Warn().Err(err).Str("path", path).Msg("noderpc cache: can't remove file")
+	}
+
+	c.mx.Lock()
+	c.delete(path)
+	c.mx.Unlock()
+}
+
+// evict - removes least recently used files until cache size is less than 90% of max size
+func (c *Cache) evict() {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	if c.maxSize == 0 || c.size <= c.maxSize {
+		return
+	}
+
+	paths := make([]string, 0, len(c.entries))
+	for path := range c.entries {
+		paths = append(paths, path)
+	}
+	sort.Slice(paths, func(i, j int) bool {
+		return c.entries[paths[i]].accessTime.Before(c.entries[paths[j]].accessTime)
+	})
+
+	limit := c.maxSize / 10 * 9
+	for i := 0; i < len(paths) && c.size > limit; i++ {
+		if err := os.Remove(paths[i]); err != nil && !os.IsNotExist(err) {
+			log.Warn().Err(err).Str("path", paths[i]).Msg("noderpc cache: can't evict file")
+			continue
+		}
+		c.delete(paths[i])
+	}
+}
+
+// add - should be called under lock
+func (c *Cache) add(path string, size int64, accessTime time.Time) {
+	c.delete(path)
+	c.entries[path] = &cacheEntry{
+		size:       size,
+		accessTime: accessTime,
+	}
+	c.size += size
+}
+
+// delete - should be called under lock
+func (c *Cache) delete(path string) {
+	if entry, ok := c.entries[path]; ok {
+		c.size -= entry.size
+		delete(c.entries, path)
+	}
+}
+
+func (c *Cache) scan() error {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	return filepath.WalkDir(c.dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		if strings.HasPrefix(d.Name(), cacheTempPrefix) {
+			return os.Remove(path)
+		}
+		if path == filepath.Join(c.dir, cacheHeadFile) {
+			// head level isn't a cached response, so it's never evicted
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		c.add(path, info.Size(), info.ModTime())
+		return nil
+	})
+}
+
+func (c *Cache) refPath(uri string) string {
+	return c.hashPath(cacheRefsDir, hashOf([]byte(uri)))
+}
+
+func (c *Cache) objectPath(hash string) string {
+	return c.hashPath(cacheObjectsDir, hash)
+}
+
+// hashPath - splits files to subdirectories by first byte of hash to avoid huge directories
+func (c *Cache) hashPath(sub, hash string) string {
+	return filepath.Join(c.dir, sub, hash[:2], hash)
+}
+
+func hashOf(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
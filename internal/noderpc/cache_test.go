@@ -0,0 +1,188 @@
+package noderpc
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type testNode struct {
+	head     int64
+	requests atomic.Int64
+}
+
+func (n *testNode) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	n.requests.Add(1)
+
+	path := strings.TrimPrefix(r.URL.Path, "/chains/main/blocks/")
+	switch {
+	case path == "head/helpers/current_level":
+		fmt.Fprintf(w, `{"level":%d}`, n.head)
+	case strings.HasSuffix(path, "/operations/3"):
+		fmt.Fprint(w, `[{"hash":"opHash","contents":[{"kind":"transaction"}]}]`)
+	case strings.Contains(path, "/script"):
+		fmt.Fprint(w, `{"code":[],"storage":{"int":"1"}}`)
+	case strings.HasSuffix(path, "/header"):
+		fmt.Fprintf(w, `{"level":%s,"hash":"block_%[1]s","chain_id":"chain"}`, strings.TrimSuffix(path, "/header"))
+	case strings.HasSuffix(path, "/hash"):
+		fmt.Fprintf(w, `"block_%s"`, strings.TrimSuffix(path, "/hash"))
+	default:
+		fmt.Fprintf(w, `{"hash":"block_%s"}`, path)
+	}
+}
+
+func TestCache(t *testing.T) {
+	ctx := context.Background()
+	node := &testNode{head: 100}
+	server := httptest.NewServer(node)
+	dir := t.TempDir()
+
+	cache, err := NewCache(NewNodeRPC(server.URL), dir)
+	require.NoError(t, err)
+
+	t.Run("finalized block is requested once", func(t *testing.T) {
+		block, err := cache.Block(ctx, 10)
+		require.NoError(t, err)
+		require.Equal(t, "block_10", block.Hash)
+		requests := node.requests.Load()
+
+		block, err = cache.Block(ctx, 10)
+		require.NoError(t, err)
+		require.Equal(t, "block_10", block.Hash)
+		require.Equal(t, requests, node.requests.Load())
+	})
+
+	t.Run("not finalized block is not cached", func(t *testing.T) {
+		_, err := cache.Block(ctx, 99)
+		require.NoError(t, err)
+		requests := node.requests.Load()
+
+		_, err = cache.Block(ctx, 99)
+		require.NoError(t, err)
+		require.Greater(t, node.requests.Load(), requests)
+	})
+
+	t.Run("head is not cached", func(t *testing.T) {
+		requests := node.requests.Load()
+		_, err := cache.Block(ctx, 0)
+		require.NoError(t, err)
+		_, err = cache.Block(ctx, 0)
+		require.NoError(t, err)
+		require.Equal(t, requests+2, node.requests.Load())
+	})
+
+	t.Run("operations and scripts", func(t *testing.T) {
+		opg, err := cache.GetOPG(ctx, 20)
+		require.NoError(t, err)
+		require.Len(t, opg, 1)
+		requests := node.requests.Load()
+
+		light, err := cache.GetLightOPG(ctx, 20)
+		require.NoError(t, err)
+		require.Len(t, light, 1)
+		require.Len(t, light[0].Contents, 1)
+		require.Equal(t, `{"kind":"transaction"}`, string(light[0].Contents[0].Raw))
+
+		_, err = cache.GetRawScript(ctx, "KT1", 20)
+		require.NoError(t, err)
+		storage, err := cache.GetScriptStorageRaw(ctx, "KT1", 20)
+		require.NoError(t, err)
+		require.Equal(t, `{"int":"1"}`, string(storage))
+		require.Equal(t, requests+1, node.requests.Load())
+	})
+
+	t.Run("headers and hashes", func(t *testing.T) {
+		header, err := cache.GetHeader(ctx, 30)
+		require.NoError(t, err)
+		require.EqualValues(t, 30, header.Level)
+		hash, err := cache.BlockHash(ctx, 30)
+		require.NoError(t, err)
+		require.Equal(t, "block_30", hash)
+		requests := node.requests.Load()
+
+		_, err = cache.GetHeader(ctx, 30)
+		require.NoError(t, err)
+		_, err = cache.BlockHash(ctx, 30)
+		require.NoError(t, err)
+		require.Equal(t, requests, node.requests.Load())
+	})
+
+	server.Close()
+
+	t.Run("replay without node", func(t *testing.T) {
+		replay, err := NewCache(NewNodeRPC(server.URL), dir)
+		require.NoError(t, err)
+		require.Equal(t, cache.Size(), replay.Size())
+
+		block, err := replay.Block(ctx, 10)
+		require.NoError(t, err)
+		require.Equal(t, "block_10", block.Hash)
+
+		_, err = replay.GetOPG(ctx, 20)
+		require.NoError(t, err)
+
+		_, err = replay.Block(ctx, 11)
+		require.Error(t, err)
+	})
+
+	t.Run("cache only", func(t *testing.T) {
+		replay, err := NewCache(NewNodeRPC(""), dir)
+		require.NoError(t, err)
+
+		level, err := replay.GetLevel(ctx)
+		require.NoError(t, err)
+		require.EqualValues(t, 30, level)
+
+		head, err := replay.GetHead(ctx)
+		require.NoError(t, err)
+		require.EqualValues(t, 30, head.Level)
+		require.Equal(t, "block_30", head.Hash)
+
+		hash, err := replay.BlockHash(ctx, 30)
+		require.NoError(t, err)
+		require.Equal(t, "block_30", hash)
+
+		_, err = replay.Block(ctx, 11)
+		require.True(t, IsNotFoundError(err))
+	})
+
+	t.Run("cache only without cached blocks", func(t *testing.T) {
+		empty, err := NewCache(NewNodeRPC(""), t.TempDir())
+		require.NoError(t, err)
+
+		_, err = empty.GetLevel(ctx)
+		require.True(t, IsNotFoundError(err))
+	})
+}
+
+func TestCache_evict(t *testing.T) {
+	ctx := context.Background()
+	node := &testNode{head: 1000}
+	server := httptest.NewServer(node)
+	defer server.Close()
+
+	const maxSize = 1000
+	cache, err := NewCache(NewNodeRPC(server.URL), t.TempDir(), WithCacheSize(maxSize))
+	require.NoError(t, err)
+
+	for level := int64(100); level < 150; level++ {
+		_, err := cache.Block(ctx, level)
+		require.NoError(t, err)
+		require.LessOrEqual(t, cache.Size(), int64(maxSize))
+	}
+
+	requests := node.requests.Load()
+	_, err = cache.Block(ctx, 149)
+	require.NoError(t, err)
+	require.Equal(t, requests, node.requests.Load(), "the last block should be kept")
+
+	_, err = cache.Block(ctx, 100)
+	require.NoError(t, err)
+	require.Greater(t, node.requests.Load(), requests, "the first block should be evicted")
+}
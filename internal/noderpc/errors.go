@@ -25,12 +25,15 @@ func (e NodeUnavailiableError) Error() string {
 	return fmt.Sprintf("%s is unavailiable: %d", e.Node, e.Code)
 }
 
+// IsNotFoundError - returns true if node responded that requested entity doesn't exist
+func IsNotFoundError(err error) bool {
+	return errors.Is(err, ErrNotFound)
+}
+
 // IsNodeUnavailiableError -
 func IsNodeUnavailiableError(err error) bool {
-	if _, ok := err.(NodeUnavailiableError); ok {
-		return true
-	}
-	return false
+	var e NodeUnavailiableError
+	return errors.As(err, &e)
 }
 
 // InvalidNodeResponse -
@@ -75,4 +78,5 @@ func (e InvalidNodeResponse) Is(target error) bool {
 var (
 	ErrInvalidStatusCode = errors.New("invalid status code")
 	ErrNodeRPCError      = errors.New("node RPC error")
+	ErrNotFound          = errors.New("not found")
 )
@@ -22,10 +22,14 @@ type INode interface {
 	RunCode(context.Context, []byte, []byte, []byte, string, string, string, string, string, int64, int64) (RunCodeResponse, error)
 	RunOperation(context.Context, string, string, string, string, int64, int64, int64, int64, int64, []byte) (OperationGroup, error)
 	RunOperationLight(context.Context, string, string, string, string, int64, int64, int64, int64, int64, []byte) (LightOperationGroup, error)
+	RunOperations(ctx context.Context, chainID, branch string, contents []RunOperationContent) (LightOperationGroup, error)
 	RunScriptView(ctx context.Context, request RunScriptViewRequest) ([]byte, error)
 	GetCounter(context.Context, string) (int64, error)
+	GetManagerKey(ctx context.Context, address string) (string, error)
 	GetBigMapType(ctx context.Context, ptr, level int64) (BigMap, error)
+	GetBigMapValue(ctx context.Context, ptr int64, keyHash string, level int64) ([]byte, error)
 	GetBlockMetadata(ctx context.Context, level int64) (metadata Metadata, err error)
 	GetLevel(ctx context.Context) (int64, error)
 	GetStorage(ctx context.Context, level int64, address string) ([]byte, error)
+	GetTicketBalances(ctx context.Context, address string, level int64) ([]TicketBalance, error)
 }
@@ -157,6 +157,45 @@ func (c *MockINodeGetBigMapTypeCall) DoAndReturn(f func(context.Context, int64,
 	return c
 }
 
+// GetBigMapValue mocks base method.
+func (m *MockINode) GetBigMapValue(ctx context.Context, ptr int64, keyHash string, level int64) ([]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBigMapValue", ctx, ptr, keyHash, level)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBigMapValue indicates an expected call of GetBigMapValue.
+func (mr *MockINodeMockRecorder) GetBigMapValue(ctx, ptr, keyHash, level any) *MockINodeGetBigMapValueCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBigMapValue", reflect.TypeOf((*MockINode)(nil).GetBigMapValue), ctx, ptr, keyHash, level)
+	return &MockINodeGetBigMapValueCall{Call: call}
+}
+
+// MockINodeGetBigMapValueCall wrap *gomock.Call
+type MockINodeGetBigMapValueCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockINodeGetBigMapValueCall) Return(arg0 []byte, arg1 error) *MockINodeGetBigMapValueCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockINodeGetBigMapValueCall) Do(f func(context.Context, int64, string, int64) ([]byte, error)) *MockINodeGetBigMapValueCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockINodeGetBigMapValueCall) DoAndReturn(f func(context.Context, int64, string, int64) ([]byte, error)) *MockINodeGetBigMapValueCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // GetBlockMetadata mocks base method.
 func (m *MockINode) GetBlockMetadata(ctx context.Context, level int64) (Metadata, error) {
 	m.ctrl.T.Helper()
@@ -508,6 +547,45 @@ func (c *MockINodeGetLightOPGCall) DoAndReturn(f func(context.Context, int64) ([
 	return c
 }
 
+// GetManagerKey mocks base method.
+func (m *MockINode) GetManagerKey(ctx context.Context, address string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetManagerKey", ctx, address)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetManagerKey indicates an expected call of GetManagerKey.
+func (mr *MockINodeMockRecorder) GetManagerKey(ctx, address any) *MockINodeGetManagerKeyCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetManagerKey", reflect.TypeOf((*MockINode)(nil).GetManagerKey), ctx, address)
+	return &MockINodeGetManagerKeyCall{Call: call}
+}
+
+// MockINodeGetManagerKeyCall wrap *gomock.Call
+type MockINodeGetManagerKeyCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockINodeGetManagerKeyCall) Return(arg0 string, arg1 error) *MockINodeGetManagerKeyCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockINodeGetManagerKeyCall) Do(f func(context.Context, string) (string, error)) *MockINodeGetManagerKeyCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockINodeGetManagerKeyCall) DoAndReturn(f func(context.Context, string) (string, error)) *MockINodeGetManagerKeyCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // GetNetworkConstants mocks base method.
 func (m *MockINode) GetNetworkConstants(arg0 context.Context, arg1 int64) (Constants, error) {
 	m.ctrl.T.Helper()
@@ -742,6 +820,45 @@ func (c *MockINodeGetStorageCall) DoAndReturn(f func(context.Context, int64, str
 	return c
 }
 
+// GetTicketBalances mocks base method.
+func (m *MockINode) GetTicketBalances(ctx context.Context, address string, level int64) ([]TicketBalance, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTicketBalances", ctx, address, level)
+	ret0, _ := ret[0].([]TicketBalance)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTicketBalances indicates an expected call of GetTicketBalances.
+func (mr *MockINodeMockRecorder) GetTicketBalances(ctx, address, level any) *MockINodeGetTicketBalancesCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTicketBalances", reflect.TypeOf((*MockINode)(nil).GetTicketBalances), ctx, address, level)
+	return &MockINodeGetTicketBalancesCall{Call: call}
+}
+
+// MockINodeGetTicketBalancesCall wrap *gomock.Call
+type MockINodeGetTicketBalancesCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockINodeGetTicketBalancesCall) Return(arg0 []TicketBalance, arg1 error) *MockINodeGetTicketBalancesCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockINodeGetTicketBalancesCall) Do(f func(context.Context, string, int64) ([]TicketBalance, error)) *MockINodeGetTicketBalancesCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockINodeGetTicketBalancesCall) DoAndReturn(f func(context.Context, string, int64) ([]TicketBalance, error)) *MockINodeGetTicketBalancesCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // RunCode mocks base method.
 func (m *MockINode) RunCode(arg0 context.Context, arg1, arg2, arg3 []byte, arg4, arg5, arg6, arg7, arg8 string, arg9, arg10 int64) (RunCodeResponse, error) {
 	m.ctrl.T.Helper()
@@ -859,6 +976,45 @@ func (c *MockINodeRunOperationLightCall) DoAndReturn(f func(context.Context, str
 	return c
 }
 
+// RunOperations mocks base method.
+func (m *MockINode) RunOperations(ctx context.Context, chainID, branch string, contents []RunOperationContent) (LightOperationGroup, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RunOperations", ctx, chainID, branch, contents)
+	ret0, _ := ret[0].(LightOperationGroup)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RunOperations indicates an expected call of RunOperations.
+func (mr *MockINodeMockRecorder) RunOperations(ctx, chainID, branch, contents any) *MockINodeRunOperationsCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RunOperations", reflect.TypeOf((*MockINode)(nil).RunOperations), ctx, chainID, branch, contents)
+	return &MockINodeRunOperationsCall{Call: call}
+}
+
+// MockINodeRunOperationsCall wrap *gomock.Call
+type MockINodeRunOperationsCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockINodeRunOperationsCall) Return(arg0 LightOperationGroup, arg1 error) *MockINodeRunOperationsCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockINodeRunOperationsCall) Do(f func(context.Context, string, string, []RunOperationContent) (LightOperationGroup, error)) *MockINodeRunOperationsCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockINodeRunOperationsCall) DoAndReturn(f func(context.Context, string, string, []RunOperationContent) (LightOperationGroup, error)) *MockINodeRunOperationsCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // RunScriptView mocks base method.
 func (m *MockINode) RunScriptView(ctx context.Context, request RunScriptViewRequest) ([]byte, error) {
 	m.ctrl.T.Helper()
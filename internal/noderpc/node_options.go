@@ -31,3 +31,10 @@ func WithLog() NodeOption {
 		node.needLog = true
 	}
 }
+
+// WithNetwork - sets network which is used as label of node metrics
+func WithNetwork(network string) NodeOption {
+	return func(node *NodeRPC) {
+		node.network = network
+	}
+}
@@ -2,319 +2,582 @@ package noderpc
 
 import (
 	"context"
-	"crypto/rand"
-	"math/big"
-	"reflect"
+	"errors"
+	"net"
+	"net/url"
+	"strings"
+	"sync"
 	"time"
 
-	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
 )
 
-// Pool - node pool
-type Pool []*poolItem
+const (
+	defaultCheckInterval = time.Second * 10
+	defaultMaxLag        = int64(2)
+	defaultBlockDuration = time.Minute
 
-type poolItem struct {
-	node      *NodeRPC
-	blockTime time.Time
+	// latencySmoothing - weight of the last probe in moving average of node latency
+	latencySmoothing = 0.3
+)
+
+// ErrNoAvailableNodes -
+var ErrNoAvailableNodes = errors.New("no available nodes")
+
+// NodeStatus - health of node
+type NodeStatus struct {
+	URL       string
+	Level     int64
+	Lag       int64
+	Latency   time.Duration
+	Available bool
+	Failures  int64
+	LastError string
+	CheckedAt time.Time
+}
+
+// StatusReporter - node which reports health of its nodes
+type StatusReporter interface {
+	Status() []NodeStatus
+}
+
+// PoolOption -
+type PoolOption func(*Pool)
+
+// WithCheckInterval - sets interval between probes of nodes
+func WithCheckInterval(interval time.Duration) PoolOption {
+	return func(p *Pool) {
+		if interval > 0 {
+			p.checkInterval = interval
+		}
+	}
 }
 
-func newPoolItem(url string, opts ...NodeOption) *poolItem {
-	return &poolItem{
-		node:      NewNodeRPC(url, opts...),
-		blockTime: time.Now(),
+// WithMaxLag - sets count of levels which node may be behind the highest head in the pool
+func WithMaxLag(lag int64) PoolOption {
+	return func(p *Pool) {
+		if lag >= 0 {
+			p.maxLag = lag
+		}
 	}
 }
 
-func newWaitPoolItem(url string, opts ...NodeOption) *poolItem {
-	return &poolItem{
-		node:      NewWaitNodeRPC(url, opts...),
-		blockTime: time.Now(),
+// WithBlockDuration - sets time during which failed node doesn't receive requests
+func WithBlockDuration(duration time.Duration) PoolOption {
+	return func(p *Pool) {
+		if duration > 0 {
+			p.blockDuration = duration
+		}
 	}
 }
 
-func (p *poolItem) block() {
-	p.blockTime = time.Now().Add(time.Minute * 5)
+// Pool - pool of nodes. Head level and latency of each node are probed in background.
+// Requests are routed to the fastest node which isn't failed and isn't behind the highest head of the pool by more than `maxLag` levels.
+// Idempotent requests are retried on another node if node fails or if node which is behind the highest head responds with not found error. Simulations aren't retried because their results depend on state of the node.
+type Pool struct {
+	nodes []*poolItem
+
+	checkInterval time.Duration
+	maxLag        int64
+	blockDuration time.Duration
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
 }
 
-// func (p *poolItem) isBlocked() bool {
-// 	return time.Now().After(p.blockTime)
-// }
+type poolItem struct {
+	url  string
+	node INode
+
+	mx           sync.RWMutex
+	level        int64
+	latency      time.Duration
+	checkedAt    time.Time
+	blockedUntil time.Time
+	failures     int64
+	lastError    string
+}
 
 // NewPool - creates `Pool` struct by `urls`
-func NewPool(urls []string, opts ...NodeOption) Pool {
-	pool := make(Pool, len(urls))
+func NewPool(urls []string, nodeOpts []NodeOption, opts ...PoolOption) *Pool {
+	nodes := make([]INode, len(urls))
 	for i := range urls {
-		pool[i] = newPoolItem(urls[i], opts...)
+		nodes[i] = NewNodeRPC(urls[i], nodeOpts...)
+	}
+	return newPool(urls, nodes, opts...)
+}
+
+// NewWaitPool - creates `Pool` struct by `urls` and waits until any node is up
+func NewWaitPool(urls []string, nodeOpts []NodeOption, opts ...PoolOption) *Pool {
+	pool := NewPool(urls, nodeOpts, opts...)
+
+	for {
+		pool.check(context.Background())
+		if pool.head() > 0 {
+			break
+		}
+
+		log.Warn().Strs("nodes", pool.urls()).Msg("Waiting nodes up 30 second...")
+		time.Sleep(time.Second * 30)
 	}
 	return pool
 }
 
-// NewWaitPool -
-func NewWaitPool(urls []string, opts ...NodeOption) Pool {
-	pool := make(Pool, len(urls))
-	for i := range urls {
-		pool[i] = newWaitPoolItem(urls[i], opts...)
+func newPool(urls []string, nodes []INode, opts ...PoolOption) *Pool {
+	pool := &Pool{
+		nodes:         make([]*poolItem, len(nodes)),
+		checkInterval: defaultCheckInterval,
+		maxLag:        defaultMaxLag,
+		blockDuration: defaultBlockDuration,
+	}
+	for i := range nodes {
+		pool.nodes[i] = &poolItem{
+			url:  urls[i],
+			node: nodes[i],
+		}
+	}
+	for _, opt := range opts {
+		opt(pool)
 	}
 	return pool
 }
 
-func (p Pool) getNode() (*poolItem, error) {
-	nodes := make([]*poolItem, len(p))
-	copy(nodes, p)
+// Start - starts probing of nodes in background
+func (p *Pool) Start(ctx context.Context) {
+	ctx, p.cancel = context.WithCancel(ctx)
+
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+
+		p.check(ctx)
 
-	switch len(nodes) {
-	case 0:
-		return nil, errors.Errorf("No available nodes")
-	case 1:
-		return nodes[0], nil
-	default:
-		idx, err := rand.Int(rand.Reader, big.NewInt(int64(len(nodes))))
-		if err != nil {
-			return nil, err
+		ticker := time.NewTicker(p.checkInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				p.check(ctx)
+			}
 		}
+	}()
+}
 
-		return nodes[idx.Int64()], nil
+// Close - stops probing of nodes
+func (p *Pool) Close() error {
+	if p.cancel != nil {
+		p.cancel()
 	}
+	p.wg.Wait()
+	return nil
 }
 
-func (p Pool) call(method string, args ...interface{}) (reflect.Value, error) {
-	node, err := p.getNode()
-	if err != nil {
-		return reflect.Value{}, err
+// Status - returns health of nodes in order of configuration
+func (p *Pool) Status() []NodeStatus {
+	head := p.head()
+	now := time.Now()
+
+	statuses := make([]NodeStatus, len(p.nodes))
+	for i, item := range p.nodes {
+		item.mx.RLock()
+		statuses[i] = item.status(head, p.isAvailable(item, head, now))
+		item.mx.RUnlock()
 	}
-	nodeVal := reflect.ValueOf(&node.node)
-	if nodeVal.Kind() == reflect.Ptr {
-		nodeVal = nodeVal.Elem()
+	return statuses
+}
+
+// CheckNode - probes single node and returns its health
+func CheckNode(ctx context.Context, uri string, node INode) NodeStatus {
+	item := &poolItem{
+		url:  uri,
+		node: node,
 	}
+	err := item.probe(ctx, defaultBlockDuration)
+	return item.status(item.level, err == nil)
+}
 
-	mthd := nodeVal.MethodByName(method)
-	numIn := mthd.Type().NumIn()
-	if numIn != len(args) {
-		return reflect.Value{}, errors.Errorf("Invalid args count: wait %d got %d", numIn, len(args))
+func (p *Pool) check(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, item := range p.nodes {
+		wg.Add(1)
+		go func(item *poolItem) {
+			defer wg.Done()
+
+			checkCtx, cancel := context.WithTimeout(ctx, p.checkInterval)
+			defer cancel()
+
+			if err := item.probe(checkCtx, p.blockDuration); err != nil && ctx.Err() == nil {
+				log.Warn().Err(err).Str("node", maskURL(item.url)).Msg("node health check")
+			}
+		}(item)
 	}
+	wg.Wait()
+}
 
-	in := make([]reflect.Value, numIn)
-	for i := range args {
-		in[i] = reflect.ValueOf(args[i])
+// head - returns the highest level of nodes which aren't failed
+func (p *Pool) head() int64 {
+	var head int64
+	now := time.Now()
+	for _, item := range p.nodes {
+		item.mx.RLock()
+		if !item.isBlocked(now) && item.level > head {
+			head = item.level
+		}
+		item.mx.RUnlock()
 	}
+	return head
+}
+
+// isBehind - returns true if node's head is lower than the highest head of the pool
+func (p *Pool) isBehind(item *poolItem) bool {
+	head := p.head()
+	item.mx.RLock()
+	defer item.mx.RUnlock()
+	return item.level < head
+}
 
-	response := mthd.Call(in)
+func (p *Pool) isAvailable(item *poolItem, head int64, now time.Time) bool {
+	return !item.isBlocked(now) && head-item.level <= p.maxLag
+}
 
-	switch len(response) {
-	case 1:
-		if !response[0].IsNil() {
-			return reflect.Value{}, response[0].Interface().(error)
+// next - returns the fastest available node which isn't in `exclude`. If all nodes are unavailable, node which is blocked for the shortest time is returned.
+func (p *Pool) next(exclude map[*poolItem]struct{}) *poolItem {
+	head := p.head()
+	now := time.Now()
+
+	var best, fallback *poolItem
+	var bestLatency time.Duration
+	var fallbackUntil time.Time
+	for _, item := range p.nodes {
+		if _, ok := exclude[item]; ok {
+			continue
 		}
-		return reflect.Value{}, nil
-	case 2:
-		if !response[1].IsNil() {
-			if IsNodeUnavailiableError(response[1].Interface().(error)) {
-				node.block()
-				return p.call(method, args...)
+
+		item.mx.RLock()
+		available := p.isAvailable(item, head, now)
+		latency := item.latency
+		blockedUntil := item.blockedUntil
+		item.mx.RUnlock()
+
+		if available {
+			if best == nil || latency < bestLatency {
+				best = item
+				bestLatency = latency
 			}
-			return response[0], response[1].Interface().(error)
+			continue
 		}
-		return response[0], nil
-	default:
-		node.block()
-		return reflect.Value{}, errors.Errorf("Invalid response length: %d", len(response))
+		if fallback == nil || blockedUntil.Before(fallbackUntil) {
+			fallback = item
+			fallbackUntil = blockedUntil
+		}
+	}
+
+	if best != nil {
+		return best
 	}
+	return fallback
 }
 
-// Block -
-func (p Pool) Block(ctx context.Context, level int64) (Block, error) {
-	data, err := p.call("Block", ctx, level)
-	if err != nil {
-		return Block{}, err
+func (p *Pool) urls() []string {
+	urls := make([]string, len(p.nodes))
+	for i := range p.nodes {
+		urls[i] = maskURL(p.nodes[i].url)
 	}
-	return data.Interface().(Block), nil
+	return urls
 }
 
-// BlockHash -
-func (p Pool) BlockHash(ctx context.Context, level int64) (string, error) {
-	data, err := p.call("BlockHash", ctx, level)
+func (item *poolItem) probe(ctx context.Context, blockDuration time.Duration) error {
+	start := time.Now()
+	level, err := item.node.GetLevel(ctx)
+	latency := time.Since(start)
+
+	item.mx.Lock()
+	defer item.mx.Unlock()
+
+	item.checkedAt = time.Now()
 	if err != nil {
-		return "", err
+		item.failLocked(err, blockDuration)
+		return err
 	}
-	return data.Interface().(string), nil
+
+	item.level = level
+	if item.latency == 0 {
+		item.latency = latency
+	} else {
+		item.latency = time.Duration(latencySmoothing*float64(latency) + (1-latencySmoothing)*float64(item.latency))
+	}
+	item.blockedUntil = time.Time{}
+	item.failures = 0
+	item.lastError = ""
+	return nil
 }
 
-// GetHead -
-func (p Pool) GetHead(ctx context.Context) (Header, error) {
-	data, err := p.call("GetHead", ctx)
-	if err != nil {
-		return Header{}, err
+func (item *poolItem) status(head int64, available bool) NodeStatus {
+	status := NodeStatus{
+		URL:       maskURL(item.url),
+		Level:     item.level,
+		Latency:   item.latency,
+		Available: available,
+		Failures:  item.failures,
+		LastError: item.lastError,
+		CheckedAt: item.checkedAt,
+	}
+	if item.level > 0 {
+		status.Lag = head - item.level
 	}
-	return data.Interface().(Header), nil
+	return status
 }
 
-// GetHeader -
-func (p Pool) GetHeader(ctx context.Context, block int64) (Header, error) {
-	data, err := p.call("GetHeader", ctx, block)
-	if err != nil {
-		return Header{}, err
+func (item *poolItem) fail(err error, blockDuration time.Duration) {
+	item.mx.Lock()
+	item.failLocked(err, blockDuration)
+	item.mx.Unlock()
+}
+
+func (item *poolItem) failLocked(err error, blockDuration time.Duration) {
+	item.failures += 1
+	item.lastError = strings.ReplaceAll(err.Error(), item.url, maskURL(item.url))
+	item.blockedUntil = time.Now().Add(blockDuration)
+}
+
+func (item *poolItem) isBlocked(now time.Time) bool {
+	return now.Before(item.blockedUntil)
+}
+
+// isNodeFailure - returns true if error is caused by node but not by request
+func isNodeFailure(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
 	}
-	return data.Interface().(Header), nil
+	if IsNodeUnavailiableError(err) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
 }
 
-// GetLevel -
-func (p Pool) GetLevel(ctx context.Context) (int64, error) {
-	data, err := p.call("GetLevel", ctx)
-	if err != nil {
-		return 0, err
+// maskURL - returns scheme and host of node URL only, because path and query may contain access keys
+func maskURL(value string) string {
+	u, err := url.Parse(value)
+	if err != nil || u.Host == "" {
+		return "node"
 	}
-	return data.Int(), nil
+	return u.Scheme + "://" + u.Host
 }
 
-// GetScriptJSON -
-func (p Pool) GetScriptJSON(ctx context.Context, address string, level int64) (Script, error) {
-	data, err := p.call("GetScriptJSON", ctx, address, level)
-	if err != nil {
-		return Script{}, err
+func poolCall[T any](ctx context.Context, p *Pool, idempotent bool, request func(node INode) (T, error)) (T, error) {
+	var (
+		result T
+		err    = ErrNoAvailableNodes
+		tried  = make(map[*poolItem]struct{}, len(p.nodes))
+	)
+
+	for item := p.next(tried); item != nil; item = p.next(tried) {
+		tried[item] = struct{}{}
+
+		result, err = request(item.node)
+		if err == nil {
+			return result, nil
+		}
+		if idempotent && IsNotFoundError(err) && p.isBehind(item) {
+			// node within `maxLag` may not have the newest blocks yet, so it isn't failed
+			log.Debug().Err(err).Str("node", maskURL(item.url)).Msg("node is behind, request is retried on another node")
+			continue
+		}
+		if !isNodeFailure(ctx, err) {
+			return result, err
+		}
+
+		item.fail(err, p.blockDuration)
+		if !idempotent {
+			return result, err
+		}
+		log.Warn().Err(err).Str("node", maskURL(item.url)).Msg("node failed, request is retried on another node")
 	}
-	return data.Interface().(Script), nil
+	return result, err
+}
+
+// Block -
+func (p *Pool) Block(ctx context.Context, level int64) (Block, error) {
+	return poolCall(ctx, p, true, func(node INode) (Block, error) {
+		return node.Block(ctx, level)
+	})
+}
+
+// BlockHash -
+func (p *Pool) BlockHash(ctx context.Context, level int64) (string, error) {
+	return poolCall(ctx, p, true, func(node INode) (string, error) {
+		return node.BlockHash(ctx, level)
+	})
+}
+
+// GetHead -
+func (p *Pool) GetHead(ctx context.Context) (Header, error) {
+	return poolCall(ctx, p, true, func(node INode) (Header, error) {
+		return node.GetHead(ctx)
+	})
+}
+
+// GetHeader -
+func (p *Pool) GetHeader(ctx context.Context, block int64) (Header, error) {
+	return poolCall(ctx, p, true, func(node INode) (Header, error) {
+		return node.GetHeader(ctx, block)
+	})
+}
+
+// GetLevel -
+func (p *Pool) GetLevel(ctx context.Context) (int64, error) {
+	return poolCall(ctx, p, true, func(node INode) (int64, error) {
+		return node.GetLevel(ctx)
+	})
+}
+
+// GetScriptJSON -
+func (p *Pool) GetScriptJSON(ctx context.Context, address string, level int64) (Script, error) {
+	return poolCall(ctx, p, true, func(node INode) (Script, error) {
+		return node.GetScriptJSON(ctx, address, level)
+	})
 }
 
 // GetScriptStorageRaw -
-func (p Pool) GetScriptStorageRaw(ctx context.Context, address string, level int64) ([]byte, error) {
-	data, err := p.call("GetScriptStorageRaw", ctx, address, level)
-	if err != nil {
-		return nil, err
-	}
-	return data.Interface().([]byte), nil
+func (p *Pool) GetScriptStorageRaw(ctx context.Context, address string, level int64) ([]byte, error) {
+	return poolCall(ctx, p, true, func(node INode) ([]byte, error) {
+		return node.GetScriptStorageRaw(ctx, address, level)
+	})
 }
 
 // GetContractBalance -
-func (p Pool) GetContractBalance(ctx context.Context, address string, level int64) (int64, error) {
-	data, err := p.call("GetContractBalance", ctx, address, level)
-	if err != nil {
-		return 0, err
-	}
-	return data.Int(), nil
+func (p *Pool) GetContractBalance(ctx context.Context, address string, level int64) (int64, error) {
+	return poolCall(ctx, p, true, func(node INode) (int64, error) {
+		return node.GetContractBalance(ctx, address, level)
+	})
 }
 
 // GetContractData -
-func (p Pool) GetContractData(ctx context.Context, address string, level int64) (ContractData, error) {
-	data, err := p.call("GetContractData", ctx, address, level)
-	if err != nil {
-		return ContractData{}, err
-	}
-	return data.Interface().(ContractData), nil
+func (p *Pool) GetContractData(ctx context.Context, address string, level int64) (ContractData, error) {
+	return poolCall(ctx, p, true, func(node INode) (ContractData, error) {
+		return node.GetContractData(ctx, address, level)
+	})
 }
 
 // GetOPG -
-func (p Pool) GetOPG(ctx context.Context, block int64) ([]OperationGroup, error) {
-	data, err := p.call("GetOPG", ctx, block)
-	if err != nil {
-		return nil, err
-	}
-	return data.Interface().([]OperationGroup), nil
+func (p *Pool) GetOPG(ctx context.Context, block int64) ([]OperationGroup, error) {
+	return poolCall(ctx, p, true, func(node INode) ([]OperationGroup, error) {
+		return node.GetOPG(ctx, block)
+	})
 }
 
 // GetLightOPG -
-func (p Pool) GetLightOPG(ctx context.Context, block int64) ([]LightOperationGroup, error) {
-	data, err := p.call("GetLightOPG", ctx, block)
-	if err != nil {
-		return nil, err
-	}
-	return data.Interface().([]LightOperationGroup), nil
+func (p *Pool) GetLightOPG(ctx context.Context, block int64) ([]LightOperationGroup, error) {
+	return poolCall(ctx, p, true, func(node INode) ([]LightOperationGroup, error) {
+		return node.GetLightOPG(ctx, block)
+	})
 }
 
 // GetContractsByBlock -
-func (p Pool) GetContractsByBlock(ctx context.Context, block int64) ([]string, error) {
-	data, err := p.call("GetContractsByBlock", ctx, block)
-	if err != nil {
-		return nil, err
-	}
-	return data.Interface().([]string), nil
+func (p *Pool) GetContractsByBlock(ctx context.Context, block int64) ([]string, error) {
+	return poolCall(ctx, p, true, func(node INode) ([]string, error) {
+		return node.GetContractsByBlock(ctx, block)
+	})
 }
 
 // GetNetworkConstants -
-func (p Pool) GetNetworkConstants(ctx context.Context, level int64) (res Constants, err error) {
-	data, err := p.call("GetNetworkConstants", ctx, level)
-	if err != nil {
-		return res, err
-	}
-	return data.Interface().(Constants), nil
+func (p *Pool) GetNetworkConstants(ctx context.Context, level int64) (Constants, error) {
+	return poolCall(ctx, p, true, func(node INode) (Constants, error) {
+		return node.GetNetworkConstants(ctx, level)
+	})
 }
 
 // RunCode -
-func (p Pool) RunCode(ctx context.Context, script, storage, input []byte, chainID, source, payer, entrypoint, proto string, amount, gas int64) (RunCodeResponse, error) {
-	data, err := p.call("RunCode", ctx, script, storage, input, chainID, source, payer, entrypoint, proto, amount, gas)
-	if err != nil {
-		return RunCodeResponse{}, err
-	}
-	return data.Interface().(RunCodeResponse), nil
+func (p *Pool) RunCode(ctx context.Context, script, storage, input []byte, chainID, source, payer, entrypoint, proto string, amount, gas int64) (RunCodeResponse, error) {
+	return poolCall(ctx, p, false, func(node INode) (RunCodeResponse, error) {
+		return node.RunCode(ctx, script, storage, input, chainID, source, payer, entrypoint, proto, amount, gas)
+	})
 }
 
 // RunOperation -
-func (p Pool) RunOperation(ctx context.Context, chainID, branch, source, destination string, fee, gasLimit, storageLimit, counter, amount int64, parameters []byte) (OperationGroup, error) {
-	data, err := p.call("RunOperation", ctx, chainID, branch, source, destination, fee, gasLimit, storageLimit, counter, amount, parameters)
-	if err != nil {
-		return OperationGroup{}, err
-	}
-	return data.Interface().(OperationGroup), nil
+func (p *Pool) RunOperation(ctx context.Context, chainID, branch, source, destination string, fee, gasLimit, storageLimit, counter, amount int64, parameters []byte) (OperationGroup, error) {
+	return poolCall(ctx, p, false, func(node INode) (OperationGroup, error) {
+		return node.RunOperation(ctx, chainID, branch, source, destination, fee, gasLimit, storageLimit, counter, amount, parameters)
+	})
 }
 
 // RunOperationLight -
-func (p Pool) RunOperationLight(ctx context.Context, chainID, branch, source, destination string, fee, gasLimit, storageLimit, counter, amount int64, parameters []byte) (LightOperationGroup, error) {
-	data, err := p.call("RunOperationLight", ctx, chainID, branch, source, destination, fee, gasLimit, storageLimit, counter, amount, parameters)
-	if err != nil {
-		return LightOperationGroup{}, err
-	}
-	return data.Interface().(LightOperationGroup), nil
+func (p *Pool) RunOperationLight(ctx context.Context, chainID, branch, source, destination string, fee, gasLimit, storageLimit, counter, amount int64, parameters []byte) (LightOperationGroup, error) {
+	return poolCall(ctx, p, false, func(node INode) (LightOperationGroup, error) {
+		return node.RunOperationLight(ctx, chainID, branch, source, destination, fee, gasLimit, storageLimit, counter, amount, parameters)
+	})
+}
+
+// RunOperations -
+func (p *Pool) RunOperations(ctx context.Context, chainID, branch string, contents []RunOperationContent) (LightOperationGroup, error) {
+	return poolCall(ctx, p, false, func(node INode) (LightOperationGroup, error) {
+		return node.RunOperations(ctx, chainID, branch, contents)
+	})
 }
 
 // GetCounter -
-func (p Pool) GetCounter(ctx context.Context, address string) (int64, error) {
-	data, err := p.call("GetCounter", ctx, address)
-	if err != nil {
-		return 0, err
-	}
-	return data.Int(), nil
+func (p *Pool) GetCounter(ctx context.Context, address string) (int64, error) {
+	return poolCall(ctx, p, true, func(node INode) (int64, error) {
+		return node.GetCounter(ctx, address)
+	})
+}
+
+// GetManagerKey -
+func (p *Pool) GetManagerKey(ctx context.Context, address string) (string, error) {
+	return poolCall(ctx, p, true, func(node INode) (string, error) {
+		return node.GetManagerKey(ctx, address)
+	})
 }
 
 // GetBigMapType -
-func (p Pool) GetBigMapType(ctx context.Context, ptr, level int64) (BigMap, error) {
-	data, err := p.call("GetBigMapType", ctx, ptr, level)
-	if err != nil {
-		return BigMap{}, err
-	}
-	return data.Interface().(BigMap), nil
+func (p *Pool) GetBigMapType(ctx context.Context, ptr, level int64) (BigMap, error) {
+	return poolCall(ctx, p, true, func(node INode) (BigMap, error) {
+		return node.GetBigMapType(ctx, ptr, level)
+	})
+}
+
+// GetBigMapValue -
+func (p *Pool) GetBigMapValue(ctx context.Context, ptr int64, keyHash string, level int64) ([]byte, error) {
+	return poolCall(ctx, p, true, func(node INode) ([]byte, error) {
+		return node.GetBigMapValue(ctx, ptr, keyHash, level)
+	})
 }
 
 // GetBlockMetadata -
-func (p Pool) GetBlockMetadata(ctx context.Context, level int64) (Metadata, error) {
-	data, err := p.call("GetBlockMetadata", ctx, level)
-	if err != nil {
-		return Metadata{}, err
-	}
-	return data.Interface().(Metadata), nil
+func (p *Pool) GetBlockMetadata(ctx context.Context, level int64) (Metadata, error) {
+	return poolCall(ctx, p, true, func(node INode) (Metadata, error) {
+		return node.GetBlockMetadata(ctx, level)
+	})
 }
 
 // GetRawScript -
-func (p Pool) GetRawScript(ctx context.Context, address string, level int64) ([]byte, error) {
-	data, err := p.call("GetRawScript", ctx, address, level)
-	if err != nil {
-		return nil, err
-	}
-	return data.Interface().([]byte), nil
+func (p *Pool) GetRawScript(ctx context.Context, address string, level int64) ([]byte, error) {
+	return poolCall(ctx, p, true, func(node INode) ([]byte, error) {
+		return node.GetRawScript(ctx, address, level)
+	})
 }
 
 // RunScriptView -
-func (p Pool) RunScriptView(ctx context.Context, request RunScriptViewRequest) ([]byte, error) {
-	data, err := p.call("RunScriptView", ctx, request)
-	if err != nil {
-		return nil, err
-	}
-	return data.Interface().([]byte), nil
+func (p *Pool) RunScriptView(ctx context.Context, request RunScriptViewRequest) ([]byte, error) {
+	return poolCall(ctx, p, false, func(node INode) ([]byte, error) {
+		return node.RunScriptView(ctx, request)
+	})
 }
 
 // GetStorage -
-func (p Pool) GetStorage(ctx context.Context, level int64, address string) ([]byte, error) {
-	data, err := p.call("GetStorage", ctx, address, level)
-	if err != nil {
-		return nil, err
-	}
-	return data.Interface().([]byte), nil
+func (p *Pool) GetStorage(ctx context.Context, level int64, address string) ([]byte, error) {
+	return poolCall(ctx, p, true, func(node INode) ([]byte, error) {
+		return node.GetStorage(ctx, level, address)
+	})
+}
+
+// GetTicketBalances -
+func (p *Pool) GetTicketBalances(ctx context.Context, address string, level int64) ([]TicketBalance, error) {
+	return poolCall(ctx, p, true, func(node INode) ([]TicketBalance, error) {
+		return node.GetTicketBalances(ctx, address, level)
+	})
 }
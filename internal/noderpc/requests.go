@@ -21,21 +21,31 @@ type runOperationRequest struct {
 }
 
 type runOperationItem struct {
-	Branch    string                    `json:"branch"`
-	Signature string                    `json:"signature"`
-	Contents  []runOperationItemContent `json:"contents"`
+	Branch    string                `json:"branch"`
+	Signature string                `json:"signature"`
+	Contents  []RunOperationContent `json:"contents"`
 }
 
-type runOperationItemContent struct {
+// RunOperationContent - content of simulated operation group. Only fields of its kind should be set.
+type RunOperationContent struct {
 	Kind         string             `json:"kind"`
+	Source       string             `json:"source"`
 	Fee          int64              `json:"fee,string"`
 	Counter      int64              `json:"counter,string"`
 	GasLimit     int64              `json:"gas_limit,string"`
 	StorageLimit int64              `json:"storage_limit,string"`
-	Source       string             `json:"source"`
-	Destination  string             `json:"destination"`
-	Amount       int64              `json:"amount,string"`
-	Parameters   stdJSON.RawMessage `json:"parameters"`
+	Destination  string             `json:"destination,omitempty"`
+	Amount       *int64             `json:"amount,omitempty,string"`
+	Balance      *int64             `json:"balance,omitempty,string"`
+	Delegate     string             `json:"delegate,omitempty"`
+	Parameters   stdJSON.RawMessage `json:"parameters,omitempty"`
+	Script       stdJSON.RawMessage `json:"script,omitempty"`
+
+	TicketContents stdJSON.RawMessage `json:"ticket_contents,omitempty"`
+	TicketType     stdJSON.RawMessage `json:"ticket_ty,omitempty"`
+	TicketTicketer string             `json:"ticket_ticketer,omitempty"`
+	TicketAmount   string             `json:"ticket_amount,omitempty"`
+	Entrypoint     string             `json:"entrypoint,omitempty"`
 }
 
 // RunScriptViewRequest -
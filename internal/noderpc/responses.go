@@ -43,8 +43,10 @@ type Constants struct {
 	CostPerByte                  int64            `json:"cost_per_byte,string"`
 	HardGasLimitPerOperation     int64            `json:"hard_gas_limit_per_operation,string"`
 	HardStorageLimitPerOperation int64            `json:"hard_storage_limit_per_operation,string"`
+	HardGasLimitPerBlock         int64            `json:"hard_gas_limit_per_block,string"`
 	TimeBetweenBlocks            Int64StringSlice `json:"time_between_blocks"`
 	MinimalBlockDelay            *int64           `json:"minimal_block_delay,omitempty,string"`
+	OriginationSize              int64            `json:"origination_size"`
 }
 
 // BlockDelay -
@@ -115,6 +117,12 @@ type Operation struct {
 	Kernel             string             `json:"kernel,omitempty"`
 	CementedCommitment string             `json:"cemented_commitment,omitempty"`
 	OutputProof        string             `json:"output_proof,omitempty"`
+	Opponent           string             `json:"opponent,omitempty"`
+	Staker             string             `json:"staker,omitempty"`
+	Message            []string           `json:"message,omitempty"`
+	Commitment         stdJSON.RawMessage `json:"commitment,omitempty"`
+	Refutation         *Refutation        `json:"refutation,omitempty"`
+	Stakers            *Stakers           `json:"stakers,omitempty"`
 	Parameters         stdJSON.RawMessage `json:"parameters,omitempty"`
 	Metadata           *OperationMetadata `json:"metadata,omitempty"`
 	Result             *OperationResult   `json:"result,omitempty"`
@@ -211,6 +219,62 @@ type OperationResult struct {
 	Address                      string             `json:"address,omitempty"`
 	GenesisCommitmentHash        string             `json:"genesis_commitment_hash,omitempty"`
 	Size                         string             `json:"size,omitempty"`
+	StakedHash                   string             `json:"staked_hash,omitempty"`
+	PublishedAtLevel             *int64             `json:"published_at_level,omitempty"`
+	CommitmentHash               string             `json:"commitment_hash,omitempty"`
+	InboxLevel                   *int64             `json:"inbox_level,omitempty"`
+	GameStatus                   *GameStatus        `json:"game_status,omitempty"`
+}
+
+// SmartRollupCommitment - commitment of `smart_rollup_publish`
+type SmartRollupCommitment struct {
+	CompressedState string `json:"compressed_state"`
+	InboxLevel      int64  `json:"inbox_level"`
+	Predecessor     string `json:"predecessor"`
+	NumberOfTicks   string `json:"number_of_ticks"`
+}
+
+// Refutation - refutation of `smart_rollup_refute`
+type Refutation struct {
+	Kind                   string `json:"refutation_kind"`
+	PlayerCommitmentHash   string `json:"player_commitment_hash,omitempty"`
+	OpponentCommitmentHash string `json:"opponent_commitment_hash,omitempty"`
+	Choice                 string `json:"choice,omitempty"`
+}
+
+// Stakers - stakers of `smart_rollup_timeout`
+type Stakers struct {
+	Alice string `json:"alice"`
+	Bob   string `json:"bob"`
+}
+
+// GameStatus - status of refutation game. Node returns `"ongoing"` or object with result of ended game.
+type GameStatus struct {
+	Status string
+	Result *GameResult
+}
+
+// GameResult -
+type GameResult struct {
+	Kind   string `json:"kind"`
+	Reason string `json:"reason,omitempty"`
+	Player string `json:"player,omitempty"`
+}
+
+// UnmarshalJSON -
+func (gs *GameStatus) UnmarshalJSON(data []byte) error {
+	if len(data) > 0 && data[0] == '"' {
+		return json.Unmarshal(data, &gs.Status)
+	}
+	var ended struct {
+		Result GameResult `json:"result"`
+	}
+	if err := json.Unmarshal(data, &ended); err != nil {
+		return err
+	}
+	gs.Status = "ended"
+	gs.Result = &ended.Result
+	return nil
 }
 
 // LazyStorageDiff -
@@ -324,6 +388,14 @@ type RunCodeResponse struct {
 	Operations       []Operation        `json:"operations"`
 	Storage          stdJSON.RawMessage `json:"storage"`
 	LazyStorageDiffs []LazyStorageDiff  `json:"lazy_storage_diff,omitempty"`
+	Trace            []TraceStep        `json:"trace,omitempty"`
+}
+
+// TraceStep - state of stack after execution of instruction. Stack is ordered from top to bottom.
+type TraceStep struct {
+	Location    int64                `json:"location"`
+	Instruction string               `json:"instruction,omitempty"`
+	Stack       []stdJSON.RawMessage `json:"stack"`
 }
 
 // RunCodeError -
@@ -350,6 +422,14 @@ type BigMap struct {
 	TotalBytes uint64        `json:"total_bytes,string"`
 }
 
+// TicketBalance - balance of ticket owned by contract
+type TicketBalance struct {
+	Ticketer    string             `json:"ticketer"`
+	ContentType stdJSON.RawMessage `json:"content_type"`
+	Content     stdJSON.RawMessage `json:"content"`
+	Amount      string             `json:"amount"`
+}
+
 // Metadata -
 type Metadata struct {
 	Protocol        string `json:"protocol"`
@@ -12,6 +12,7 @@ import (
 	"time"
 
 	"github.com/baking-bad/bcdhub/internal/helpers"
+	"github.com/baking-bad/bcdhub/internal/metrics"
 	jsoniter "github.com/json-iterator/go"
 	"github.com/pkg/errors"
 	"github.com/rs/zerolog/log"
@@ -41,6 +42,7 @@ type NodeRPC struct {
 	userAgent string
 	rateLimit *rate.Limiter
 	needLog   bool
+	network   string
 }
 
 // NewNodeRPC -
@@ -91,7 +93,7 @@ func (rpc *NodeRPC) checkStatusCode(r io.Reader, statusCode int, checkStatusCode
 	case statusCode == http.StatusOK:
 		return nil
 	case statusCode == http.StatusNotFound:
-		return errors.Errorf("%s: not found", uri)
+		return fmt.Errorf("%s: %w", uri, ErrNotFound)
 	case statusCode > http.StatusInternalServerError:
 		return NewNodeUnavailiableError(rpc.baseURL, statusCode)
 	case checkStatusCode:
@@ -152,7 +154,7 @@ func (rpc *NodeRPC) makePostRequest(ctx context.Context, uri string, data interf
 	return rpc.makeRequest(req)
 }
 
-func (rpc *NodeRPC) get(ctx context.Context, uri string, response interface{}) error {
+func (rpc *NodeRPC) get(ctx context.Context, method, uri string, response interface{}) (err error) {
 	if rpc.rateLimit != nil {
 		if err := rpc.rateLimit.Wait(ctx); err != nil {
 			return err
@@ -161,6 +163,7 @@ func (rpc *NodeRPC) get(ctx context.Context, uri string, response interface{}) e
 
 	start := time.Now()
 	defer func() {
+		rpc.observe(method, start, err)
 		if rpc.needLog {
 			log.Info().Str("method", "get").Int64("ms", time.Since(start).Milliseconds()).Msg(uri)
 		}
@@ -175,7 +178,7 @@ func (rpc *NodeRPC) get(ctx context.Context, uri string, response interface{}) e
 	return rpc.parseResponse(resp.Body, resp.StatusCode, true, resp.Request.URL.String(), response)
 }
 
-func (rpc *NodeRPC) getRaw(ctx context.Context, uri string) ([]byte, error) {
+func (rpc *NodeRPC) getRaw(ctx context.Context, method, uri string) (data []byte, err error) {
 	if rpc.rateLimit != nil {
 		if err := rpc.rateLimit.Wait(ctx); err != nil {
 			return nil, err
@@ -184,6 +187,7 @@ func (rpc *NodeRPC) getRaw(ctx context.Context, uri string) ([]byte, error) {
 
 	start := time.Now()
 	defer func() {
+		rpc.observe(method, start, err)
 		if rpc.needLog {
 			log.Info().Str("method", "get").Int64("ms", time.Since(start).Milliseconds()).Msg(uri)
 		}
@@ -201,7 +205,7 @@ func (rpc *NodeRPC) getRaw(ctx context.Context, uri string) ([]byte, error) {
 	return io.ReadAll(resp.Body)
 }
 
-func (rpc *NodeRPC) post(ctx context.Context, uri string, data interface{}, checkStatusCode bool, response interface{}) error {
+func (rpc *NodeRPC) post(ctx context.Context, method, uri string, data interface{}, checkStatusCode bool, response interface{}) (err error) {
 	if rpc.rateLimit != nil {
 		if err := rpc.rateLimit.Wait(ctx); err != nil {
 			return err
@@ -210,6 +214,7 @@ func (rpc *NodeRPC) post(ctx context.Context, uri string, data interface{}, chec
 
 	start := time.Now()
 	defer func() {
+		rpc.observe(method, start, err)
 		if rpc.needLog {
 			log.Info().Str("method", "post").Int64("ms", time.Since(start).Milliseconds()).Msg(uri)
 		}
@@ -224,6 +229,13 @@ func (rpc *NodeRPC) post(ctx context.Context, uri string, data interface{}, chec
 	return rpc.parseResponse(resp.Body, resp.StatusCode, checkStatusCode, resp.Request.URL.String(), response)
 }
 
+func (rpc *NodeRPC) observe(method string, start time.Time, err error) {
+	metrics.RPCRequestDuration.WithLabelValues(rpc.network, method).Observe(time.Since(start).Seconds())
+	if err != nil && !errors.Is(err, context.Canceled) {
+		metrics.RPCErrors.WithLabelValues(rpc.network, method).Inc()
+	}
+}
+
 func closeWithLogError(stream io.ReadCloser) {
 	if _, err := io.Copy(io.Discard, stream); err != nil {
 		log.Err(err).Msg("noderpc: drain response body")
@@ -235,13 +247,13 @@ func closeWithLogError(stream io.ReadCloser) {
 
 // Block - returns block
 func (rpc *NodeRPC) Block(ctx context.Context, level int64) (block Block, err error) {
-	err = rpc.get(ctx, fmt.Sprintf("chains/main/blocks/%s", getBlockString(level)), &block)
+	err = rpc.get(ctx, "Block", fmt.Sprintf("chains/main/blocks/%s", getBlockString(level)), &block)
 	return
 }
 
 // BlockHash - returns block's hash, its unique identifier.
 func (rpc *NodeRPC) BlockHash(ctx context.Context, level int64) (hash string, err error) {
-	err = rpc.get(ctx, fmt.Sprintf("chains/main/blocks/%s/hash", getBlockString(level)), &hash)
+	err = rpc.get(ctx, "BlockHash", fmt.Sprintf("chains/main/blocks/%s/hash", getBlockString(level)), &hash)
 	return
 }
 
@@ -255,7 +267,7 @@ func (rpc *NodeRPC) GetLevel(ctx context.Context) (int64, error) {
 	var head struct {
 		Level int64 `json:"level"`
 	}
-	if err := rpc.get(ctx, "chains/main/blocks/head/helpers/current_level", &head); err != nil {
+	if err := rpc.get(ctx, "GetLevel", "chains/main/blocks/head/helpers/current_level", &head); err != nil {
 		return 0, err
 	}
 	return head.Level, nil
@@ -263,19 +275,19 @@ func (rpc *NodeRPC) GetLevel(ctx context.Context) (int64, error) {
 
 // GetHeader - get head for certain level
 func (rpc *NodeRPC) GetHeader(ctx context.Context, level int64) (header Header, err error) {
-	err = rpc.get(ctx, fmt.Sprintf("chains/main/blocks/%s/header", getBlockString(level)), &header)
+	err = rpc.get(ctx, "GetHeader", fmt.Sprintf("chains/main/blocks/%s/header", getBlockString(level)), &header)
 	return
 }
 
 // GetScriptJSON -
 func (rpc *NodeRPC) GetScriptJSON(ctx context.Context, address string, level int64) (script Script, err error) {
-	err = rpc.get(ctx, fmt.Sprintf("chains/main/blocks/%s/context/contracts/%s/script", getBlockString(level), address), &script)
+	err = rpc.get(ctx, "GetScriptJSON", fmt.Sprintf("chains/main/blocks/%s/context/contracts/%s/script", getBlockString(level), address), &script)
 	return
 }
 
 // GetRawScript -
 func (rpc *NodeRPC) GetRawScript(ctx context.Context, address string, level int64) ([]byte, error) {
-	return rpc.getRaw(ctx, fmt.Sprintf("chains/main/blocks/%s/context/contracts/%s/script", getBlockString(level), address))
+	return rpc.getRaw(ctx, "GetRawScript", fmt.Sprintf("chains/main/blocks/%s/context/contracts/%s/script", getBlockString(level), address))
 }
 
 // GetScriptStorageRaw -
@@ -283,14 +295,14 @@ func (rpc *NodeRPC) GetScriptStorageRaw(ctx context.Context, address string, lev
 	var response struct {
 		Storage stdJSON.RawMessage `json:"storage"`
 	}
-	err := rpc.get(ctx, fmt.Sprintf("chains/main/blocks/%s/context/contracts/%s/script", getBlockString(level), address), &response)
+	err := rpc.get(ctx, "GetScriptStorageRaw", fmt.Sprintf("chains/main/blocks/%s/context/contracts/%s/script", getBlockString(level), address), &response)
 	return response.Storage, err
 }
 
 // GetContractBalance -
 func (rpc *NodeRPC) GetContractBalance(ctx context.Context, address string, level int64) (int64, error) {
 	var balanceStr string
-	if err := rpc.get(ctx, fmt.Sprintf("chains/main/blocks/%s/context/contracts/%s/balance", getBlockString(level), address), &balanceStr); err != nil {
+	if err := rpc.get(ctx, "GetContractBalance", fmt.Sprintf("chains/main/blocks/%s/context/contracts/%s/balance", getBlockString(level), address), &balanceStr); err != nil {
 		return 0, err
 	}
 	return strconv.ParseInt(balanceStr, 10, 64)
@@ -299,19 +311,19 @@ func (rpc *NodeRPC) GetContractBalance(ctx context.Context, address string, leve
 // GetContractData -
 func (rpc *NodeRPC) GetContractData(ctx context.Context, address string, level int64) (ContractData, error) {
 	var response ContractData
-	err := rpc.get(ctx, fmt.Sprintf("chains/main/blocks/%s/context/contracts/%s", getBlockString(level), address), &response)
+	err := rpc.get(ctx, "GetContractData", fmt.Sprintf("chains/main/blocks/%s/context/contracts/%s", getBlockString(level), address), &response)
 	return response, err
 }
 
 // GetOPG -
 func (rpc *NodeRPC) GetOPG(ctx context.Context, block int64) (group []OperationGroup, err error) {
-	err = rpc.get(ctx, fmt.Sprintf("chains/main/blocks/%s/operations/3", getBlockString(block)), &group)
+	err = rpc.get(ctx, "GetOPG", fmt.Sprintf("chains/main/blocks/%s/operations/3", getBlockString(block)), &group)
 	return
 }
 
 // GetLightOPG -
 func (rpc *NodeRPC) GetLightOPG(ctx context.Context, block int64) (group []LightOperationGroup, err error) {
-	err = rpc.get(ctx, fmt.Sprintf("chains/main/blocks/%s/operations/3", getBlockString(block)), &group)
+	err = rpc.get(ctx, "GetLightOPG", fmt.Sprintf("chains/main/blocks/%s/operations/3", getBlockString(block)), &group)
 	return
 }
 
@@ -321,7 +333,7 @@ func (rpc *NodeRPC) GetContractsByBlock(ctx context.Context, block int64) ([]str
 		return nil, errors.Errorf("For less loading node RPC `block` value is only 1")
 	}
 	contracts := make([]string, 0)
-	if err := rpc.get(ctx, fmt.Sprintf("chains/main/blocks/%d/context/contracts", block), &contracts); err != nil {
+	if err := rpc.get(ctx, "GetContractsByBlock", fmt.Sprintf("chains/main/blocks/%d/context/contracts", block), &contracts); err != nil {
 		return nil, err
 	}
 	return contracts, nil
@@ -329,7 +341,7 @@ func (rpc *NodeRPC) GetContractsByBlock(ctx context.Context, block int64) ([]str
 
 // GetNetworkConstants -
 func (rpc *NodeRPC) GetNetworkConstants(ctx context.Context, level int64) (constants Constants, err error) {
-	err = rpc.get(ctx, fmt.Sprintf("chains/main/blocks/%s/context/constants", getBlockString(level)), &constants)
+	err = rpc.get(ctx, "GetNetworkConstants", fmt.Sprintf("chains/main/blocks/%s/context/constants", getBlockString(level)), &constants)
 	return
 }
 
@@ -359,89 +371,117 @@ func (rpc *NodeRPC) RunCode(ctx context.Context, script, storage, input []byte,
 		request.Entrypoint = entrypoint
 	}
 
-	err = rpc.post(ctx, "chains/main/blocks/head/helpers/scripts/run_code", request, true, &response)
+	err = rpc.post(ctx, "RunCode", "chains/main/blocks/head/helpers/scripts/run_code", request, true, &response)
 	return
 }
 
 // RunOperation -
 func (rpc *NodeRPC) RunOperation(ctx context.Context, chainID, branch, source, destination string, fee, gasLimit, storageLimit, counter, amount int64, parameters []byte) (group OperationGroup, err error) {
-	request := runOperationRequest{
-		ChainID: chainID,
-		Operation: runOperationItem{
-			Branch:    branch,
-			Signature: "sigUHx32f9wesZ1n2BWpixXz4AQaZggEtchaQNHYGRCoWNAXx45WGW2ua3apUUUAGMLPwAU41QoaFCzVSL61VaessLg4YbbP", // base58_encode(b'0' * 64, b'sig').decode()
-			Contents: []runOperationItemContent{
-				{
-					Kind:         "transaction",
-					Fee:          fee,
-					Counter:      counter,
-					GasLimit:     gasLimit,
-					StorageLimit: storageLimit,
-					Source:       source,
-					Destination:  destination,
-					Amount:       amount,
-					Parameters:   parameters,
-				},
-			},
+	request := newRunOperationRequest(chainID, branch, []RunOperationContent{
+		{
+			Kind:         "transaction",
+			Fee:          fee,
+			Counter:      counter,
+			GasLimit:     gasLimit,
+			StorageLimit: storageLimit,
+			Source:       source,
+			Destination:  destination,
+			Amount:       &amount,
+			Parameters:   parameters,
 		},
-	}
+	})
 
-	err = rpc.post(ctx, "chains/main/blocks/head/helpers/scripts/run_operation", request, true, &group)
+	err = rpc.post(ctx, "RunOperation", "chains/main/blocks/head/helpers/scripts/run_operation", request, true, &group)
 	return
 }
 
 // RunOperationLight -
 func (rpc *NodeRPC) RunOperationLight(ctx context.Context, chainID, branch, source, destination string, fee, gasLimit, storageLimit, counter, amount int64, parameters []byte) (group LightOperationGroup, err error) {
-	request := runOperationRequest{
+	request := newRunOperationRequest(chainID, branch, []RunOperationContent{
+		{
+			Kind:         "transaction",
+			Fee:          fee,
+			Counter:      counter,
+			GasLimit:     gasLimit,
+			StorageLimit: storageLimit,
+			Source:       source,
+			Destination:  destination,
+			Amount:       &amount,
+			Parameters:   parameters,
+		},
+	})
+
+	err = rpc.post(ctx, "RunOperationLight", "chains/main/blocks/head/helpers/scripts/run_operation", request, true, &group)
+	return
+}
+
+// RunOperations - simulates operation group with several contents. Contents are applied in passed order, so their counters should be sequential.
+func (rpc *NodeRPC) RunOperations(ctx context.Context, chainID, branch string, contents []RunOperationContent) (group LightOperationGroup, err error) {
+	if len(contents) == 0 {
+		return group, errors.New("empty operation contents")
+	}
+	request := newRunOperationRequest(chainID, branch, contents)
+	err = rpc.post(ctx, "RunOperations", "chains/main/blocks/head/helpers/scripts/run_operation", request, true, &group)
+	return
+}
+
+func newRunOperationRequest(chainID, branch string, contents []RunOperationContent) runOperationRequest {
+	return runOperationRequest{
 		ChainID: chainID,
 		Operation: runOperationItem{
 			Branch:    branch,
 			Signature: "sigUHx32f9wesZ1n2BWpixXz4AQaZggEtchaQNHYGRCoWNAXx45WGW2ua3apUUUAGMLPwAU41QoaFCzVSL61VaessLg4YbbP", // base58_encode(b'0' * 64, b'sig').decode()
-			Contents: []runOperationItemContent{
-				{
-					Kind:         "transaction",
-					Fee:          fee,
-					Counter:      counter,
-					GasLimit:     gasLimit,
-					StorageLimit: storageLimit,
-					Source:       source,
-					Destination:  destination,
-					Amount:       amount,
-					Parameters:   parameters,
-				},
-			},
+			Contents:  contents,
 		},
 	}
-
-	err = rpc.post(ctx, "chains/main/blocks/head/helpers/scripts/run_operation", request, true, &group)
-	return
 }
 
 // RunScriptView -
 func (rpc *NodeRPC) RunScriptView(ctx context.Context, request RunScriptViewRequest) ([]byte, error) {
 	var response RunScriptViewResponse
-	err := rpc.post(ctx, "chains/main/blocks/head/helpers/scripts/run_script_view", request, true, &response)
+	err := rpc.post(ctx, "RunScriptView", "chains/main/blocks/head/helpers/scripts/run_script_view", request, true, &response)
 	return response.Data, err
 }
 
 // GetCounter -
 func (rpc *NodeRPC) GetCounter(ctx context.Context, address string) (int64, error) {
 	var counter string
-	if err := rpc.get(ctx, fmt.Sprintf("chains/main/blocks/head/context/contracts/%s/counter", address), &counter); err != nil {
+	if err := rpc.get(ctx, "GetCounter", fmt.Sprintf("chains/main/blocks/head/context/contracts/%s/counter", address), &counter); err != nil {
 		return 0, err
 	}
 	return strconv.ParseInt(counter, 10, 64)
 }
 
+// GetManagerKey - returns public key of implicit account. Empty string is returned if the key isn't revealed.
+func (rpc *NodeRPC) GetManagerKey(ctx context.Context, address string) (string, error) {
+	var key *string
+	if err := rpc.get(ctx, "GetManagerKey", fmt.Sprintf("chains/main/blocks/head/context/contracts/%s/manager_key", address), &key); err != nil {
+		return "", err
+	}
+	if key == nil {
+		return "", nil
+	}
+	return *key, nil
+}
+
 // GetBigMapType -
 func (rpc *NodeRPC) GetBigMapType(ctx context.Context, ptr, level int64) (bm BigMap, err error) {
-	err = rpc.get(ctx, fmt.Sprintf("chains/main/blocks/%s/context/raw/json/big_maps/index/%d", getBlockString(level), ptr), &bm)
+	err = rpc.get(ctx, "GetBigMapType", fmt.Sprintf("chains/main/blocks/%s/context/raw/json/big_maps/index/%d", getBlockString(level), ptr), &bm)
 	return
 }
 
+// GetBigMapValue - returns Micheline value of big map key by script expression hash of the key
+func (rpc *NodeRPC) GetBigMapValue(ctx context.Context, ptr int64, keyHash string, level int64) ([]byte, error) {
+	return rpc.getRaw(
+		ctx,
+		"GetBigMapValue",
+		fmt.Sprintf("chains/main/blocks/%s/context/big_maps/%d/%s", getBlockString(level), ptr, keyHash),
+	)
+}
+
 // GetBlockMetadata -
 func (rpc *NodeRPC) GetBlockMetadata(ctx context.Context, level int64) (metadata Metadata, err error) {
-	err = rpc.get(ctx, fmt.Sprintf("chains/main/blocks/%s/metadata", getBlockString(level)), &metadata)
+	err = rpc.get(ctx, "GetBlockMetadata", fmt.Sprintf("chains/main/blocks/%s/metadata", getBlockString(level)), &metadata)
 	return
 }
 
@@ -449,7 +489,14 @@ func (rpc *NodeRPC) GetBlockMetadata(ctx context.Context, level int64) (metadata
 func (rpc *NodeRPC) GetStorage(ctx context.Context, level int64, address string) (response []byte, err error) {
 	response, err = rpc.getRaw(
 		ctx,
+		"GetStorage",
 		fmt.Sprintf("chains/main/blocks/%s/context/contracts/%s/storage", getBlockString(level), address),
 	)
 	return
 }
+
+// GetTicketBalances - returns all non-zero ticket balances of contract
+func (rpc *NodeRPC) GetTicketBalances(ctx context.Context, address string, level int64) (balances []TicketBalance, err error) {
+	err = rpc.get(ctx, "GetTicketBalances", fmt.Sprintf("chains/main/blocks/%s/context/contracts/%s/all_ticket_balances", getBlockString(level), address), &balances)
+	return
+}
@@ -4,9 +4,14 @@ import (
 	"context"
 	stdJSON "encoding/json"
 	"io"
+	"net/http"
+	"net/http/httptest"
 	"strings"
 	"testing"
 
+	"github.com/baking-bad/bcdhub/internal/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
 	"github.com/stretchr/testify/require"
 )
 
@@ -81,3 +86,84 @@ func TestNodeRPC_makePostRequest_invalidRawMessage(t *testing.T) {
 	require.Error(t, err)
 	require.Contains(t, err.Error(), "json.Marshal")
 }
+
+func TestNodeRPC_RunOperations(t *testing.T) {
+	var body runOperationRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/chains/main/blocks/head/helpers/scripts/run_operation", r.URL.Path)
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		_, _ = w.Write([]byte(`{"contents":[{"kind":"transaction","source":"tz1burnburnburnburnburnburnburjAYjjX"},{"kind":"origination","source":"tz1burnburnburnburnburnburnburjAYjjX"}]}`))
+	}))
+	defer server.Close()
+
+	var (
+		amount  int64 = 0
+		balance int64 = 100
+	)
+	rpc := NewNodeRPC(server.URL)
+	group, err := rpc.RunOperations(context.Background(), "NetXdQprcVkpaWU", "BLockGenesisGenesisGenesisGenesisGenesisf79b5d1CoW2", []RunOperationContent{
+		{
+			Kind:        "transaction",
+			Source:      "tz1burnburnburnburnburnburnburjAYjjX",
+			Counter:     1,
+			Destination: "KT1FSSJtvHsRL3orgtj8mBi4EoaKJyStG2FF",
+			Amount:      &amount,
+			Parameters:  stdJSON.RawMessage(`{"entrypoint":"default","value":{"prim":"Unit"}}`),
+		}, {
+			Kind:    "origination",
+			Source:  "tz1burnburnburnburnburnburnburjAYjjX",
+			Counter: 2,
+			Balance: &balance,
+			Script:  stdJSON.RawMessage(`{"code":[],"storage":{"prim":"Unit"}}`),
+		},
+	})
+	require.NoError(t, err)
+	require.Len(t, group.Contents, 2)
+
+	require.Len(t, body.Operation.Contents, 2)
+	require.EqualValues(t, 0, *body.Operation.Contents[0].Amount)
+	require.Nil(t, body.Operation.Contents[0].Balance)
+	require.EqualValues(t, 2, body.Operation.Contents[1].Counter)
+	require.EqualValues(t, 100, *body.Operation.Contents[1].Balance)
+	require.Nil(t, body.Operation.Contents[1].Amount)
+	require.Empty(t, body.Operation.Contents[1].Destination)
+
+	_, err = rpc.RunOperations(context.Background(), "NetXdQprcVkpaWU", "BLockGenesisGenesisGenesisGenesisGenesisf79b5d1CoW2", nil)
+	require.Error(t, err)
+}
+
+func TestNodeRPC_metrics(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/chains/main/blocks/head/helpers/current_level" {
+			_, _ = w.Write([]byte(`{"level":100}`))
+			return
+		}
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	errorsCount := func(method string) float64 {
+		var m dto.Metric
+		require.NoError(t, metrics.RPCErrors.WithLabelValues("metrics_test", method).Write(&m))
+		return m.GetCounter().GetValue()
+	}
+	requestsCount := func(method string) uint64 {
+		var m dto.Metric
+		observer := metrics.RPCRequestDuration.WithLabelValues("metrics_test", method)
+		require.NoError(t, observer.(prometheus.Metric).Write(&m))
+		return m.GetHistogram().GetSampleCount()
+	}
+
+	rpc := NewNodeRPC(server.URL, WithNetwork("metrics_test"))
+
+	level, err := rpc.GetLevel(context.Background())
+	require.NoError(t, err)
+	require.EqualValues(t, 100, level)
+	require.EqualValues(t, 1, requestsCount("GetLevel"))
+	require.Zero(t, errorsCount("GetLevel"))
+
+	_, err = rpc.GetStorage(context.Background(), 10, "KT1FSSJtvHsRL3orgtj8mBi4EoaKJyStG2FF")
+	require.True(t, IsNodeUnavailiableError(err))
+	require.EqualValues(t, 1, requestsCount("GetStorage"))
+	require.EqualValues(t, 1, errorsCount("GetStorage"))
+}
@@ -0,0 +1,131 @@
+package contractmetadata
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// MaxMetadataSize - limit of metadata document size in bytes
+const MaxMetadataSize = 1024 * 1024
+
+// DefaultIPFSGateway - gateway which is used if no one is set
+const DefaultIPFSGateway = "https://ipfs.io"
+
+// Fetcher - loads metadata documents by off-chain URIs (`https://`, `http://` and `ipfs://`)
+type Fetcher interface {
+	Fetch(ctx context.Context, uri string) ([]byte, error)
+}
+
+// HTTPFetcher - loads documents over HTTP. IPFS documents are requested through gateways in turn until the first success.
+type HTTPFetcher struct {
+	client   *http.Client
+	gateways []string
+}
+
+// NewHTTPFetcher -
+func NewHTTPFetcher(timeout time.Duration, gateways ...string) *HTTPFetcher {
+	if len(gateways) == 0 {
+		gateways = []string{DefaultIPFSGateway}
+	}
+	trimmed := make([]string, len(gateways))
+	for i := range gateways {
+		trimmed[i] = strings.TrimSuffix(gateways[i], "/")
+	}
+	return &HTTPFetcher{
+		client: &http.Client{
+			Timeout: timeout,
+		},
+		gateways: trimmed,
+	}
+}
+
+// Fetch -
+func (f *HTTPFetcher) Fetch(ctx context.Context, uri string) ([]byte, error) {
+	switch Scheme(uri) {
+	case SchemeHTTP, SchemeHTTPS:
+		return f.get(ctx, uri)
+	case SchemeIPFS:
+		path := strings.TrimPrefix(strings.TrimPrefix(uri, SchemeIPFS+"://"), "ipfs/")
+		var err error
+		for _, gateway := range f.gateways {
+			var data []byte
+			data, err = f.get(ctx, gateway+"/ipfs/"+path)
+			if err == nil {
+				return data, nil
+			}
+		}
+		return nil, err
+	default:
+		return nil, errors.Wrap(ErrUnsupportedURI, uri)
+	}
+}
+
+func (f *HTTPFetcher) get(ctx context.Context, link string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, link, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("%s: invalid status code %d", link, resp.StatusCode)
+	}
+	return readLimited(resp.Body)
+}
+
+// DirectoryFetcher - loads documents from local directory instead of network. URI `<scheme>://<path>` is mapped to file `<directory>/<scheme>/<path>`.
+// It's useful for sandbox and tests.
+type DirectoryFetcher struct {
+	directory string
+}
+
+// NewDirectoryFetcher -
+func NewDirectoryFetcher(directory string) *DirectoryFetcher {
+	return &DirectoryFetcher{directory}
+}
+
+// Fetch -
+func (f *DirectoryFetcher) Fetch(_ context.Context, uri string) ([]byte, error) {
+	scheme := Scheme(uri)
+	switch scheme {
+	case SchemeHTTP, SchemeHTTPS, SchemeIPFS:
+	default:
+		return nil, errors.Wrap(ErrUnsupportedURI, uri)
+	}
+
+	path := strings.TrimPrefix(uri[len(scheme)+1:], "//")
+	name := filepath.Join(f.directory, scheme, filepath.FromSlash(path))
+	if !strings.HasPrefix(name, filepath.Clean(f.directory)+string(filepath.Separator)) {
+		return nil, errors.Wrap(ErrInvalidURI, uri)
+	}
+
+	file, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return readLimited(file)
+}
+
+func readLimited(r io.Reader) ([]byte, error) {
+	data, err := io.ReadAll(io.LimitReader(r, MaxMetadataSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > MaxMetadataSize {
+		return nil, errors.Errorf("metadata document is too large: more than %d bytes", MaxMetadataSize)
+	}
+	return data, nil
+}
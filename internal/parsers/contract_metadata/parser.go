@@ -0,0 +1,192 @@
+package contractmetadata
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+
+	"github.com/baking-bad/bcdhub/internal/bcd/ast"
+	"github.com/baking-bad/bcdhub/internal/bcd/base"
+	"github.com/baking-bad/bcdhub/internal/models/bigmapdiff"
+	contractmetadata "github.com/baking-bad/bcdhub/internal/models/contract_metadata"
+	"github.com/baking-bad/bcdhub/internal/noderpc"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/pkg/errors"
+)
+
+var json = jsoniter.ConfigCompatibleWithStandardLibrary
+
+// maxDepth - limit of nested URIs (e.g. `sha256://` wrapping `tezos-storage:`)
+const maxDepth = 3
+
+// MetadataBigMap - annotation of big map which contains TZIP-16 metadata
+const MetadataBigMap = "metadata"
+
+// ErrNoMetadata -
+var ErrNoMetadata = errors.New("contract hasn't metadata")
+
+// Parser - resolves TZIP-16 metadata of contract starting from the empty key of its `%metadata` big map
+type Parser struct {
+	bigMapDiffs bigmapdiff.Repository
+	rpc         noderpc.INode
+	fetcher     Fetcher
+	network     string
+}
+
+// NewParser - `fetcher` may be nil, then only `tezos-storage:` URIs are resolved. `network` is the name of indexed network:
+// `tezos-storage:` URIs pointing to another network can't be resolved.
+func NewParser(bigMapDiffs bigmapdiff.Repository, rpc noderpc.INode, fetcher Fetcher, network string) Parser {
+	return Parser{
+		bigMapDiffs: bigMapDiffs,
+		rpc:         rpc,
+		fetcher:     fetcher,
+		network:     network,
+	}
+}
+
+// Parse - resolves metadata of the contract which stores it in big map `ptr`
+func (p Parser) Parse(ctx context.Context, address string, ptr int64) (*contractmetadata.ContractMetadata, error) {
+	root, err := p.bigMapValue(ctx, ptr, "")
+	if err != nil {
+		return nil, err
+	}
+	uri := string(root)
+
+	data, err := p.resolve(ctx, ptr, uri, 0)
+	if err != nil {
+		return nil, errors.Wrap(err, uri)
+	}
+
+	var metadata contractmetadata.Metadata
+	if err := json.Unmarshal(data, &metadata); err != nil {
+		return nil, errors.Wrap(err, "invalid metadata JSON")
+	}
+
+	return &contractmetadata.ContractMetadata{
+		Address:     address,
+		Ptr:         ptr,
+		URI:         uri,
+		Name:        metadata.Name,
+		Description: metadata.Description,
+		Version:     metadata.Version,
+		Interfaces:  metadata.Interfaces,
+		Metadata:    data,
+	}, nil
+}
+
+func (p Parser) resolve(ctx context.Context, ptr int64, uri string, depth int) ([]byte, error) {
+	if depth >= maxDepth {
+		return nil, errors.Wrap(ErrInvalidURI, "too deep nesting")
+	}
+
+	switch Scheme(uri) {
+	case SchemeTezosStorage:
+		storageURI, err := ParseTezosStorageURI(uri)
+		if err != nil {
+			return nil, err
+		}
+		if err := p.checkNetwork(ctx, storageURI.Network); err != nil {
+			return nil, err
+		}
+		if storageURI.Address != "" {
+			ptr, err = p.metadataPointer(ctx, storageURI.Address)
+			if err != nil {
+				return nil, err
+			}
+		}
+		return p.bigMapValue(ctx, ptr, storageURI.Key)
+
+	case SchemeSha256:
+		sha256URI, err := ParseSha256URI(uri)
+		if err != nil {
+			return nil, err
+		}
+		data, err := p.resolve(ctx, ptr, sha256URI.URI, depth+1)
+		if err != nil {
+			return nil, err
+		}
+		hash := sha256.Sum256(data)
+		if !bytes.Equal(hash[:], sha256URI.Hash) {
+			return nil, errors.Wrapf(ErrHashMismatch, "expected 0x%x got 0x%x", sha256URI.Hash, hash)
+		}
+		return data, nil
+
+	case SchemeHTTP, SchemeHTTPS, SchemeIPFS:
+		if p.fetcher == nil {
+			return nil, errors.Wrap(ErrUnsupportedURI, uri)
+		}
+		return p.fetcher.Fetch(ctx, uri)
+
+	default:
+		return nil, errors.Wrap(ErrUnsupportedURI, uri)
+	}
+}
+
+// checkNetwork - network of `tezos-storage:` URI is set by name (e.g. `mainnet`) or by chain id
+func (p Parser) checkNetwork(ctx context.Context, network string) error {
+	if network == "" || strings.EqualFold(network, p.network) {
+		return nil
+	}
+	if p.rpc != nil {
+		head, err := p.rpc.GetHead(ctx)
+		if err != nil {
+			return err
+		}
+		if head.ChainID == network {
+			return nil
+		}
+	}
+	return errors.Wrapf(ErrUnsupportedURI, "metadata is stored in another network: %s", network)
+}
+
+// bigMapValue - returns decoded bytes stored by string key in big map
+func (p Parser) bigMapValue(ctx context.Context, ptr int64, key string) ([]byte, error) {
+	keyHash, err := ast.BigMapKeyHash(&base.Node{StringValue: &key})
+	if err != nil {
+		return nil, err
+	}
+	state, err := p.bigMapDiffs.Current(ctx, keyHash, ptr)
+	if err != nil {
+		return nil, errors.Wrapf(err, "big map %d key %q", ptr, key)
+	}
+	if state.Removed {
+		return nil, errors.Wrapf(ErrNoMetadata, "big map %d key %q was removed", ptr, key)
+	}
+
+	var value base.Node
+	if err := json.Unmarshal(state.Value, &value); err != nil {
+		return nil, err
+	}
+	if value.BytesValue == nil {
+		return nil, errors.Errorf("big map %d key %q: value is not bytes", ptr, key)
+	}
+	return hex.DecodeString(*value.BytesValue)
+}
+
+// metadataPointer - receives pointer of `%metadata` big map of another contract
+func (p Parser) metadataPointer(ctx context.Context, address string) (int64, error) {
+	script, err := p.rpc.GetScriptJSON(ctx, address, 0)
+	if err != nil {
+		return 0, err
+	}
+	storage, err := script.GetSettledStorage()
+	if err != nil {
+		return 0, err
+	}
+	ptr, ok := FindMetadataPointer(storage)
+	if !ok {
+		return 0, errors.Wrap(ErrNoMetadata, address)
+	}
+	return ptr, nil
+}
+
+// FindMetadataPointer - returns pointer of `%metadata` big map in the settled storage
+func FindMetadataPointer(storage *ast.TypedAst) (int64, bool) {
+	bigMap, ok := storage.FindByName(MetadataBigMap, false).(*ast.BigMap)
+	if !ok || bigMap.Ptr == nil {
+		return 0, false
+	}
+	return *bigMap.Ptr, true
+}
@@ -0,0 +1,130 @@
+package contractmetadata
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/baking-bad/bcdhub/internal/bcd/ast"
+	"github.com/baking-bad/bcdhub/internal/bcd/base"
+	"github.com/baking-bad/bcdhub/internal/models/bigmapdiff"
+	mock_bmd "github.com/baking-bad/bcdhub/internal/models/mock/bigmapdiff"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestParser_Parse(t *testing.T) {
+	const (
+		address  = "KT1QcxwB4QyPKfmSwjH1VRxa6kquUjeDWeEy"
+		ptr      = int64(42)
+		document = `{"name":"Test","description":"Test contract","version":"1.0.0","interfaces":["TZIP-016","TZIP-012"],"views":[{"name":"get_balance","implementations":[{"michelsonStorageView":{"parameter":{"prim":"nat"},"returnType":{"prim":"nat"},"code":[{"prim":"CAR"}]}}]},{"name":"rest","implementations":[{"restApiQuery":{"specificationUri":"https://example.com"}}]}]}`
+	)
+
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, SchemeIPFS), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, SchemeIPFS, "QmTest"), []byte(document), 0o644))
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, SchemeHTTPS, "example.com"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, SchemeHTTPS, "example.com", "metadata.json"), []byte(document), 0o644))
+
+	hash := sha256.Sum256([]byte(document))
+
+	tests := []struct {
+		name    string
+		root    string
+		keys    map[string]string
+		wantErr bool
+	}{
+		{
+			name: "tezos-storage",
+			root: "tezos-storage:content",
+			keys: map[string]string{"content": document},
+		}, {
+			name: "https",
+			root: "https://example.com/metadata.json",
+		}, {
+			name: "sha256 over ipfs",
+			root: fmt.Sprintf("sha256://0x%x/%s", hash, url.PathEscape("ipfs://QmTest")),
+		}, {
+			name:    "sha256 mismatch",
+			root:    fmt.Sprintf("sha256://0x%s/%s", hex.EncodeToString(make([]byte, 32)), url.PathEscape("ipfs://QmTest")),
+			wantErr: true,
+		}, {
+			name:    "unsupported scheme",
+			root:    "ftp://example.com/metadata.json",
+			wantErr: true,
+		}, {
+			name:    "tezos-storage of another network",
+			root:    "tezos-storage://KT1QcxwB4QyPKfmSwjH1VRxa6kquUjeDWeEy.ghostnet/content",
+			wantErr: true,
+		}, {
+			name:    "invalid JSON",
+			root:    "tezos-storage:content",
+			keys:    map[string]string{"content": "not a JSON"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			bmdRepo := mock_bmd.NewMockRepository(ctrl)
+			expectKey(t, bmdRepo, ptr, "", tt.root)
+			for key, value := range tt.keys {
+				expectKey(t, bmdRepo, ptr, key, value)
+			}
+
+			metadata, err := NewParser(bmdRepo, nil, NewDirectoryFetcher(dir), "mainnet").Parse(context.Background(), address, ptr)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, address, metadata.Address)
+			require.Equal(t, ptr, metadata.Ptr)
+			require.Equal(t, tt.root, metadata.URI)
+			require.Equal(t, "Test", metadata.Name)
+			require.Equal(t, "Test contract", metadata.Description)
+			require.Equal(t, "1.0.0", metadata.Version)
+			require.EqualValues(t, []string{"TZIP-016", "TZIP-012"}, metadata.Interfaces)
+			require.JSONEq(t, document, string(metadata.Metadata))
+
+			views, err := metadata.Views()
+			require.NoError(t, err)
+			require.Len(t, views, 1)
+			require.Equal(t, "get_balance", views[0].Name)
+		})
+	}
+}
+
+func TestDirectoryFetcher_Fetch(t *testing.T) {
+	dir := t.TempDir()
+	fetcher := NewDirectoryFetcher(filepath.Join(dir, "stub"))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "secret"), []byte("{}"), 0o644))
+
+	_, err := fetcher.Fetch(context.Background(), "https://../../secret")
+	require.Error(t, err)
+
+	_, err = fetcher.Fetch(context.Background(), "tezos-storage:content")
+	require.ErrorIs(t, err, ErrUnsupportedURI)
+}
+
+func expectKey(t *testing.T, repo *mock_bmd.MockRepository, ptr int64, key, value string) {
+	keyHash, err := ast.BigMapKeyHash(&base.Node{StringValue: &key})
+	require.NoError(t, err)
+
+	repo.EXPECT().
+		Current(gomock.Any(), keyHash, ptr).
+		Return(bigmapdiff.BigMapState{
+			Ptr:     ptr,
+			KeyHash: keyHash,
+			Value:   []byte(fmt.Sprintf(`{"bytes":"%s"}`, hex.EncodeToString([]byte(value)))),
+		}, nil).
+		Times(1)
+}
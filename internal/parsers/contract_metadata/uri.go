@@ -0,0 +1,119 @@
+package contractmetadata
+
+import (
+	"encoding/hex"
+	"net/url"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// URI schemes
+const (
+	SchemeTezosStorage = "tezos-storage"
+	SchemeSha256       = "sha256"
+	SchemeHTTP         = "http"
+	SchemeHTTPS        = "https"
+	SchemeIPFS         = "ipfs"
+)
+
+// Errors
+var (
+	ErrUnsupportedURI = errors.New("unsupported metadata URI")
+	ErrInvalidURI     = errors.New("invalid metadata URI")
+	ErrHashMismatch   = errors.New("metadata hash mismatch")
+)
+
+// Scheme - returns scheme of metadata URI
+func Scheme(uri string) string {
+	scheme, _, ok := strings.Cut(uri, ":")
+	if !ok {
+		return ""
+	}
+	return strings.ToLower(scheme)
+}
+
+// TezosStorageURI - parsed `tezos-storage:` URI. Empty address means the contract which owns the metadata big map.
+type TezosStorageURI struct {
+	Address string
+	Network string
+	Key     string
+}
+
+// ParseTezosStorageURI - parses URI in format `tezos-storage:<key>` or `tezos-storage://<address>[.<network>]/<key>`
+func ParseTezosStorageURI(uri string) (TezosStorageURI, error) {
+	var result TezosStorageURI
+
+	path, ok := strings.CutPrefix(uri, SchemeTezosStorage+":")
+	if !ok {
+		return result, errors.Wrap(ErrInvalidURI, uri)
+	}
+
+	if host, ok := strings.CutPrefix(path, "//"); ok {
+		host, key, ok := strings.Cut(host, "/")
+		if !ok {
+			return result, errors.Wrap(ErrInvalidURI, uri)
+		}
+		address, network, _ := strings.Cut(host, ".")
+		if address == "" {
+			return result, errors.Wrap(ErrInvalidURI, uri)
+		}
+		result.Address = address
+		result.Network = network
+		path = key
+	}
+
+	key, err := url.PathUnescape(path)
+	if err != nil {
+		return result, errors.Wrap(ErrInvalidURI, err.Error())
+	}
+	if key == "" {
+		return result, errors.Wrap(ErrInvalidURI, uri)
+	}
+	result.Key = key
+	return result, nil
+}
+
+// Sha256URI - parsed `sha256:` URI
+type Sha256URI struct {
+	Hash []byte
+	URI  string
+}
+
+// ParseSha256URI - parses URI in format `sha256://0x<hash>/<url-encoded URI>`
+func ParseSha256URI(uri string) (Sha256URI, error) {
+	var result Sha256URI
+
+	path, ok := strings.CutPrefix(uri, SchemeSha256+"://")
+	if !ok {
+		return result, errors.Wrap(ErrInvalidURI, uri)
+	}
+	hash, inner, ok := strings.Cut(path, "/")
+	if !ok {
+		return result, errors.Wrap(ErrInvalidURI, uri)
+	}
+	hash, ok = strings.CutPrefix(hash, "0x")
+	if !ok {
+		return result, errors.Wrap(ErrInvalidURI, uri)
+	}
+
+	decoded, err := hex.DecodeString(hash)
+	if err != nil {
+		return result, errors.Wrap(ErrInvalidURI, err.Error())
+	}
+	if len(decoded) != 32 {
+		return result, errors.Wrapf(ErrInvalidURI, "invalid hash length: %s", uri)
+	}
+
+	innerURI, err := url.PathUnescape(inner)
+	if err != nil {
+		return result, errors.Wrap(ErrInvalidURI, err.Error())
+	}
+	if innerURI == "" {
+		return result, errors.Wrap(ErrInvalidURI, uri)
+	}
+
+	result.Hash = decoded
+	result.URI = innerURI
+	return result, nil
+}
@@ -0,0 +1,96 @@
+package contractmetadata
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTezosStorageURI(t *testing.T) {
+	tests := []struct {
+		name    string
+		uri     string
+		want    TezosStorageURI
+		wantErr bool
+	}{
+		{
+			name: "own big map",
+			uri:  "tezos-storage:content",
+			want: TezosStorageURI{Key: "content"},
+		}, {
+			name: "escaped key",
+			uri:  "tezos-storage:here%2Fthere",
+			want: TezosStorageURI{Key: "here/there"},
+		}, {
+			name: "another contract",
+			uri:  "tezos-storage://KT1QDFEu8JijYbsJqzoXq7mKvfaQQamHD1kX/foo",
+			want: TezosStorageURI{Address: "KT1QDFEu8JijYbsJqzoXq7mKvfaQQamHD1kX", Key: "foo"},
+		}, {
+			name: "another contract with network",
+			uri:  "tezos-storage://KT1QDFEu8JijYbsJqzoXq7mKvfaQQamHD1kX.NetXdQprcVkpaWU/%2Ffoo",
+			want: TezosStorageURI{Address: "KT1QDFEu8JijYbsJqzoXq7mKvfaQQamHD1kX", Network: "NetXdQprcVkpaWU", Key: "/foo"},
+		}, {
+			name:    "empty key",
+			uri:     "tezos-storage:",
+			wantErr: true,
+		}, {
+			name:    "without key",
+			uri:     "tezos-storage://KT1QDFEu8JijYbsJqzoXq7mKvfaQQamHD1kX",
+			wantErr: true,
+		}, {
+			name:    "another scheme",
+			uri:     "https://example.com",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseTezosStorageURI(tt.uri)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestParseSha256URI(t *testing.T) {
+	tests := []struct {
+		name    string
+		uri     string
+		wantURI string
+		wantErr bool
+	}{
+		{
+			name:    "https",
+			uri:     "sha256://0xeaa42ea06b95d7917d22135a630e65352cfd0a721ae88155a1512468a95cb750/https:%2F%2Ftezos.com",
+			wantURI: "https://tezos.com",
+		}, {
+			name:    "without 0x",
+			uri:     "sha256://eaa42ea06b95d7917d22135a630e65352cfd0a721ae88155a1512468a95cb750/https:%2F%2Ftezos.com",
+			wantErr: true,
+		}, {
+			name:    "short hash",
+			uri:     "sha256://0xeaa42ea0/https:%2F%2Ftezos.com",
+			wantErr: true,
+		}, {
+			name:    "without URI",
+			uri:     "sha256://0xeaa42ea06b95d7917d22135a630e65352cfd0a721ae88155a1512468a95cb750",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseSha256URI(tt.uri)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.wantURI, got.URI)
+			require.Len(t, got.Hash, 32)
+		})
+	}
+}
@@ -114,13 +114,14 @@ func (p *Jakarta) Parse(ctx context.Context, script noderpc.Script, old *modelsC
 	old.JakartaID = contractScript.ID
 
 	m := &migration.Migration{
-		ContractID:     old.ID,
-		Contract:       *old,
-		Level:          next.StartLevel,
-		ProtocolID:     next.ID,
-		PrevProtocolID: previous.ID,
-		Timestamp:      timestamp,
-		Kind:           types.MigrationKindUpdate,
+		ContractID:      old.ID,
+		Contract:        *old,
+		Level:           next.StartLevel,
+		ProtocolID:      next.ID,
+		PrevProtocolID:  previous.ID,
+		Timestamp:       timestamp,
+		Kind:            types.MigrationKindUpdate,
+		DeffatedStorage: script.Storage,
 	}
 
 	return tx.Migrations(ctx, m)
@@ -2,6 +2,7 @@ package migrations
 
 import (
 	"context"
+	"encoding/json"
 
 	"github.com/baking-bad/bcdhub/internal/config"
 	"github.com/baking-bad/bcdhub/internal/models/account"
@@ -30,6 +31,13 @@ func NewVestingParser(ctx *config.Context, contractParser contract.Parser, proto
 
 // Parse -
 func (p *VestingParser) Parse(ctx context.Context, data noderpc.ContractData, head noderpc.Header, address string, store parsers.Store) error {
+	var script struct {
+		Storage json.RawMessage `json:"storage"`
+	}
+	if err := json.Unmarshal(data.RawScript, &script); err != nil {
+		return err
+	}
+
 	vestingOperation := &operation.Operation{
 		ProtocolID: p.protocol.ID,
 		Status:     types.OperationStatusApplied,
@@ -55,9 +63,10 @@ func (p *VestingParser) Parse(ctx context.Context, data noderpc.ContractData, he
 			Level:      head.Level,
 			LastAction: head.Timestamp,
 		},
-		Level:     head.Level,
-		Timestamp: head.Timestamp,
-		Script:    data.RawScript,
+		Level:           head.Level,
+		Timestamp:       head.Timestamp,
+		Script:          data.RawScript,
+		DeffatedStorage: script.Storage,
 	}
 	if err := p.parser.Parse(ctx, vestingOperation, store); err != nil {
 		return err
@@ -70,7 +70,9 @@ func (Group) needParse(item noderpc.LightOperation) bool {
 	registerGlobalConstantCondition := item.Kind == consts.RegisterGlobalConstant
 	eventCondition := item.Kind == consts.Event
 	transferTicketCondition := item.Kind == consts.TransferTicket
-	srCondition := item.Kind == consts.SrOriginate || item.Kind == consts.SrExecuteOutboxMessage
+	srCondition := item.Kind == consts.SrOriginate || item.Kind == consts.SrExecuteOutboxMessage ||
+		item.Kind == consts.SrAddMessages || item.Kind == consts.SrPublish || item.Kind == consts.SrCement ||
+		item.Kind == consts.SrRefute || item.Kind == consts.SrTimeout || item.Kind == consts.SrRecoverBond
 	return originationCondition || transactionCondition || srCondition ||
 		registerGlobalConstantCondition || eventCondition || transferTicketCondition
 }
@@ -105,6 +107,16 @@ func (content Content) Parse(ctx context.Context, data noderpc.Operation, store
 		operationParser = NewSrOriginate(content.ParseParams)
 	case consts.SrExecuteOutboxMessage:
 		operationParser = NewSrExecuteOutboxMessage(content.ParseParams)
+	case consts.SrAddMessages:
+		operationParser = NewSrAddMessages(content.ParseParams)
+	case consts.SrPublish:
+		operationParser = NewSrPublish(content.ParseParams)
+	case consts.SrCement:
+		operationParser = NewSrCement(content.ParseParams)
+	case consts.SrRefute, consts.SrTimeout:
+		operationParser = NewSrRefute(content.ParseParams)
+	case consts.SrRecoverBond:
+		operationParser = NewSrRecoverBond(content.ParseParams)
 	default:
 		return nil
 	}
@@ -28,6 +28,7 @@ import (
 	"github.com/baking-bad/bcdhub/internal/models/operation"
 	"github.com/baking-bad/bcdhub/internal/models/protocol"
 	"github.com/baking-bad/bcdhub/internal/models/ticket"
+	"github.com/baking-bad/bcdhub/internal/models/token"
 	"github.com/baking-bad/bcdhub/internal/models/types"
 	"github.com/baking-bad/bcdhub/internal/noderpc"
 	"github.com/baking-bad/bcdhub/internal/parsers"
@@ -521,6 +522,17 @@ func TestGroup_Parse(t *testing.T) {
 						Parameters:      []byte("{\"entrypoint\":\"default\",\"value\":{\"prim\":\"Right\",\"args\":[{\"prim\":\"Left\",\"args\":[{\"prim\":\"Right\",\"args\":[{\"prim\":\"Right\",\"args\":[{\"prim\":\"Pair\",\"args\":[{\"string\":\"tz1aSPEN4RTZbn4aXEsxDiix38dDmacGQ8sq\"},{\"prim\":\"Pair\",\"args\":[{\"string\":\"tz1invbJv3AEm55ct7QF2dVbWZuaDekssYkV\"},{\"int\":\"8010000\"}]}]}]}]}]}]}}"),
 						DeffatedStorage: []byte("{\"prim\":\"Pair\",\"args\":[{\"prim\":\"Pair\",\"args\":[{\"prim\":\"Pair\",\"args\":[{\"prim\":\"Pair\",\"args\":[[{\"bytes\":\"000056d8b91b541c9d20d51f929dcccca2f14928f1dc\"}],{\"int\":\"62\"}]},{\"prim\":\"Pair\",\"args\":[{\"int\":\"63\"},{\"string\":\"Aspen Digital Token\"}]}]},{\"prim\":\"Pair\",\"args\":[{\"prim\":\"Pair\",\"args\":[{\"prim\":\"False\"},{\"bytes\":\"0000a2560a416161def96031630886abe950c4baf036\"}]},{\"prim\":\"Pair\",\"args\":[{\"prim\":\"False\"},{\"bytes\":\"010d25f77b84dc2164a5d1ce5e8a5d3ca2b1d0cbf900\"}]}]}]},{\"prim\":\"Pair\",\"args\":[{\"prim\":\"Pair\",\"args\":[{\"bytes\":\"01796ad78734892d5ae4186e84a30290040732ada700\"},{\"string\":\"ASPD\"}]},{\"int\":\"18000000\"}]}]}"),
 						Tags:            types.FA12Tag,
+						TokenTransfers: []*token.Transfer{
+							{
+								Level:     1068669,
+								Timestamp: timestamp,
+								Contract:  account.Account{Address: "KT1S5iPRQ612wcNm6mXDqDhTNegGFcvTV7vM"},
+								From:      account.Account{Address: "tz1aSPEN4RTZbn4aXEsxDiix38dDmacGQ8sq"},
+								To:        account.Account{Address: "tz1invbJv3AEm55ct7QF2dVbWZuaDekssYkV"},
+								TokenId:   decimal.RequireFromString("0"),
+								Amount:    decimal.RequireFromString("8010000"),
+							},
+						},
 						BigMapDiffs: []*bigmapdiff.BigMapDiff{
 							{
 								Ptr:        63,
@@ -782,6 +794,17 @@ func TestGroup_Parse(t *testing.T) {
 						Burned:          47000,
 						DeffatedStorage: []byte("{\"prim\":\"Pair\",\"args\":[{\"int\":\"31\"},{\"prim\":\"Pair\",\"args\":[[{\"prim\":\"DUP\"},{\"prim\":\"CAR\"},{\"prim\":\"DIP\",\"args\":[[{\"prim\":\"CDR\"}]]},{\"prim\":\"DUP\"},{\"prim\":\"DUP\"},{\"prim\":\"CAR\"},{\"prim\":\"DIP\",\"args\":[[{\"prim\":\"CDR\"}]]},{\"prim\":\"DIP\",\"args\":[[{\"prim\":\"DIP\",\"args\":[{\"int\":\"2\"},[{\"prim\":\"DUP\"}]]},{\"prim\":\"DIG\",\"args\":[{\"int\":\"2\"}]}]]},{\"prim\":\"PUSH\",\"args\":[{\"prim\":\"string\"},{\"string\":\"code\"}]},{\"prim\":\"PAIR\"},{\"prim\":\"PACK\"},{\"prim\":\"GET\"},{\"prim\":\"IF_NONE\",\"args\":[[{\"prim\":\"NONE\",\"args\":[{\"prim\":\"lambda\",\"args\":[{\"prim\":\"pair\",\"args\":[{\"prim\":\"bytes\"},{\"prim\":\"big_map\",\"args\":[{\"prim\":\"bytes\"},{\"prim\":\"bytes\"}]}]},{\"prim\":\"pair\",\"args\":[{\"prim\":\"list\",\"args\":[{\"prim\":\"operation\"}]},{\"prim\":\"big_map\",\"args\":[{\"prim\":\"bytes\"},{\"prim\":\"bytes\"}]}]}]}]}],[{\"prim\":\"UNPACK\",\"args\":[{\"prim\":\"lambda\",\"args\":[{\"prim\":\"pair\",\"args\":[{\"prim\":\"bytes\"},{\"prim\":\"big_map\",\"args\":[{\"prim\":\"bytes\"},{\"prim\":\"bytes\"}]}]},{\"prim\":\"pair\",\"args\":[{\"prim\":\"list\",\"args\":[{\"prim\":\"operation\"}]},{\"prim\":\"big_map\",\"args\":[{\"prim\":\"bytes\"},{\"prim\":\"bytes\"}]}]}]}]},{\"prim\":\"IF_NONE\",\"args\":[[{\"prim\":\"PUSH\",\"args\":[{\"prim\":\"string\"},{\"string\":\"UStore: failed to unpack code\"}]},{\"prim\":\"FAILWITH\"}],[]]},{\"prim\":\"SOME\"}]]},{\"prim\":\"IF_NONE\",\"args\":[[{\"prim\":\"DROP\"},{\"prim\":\"DIP\",\"args\":[[{\"prim\":\"DUP\"},{\"prim\":\"PUSH\",\"args\":[{\"prim\":\"bytes\"},{\"bytes\":\"05010000000866616c6c6261636b\"}]},{\"prim\":\"GET\"},{\"prim\":\"IF_NONE\",\"args\":[[{\"prim\":\"PUSH\",\"args\":[{\"prim\":\"string\"},{\"string\":\"UStore: no field fallback\"}]},{\"prim\":\"FAILWITH\"}],[]]},{\"prim\":\"UNPACK\",\"args\":[{\"prim\":\"lambda\",\"args\":[{\"prim\":\"pair\",\"args\":[{\"prim\":\"pair\",\"args\":[{\"prim\":\"string\"},{\"prim\":\"bytes\"}]},{\"prim\":\"big_map\",\"args\":[{\"prim\":\"bytes\"},{\"prim\":\"bytes\"}]}]},{\"prim\":\"pair\",\"args\":[{\"prim\":\"list\",\"args\":[{\"prim\":\"operation\"}]},{\"prim\":\"big_map\",\"args\":[{\"prim\":\"bytes\"},{\"prim\":\"bytes\"}]}]}]}]},{\"prim\":\"IF_NONE\",\"args\":[[{\"prim\":\"PUSH\",\"args\":[{\"prim\":\"string\"},{\"string\":\"UStore: failed to unpack fallback\"}]},{\"prim\":\"FAILWITH\"}],[]]},{\"prim\":\"SWAP\"}]]},{\"prim\":\"PAIR\"},{\"prim\":\"EXEC\"}],[{\"prim\":\"DIP\",\"args\":[[{\"prim\":\"SWAP\"},{\"prim\":\"DROP\"},{\"prim\":\"PAIR\"}]]},{\"prim\":\"SWAP\"},{\"prim\":\"EXEC\"}]]}],{\"prim\":\"Pair\",\"args\":[{\"int\":\"1\"},{\"prim\":\"False\"}]}]}]}"),
 						Tags:            types.FA12Tag,
+						TokenTransfers: []*token.Transfer{
+							{
+								Level:     1151495,
+								Timestamp: timestamp,
+								Contract:  account.Account{Address: "KT1PWx2mnDueood7fEmfbBDKx1D9BAnnXitn"},
+								From:      account.Account{Address: "KT1Ap287P1NzsnToSJdA4aqSNjPomRaHBZSr"},
+								To:        account.Account{Address: "tz1dMH7tW7RhdvVMR4wKVFF1Ke8m8ZDvrTTE"},
+								TokenId:   decimal.RequireFromString("0"),
+								Amount:    decimal.RequireFromString("7874880"),
+							},
+						},
 						BigMapDiffs: []*bigmapdiff.BigMapDiff{
 							{
 								Ptr:        31,
@@ -1359,6 +1382,17 @@ func TestGroup_Parse(t *testing.T) {
 							Valid: true,
 						},
 						Tags: types.FA2Tag | types.LedgerTag,
+						TokenTransfers: []*token.Transfer{
+							{
+								Level:     1516349,
+								Timestamp: timestamp,
+								Contract:  account.Account{Address: "KT1QcxwB4QyPKfmSwjH1VRxa6kquUjeDWeEy"},
+								From:      account.Account{Address: "tz1aCzsYRUgDZBV7zb7Si6q2AobrocFW5qwb"},
+								To:        account.Account{Address: "tz1a6ZKyEoCmfpsY74jEq6uKBK8RQXdj1aVi"},
+								TokenId:   decimal.RequireFromString("12"),
+								Amount:    decimal.RequireFromString("1"),
+							},
+						},
 						Initiator: account.Account{
 							Address:         "tz1aCzsYRUgDZBV7zb7Si6q2AobrocFW5qwb",
 							Type:            types.AccountTypeTz,
@@ -1395,6 +1429,14 @@ func TestGroup_Parse(t *testing.T) {
 						LastUpdateTime:  timestamp,
 					},
 				},
+				TokenBalances: map[string]*token.Balance{
+					"KT1QcxwB4QyPKfmSwjH1VRxa6kquUjeDWeEy_12_tz1aCzsYRUgDZBV7zb7Si6q2AobrocFW5qwb": {
+						Amount: decimal.RequireFromString("-1"),
+					},
+					"KT1QcxwB4QyPKfmSwjH1VRxa6kquUjeDWeEy_12_tz1a6ZKyEoCmfpsY74jEq6uKBK8RQXdj1aVi": {
+						Amount: decimal.RequireFromString("1"),
+					},
+				},
 			},
 		}, {
 			name: "oocFt4vkkgQGfoRH54328cJUbDdWvj3x6KEs5Arm4XhqwwJmnJ8",
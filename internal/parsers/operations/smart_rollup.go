@@ -4,6 +4,7 @@ import (
 	"encoding/hex"
 	"strconv"
 
+	"github.com/baking-bad/bcdhub/internal/bcd/consts"
 	"github.com/baking-bad/bcdhub/internal/models/account"
 	"github.com/baking-bad/bcdhub/internal/models/operation"
 	smartrollup "github.com/baking-bad/bcdhub/internal/models/smart_rollup"
@@ -52,3 +53,32 @@ func (sr SmartRolupParser) Parse(data noderpc.Operation, operation operation.Ope
 	}
 	return rollup, nil
 }
+
+func newSrAccount(address string, head noderpc.Header) account.Account {
+	return account.Account{
+		Address:    address,
+		Type:       types.NewAccountType(address),
+		Level:      head.Level,
+		LastAction: head.Timestamp,
+	}
+}
+
+func isAppliedSrOperation(data noderpc.Operation) bool {
+	result := data.GetResult()
+	return result != nil && result.Status == consts.Applied
+}
+
+func setGameStatus(refutation *smartrollup.Refutation, status *noderpc.GameStatus, head noderpc.Header) {
+	if status == nil {
+		return
+	}
+	refutation.Status = status.Status
+	if status.Result == nil {
+		return
+	}
+	refutation.Result = status.Result.Kind
+	refutation.Reason = status.Result.Reason
+	if status.Result.Player != "" {
+		refutation.Loser = newSrAccount(status.Result.Player, head)
+	}
+}
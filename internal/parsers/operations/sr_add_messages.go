@@ -0,0 +1,50 @@
+package operations
+
+import (
+	"context"
+	"encoding/hex"
+
+	smartrollup "github.com/baking-bad/bcdhub/internal/models/smart_rollup"
+	"github.com/baking-bad/bcdhub/internal/noderpc"
+	"github.com/baking-bad/bcdhub/internal/parsers"
+	"github.com/pkg/errors"
+)
+
+// SrAddMessages -
+type SrAddMessages struct {
+	*ParseParams
+}
+
+// NewSrAddMessages -
+func NewSrAddMessages(params *ParseParams) SrAddMessages {
+	return SrAddMessages{params}
+}
+
+// Parse -
+func (p SrAddMessages) Parse(ctx context.Context, data noderpc.Operation, store parsers.Store) error {
+	if !isAppliedSrOperation(data) {
+		return nil
+	}
+
+	sender := newSrAccount(data.Source, p.head)
+	messages := make([]*smartrollup.InboxMessage, len(data.Message))
+	for i := range data.Message {
+		payload, err := hex.DecodeString(data.Message[i])
+		if err != nil {
+			return errors.Wrap(err, "inbox message decoding")
+		}
+		messages[i] = &smartrollup.InboxMessage{
+			Level:     p.head.Level,
+			Timestamp: p.head.Timestamp,
+			Sender:    sender,
+			Hash:      p.hash,
+			Counter:   data.Counter,
+			Index:     int64(i),
+			Payload:   payload,
+		}
+	}
+
+	store.AddSmartRollupInboxMessages(messages...)
+	store.AddAccounts(sender)
+	return nil
+}
@@ -0,0 +1,49 @@
+package operations
+
+import (
+	"context"
+
+	smartrollup "github.com/baking-bad/bcdhub/internal/models/smart_rollup"
+	"github.com/baking-bad/bcdhub/internal/noderpc"
+	"github.com/baking-bad/bcdhub/internal/parsers"
+	"github.com/pkg/errors"
+)
+
+// SrCement -
+type SrCement struct {
+	*ParseParams
+}
+
+// NewSrCement -
+func NewSrCement(params *ParseParams) SrCement {
+	return SrCement{params}
+}
+
+// Parse -
+func (p SrCement) Parse(ctx context.Context, data noderpc.Operation, store parsers.Store) error {
+	if !isAppliedSrOperation(data) || data.Rollup == nil {
+		return nil
+	}
+
+	result := data.GetResult()
+	cementation := smartrollup.Cementation{
+		Level:          p.head.Level,
+		Timestamp:      p.head.Timestamp,
+		Rollup:         newSrAccount(*data.Rollup, p.head),
+		Sender:         newSrAccount(data.Source, p.head),
+		CommitmentHash: result.CommitmentHash,
+	}
+	// before Oxford cemented commitment was passed in operation and wasn't returned in result
+	if cementation.CommitmentHash == "" && len(data.Commitment) > 0 {
+		if err := json.Unmarshal(data.Commitment, &cementation.CommitmentHash); err != nil {
+			return errors.Wrap(err, "commitment decoding")
+		}
+	}
+	if result.InboxLevel != nil {
+		cementation.InboxLevel = *result.InboxLevel
+	}
+
+	store.AddSmartRollupCementations(&cementation)
+	store.AddAccounts(cementation.Rollup, cementation.Sender)
+	return nil
+}
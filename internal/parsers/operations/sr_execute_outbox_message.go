@@ -4,9 +4,11 @@ import (
 	"context"
 	"encoding/hex"
 
+	"github.com/baking-bad/bcdhub/internal/bcd/consts"
 	"github.com/baking-bad/bcdhub/internal/bcd/encoding"
 	"github.com/baking-bad/bcdhub/internal/models/account"
 	"github.com/baking-bad/bcdhub/internal/models/operation"
+	smartrollup "github.com/baking-bad/bcdhub/internal/models/smart_rollup"
 	"github.com/baking-bad/bcdhub/internal/models/types"
 	"github.com/baking-bad/bcdhub/internal/noderpc"
 	"github.com/baking-bad/bcdhub/internal/parsers"
@@ -75,6 +77,26 @@ func (p SrExecuteOutboxMessage) Parse(ctx context.Context, data noderpc.Operatio
 			return errors.Wrap(err, "outbox proof decoding")
 		}
 		operation.Payload = append(operation.Payload, proof...)
+
+		if !operation.Destination.IsEmpty() {
+			message := smartrollup.OutboxMessage{
+				Level:              p.head.Level,
+				Timestamp:          p.head.Timestamp,
+				Rollup:             operation.Destination,
+				Executor:           operation.Source,
+				Hash:               p.hash,
+				Counter:            data.Counter,
+				CementedCommitment: data.CementedCommitment,
+			}
+			if data.Metadata != nil {
+				for i := range data.Metadata.Internal {
+					if data.Metadata.Internal[i].Kind == consts.Transaction {
+						message.TransactionsCount++
+					}
+				}
+			}
+			store.AddSmartRollupOutboxMessages(&message)
+		}
 	}
 
 	store.AddOperations(&operation)
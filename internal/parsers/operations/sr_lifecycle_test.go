@@ -0,0 +1,169 @@
+package operations
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/baking-bad/bcdhub/internal/models/account"
+	smartrollup "github.com/baking-bad/bcdhub/internal/models/smart_rollup"
+	"github.com/baking-bad/bcdhub/internal/models/types"
+	"github.com/baking-bad/bcdhub/internal/noderpc"
+	"github.com/baking-bad/bcdhub/internal/parsers"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSmartRollupLifecycle(t *testing.T) {
+	const (
+		rollup = "sr1LhGA2zC9VcYALSifpRBCgDiQfDSQ6bb4x"
+		alice  = "tz1gjaF81ZRRvdzjobyfVNsAeSC6PScjfQwN"
+		bob    = "tz1SiPXX4MYGNJNDsRc7n8hkvUqFzg8xqF9m"
+	)
+	ts := time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC)
+	head := noderpc.Header{Level: 100, Timestamp: ts}
+	hash := []byte{0x01, 0x02}
+
+	newAccount := func(address string) account.Account {
+		return account.Account{
+			Address:    address,
+			Type:       types.NewAccountType(address),
+			Level:      100,
+			LastAction: ts,
+		}
+	}
+
+	tests := []struct {
+		name  string
+		data  string
+		check func(t *testing.T, store *parsers.TestStore)
+	}{
+		{
+			name: "add messages",
+			data: `{"kind":"smart_rollup_add_messages","source":"` + alice + `","fee":"100","counter":"10","gas_limit":"1000","storage_limit":"0","message":["0001","ff"],"metadata":{"operation_result":{"status":"applied","consumed_milligas":"1000"}}}`,
+			check: func(t *testing.T, store *parsers.TestStore) {
+				require.Len(t, store.SmartRollupInboxMessages, 2)
+				require.Equal(t, &smartrollup.InboxMessage{
+					Level:     100,
+					Timestamp: ts,
+					Sender:    newAccount(alice),
+					Hash:      hash,
+					Counter:   10,
+					Index:     1,
+					Payload:   []byte{0xff},
+				}, store.SmartRollupInboxMessages[1])
+			},
+		}, {
+			name: "publish",
+			data: `{"kind":"smart_rollup_publish","source":"` + alice + `","fee":"100","counter":"11","gas_limit":"1000","storage_limit":"0","rollup":"` + rollup + `","commitment":{"compressed_state":"srs11y1ZCJfeWnHzoX3rAjcTXiphwg8NvqQhvishP3PU68jgSREuk6","inbox_level":80,"predecessor":"src12UJzB8mg7yU6nWPzicH7ofJbFjyJEbHvwtZdRaD7nLnVkNR4aw","number_of_ticks":"880000000000"},"metadata":{"operation_result":{"status":"applied","consumed_milligas":"1000","staked_hash":"src13wCGc2nMVfN7rD1rgeG3g1q7oXYX2m5MJY5ZRooVhLt7JwKXwX","published_at_level":100}}}`,
+			check: func(t *testing.T, store *parsers.TestStore) {
+				require.Len(t, store.SmartRollupCommitments, 1)
+				require.Equal(t, &smartrollup.Commitment{
+					Level:           100,
+					Timestamp:       ts,
+					Rollup:          newAccount(rollup),
+					Publisher:       newAccount(alice),
+					Hash:            "src13wCGc2nMVfN7rD1rgeG3g1q7oXYX2m5MJY5ZRooVhLt7JwKXwX",
+					Predecessor:     "src12UJzB8mg7yU6nWPzicH7ofJbFjyJEbHvwtZdRaD7nLnVkNR4aw",
+					CompressedState: "srs11y1ZCJfeWnHzoX3rAjcTXiphwg8NvqQhvishP3PU68jgSREuk6",
+					InboxLevel:      80,
+					NumberOfTicks:   decimal.RequireFromString("880000000000"),
+				}, store.SmartRollupCommitments[0])
+				require.Contains(t, store.Accounts, rollup)
+				require.Contains(t, store.Accounts, alice)
+			},
+		}, {
+			name: "cement",
+			data: `{"kind":"smart_rollup_cement","source":"` + bob + `","fee":"100","counter":"12","gas_limit":"1000","storage_limit":"0","rollup":"` + rollup + `","metadata":{"operation_result":{"status":"applied","consumed_milligas":"1000","inbox_level":80,"commitment_hash":"src13wCGc2nMVfN7rD1rgeG3g1q7oXYX2m5MJY5ZRooVhLt7JwKXwX"}}}`,
+			check: func(t *testing.T, store *parsers.TestStore) {
+				require.Equal(t, []*smartrollup.Cementation{
+					{
+						Level:          100,
+						Timestamp:      ts,
+						Rollup:         newAccount(rollup),
+						Sender:         newAccount(bob),
+						CommitmentHash: "src13wCGc2nMVfN7rD1rgeG3g1q7oXYX2m5MJY5ZRooVhLt7JwKXwX",
+						InboxLevel:     80,
+					},
+				}, store.SmartRollupCementations)
+			},
+		}, {
+			name: "cement before oxford",
+			data: `{"kind":"smart_rollup_cement","source":"` + bob + `","fee":"100","counter":"12","gas_limit":"1000","storage_limit":"0","rollup":"` + rollup + `","commitment":"src13wCGc2nMVfN7rD1rgeG3g1q7oXYX2m5MJY5ZRooVhLt7JwKXwX","metadata":{"operation_result":{"status":"applied","consumed_milligas":"1000","inbox_level":80}}}`,
+			check: func(t *testing.T, store *parsers.TestStore) {
+				require.Len(t, store.SmartRollupCementations, 1)
+				require.Equal(t, "src13wCGc2nMVfN7rD1rgeG3g1q7oXYX2m5MJY5ZRooVhLt7JwKXwX", store.SmartRollupCementations[0].CommitmentHash)
+			},
+		}, {
+			name: "refute start",
+			data: `{"kind":"smart_rollup_refute","source":"` + alice + `","fee":"100","counter":"13","gas_limit":"1000","storage_limit":"0","rollup":"` + rollup + `","opponent":"` + bob + `","refutation":{"refutation_kind":"start","player_commitment_hash":"src1","opponent_commitment_hash":"src2"},"metadata":{"operation_result":{"status":"applied","consumed_milligas":"1000","game_status":"ongoing"}}}`,
+			check: func(t *testing.T, store *parsers.TestStore) {
+				require.Equal(t, []*smartrollup.Refutation{
+					{
+						Level:              100,
+						Timestamp:          ts,
+						Rollup:             newAccount(rollup),
+						Player:             newAccount(alice),
+						Opponent:           newAccount(bob),
+						Kind:               smartrollup.RefutationKindStart,
+						PlayerCommitment:   "src1",
+						OpponentCommitment: "src2",
+						Status:             smartrollup.GameStatusOngoing,
+					},
+				}, store.SmartRollupRefutations)
+			},
+		}, {
+			name: "timeout",
+			data: `{"kind":"smart_rollup_timeout","source":"` + alice + `","fee":"100","counter":"14","gas_limit":"1000","storage_limit":"0","rollup":"` + rollup + `","stakers":{"alice":"` + alice + `","bob":"` + bob + `"},"metadata":{"operation_result":{"status":"applied","consumed_milligas":"1000","game_status":{"result":{"kind":"loser","reason":"timeout","player":"` + bob + `"}}}}}`,
+			check: func(t *testing.T, store *parsers.TestStore) {
+				require.Equal(t, []*smartrollup.Refutation{
+					{
+						Level:     100,
+						Timestamp: ts,
+						Rollup:    newAccount(rollup),
+						Player:    newAccount(alice),
+						Opponent:  newAccount(bob),
+						Loser:     newAccount(bob),
+						Kind:      smartrollup.RefutationKindTimeout,
+						Status:    smartrollup.GameStatusEnded,
+						Result:    smartrollup.GameResultLoser,
+						Reason:    "timeout",
+					},
+				}, store.SmartRollupRefutations)
+			},
+		}, {
+			name: "recover bond",
+			data: `{"kind":"smart_rollup_recover_bond","source":"` + alice + `","fee":"100","counter":"15","gas_limit":"1000","storage_limit":"0","rollup":"` + rollup + `","staker":"` + bob + `","metadata":{"operation_result":{"status":"applied","consumed_milligas":"1000"}}}`,
+			check: func(t *testing.T, store *parsers.TestStore) {
+				require.Equal(t, []*smartrollup.BondRecovery{
+					{
+						Level:     100,
+						Timestamp: ts,
+						Rollup:    newAccount(rollup),
+						Staker:    newAccount(bob),
+					},
+				}, store.SmartRollupBondRecoveries)
+			},
+		}, {
+			name: "failed publish",
+			data: `{"kind":"smart_rollup_publish","source":"` + alice + `","fee":"100","counter":"11","gas_limit":"1000","storage_limit":"0","rollup":"` + rollup + `","commitment":{"compressed_state":"srs1","inbox_level":80,"predecessor":"src1","number_of_ticks":"1"},"metadata":{"operation_result":{"status":"backtracked","consumed_milligas":"1000"}}}`,
+			check: func(t *testing.T, store *parsers.TestStore) {
+				require.Empty(t, store.SmartRollupCommitments)
+				require.Empty(t, store.Accounts)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var data noderpc.Operation
+			require.NoError(t, json.Unmarshal([]byte(tt.data), &data))
+
+			store := parsers.NewTestStore()
+			params := &ParseParams{head: head, hash: hash}
+			require.NoError(t, NewContent(params).Parse(context.Background(), data, store))
+			require.Empty(t, store.Operations)
+			tt.check(t, store)
+		})
+	}
+}
@@ -0,0 +1,53 @@
+package operations
+
+import (
+	"context"
+
+	smartrollup "github.com/baking-bad/bcdhub/internal/models/smart_rollup"
+	"github.com/baking-bad/bcdhub/internal/noderpc"
+	"github.com/baking-bad/bcdhub/internal/parsers"
+	"github.com/pkg/errors"
+	"github.com/shopspring/decimal"
+)
+
+// SrPublish -
+type SrPublish struct {
+	*ParseParams
+}
+
+// NewSrPublish -
+func NewSrPublish(params *ParseParams) SrPublish {
+	return SrPublish{params}
+}
+
+// Parse -
+func (p SrPublish) Parse(ctx context.Context, data noderpc.Operation, store parsers.Store) error {
+	if !isAppliedSrOperation(data) || data.Rollup == nil {
+		return nil
+	}
+
+	var value noderpc.SmartRollupCommitment
+	if err := json.Unmarshal(data.Commitment, &value); err != nil {
+		return errors.Wrap(err, "commitment decoding")
+	}
+	ticks, err := decimal.NewFromString(value.NumberOfTicks)
+	if err != nil {
+		return errors.Wrap(err, "number of ticks decoding")
+	}
+
+	commitment := smartrollup.Commitment{
+		Level:           p.head.Level,
+		Timestamp:       p.head.Timestamp,
+		Rollup:          newSrAccount(*data.Rollup, p.head),
+		Publisher:       newSrAccount(data.Source, p.head),
+		Hash:            data.GetResult().StakedHash,
+		Predecessor:     value.Predecessor,
+		CompressedState: value.CompressedState,
+		InboxLevel:      value.InboxLevel,
+		NumberOfTicks:   ticks,
+	}
+
+	store.AddSmartRollupCommitments(&commitment)
+	store.AddAccounts(commitment.Rollup, commitment.Publisher)
+	return nil
+}
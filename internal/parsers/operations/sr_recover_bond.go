@@ -0,0 +1,37 @@
+package operations
+
+import (
+	"context"
+
+	smartrollup "github.com/baking-bad/bcdhub/internal/models/smart_rollup"
+	"github.com/baking-bad/bcdhub/internal/noderpc"
+	"github.com/baking-bad/bcdhub/internal/parsers"
+)
+
+// SrRecoverBond -
+type SrRecoverBond struct {
+	*ParseParams
+}
+
+// NewSrRecoverBond -
+func NewSrRecoverBond(params *ParseParams) SrRecoverBond {
+	return SrRecoverBond{params}
+}
+
+// Parse -
+func (p SrRecoverBond) Parse(ctx context.Context, data noderpc.Operation, store parsers.Store) error {
+	if !isAppliedSrOperation(data) || data.Rollup == nil {
+		return nil
+	}
+
+	recovery := smartrollup.BondRecovery{
+		Level:     p.head.Level,
+		Timestamp: p.head.Timestamp,
+		Rollup:    newSrAccount(*data.Rollup, p.head),
+		Staker:    newSrAccount(data.Staker, p.head),
+	}
+
+	store.AddSmartRollupBondRecoveries(&recovery)
+	store.AddAccounts(recovery.Rollup, recovery.Staker)
+	return nil
+}
@@ -0,0 +1,52 @@
+package operations
+
+import (
+	"context"
+
+	smartrollup "github.com/baking-bad/bcdhub/internal/models/smart_rollup"
+	"github.com/baking-bad/bcdhub/internal/noderpc"
+	"github.com/baking-bad/bcdhub/internal/parsers"
+)
+
+// SrRefute - parses `smart_rollup_refute` and `smart_rollup_timeout` which are steps of refutation game
+type SrRefute struct {
+	*ParseParams
+}
+
+// NewSrRefute -
+func NewSrRefute(params *ParseParams) SrRefute {
+	return SrRefute{params}
+}
+
+// Parse -
+func (p SrRefute) Parse(ctx context.Context, data noderpc.Operation, store parsers.Store) error {
+	if !isAppliedSrOperation(data) || data.Rollup == nil {
+		return nil
+	}
+
+	refutation := smartrollup.Refutation{
+		Level:     p.head.Level,
+		Timestamp: p.head.Timestamp,
+		Rollup:    newSrAccount(*data.Rollup, p.head),
+	}
+
+	switch {
+	case data.Stakers != nil:
+		refutation.Kind = smartrollup.RefutationKindTimeout
+		refutation.Player = newSrAccount(data.Stakers.Alice, p.head)
+		refutation.Opponent = newSrAccount(data.Stakers.Bob, p.head)
+	case data.Refutation != nil:
+		refutation.Kind = data.Refutation.Kind
+		refutation.Player = newSrAccount(data.Source, p.head)
+		refutation.Opponent = newSrAccount(data.Opponent, p.head)
+		refutation.PlayerCommitment = data.Refutation.PlayerCommitmentHash
+		refutation.OpponentCommitment = data.Refutation.OpponentCommitmentHash
+	default:
+		return nil
+	}
+	setGameStatus(&refutation, data.GetResult().GameStatus, p.head)
+
+	store.AddSmartRollupRefutations(&refutation)
+	store.AddAccounts(refutation.Rollup, refutation.Player, refutation.Opponent)
+	return nil
+}
@@ -17,6 +17,7 @@ import (
 	"github.com/baking-bad/bcdhub/internal/models/contract"
 	"github.com/baking-bad/bcdhub/internal/models/operation"
 	"github.com/baking-bad/bcdhub/internal/models/ticket"
+	"github.com/baking-bad/bcdhub/internal/models/token"
 	"github.com/baking-bad/bcdhub/internal/models/types"
 	"github.com/baking-bad/bcdhub/internal/noderpc"
 	"github.com/baking-bad/bcdhub/internal/parsers"
@@ -146,6 +147,11 @@ func compareParserResponse(t *testing.T, got, want *parsers.TestStore) {
 	for hash := range got.Tickets {
 		require.Equal(t, want.Tickets[hash], got.Tickets[hash])
 	}
+	for key, wantBalance := range want.TokenBalances {
+		gotBalance, ok := got.TokenBalances[key]
+		require.True(t, ok, key)
+		require.Equal(t, wantBalance.Amount.String(), gotBalance.Amount.String())
+	}
 	for key, wantAddress := range want.Accounts {
 		gotAddress, ok := got.Accounts[key]
 		require.True(t, ok)
@@ -189,6 +195,7 @@ func compareOperations(t *testing.T, want, got *operation.Operation) {
 	require.Len(t, got.BigMapDiffs, len(want.BigMapDiffs))
 	require.Len(t, got.BigMapActions, len(want.BigMapActions))
 	require.Len(t, got.TicketUpdates, len(want.TicketUpdates))
+	require.Len(t, got.TokenTransfers, len(want.TokenTransfers))
 
 	for i := range want.BigMapDiffs {
 		compareBigMapDiff(t, want.BigMapDiffs[i], got.BigMapDiffs[i])
@@ -201,6 +208,20 @@ func compareOperations(t *testing.T, want, got *operation.Operation) {
 	for i := range want.TicketUpdates {
 		compareTicketUpdates(t, want.TicketUpdates[i], got.TicketUpdates[i])
 	}
+
+	for i := range want.TokenTransfers {
+		compareTokenTransfers(t, want.TokenTransfers[i], got.TokenTransfers[i])
+	}
+}
+
+func compareTokenTransfers(t *testing.T, want, got *token.Transfer) {
+	require.EqualValues(t, want.Contract.Address, got.Contract.Address)
+	require.EqualValues(t, want.From.Address, got.From.Address)
+	require.EqualValues(t, want.To.Address, got.To.Address)
+	require.EqualValues(t, want.TokenId.String(), got.TokenId.String())
+	require.EqualValues(t, want.Amount.String(), got.Amount.String())
+	require.EqualValues(t, want.Level, got.Level)
+	require.EqualValues(t, want.Timestamp, got.Timestamp)
 }
 
 func compareTicketUpdates(t *testing.T, want, got *ticket.TicketUpdate) {
@@ -0,0 +1,428 @@
+package operations
+
+import (
+	"context"
+
+	"github.com/baking-bad/bcdhub/internal/bcd/ast"
+	"github.com/baking-bad/bcdhub/internal/bcd/base"
+	"github.com/baking-bad/bcdhub/internal/bcd/consts"
+	"github.com/baking-bad/bcdhub/internal/bcd/contract/trees"
+	"github.com/baking-bad/bcdhub/internal/bcd/forge"
+	bcdTypes "github.com/baking-bad/bcdhub/internal/bcd/types"
+	"github.com/baking-bad/bcdhub/internal/models"
+	"github.com/baking-bad/bcdhub/internal/models/account"
+	"github.com/baking-bad/bcdhub/internal/models/bigmapdiff"
+	"github.com/baking-bad/bcdhub/internal/models/operation"
+	"github.com/baking-bad/bcdhub/internal/models/token"
+	"github.com/baking-bad/bcdhub/internal/models/types"
+	"github.com/baking-bad/bcdhub/internal/parsers"
+	"github.com/pkg/errors"
+	"github.com/shopspring/decimal"
+)
+
+type ledgerKind int
+
+const (
+	ledgerKindUnknown ledgerKind = iota
+	ledgerKindSingleAsset
+	ledgerKindMultiAsset
+	ledgerKindNftAsset
+	ledgerKindAllowances
+)
+
+// ledgerNames - names of balance big maps. FA1.2 contracts usually keep balances in `ledger` or `balances`.
+var ledgerNames = []string{"ledger", "balances"}
+
+// TokenTransferParser - extracts FA1.2 and FA2 token transfers from `transfer` parameters and ledger big map diffs
+type TokenTransferParser struct {
+	bigMapDiffs bigmapdiff.Repository
+	storage     models.GeneralRepository
+}
+
+// NewTokenTransferParser -
+func NewTokenTransferParser(bigMapDiffs bigmapdiff.Repository, storage models.GeneralRepository) TokenTransferParser {
+	return TokenTransferParser{
+		bigMapDiffs: bigMapDiffs,
+		storage:     storage,
+	}
+}
+
+// Parse - sets token transfers of the operation and updates token balances in the store.
+// Transfers are decoded from parameters for `transfer` calls and from ledger big map diffs for others (mint, burn, etc.).
+func (p TokenTransferParser) Parse(ctx context.Context, tx *operation.Operation, store parsers.Store) error {
+	if !tx.IsApplied() || tx.AST == nil {
+		return nil
+	}
+	if !tx.Tags.Has(types.FA12Tag) && !tx.Tags.Has(types.FA2Tag) {
+		return nil
+	}
+
+	var (
+		transfers []*token.Transfer
+		err       error
+	)
+	switch {
+	case tx.Entrypoint.String() == consts.TransferEntrypoint:
+		transfers, err = p.fromParameters(tx)
+	case tx.Tags.Has(types.LedgerTag), tx.Tags.Has(types.FA12Tag):
+		transfers, err = p.fromLedger(ctx, tx, store)
+	}
+	if err != nil {
+		return err
+	}
+	if len(transfers) == 0 {
+		return nil
+	}
+
+	for i := range transfers {
+		store.AddAccounts(transfers[i].Contract, transfers[i].From, transfers[i].To)
+
+		if !transfers[i].From.IsEmpty() {
+			store.AddTokenBalances(token.Balance{
+				Contract: transfers[i].Contract,
+				Account:  transfers[i].From,
+				TokenId:  transfers[i].TokenId,
+				Amount:   transfers[i].Amount.Neg(),
+			})
+		}
+		if !transfers[i].To.IsEmpty() {
+			store.AddTokenBalances(token.Balance{
+				Contract: transfers[i].Contract,
+				Account:  transfers[i].To,
+				TokenId:  transfers[i].TokenId,
+				Amount:   transfers[i].Amount.Copy(),
+			})
+		}
+	}
+
+	tx.TokenTransfers = transfers
+	return nil
+}
+
+func (p TokenTransferParser) fromParameters(tx *operation.Operation) ([]*token.Transfer, error) {
+	if len(tx.Parameters) == 0 {
+		return nil, nil
+	}
+
+	param, err := tx.AST.ParameterType()
+	if err != nil {
+		return nil, err
+	}
+	subTree, err := param.FromParameters(bcdTypes.NewParameters(tx.Parameters))
+	if err != nil {
+		return nil, err
+	}
+	node, _ := subTree.UnwrapAndGetEntrypointName()
+	if node == nil {
+		return nil, nil
+	}
+	value, err := node.ToBaseNode(false)
+	if err != nil {
+		return nil, err
+	}
+
+	transfers := make([]*token.Transfer, 0)
+	switch value.Prim {
+	case consts.PrimArray:
+		// FA2: list (pair (address %from_) (list %txs (pair (address %to_) (pair (nat %token_id) (nat %amount)))))
+		for _, item := range value.Args {
+			args := pairArgs(item)
+			if len(args) != 2 || args[1].Prim != consts.PrimArray {
+				return nil, nil
+			}
+			from, err := nodeAddress(args[0])
+			if err != nil {
+				return nil, err
+			}
+			for _, txItem := range args[1].Args {
+				txArgs := pairArgs(txItem)
+				if len(txArgs) != 3 {
+					return nil, nil
+				}
+				to, err := nodeAddress(txArgs[0])
+				if err != nil {
+					return nil, err
+				}
+				tokenId, ok := nodeDecimal(txArgs[1])
+				if !ok {
+					return nil, nil
+				}
+				amount, ok := nodeDecimal(txArgs[2])
+				if !ok {
+					return nil, nil
+				}
+				transfers = append(transfers, newTokenTransfer(tx, from, to, tokenId, amount))
+			}
+		}
+	case consts.Pair:
+		// FA1.2: pair (address :from) (pair (address :to) (nat :value))
+		args := pairArgs(value)
+		if len(args) != 3 {
+			return nil, nil
+		}
+		from, err := nodeAddress(args[0])
+		if err != nil {
+			return nil, err
+		}
+		to, err := nodeAddress(args[1])
+		if err != nil {
+			return nil, err
+		}
+		amount, ok := nodeDecimal(args[2])
+		if !ok {
+			return nil, nil
+		}
+		transfers = append(transfers, newTokenTransfer(tx, from, to, decimal.Zero, amount))
+	}
+
+	return transfers, nil
+}
+
+func (p TokenTransferParser) fromLedger(ctx context.Context, tx *operation.Operation, store parsers.Store) ([]*token.Transfer, error) {
+	if len(tx.BigMapDiffs) == 0 || len(tx.DeffatedStorage) == 0 {
+		return nil, nil
+	}
+
+	storage, err := tx.AST.StorageType()
+	if err != nil {
+		return nil, err
+	}
+	if err := storage.SettleFromBytes(tx.DeffatedStorage); err != nil {
+		return nil, err
+	}
+	ledger, kind := findLedger(storage)
+	if kind == ledgerKindUnknown {
+		return nil, nil
+	}
+
+	transfers := make([]*token.Transfer, 0)
+	for _, diff := range tx.BigMapDiffs {
+		if diff.Ptr != *ledger.Ptr {
+			continue
+		}
+
+		prev, err := p.previousValue(ctx, tx, diff, store)
+		if err != nil {
+			return nil, err
+		}
+
+		var key base.Node
+		if err := json.Unmarshal(diff.Key, &key); err != nil {
+			return nil, err
+		}
+
+		switch kind {
+		case ledgerKindNftAsset:
+			tokenId, ok := nodeDecimal(&key)
+			if !ok {
+				continue
+			}
+			from, err := ledgerOwner(prev)
+			if err != nil {
+				return nil, err
+			}
+			to, err := ledgerOwner(diff.Value)
+			if err != nil {
+				return nil, err
+			}
+			if from == to {
+				continue
+			}
+			transfers = append(transfers, newTokenTransfer(tx, from, to, tokenId, decimal.NewFromInt(1)))
+
+		case ledgerKindSingleAsset, ledgerKindMultiAsset, ledgerKindAllowances:
+			var (
+				owner   string
+				tokenId = decimal.Zero
+			)
+			if kind == ledgerKindMultiAsset {
+				args := pairArgs(&key)
+				if len(args) != 2 {
+					continue
+				}
+				owner, err = nodeAddress(args[0])
+				if err != nil {
+					return nil, err
+				}
+				id, ok := nodeDecimal(args[1])
+				if !ok {
+					continue
+				}
+				tokenId = id
+			} else {
+				owner, err = nodeAddress(&key)
+				if err != nil {
+					return nil, err
+				}
+			}
+
+			prevAmount, err := ledgerAmount(prev)
+			if err != nil {
+				return nil, err
+			}
+			amount, err := ledgerAmount(diff.Value)
+			if err != nil {
+				return nil, err
+			}
+
+			delta := amount.Sub(prevAmount)
+			switch delta.Sign() {
+			case 1:
+				transfers = append(transfers, newTokenTransfer(tx, "", owner, tokenId, delta))
+			case -1:
+				transfers = append(transfers, newTokenTransfer(tx, owner, "", tokenId, delta.Neg()))
+			}
+		}
+	}
+	return transfers, nil
+}
+
+// previousValue - returns ledger value before the operation: from earlier operations of the block or from the current big map state
+func (p TokenTransferParser) previousValue(ctx context.Context, tx *operation.Operation, diff *bigmapdiff.BigMapDiff, store parsers.Store) ([]byte, error) {
+	var (
+		value []byte
+		found bool
+	)
+	for _, op := range store.ListOperations() {
+		if op == tx {
+			break
+		}
+		for _, prev := range op.BigMapDiffs {
+			if prev.Ptr == diff.Ptr && prev.KeyHash == diff.KeyHash {
+				value = prev.Value
+				found = true
+			}
+		}
+	}
+	if found {
+		return value, nil
+	}
+
+	state, err := p.bigMapDiffs.Current(ctx, diff.KeyHash, diff.Ptr)
+	if err != nil {
+		if p.storage.IsRecordNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if state.Removed {
+		return nil, nil
+	}
+	return state.Value, nil
+}
+
+// findLedger - returns the first balance big map of known shape
+func findLedger(storage *ast.TypedAst) (*ast.BigMap, ledgerKind) {
+	for _, name := range ledgerNames {
+		ledger, ok := storage.FindByName(name, false).(*ast.BigMap)
+		if !ok || ledger.Ptr == nil {
+			continue
+		}
+		if kind := getLedgerKind(ledger); kind != ledgerKindUnknown {
+			return ledger, kind
+		}
+	}
+	return nil, ledgerKindUnknown
+}
+
+func getLedgerKind(ledger *ast.BigMap) ledgerKind {
+	switch {
+	case ledger.EqualType(trees.NewFA12LedgerAllowances.Nodes[0]):
+		return ledgerKindAllowances
+	case ledger.EqualType(trees.NewNftLedgerSingleAsset.Nodes[0]):
+		return ledgerKindSingleAsset
+	case ledger.EqualType(trees.NewNftLedgerMultiAsset.Nodes[0]):
+		return ledgerKindMultiAsset
+	case ledger.EqualType(trees.NewNftLedgerAsset.Nodes[0]):
+		return ledgerKindNftAsset
+	default:
+		return ledgerKindUnknown
+	}
+}
+
+func newTokenTransfer(tx *operation.Operation, from, to string, tokenId, amount decimal.Decimal) *token.Transfer {
+	return &token.Transfer{
+		Level:     tx.Level,
+		Timestamp: tx.Timestamp,
+		TokenId:   tokenId,
+		Amount:    amount,
+		Contract:  tx.Destination,
+		From:      tokenAccount(tx, from),
+		To:        tokenAccount(tx, to),
+	}
+}
+
+func tokenAccount(tx *operation.Operation, address string) account.Account {
+	if address == "" {
+		return account.Account{}
+	}
+	return account.Account{
+		Address:    address,
+		Type:       types.NewAccountType(address),
+		Level:      tx.Level,
+		LastAction: tx.Timestamp,
+	}
+}
+
+func ledgerOwner(value []byte) (string, error) {
+	if len(value) == 0 {
+		return "", nil
+	}
+	var node base.Node
+	if err := json.Unmarshal(value, &node); err != nil {
+		return "", err
+	}
+	return nodeAddress(&node)
+}
+
+func ledgerAmount(value []byte) (decimal.Decimal, error) {
+	if len(value) == 0 {
+		return decimal.Zero, nil
+	}
+	var node base.Node
+	if err := json.Unmarshal(value, &node); err != nil {
+		return decimal.Zero, err
+	}
+	// FA1.2 ledger value with allowances: pair (nat %balance) (map %approvals address nat)
+	if args := pairArgs(&node); len(args) > 0 {
+		node = *args[0]
+	}
+	amount, ok := nodeDecimal(&node)
+	if !ok {
+		return decimal.Zero, errors.Errorf("invalid ledger amount: %s", value)
+	}
+	return amount, nil
+}
+
+// pairArgs - flattens right comb of pairs
+func pairArgs(node *base.Node) []*base.Node {
+	if node == nil || node.Prim != consts.Pair || len(node.Args) == 0 {
+		return nil
+	}
+	args := make([]*base.Node, 0, len(node.Args))
+	args = append(args, node.Args[:len(node.Args)-1]...)
+
+	last := node.Args[len(node.Args)-1]
+	if last.Prim == consts.Pair {
+		return append(args, pairArgs(last)...)
+	}
+	return append(args, last)
+}
+
+func nodeAddress(node *base.Node) (string, error) {
+	switch {
+	case node.StringValue != nil:
+		return *node.StringValue, nil
+	case node.BytesValue != nil:
+		return forge.UnforgeAddress(*node.BytesValue)
+	default:
+		return "", errors.Errorf("invalid address node: %s", node.String())
+	}
+}
+
+func nodeDecimal(node *base.Node) (decimal.Decimal, bool) {
+	if node.IntValue == nil || node.IntValue.Int == nil {
+		return decimal.Zero, false
+	}
+	return decimal.NewFromBigInt(node.IntValue.Int, 0), true
+}
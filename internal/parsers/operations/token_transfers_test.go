@@ -0,0 +1,225 @@
+package operations
+
+import (
+	"context"
+	"testing"
+
+	"github.com/baking-bad/bcdhub/internal/bcd/ast"
+	"github.com/baking-bad/bcdhub/internal/models/account"
+	"github.com/baking-bad/bcdhub/internal/models/bigmapdiff"
+	"github.com/baking-bad/bcdhub/internal/models/mock"
+	mock_bmd "github.com/baking-bad/bcdhub/internal/models/mock/bigmapdiff"
+	"github.com/baking-bad/bcdhub/internal/models/operation"
+	"github.com/baking-bad/bcdhub/internal/models/types"
+	"github.com/baking-bad/bcdhub/internal/parsers"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestTokenTransferParser_Ledger(t *testing.T) {
+	const (
+		contractAddress = "KT1QcxwB4QyPKfmSwjH1VRxa6kquUjeDWeEy"
+		owner           = "tz1aCzsYRUgDZBV7zb7Si6q2AobrocFW5qwb"
+		script          = `[{"prim":"parameter","args":[{"prim":"or","args":[{"prim":"pair","args":[{"prim":"address"},{"prim":"nat"},{"prim":"nat"}],"annots":["%mint"]},{"prim":"pair","args":[{"prim":"address"},{"prim":"nat"},{"prim":"nat"}],"annots":["%burn"]}]}]},{"prim":"storage","args":[{"prim":"big_map","args":[{"prim":"pair","args":[{"prim":"address"},{"prim":"nat"}]},{"prim":"nat"}],"annots":["%ledger"]}]}]`
+		keyHash         = "exprtfKNhZ1G8vMscchFjt1G1qww2P93VTLHMuhyThVYygZLdnRev2"
+		key             = `{"prim":"Pair","args":[{"string":"tz1aCzsYRUgDZBV7zb7Si6q2AobrocFW5qwb"},{"int":"3"}]}`
+	)
+
+	tests := []struct {
+		name       string
+		prev       []byte
+		value      []byte
+		wantFrom   string
+		wantTo     string
+		wantAmount string
+	}{
+		{
+			name:       "mint to new holder",
+			value:      []byte(`{"int":"100"}`),
+			wantTo:     owner,
+			wantAmount: "100",
+		}, {
+			name:       "mint to existing holder",
+			prev:       []byte(`{"int":"100"}`),
+			value:      []byte(`{"int":"150"}`),
+			wantTo:     owner,
+			wantAmount: "50",
+		}, {
+			name:       "burn",
+			prev:       []byte(`{"int":"100"}`),
+			value:      []byte(`{"int":"40"}`),
+			wantFrom:   owner,
+			wantAmount: "60",
+		}, {
+			name:       "remove key",
+			prev:       []byte(`{"int":"100"}`),
+			wantFrom:   owner,
+			wantAmount: "100",
+		}, {
+			name:  "unchanged",
+			prev:  []byte(`{"int":"100"}`),
+			value: []byte(`{"int":"100"}`),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			generalRepo := mock.NewMockGeneralRepository(ctrl)
+			bmdRepo := mock_bmd.NewMockRepository(ctrl)
+
+			if tt.prev == nil {
+				errNotFound := errors.New("not found")
+				bmdRepo.EXPECT().
+					Current(gomock.Any(), keyHash, int64(10)).
+					Return(bigmapdiff.BigMapState{}, errNotFound).
+					Times(1)
+				generalRepo.EXPECT().
+					IsRecordNotFound(errNotFound).
+					Return(true).
+					Times(1)
+			} else {
+				bmdRepo.EXPECT().
+					Current(gomock.Any(), keyHash, int64(10)).
+					Return(bigmapdiff.BigMapState{Value: tt.prev}, nil).
+					Times(1)
+			}
+
+			tree, err := ast.NewScriptWithoutCode([]byte(script))
+			require.NoError(t, err)
+
+			tx := &operation.Operation{
+				Level:  100,
+				Status: types.OperationStatusApplied,
+				Tags:   types.FA2Tag | types.LedgerTag,
+				Destination: account.Account{
+					Address: contractAddress,
+					Type:    types.AccountTypeContract,
+				},
+				Entrypoint:      types.NullString{Str: "mint", Valid: true},
+				AST:             tree,
+				DeffatedStorage: []byte(`{"int":"10"}`),
+				BigMapDiffs: []*bigmapdiff.BigMapDiff{
+					{
+						Ptr:     10,
+						KeyHash: keyHash,
+						Key:     []byte(key),
+						Value:   tt.value,
+					},
+				},
+			}
+
+			store := parsers.NewTestStore()
+			err = NewTokenTransferParser(bmdRepo, generalRepo).Parse(context.Background(), tx, store)
+			require.NoError(t, err)
+
+			if tt.wantAmount == "" {
+				require.Empty(t, tx.TokenTransfers)
+				return
+			}
+
+			require.Len(t, tx.TokenTransfers, 1)
+			transfer := tx.TokenTransfers[0]
+			require.Equal(t, contractAddress, transfer.Contract.Address)
+			require.Equal(t, tt.wantFrom, transfer.From.Address)
+			require.Equal(t, tt.wantTo, transfer.To.Address)
+			require.Equal(t, "3", transfer.TokenId.String())
+			require.Equal(t, tt.wantAmount, transfer.Amount.String())
+			require.Len(t, store.TokenBalances, 1)
+		})
+	}
+}
+
+func TestTokenTransferParser_FA12Balances(t *testing.T) {
+	const (
+		contractAddress = "KT1PWx2mnDueood7fEmfbBDKx1D9BAnnXitn"
+		owner           = "tz1aCzsYRUgDZBV7zb7Si6q2AobrocFW5qwb"
+		script          = `[{"prim":"parameter","args":[{"prim":"or","args":[{"prim":"pair","args":[{"prim":"address"},{"prim":"nat"}],"annots":["%mint"]},{"prim":"pair","args":[{"prim":"address"},{"prim":"nat"}],"annots":["%burn"]}]}]},{"prim":"storage","args":[{"prim":"pair","args":[{"prim":"big_map","args":[{"prim":"address"},{"prim":"pair","args":[{"prim":"nat","annots":["%balance"]},{"prim":"map","args":[{"prim":"address"},{"prim":"nat"}],"annots":["%approvals"]}]}],"annots":["%balances"]},{"prim":"nat","annots":["%totalSupply"]}]}]}]`
+		keyHash         = "exprtfKNhZ1G8vMscchFjt1G1qww2P93VTLHMuhyThVYygZLdnRev2"
+		key             = `{"string":"tz1aCzsYRUgDZBV7zb7Si6q2AobrocFW5qwb"}`
+	)
+
+	tests := []struct {
+		name       string
+		prev       []byte
+		value      []byte
+		wantFrom   string
+		wantTo     string
+		wantAmount string
+	}{
+		{
+			name:       "mint",
+			prev:       []byte(`{"prim":"Pair","args":[{"int":"100"},[]]}`),
+			value:      []byte(`{"prim":"Pair","args":[{"int":"150"},[]]}`),
+			wantTo:     owner,
+			wantAmount: "50",
+		}, {
+			name:       "burn",
+			prev:       []byte(`{"prim":"Pair","args":[{"int":"100"},[]]}`),
+			value:      []byte(`{"prim":"Pair","args":[{"int":"40"},[]]}`),
+			wantFrom:   owner,
+			wantAmount: "60",
+		}, {
+			name:  "approve",
+			prev:  []byte(`{"prim":"Pair","args":[{"int":"100"},[]]}`),
+			value: []byte(`{"prim":"Pair","args":[{"int":"100"},[{"prim":"Elt","args":[{"string":"tz1aCzsYRUgDZBV7zb7Si6q2AobrocFW5qwb"},{"int":"5"}]}]]}`),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			generalRepo := mock.NewMockGeneralRepository(ctrl)
+			bmdRepo := mock_bmd.NewMockRepository(ctrl)
+			bmdRepo.EXPECT().
+				Current(gomock.Any(), keyHash, int64(10)).
+				Return(bigmapdiff.BigMapState{Value: tt.prev}, nil).
+				Times(1)
+
+			tree, err := ast.NewScriptWithoutCode([]byte(script))
+			require.NoError(t, err)
+
+			tx := &operation.Operation{
+				Level:  100,
+				Status: types.OperationStatusApplied,
+				Tags:   types.FA12Tag,
+				Destination: account.Account{
+					Address: contractAddress,
+					Type:    types.AccountTypeContract,
+				},
+				Entrypoint:      types.NullString{Str: tt.name, Valid: true},
+				AST:             tree,
+				DeffatedStorage: []byte(`{"prim":"Pair","args":[{"int":"10"},{"int":"1000"}]}`),
+				BigMapDiffs: []*bigmapdiff.BigMapDiff{
+					{
+						Ptr:     10,
+						KeyHash: keyHash,
+						Key:     []byte(key),
+						Value:   tt.value,
+					},
+				},
+			}
+
+			store := parsers.NewTestStore()
+			err = NewTokenTransferParser(bmdRepo, generalRepo).Parse(context.Background(), tx, store)
+			require.NoError(t, err)
+
+			if tt.wantAmount == "" {
+				require.Empty(t, tx.TokenTransfers)
+				return
+			}
+
+			require.Len(t, tx.TokenTransfers, 1)
+			transfer := tx.TokenTransfers[0]
+			require.Equal(t, tt.wantFrom, transfer.From.Address)
+			require.Equal(t, tt.wantTo, transfer.To.Address)
+			require.True(t, transfer.TokenId.IsZero())
+			require.Equal(t, tt.wantAmount, transfer.Amount.String())
+		})
+	}
+}
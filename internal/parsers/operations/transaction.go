@@ -226,6 +226,10 @@ func (p Transaction) appliedHandler(ctx context.Context, item noderpc.Operation,
 		return err
 	}
 
+	if err := NewTokenTransferParser(p.ctx.BigMapDiffs, p.ctx.Storage).Parse(ctx, tx, store); err != nil {
+		return errors.Wrap(err, "token transfers")
+	}
+
 	return NewMigration(p.ctx.Contracts).Parse(ctx, item, tx, p.protocol.Hash, store)
 }
 
@@ -12,6 +12,7 @@ import (
 	"github.com/baking-bad/bcdhub/internal/models/operation"
 	smartrollup "github.com/baking-bad/bcdhub/internal/models/smart_rollup"
 	"github.com/baking-bad/bcdhub/internal/models/ticket"
+	"github.com/baking-bad/bcdhub/internal/models/token"
 	accountTypes "github.com/baking-bad/bcdhub/internal/models/types"
 )
 
@@ -23,8 +24,15 @@ type Store interface {
 	AddOperations(operations ...*operation.Operation)
 	AddGlobalConstants(constants ...*contract.GlobalConstant)
 	AddSmartRollups(rollups ...*smartrollup.SmartRollup)
+	AddSmartRollupCommitments(commitments ...*smartrollup.Commitment)
+	AddSmartRollupCementations(cementations ...*smartrollup.Cementation)
+	AddSmartRollupRefutations(refutations ...*smartrollup.Refutation)
+	AddSmartRollupBondRecoveries(recoveries ...*smartrollup.BondRecovery)
+	AddSmartRollupOutboxMessages(messages ...*smartrollup.OutboxMessage)
+	AddSmartRollupInboxMessages(messages ...*smartrollup.InboxMessage)
 	AddTickets(tickets ...ticket.Ticket)
 	AddTicketBalances(balances ...ticket.Balance)
+	AddTokenBalances(balances ...token.Balance)
 	ListContracts() []*contract.Contract
 	ListOperations() []*operation.Operation
 	AddAccounts(accounts ...account.Account)
@@ -35,30 +43,44 @@ type Store interface {
 
 // TestStore -
 type TestStore struct {
-	Block           *block.Block
-	BigMapState     []*bigmapdiff.BigMapState
-	Contracts       []*contract.Contract
-	Migrations      []*migration.Migration
-	Operations      []*operation.Operation
-	GlobalConstants []*contract.GlobalConstant
-	SmartRollups    []*smartrollup.SmartRollup
-	Tickets         map[string]*ticket.Ticket
-	TicketBalances  map[string]*ticket.Balance
-	Accounts        map[string]*account.Account
+	Block                     *block.Block
+	BigMapState               []*bigmapdiff.BigMapState
+	Contracts                 []*contract.Contract
+	Migrations                []*migration.Migration
+	Operations                []*operation.Operation
+	GlobalConstants           []*contract.GlobalConstant
+	SmartRollups              []*smartrollup.SmartRollup
+	SmartRollupCommitments    []*smartrollup.Commitment
+	SmartRollupCementations   []*smartrollup.Cementation
+	SmartRollupRefutations    []*smartrollup.Refutation
+	SmartRollupBondRecoveries []*smartrollup.BondRecovery
+	SmartRollupOutboxMessages []*smartrollup.OutboxMessage
+	SmartRollupInboxMessages  []*smartrollup.InboxMessage
+	Tickets                   map[string]*ticket.Ticket
+	TicketBalances            map[string]*ticket.Balance
+	TokenBalances             map[string]*token.Balance
+	Accounts                  map[string]*account.Account
 }
 
 // NewTestStore -
 func NewTestStore() *TestStore {
 	return &TestStore{
-		BigMapState:     make([]*bigmapdiff.BigMapState, 0),
-		Contracts:       make([]*contract.Contract, 0),
-		Migrations:      make([]*migration.Migration, 0),
-		Operations:      make([]*operation.Operation, 0),
-		GlobalConstants: make([]*contract.GlobalConstant, 0),
-		SmartRollups:    make([]*smartrollup.SmartRollup, 0),
-		Tickets:         make(map[string]*ticket.Ticket, 0),
-		TicketBalances:  make(map[string]*ticket.Balance, 0),
-		Accounts:        make(map[string]*account.Account),
+		BigMapState:               make([]*bigmapdiff.BigMapState, 0),
+		Contracts:                 make([]*contract.Contract, 0),
+		Migrations:                make([]*migration.Migration, 0),
+		Operations:                make([]*operation.Operation, 0),
+		GlobalConstants:           make([]*contract.GlobalConstant, 0),
+		SmartRollups:              make([]*smartrollup.SmartRollup, 0),
+		SmartRollupCommitments:    make([]*smartrollup.Commitment, 0),
+		SmartRollupCementations:   make([]*smartrollup.Cementation, 0),
+		SmartRollupRefutations:    make([]*smartrollup.Refutation, 0),
+		SmartRollupBondRecoveries: make([]*smartrollup.BondRecovery, 0),
+		SmartRollupOutboxMessages: make([]*smartrollup.OutboxMessage, 0),
+		SmartRollupInboxMessages:  make([]*smartrollup.InboxMessage, 0),
+		Tickets:                   make(map[string]*ticket.Ticket, 0),
+		TicketBalances:            make(map[string]*ticket.Balance, 0),
+		TokenBalances:             make(map[string]*token.Balance, 0),
+		Accounts:                  make(map[string]*account.Account),
 	}
 }
 
@@ -92,6 +114,36 @@ func (store *TestStore) AddSmartRollups(rollups ...*smartrollup.SmartRollup) {
 	store.SmartRollups = append(store.SmartRollups, rollups...)
 }
 
+// AddSmartRollupCommitments -
+func (store *TestStore) AddSmartRollupCommitments(commitments ...*smartrollup.Commitment) {
+	store.SmartRollupCommitments = append(store.SmartRollupCommitments, commitments...)
+}
+
+// AddSmartRollupCementations -
+func (store *TestStore) AddSmartRollupCementations(cementations ...*smartrollup.Cementation) {
+	store.SmartRollupCementations = append(store.SmartRollupCementations, cementations...)
+}
+
+// AddSmartRollupRefutations -
+func (store *TestStore) AddSmartRollupRefutations(refutations ...*smartrollup.Refutation) {
+	store.SmartRollupRefutations = append(store.SmartRollupRefutations, refutations...)
+}
+
+// AddSmartRollupBondRecoveries -
+func (store *TestStore) AddSmartRollupBondRecoveries(recoveries ...*smartrollup.BondRecovery) {
+	store.SmartRollupBondRecoveries = append(store.SmartRollupBondRecoveries, recoveries...)
+}
+
+// AddSmartRollupOutboxMessages -
+func (store *TestStore) AddSmartRollupOutboxMessages(messages ...*smartrollup.OutboxMessage) {
+	store.SmartRollupOutboxMessages = append(store.SmartRollupOutboxMessages, messages...)
+}
+
+// AddSmartRollupInboxMessages -
+func (store *TestStore) AddSmartRollupInboxMessages(messages ...*smartrollup.InboxMessage) {
+	store.SmartRollupInboxMessages = append(store.SmartRollupInboxMessages, messages...)
+}
+
 // AddAccounts -
 func (store *TestStore) AddAccounts(accounts ...account.Account) {
 	for i := range accounts {
@@ -140,6 +192,18 @@ func (store *TestStore) AddTicketBalances(balance ...ticket.Balance) {
 	}
 }
 
+// AddTokenBalances -
+func (store *TestStore) AddTokenBalances(balance ...token.Balance) {
+	for i := range balance {
+		key := balance[i].String()
+		if b, ok := store.TokenBalances[key]; !ok {
+			store.TokenBalances[key] = &balance[i]
+		} else {
+			b.Amount = b.Amount.Add(balance[i].Amount)
+		}
+	}
+}
+
 // ListContracts -
 func (store *TestStore) ListContracts() []*contract.Contract {
 	return store.Contracts
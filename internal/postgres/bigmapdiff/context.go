@@ -35,8 +35,18 @@ func (storage *Storage) buildGetContext(ctx bigmapdiff.GetContext) *bun.SelectQu
 }
 
 func (storage *Storage) buildGetContextForState(ctx bigmapdiff.GetContext) *bun.SelectQuery {
-	query := storage.DB.NewSelect().Model((*bigmapdiff.BigMapState)(nil))
+	query := stateFilters(storage.DB.NewSelect().Model((*bigmapdiff.BigMapState)(nil)), ctx)
 
+	query.Limit(storage.GetPageSize(ctx.Size))
+
+	if ctx.Offset > 0 {
+		query.Offset(int(ctx.Offset))
+	}
+
+	return query.Order("id desc")
+}
+
+func stateFilters(query *bun.SelectQuery, ctx bigmapdiff.GetContext) *bun.SelectQuery {
 	if ctx.Contract != "" {
 		query.Where("contract = ?", ctx.Contract)
 	}
@@ -52,12 +62,5 @@ func (storage *Storage) buildGetContextForState(ctx bigmapdiff.GetContext) *bun.
 	if ctx.CurrentLevel != nil {
 		query.Where("last_update_level = ?", *ctx.CurrentLevel)
 	}
-
-	query.Limit(storage.GetPageSize(ctx.Size))
-
-	if ctx.Offset > 0 {
-		query.Offset(int(ctx.Offset))
-	}
-
-	return query.Order("id desc")
+	return query
 }
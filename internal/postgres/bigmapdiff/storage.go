@@ -4,7 +4,9 @@ import (
 	"context"
 	"database/sql"
 
+	"github.com/baking-bad/bcdhub/internal/models/bigmapaction"
 	"github.com/baking-bad/bcdhub/internal/models/bigmapdiff"
+	"github.com/baking-bad/bcdhub/internal/models/types"
 	"github.com/baking-bad/bcdhub/internal/postgres/consts"
 	"github.com/baking-bad/bcdhub/internal/postgres/core"
 	"github.com/pkg/errors"
@@ -42,6 +44,23 @@ func (storage *Storage) GetForAddress(ctx context.Context, address string) (resp
 	return
 }
 
+// GetAtLevel - returns the last diff of every key of contract's big maps `ptrs` made at or before `level`. Diff with empty value means that key was removed.
+func (storage *Storage) GetAtLevel(ctx context.Context, contract string, level int64, ptrs ...int64) (response []bigmapdiff.BigMapDiff, err error) {
+	if len(ptrs) == 0 {
+		return nil, nil
+	}
+
+	query := storage.DB.NewSelect().
+		Model(&response).
+		DistinctOn("ptr, key_hash").
+		Where("ptr IN (?)", bun.In(ptrs)).
+		Where("level <= ?", level).
+		Order("ptr", "key_hash").
+		OrderExpr("level desc, id desc")
+	err = core.Contract(query, contract).Scan(ctx)
+	return
+}
+
 // Count -
 func (storage *Storage) Count(ctx context.Context, ptr int64) (int, error) {
 	return storage.DB.NewSelect().
@@ -181,3 +200,58 @@ func (storage *Storage) Keys(ctx context.Context, req bigmapdiff.GetContext) (st
 	err = storage.buildGetContextForState(req).Scan(ctx, &states)
 	return
 }
+
+// ExportKeys - iterates over all big map keys matched to request in ascending order of id. `reqCtx.Size` is used as batch size, `reqCtx.Offset` is ignored.
+func (storage *Storage) ExportKeys(ctx context.Context, reqCtx bigmapdiff.GetContext, handler func(states []bigmapdiff.BigMapState) error) error {
+	return core.Iterate(ctx, int(reqCtx.Size), func(states *[]bigmapdiff.BigMapState) *bun.SelectQuery {
+		return stateFilters(storage.DB.NewSelect().Model(states), reqCtx)
+	}, handler)
+}
+
+// KeysAtLevel - returns big map keys as they were at `level`. States are built from the last diff of every key made at or before `level`.
+// Keys of the big map removed by `remove` action at or before `level` are marked as removed. Copied keys are stored as diffs of destination big map, so they are included too.
+func (storage *Storage) KeysAtLevel(ctx context.Context, req bigmapdiff.GetContext, level int64) (states []bigmapdiff.BigMapState, err error) {
+	last := storage.DB.NewSelect().
+		Model((*bigmapdiff.BigMapDiff)(nil)).
+		ColumnExpr("DISTINCT ON (ptr, key_hash) id, ptr, key_hash, contract, key, value, level, timestamp").
+		ColumnExpr("count(*) OVER (PARTITION BY ptr, key_hash) AS count").
+		Where("level <= ?", level).
+		OrderExpr("ptr, key_hash, level desc, id desc")
+	if req.Ptr != nil {
+		last.Where("ptr = ?", *req.Ptr)
+	}
+	if req.Contract != "" {
+		last.Where("contract = ?", req.Contract)
+	}
+
+	removed := storage.DB.NewSelect().
+		Model((*bigmapaction.BigMapAction)(nil)).
+		ColumnExpr("1").
+		Where("big_map_action.source_ptr = diff.ptr").
+		Where("big_map_action.action = ?", types.BigMapActionRemove).
+		Where("big_map_action.level <= ?", level)
+
+	query := storage.DB.NewSelect().
+		TableExpr("(?) AS diff", last).
+		ColumnExpr("diff.id, diff.ptr, diff.key_hash, diff.contract, diff.key, diff.value, diff.count").
+		ColumnExpr("diff.level AS last_update_level, diff.timestamp AS last_update_time").
+		ColumnExpr("(coalesce(length(diff.value), 0) = 0 OR EXISTS (?)) AS removed", removed)
+
+	if req.MaxLevel != nil {
+		query.Where("diff.level < ?", *req.MaxLevel)
+	}
+	if req.MinLevel != nil {
+		query.Where("diff.level >= ?", *req.MinLevel)
+	}
+	if req.CurrentLevel != nil {
+		query.Where("diff.level = ?", *req.CurrentLevel)
+	}
+
+	query.Limit(storage.GetPageSize(req.Size))
+	if req.Offset > 0 {
+		query.Offset(int(req.Offset))
+	}
+
+	err = query.OrderExpr("diff.id desc").Scan(ctx, &states)
+	return
+}
@@ -0,0 +1,65 @@
+package contractmetadata
+
+import (
+	"context"
+
+	"github.com/baking-bad/bcdhub/internal/bcd/ast"
+	"github.com/baking-bad/bcdhub/internal/bcd/base"
+	"github.com/baking-bad/bcdhub/internal/models/bigmapdiff"
+	contractmetadata "github.com/baking-bad/bcdhub/internal/models/contract_metadata"
+	"github.com/baking-bad/bcdhub/internal/postgres/core"
+)
+
+// Storage -
+type Storage struct {
+	*core.Postgres
+}
+
+// NewStorage -
+func NewStorage(pg *core.Postgres) *Storage {
+	return &Storage{pg}
+}
+
+// Get -
+func (storage *Storage) Get(ctx context.Context, address string) (response contractmetadata.ContractMetadata, err error) {
+	err = storage.DB.NewSelect().
+		Model(&response).
+		Where("address = ?", address).
+		Order("level desc").
+		Limit(1).
+		Scan(ctx)
+	return
+}
+
+// Unresolved - metadata big maps are recognized by the empty key which keeps the root URI of TZIP-16 metadata
+func (storage *Storage) Unresolved(ctx context.Context, limit, offset int) (updates []contractmetadata.Update, err error) {
+	var empty string
+	rootKeyHash, err := ast.BigMapKeyHash(&base.Node{StringValue: &empty})
+	if err != nil {
+		return nil, err
+	}
+
+	metadataPtrs := storage.DB.NewSelect().
+		Model((*bigmapdiff.BigMapState)(nil)).
+		Column("ptr").
+		Where("key_hash = ?", rootKeyHash).
+		Where("removed = false")
+
+	resolvedLevel := storage.DB.NewSelect().
+		Model((*contractmetadata.ContractMetadata)(nil)).
+		ColumnExpr("max(level)").
+		Where("address = big_map_state.contract")
+
+	err = storage.DB.NewSelect().
+		Model((*bigmapdiff.BigMapState)(nil)).
+		ColumnExpr("contract AS address, ptr").
+		ColumnExpr("max(last_update_level) AS level, max(last_update_time) AS timestamp").
+		Where("ptr IN (?)", metadataPtrs).
+		Group("contract", "ptr").
+		Having("max(last_update_level) > coalesce((?), 0)", resolvedLevel).
+		Order("level", "address").
+		Limit(limit).
+		Offset(offset).
+		Scan(ctx, &updates)
+	return
+}
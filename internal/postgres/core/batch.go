@@ -0,0 +1,87 @@
+package core
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/baking-bad/bcdhub/internal/metrics"
+	"github.com/pkg/errors"
+	"github.com/uptrace/bun"
+)
+
+// Batch - database transaction which spans several blocks during bulk sync.
+// Every block is saved in its own savepoint of the batch, so data of previous blocks is visible to parsers of the next ones.
+// Append-only tables (operations and their children) are written with COPY instead of INSERT.
+type Batch struct {
+	conn  bun.Conn
+	tx    bun.Tx
+	start time.Time
+}
+
+// NewBatch - opens dedicated connection and begins batch transaction on it.
+// Batch isn't bound to `ctx`: it's finished only by `Commit` or `Rollback`.
+func (p *Postgres) NewBatch(ctx context.Context) (*Batch, error) {
+	conn, err := p.db.Conn(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "acquire connection")
+	}
+	tx, err := conn.BeginTx(context.WithoutCancel(ctx), nil)
+	if err != nil {
+		_ = conn.Close()
+		return nil, errors.Wrap(err, "begin batch")
+	}
+	return &Batch{
+		conn:  conn,
+		tx:    tx,
+		start: time.Now(),
+	}, nil
+}
+
+// DB - returns database handle which reads and writes inside the batch
+func (b *Batch) DB() bun.IDB {
+	return b.tx
+}
+
+// Begin - begins transaction of one block inside the batch
+func (b *Batch) Begin(ctx context.Context) (Transaction, error) {
+	tx, err := b.tx.BeginTx(ctx, nil)
+	if err != nil {
+		return Transaction{}, err
+	}
+	return Transaction{tx: tx, start: time.Now(), batch: b}, nil
+}
+
+// Commit -
+func (b *Batch) Commit() error {
+	err := b.tx.Commit()
+	if closeErr := b.conn.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		metrics.ObserveSince(metrics.PostgresTransactionDuration.WithLabelValues(metrics.TransactionKindBatch, metrics.TransactionResultCommit), b.start)
+	}
+	return err
+}
+
+// Rollback -
+func (b *Batch) Rollback() error {
+	err := b.tx.Rollback()
+	if !errors.Is(err, sql.ErrTxDone) {
+		metrics.ObserveSince(metrics.PostgresTransactionDuration.WithLabelValues(metrics.TransactionKindBatch, metrics.TransactionResultRollback), b.start)
+	}
+	if closeErr := b.conn.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// nextIds - reserves `count` values of `id` sequence of the table
+func (b *Batch) nextIds(ctx context.Context, table string, count int) ([]int64, error) {
+	ids := make([]int64, 0, count)
+	err := b.tx.NewRaw(
+		"SELECT nextval(pg_get_serial_sequence(?, 'id')) FROM generate_series(1, ?)",
+		table, count,
+	).Scan(ctx, &ids)
+	return ids, err
+}
@@ -0,0 +1,155 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"database/sql/driver"
+	"encoding/hex"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/stdlib"
+	"github.com/pkg/errors"
+	"github.com/uptrace/bun/schema"
+)
+
+// copyModels - writes models to their table by `COPY ... FROM STDIN` in text format.
+// Autoincrement columns with zero value are skipped, so database fills them by default.
+func copyModels[M any](ctx context.Context, b *Batch, models []*M) error {
+	if len(models) == 0 {
+		return nil
+	}
+
+	table := b.tx.Dialect().Tables().Get(reflect.TypeFor[M]())
+	fields := copyFields(table, reflect.ValueOf(models[0]).Elem())
+
+	var buf bytes.Buffer
+	for i := range models {
+		if err := appendCopyRow(&buf, fields, reflect.ValueOf(models[i]).Elem()); err != nil {
+			return errors.Wrap(err, table.Name)
+		}
+	}
+
+	columns := make([]string, len(fields))
+	for i := range fields {
+		columns[i] = string(fields[i].SQLName)
+	}
+	query := fmt.Sprintf("COPY %s (%s) FROM STDIN", table.SQLName, strings.Join(columns, ", "))
+
+	return b.conn.Raw(func(driverConn any) error {
+		conn, ok := driverConn.(*stdlib.Conn)
+		if !ok {
+			return errors.Errorf("COPY isn't supported by driver connection %T", driverConn)
+		}
+		_, err := conn.Conn().PgConn().CopyFrom(ctx, &buf, query)
+		return err
+	})
+}
+
+func copyFields(table *schema.Table, first reflect.Value) []*schema.Field {
+	fields := make([]*schema.Field, 0, len(table.Fields))
+	for _, field := range table.Fields {
+		if field.AutoIncrement && field.HasZeroValue(first) {
+			continue
+		}
+		fields = append(fields, field)
+	}
+	return fields
+}
+
+func appendCopyRow(buf *bytes.Buffer, fields []*schema.Field, strct reflect.Value) error {
+	for i, field := range fields {
+		if i > 0 {
+			buf.WriteByte('\t')
+		}
+		if field.NullZero && field.HasZeroValue(strct) {
+			buf.WriteString(`\N`)
+			continue
+		}
+		if err := appendCopyValue(buf, field.Value(strct)); err != nil {
+			return errors.Wrap(err, field.Name)
+		}
+	}
+	buf.WriteByte('\n')
+	return nil
+}
+
+var timeType = reflect.TypeFor[time.Time]()
+
+func appendCopyValue(buf *bytes.Buffer, value reflect.Value) error {
+	if value.Kind() == reflect.Pointer {
+		if value.IsNil() {
+			buf.WriteString(`\N`)
+			return nil
+		}
+		value = value.Elem()
+	}
+
+	if valuer, ok := value.Interface().(driver.Valuer); ok {
+		v, err := valuer.Value()
+		if err != nil {
+			return err
+		}
+		if v == nil {
+			buf.WriteString(`\N`)
+			return nil
+		}
+		return appendCopyValue(buf, reflect.ValueOf(v))
+	}
+
+	if value.Type() == timeType {
+		buf.WriteString(value.Interface().(time.Time).UTC().Format(time.RFC3339Nano))
+		return nil
+	}
+
+	switch value.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		buf.WriteString(strconv.FormatInt(value.Int(), 10))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		buf.WriteString(strconv.FormatUint(value.Uint(), 10))
+	case reflect.Float32, reflect.Float64:
+		buf.WriteString(strconv.FormatFloat(value.Float(), 'g', -1, 64))
+	case reflect.Bool:
+		if value.Bool() {
+			buf.WriteByte('t')
+		} else {
+			buf.WriteByte('f')
+		}
+	case reflect.String:
+		appendCopyString(buf, value.String())
+	case reflect.Slice:
+		if value.Type().Elem().Kind() != reflect.Uint8 {
+			return errors.Errorf("unsupported type of COPY value: %s", value.Type())
+		}
+		if value.IsNil() {
+			buf.WriteString(`\N`)
+			return nil
+		}
+		// bytea in hex format with escaped backslash
+		buf.WriteString(`\\x`)
+		buf.WriteString(hex.EncodeToString(value.Bytes()))
+	default:
+		return errors.Errorf("unsupported type of COPY value: %s", value.Type())
+	}
+	return nil
+}
+
+func appendCopyString(buf *bytes.Buffer, s string) {
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; c {
+		case '\\':
+			buf.WriteString(`\\`)
+		case '\t':
+			buf.WriteString(`\t`)
+		case '\n':
+			buf.WriteString(`\n`)
+		case '\r':
+			buf.WriteString(`\r`)
+		default:
+			buf.WriteByte(c)
+		}
+	}
+}
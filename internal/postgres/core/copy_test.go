@@ -0,0 +1,123 @@
+package core
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/baking-bad/bcdhub/internal/bcd/tezerrors"
+	"github.com/baking-bad/bcdhub/internal/models/bigmapdiff"
+	"github.com/baking-bad/bcdhub/internal/models/types"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/require"
+	"github.com/uptrace/bun/dialect/pgdialect"
+)
+
+func Test_appendCopyValue(t *testing.T) {
+	var nilInt *int64
+	nonce := int64(7)
+
+	tests := []struct {
+		name  string
+		value any
+		want  string
+	}{
+		{
+			name:  "int",
+			value: int64(-42),
+			want:  "-42",
+		}, {
+			name:  "enum",
+			value: types.OperationStatusApplied,
+			want:  "1",
+		}, {
+			name:  "bool",
+			value: true,
+			want:  "t",
+		}, {
+			name:  "string with special symbols",
+			value: "a\tb\nc\\d\re",
+			want:  `a\tb\nc\\d\re`,
+		}, {
+			name:  "bytes",
+			value: []byte{0x0a, 0xff},
+			want:  `\\x0aff`,
+		}, {
+			name:  "nil bytes",
+			value: types.Bytes(nil),
+			want:  `\N`,
+		}, {
+			name:  "time",
+			value: time.Date(2024, 1, 2, 3, 4, 5, 6000, time.FixedZone("test", 3600)),
+			want:  "2024-01-02T02:04:05.000006Z",
+		}, {
+			name:  "nil pointer",
+			value: nilInt,
+			want:  `\N`,
+		}, {
+			name:  "pointer",
+			value: &nonce,
+			want:  "7",
+		}, {
+			name:  "valid null string",
+			value: types.NewNullString(&[]string{"default"}[0]),
+			want:  "default",
+		}, {
+			name:  "invalid null string",
+			value: types.NullString{},
+			want:  `\N`,
+		}, {
+			name:  "decimal",
+			value: decimal.RequireFromString("100000000000000000000"),
+			want:  "100000000000000000000",
+		}, {
+			name:  "nil errors",
+			value: tezerrors.Errors(nil),
+			want:  `\N`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			err := appendCopyValue(&buf, reflect.ValueOf(tt.value))
+			require.NoError(t, err)
+			require.Equal(t, tt.want, buf.String())
+		})
+	}
+}
+
+func Test_appendCopyValue_unsupported(t *testing.T) {
+	var buf bytes.Buffer
+	err := appendCopyValue(&buf, reflect.ValueOf([]int64{1}))
+	require.Error(t, err)
+}
+
+func Test_appendCopyRow(t *testing.T) {
+	table := pgdialect.New().Tables().Get(reflect.TypeFor[bigmapdiff.BigMapDiff]())
+	diff := bigmapdiff.BigMapDiff{
+		Ptr:         10,
+		Key:         types.Bytes{0x01},
+		KeyHash:     "expru",
+		Level:       100,
+		Contract:    "KT1",
+		Timestamp:   time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		ProtocolID:  2,
+		OperationID: 3,
+	}
+	value := reflect.ValueOf(&diff).Elem()
+
+	fields := copyFields(table, value)
+	columns := make([]string, len(fields))
+	for i := range fields {
+		columns[i] = fields[i].Name
+	}
+	require.Equal(t, []string{"ptr", "key", "key_hash", "value", "level", "contract", "timestamp", "protocol_id", "operation_id"}, columns)
+
+	var buf bytes.Buffer
+	require.NoError(t, appendCopyRow(&buf, fields, value))
+	require.Equal(t, "10\t\\\\x01\texpru\t\\N\t100\tKT1\t2024-01-02T03:04:05Z\t2\t3\n", buf.String())
+
+	diff.ID = 5
+	require.Len(t, copyFields(table, value), len(fields)+1)
+}
@@ -0,0 +1,53 @@
+package core
+
+import (
+	"context"
+
+	"github.com/uptrace/bun"
+)
+
+// DefaultBatchSize - default count of rows which is requested by cursor at once
+const DefaultBatchSize = 1000
+
+// Identifiable - pointer to model which has primary key `id`
+type Identifiable[M any] interface {
+	*M
+	GetID() int64
+}
+
+// Iterate - requests rows by batches using `id` as cursor and passes every batch to `handler`.
+// `newQuery` has to return select query with model set to passed slice and with filters applied. Order and limit are set by cursor.
+// Every batch is received by separate query, so big result sets are neither loaded into memory nor hold connection for a long time.
+func Iterate[M any, PM Identifiable[M]](ctx context.Context, batchSize int, newQuery func(items *[]M) *bun.SelectQuery, handler func(items []M) error) error {
+	if batchSize <= 0 {
+		batchSize = DefaultBatchSize
+	}
+
+	var lastID int64
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+		}
+
+		items := make([]M, 0, batchSize)
+		query := newQuery(&items)
+		if lastID > 0 {
+			query.Where("?TableAlias.id > ?", lastID)
+		}
+		if err := query.OrderExpr("?TableAlias.id asc").Limit(batchSize).Scan(ctx); err != nil {
+			return err
+		}
+		if len(items) == 0 {
+			return nil
+		}
+		if err := handler(items); err != nil {
+			return err
+		}
+		if len(items) < batchSize {
+			return nil
+		}
+		lastID = PM(&items[len(items)-1]).GetID()
+	}
+}
@@ -4,6 +4,9 @@ import (
 	"context"
 
 	"github.com/baking-bad/bcdhub/internal/models"
+	"github.com/baking-bad/bcdhub/internal/models/operation"
+	"github.com/baking-bad/bcdhub/internal/models/types"
+	"github.com/uptrace/bun"
 )
 
 // GetByID -
@@ -11,3 +14,15 @@ func (p *Postgres) GetByID(ctx context.Context, output models.Model) error {
 	err := p.DB.NewSelect().Model(output).Where("id = ?", output.GetID()).Scan(ctx)
 	return err
 }
+
+// CountersForAccount - calculates counters of account by its operations. Operation is counted twice if account is both its source and destination.
+func CountersForAccount(ctx context.Context, db bun.IDB, accountID int64) (counters operation.AccountCounters, err error) {
+	err = db.NewSelect().
+		Model((*operation.Operation)(nil)).
+		ColumnExpr("count(*) filter (where source_id = ?) + count(*) filter (where destination_id = ?) as operations_count", accountID, accountID).
+		ColumnExpr("count(*) filter (where source_id = ? and kind = ?) as events_count", accountID, types.OperationKindEvent).
+		WhereOr("source_id = ?", accountID).
+		WhereOr("destination_id = ?", accountID).
+		Scan(ctx, &counters)
+	return
+}
@@ -20,6 +20,18 @@ func (p *Postgres) CreateIndex(ctx context.Context, name, columns string, model
 	return err
 }
 
+// CreateGinIndex - creates GIN index. It's used for trigram (`public.gin_trgm_ops`) lookups.
+func (p *Postgres) CreateGinIndex(ctx context.Context, name, columns string, model any) error {
+	_, err := p.DB.NewCreateIndex().
+		Model(model).
+		IfNotExists().
+		Index(name).
+		Using("GIN").
+		ColumnExpr(columns).
+		Exec(ctx)
+	return err
+}
+
 func createBaseIndices(ctx context.Context, db bun.IDB) error {
 	return db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
 		// Blocks
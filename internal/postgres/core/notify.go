@@ -0,0 +1,69 @@
+package core
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/rs/zerolog/log"
+)
+
+const reconnectTimeout = 5 * time.Second
+
+// Notify - sends notification with payload to the channel
+func (p *Postgres) Notify(ctx context.Context, channel, payload string) error {
+	_, err := p.DB.ExecContext(ctx, "SELECT pg_notify(?, ?)", channel, payload)
+	return err
+}
+
+// Listen - subscribes on the channel notifications. Payloads are sent to the returned channel until context is done.
+// Listen uses dedicated connection and reconnects to the database if connection was lost.
+func (p *Postgres) Listen(ctx context.Context, channel string) <-chan string {
+	output := make(chan string, 1024)
+
+	go func() {
+		defer close(output)
+
+		for {
+			if err := p.listen(ctx, channel, output); err != nil {
+				log.Err(err).Str("channel", channel).Msg("listen postgres notifications")
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(reconnectTimeout):
+			}
+		}
+	}()
+
+	return output
+}
+
+func (p *Postgres) listen(ctx context.Context, channel string, output chan<- string) error {
+	conn, err := pgx.ConnectConfig(ctx, p.connConfig)
+	if err != nil {
+		return err
+	}
+	defer conn.Close(context.Background())
+
+	if _, err := conn.Exec(ctx, "LISTEN "+pgx.Identifier{channel}.Sanitize()); err != nil {
+		return err
+	}
+
+	for {
+		notification, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case output <- notification.Payload:
+		}
+	}
+}
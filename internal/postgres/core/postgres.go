@@ -20,8 +20,10 @@ import (
 
 // Postgres -
 type Postgres struct {
-	DB   *bun.DB
-	conn *sql.DB
+	DB         bun.IDB
+	db         *bun.DB
+	conn       *sql.DB
+	connConfig *pgx.ConnConfig
 
 	PageSize int64
 
@@ -93,19 +95,21 @@ func New(cfg Config, schemaName, appName string, opts ...PostgresOption) (*Postg
 	pgxConfig.ConnectTimeout = postgres.timeout
 	pgxConfig.RuntimeParams["statement_timeout"] = strconv.Itoa(int(postgres.timeout / time.Millisecond))
 
+	postgres.connConfig = pgxConfig
 	postgres.conn = stdlib.OpenDB(*pgxConfig)
-	postgres.DB = bun.NewDB(postgres.conn, pgdialect.New())
+	postgres.db = bun.NewDB(postgres.conn, pgdialect.New())
+	postgres.DB = postgres.db
 
 	maxOpenConns := 4 * runtime.GOMAXPROCS(0)
 	postgres.conn.SetMaxOpenConns(maxOpenConns)
 	postgres.conn.SetMaxIdleConns(maxOpenConns)
 
 	if postgres.hasLogger {
-		postgres.DB.AddQueryHook(&logQueryHook{})
+		postgres.db.AddQueryHook(&logQueryHook{})
 	}
 
 	// register many-to-many relationships
-	postgres.DB.RegisterModel(models.ManyToMany()...)
+	postgres.db.RegisterModel(models.ManyToMany()...)
 
 	return &postgres, nil
 }
@@ -131,7 +135,7 @@ func WaitNew(ctx context.Context, cfg Config, schemaName, appName string, timeou
 		}
 	}
 
-	for err := db.DB.PingContext(ctx); err != nil; err = db.DB.PingContext(ctx) {
+	for err := db.conn.PingContext(ctx); err != nil; err = db.conn.PingContext(ctx) {
 		log.Warn().Msgf("Waiting postgres up %d seconds...", timeout)
 		time.Sleep(time.Second * time.Duration(timeout))
 	}
@@ -140,11 +144,11 @@ func WaitNew(ctx context.Context, cfg Config, schemaName, appName string, timeou
 }
 
 func (p *Postgres) InitDatabase(ctx context.Context) error {
-	if err := createSchema(ctx, p.DB, p.schema); err != nil {
+	if err := createSchema(ctx, p.db, p.schema); err != nil {
 		return err
 	}
 
-	if err := createTables(ctx, p.DB); err != nil {
+	if err := createTables(ctx, p.db); err != nil {
 		return err
 	}
 
@@ -160,6 +164,14 @@ func (p *Postgres) Close() error {
 	return p.conn.Close()
 }
 
+// WithDB - returns copy of storage which executes queries through `db`, e.g. inside a transaction.
+// The copy shares connection pool with the origin, so only the origin should be closed.
+func (p *Postgres) WithDB(db bun.IDB) *Postgres {
+	clone := *p
+	clone.DB = db
+	return &clone
+}
+
 // IsRecordNotFound -
 func (p *Postgres) IsRecordNotFound(err error) bool {
 	return err != nil && errors.Is(err, sql.ErrNoRows)
@@ -167,6 +179,6 @@ func (p *Postgres) IsRecordNotFound(err error) bool {
 
 // Execute -
 func (p *Postgres) Execute(rawSQL string) error {
-	_, err := p.DB.Exec(rawSQL)
+	_, err := p.DB.ExecContext(context.Background(), rawSQL)
 	return err
 }
@@ -12,6 +12,7 @@ import (
 	"github.com/baking-bad/bcdhub/internal/models/migration"
 	"github.com/baking-bad/bcdhub/internal/models/operation"
 	"github.com/baking-bad/bcdhub/internal/models/ticket"
+	"github.com/baking-bad/bcdhub/internal/models/token"
 	"github.com/rs/zerolog/log"
 	"github.com/uptrace/bun"
 )
@@ -48,6 +49,7 @@ func createHypertables(ctx context.Context, db *bun.DB) error {
 		&migration.Migration{},
 		&operation.Operation{},
 		&ticket.TicketUpdate{},
+		&token.Transfer{},
 	} {
 		if _, err := db.ExecContext(ctx,
 			`SELECT public.create_hypertable(?, 'timestamp', chunk_time_interval => INTERVAL '1 month', if_not_exists => TRUE);`,
@@ -74,6 +76,14 @@ func createSchema(ctx context.Context, db *bun.DB, schemaName string) error {
 		if _, err := db.NewRaw("CREATE EXTENSION IF NOT EXISTS timescaledb;").Exec(ctx); err != nil {
 			log.Err(err).Msg("create timescale extension")
 		}
+		if _, err := db.NewRaw("CREATE EXTENSION IF NOT EXISTS pg_trgm;").Exec(ctx); err != nil {
+			log.Err(err).Msg("create pg_trgm extension")
+		}
+		// array_to_string isn't immutable, so it can't be used in index expression
+		if _, err := db.NewRaw(`CREATE OR REPLACE FUNCTION public.immutable_array_to_string(text[]) RETURNS text
+			LANGUAGE sql IMMUTABLE PARALLEL SAFE AS $$SELECT array_to_string($1, ' ')$$;`).Exec(ctx); err != nil {
+			log.Err(err).Msg("create immutable_array_to_string function")
+		}
 	})
 
 	wg.Wait()
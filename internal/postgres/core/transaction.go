@@ -2,41 +2,57 @@ package core
 
 import (
 	"context"
+	"database/sql"
+	"errors"
+	"time"
 
+	"github.com/baking-bad/bcdhub/internal/metrics"
 	"github.com/baking-bad/bcdhub/internal/models/account"
 	"github.com/baking-bad/bcdhub/internal/models/bigmapaction"
 	"github.com/baking-bad/bcdhub/internal/models/bigmapdiff"
 	"github.com/baking-bad/bcdhub/internal/models/block"
 	"github.com/baking-bad/bcdhub/internal/models/contract"
+	contractmetadata "github.com/baking-bad/bcdhub/internal/models/contract_metadata"
 	"github.com/baking-bad/bcdhub/internal/models/migration"
 	"github.com/baking-bad/bcdhub/internal/models/operation"
 	"github.com/baking-bad/bcdhub/internal/models/protocol"
 	smartrollup "github.com/baking-bad/bcdhub/internal/models/smart_rollup"
 	"github.com/baking-bad/bcdhub/internal/models/stats"
 	"github.com/baking-bad/bcdhub/internal/models/ticket"
+	"github.com/baking-bad/bcdhub/internal/models/token"
 	"github.com/baking-bad/bcdhub/internal/models/types"
 	"github.com/uptrace/bun"
 )
 
 type Transaction struct {
-	tx bun.Tx
+	tx    bun.Tx
+	start time.Time
+	batch *Batch
 }
 
 // NewTransaction -
-func NewTransaction(ctx context.Context, db *bun.DB) (Transaction, error) {
+func NewTransaction(ctx context.Context, db bun.IDB) (Transaction, error) {
 	tx, err := db.BeginTx(ctx, nil)
 	if err != nil {
 		return Transaction{}, err
 	}
-	return Transaction{tx}, nil
+	return Transaction{tx: tx, start: time.Now()}, nil
 }
 
 func (t Transaction) Commit() error {
+	if t.batch != nil {
+		return t.tx.Commit()
+	}
+	defer metrics.ObserveSince(metrics.PostgresTransactionDuration.WithLabelValues(metrics.TransactionKindSave, metrics.TransactionResultCommit), t.start)
 	return t.tx.Commit()
 }
 
 func (t Transaction) Rollback() error {
-	return t.tx.Rollback()
+	err := t.tx.Rollback()
+	if t.batch == nil && !errors.Is(err, sql.ErrTxDone) {
+		metrics.ObserveSince(metrics.PostgresTransactionDuration.WithLabelValues(metrics.TransactionKindSave, metrics.TransactionResultRollback), t.start)
+	}
+	return err
 }
 
 func (t Transaction) Save(ctx context.Context, data any) error {
@@ -80,6 +96,9 @@ func (t Transaction) BigMapDiffs(ctx context.Context, bigmapdiffs ...*bigmapdiff
 	if len(bigmapdiffs) == 0 {
 		return nil
 	}
+	if t.batch != nil {
+		return copyModels(ctx, t.batch, bigmapdiffs)
+	}
 	return t.Save(ctx, &bigmapdiffs)
 }
 
@@ -87,6 +106,9 @@ func (t Transaction) BigMapActions(ctx context.Context, bigmapactions ...*bigmap
 	if len(bigmapactions) == 0 {
 		return nil
 	}
+	if t.batch != nil {
+		return copyModels(ctx, t.batch, bigmapactions)
+	}
 	return t.Save(ctx, &bigmapactions)
 }
 
@@ -119,6 +141,17 @@ func (t Transaction) Operations(ctx context.Context, operations ...*operation.Op
 	if len(operations) == 0 {
 		return nil
 	}
+	if t.batch != nil {
+		// identifiers are reserved before COPY because children rows refer to them
+		ids, err := t.batch.nextIds(ctx, "operations", len(operations))
+		if err != nil {
+			return err
+		}
+		for i := range operations {
+			operations[i].ID = ids[i]
+		}
+		return copyModels(ctx, t.batch, operations)
+	}
 	return t.Save(ctx, &operations)
 }
 
@@ -126,6 +159,9 @@ func (t Transaction) TickerUpdates(ctx context.Context, updates ...*ticket.Ticke
 	if len(updates) == 0 {
 		return nil
 	}
+	if t.batch != nil {
+		return copyModels(ctx, t.batch, updates)
+	}
 	return t.Save(ctx, &updates)
 }
 
@@ -240,6 +276,31 @@ func (t Transaction) UpdateStats(ctx context.Context, stats stats.Stats) error {
 	return err
 }
 
+func (t Transaction) StatsSeries(ctx context.Context, series ...*stats.Series) error {
+	if len(series) == 0 {
+		return nil
+	}
+
+	_, err := t.tx.NewInsert().Model(&series).
+		On("CONFLICT (timestamp, contract_id, kind, status) DO UPDATE").
+		Set("operations_count = EXCLUDED.operations_count + series.operations_count").
+		Set("consumed_gas = EXCLUDED.consumed_gas + series.consumed_gas").
+		Set("burned = EXCLUDED.burned + series.burned").
+		Exec(ctx)
+	return err
+}
+
+func (t Transaction) StatsCallers(ctx context.Context, callers ...*stats.Caller) error {
+	if len(callers) == 0 {
+		return nil
+	}
+
+	_, err := t.tx.NewInsert().Model(&callers).
+		On("CONFLICT (timestamp, contract_id, caller_id) DO NOTHING").
+		Exec(ctx)
+	return err
+}
+
 func (t Transaction) Tickets(ctx context.Context, tickets ...*ticket.Ticket) error {
 	if len(tickets) == 0 {
 		return nil
@@ -267,6 +328,64 @@ func (t Transaction) TicketBalances(ctx context.Context, balances ...*ticket.Bal
 	return err
 }
 
+func (t Transaction) TokenTransfers(ctx context.Context, transfers ...*token.Transfer) error {
+	if len(transfers) == 0 {
+		return nil
+	}
+	if t.batch != nil {
+		return copyModels(ctx, t.batch, transfers)
+	}
+	return t.Save(ctx, &transfers)
+}
+
+func (t Transaction) TokenBalances(ctx context.Context, balances ...*token.Balance) error {
+	if len(balances) == 0 {
+		return nil
+	}
+
+	_, err := t.tx.NewInsert().Model(&balances).
+		Column("contract_id", "account_id", "token_id", "amount").
+		On("CONFLICT (contract_id, account_id, token_id) DO UPDATE").
+		Set("amount = balance.amount + EXCLUDED.amount").
+		Exec(ctx)
+	return err
+}
+
+func (t Transaction) ContractMetadata(ctx context.Context, metadata *contractmetadata.ContractMetadata) error {
+	if metadata == nil {
+		return nil
+	}
+
+	_, err := t.tx.NewInsert().Model(metadata).
+		Column("address", "level", "timestamp", "ptr", "uri", "name", "description", "version", "interfaces", "metadata").
+		On("CONFLICT (address, level) DO UPDATE").
+		Set("timestamp = EXCLUDED.timestamp").
+		Set("ptr = EXCLUDED.ptr").
+		Set("uri = EXCLUDED.uri").
+		Set("name = EXCLUDED.name").
+		Set("description = EXCLUDED.description").
+		Set("version = EXCLUDED.version").
+		Set("interfaces = EXCLUDED.interfaces").
+		Set("metadata = EXCLUDED.metadata").
+		Returning("id").
+		Exec(ctx)
+	return err
+}
+
+// AccountCounters - locks account until the end of transaction and returns it with counters recalculated by its operations.
+// Counters of locked account can't be changed by other transactions, so they are consistent with recalculated ones.
+func (t Transaction) AccountCounters(ctx context.Context, accountID int64) (acc account.Account, counters operation.AccountCounters, err error) {
+	if err = t.tx.NewSelect().
+		Model(&acc).
+		Where("id = ?", accountID).
+		For("UPDATE").
+		Scan(ctx); err != nil {
+		return
+	}
+	counters, err = CountersForAccount(ctx, t.tx, accountID)
+	return
+}
+
 func (t Transaction) DeleteBigMapStatesByContract(ctx context.Context, contract string) (states []bigmapdiff.BigMapState, err error) {
 	_, err = t.tx.NewDelete().
 		Model((*bigmapdiff.BigMapState)(nil)).
@@ -9,6 +9,7 @@ import (
 	"github.com/baking-bad/bcdhub/internal/helpers"
 	"github.com/baking-bad/bcdhub/internal/models/account"
 	"github.com/baking-bad/bcdhub/internal/models/operation"
+	"github.com/baking-bad/bcdhub/internal/models/types"
 	"github.com/baking-bad/bcdhub/internal/postgres/core"
 	"github.com/uptrace/bun"
 )
@@ -253,6 +254,22 @@ func (storage *Storage) GetByHash(ctx context.Context, hash []byte) (operations
 	return operations, err
 }
 
+// GetByLevel -
+func (storage *Storage) GetByLevel(ctx context.Context, level int64) (operations []operation.Operation, err error) {
+	query := storage.DB.NewSelect().Model((*operation.Operation)(nil)).
+		Where("level = ?", level)
+
+	addOperationSorting(query)
+	err = storage.DB.NewSelect().TableExpr("(?) as operation", query).
+		ColumnExpr("operation.*").
+		ColumnExpr("source.address as source__address, source.type as source__type,source.id as source__id").
+		ColumnExpr("destination.address as destination__address, destination.type as destination__type, destination.id as destination__id").
+		Join("LEFT JOIN accounts as source ON source.id = operation.source_id").
+		Join("LEFT JOIN accounts as destination ON destination.id = operation.destination_id").
+		Scan(ctx, &operations)
+	return operations, err
+}
+
 // GetByHashAndCounter -
 func (storage *Storage) GetByHashAndCounter(ctx context.Context, hash []byte, counter int64) (operations []operation.Operation, err error) {
 	query := storage.DB.NewSelect().Model(&operations)
@@ -290,6 +307,146 @@ func (storage *Storage) ListEvents(ctx context.Context, accountID int64, size, o
 	return
 }
 
+// Events - returns events of all contracts matched to filters of request. Events are sorted by id in descending order.
+func (storage *Storage) Events(ctx context.Context, req operation.EventsRequest) (operations []operation.Operation, err error) {
+	query := storage.DB.NewSelect().Model(&operations).
+		Relation("Source", func(sq *bun.SelectQuery) *bun.SelectQuery {
+			return sq.Column("id", "address", "type")
+		}).
+		Where("operation.kind = ?", types.OperationKindEvent).
+		Limit(storage.GetPageSize(req.Limit)).
+		Order("operation.id desc")
+
+	if req.LastID > 0 {
+		query.Where("operation.id < ?", req.LastID)
+	}
+	if req.Tag != "" {
+		query.Where("operation.tag = ?", req.Tag)
+	}
+	if req.EmitterID > 0 {
+		query.Where("operation.source_id = ?", req.EmitterID)
+	}
+	if req.TypeHash != "" {
+		query.Where("encode(sha256(operation.payload_type), 'hex') = ?", req.TypeHash)
+	}
+	if req.FromLevel > 0 {
+		query.Where("operation.level >= ?", req.FromLevel)
+	}
+	if req.ToLevel > 0 {
+		query.Where("operation.level <= ?", req.ToLevel)
+	}
+
+	err = query.Scan(ctx)
+	return
+}
+
+// EventTypes - returns distinct pairs of emitter, tag and payload type with count of events. Types are sorted by count of events in descending order.
+func (storage *Storage) EventTypes(ctx context.Context, req operation.EventTypesRequest) (eventTypes []operation.EventType, err error) {
+	grouped := storage.DB.NewSelect().
+		Model((*operation.Operation)(nil)).
+		Column("source_id", "tag", "payload_type").
+		ColumnExpr("count(*) as count").
+		ColumnExpr("min(level) as first_level").
+		ColumnExpr("max(level) as last_level").
+		Where("kind = ?", types.OperationKindEvent).
+		Group("source_id", "tag", "payload_type")
+
+	if req.Tag != "" {
+		grouped.Where("tag = ?", req.Tag)
+	}
+	if req.EmitterID > 0 {
+		grouped.Where("source_id = ?", req.EmitterID)
+	}
+
+	query := storage.DB.NewSelect().
+		TableExpr("(?) as event_type", grouped).
+		ColumnExpr("event_type.*").
+		ColumnExpr("accounts.address as emitter").
+		Join("LEFT JOIN accounts ON accounts.id = event_type.source_id").
+		Limit(storage.GetPageSize(req.Limit)).
+		OrderExpr("event_type.count desc, event_type.source_id asc, event_type.tag asc")
+
+	if req.Offset > 0 {
+		query.Offset(int(req.Offset))
+	}
+
+	err = query.Scan(ctx, &eventTypes)
+	return
+}
+
+// History - returns operations where account is source, initiator, destination or delegate. Operations are sorted by id in descending order.
+func (storage *Storage) History(ctx context.Context, accountID int64, req operation.HistoryRequest) (operations []operation.Operation, err error) {
+	query := storage.DB.NewSelect().Model(&operations).
+		Limit(storage.GetPageSize(req.Limit)).
+		Order("operation.id desc")
+
+	if req.LastID > 0 {
+		query.Where("operation.id < ?", req.LastID)
+	}
+
+	err = history(query, accountID, req).Scan(ctx)
+	return
+}
+
+// Export - iterates over all operations of account matched to filters of request in ascending order of id. `req.Limit` is used as batch size, `req.LastID` is ignored.
+func (storage *Storage) Export(ctx context.Context, accountID int64, req operation.HistoryRequest, handler func(operations []operation.Operation) error) error {
+	return core.Iterate(ctx, int(req.Limit), func(operations *[]operation.Operation) *bun.SelectQuery {
+		return history(storage.DB.NewSelect().Model(operations), accountID, req)
+	}, handler)
+}
+
+func history(query *bun.SelectQuery, accountID int64, req operation.HistoryRequest) *bun.SelectQuery {
+	query.
+		WhereGroup(" AND ", participant(accountID)).
+		Relation("Destination", func(sq *bun.SelectQuery) *bun.SelectQuery {
+			return sq.Column("id", "address", "type")
+		}).
+		Relation("Source", func(sq *bun.SelectQuery) *bun.SelectQuery {
+			return sq.Column("id", "address", "type")
+		}).
+		Relation("Initiator", func(sq *bun.SelectQuery) *bun.SelectQuery {
+			return sq.Column("id", "address", "type")
+		}).
+		Relation("Delegate", func(sq *bun.SelectQuery) *bun.SelectQuery {
+			return sq.Column("id", "address", "type")
+		})
+
+	if req.CounterpartyID > 0 {
+		query.WhereGroup(" AND ", participant(req.CounterpartyID))
+	}
+	if len(req.Kinds) > 0 {
+		query.Where("operation.kind IN (?)", bun.In(req.Kinds))
+	}
+	if len(req.Statuses) > 0 {
+		query.Where("operation.status IN (?)", bun.In(req.Statuses))
+	}
+	if len(req.Entrypoints) > 0 {
+		query.Where("operation.entrypoint IN (?)", bun.In(req.Entrypoints))
+	}
+	if !req.From.IsZero() {
+		query.Where("operation.timestamp >= ?", req.From)
+	}
+	if !req.To.IsZero() {
+		query.Where("operation.timestamp < ?", req.To)
+	}
+	return query
+}
+
+func participant(accountID int64) func(q *bun.SelectQuery) *bun.SelectQuery {
+	return func(q *bun.SelectQuery) *bun.SelectQuery {
+		return q.
+			Where("operation.source_id = ?", accountID).
+			WhereOr("operation.initiator_id = ?", accountID).
+			WhereOr("operation.destination_id = ?", accountID).
+			WhereOr("operation.delegate_id = ?", accountID)
+	}
+}
+
+// CountersForAccount - calculates counters of account by its operations. Operation is counted twice if account is both its source and destination.
+func (storage *Storage) CountersForAccount(ctx context.Context, accountID int64) (operation.AccountCounters, error) {
+	return core.CountersForAccount(ctx, storage.DB, accountID)
+}
+
 // Origination -
 func (storage *Storage) Origination(ctx context.Context, accountID int64) (result operation.Operation, err error) {
 	err = storage.DB.NewSelect().
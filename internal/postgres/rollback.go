@@ -4,7 +4,9 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"time"
 
+	"github.com/baking-bad/bcdhub/internal/metrics"
 	"github.com/baking-bad/bcdhub/internal/models"
 	"github.com/baking-bad/bcdhub/internal/models/account"
 	"github.com/baking-bad/bcdhub/internal/models/bigmapdiff"
@@ -14,27 +16,34 @@ import (
 	"github.com/baking-bad/bcdhub/internal/models/protocol"
 	"github.com/baking-bad/bcdhub/internal/models/stats"
 	"github.com/baking-bad/bcdhub/internal/models/ticket"
+	"github.com/baking-bad/bcdhub/internal/models/token"
 	"github.com/uptrace/bun"
 )
 
 type Rollback struct {
-	tx bun.Tx
+	tx    bun.Tx
+	start time.Time
 }
 
-func NewRollback(db *bun.DB) (Rollback, error) {
-	tx, err := db.Begin()
+func NewRollback(db bun.IDB) (Rollback, error) {
+	tx, err := db.BeginTx(context.Background(), nil)
 	if err != nil {
 		return Rollback{}, err
 	}
-	return Rollback{tx}, nil
+	return Rollback{tx, time.Now()}, nil
 }
 
 func (r Rollback) Commit() error {
+	defer metrics.ObserveSince(metrics.PostgresTransactionDuration.WithLabelValues(metrics.TransactionKindRollback, metrics.TransactionResultCommit), r.start)
 	return r.tx.Commit()
 }
 
 func (r Rollback) Rollback() error {
-	return r.tx.Rollback()
+	err := r.tx.Rollback()
+	if !errors.Is(err, sql.ErrTxDone) {
+		metrics.ObserveSince(metrics.PostgresTransactionDuration.WithLabelValues(metrics.TransactionKindRollback, metrics.TransactionResultRollback), r.start)
+	}
+	return err
 }
 
 func (r Rollback) DeleteAll(ctx context.Context, model any, level int64) (int, error) {
@@ -88,7 +97,10 @@ func (r Rollback) SaveBigMapState(ctx context.Context, state bigmapdiff.BigMapSt
 
 func (r Rollback) GetOperations(ctx context.Context, level int64) (ops []operation.Operation, err error) {
 	err = r.tx.NewSelect().Model(&ops).
-		Where("level = ?", level).
+		Relation("Destination", func(sq *bun.SelectQuery) *bun.SelectQuery {
+			return sq.Column("type")
+		}).
+		Where("operation.level = ?", level).
 		Scan(ctx)
 	return
 }
@@ -206,6 +218,29 @@ func (r Rollback) UpdateStats(ctx context.Context, stats stats.Stats) error {
 	return err
 }
 
+func (r Rollback) UpdateSeries(ctx context.Context, series ...*stats.Series) error {
+	for i := range series {
+		if _, err := r.tx.NewUpdate().
+			Model(series[i]).
+			WherePK().
+			Set("operations_count = operations_count - ?operations_count").
+			Set("consumed_gas = consumed_gas - ?consumed_gas").
+			Set("burned = burned - ?burned").
+			Exec(ctx); err != nil {
+			return err
+		}
+
+		if _, err := r.tx.NewDelete().
+			Model(series[i]).
+			WherePK().
+			Where("operations_count <= 0").
+			Exec(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (r Rollback) GetMigrations(ctx context.Context, level int64) (migrations []migration.Migration, err error) {
 	err = r.tx.NewSelect().Model(&migrations).
 		ColumnExpr("account_id AS contract__account_id, migration.*").
@@ -261,3 +296,23 @@ func (r Rollback) DeleteTicketBalances(ctx context.Context, ticketIds []int64) (
 		Exec(ctx)
 	return
 }
+
+func (r Rollback) GetTokenTransfers(ctx context.Context, level int64) (transfers []token.Transfer, err error) {
+	err = r.tx.NewSelect().Model(&transfers).
+		Where("level = ?", level).
+		Scan(ctx)
+	return
+}
+
+func (r Rollback) TokenBalances(ctx context.Context, balances ...*token.Balance) error {
+	if len(balances) == 0 {
+		return nil
+	}
+
+	_, err := r.tx.NewInsert().Model(&balances).
+		Column("contract_id", "account_id", "token_id", "amount").
+		On("CONFLICT (contract_id, account_id, token_id) DO UPDATE").
+		Set("amount = balance.amount - EXCLUDED.amount").
+		Exec(ctx)
+	return err
+}
@@ -0,0 +1,410 @@
+package search
+
+import (
+	"context"
+	"strings"
+
+	"github.com/baking-bad/bcdhub/internal/bcd/base"
+	"github.com/baking-bad/bcdhub/internal/bcd/formatter"
+	"github.com/baking-bad/bcdhub/internal/models/account"
+	"github.com/baking-bad/bcdhub/internal/models/bigmapdiff"
+	"github.com/baking-bad/bcdhub/internal/models/contract"
+	"github.com/baking-bad/bcdhub/internal/models/search"
+	"github.com/baking-bad/bcdhub/internal/models/types"
+	"github.com/baking-bad/bcdhub/internal/postgres/core"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/uptrace/bun"
+)
+
+var json = jsoniter.ConfigCompatibleWithStandardLibrary
+
+// Storage -
+type Storage struct {
+	*core.Postgres
+}
+
+// NewStorage -
+func NewStorage(pg *core.Postgres) *Storage {
+	return &Storage{pg}
+}
+
+// Search -
+func (storage *Storage) Search(ctx context.Context, text string, limit int64, hitTypes ...string) ([]search.Hit, error) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return []search.Hit{}, nil
+	}
+	if limit <= 0 || limit > search.MaxDepth {
+		limit = search.MaxDepth
+	}
+
+	hits := make([]search.Hit, 0)
+	for _, finder := range storage.finders(text, hitTypes) {
+		found, err := finder.hits(ctx, int(limit))
+		if err != nil {
+			return nil, err
+		}
+		hits = append(hits, found...)
+	}
+	return hits, nil
+}
+
+// Count -
+func (storage *Storage) Count(ctx context.Context, text string, hitTypes ...string) (int64, error) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return 0, nil
+	}
+
+	var total int64
+	for _, finder := range storage.finders(text, hitTypes) {
+		for _, query := range finder.filters() {
+			count, err := storage.DB.NewSelect().
+				TableExpr("(?) AS matched", query.Limit(search.MaxDepth)).
+				Count(ctx)
+			if err != nil {
+				return 0, err
+			}
+			total += int64(count)
+		}
+	}
+	return total, nil
+}
+
+// finder - searches hits of one type. `filters` return queries of rows matched to text, so they can be counted.
+// `hits` returns rows sorted by rank and level in descending order.
+type finder struct {
+	filters func() []*bun.SelectQuery
+	hits    func(ctx context.Context, limit int) ([]search.Hit, error)
+}
+
+func (storage *Storage) finders(text string, hitTypes []string) []finder {
+	filter := make(map[string]struct{}, len(hitTypes))
+	for i := range hitTypes {
+		filter[hitTypes[i]] = struct{}{}
+	}
+	wanted := func(typ string) bool {
+		if len(filter) == 0 {
+			return true
+		}
+		_, ok := filter[typ]
+		return ok
+	}
+
+	finders := make([]finder, 0)
+	if accountTypes := search.AccountTypes(hitTypes...); len(accountTypes) > 0 {
+		finders = append(finders, storage.addresses(text, accountTypes))
+	}
+	if wanted(search.HitTypeGlobalConstant) {
+		finders = append(finders, storage.globalConstants(text))
+	}
+	if wanted(search.HitTypeEntrypoint) {
+		finders = append(finders, storage.scriptItems(text, "entrypoints", search.HitTypeEntrypoint))
+	}
+	if wanted(search.HitTypeAnnotation) {
+		finders = append(finders, storage.scriptItems(text, "annotations", search.HitTypeAnnotation))
+	}
+	if wanted(search.HitTypeTag) {
+		finders = append(finders, storage.tags(text))
+	}
+	if wanted(search.HitTypeBigMapKey) {
+		finders = append(finders, storage.bigMapKeys(text))
+	}
+	return finders
+}
+
+func (storage *Storage) addresses(text string, accountTypes []types.AccountType) finder {
+	filter := func() *bun.SelectQuery {
+		return storage.DB.NewSelect().
+			Model((*account.Account)(nil)).
+			Where("address LIKE ?", escapeLike(text)+"%").
+			Where("type IN (?)", bun.In(accountTypes))
+	}
+
+	return finder{
+		filters: func() []*bun.SelectQuery {
+			return []*bun.SelectQuery{filter()}
+		},
+		hits: func(ctx context.Context, limit int) ([]search.Hit, error) {
+			var accounts []account.Account
+			if err := filter().
+				Column("type", "address", "level").
+				OrderExpr("lower(address) = lower(?) desc", text).
+				Order("level desc").
+				Limit(limit).
+				Scan(ctx, &accounts); err != nil {
+				return nil, err
+			}
+
+			hits := make([]search.Hit, len(accounts))
+			for i := range accounts {
+				hits[i] = search.Hit{
+					Type:    accounts[i].Type.String(),
+					Address: accounts[i].Address,
+					Value:   accounts[i].Address,
+					Level:   accounts[i].Level,
+					Rank:    rank(accounts[i].Address, text),
+				}
+			}
+			return hits, nil
+		},
+	}
+}
+
+func (storage *Storage) globalConstants(text string) finder {
+	filter := func() *bun.SelectQuery {
+		return storage.DB.NewSelect().
+			Model((*contract.GlobalConstant)(nil)).
+			Where("address LIKE ?", escapeLike(text)+"%")
+	}
+
+	return finder{
+		filters: func() []*bun.SelectQuery {
+			return []*bun.SelectQuery{filter()}
+		},
+		hits: func(ctx context.Context, limit int) ([]search.Hit, error) {
+			var constants []contract.GlobalConstant
+			if err := filter().
+				Column("address", "level").
+				OrderExpr("lower(address) = lower(?) desc", text).
+				Order("level desc").
+				Limit(limit).
+				Scan(ctx, &constants); err != nil {
+				return nil, err
+			}
+
+			hits := make([]search.Hit, len(constants))
+			for i := range constants {
+				hits[i] = search.Hit{
+					Type:    search.HitTypeGlobalConstant,
+					Address: constants[i].Address,
+					Value:   constants[i].Address,
+					Level:   constants[i].Level,
+					Rank:    rank(constants[i].Address, text),
+				}
+			}
+			return hits, nil
+		},
+	}
+}
+
+// scriptItems - searches contracts by items of script's array `column` (entrypoints or annotations) which contain the text.
+// Scripts are prefiltered by trigram index over the whole array. Annotations are ranked without their sigil if the text doesn't start with it.
+func (storage *Storage) scriptItems(text, column, hitType string) finder {
+	pattern := "%" + escapeLike(text) + "%"
+
+	rankValue := "matched.value"
+	if hitType == search.HitTypeAnnotation && !hasAnnotationSigil(text) {
+		rankValue = "substr(matched.value, 2)"
+	}
+
+	filter := func() *bun.SelectQuery {
+		matched := storage.DB.NewSelect().
+			Model((*contract.Script)(nil)).
+			ColumnExpr("script.id, item.value").
+			Join("CROSS JOIN LATERAL unnest(script.?) AS item(value)", bun.Ident(column)).
+			Where("public.immutable_array_to_string(script.?) ILIKE ?", bun.Ident(column), pattern).
+			Where("item.value ILIKE ?", pattern)
+
+		// the same item may be found in several scripts of contract
+		return storage.DB.NewSelect().
+			Model((*contract.Contract)(nil)).
+			ColumnExpr("DISTINCT account.address, contract.level, matched.value").
+			Join("JOIN (?) AS matched ON matched.id IN (contract.alpha_id, contract.babylon_id, contract.jakarta_id)", matched).
+			Join(`JOIN "accounts" AS "account" ON "account"."id" = "contract"."account_id"`)
+	}
+
+	return finder{
+		filters: func() []*bun.SelectQuery {
+			return []*bun.SelectQuery{filter()}
+		},
+		hits: func(ctx context.Context, limit int) ([]search.Hit, error) {
+			var rows []struct {
+				Address string `bun:"address"`
+				Level   int64  `bun:"level"`
+				Value   string `bun:"value"`
+				Rank    int    `bun:"rank"`
+			}
+			if err := filter().
+				ColumnExpr("CASE WHEN lower(?) = lower(?) THEN ? WHEN ? ILIKE ? THEN ? ELSE ? END AS rank",
+					bun.Safe(rankValue), text, search.RankExact,
+					bun.Safe(rankValue), escapeLike(text)+"%", search.RankPrefix,
+					search.RankContains,
+				).
+				OrderExpr("rank, contract.level desc").
+				Limit(limit).
+				Scan(ctx, &rows); err != nil {
+				return nil, err
+			}
+
+			hits := make([]search.Hit, len(rows))
+			for i := range rows {
+				hits[i] = search.Hit{
+					Type:    hitType,
+					Address: rows[i].Address,
+					Value:   rows[i].Value,
+					Level:   rows[i].Level,
+					Rank:    rows[i].Rank,
+				}
+			}
+			return hits, nil
+		},
+	}
+}
+
+// tags - searches contracts by names of their tags. Every matched tag is searched separately, because its rank doesn't depend on contract.
+func (storage *Storage) tags(text string) finder {
+	lower := strings.ToLower(text)
+
+	// all bits are set, so all known tags are returned
+	var matched []string
+	for _, name := range types.Tags(^0).ToArray() {
+		if strings.Contains(strings.ToLower(name), lower) {
+			matched = append(matched, name)
+		}
+	}
+
+	filter := func(name string) *bun.SelectQuery {
+		return storage.DB.NewSelect().
+			Model((*contract.Contract)(nil)).
+			Where("contract.tags & ? > 0", types.NewTags([]string{name}))
+	}
+
+	return finder{
+		filters: func() []*bun.SelectQuery {
+			queries := make([]*bun.SelectQuery, len(matched))
+			for i := range matched {
+				queries[i] = filter(matched[i])
+			}
+			return queries
+		},
+		hits: func(ctx context.Context, limit int) ([]search.Hit, error) {
+			hits := make([]search.Hit, 0)
+			for _, name := range matched {
+				var contracts []contract.Contract
+				if err := filter(name).
+					ColumnExpr("contract.level").
+					ColumnExpr("account.address as account__address").
+					Join(`LEFT JOIN "accounts" AS "account" ON "account"."id" = "contract"."account_id"`).
+					Order("contract.level desc").
+					Limit(limit).
+					Scan(ctx, &contracts); err != nil {
+					return nil, err
+				}
+
+				for i := range contracts {
+					hits = append(hits, search.Hit{
+						Type:    search.HitTypeTag,
+						Address: contracts[i].Account.Address,
+						Value:   name,
+						Level:   contracts[i].Level,
+						Rank:    rank(name, lower),
+					})
+				}
+			}
+			return hits, nil
+		},
+	}
+}
+
+// bigMapKeys - searches keys by their Micheline representation. Keys whose decoded value doesn't contain the text are skipped,
+// so count of keys may be bigger than count of returned hits.
+func (storage *Storage) bigMapKeys(text string) finder {
+	escaped := escapeLike(text)
+
+	filter := func() *bun.SelectQuery {
+		return storage.DB.NewSelect().
+			Model((*bigmapdiff.BigMapState)(nil)).
+			Where("encode(key, 'escape') ILIKE ?", "%"+escaped+"%")
+	}
+
+	return finder{
+		filters: func() []*bun.SelectQuery {
+			return []*bun.SelectQuery{filter()}
+		},
+		hits: func(ctx context.Context, limit int) ([]search.Hit, error) {
+			// ranks of primitive keys: their values are the last quoted strings of Micheline
+			var states []bigmapdiff.BigMapState
+			if err := filter().
+				Column("ptr", "key_hash", "contract", "key", "last_update_level").
+				OrderExpr("CASE WHEN encode(key, 'escape') ILIKE ? THEN ? WHEN encode(key, 'escape') ILIKE ? THEN ? ELSE ? END",
+					`%"`+escaped+`"}`, search.RankExact,
+					`%"`+escaped+`%`, search.RankPrefix,
+					search.RankContains,
+				).
+				Order("last_update_level desc").
+				Limit(limit).
+				Scan(ctx, &states); err != nil {
+				return nil, err
+			}
+
+			hits := make([]search.Hit, 0, len(states))
+			for i := range states {
+				value, err := keyString(states[i].Key)
+				if err != nil {
+					return nil, err
+				}
+				// key was matched by its micheline representation: skip if decoded value does not contain the text
+				if !strings.Contains(strings.ToLower(value), strings.ToLower(text)) {
+					continue
+				}
+				ptr := states[i].Ptr
+				hits = append(hits, search.Hit{
+					Type:    search.HitTypeBigMapKey,
+					Address: states[i].Contract,
+					Value:   value,
+					Level:   states[i].LastUpdateLevel,
+					Rank:    rank(value, text),
+					Ptr:     &ptr,
+					KeyHash: states[i].KeyHash,
+				})
+			}
+			return hits, nil
+		},
+	}
+}
+
+func keyString(key []byte) (string, error) {
+	var node base.Node
+	if err := json.Unmarshal(key, &node); err != nil {
+		return "", err
+	}
+	switch {
+	case node.StringValue != nil:
+		return *node.StringValue, nil
+	case node.BytesValue != nil:
+		return *node.BytesValue, nil
+	case node.IntValue != nil:
+		return node.IntValue.String(), nil
+	default:
+		return formatter.MichelineToMichelsonInline(string(key))
+	}
+}
+
+func hasAnnotationSigil(text string) bool {
+	switch text[0] {
+	case '%', ':', '@':
+		return true
+	default:
+		return false
+	}
+}
+
+func rank(value, text string) int {
+	value = strings.ToLower(value)
+	text = strings.ToLower(text)
+	switch {
+	case value == text:
+		return search.RankExact
+	case strings.HasPrefix(value, text):
+		return search.RankPrefix
+	default:
+		return search.RankContains
+	}
+}
+
+var likeReplacer = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+func escapeLike(text string) string {
+	return likeReplacer.Replace(text)
+}
@@ -57,3 +57,148 @@ func (storage *Storage) List(ctx context.Context, limit, offset int64, sort stri
 	err = query.Relation("Address").Scan(ctx)
 	return
 }
+
+func listQuery(query *bun.SelectQuery, limit int, req smartrollup.ListRequest) {
+	query.Limit(limit)
+	if req.Offset > 0 {
+		query.Offset(int(req.Offset))
+	}
+	lowerSort := strings.ToLower(req.Sort)
+	if lowerSort != "asc" && lowerSort != "desc" {
+		lowerSort = "desc"
+	}
+	query.OrderExpr("?TableAlias.id ?", bun.Safe(lowerSort))
+}
+
+// Commitments -
+func (storage *Storage) Commitments(ctx context.Context, rollupId int64, req smartrollup.ListRequest) (commitments []smartrollup.Commitment, err error) {
+	query := storage.DB.NewSelect().
+		Model(&commitments).
+		Relation("Publisher", func(sq *bun.SelectQuery) *bun.SelectQuery {
+			return sq.Column("address")
+		}).
+		Where("?TableAlias.rollup_id = ?", rollupId)
+	listQuery(query, storage.GetPageSize(req.Limit), req)
+
+	err = query.Scan(ctx)
+	return
+}
+
+// Cementations -
+func (storage *Storage) Cementations(ctx context.Context, rollupId int64, hashes ...string) (cementations []smartrollup.Cementation, err error) {
+	if len(hashes) == 0 {
+		return
+	}
+	err = storage.DB.NewSelect().
+		Model(&cementations).
+		Where("?TableAlias.rollup_id = ?", rollupId).
+		Where("commitment_hash IN (?)", bun.In(hashes)).
+		Scan(ctx)
+	return
+}
+
+const stakersQuery = `WITH last_commitments AS (
+	SELECT DISTINCT ON (publisher_id) publisher_id, hash AS last_commitment, level AS last_level
+	FROM smart_rollup_commitments WHERE rollup_id = ?0 ORDER BY publisher_id, id DESC
+), commitments AS (
+	SELECT publisher_id, MIN(level) AS first_level, COUNT(*) AS commitments_count
+	FROM smart_rollup_commitments WHERE rollup_id = ?0 GROUP BY publisher_id
+), recovered AS (
+	SELECT staker_id, MAX(level) AS recovered_level
+	FROM smart_rollup_bond_recoveries WHERE rollup_id = ?0 GROUP BY staker_id
+), lost AS (
+	SELECT loser_id, MAX(level) AS lost_level
+	FROM smart_rollup_refutations WHERE rollup_id = ?0 AND loser_id > 0 GROUP BY loser_id
+)
+SELECT lc.publisher_id AS staker_id, accounts.address, c.first_level, lc.last_level, c.commitments_count, lc.last_commitment,
+	COALESCE(r.recovered_level, 0) AS recovered_level, COALESCE(l.lost_level, 0) AS lost_level
+FROM last_commitments AS lc
+JOIN commitments AS c ON c.publisher_id = lc.publisher_id
+JOIN accounts ON accounts.id = lc.publisher_id
+LEFT JOIN recovered AS r ON r.staker_id = lc.publisher_id
+LEFT JOIN lost AS l ON l.loser_id = lc.publisher_id
+ORDER BY c.first_level, lc.publisher_id`
+
+// Stakers -
+func (storage *Storage) Stakers(ctx context.Context, rollupId int64) (stakers []smartrollup.Staker, err error) {
+	err = storage.DB.NewRaw(stakersQuery, rollupId).Scan(ctx, &stakers)
+	return
+}
+
+// Games - returns starts of refutation games
+func (storage *Storage) Games(ctx context.Context, rollupId int64, req smartrollup.ListRequest) (games []smartrollup.Refutation, err error) {
+	query := storage.DB.NewSelect().
+		Model(&games).
+		Relation("Player", func(sq *bun.SelectQuery) *bun.SelectQuery {
+			return sq.Column("address")
+		}).
+		Relation("Opponent", func(sq *bun.SelectQuery) *bun.SelectQuery {
+			return sq.Column("address")
+		}).
+		Where("?TableAlias.rollup_id = ?", rollupId).
+		Where("?TableAlias.kind = ?", smartrollup.RefutationKindStart)
+	listQuery(query, storage.GetPageSize(req.Limit), req)
+
+	err = query.Scan(ctx)
+	return
+}
+
+// GameMoves - returns refutations of the game which is started by `start` until the end of the game
+func (storage *Storage) GameMoves(ctx context.Context, rollupId int64, start smartrollup.Refutation) (moves []smartrollup.Refutation, err error) {
+	if start.IsEnded() {
+		return
+	}
+
+	var refutations []smartrollup.Refutation
+	if err = storage.DB.NewSelect().
+		Model(&refutations).
+		Relation("Player", func(sq *bun.SelectQuery) *bun.SelectQuery {
+			return sq.Column("address")
+		}).
+		Relation("Opponent", func(sq *bun.SelectQuery) *bun.SelectQuery {
+			return sq.Column("address")
+		}).
+		Relation("Loser", func(sq *bun.SelectQuery) *bun.SelectQuery {
+			return sq.Column("address")
+		}).
+		Where("?TableAlias.rollup_id = ?", rollupId).
+		Where("?TableAlias.id > ?", start.ID).
+		WhereGroup(" AND ", func(sq *bun.SelectQuery) *bun.SelectQuery {
+			return sq.
+				WhereGroup(" OR ", func(q *bun.SelectQuery) *bun.SelectQuery {
+					return q.Where("?TableAlias.player_id = ?", start.PlayerId).Where("?TableAlias.opponent_id = ?", start.OpponentId)
+				}).
+				WhereGroup(" OR ", func(q *bun.SelectQuery) *bun.SelectQuery {
+					return q.Where("?TableAlias.player_id = ?", start.OpponentId).Where("?TableAlias.opponent_id = ?", start.PlayerId)
+				})
+		}).
+		OrderExpr("?TableAlias.id asc").
+		Scan(ctx); err != nil {
+		return
+	}
+
+	for i := range refutations {
+		if refutations[i].Kind == smartrollup.RefutationKindStart {
+			break
+		}
+		moves = append(moves, refutations[i])
+		if refutations[i].IsEnded() {
+			break
+		}
+	}
+	return
+}
+
+// OutboxMessages -
+func (storage *Storage) OutboxMessages(ctx context.Context, rollupId int64, req smartrollup.ListRequest) (messages []smartrollup.OutboxMessage, err error) {
+	query := storage.DB.NewSelect().
+		Model(&messages).
+		Relation("Executor", func(sq *bun.SelectQuery) *bun.SelectQuery {
+			return sq.Column("address")
+		}).
+		Where("?TableAlias.rollup_id = ?", rollupId)
+	listQuery(query, storage.GetPageSize(req.Limit), req)
+
+	err = query.Scan(ctx)
+	return
+}
@@ -4,7 +4,10 @@ import (
 	"context"
 
 	"github.com/baking-bad/bcdhub/internal/models/stats"
+	"github.com/baking-bad/bcdhub/internal/models/types"
 	"github.com/baking-bad/bcdhub/internal/postgres/core"
+	"github.com/pkg/errors"
+	"github.com/uptrace/bun"
 )
 
 // Storage -
@@ -26,3 +29,61 @@ func (storage *Storage) Get(ctx context.Context) (stats stats.Stats, err error)
 		Scan(ctx)
 	return
 }
+
+// maxSeriesPoints - limit of points in one series
+const maxSeriesPoints = 1000
+
+// Series - returns time series of metric aggregated by buckets in ascending order of time
+func (storage *Storage) Series(ctx context.Context, req stats.SeriesRequest) (points []stats.SeriesPoint, err error) {
+	var query *bun.SelectQuery
+	if req.Metric == stats.MetricCallers {
+		query = storage.DB.NewSelect().
+			Model((*stats.Caller)(nil)).
+			ColumnExpr("COUNT(DISTINCT caller_id) as value")
+	} else {
+		query = storage.DB.NewSelect().
+			Model((*stats.Series)(nil))
+
+		switch req.Metric {
+		case stats.MetricOperations:
+			query.ColumnExpr("SUM(operations_count) as value")
+		case stats.MetricGas:
+			query.ColumnExpr("SUM(consumed_gas) as value")
+		case stats.MetricBurned:
+			query.ColumnExpr("SUM(burned) as value")
+		case stats.MetricOriginations:
+			query.ColumnExpr("SUM(operations_count) as value")
+			req.Kinds = []types.OperationKind{types.OperationKindOrigination, types.OperationKindOriginationNew}
+		case stats.MetricEvents:
+			query.ColumnExpr("SUM(operations_count) as value")
+			req.Kinds = []types.OperationKind{types.OperationKindEvent}
+		default:
+			return nil, errors.Errorf("unknown metric: %s", req.Metric)
+		}
+
+		if len(req.Kinds) > 0 {
+			query.Where("kind IN (?)", bun.In(req.Kinds))
+		}
+		if req.Status > 0 {
+			query.Where("status = ?", req.Status)
+		}
+	}
+
+	query.
+		ColumnExpr("date_trunc(?, timestamp) as timestamp", req.Bucket).
+		Where("contract_id = ?", req.ContractId)
+
+	if !req.From.IsZero() {
+		query.Where("timestamp >= ?", req.From)
+	}
+	if !req.To.IsZero() {
+		query.Where("timestamp < ?", req.To)
+	}
+
+	err = query.
+		GroupExpr("2").
+		OrderExpr("2").
+		Limit(maxSeriesPoints).
+		Scan(ctx, &points)
+	return
+}
@@ -9,8 +9,9 @@ import (
 	"github.com/baking-bad/bcdhub/internal/models/bigmapdiff"
 	"github.com/baking-bad/bcdhub/internal/models/contract"
 	"github.com/baking-bad/bcdhub/internal/models/operation"
+	"github.com/baking-bad/bcdhub/internal/models/stats"
 	"github.com/baking-bad/bcdhub/internal/models/ticket"
-	"github.com/baking-bad/bcdhub/internal/postgres/core"
+	"github.com/baking-bad/bcdhub/internal/models/token"
 	"github.com/pkg/errors"
 )
 
@@ -25,7 +26,7 @@ func (store *Store) Save(ctx context.Context) error {
 	}
 	store.Stats.ID = store.statsId
 
-	tx, err := core.NewTransaction(ctx, store.db)
+	tx, err := store.begin(ctx)
 	if err != nil {
 		return err
 	}
@@ -46,10 +47,18 @@ func (store *Store) Save(ctx context.Context) error {
 		return errors.Wrap(err, "saving ticket balances")
 	}
 
+	if err := store.saveTokenBalances(ctx, tx); err != nil {
+		return errors.Wrap(err, "saving token balances")
+	}
+
 	if err := store.saveOperations(ctx, tx); err != nil {
 		return errors.Wrap(err, "saving operations")
 	}
 
+	if err := store.saveSeries(ctx, tx); err != nil {
+		return errors.Wrap(err, "saving series")
+	}
+
 	if err := store.saveContracts(ctx, tx); err != nil {
 		return errors.Wrap(err, "saving contracts")
 	}
@@ -70,6 +79,10 @@ func (store *Store) Save(ctx context.Context) error {
 		return errors.Wrap(err, "saving smart rollups")
 	}
 
+	if err := store.saveSmartRollupLifecycle(ctx, tx); err != nil {
+		return errors.Wrap(err, "saving smart rollup lifecycle")
+	}
+
 	if err := tx.UpdateStats(ctx, store.Stats); err != nil {
 		return errors.Wrap(err, "saving stats")
 	}
@@ -156,6 +169,29 @@ func (store *Store) saveTicketBalances(ctx context.Context, tx models.Transactio
 	return tx.TicketBalances(ctx, balances...)
 }
 
+func (store *Store) saveTokenBalances(ctx context.Context, tx models.Transaction) error {
+	if len(store.TokenBalances) == 0 {
+		return nil
+	}
+
+	balances := make([]*token.Balance, 0, len(store.TokenBalances))
+	for _, balance := range store.TokenBalances {
+		if id, ok := store.getAccountId(balance.Account); ok {
+			balance.AccountId = id
+		} else {
+			return errors.Errorf("unknown token balance account: %s", balance.Account.Address)
+		}
+		if id, ok := store.getAccountId(balance.Contract); ok {
+			balance.ContractId = id
+		} else {
+			return errors.Errorf("unknown token balance contract: %s", balance.Contract.Address)
+		}
+		balances = append(balances, balance)
+	}
+
+	return tx.TokenBalances(ctx, balances...)
+}
+
 func (store *Store) saveMigrations(ctx context.Context, tx models.Transaction) error {
 	if len(store.Migrations) == 0 {
 		return nil
@@ -202,9 +238,10 @@ func (store *Store) saveOperations(ctx context.Context, tx models.Transaction) e
 	}
 
 	var (
-		bigMapDiffs   = make([]*bigmapdiff.BigMapDiff, 0)
-		bigMapActions = make([]*bigmapaction.BigMapAction, 0)
-		ticketUpdates = make([]*ticket.TicketUpdate, 0)
+		bigMapDiffs    = make([]*bigmapdiff.BigMapDiff, 0)
+		bigMapActions  = make([]*bigmapaction.BigMapAction, 0)
+		ticketUpdates  = make([]*ticket.TicketUpdate, 0)
+		tokenTransfers = make([]*token.Transfer, 0)
 	)
 
 	for _, operation := range store.Operations {
@@ -246,6 +283,27 @@ func (store *Store) saveOperations(ctx context.Context, tx models.Transaction) e
 		}
 
 		ticketUpdates = append(ticketUpdates, operation.TicketUpdates...)
+
+		for j, transfer := range operation.TokenTransfers {
+			if id, ok := store.getAccountId(transfer.Contract); ok {
+				operation.TokenTransfers[j].ContractId = id
+			} else {
+				return errors.Errorf("unknown token transfer contract: %s", transfer.Contract.Address)
+			}
+			if id, ok := store.getAccountId(transfer.From); ok {
+				operation.TokenTransfers[j].FromId = id
+			} else {
+				return errors.Errorf("unknown token transfer sender: %s", transfer.From.Address)
+			}
+			if id, ok := store.getAccountId(transfer.To); ok {
+				operation.TokenTransfers[j].ToId = id
+			} else {
+				return errors.Errorf("unknown token transfer receiver: %s", transfer.To.Address)
+			}
+			operation.TokenTransfers[j].OperationId = operation.ID
+		}
+
+		tokenTransfers = append(tokenTransfers, operation.TokenTransfers...)
 	}
 
 	if err := tx.BigMapDiffs(ctx, bigMapDiffs...); err != nil {
@@ -257,6 +315,112 @@ func (store *Store) saveOperations(ctx context.Context, tx models.Transaction) e
 	if err := tx.TickerUpdates(ctx, ticketUpdates...); err != nil {
 		return errors.Wrap(err, "saving ticket updates")
 	}
+	if err := tx.TokenTransfers(ctx, tokenTransfers...); err != nil {
+		return errors.Wrap(err, "saving token transfers")
+	}
+	return nil
+}
+
+func (store *Store) saveSeries(ctx context.Context, tx models.Transaction) error {
+	if len(store.Operations) == 0 {
+		return nil
+	}
+
+	builder := stats.NewSeriesBuilder()
+	for i := range store.Operations {
+		builder.Add(store.Operations[i])
+	}
+
+	if err := tx.StatsSeries(ctx, builder.Series()...); err != nil {
+		return err
+	}
+	return tx.StatsCallers(ctx, builder.Callers()...)
+}
+
+func (store *Store) saveSmartRollupLifecycle(ctx context.Context, tx models.Transaction) error {
+	var err error
+	for _, c := range store.SmartRollupCommitments {
+		if c.RollupId, err = store.accountId(c.Rollup); err != nil {
+			return err
+		}
+		if c.PublisherId, err = store.accountId(c.Publisher); err != nil {
+			return err
+		}
+	}
+	for _, c := range store.SmartRollupCementations {
+		if c.RollupId, err = store.accountId(c.Rollup); err != nil {
+			return err
+		}
+		if c.SenderId, err = store.accountId(c.Sender); err != nil {
+			return err
+		}
+	}
+	for _, r := range store.SmartRollupRefutations {
+		if r.RollupId, err = store.accountId(r.Rollup); err != nil {
+			return err
+		}
+		if r.PlayerId, err = store.accountId(r.Player); err != nil {
+			return err
+		}
+		if r.OpponentId, err = store.accountId(r.Opponent); err != nil {
+			return err
+		}
+		if r.LoserId, err = store.accountId(r.Loser); err != nil {
+			return err
+		}
+	}
+	for _, r := range store.SmartRollupBondRecoveries {
+		if r.RollupId, err = store.accountId(r.Rollup); err != nil {
+			return err
+		}
+		if r.StakerId, err = store.accountId(r.Staker); err != nil {
+			return err
+		}
+	}
+	for _, m := range store.SmartRollupOutboxMessages {
+		if m.RollupId, err = store.accountId(m.Rollup); err != nil {
+			return err
+		}
+		if m.ExecutorId, err = store.accountId(m.Executor); err != nil {
+			return err
+		}
+	}
+	for _, m := range store.SmartRollupInboxMessages {
+		if m.SenderId, err = store.accountId(m.Sender); err != nil {
+			return err
+		}
+	}
+
+	if len(store.SmartRollupCommitments) > 0 {
+		if err := tx.Save(ctx, &store.SmartRollupCommitments); err != nil {
+			return errors.Wrap(err, "saving commitments")
+		}
+	}
+	if len(store.SmartRollupCementations) > 0 {
+		if err := tx.Save(ctx, &store.SmartRollupCementations); err != nil {
+			return errors.Wrap(err, "saving cementations")
+		}
+	}
+	if len(store.SmartRollupRefutations) > 0 {
+		if err := tx.Save(ctx, &store.SmartRollupRefutations); err != nil {
+			return errors.Wrap(err, "saving refutations")
+		}
+	}
+	if len(store.SmartRollupBondRecoveries) > 0 {
+		if err := tx.Save(ctx, &store.SmartRollupBondRecoveries); err != nil {
+			return errors.Wrap(err, "saving bond recoveries")
+		}
+	}
+	if len(store.SmartRollupOutboxMessages) > 0 {
+		if err := tx.Save(ctx, &store.SmartRollupOutboxMessages); err != nil {
+			return errors.Wrap(err, "saving outbox messages")
+		}
+	}
+	if len(store.SmartRollupInboxMessages) > 0 {
+		if err := tx.Save(ctx, &store.SmartRollupInboxMessages); err != nil {
+			return errors.Wrap(err, "saving inbox messages")
+		}
+	}
 	return nil
 }
 
@@ -298,6 +462,13 @@ func (store *Store) getAccountId(acc account.Account) (int64, bool) {
 	return id, ok
 }
 
+func (store *Store) accountId(acc account.Account) (int64, error) {
+	if id, ok := store.getAccountId(acc); ok {
+		return id, nil
+	}
+	return 0, errors.Errorf("unknown account: %s", acc.Address)
+}
+
 func (store *Store) saveContracts(ctx context.Context, tx models.Transaction) error {
 	if len(store.Contracts) == 0 {
 		return nil
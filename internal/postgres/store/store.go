@@ -1,6 +1,7 @@
 package store
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/baking-bad/bcdhub/internal/models/account"
@@ -12,52 +13,83 @@ import (
 	smartrollup "github.com/baking-bad/bcdhub/internal/models/smart_rollup"
 	"github.com/baking-bad/bcdhub/internal/models/stats"
 	"github.com/baking-bad/bcdhub/internal/models/ticket"
+	"github.com/baking-bad/bcdhub/internal/models/token"
 	"github.com/baking-bad/bcdhub/internal/models/types"
+	"github.com/baking-bad/bcdhub/internal/postgres/core"
 	"github.com/uptrace/bun"
 )
 
 // Store -
 type Store struct {
-	Block           *block.Block
-	BigMapState     map[string]*bigmapdiff.BigMapState
-	Contracts       []*contract.Contract
-	Migrations      []*migration.Migration
-	Operations      []*operation.Operation
-	GlobalConstants []*contract.GlobalConstant
-	SmartRollups    []*smartrollup.SmartRollup
-	Tickets         map[string]*ticket.Ticket
-	TicketBalances  map[string]*ticket.Balance
-	Accounts        map[string]*account.Account
-	Stats           stats.Stats
+	Block                     *block.Block
+	BigMapState               map[string]*bigmapdiff.BigMapState
+	Contracts                 []*contract.Contract
+	Migrations                []*migration.Migration
+	Operations                []*operation.Operation
+	GlobalConstants           []*contract.GlobalConstant
+	SmartRollups              []*smartrollup.SmartRollup
+	SmartRollupCommitments    []*smartrollup.Commitment
+	SmartRollupCementations   []*smartrollup.Cementation
+	SmartRollupRefutations    []*smartrollup.Refutation
+	SmartRollupBondRecoveries []*smartrollup.BondRecovery
+	SmartRollupOutboxMessages []*smartrollup.OutboxMessage
+	SmartRollupInboxMessages  []*smartrollup.InboxMessage
+	Tickets                   map[string]*ticket.Ticket
+	TicketBalances            map[string]*ticket.Balance
+	TokenBalances             map[string]*token.Balance
+	Accounts                  map[string]*account.Account
+	Stats                     stats.Stats
 
 	stats     stats.Repository
-	db        *bun.DB
+	db        bun.IDB
+	batch     *core.Batch
 	accIds    map[string]int64
 	ticketIds map[string]int64
 	statsId   int64
 }
 
 // NewStore -
-func NewStore(db *bun.DB, statsRepo stats.Repository) *Store {
+func NewStore(db bun.IDB, statsRepo stats.Repository) *Store {
 	return &Store{
-		BigMapState:     make(map[string]*bigmapdiff.BigMapState),
-		Contracts:       make([]*contract.Contract, 0),
-		Migrations:      make([]*migration.Migration, 0),
-		Operations:      make([]*operation.Operation, 0),
-		GlobalConstants: make([]*contract.GlobalConstant, 0),
-		SmartRollups:    make([]*smartrollup.SmartRollup, 0),
-		Tickets:         make(map[string]*ticket.Ticket),
-		TicketBalances:  make(map[string]*ticket.Balance),
-		Accounts:        make(map[string]*account.Account),
-		Stats:           stats.Stats{},
-		stats:           statsRepo,
-		db:              db,
-		accIds:          make(map[string]int64),
-		ticketIds:       make(map[string]int64),
-		statsId:         0,
+		BigMapState:               make(map[string]*bigmapdiff.BigMapState),
+		Contracts:                 make([]*contract.Contract, 0),
+		Migrations:                make([]*migration.Migration, 0),
+		Operations:                make([]*operation.Operation, 0),
+		GlobalConstants:           make([]*contract.GlobalConstant, 0),
+		SmartRollups:              make([]*smartrollup.SmartRollup, 0),
+		SmartRollupCommitments:    make([]*smartrollup.Commitment, 0),
+		SmartRollupCementations:   make([]*smartrollup.Cementation, 0),
+		SmartRollupRefutations:    make([]*smartrollup.Refutation, 0),
+		SmartRollupBondRecoveries: make([]*smartrollup.BondRecovery, 0),
+		SmartRollupOutboxMessages: make([]*smartrollup.OutboxMessage, 0),
+		SmartRollupInboxMessages:  make([]*smartrollup.InboxMessage, 0),
+		Tickets:                   make(map[string]*ticket.Ticket),
+		TicketBalances:            make(map[string]*ticket.Balance),
+		TokenBalances:             make(map[string]*token.Balance),
+		Accounts:                  make(map[string]*account.Account),
+		Stats:                     stats.Stats{},
+		stats:                     statsRepo,
+		db:                        db,
+		accIds:                    make(map[string]int64),
+		ticketIds:                 make(map[string]int64),
+		statsId:                   0,
 	}
 }
 
+// NewBatchStore - creates store which saves data in the batch of blocks
+func NewBatchStore(batch *core.Batch, statsRepo stats.Repository) *Store {
+	store := NewStore(batch.DB(), statsRepo)
+	store.batch = batch
+	return store
+}
+
+func (store *Store) begin(ctx context.Context) (core.Transaction, error) {
+	if store.batch != nil {
+		return store.batch.Begin(ctx)
+	}
+	return core.NewTransaction(ctx, store.db)
+}
+
 func (store *Store) SetBlock(block *block.Block) {
 	store.Block = block
 }
@@ -120,6 +152,36 @@ func (store *Store) AddSmartRollups(rollups ...*smartrollup.SmartRollup) {
 	store.Stats.SmartRollupsCount += len(rollups)
 }
 
+// AddSmartRollupCommitments -
+func (store *Store) AddSmartRollupCommitments(commitments ...*smartrollup.Commitment) {
+	store.SmartRollupCommitments = append(store.SmartRollupCommitments, commitments...)
+}
+
+// AddSmartRollupCementations -
+func (store *Store) AddSmartRollupCementations(cementations ...*smartrollup.Cementation) {
+	store.SmartRollupCementations = append(store.SmartRollupCementations, cementations...)
+}
+
+// AddSmartRollupRefutations -
+func (store *Store) AddSmartRollupRefutations(refutations ...*smartrollup.Refutation) {
+	store.SmartRollupRefutations = append(store.SmartRollupRefutations, refutations...)
+}
+
+// AddSmartRollupBondRecoveries -
+func (store *Store) AddSmartRollupBondRecoveries(recoveries ...*smartrollup.BondRecovery) {
+	store.SmartRollupBondRecoveries = append(store.SmartRollupBondRecoveries, recoveries...)
+}
+
+// AddSmartRollupOutboxMessages -
+func (store *Store) AddSmartRollupOutboxMessages(messages ...*smartrollup.OutboxMessage) {
+	store.SmartRollupOutboxMessages = append(store.SmartRollupOutboxMessages, messages...)
+}
+
+// AddSmartRollupInboxMessages -
+func (store *Store) AddSmartRollupInboxMessages(messages ...*smartrollup.InboxMessage) {
+	store.SmartRollupInboxMessages = append(store.SmartRollupInboxMessages, messages...)
+}
+
 // AddAccounts -
 func (store *Store) AddAccounts(accounts ...account.Account) {
 	for i := range accounts {
@@ -168,6 +230,18 @@ func (store *Store) AddTicketBalances(balance ...ticket.Balance) {
 	}
 }
 
+// AddTokenBalances -
+func (store *Store) AddTokenBalances(balance ...token.Balance) {
+	for i := range balance {
+		key := balance[i].String()
+		if b, ok := store.TokenBalances[key]; !ok {
+			store.TokenBalances[key] = &balance[i]
+		} else {
+			b.Amount = b.Amount.Add(balance[i].Amount)
+		}
+	}
+}
+
 // ListContracts -
 func (store *Store) ListContracts() []*contract.Contract {
 	return store.Contracts
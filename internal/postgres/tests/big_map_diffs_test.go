@@ -138,3 +138,29 @@ func (s *StorageTestSuite) TestBigMapDiffsKeys() {
 	s.Require().NoError(err)
 	s.Require().Len(states, 2)
 }
+
+func (s *StorageTestSuite) TestBigMapDiffsKeysAtLevel() {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	states, err := s.bigMapDiffs.KeysAtLevel(ctx, bigmapdiff.GetContext{
+		Ptr: testsuite.Ptr[int64](41),
+	}, 40)
+	s.Require().NoError(err)
+	s.Require().Len(states, 2)
+
+	for i := range states {
+		s.Require().EqualValues(41, states[i].Ptr)
+		s.Require().EqualValues(40, states[i].LastUpdateLevel)
+		s.Require().EqualValues(1, states[i].Count)
+		s.Require().EqualValues("KT1NSpRTVR4MUwx64XCADXDUmpMGQw5yVNK1", states[i].Contract)
+		s.Require().False(states[i].Removed)
+		s.Require().NotEmpty(states[i].Value)
+	}
+
+	before, err := s.bigMapDiffs.KeysAtLevel(ctx, bigmapdiff.GetContext{
+		Ptr: testsuite.Ptr[int64](41),
+	}, 39)
+	s.Require().NoError(err)
+	s.Require().Len(before, 0)
+}
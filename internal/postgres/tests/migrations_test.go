@@ -22,4 +22,5 @@ func (s *StorageTestSuite) TestMigrationGet() {
 	s.Require().EqualValues(1, m.ContractID)
 	s.Require().EqualValues(2, m.Level)
 	s.Require().EqualValues(types.MigrationKindUpdate, m.Kind)
+	s.Require().JSONEq(`{"int":"1"}`, string(m.DeffatedStorage))
 }
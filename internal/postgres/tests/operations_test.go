@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/baking-bad/bcdhub/internal/models/types"
+	"github.com/baking-bad/bcdhub/internal/postgres/core"
 	"github.com/baking-bad/bcdhub/internal/testsuite"
 )
 
@@ -38,6 +39,40 @@ func (s *StorageTestSuite) TestOperationsLast() {
 	s.Require().Equal(testsuite.MustHexDecode("a47ce950e17c7f06af8e9e992ae10ad2b7aca0cf8a72c0ce451684f673f20324"), operation.Hash)
 }
 
+func (s *StorageTestSuite) TestOperationsLastAtLevel() {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	// operations at or before the level are the operations before the next block
+	next, err := s.blocks.Get(ctx, 38)
+	s.Require().NoError(err)
+
+	operation, err := s.operations.Last(ctx, map[string]interface{}{
+		"destination_id": 88,
+		"status":         types.OperationStatusApplied,
+		"timestamp": core.TimestampFilter{
+			Lt: next.Timestamp,
+		},
+	}, 0)
+	s.Require().NoError(err)
+	s.Require().EqualValues(67, operation.ID)
+	s.Require().EqualValues(37, operation.Level)
+
+	next, err = s.blocks.Get(ctx, 37)
+	s.Require().NoError(err)
+
+	operation, err = s.operations.Last(ctx, map[string]interface{}{
+		"destination_id": 88,
+		"status":         types.OperationStatusApplied,
+		"timestamp": core.TimestampFilter{
+			Lt: next.Timestamp,
+		},
+	}, 0)
+	s.Require().NoError(err)
+	s.Require().EqualValues(58, operation.ID)
+	s.Require().EqualValues(36, operation.Level)
+}
+
 func (s *StorageTestSuite) TestOperationsGetByHash() {
 	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
 	defer cancel()
@@ -0,0 +1,70 @@
+package tests
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/baking-bad/bcdhub/internal/models/search"
+)
+
+func (s *StorageTestSuite) TestSearchEntrypoints() {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	hits, err := s.search.Search(ctx, "liquidity", 10, search.HitTypeEntrypoint)
+	s.Require().NoError(err)
+	s.Require().NotEmpty(hits)
+
+	for i := range hits {
+		s.Require().Equal(search.HitTypeEntrypoint, hits[i].Type)
+		s.Require().Contains(strings.ToLower(hits[i].Value), "liquidity")
+		s.Require().Equal(search.RankContains, hits[i].Rank)
+	}
+
+	count, err := s.search.Count(ctx, "liquidity", search.HitTypeEntrypoint)
+	s.Require().NoError(err)
+	s.Require().EqualValues(len(hits), count)
+
+	hits, err = s.search.Search(ctx, "addLiq", 10, search.HitTypeEntrypoint)
+	s.Require().NoError(err)
+	s.Require().NotEmpty(hits)
+	for i := range hits {
+		s.Require().Equal("addLiquidity", hits[i].Value)
+		s.Require().Equal(search.RankPrefix, hits[i].Rank)
+	}
+}
+
+func (s *StorageTestSuite) TestSearchAnnotations() {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	hits, err := s.search.Search(ctx, "transfer", 10, search.HitTypeAnnotation)
+	s.Require().NoError(err)
+	s.Require().NotEmpty(hits)
+
+	// exact matches without sigil are the first ones
+	s.Require().Equal("%transfer", hits[0].Value)
+	s.Require().Equal(search.RankExact, hits[0].Rank)
+	for i := 1; i < len(hits); i++ {
+		s.Require().LessOrEqual(hits[i-1].Rank, hits[i].Rank)
+	}
+}
+
+func (s *StorageTestSuite) TestSearchLimit() {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	hits, err := s.search.Search(ctx, "KT1", 2, "contract")
+	s.Require().NoError(err)
+	s.Require().Len(hits, 2)
+	s.Require().GreaterOrEqual(hits[0].Level, hits[1].Level)
+
+	all, err := s.search.Search(ctx, "KT1", search.MaxDepth, "contract")
+	s.Require().NoError(err)
+
+	count, err := s.search.Count(ctx, "KT1", "contract")
+	s.Require().NoError(err)
+	s.Require().EqualValues(len(all), count)
+	s.Require().Greater(count, int64(2))
+}
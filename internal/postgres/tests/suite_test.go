@@ -17,6 +17,7 @@ import (
 	"github.com/baking-bad/bcdhub/internal/postgres/migration"
 	"github.com/baking-bad/bcdhub/internal/postgres/operation"
 	"github.com/baking-bad/bcdhub/internal/postgres/protocol"
+	"github.com/baking-bad/bcdhub/internal/postgres/search"
 	smartrollup "github.com/baking-bad/bcdhub/internal/postgres/smart_rollup"
 	"github.com/baking-bad/bcdhub/internal/postgres/stats"
 	"github.com/baking-bad/bcdhub/internal/postgres/ticket"
@@ -41,6 +42,7 @@ type StorageTestSuite struct {
 	migrations      *migration.Storage
 	operations      *operation.Storage
 	protocols       *protocol.Storage
+	search          *search.Storage
 	smartRollups    *smartrollup.Storage
 	ticketUpdates   *ticket.Storage
 	stats           *stats.Storage
@@ -85,6 +87,7 @@ func (s *StorageTestSuite) SetupSuite() {
 	s.migrations = migration.NewStorage(strg)
 	s.operations = operation.NewStorage(strg)
 	s.protocols = protocol.NewStorage(strg)
+	s.search = search.NewStorage(strg)
 	s.smartRollups = smartrollup.NewStorage(strg)
 	s.ticketUpdates = ticket.NewStorage(strg)
 	s.stats = stats.NewStorage(strg)
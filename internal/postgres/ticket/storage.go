@@ -21,18 +21,37 @@ func NewStorage(pg *core.Postgres) *Storage {
 
 // Updates -
 func (storage *Storage) Updates(ctx context.Context, req ticket.UpdatesRequest) (response []ticket.TicketUpdate, err error) {
-	query := storage.DB.
-		NewSelect().
-		Model(&response).
-		Relation("Ticket").
-		Relation("Ticket.Ticketer").
-		Relation("Account", func(sq *bun.SelectQuery) *bun.SelectQuery {
-			return sq.Column("address")
-		}).
+	filter, err := storage.updatesFilter(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	query := filter(storage.DB.NewSelect().Model(&response)).
 		Limit(storage.GetPageSize(req.Limit))
 
+	if req.Offset > 0 {
+		query.Offset(int(req.Offset))
+	}
+
+	err = query.Order("id desc").Scan(ctx)
+	return
+}
+
+// ExportUpdates - iterates over all ticket updates matched to request in ascending order of id. `req.Limit` is used as batch size, `req.Offset` is ignored.
+func (storage *Storage) ExportUpdates(ctx context.Context, req ticket.UpdatesRequest, handler func(updates []ticket.TicketUpdate) error) error {
+	filter, err := storage.updatesFilter(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	return core.Iterate(ctx, int(req.Limit), func(updates *[]ticket.TicketUpdate) *bun.SelectQuery {
+		return filter(storage.DB.NewSelect().Model(updates))
+	}, handler)
+}
+
+func (storage *Storage) updatesFilter(ctx context.Context, req ticket.UpdatesRequest) (func(query *bun.SelectQuery) *bun.SelectQuery, error) {
+	var ticketerId, accountId uint64
 	if req.Ticketer != "" {
-		var ticketerId uint64
 		if err := storage.DB.NewSelect().
 			Model((*account.Account)(nil)).
 			Column("id").
@@ -41,11 +60,9 @@ func (storage *Storage) Updates(ctx context.Context, req ticket.UpdatesRequest)
 			Scan(ctx, &ticketerId); err != nil {
 			return nil, err
 		}
-		query.Where("ticket.ticketer_id = ?", ticketerId)
 	}
 
 	if req.Account != "" {
-		var accountId uint64
 		if err := storage.DB.NewSelect().
 			Model((*account.Account)(nil)).
 			Column("id").
@@ -54,19 +71,33 @@ func (storage *Storage) Updates(ctx context.Context, req ticket.UpdatesRequest)
 			Scan(ctx, &accountId); err != nil {
 			return nil, err
 		}
-		query.Where("account_id = ?", accountId)
-	}
-
-	if req.TicketId != nil {
-		query.Where("ticket_id = ?", *req.TicketId)
 	}
 
-	if req.Offset > 0 {
-		query.Offset(int(req.Offset))
-	}
+	return func(query *bun.SelectQuery) *bun.SelectQuery {
+		query.
+			Relation("Ticket").
+			Relation("Ticket.Ticketer").
+			Relation("Account", func(sq *bun.SelectQuery) *bun.SelectQuery {
+				return sq.Column("address", "type")
+			})
 
-	err = query.Order("id desc").Scan(ctx)
-	return
+		if req.Ticketer != "" {
+			query.Where("ticket.ticketer_id = ?", ticketerId)
+		}
+		if req.Account != "" {
+			query.Where("account_id = ?", accountId)
+		}
+		if req.TicketId != nil {
+			query.Where("ticket_id = ?", *req.TicketId)
+		}
+		if req.FromLevel > 0 {
+			query.Where("ticket_update.level >= ?", req.FromLevel)
+		}
+		if req.ToLevel > 0 {
+			query.Where("ticket_update.level <= ?", req.ToLevel)
+		}
+		return query
+	}, nil
 }
 
 // ForOperation -
@@ -140,3 +171,79 @@ func (storage *Storage) List(ctx context.Context, ticketer string, limit, offset
 	err = query.Scan(ctx)
 	return
 }
+
+// Get - returns ticket by its hash
+func (storage *Storage) Get(ctx context.Context, hash string) (t ticket.Ticket, err error) {
+	err = storage.DB.
+		NewSelect().
+		Model(&t).
+		Relation("Ticketer").
+		Where("hash = ?", hash).
+		Limit(1).
+		Scan(ctx)
+	return
+}
+
+// Holders - returns accounts with positive balance of ticket sorted by balance
+func (storage *Storage) Holders(ctx context.Context, ticketId, limit, offset int64) (balances []ticket.Balance, err error) {
+	query := storage.DB.
+		NewSelect().
+		Model(&balances).
+		Relation("Account", func(sq *bun.SelectQuery) *bun.SelectQuery {
+			return sq.Column("address", "type")
+		}).
+		Where("ticket_id = ?", ticketId).
+		Where("amount > 0").
+		Limit(storage.GetPageSize(limit))
+
+	if offset > 0 {
+		query.Offset(int(offset))
+	}
+
+	err = query.OrderExpr("amount desc, account_id asc").Scan(ctx)
+	return
+}
+
+// Summary -
+func (storage *Storage) Summary(ctx context.Context, ticketId int64) (summary ticket.Summary, err error) {
+	err = storage.DB.
+		NewSelect().
+		Model((*ticket.Balance)(nil)).
+		ColumnExpr("COALESCE(SUM(amount), 0) as supply").
+		ColumnExpr("COUNT(*) as holders_count").
+		Where("ticket_id = ?", ticketId).
+		Where("amount > 0").
+		Scan(ctx, &summary)
+	return
+}
+
+// SupplyHistory - returns total supply of ticket after each level where it was changed in ascending order of level
+func (storage *Storage) SupplyHistory(ctx context.Context, ticketId int64, req ticket.SupplyRequest) (points []ticket.SupplyPoint, err error) {
+	history := storage.DB.
+		NewSelect().
+		Model((*ticket.TicketUpdate)(nil)).
+		Column("level").
+		ColumnExpr("MAX(timestamp) as timestamp").
+		ColumnExpr("SUM(amount) as delta").
+		ColumnExpr("SUM(SUM(amount)) OVER (ORDER BY level) as supply").
+		Where("ticket_id = ?", ticketId).
+		Group("level")
+
+	query := storage.DB.
+		NewSelect().
+		TableExpr("(?) as history", history).
+		Limit(storage.GetPageSize(req.Limit))
+
+	if req.FromLevel > 0 {
+		query.Where("level >= ?", req.FromLevel)
+	}
+	if req.ToLevel > 0 {
+		query.Where("level <= ?", req.ToLevel)
+	}
+	if req.Offset > 0 {
+		query.Offset(int(req.Offset))
+	}
+
+	err = query.Order("level").Scan(ctx, &points)
+	return
+}
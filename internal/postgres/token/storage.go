@@ -0,0 +1,123 @@
+package token
+
+import (
+	"context"
+
+	"github.com/baking-bad/bcdhub/internal/models/account"
+	"github.com/baking-bad/bcdhub/internal/models/token"
+	"github.com/baking-bad/bcdhub/internal/postgres/core"
+	"github.com/uptrace/bun"
+)
+
+// Storage -
+type Storage struct {
+	*core.Postgres
+}
+
+// NewStorage -
+func NewStorage(pg *core.Postgres) *Storage {
+	return &Storage{pg}
+}
+
+// Holders -
+func (storage *Storage) Holders(ctx context.Context, contractId int64, req token.HoldersRequest) (balances []token.Balance, err error) {
+	query := storage.DB.
+		NewSelect().
+		Model(&balances).
+		Relation("Account", func(sq *bun.SelectQuery) *bun.SelectQuery {
+			return sq.Column("address")
+		}).
+		Where("contract_id = ?", contractId).
+		Where("amount > 0").
+		Limit(storage.GetPageSize(req.Limit))
+
+	if req.TokenId != nil {
+		query.Where("token_id = ?", *req.TokenId)
+	}
+
+	if req.Offset > 0 {
+		query.Offset(int(req.Offset))
+	}
+
+	err = query.Order("amount desc").Scan(ctx)
+	return
+}
+
+// BalancesForAccount -
+func (storage *Storage) BalancesForAccount(ctx context.Context, accountId int64, req token.BalanceRequest) (balances []token.Balance, err error) {
+	query := storage.DB.
+		NewSelect().
+		Model(&balances).
+		Relation("Contract", func(sq *bun.SelectQuery) *bun.SelectQuery {
+			return sq.Column("address")
+		}).
+		Where("account_id = ?", accountId).
+		Limit(storage.GetPageSize(req.Limit))
+
+	if req.Offset > 0 {
+		query.Offset(int(req.Offset))
+	}
+
+	if req.WithoutZeroBalances {
+		query.Where("amount > 0")
+	}
+
+	err = query.Order("contract_id", "token_id").Scan(ctx)
+	return
+}
+
+// Transfers -
+func (storage *Storage) Transfers(ctx context.Context, req token.TransfersRequest) (transfers []token.Transfer, err error) {
+	query := storage.DB.
+		NewSelect().
+		Model(&transfers).
+		Relation("Contract", func(sq *bun.SelectQuery) *bun.SelectQuery {
+			return sq.Column("address")
+		}).
+		Relation("From", func(sq *bun.SelectQuery) *bun.SelectQuery {
+			return sq.Column("address")
+		}).
+		Relation("To", func(sq *bun.SelectQuery) *bun.SelectQuery {
+			return sq.Column("address")
+		}).
+		Limit(storage.GetPageSize(req.Limit))
+
+	if req.Contract != "" {
+		contractId, err := storage.accountId(ctx, req.Contract)
+		if err != nil {
+			return nil, err
+		}
+		query.Where("transfer.contract_id = ?", contractId)
+	}
+
+	if req.Account != "" {
+		accountId, err := storage.accountId(ctx, req.Account)
+		if err != nil {
+			return nil, err
+		}
+		query.WhereGroup(" AND ", func(sq *bun.SelectQuery) *bun.SelectQuery {
+			return sq.Where("transfer.from_id = ?", accountId).WhereOr("transfer.to_id = ?", accountId)
+		})
+	}
+
+	if req.TokenId != nil {
+		query.Where("transfer.token_id = ?", *req.TokenId)
+	}
+
+	if req.Offset > 0 {
+		query.Offset(int(req.Offset))
+	}
+
+	err = query.Order("transfer.id desc").Scan(ctx)
+	return
+}
+
+func (storage *Storage) accountId(ctx context.Context, address string) (id int64, err error) {
+	err = storage.DB.NewSelect().
+		Model((*account.Account)(nil)).
+		Column("id").
+		Where("address = ?", address).
+		Limit(1).
+		Scan(ctx, &id)
+	return
+}
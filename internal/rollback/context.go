@@ -11,16 +11,18 @@ import (
 type rollbackContext struct {
 	generalStats stats.Stats
 	accountStats map[int64]*account.Account
+	series       *stats.SeriesBuilder
 }
 
 func newRollbackContext(ctx context.Context, statsRepo stats.Repository) (rollbackContext, error) {
-	stats, err := statsRepo.Get(ctx)
+	generalStats, err := statsRepo.Get(ctx)
 	if err != nil {
 		return rollbackContext{}, err
 	}
 	return rollbackContext{
-		generalStats: stats,
+		generalStats: generalStats,
 		accountStats: make(map[int64]*account.Account),
+		series:       stats.NewSeriesBuilder(),
 	}, nil
 }
 
@@ -101,6 +103,10 @@ func (rCtx *rollbackContext) update(ctx context.Context, rollback models.Rollbac
 		return err
 	}
 
+	if err := rollback.UpdateSeries(ctx, rCtx.series.Series()...); err != nil {
+		return err
+	}
+
 	for _, acc := range rCtx.accountStats {
 		if err := rollback.UpdateAccountStats(ctx, *acc); err != nil {
 			return err
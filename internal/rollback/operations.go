@@ -21,6 +21,8 @@ func (rm Manager) rollbackOperations(ctx context.Context, level int64, rCtx *rol
 	}
 
 	for i := range ops {
+		rCtx.series.Add(&ops[i])
+
 		if ops[i].DestinationID > 0 {
 			rCtx.applyOperationsCount(ops[i].DestinationID, 1)
 			rCtx.applyTicketUpdates(ops[i].DestinationID, int64(ops[i].TicketUpdatesCount))
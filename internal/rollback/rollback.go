@@ -3,12 +3,14 @@ package rollback
 import (
 	"context"
 
+	"github.com/baking-bad/bcdhub/internal/events"
 	"github.com/baking-bad/bcdhub/internal/models"
 	"github.com/baking-bad/bcdhub/internal/models/account"
 	"github.com/baking-bad/bcdhub/internal/models/bigmapaction"
 	"github.com/baking-bad/bcdhub/internal/models/bigmapdiff"
 	"github.com/baking-bad/bcdhub/internal/models/block"
 	"github.com/baking-bad/bcdhub/internal/models/contract"
+	contractmetadata "github.com/baking-bad/bcdhub/internal/models/contract_metadata"
 	"github.com/baking-bad/bcdhub/internal/models/migration"
 	smartrollup "github.com/baking-bad/bcdhub/internal/models/smart_rollup"
 	"github.com/baking-bad/bcdhub/internal/models/stats"
@@ -68,7 +70,17 @@ func (rm Manager) Rollback(ctx context.Context, network types.Network, fromState
 		log.Info().Str("network", network.String()).Msgf("rolled back to %d", level)
 	}
 
-	return rm.rollback.Commit()
+	if err := rm.rollback.Commit(); err != nil {
+		return err
+	}
+
+	if err := events.Publish(ctx, rm.storage, network, events.Message{
+		Type:  events.MessageTypeRollback,
+		Level: toLevel,
+	}); err != nil {
+		log.Warn().Err(err).Str("network", network.String()).Msg("publish rollback notification")
+	}
+	return nil
 }
 
 func (rm Manager) rollbackBlock(ctx context.Context, level int64) error {
@@ -92,6 +104,9 @@ func (rm Manager) rollbackBlock(ctx context.Context, level int64) error {
 	if err := rm.rollbackTickets(ctx, level); err != nil {
 		return err
 	}
+	if err := rm.rollbackTokens(ctx, level); err != nil {
+		return err
+	}
 	if err := rm.rollbackAll(ctx, level, &rollbackCtx); err != nil {
 		return err
 	}
@@ -131,6 +146,14 @@ func (rm Manager) rollbackAll(ctx context.Context, level int64, rCtx *rollbackCo
 		(*bigmapdiff.BigMapDiff)(nil),
 		(*bigmapaction.BigMapAction)(nil),
 		(*smartrollup.SmartRollup)(nil),
+		(*smartrollup.Commitment)(nil),
+		(*smartrollup.Cementation)(nil),
+		(*smartrollup.Refutation)(nil),
+		(*smartrollup.BondRecovery)(nil),
+		(*smartrollup.OutboxMessage)(nil),
+		(*smartrollup.InboxMessage)(nil),
+		(*stats.Caller)(nil),
+		(*contractmetadata.ContractMetadata)(nil),
 		(*account.Account)(nil),
 	} {
 		if _, err := rm.rollback.DeleteAll(ctx, model, level); err != nil {
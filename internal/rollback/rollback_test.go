@@ -18,6 +18,7 @@ import (
 	"github.com/baking-bad/bcdhub/internal/models/operation"
 	"github.com/baking-bad/bcdhub/internal/models/stats"
 	"github.com/baking-bad/bcdhub/internal/models/ticket"
+	"github.com/baking-bad/bcdhub/internal/models/token"
 	"github.com/baking-bad/bcdhub/internal/models/types"
 	"github.com/baking-bad/bcdhub/internal/testsuite"
 	"github.com/shopspring/decimal"
@@ -230,6 +231,42 @@ func TestManager_Rollback(t *testing.T) {
 		Return(nil).
 		Times(1)
 
+	rb.EXPECT().
+		GetTokenTransfers(gomock.Any(), level).
+		Return([]token.Transfer{
+			{
+				ContractId: 2,
+				FromId:     1,
+				ToId:       4,
+				TokenId:    decimal.Zero,
+				Amount:     decimal.RequireFromString("10"),
+			}, {
+				ContractId: 2,
+				ToId:       1,
+				TokenId:    decimal.Zero,
+				Amount:     decimal.RequireFromString("5"),
+			},
+		}, nil).
+		Times(1)
+
+	rb.EXPECT().
+		TokenBalances(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, balances ...*token.Balance) error {
+			require.Len(t, balances, 2)
+			for _, balance := range balances {
+				switch balance.AccountId {
+				case 1:
+					require.Equal(t, "-5", balance.Amount.String())
+				case 4:
+					require.Equal(t, "10", balance.Amount.String())
+				default:
+					t.Fatalf("unexpected account: %d", balance.AccountId)
+				}
+			}
+			return nil
+		}).
+		Times(1)
+
 	rb.EXPECT().
 		UpdateAccountStats(gomock.Any(), account.Account{
 			ID:              4,
@@ -378,7 +415,7 @@ func TestManager_Rollback(t *testing.T) {
 	rb.EXPECT().
 		DeleteAll(gomock.Any(), nil, level).
 		Return(0, nil).
-		Times(8)
+		Times(17)
 
 	rb.EXPECT().
 		Protocols(gomock.Any(), level).
@@ -399,11 +436,36 @@ func TestManager_Rollback(t *testing.T) {
 		Return(nil).
 		Times(1)
 
+	rb.EXPECT().
+		UpdateSeries(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, series ...*stats.Series) error {
+			require.Len(t, series, 9)
+			for _, s := range series {
+				switch {
+				case s.ContractId == 0 && s.Kind == types.OperationKindTransaction:
+					require.EqualValues(t, 4, s.OperationsCount)
+				case s.ContractId == 1 && s.Kind == types.OperationKindTransaction:
+					require.EqualValues(t, 2, s.OperationsCount)
+				case s.ContractId == 1 && s.Kind == types.OperationKindEvent:
+					require.EqualValues(t, 1, s.OperationsCount)
+				case s.ContractId == 3:
+					t.Fatalf("series of implicit account: %d", s.ContractId)
+				}
+			}
+			return nil
+		}).
+		Times(1)
+
 	rb.EXPECT().
 		Commit().
 		Return(nil).
 		Times(1)
 
+	storage.EXPECT().
+		Notify(gomock.Any(), "bcd_mainnet", `{"type":"rollback","level":10}`).
+		Return(nil).
+		Times(1)
+
 	t.Run("Rollback", func(t *testing.T) {
 		state := block.Block{
 			Level: 11,
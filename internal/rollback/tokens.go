@@ -0,0 +1,59 @@
+package rollback
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/baking-bad/bcdhub/internal/models/token"
+	"github.com/shopspring/decimal"
+)
+
+func (rm Manager) rollbackTokenTransfers(ctx context.Context, level int64) error {
+	transfers, err := rm.rollback.GetTokenTransfers(ctx, level)
+	if err != nil {
+		return err
+	}
+
+	if len(transfers) == 0 {
+		return nil
+	}
+
+	balances := make(map[string]*token.Balance)
+	update := func(contractId, accountId int64, tokenId, amount decimal.Decimal) {
+		if accountId == 0 {
+			return
+		}
+		key := fmt.Sprintf("%d_%d_%s", contractId, accountId, tokenId.String())
+		if b, ok := balances[key]; ok {
+			b.Amount = b.Amount.Add(amount)
+		} else {
+			balances[key] = &token.Balance{
+				ContractId: contractId,
+				AccountId:  accountId,
+				TokenId:    tokenId,
+				Amount:     amount.Copy(),
+			}
+		}
+	}
+
+	for i := range transfers {
+		update(transfers[i].ContractId, transfers[i].FromId, transfers[i].TokenId, transfers[i].Amount.Neg())
+		update(transfers[i].ContractId, transfers[i].ToId, transfers[i].TokenId, transfers[i].Amount)
+	}
+
+	arr := make([]*token.Balance, 0, len(balances))
+	for _, balance := range balances {
+		arr = append(arr, balance)
+	}
+
+	return rm.rollback.TokenBalances(ctx, arr...)
+}
+
+func (rm Manager) rollbackTokens(ctx context.Context, level int64) error {
+	if err := rm.rollbackTokenTransfers(ctx, level); err != nil {
+		return err
+	}
+
+	_, err := rm.rollback.DeleteAll(ctx, (*token.Transfer)(nil), level)
+	return err
+}
@@ -0,0 +1,120 @@
+package verify
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/baking-bad/bcdhub/internal/models/bigmapdiff"
+	"github.com/baking-bad/bcdhub/internal/models/contract"
+	"github.com/baking-bad/bcdhub/internal/noderpc"
+)
+
+// bigMaps - compares values of indexed big map keys with node's ones. Keys which were added on node but are absent
+// in the index can't be found, because node doesn't list keys of big map. Temporary and removed big maps are skipped.
+func (v verifier) bigMaps(ctx context.Context, c contract.Contract) ([]Mismatch, error) {
+	states, err := v.indexedBigMapStates(ctx, c.Account.Address)
+	if err != nil {
+		return nil, err
+	}
+
+	mismatches := make([]Mismatch, 0)
+	exists := make(map[int64]bool)
+	for i := range states {
+		ptr := states[i].Ptr
+
+		ok, checked := exists[ptr]
+		if !checked {
+			_, err := v.ctx.RPC.GetBigMapType(ctx, ptr, v.block.Level)
+			switch {
+			case err == nil:
+				ok = true
+			case noderpc.IsNotFoundError(err):
+				ok = false
+			default:
+				return nil, err
+			}
+			exists[ptr] = ok
+		}
+		if !ok {
+			continue
+		}
+
+		var removed bool
+		value, err := v.ctx.RPC.GetBigMapValue(ctx, ptr, states[i].KeyHash, v.block.Level)
+		if err != nil {
+			if !noderpc.IsNotFoundError(err) {
+				return nil, err
+			}
+			removed = true
+		}
+
+		if states[i].Removed == removed {
+			if removed {
+				continue
+			}
+			equal, err := jsonEqual(states[i].Value, value)
+			if err != nil {
+				return nil, err
+			}
+			if equal {
+				continue
+			}
+		}
+
+		mismatches = append(mismatches, Mismatch{
+			Contract: c.Account.Address,
+			Kind:     KindBigMap,
+			Key:      fmt.Sprintf("%d/%s", ptr, states[i].KeyHash),
+			Indexed:  bigMapValue(states[i].Removed, states[i].Value),
+			Expected: bigMapValue(removed, value),
+		})
+	}
+	return mismatches, nil
+}
+
+// indexedBigMapStates - returns at most `maxKeys` states of contract's big maps at the verified level
+func (v verifier) indexedBigMapStates(ctx context.Context, address string) ([]bigmapdiff.BigMapState, error) {
+	current, err := v.ctx.BigMapDiffs.GetForAddress(ctx, address)
+	if err != nil {
+		return nil, err
+	}
+
+	states := make([]bigmapdiff.BigMapState, 0, len(current))
+	if v.atHead {
+		for i := range current {
+			if current[i].Ptr >= 0 {
+				states = append(states, current[i])
+			}
+		}
+	} else {
+		ptrs := make([]int64, 0)
+		seen := make(map[int64]struct{})
+		for i := range current {
+			if _, ok := seen[current[i].Ptr]; ok || current[i].Ptr < 0 {
+				continue
+			}
+			seen[current[i].Ptr] = struct{}{}
+			ptrs = append(ptrs, current[i].Ptr)
+		}
+
+		diffs, err := v.ctx.BigMapDiffs.GetAtLevel(ctx, address, v.block.Level, ptrs...)
+		if err != nil {
+			return nil, err
+		}
+		for i := range diffs {
+			states = append(states, *diffs[i].ToState())
+		}
+	}
+
+	if len(states) > v.maxKeys {
+		states = states[:v.maxKeys]
+	}
+	return states, nil
+}
+
+func bigMapValue(removed bool, value []byte) string {
+	if removed {
+		return "removed"
+	}
+	return string(value)
+}
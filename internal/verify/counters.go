@@ -0,0 +1,78 @@
+package verify
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/baking-bad/bcdhub/internal/models/contract"
+	"github.com/baking-bad/bcdhub/internal/models/types"
+)
+
+// counters - compares counters of contract's account with ones recalculated by indexed operations.
+// Counters aren't kept for the past, so they are compared at indexed head only.
+func (v verifier) counters(ctx context.Context, c contract.Contract) ([]Mismatch, error) {
+	if !v.atHead {
+		return nil, nil
+	}
+
+	expected, err := v.ctx.Operations.CountersForAccount(ctx, c.AccountID)
+	if err != nil {
+		return nil, err
+	}
+	bootstrap, err := v.bootstrapOperations(ctx, c)
+	if err != nil {
+		return nil, err
+	}
+	expected.OperationsCount += bootstrap
+
+	// counters are recalculated again in repair transaction: they may be changed by indexer after verification
+	repair := &countersRepair{
+		accountID:           c.AccountID,
+		bootstrapOperations: bootstrap,
+	}
+
+	mismatches := make([]Mismatch, 0)
+	for _, counter := range []struct {
+		name     string
+		indexed  int64
+		expected int64
+	}{
+		{"operations_count", c.Account.OperationsCount, expected.OperationsCount},
+		{"events_count", c.Account.EventsCount, expected.EventsCount},
+	} {
+		if counter.indexed == counter.expected {
+			continue
+		}
+		mismatches = append(mismatches, Mismatch{
+			Contract: c.Account.Address,
+			Kind:     KindCounters,
+			Key:      counter.name,
+			Indexed:  strconv.FormatInt(counter.indexed, 10),
+			Expected: strconv.FormatInt(counter.expected, 10),
+			repair:   repair,
+		})
+	}
+	return mismatches, nil
+}
+
+// bootstrapOperations - returns count of operations which are counted for contract originated by implicit bootstrap migration.
+// Such origination isn't stored as operation but it's counted in `operations_count`. Vesting contracts from `implicit_contracts` config are bootstrapped without counted operations.
+func (v verifier) bootstrapOperations(ctx context.Context, c contract.Contract) (int64, error) {
+	for _, implicit := range v.ctx.Config.ImplicitContracts[v.ctx.Network.String()] {
+		if implicit.Address == c.Account.Address {
+			return 0, nil
+		}
+	}
+
+	migrations, err := v.ctx.Migrations.Get(ctx, c.ID)
+	if err != nil {
+		return 0, err
+	}
+	var count int64
+	for i := range migrations {
+		if migrations[i].Kind == types.MigrationKindBootstrap {
+			count++
+		}
+	}
+	return count, nil
+}
@@ -0,0 +1,98 @@
+package verify
+
+import (
+	"context"
+
+	"github.com/baking-bad/bcdhub/internal/config"
+	"github.com/baking-bad/bcdhub/internal/models"
+	"github.com/baking-bad/bcdhub/internal/models/account"
+	"github.com/baking-bad/bcdhub/internal/postgres/core"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+)
+
+// Repair - replaces indexed counters of contracts with mismatches by the recalculated ones. Every contract is repaired in its own transaction.
+// Counters are recalculated again in the transaction with locked account, so operations indexed after verification are taken into account.
+// Big map and ticket balance mismatches aren't repaired: see `Mismatch`. Only report made at indexed head can be repaired.
+// It returns count of repaired mismatches.
+func Repair(ctx context.Context, cfgCtx *config.Context, report Report) (int, error) {
+	if !report.AtHead {
+		return 0, errors.Errorf("state at level %d can't be repaired: only indexed head is repairable", report.Level)
+	}
+
+	byContract := make(map[string][]Mismatch)
+	for i := range report.Mismatches {
+		if report.Mismatches[i].Repairable() {
+			byContract[report.Mismatches[i].Contract] = append(byContract[report.Mismatches[i].Contract], report.Mismatches[i])
+		}
+	}
+
+	var repaired int
+	for _, address := range report.Contracts() {
+		mismatches, ok := byContract[address]
+		if !ok {
+			continue
+		}
+
+		tx, err := core.NewTransaction(ctx, cfgCtx.StorageDB.DB)
+		if err != nil {
+			return repaired, err
+		}
+		changed, err := repairContract(ctx, tx, mismatches)
+		if err != nil {
+			if rbErr := tx.Rollback(); rbErr != nil {
+				log.Err(rbErr).Str("contract", address).Msg("rollback repair transaction")
+			}
+			return repaired, errors.Wrap(err, address)
+		}
+		if err := tx.Commit(); err != nil {
+			return repaired, errors.Wrap(err, address)
+		}
+		if !changed {
+			log.Info().Str("network", cfgCtx.Network.String()).Str("contract", address).Msg("counters are already consistent")
+			continue
+		}
+		repaired += len(mismatches)
+
+		log.Info().Str("network", cfgCtx.Network.String()).Str("contract", address).Int("mismatches", len(mismatches)).Msg("repaired")
+	}
+	return repaired, nil
+}
+
+// repairContract - recalculates counters of contract's account in `tx` and saves the difference. It returns false if counters are equal to the recalculated ones.
+func repairContract(ctx context.Context, tx models.Transaction, mismatches []Mismatch) (bool, error) {
+	var repair *countersRepair
+	for i := range mismatches {
+		if mismatches[i].repair != nil {
+			repair = mismatches[i].repair
+			break
+		}
+	}
+	if repair == nil {
+		return false, nil
+	}
+
+	indexed, expected, err := tx.AccountCounters(ctx, repair.accountID)
+	if err != nil {
+		return false, errors.Wrap(err, "recalculate counters")
+	}
+	expected.OperationsCount += repair.bootstrapOperations
+
+	if indexed.OperationsCount == expected.OperationsCount && indexed.EventsCount == expected.EventsCount {
+		return false, nil
+	}
+
+	// accounts are upserted by adding counters, so only difference is saved
+	acc := account.Account{
+		Address:         indexed.Address,
+		Type:            indexed.Type,
+		Level:           indexed.Level,
+		LastAction:      indexed.LastAction,
+		OperationsCount: expected.OperationsCount - indexed.OperationsCount,
+		EventsCount:     expected.EventsCount - indexed.EventsCount,
+	}
+	if err := tx.Accounts(ctx, &acc); err != nil {
+		return false, errors.Wrap(err, "save account")
+	}
+	return true, nil
+}
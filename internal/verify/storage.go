@@ -0,0 +1,70 @@
+package verify
+
+import (
+	"context"
+
+	"github.com/baking-bad/bcdhub/internal/models/contract"
+	"github.com/baking-bad/bcdhub/internal/models/types"
+)
+
+// storage - compares storage of the last applied operation to contract with node's one. Contracts without such operations
+// and contracts whose storage was changed by protocol migration after the operation are skipped.
+func (v verifier) storage(ctx context.Context, c contract.Contract) ([]Mismatch, error) {
+	// `last_action` forces search at the timestamp of the block first, so operations of the block are included
+	operation, err := v.ctx.Operations.Last(ctx, map[string]interface{}{
+		"destination_id": c.AccountID,
+		"status":         types.OperationStatusApplied,
+		"last_action":    v.block.Timestamp,
+	}, 0)
+	if err != nil {
+		if v.ctx.Storage.IsRecordNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if len(operation.DeffatedStorage) == 0 {
+		return nil, nil
+	}
+
+	migrated, err := v.isMigratedBetween(ctx, c.ID, operation.Level, v.block.Level)
+	if err != nil {
+		return nil, err
+	}
+	if migrated {
+		return nil, nil
+	}
+
+	storage, err := v.ctx.RPC.GetScriptStorageRaw(ctx, c.Account.Address, v.block.Level)
+	if err != nil {
+		return nil, err
+	}
+
+	equal, err := jsonEqual(operation.DeffatedStorage, storage)
+	if err != nil {
+		return nil, err
+	}
+	if equal {
+		return nil, nil
+	}
+	return []Mismatch{
+		{
+			Contract: c.Account.Address,
+			Kind:     KindStorage,
+			Indexed:  string(operation.DeffatedStorage),
+			Expected: string(storage),
+		},
+	}, nil
+}
+
+func (v verifier) isMigratedBetween(ctx context.Context, contractID, from, to int64) (bool, error) {
+	migrations, err := v.ctx.Migrations.Get(ctx, contractID)
+	if err != nil {
+		return false, err
+	}
+	for i := range migrations {
+		if migrations[i].Kind == types.MigrationKindUpdate && migrations[i].Level > from && migrations[i].Level <= to {
+			return true, nil
+		}
+	}
+	return false, nil
+}
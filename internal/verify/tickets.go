@@ -0,0 +1,150 @@
+package verify
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/baking-bad/bcdhub/internal/models/account"
+	"github.com/baking-bad/bcdhub/internal/models/contract"
+	"github.com/baking-bad/bcdhub/internal/models/ticket"
+	"github.com/baking-bad/bcdhub/internal/noderpc"
+	"github.com/shopspring/decimal"
+)
+
+// ticketBalancesPageSize - the biggest page of balances which is returned by ticket repository
+const ticketBalancesPageSize = 99
+
+// ticketAmount - balance of ticket keyed by ticket hash
+type ticketAmount struct {
+	ticketer string
+	content  []byte
+	amount   decimal.Decimal
+}
+
+// ticketBalances - compares non-zero ticket balances of contract with node's ones.
+// Networks whose protocol doesn't provide ticket balances of contracts are skipped.
+func (v verifier) ticketBalances(ctx context.Context, c contract.Contract) ([]Mismatch, error) {
+	response, err := v.ctx.RPC.GetTicketBalances(ctx, c.Account.Address, v.block.Level)
+	if err != nil {
+		if noderpc.IsNotFoundError(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	expected := make(map[string]ticketAmount, len(response))
+	for i := range response {
+		amount, err := decimal.NewFromString(response[i].Amount)
+		if err != nil {
+			return nil, err
+		}
+		hash := ticket.Ticket{
+			ContentType: response[i].ContentType,
+			Content:     response[i].Content,
+			Ticketer:    account.Account{Address: response[i].Ticketer},
+		}.GetHash()
+		expected[hash] = ticketAmount{
+			ticketer: response[i].Ticketer,
+			content:  response[i].Content,
+			amount:   amount,
+		}
+	}
+
+	indexed, err := v.indexedTicketBalances(ctx, c)
+	if err != nil {
+		return nil, err
+	}
+
+	hashes := make([]string, 0, len(expected)+len(indexed))
+	for hash := range indexed {
+		hashes = append(hashes, hash)
+	}
+	for hash := range expected {
+		if _, ok := indexed[hash]; !ok {
+			hashes = append(hashes, hash)
+		}
+	}
+	sort.Strings(hashes)
+
+	mismatches := make([]Mismatch, 0)
+	for _, hash := range hashes {
+		got, inIndex := indexed[hash]
+		want := expected[hash]
+		if got.amount.Equal(want.amount) {
+			continue
+		}
+
+		key := want
+		if inIndex {
+			key = got
+		}
+		mismatches = append(mismatches, Mismatch{
+			Contract: c.Account.Address,
+			Kind:     KindTicketBalance,
+			Key:      fmt.Sprintf("%s %s", key.ticketer, key.content),
+			Indexed:  got.amount.String(),
+			Expected: want.amount.String(),
+		})
+	}
+	return mismatches, nil
+}
+
+// indexedTicketBalances - returns non-zero ticket balances of contract at the verified level keyed by ticket hash.
+// Balances of the past are summed up from ticket updates.
+func (v verifier) indexedTicketBalances(ctx context.Context, c contract.Contract) (map[string]ticketAmount, error) {
+	result := make(map[string]ticketAmount)
+
+	if v.atHead {
+		for offset := int64(0); ; offset += ticketBalancesPageSize {
+			balances, err := v.ctx.Tickets.BalancesForAccount(ctx, c.AccountID, ticket.BalanceRequest{
+				Limit:               ticketBalancesPageSize,
+				Offset:              offset,
+				WithoutZeroBalances: true,
+			})
+			if err != nil {
+				return nil, err
+			}
+			for i := range balances {
+				result[balances[i].Ticket.Hash] = ticketAmount{
+					ticketer: balances[i].Ticket.Ticketer.Address,
+					content:  balances[i].Ticket.Content,
+					amount:   balances[i].Amount,
+				}
+			}
+			if len(balances) < ticketBalancesPageSize {
+				return result, nil
+			}
+		}
+	}
+
+	err := v.ctx.Tickets.ExportUpdates(ctx, ticket.UpdatesRequest{
+		Account: c.Account.Address,
+		ToLevel: v.block.Level,
+		Limit:   1000,
+	}, func(updates []ticket.TicketUpdate) error {
+		for i := range updates {
+			balance, ok := result[updates[i].Ticket.Hash]
+			if !ok {
+				balance = ticketAmount{
+					ticketer: updates[i].Ticket.Ticketer.Address,
+					content:  updates[i].Ticket.Content,
+					amount:   decimal.Zero,
+				}
+			}
+			balance.amount = balance.amount.Add(updates[i].Amount)
+			result[updates[i].Ticket.Hash] = balance
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for hash, balance := range result {
+		if balance.amount.IsZero() {
+			delete(result, hash)
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,216 @@
+package verify
+
+import (
+	"context"
+	"math/rand/v2"
+	"reflect"
+
+	"github.com/baking-bad/bcdhub/internal/config"
+	"github.com/baking-bad/bcdhub/internal/models/block"
+	"github.com/baking-bad/bcdhub/internal/models/contract"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+)
+
+var json = jsoniter.ConfigCompatibleWithStandardLibrary
+
+// DefaultMaxKeys - count of big map keys which are compared per contract by default
+const DefaultMaxKeys = 1000
+
+// Kind - kind of verified state
+type Kind string
+
+const (
+	KindStorage       Kind = "storage"
+	KindBigMap        Kind = "big_map"
+	KindTicketBalance Kind = "ticket_balance"
+	KindCounters      Kind = "counters"
+)
+
+// Options - parameters of verification. Zero values mean defaults.
+type Options struct {
+	// Level - level of comparison. Indexed head is used if it's zero.
+	Level int64
+	// Sample - count of randomly chosen contracts. All contracts are verified if it's zero.
+	Sample int
+	// Addresses - contracts which are verified instead of sample
+	Addresses []string
+	// MaxKeys - limit of compared big map keys per contract
+	MaxKeys int
+}
+
+// Mismatch - difference between indexed state and expected one. Expected state is received from node, except for counters
+// which are recalculated by indexed operations. Only counters can be repaired: big map states and ticket balances are derived
+// from big map diffs and ticket updates, so patching them would make historical state disagree with the current one.
+// Such contracts have to be re-indexed.
+type Mismatch struct {
+	Contract string
+	Kind     Kind
+	Key      string
+	Indexed  string
+	Expected string
+
+	// data to recalculate counters in repair transaction. It's nil if mismatch can't be repaired.
+	repair *countersRepair
+}
+
+// countersRepair - account which counters are recalculated and count of its operations which aren't stored as operations
+type countersRepair struct {
+	accountID           int64
+	bootstrapOperations int64
+}
+
+// Repairable - returns true if indexed state can be replaced with the expected one
+func (m Mismatch) Repairable() bool {
+	return m.repair != nil
+}
+
+// Report - result of verification
+type Report struct {
+	Level      int64
+	AtHead     bool
+	Checked    int
+	Mismatches []Mismatch
+}
+
+// Repairable - returns count of mismatches which can be repaired
+func (r Report) Repairable() int {
+	var count int
+	for i := range r.Mismatches {
+		if r.Mismatches[i].Repairable() {
+			count++
+		}
+	}
+	return count
+}
+
+// Contracts - returns addresses of contracts with mismatches in order of their appearance in report
+func (r Report) Contracts() []string {
+	addresses := make([]string, 0)
+	seen := make(map[string]struct{})
+	for i := range r.Mismatches {
+		if _, ok := seen[r.Mismatches[i].Contract]; ok {
+			continue
+		}
+		seen[r.Mismatches[i].Contract] = struct{}{}
+		addresses = append(addresses, r.Mismatches[i].Contract)
+	}
+	return addresses
+}
+
+type verifier struct {
+	ctx     *config.Context
+	block   block.Block
+	atHead  bool
+	maxKeys int
+}
+
+// Run - compares indexed state of contracts with node state at the level of `opts`
+func Run(ctx context.Context, cfgCtx *config.Context, opts Options) (Report, error) {
+	head, err := cfgCtx.Blocks.Last(ctx)
+	if err != nil {
+		return Report{}, errors.Wrap(err, "get indexed head")
+	}
+
+	v := verifier{
+		ctx:     cfgCtx,
+		block:   head,
+		atHead:  true,
+		maxKeys: opts.MaxKeys,
+	}
+	if v.maxKeys <= 0 {
+		v.maxKeys = DefaultMaxKeys
+	}
+
+	switch {
+	case opts.Level > head.Level:
+		return Report{}, errors.Errorf("level %d is above indexed head %d", opts.Level, head.Level)
+	case opts.Level > 0 && opts.Level < head.Level:
+		b, err := cfgCtx.Blocks.Get(ctx, opts.Level)
+		if err != nil {
+			return Report{}, errors.Wrapf(err, "get block %d", opts.Level)
+		}
+		v.block = b
+		v.atHead = false
+	}
+
+	contracts, err := selectContracts(ctx, cfgCtx, opts)
+	if err != nil {
+		return Report{}, err
+	}
+
+	report := Report{
+		Level:      v.block.Level,
+		AtHead:     v.atHead,
+		Mismatches: make([]Mismatch, 0),
+	}
+	for i := range contracts {
+		if contracts[i].Account.Level > v.block.Level {
+			continue
+		}
+		mismatches, err := v.contract(ctx, contracts[i])
+		if err != nil {
+			return report, errors.Wrap(err, contracts[i].Account.Address)
+		}
+		report.Checked++
+		report.Mismatches = append(report.Mismatches, mismatches...)
+
+		if report.Checked%100 == 0 {
+			log.Info().Int("checked", report.Checked).Int("total", len(contracts)).Int("mismatches", len(report.Mismatches)).Msg("verification progress")
+		}
+	}
+	return report, nil
+}
+
+func selectContracts(ctx context.Context, cfgCtx *config.Context, opts Options) ([]contract.Contract, error) {
+	if len(opts.Addresses) > 0 {
+		contracts := make([]contract.Contract, 0, len(opts.Addresses))
+		for _, address := range opts.Addresses {
+			c, err := cfgCtx.Contracts.Get(ctx, address)
+			if err != nil {
+				return nil, errors.Wrap(err, address)
+			}
+			contracts = append(contracts, c)
+		}
+		return contracts, nil
+	}
+
+	contracts, err := cfgCtx.Contracts.AllExceptDelegators(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "receive contracts")
+	}
+	if opts.Sample > 0 && opts.Sample < len(contracts) {
+		rand.Shuffle(len(contracts), func(i, j int) {
+			contracts[i], contracts[j] = contracts[j], contracts[i]
+		})
+		contracts = contracts[:opts.Sample]
+	}
+	return contracts, nil
+}
+
+func (v verifier) contract(ctx context.Context, c contract.Contract) ([]Mismatch, error) {
+	mismatches := make([]Mismatch, 0)
+	for _, check := range []func(context.Context, contract.Contract) ([]Mismatch, error){
+		v.storage, v.bigMaps, v.ticketBalances, v.counters,
+	} {
+		result, err := check(ctx, c)
+		if err != nil {
+			return nil, err
+		}
+		mismatches = append(mismatches, result...)
+	}
+	return mismatches, nil
+}
+
+// jsonEqual - compares JSON documents regardless of their formatting
+func jsonEqual(a, b []byte) (bool, error) {
+	var x, y any
+	if err := json.Unmarshal(a, &x); err != nil {
+		return false, err
+	}
+	if err := json.Unmarshal(b, &y); err != nil {
+		return false, err
+	}
+	return reflect.DeepEqual(x, y), nil
+}
@@ -0,0 +1,411 @@
+package verify
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/baking-bad/bcdhub/internal/config"
+	"github.com/baking-bad/bcdhub/internal/models/account"
+	"github.com/baking-bad/bcdhub/internal/models/bigmapdiff"
+	"github.com/baking-bad/bcdhub/internal/models/block"
+	"github.com/baking-bad/bcdhub/internal/models/contract"
+	"github.com/baking-bad/bcdhub/internal/models/migration"
+	"github.com/baking-bad/bcdhub/internal/models/mock"
+	mock_bmd "github.com/baking-bad/bcdhub/internal/models/mock/bigmapdiff"
+	mock_block "github.com/baking-bad/bcdhub/internal/models/mock/block"
+	mock_contract "github.com/baking-bad/bcdhub/internal/models/mock/contract"
+	mock_migration "github.com/baking-bad/bcdhub/internal/models/mock/migration"
+	mock_operation "github.com/baking-bad/bcdhub/internal/models/mock/operation"
+	mock_ticket "github.com/baking-bad/bcdhub/internal/models/mock/ticket"
+	"github.com/baking-bad/bcdhub/internal/models/operation"
+	"github.com/baking-bad/bcdhub/internal/models/ticket"
+	"github.com/baking-bad/bcdhub/internal/models/types"
+	"github.com/baking-bad/bcdhub/internal/noderpc"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+const (
+	contractAddress = "KT1Hkg5qeNhfwpKW4fXvq7HGZB9z2EnmCCA9"
+	ticketerAddress = "KT1SM849krq9FFxGWCZyc7X5GvAz8XnRmXnf"
+)
+
+type testRepos struct {
+	general    *mock.MockGeneralRepository
+	blocks     *mock_block.MockRepository
+	contracts  *mock_contract.MockRepository
+	operations *mock_operation.MockRepository
+	migrations *mock_migration.MockRepository
+	bigMaps    *mock_bmd.MockRepository
+	tickets    *mock_ticket.MockRepository
+	rpc        *noderpc.MockINode
+}
+
+func newTestContext(ctrl *gomock.Controller) (*config.Context, testRepos) {
+	repos := testRepos{
+		general:    mock.NewMockGeneralRepository(ctrl),
+		blocks:     mock_block.NewMockRepository(ctrl),
+		contracts:  mock_contract.NewMockRepository(ctrl),
+		operations: mock_operation.NewMockRepository(ctrl),
+		migrations: mock_migration.NewMockRepository(ctrl),
+		bigMaps:    mock_bmd.NewMockRepository(ctrl),
+		tickets:    mock_ticket.NewMockRepository(ctrl),
+		rpc:        noderpc.NewMockINode(ctrl),
+	}
+	repos.general.EXPECT().
+		IsRecordNotFound(gomock.Any()).
+		DoAndReturn(func(err error) bool {
+			return err == sql.ErrNoRows
+		}).
+		AnyTimes()
+
+	return &config.Context{
+		Network:     types.Mainnet,
+		RPC:         repos.rpc,
+		Storage:     repos.general,
+		Blocks:      repos.blocks,
+		Contracts:   repos.contracts,
+		Operations:  repos.operations,
+		Migrations:  repos.migrations,
+		BigMapDiffs: repos.bigMaps,
+		Tickets:     repos.tickets,
+	}, repos
+}
+
+func testContract() contract.Contract {
+	return contract.Contract{
+		ID:        1,
+		AccountID: 10,
+		Account: account.Account{
+			ID:              10,
+			Address:         contractAddress,
+			Type:            types.AccountTypeContract,
+			Level:           5,
+			OperationsCount: 3,
+		},
+	}
+}
+
+func testTicket(content string) ticket.Ticket {
+	t := ticket.Ticket{
+		ContentType: []byte(`{"prim":"string"}`),
+		Content:     []byte(content),
+		Ticketer:    account.Account{Address: ticketerAddress},
+	}
+	t.Hash = t.GetHash()
+	return t
+}
+
+func TestRun_atHead(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	cfgCtx, repos := newTestContext(ctrl)
+	timestamp := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	repos.blocks.EXPECT().
+		Last(gomock.Any()).
+		Return(block.Block{Level: 100, Timestamp: timestamp}, nil).
+		Times(1)
+	repos.contracts.EXPECT().
+		Get(gomock.Any(), contractAddress).
+		Return(testContract(), nil).
+		Times(1)
+
+	repos.operations.EXPECT().
+		Last(gomock.Any(), map[string]interface{}{
+			"destination_id": int64(10),
+			"status":         types.OperationStatusApplied,
+			"last_action":    timestamp,
+		}, int64(0)).
+		Return(operation.Operation{Level: 90, DeffatedStorage: []byte(`{"prim":"Pair","args":[{"int":"7"},{"int":"1"}]}`)}, nil).
+		Times(1)
+	repos.migrations.EXPECT().
+		Get(gomock.Any(), int64(1)).
+		Return(nil, nil).
+		Times(2)
+	repos.rpc.EXPECT().
+		GetScriptStorageRaw(gomock.Any(), contractAddress, int64(100)).
+		Return([]byte(`{ "prim": "Pair", "args": [ { "int": "7" }, { "int": "1" } ] }`), nil).
+		Times(1)
+
+	states := []bigmapdiff.BigMapState{
+		{ID: 1, Ptr: 7, KeyHash: "expru1", Contract: contractAddress, Key: []byte(`{"int":"1"}`), Value: []byte(`{"int":"5"}`), Count: 1},
+		{ID: 2, Ptr: 7, KeyHash: "expru2", Contract: contractAddress, Key: []byte(`{"int":"2"}`), Value: []byte(`{"int":"6"}`), Count: 2},
+		{ID: 3, Ptr: -1, KeyHash: "expru3", Contract: contractAddress, Key: []byte(`{"int":"3"}`), Value: []byte(`{"int":"7"}`), Count: 1},
+	}
+	repos.bigMaps.EXPECT().
+		GetForAddress(gomock.Any(), contractAddress).
+		Return(states, nil).
+		Times(1)
+	repos.rpc.EXPECT().
+		GetBigMapType(gomock.Any(), int64(7), int64(100)).
+		Return(noderpc.BigMap{}, nil).
+		Times(1)
+	repos.rpc.EXPECT().
+		GetBigMapValue(gomock.Any(), int64(7), "expru1", int64(100)).
+		Return([]byte(`{"int":"5"}`), nil).
+		Times(1)
+	repos.rpc.EXPECT().
+		GetBigMapValue(gomock.Any(), int64(7), "expru2", int64(100)).
+		Return(nil, fmt.Errorf("big_maps/7/expru2: %w", noderpc.ErrNotFound)).
+		Times(1)
+
+	indexedTicket := testTicket(`{"string":"a"}`)
+	newTicket := testTicket(`{"string":"b"}`)
+	repos.rpc.EXPECT().
+		GetTicketBalances(gomock.Any(), contractAddress, int64(100)).
+		Return([]noderpc.TicketBalance{
+			{Ticketer: ticketerAddress, ContentType: indexedTicket.ContentType, Content: indexedTicket.Content, Amount: "15"},
+			{Ticketer: ticketerAddress, ContentType: newTicket.ContentType, Content: newTicket.Content, Amount: "3"},
+		}, nil).
+		Times(1)
+	repos.tickets.EXPECT().
+		BalancesForAccount(gomock.Any(), int64(10), ticket.BalanceRequest{Limit: ticketBalancesPageSize, WithoutZeroBalances: true}).
+		Return([]ticket.Balance{
+			{TicketId: 2, AccountId: 10, Amount: decimal.NewFromInt(10), Ticket: indexedTicket},
+		}, nil).
+		Times(1)
+
+	repos.operations.EXPECT().
+		CountersForAccount(gomock.Any(), int64(10)).
+		Return(operation.AccountCounters{OperationsCount: 3, EventsCount: 1}, nil).
+		Times(1)
+
+	report, err := Run(context.Background(), cfgCtx, Options{Addresses: []string{contractAddress}})
+	require.NoError(t, err)
+	require.True(t, report.AtHead)
+	require.EqualValues(t, 100, report.Level)
+	require.Equal(t, 1, report.Checked)
+	require.Equal(t, []string{contractAddress}, report.Contracts())
+	require.Len(t, report.Mismatches, 4)
+	require.Equal(t, 1, report.Repairable())
+
+	byKey := make(map[string]Mismatch)
+	for _, m := range report.Mismatches {
+		// big map states and ticket balances would disagree with their history after repair
+		require.Equal(t, m.Kind == KindCounters, m.Repairable())
+		byKey[m.Key] = m
+	}
+
+	bigMap := byKey["7/expru2"]
+	require.Equal(t, KindBigMap, bigMap.Kind)
+	require.Equal(t, `{"int":"6"}`, bigMap.Indexed)
+	require.Equal(t, "removed", bigMap.Expected)
+
+	balance := byKey[fmt.Sprintf("%s %s", ticketerAddress, indexedTicket.Content)]
+	require.Equal(t, KindTicketBalance, balance.Kind)
+	require.Equal(t, "10", balance.Indexed)
+	require.Equal(t, "15", balance.Expected)
+
+	counters := byKey["events_count"]
+	require.Equal(t, KindCounters, counters.Kind)
+	require.Equal(t, "0", counters.Indexed)
+	require.Equal(t, "1", counters.Expected)
+
+	tx := mock.NewMockTransaction(ctrl)
+	tx.EXPECT().
+		AccountCounters(gomock.Any(), int64(10)).
+		Return(testContract().Account, operation.AccountCounters{OperationsCount: 3, EventsCount: 1}, nil).
+		Times(1)
+	tx.EXPECT().
+		Accounts(gomock.Any(), &account.Account{
+			Address:     contractAddress,
+			Type:        types.AccountTypeContract,
+			Level:       5,
+			EventsCount: 1,
+		}).
+		Return(nil).
+		Times(1)
+
+	changed, err := repairContract(context.Background(), tx, report.Mismatches)
+	require.NoError(t, err)
+	require.True(t, changed)
+}
+
+func TestRepairContract_recalculatesInTransaction(t *testing.T) {
+	mismatches := []Mismatch{
+		{
+			Contract: contractAddress,
+			Kind:     KindCounters,
+			Key:      "events_count",
+			Indexed:  "0",
+			Expected: "1",
+			repair:   &countersRepair{accountID: 10},
+		},
+	}
+
+	t.Run("operations indexed after verification", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		indexed := testContract().Account
+		indexed.OperationsCount = 5
+		indexed.EventsCount = 1
+
+		tx := mock.NewMockTransaction(ctrl)
+		tx.EXPECT().
+			AccountCounters(gomock.Any(), int64(10)).
+			Return(indexed, operation.AccountCounters{OperationsCount: 5, EventsCount: 1}, nil).
+			Times(1)
+
+		changed, err := repairContract(context.Background(), tx, mismatches)
+		require.NoError(t, err)
+		require.False(t, changed)
+	})
+
+	t.Run("only remaining difference is saved", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		indexed := testContract().Account
+		indexed.OperationsCount = 5
+
+		tx := mock.NewMockTransaction(ctrl)
+		tx.EXPECT().
+			AccountCounters(gomock.Any(), int64(10)).
+			Return(indexed, operation.AccountCounters{OperationsCount: 5, EventsCount: 2}, nil).
+			Times(1)
+		tx.EXPECT().
+			Accounts(gomock.Any(), &account.Account{
+				Address:     contractAddress,
+				Type:        types.AccountTypeContract,
+				Level:       5,
+				EventsCount: 2,
+			}).
+			Return(nil).
+			Times(1)
+
+		changed, err := repairContract(context.Background(), tx, mismatches)
+		require.NoError(t, err)
+		require.True(t, changed)
+	})
+}
+
+func TestVerifier_countersOfBootstrappedContract(t *testing.T) {
+	tests := []struct {
+		name           string
+		implicit       bool
+		indexed        int64
+		wantMismatches int
+	}{
+		{
+			name:    "implicit origination is counted as operation",
+			indexed: 1,
+		}, {
+			name:     "vesting contract isn't counted",
+			implicit: true,
+			indexed:  0,
+		}, {
+			name:           "missed implicit origination",
+			indexed:        0,
+			wantMismatches: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			cfgCtx, repos := newTestContext(ctrl)
+			if tt.implicit {
+				cfgCtx.Config.ImplicitContracts = map[string][]config.Contract{
+					types.Mainnet.String(): {{Address: contractAddress, Level: 1}},
+				}
+			}
+
+			c := testContract()
+			c.Account.OperationsCount = tt.indexed
+
+			repos.operations.EXPECT().
+				CountersForAccount(gomock.Any(), int64(10)).
+				Return(operation.AccountCounters{}, nil).
+				Times(1)
+			repos.migrations.EXPECT().
+				Get(gomock.Any(), int64(1)).
+				Return([]migration.Migration{{Kind: types.MigrationKindBootstrap, Level: 1}}, nil).
+				MaxTimes(1)
+
+			v := verifier{ctx: cfgCtx, atHead: true}
+			mismatches, err := v.counters(context.Background(), c)
+			require.NoError(t, err)
+			require.Len(t, mismatches, tt.wantMismatches)
+		})
+	}
+}
+
+func TestRun_belowHead(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	cfgCtx, repos := newTestContext(ctrl)
+
+	repos.blocks.EXPECT().
+		Last(gomock.Any()).
+		Return(block.Block{Level: 100}, nil).
+		Times(1)
+	repos.blocks.EXPECT().
+		Get(gomock.Any(), int64(50)).
+		Return(block.Block{Level: 50}, nil).
+		Times(1)
+
+	young := testContract()
+	young.Account.Address = "KT1young"
+	young.Account.Level = 70
+	repos.contracts.EXPECT().
+		AllExceptDelegators(gomock.Any()).
+		Return([]contract.Contract{testContract(), young}, nil).
+		Times(1)
+
+	repos.operations.EXPECT().
+		Last(gomock.Any(), gomock.Any(), int64(0)).
+		Return(operation.Operation{}, sql.ErrNoRows).
+		Times(1)
+
+	repos.bigMaps.EXPECT().
+		GetForAddress(gomock.Any(), contractAddress).
+		Return([]bigmapdiff.BigMapState{
+			{Ptr: 7, KeyHash: "expru1", Contract: contractAddress, Value: []byte(`{"int":"5"}`)},
+			{Ptr: 7, KeyHash: "expru2", Contract: contractAddress, Removed: true},
+		}, nil).
+		Times(1)
+	repos.bigMaps.EXPECT().
+		GetAtLevel(gomock.Any(), contractAddress, int64(50), int64(7)).
+		Return([]bigmapdiff.BigMapDiff{
+			{Ptr: 7, KeyHash: "expru1", Contract: contractAddress, Value: []byte(`{"int":"4"}`)},
+		}, nil).
+		Times(1)
+	repos.rpc.EXPECT().
+		GetBigMapType(gomock.Any(), int64(7), int64(50)).
+		Return(noderpc.BigMap{}, nil).
+		Times(1)
+	repos.rpc.EXPECT().
+		GetBigMapValue(gomock.Any(), int64(7), "expru1", int64(50)).
+		Return([]byte(`{"int":"3"}`), nil).
+		Times(1)
+
+	repos.rpc.EXPECT().
+		GetTicketBalances(gomock.Any(), contractAddress, int64(50)).
+		Return(nil, noderpc.ErrNotFound).
+		Times(1)
+
+	report, err := Run(context.Background(), cfgCtx, Options{Level: 50})
+	require.NoError(t, err)
+	require.False(t, report.AtHead)
+	require.Equal(t, 1, report.Checked)
+	require.Len(t, report.Mismatches, 1)
+	require.Equal(t, KindBigMap, report.Mismatches[0].Kind)
+	require.Equal(t, `{"int":"4"}`, report.Mismatches[0].Indexed)
+	require.Equal(t, `{"int":"3"}`, report.Mismatches[0].Expected)
+	require.False(t, report.Mismatches[0].Repairable())
+
+	_, err = Repair(context.Background(), cfgCtx, report)
+	require.Error(t, err)
+}
+
+func TestRun_levelAboveHead(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	cfgCtx, repos := newTestContext(ctrl)
+
+	repos.blocks.EXPECT().
+		Last(gomock.Any()).
+		Return(block.Block{Level: 100}, nil).
+		Times(1)
+
+	_, err := Run(context.Background(), cfgCtx, Options{Level: 101})
+	require.Error(t, err)
+}
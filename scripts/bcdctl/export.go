@@ -0,0 +1,98 @@
+package main
+
+import (
+	"context"
+	"io"
+	"os"
+
+	"github.com/baking-bad/bcdhub/internal/config"
+	"github.com/baking-bad/bcdhub/internal/export"
+	"github.com/baking-bad/bcdhub/internal/models/bigmapdiff"
+	"github.com/baking-bad/bcdhub/internal/models/operation"
+	"github.com/baking-bad/bcdhub/internal/models/ticket"
+	"github.com/baking-bad/bcdhub/internal/models/types"
+	"github.com/rs/zerolog/log"
+)
+
+type exportCommand struct {
+	Network string `description:"Network"                                   long:"network" short:"n" required:"true"`
+	Format  string `description:"Output format"                             long:"format"  short:"f" default:"csv" choice:"csv" choice:"ndjson"`
+	Output  string `description:"Output file. Stdout is used if it's empty" long:"output"  short:"o"`
+}
+
+type exportOperationsCommand struct {
+	Address string `description:"Account address" long:"address" short:"a" required:"true"`
+}
+
+type exportBigMapKeysCommand struct {
+	Ptr int64 `description:"Big map pointer" long:"ptr" short:"p" required:"true"`
+}
+
+type exportTicketUpdatesCommand struct {
+	Address string `description:"Ticketer address" long:"address" short:"a" required:"true"`
+}
+
+var (
+	exportCmd              exportCommand
+	exportOperationsCmd    exportOperationsCommand
+	exportBigMapKeysCmd    exportBigMapKeysCommand
+	exportTicketUpdatesCmd exportTicketUpdatesCommand
+)
+
+// Execute
+func (x *exportOperationsCommand) Execute(_ []string) error {
+	return exportCmd.run(func(ctx context.Context, cfgCtx *config.Context, w export.Writer) error {
+		acc, err := cfgCtx.Accounts.Get(ctx, x.Address)
+		if err != nil {
+			return err
+		}
+		return export.Operations(ctx, cfgCtx, acc.ID, operation.HistoryRequest{}, w)
+	})
+}
+
+// Execute
+func (x *exportBigMapKeysCommand) Execute(_ []string) error {
+	return exportCmd.run(func(ctx context.Context, cfgCtx *config.Context, w export.Writer) error {
+		return export.BigMapKeys(ctx, cfgCtx, bigmapdiff.GetContext{Ptr: &x.Ptr}, w)
+	})
+}
+
+// Execute
+func (x *exportTicketUpdatesCommand) Execute(_ []string) error {
+	return exportCmd.run(func(ctx context.Context, cfgCtx *config.Context, w export.Writer) error {
+		return export.TicketUpdates(ctx, cfgCtx, ticket.UpdatesRequest{Ticketer: x.Address}, w)
+	})
+}
+
+func (x *exportCommand) run(handler func(ctx context.Context, cfgCtx *config.Context, w export.Writer) error) error {
+	cfgCtx, err := ctxs.Get(types.NewNetwork(x.Network))
+	if err != nil {
+		return err
+	}
+
+	var output io.Writer = os.Stdout
+	if x.Output != "" {
+		file, err := os.Create(x.Output)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		output = file
+	}
+
+	writer, err := export.NewWriter(export.Format(x.Format), output)
+	if err != nil {
+		return err
+	}
+
+	if err := handler(context.Background(), cfgCtx, writer); err != nil {
+		return err
+	}
+	if err := writer.Flush(); err != nil {
+		return err
+	}
+	if x.Output != "" {
+		log.Info().Str("output", x.Output).Msg("Done")
+	}
+	return nil
+}
@@ -41,6 +41,36 @@ func main() {
 		return
 	}
 
+	if _, err := parser.AddCommand("verify",
+		"Verify indexed state",
+		"Compare indexed state of contracts with node state and optionally repair mismatches",
+		&verifyCmd); err != nil {
+		log.Err(err).Msg("add verify command")
+		return
+	}
+
+	export, err := parser.AddCommand("export",
+		"Export collection",
+		"Export operations, big map keys or ticket updates to CSV or NDJSON",
+		&exportCmd)
+	if err != nil {
+		log.Err(err).Msg("add export command")
+		return
+	}
+	for _, sub := range []struct {
+		name, short, long string
+		data              any
+	}{
+		{"operations", "Export operations", "Export all operations of account", &exportOperationsCmd},
+		{"bigmap", "Export big map keys", "Export current state of big map keys", &exportBigMapKeysCmd},
+		{"ticket_updates", "Export ticket updates", "Export all ticket updates of ticketer", &exportTicketUpdatesCmd},
+	} {
+		if _, err := export.AddCommand(sub.name, sub.short, sub.long, sub.data); err != nil {
+			log.Err(err).Msgf("add export %s command", sub.name)
+			return
+		}
+	}
+
 	if _, err := parser.Parse(); err != nil {
 		panic(err)
 	}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/baking-bad/bcdhub/internal/models/types"
+	"github.com/baking-bad/bcdhub/internal/verify"
+	"github.com/rs/zerolog/log"
+)
+
+// maxValueLength - length of state values in the report. Longer values are truncated.
+const maxValueLength = 64
+
+type verifyCommand struct {
+	Network   string   `description:"Network"                                                                         long:"network"  short:"n" required:"true"`
+	Level     int64    `description:"Level of comparison. Indexed head is used if it's not set"                       long:"level"    short:"l"`
+	Sample    int      `description:"Count of random contracts to verify. All contracts are verified if it's not set" long:"sample"   short:"s"`
+	Addresses []string `description:"Contract to verify. It can be set several times"                                 long:"address"  short:"a"`
+	MaxKeys   int      `description:"Limit of compared big map keys per contract"                                     long:"max-keys" default:"1000"`
+	Repair    bool     `description:"Replace mismatched counters with the recalculated ones"                          long:"repair"`
+}
+
+var verifyCmd verifyCommand
+
+// Execute
+func (x *verifyCommand) Execute(_ []string) error {
+	network := types.NewNetwork(x.Network)
+	cfgCtx, err := ctxs.Get(network)
+	if err != nil {
+		return err
+	}
+
+	report, err := verify.Run(context.Background(), cfgCtx, verify.Options{
+		Level:     x.Level,
+		Sample:    x.Sample,
+		Addresses: x.Addresses,
+		MaxKeys:   x.MaxKeys,
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := printReport(report); err != nil {
+		return err
+	}
+
+	if !x.Repair || report.Repairable() == 0 {
+		return nil
+	}
+
+	log.Warn().Msgf("Do you want to repair %d mismatches of '%s'? (yes - continue. no - cancel)", report.Repairable(), network.String())
+	if !yes() {
+		log.Info().Msg("Cancelled")
+		return nil
+	}
+
+	repaired, err := verify.Repair(context.Background(), cfgCtx, report)
+	if err != nil {
+		return err
+	}
+	log.Info().Int("repaired", repaired).Int("mismatches", len(report.Mismatches)).Msg("Done")
+	return nil
+}
+
+func printReport(report verify.Report) error {
+	fmt.Printf("level: %d, checked contracts: %d, mismatches: %d\n", report.Level, report.Checked, len(report.Mismatches))
+	if len(report.Mismatches) == 0 {
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "CONTRACT\tKIND\tKEY\tINDEXED\tEXPECTED\tREPAIRABLE")
+	for _, m := range report.Mismatches {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%t\n",
+			m.Contract, m.Kind, truncate(m.Key), truncate(m.Indexed), truncate(m.Expected), m.Repairable())
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	if notRepairable := len(report.Mismatches) - report.Repairable(); notRepairable > 0 {
+		fmt.Printf("%d mismatches can't be repaired: only counters are repaired, contracts with other mismatches have to be re-indexed\n", notRepairable)
+	}
+	return nil
+}
+
+func truncate(value string) string {
+	if len(value) <= maxValueLength {
+		return value
+	}
+	return value[:maxValueLength-3] + "..."
+}
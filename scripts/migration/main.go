@@ -14,7 +14,11 @@ import (
 	"github.com/rs/zerolog/log"
 )
 
-var migrationsList = []migrations.Migration{}
+var migrationsList = []migrations.Migration{
+	&migrations.BlockPredecessor{},
+	&migrations.ContractMetadataHistory{},
+	&migrations.MigrationStorage{},
+}
 
 func main() {
 	cctx, cancel := context.WithCancel(context.Background())
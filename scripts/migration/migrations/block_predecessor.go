@@ -0,0 +1,32 @@
+package migrations
+
+import (
+	"context"
+
+	"github.com/baking-bad/bcdhub/internal/config"
+	"github.com/baking-bad/bcdhub/internal/models/block"
+)
+
+// BlockPredecessor - adds column of predecessor hash to blocks which are indexed before reorganisation detection.
+// Predecessors of such blocks stay empty: rollback reads hashes of previous blocks instead.
+type BlockPredecessor struct{}
+
+// Key -
+func (m *BlockPredecessor) Key() string {
+	return "block_predecessor"
+}
+
+// Description -
+func (m *BlockPredecessor) Description() string {
+	return "add `predecessor` column to `blocks` table"
+}
+
+// Do - migrate function
+func (m *BlockPredecessor) Do(ctx *config.Context) error {
+	_, err := ctx.StorageDB.DB.NewAddColumn().
+		Model((*block.Block)(nil)).
+		ColumnExpr("predecessor text").
+		IfNotExists().
+		Exec(context.Background())
+	return err
+}
@@ -0,0 +1,37 @@
+package migrations
+
+import (
+	"context"
+
+	"github.com/baking-bad/bcdhub/internal/config"
+	"github.com/uptrace/bun"
+)
+
+// ContractMetadataHistory - replaces uniqueness of contract metadata by address with uniqueness by address and level,
+// so every resolved version of metadata is kept and rollback restores the previous one.
+type ContractMetadataHistory struct{}
+
+// Key -
+func (m *ContractMetadataHistory) Key() string {
+	return "contract_metadata_history"
+}
+
+// Description -
+func (m *ContractMetadataHistory) Description() string {
+	return "keep versions of contract metadata by level"
+}
+
+// Do - migrate function
+func (m *ContractMetadataHistory) Do(ctx *config.Context) error {
+	return ctx.StorageDB.DB.RunInTx(context.Background(), nil, func(c context.Context, tx bun.Tx) error {
+		if _, err := tx.ExecContext(c, `ALTER TABLE contract_metadata DROP CONSTRAINT IF EXISTS contract_metadata_address_key`); err != nil {
+			return err
+		}
+		_, err := tx.ExecContext(c, `DO $$ BEGIN
+			IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'contract_metadata_address_level') THEN
+				ALTER TABLE contract_metadata ADD CONSTRAINT contract_metadata_address_level UNIQUE (address, level);
+			END IF;
+		END $$`)
+		return err
+	})
+}
@@ -0,0 +1,67 @@
+package migrations
+
+import (
+	"context"
+
+	"github.com/baking-bad/bcdhub/internal/config"
+	"github.com/baking-bad/bcdhub/internal/models/migration"
+	"github.com/baking-bad/bcdhub/internal/models/types"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+)
+
+// MigrationStorage - adds column of contract storage after protocol migration and fills it for indexed update migrations by node requests.
+// Historical storage of migrated contracts is rebuilt from this column.
+type MigrationStorage struct{}
+
+// Key -
+func (m *MigrationStorage) Key() string {
+	return "migration_storage"
+}
+
+// Description -
+func (m *MigrationStorage) Description() string {
+	return "add `deffated_storage` column to `migrations` table and fill it for update migrations"
+}
+
+// Do - migrate function
+func (m *MigrationStorage) Do(ctx *config.Context) error {
+	c := context.Background()
+
+	if _, err := ctx.StorageDB.DB.NewAddColumn().
+		Model((*migration.Migration)(nil)).
+		ColumnExpr("deffated_storage bytea").
+		IfNotExists().
+		Exec(c); err != nil {
+		return err
+	}
+
+	var migrations []migration.Migration
+	if err := ctx.StorageDB.DB.NewSelect().
+		Model(&migrations).
+		Relation("Contract").
+		Relation("Contract.Account").
+		Where("migration.kind = ?", types.MigrationKindUpdate).
+		Where("migration.deffated_storage IS NULL").
+		Scan(c); err != nil {
+		return err
+	}
+
+	for i := range migrations {
+		address := migrations[i].Contract.Account.Address
+		script, err := ctx.RPC.GetScriptJSON(c, address, migrations[i].Level)
+		if err != nil {
+			return errors.Wrapf(err, "receive storage of %s at level %d", address, migrations[i].Level)
+		}
+
+		if _, err := ctx.StorageDB.DB.NewUpdate().
+			Model((*migration.Migration)(nil)).
+			Set("deffated_storage = ?", []byte(script.Storage)).
+			Where("id = ?", migrations[i].ID).
+			Exec(c); err != nil {
+			return err
+		}
+		log.Info().Str("network", ctx.Network.String()).Str("contract", address).Int64("level", migrations[i].Level).Msg("migration storage is saved")
+	}
+	return nil
+}